@@ -0,0 +1,134 @@
+package alertingrules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/ngalert/api"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ConvertPrometheusRules converts the rule groups of Prometheus rule files to Grafana-managed rule groups, and writes
+// them to an alerting provisioning file. Nothing is saved to the database. With --dry-run, it only reports the parts
+// of the rules that cannot be converted.
+func ConvertPrometheusRules(c utils.CommandLine, runner server.Runner) error {
+	paths := c.Args().Slice()
+	if len(paths) == 0 {
+		return errors.New("missing path of the Prometheus rule files to convert")
+	}
+	datasourceUID := c.String("datasource-uid")
+	if datasourceUID == "" {
+		return errors.New("--datasource-uid must be set")
+	}
+	dryRun := c.Bool("dry-run")
+	folder, output := c.String("folder"), c.String("output")
+	if !dryRun && (folder == "" || output == "") {
+		return errors.New("--folder and --output must be set, unless it is a dry run")
+	}
+	orgID := int64(c.Int("org-id"))
+
+	var groups []apimodels.PrometheusRuleGroup
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var file apimodels.PrometheusRuleFile
+		if err := yaml.Unmarshal(b, &file); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		groups = append(groups, file.Groups...)
+	}
+
+	limits := api.RuleLimitsFromConfig(&runner.Cfg.UnifiedAlerting, runner.Features)
+	converter := prom.NewConverter(prom.Config{
+		DatasourceUID:   datasourceUID,
+		DefaultInterval: time.Minute,
+		BaseInterval:    limits.BaseInterval,
+		RecordingRules:  limits.RecordingRulesAllowed,
+	}, nil)
+
+	names := make(map[string]struct{}, len(groups))
+	converted := make([]ngmodels.AlertRuleGroupWithFolderFullpath, 0, len(groups))
+	ruleCount := 0
+	for _, group := range groups {
+		postable, result := converter.ConvertRuleGroup(group)
+		if result.Error == "" {
+			if _, ok := names[group.Name]; ok {
+				result.Error = "another rule group has the same name"
+			}
+		}
+		var rules []*ngmodels.AlertRuleWithOptionals
+		if result.Error == "" {
+			var err error
+			if rules, err = api.ValidateRuleGroup(&postable, orgID, "", limits); err != nil {
+				result.Error = err.Error()
+			}
+		}
+		printResult(result)
+		if result.Error != "" {
+			continue
+		}
+		names[group.Name] = struct{}{}
+
+		alertRules := make([]ngmodels.AlertRule, 0, len(rules))
+		for _, rule := range rules {
+			rule.UID = util.GenerateShortUID()
+			alertRules = append(alertRules, rule.AlertRule)
+		}
+		ruleCount += len(alertRules)
+		key := ngmodels.AlertRuleGroupKey{OrgID: orgID, RuleGroup: group.Name}
+		converted = append(converted, ngmodels.NewAlertRuleGroupWithFolderFullpath(key, alertRules, folder))
+	}
+
+	if dryRun {
+		logger.Infof("%s Converted %d rules in %d rule groups. Nothing is written in a dry run\n", color.GreenString("✔"), ruleCount, len(converted))
+		return nil
+	}
+	if len(converted) == 0 {
+		return errors.New("no rule group can be converted")
+	}
+	export, err := api.AlertingFileExportFromAlertRuleGroupWithFolderFullpath(converted)
+	if err != nil {
+		return fmt.Errorf("failed to create the provisioning file: %w", err)
+	}
+	b, err := yaml.Marshal(export)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, b, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	logger.Infof("%s Converted %d rules in %d rule groups to %s\n", color.GreenString("✔"), ruleCount, len(converted), output)
+	return nil
+}
+
+func printResult(result apimodels.PrometheusRuleGroupImportResult) {
+	if result.Error != "" {
+		logger.Warnf("Skipped rule group %s: %s\n", result.Name, result.Error)
+	}
+	for _, w := range result.Warnings {
+		logger.Warnf("Rule group %s: %s\n", result.Name, w)
+	}
+	for _, rule := range result.Rules {
+		if rule.Error != "" {
+			logger.Warnf("Skipped rule %s of rule group %s: %s\n", rule.Name, result.Name, rule.Error)
+			continue
+		}
+		for _, w := range rule.Warnings {
+			logger.Warnf("Rule %s of rule group %s: %s\n", rule.Name, result.Name, w)
+		}
+	}
+}
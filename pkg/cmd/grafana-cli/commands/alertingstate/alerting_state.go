@@ -0,0 +1,208 @@
+package alertingstate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/folder"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// ExportAlertInstanceStates writes the state of the alert instances of an organization saved in the database to a file.
+// The file can be imported with ImportAlertInstanceStates, or with the alert instance state import API.
+func ExportAlertInstanceStates(c utils.CommandLine, runner server.Runner) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("missing path of the file to export the alert instance states to")
+	}
+	ctx := context.Background()
+	st := newStore(runner)
+	orgID := int64(c.Int("org-id"))
+
+	ruleUIDs := c.StringSlice("rule-uid")
+	if len(ruleUIDs) == 0 {
+		ruleUIDs = []string{""}
+	}
+	var instances []*ngmodels.AlertInstance
+	for _, uid := range ruleUIDs {
+		result, err := st.ListAlertInstances(ctx, &ngmodels.ListAlertInstancesQuery{RuleOrgID: orgID, RuleUID: uid})
+		if err != nil {
+			return fmt.Errorf("failed to list alert instances: %w", err)
+		}
+		instances = append(instances, result...)
+	}
+	slices.SortFunc(instances, func(a, b *ngmodels.AlertInstance) int {
+		if r := strings.Compare(a.RuleUID, b.RuleUID); r != 0 {
+			return r
+		}
+		return strings.Compare(a.LabelsHash, b.LabelsHash)
+	})
+
+	export := apimodels.AlertInstanceStateExport{
+		Instances: make([]apimodels.AlertInstanceState, 0, len(instances)),
+	}
+	for _, instance := range instances {
+		export.Instances = append(export.Instances, state.AlertInstanceStateFromInstance(*instance))
+	}
+	b, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, b, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	logger.Infof("%s Exported the state of %d alert instances of organization %d to %s\n", color.GreenString("✔"), len(export.Instances), orgID, path)
+	return nil
+}
+
+// ImportAlertInstanceStates saves the state of the alert instances in a file created by ExportAlertInstanceStates to
+// the database. Grafana must be restarted to use the imported state.
+func ImportAlertInstanceStates(c utils.CommandLine, runner server.Runner) error {
+	path := c.Args().First()
+	if path == "" {
+		return errors.New("missing path of the file to import the alert instance states from")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var body apimodels.AlertInstanceStateImport
+	if err := json.Unmarshal(b, &body); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if body.RuleUIDMapping == nil {
+		body.RuleUIDMapping = make(map[string]string)
+	}
+	for _, m := range c.StringSlice("rule-uid-mapping") {
+		from, to, ok := strings.Cut(m, "=")
+		if !ok || from == "" || to == "" {
+			return fmt.Errorf("invalid rule UID mapping '%s', expected <exported rule UID>=<rule UID>", m)
+		}
+		body.RuleUIDMapping[from] = to
+	}
+
+	ctx := context.Background()
+	st := newStore(runner)
+	orgID := int64(c.Int("org-id"))
+
+	uids := make([]string, 0, len(body.Instances))
+	instances := make([]ngmodels.AlertInstance, 0, len(body.Instances))
+	for _, s := range body.Instances {
+		uid := s.RuleUID
+		if mapped, ok := body.RuleUIDMapping[uid]; ok {
+			uid = mapped
+		}
+		uids = append(uids, uid)
+		instances = append(instances, state.InstanceFromAlertInstanceState(orgID, s))
+	}
+	if len(instances) == 0 {
+		return errors.New("no alert instances to import")
+	}
+
+	rules, err := st.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{OrgID: orgID, RuleUIDs: uids})
+	if err != nil {
+		return fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	rulesByUID := make(map[string]*ngmodels.AlertRule, len(rules))
+	namespaceUIDs := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		rulesByUID[rule.UID] = rule
+		namespaceUIDs = append(namespaceUIDs, rule.NamespaceUID)
+	}
+	folderTitles, err := getFolderTitles(ctx, runner.SQLStore, orgID, namespaceUIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get folders: %w", err)
+	}
+
+	imported, skipped := state.PrepareInstancesForImport(st.Logger, orgID, instances, body.RuleUIDMapping, rulesByUID, folderTitles)
+	for _, instance := range imported {
+		if err := st.SaveAlertInstance(ctx, instance); err != nil {
+			return fmt.Errorf("failed to save alert instance: %w", err)
+		}
+	}
+
+	for _, s := range skipped {
+		logger.Warnf("Skipped alert instance %v of rule %s: %s\n", s.Instance.Labels, s.Instance.RuleUID, s.Reason)
+	}
+	logger.Infof("%s Imported the state of %d alert instances to organization %d\n", color.GreenString("✔"), len(imported), orgID)
+	logger.Info("Restart Grafana to use the imported state\n")
+	return nil
+}
+
+func newStore(runner server.Runner) *store.DBstore {
+	return &store.DBstore{
+		Cfg:            runner.Cfg.UnifiedAlerting,
+		FeatureToggles: runner.Features,
+		SQLStore:       runner.SQLStore,
+		Logger:         log.New("ngalert.dbstore"),
+	}
+}
+
+// getFolderTitles returns the full paths of the folders by UID, as used by the scheduler for the folder label.
+// The titles in the path are escaped in the same way as the full paths computed by the folder service.
+func getFolderTitles(ctx context.Context, sqlStore db.DB, orgID int64, uids []string) (map[string]string, error) {
+	result := make(map[string]string, len(uids))
+	if len(uids) == 0 {
+		return result, nil
+	}
+	type folderRow struct {
+		UID       string `xorm:"uid"`
+		ParentUID string `xorm:"parent_uid"`
+		Title     string `xorm:"title"`
+	}
+	folders := make(map[string]folderRow, len(uids))
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		// Load the folders and their ancestors, one level of the hierarchy at a time.
+		missing := uids
+		for depth := 0; len(missing) > 0 && depth <= folder.MaxNestedFolderDepth; depth++ {
+			var rows []folderRow
+			if err := sess.Table("folder").Cols("uid", "parent_uid", "title").Where("org_id = ?", orgID).In("uid", missing).Find(&rows); err != nil {
+				return err
+			}
+			missing = nil
+			for _, row := range rows {
+				folders[row.UID] = row
+			}
+			for _, row := range rows {
+				if _, ok := folders[row.ParentUID]; row.ParentUID != "" && !ok && !slices.Contains(missing, row.ParentUID) {
+					missing = append(missing, row.ParentUID)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, uid := range uids {
+		f, ok := folders[uid]
+		if !ok {
+			continue
+		}
+		path := []string{strings.ReplaceAll(f.Title, "/", "\\/")}
+		for f.ParentUID != "" {
+			if f, ok = folders[f.ParentUID]; !ok {
+				break
+			}
+			path = append([]string{strings.ReplaceAll(f.Title, "/", "\\/")}, path...)
+		}
+		result[uid] = strings.Join(path, "/")
+	}
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package alertingstate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/folderimpl"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationGetFolderTitles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	sqlStore := db.InitTestDB(t)
+	folderStore := folderimpl.ProvideStore(sqlStore)
+	orgID := int64(1)
+
+	for _, cmd := range []folder.CreateFolderCommand{
+		{OrgID: orgID, UID: "parent", Title: "Parent"},
+		{OrgID: orgID, UID: "child", Title: "Child/with slash", ParentUID: "parent"},
+		{OrgID: orgID, UID: "grandchild", Title: "Grandchild", ParentUID: "child"},
+		{OrgID: orgID + 1, UID: "other-org", Title: "Other org"},
+	} {
+		_, err := folderStore.Create(ctx, cmd)
+		require.NoError(t, err)
+	}
+
+	result, err := getFolderTitles(ctx, sqlStore, orgID, []string{"grandchild", "parent", "other-org", "unknown"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"parent":     "Parent",
+		"grandchild": `Parent/Child\/with slash/Grandchild`,
+	}, result)
+
+	// The full path must match the one computed by the folder service, which the scheduler uses for the folder label.
+	uid := "grandchild"
+	f, err := folderStore.Get(ctx, folder.GetFolderQuery{OrgID: orgID, UID: &uid, WithFullpath: true})
+	require.NoError(t, err)
+	assert.Equal(t, f.Fullpath, result[uid])
+}
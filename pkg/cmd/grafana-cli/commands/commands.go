@@ -6,6 +6,8 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/alertingrules"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/alertingstate"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
@@ -167,6 +169,78 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:  "alerting-state",
+		Usage: "Exports and imports the state of alert instances",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "export",
+				Usage:  "export <file>. Writes the state of the alert instances of an organization saved in the database to a file. Safe to execute multiple times.",
+				Action: runRunnerCommand(alertingstate.ExportAlertInstanceStates),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "The ID of the organization to export the state of",
+						Value: 1,
+					},
+					&cli.StringSliceFlag{
+						Name:  "rule-uid",
+						Usage: "Only export the state of the alert rules with these UIDs",
+					},
+				},
+			},
+			{
+				Name:   "import",
+				Usage:  "import <file>. Saves the state of the alert instances exported to a file to the database. Stop Grafana before importing, and start it again to use the imported state.",
+				Action: runRunnerCommand(alertingstate.ImportAlertInstanceStates),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "The ID of the organization to import the state to",
+						Value: 1,
+					},
+					&cli.StringSliceFlag{
+						Name:  "rule-uid-mapping",
+						Usage: "Imports the state of an exported alert rule to a rule with another UID, as <exported rule UID>=<rule UID>",
+					},
+				},
+			},
+		},
+	},
+	{
+		Name:  "alerting-rules",
+		Usage: "Converts alert rules from other systems to Grafana-managed alert rules",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "convert-prometheus",
+				Usage:  "convert-prometheus <file>... Converts the rule groups of Prometheus rule files to a Grafana alerting provisioning file, and reports the parts of the rules that cannot be converted. Nothing is saved to the database.",
+				Action: runRunnerCommand(alertingrules.ConvertPrometheusRules),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "datasource-uid",
+						Usage: "The UID of the Prometheus data source the converted rules query",
+					},
+					&cli.StringFlag{
+						Name:  "folder",
+						Usage: "The title of the folder of the converted rules in the provisioning file",
+					},
+					&cli.StringFlag{
+						Name:  "output",
+						Usage: "The path of the provisioning file to write",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Only report the parts of the rules that cannot be converted, without writing the provisioning file",
+					},
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "The ID of the organization of the converted rules",
+						Value: 1,
+					},
+				},
+			},
+		},
+	},
 	{
 		Name:  "user-manager",
 		Usage: "Runs different helpful user commands",
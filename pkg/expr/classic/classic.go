@@ -33,9 +33,15 @@ import (
 //	false OR true AND true
 //
 // then the outcome of ConditionsCmd is true.
+//
+// In the per_series mode, ConditionsCmd does the same for each label set of the series and
+// numbers of the conditions instead, and returns a number per label set. A condition that has
+// a single value without labels is compared for all label sets, and a condition that does not
+// have a value for a label set has no data for it.
 type ConditionsCmd struct {
 	Conditions []condition
 	RefID      string
+	Mode       ConditionsMode
 }
 
 // condition is a single condition in ConditionsCmd.
@@ -72,6 +78,13 @@ func (cmd *ConditionsCmd) NeedsVars() []string {
 func (cmd *ConditionsCmd) Execute(ctx context.Context, t time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
 	ctx, span := tracer.Start(ctx, "SSE.ExecuteClassicConditions")
 	defer span.End()
+	if cmd.Mode == ConditionsModePerSeries {
+		return cmd.executePerSeries(ctx, t, vars)
+	}
+	return cmd.executeSingle(ctx, t, vars)
+}
+
+func (cmd *ConditionsCmd) executeSingle(ctx context.Context, t time.Time, vars mathexp.Vars) (mathexp.Results, error) {
 	// isFiring and isNoData contains the outcome of ConditionsCmd, and is derived from the
 	// boolean comparison of isCondFiring and isCondNoData of all conditions in ConditionsCmd
 	var isFiring, isNoData bool
@@ -103,7 +116,15 @@ func (cmd *ConditionsCmd) Execute(ctx context.Context, t time.Time, vars mathexp
 	// Start to prepare the result of the ConditionsCmd. It contains a mathexp.Number
 	// that has a value of 1, 0, or nil, depending on whether the result is firing, normal,
 	// or no data; and a list of matches for all conditions
-	number := mathexp.NewNumber("", nil)
+	res := mathexp.Results{}
+	res.Values = append(res.Values, newOutcome(nil, isFiring, isNoData, matches))
+	return res, nil
+}
+
+// newOutcome returns a mathexp.Number that has a value of 1, 0, or nil, depending on whether
+// the outcome is firing, normal, or no data, and the matches as metadata.
+func newOutcome(labels data.Labels, isFiring, isNoData bool, matches []EvalMatch) mathexp.Number {
+	number := mathexp.NewNumber("", labels)
 	number.SetMeta(matches)
 
 	var v float64
@@ -118,12 +139,132 @@ func (cmd *ConditionsCmd) Execute(ctx context.Context, t time.Time, vars mathexp
 		// the default value of v is 0
 		number.SetValue(&v)
 	}
+	return number
+}
+
+// executePerSeries evaluates all conditions for each label set of their values.
+func (cmd *ConditionsCmd) executePerSeries(ctx context.Context, t time.Time, vars mathexp.Vars) (mathexp.Results, error) {
+	type condValues struct {
+		byLabels  map[data.Fingerprint]namedNumber
+		unlabeled *namedNumber
+	}
+
+	// labelSets contains the label sets of all conditions in the order they were first seen
+	var labelSets []data.Labels
+	seen := make(map[data.Fingerprint]struct{})
+
+	values := make([]condValues, len(cmd.Conditions))
+	for i, cond := range cmd.Conditions {
+		values[i].byLabels = make(map[data.Fingerprint]namedNumber)
+		for _, value := range vars[cond.InputRefID].Values {
+			if _, ok := value.(mathexp.NoData); ok {
+				continue
+			}
+			n, err := cond.reduce(value)
+			if err != nil {
+				return mathexp.Results{}, err
+			}
+			labels := n.number.GetLabels()
+			if len(labels) == 0 {
+				if values[i].unlabeled == nil {
+					values[i].unlabeled = &n
+				}
+				continue
+			}
+			fp := labels.Fingerprint()
+			values[i].byLabels[fp] = n
+			if _, ok := seen[fp]; !ok {
+				seen[fp] = struct{}{}
+				labelSets = append(labelSets, labels)
+			}
+		}
+	}
+
+	// Without labels there is nothing to evaluate per series
+	if len(labelSets) == 0 {
+		return cmd.executeSingle(ctx, t, vars)
+	}
 
 	res := mathexp.Results{}
-	res.Values = append(res.Values, number)
+	for _, labels := range labelSets {
+		fp := labels.Fingerprint()
+		var isFiring, isNoData bool
+		matches := make([]EvalMatch, 0)
+		for i, cond := range cmd.Conditions {
+			n, ok := values[i].byLabels[fp]
+			if !ok {
+				if values[i].unlabeled != nil {
+					n = *values[i].unlabeled
+				} else {
+					// The condition has no data for this label set
+					n = namedNumber{number: mathexp.NewNumber("no data", nil)}
+				}
+			}
+
+			isCondFiring := cond.Evaluator.Eval(n.number)
+			isCondNoData := n.number.GetFloat64Value() == nil
+			if isCondFiring {
+				matchLabels := n.number.GetLabels()
+				if matchLabels != nil {
+					matchLabels = matchLabels.Copy()
+				}
+				matches = append(matches, EvalMatch{
+					Value:     n.number.GetFloat64Value(),
+					Metric:    n.name,
+					Labels:    matchLabels,
+					Condition: i + 1,
+				})
+			} else if isCondNoData {
+				matches = append(matches, EvalMatch{
+					Metric:    "NoData",
+					Labels:    labels.Copy(),
+					Condition: i + 1,
+				})
+			}
+
+			if i == 0 {
+				isFiring = isCondFiring
+				isNoData = isCondNoData
+			} else {
+				isFiring = compareWithOperator(isFiring, isCondFiring, cond.Operator)
+				isNoData = compareWithOperator(isNoData, isCondNoData, cond.Operator)
+			}
+		}
+		res.Values = append(res.Values, newOutcome(labels.Copy(), isFiring, isNoData, matches))
+	}
 	return res, nil
 }
 
+// namedNumber is a value of a condition reduced to a number, and the name of the value.
+type namedNumber struct {
+	name   string
+	number mathexp.Number
+}
+
+// reduce reduces the value of the condition to a number.
+func (cond condition) reduce(value mathexp.Value) (namedNumber, error) {
+	switch v := value.(type) {
+	case mathexp.NoData:
+		// Reduce expressions return v.New(), however ConditionsCmds use the operator
+		// in the condition to determine if the outcome is no data. To keep this code as
+		// simple as possible we translate mathexp.NoData into a mathexp.Number with a
+		// nil value so number.GetFloat64Value() returns nil
+		number := mathexp.NewNumber("no data", nil)
+		number.SetValue(nil)
+		return namedNumber{number: number}, nil
+	case mathexp.Number:
+		var name string
+		if len(v.Frame.Fields) > 0 {
+			name = v.Frame.Fields[0].Name
+		}
+		return namedNumber{name: name, number: v}, nil
+	case mathexp.Series:
+		return namedNumber{name: v.GetName(), number: cond.Reducer.Reduce(v)}, nil
+	default:
+		return namedNumber{}, fmt.Errorf("can only reduce type series, got type %v", v.Type())
+	}
+}
+
 func (cmd *ConditionsCmd) executeCond(_ context.Context, _ time.Time, cond condition, vars mathexp.Vars) (bool, bool, []EvalMatch, error) {
 	// isCondFiring and isCondNoData contains the outcome of the condition in ConditionsCmd.
 	// The condition is firing if isCondFiring is true, and no data if isCondNoData is true.
@@ -158,29 +299,11 @@ func (cmd *ConditionsCmd) executeCond(_ context.Context, _ time.Time, cond condi
 	// Look at all values and compare them against the condition. The values can contain
 	// either no data, numbers, or time series.
 	for _, value := range data.Values {
-		var (
-			name   string
-			number mathexp.Number
-		)
-		switch v := value.(type) {
-		case mathexp.NoData:
-			// Reduce expressions return v.New(), however ConditionsCmds use the operator
-			// in the condition to determine if the outcome is no data. To keep this code as
-			// simple as possible we translate mathexp.NoData into a mathexp.Number with a
-			// nil value so number.GetFloat64Value() returns nil
-			number = mathexp.NewNumber("no data", nil)
-			number.SetValue(nil)
-		case mathexp.Number:
-			if len(v.Frame.Fields) > 0 {
-				name = v.Frame.Fields[0].Name
-			}
-			number = v
-		case mathexp.Series:
-			name = v.GetName()
-			number = cond.Reducer.Reduce(v)
-		default:
-			return false, false, nil, fmt.Errorf("can only reduce type series, got type %v", v.Type())
+		n, err := cond.reduce(value)
+		if err != nil {
+			return false, false, nil, err
 		}
+		name, number := n.name, n.number
 
 		isValueFiring := cond.Evaluator.Eval(number)
 		// If the value was either a mathexp.NoData, a mathexp.Number with a nil float64,
@@ -234,6 +357,8 @@ type EvalMatch struct {
 	Value  *float64    `json:"value"`
 	Metric string      `json:"metric"`
 	Labels data.Labels `json:"labels"`
+	// Condition is the position of the matched condition, starting at 1. It is only set in the per_series mode.
+	Condition int `json:"condition,omitempty"`
 }
 
 func (em EvalMatch) MarshalJSON() ([]byte, error) {
@@ -242,13 +367,15 @@ func (em EvalMatch) MarshalJSON() ([]byte, error) {
 		fs = strconv.FormatFloat(*em.Value, 'f', -1, 64)
 	}
 	return json.Marshal(struct {
-		Value  string      `json:"value"`
-		Metric string      `json:"metric"`
-		Labels data.Labels `json:"labels"`
+		Value     string      `json:"value"`
+		Metric    string      `json:"metric"`
+		Labels    data.Labels `json:"labels"`
+		Condition int         `json:"condition,omitempty"`
 	}{
 		fs,
 		em.Metric,
 		em.Labels,
+		em.Condition,
 	})
 }
 
@@ -275,6 +402,17 @@ const (
 	ConditionOperatorOr  ConditionOperatorType = "or"
 )
 
+// How the conditions are evaluated
+// +enum
+type ConditionsMode string
+
+const (
+	// Reduce the outcomes of all series to a single number
+	ConditionsModeSingle ConditionsMode = "single"
+	// Evaluate the conditions for each label set and return a number per series
+	ConditionsModePerSeries ConditionsMode = "per_series"
+)
+
 type ConditionOperatorJSON struct {
 	Type ConditionOperatorType `json:"type"`
 }
@@ -288,9 +426,15 @@ type ConditionReducerJSON struct {
 	// Params []any `json:"params"` (Unused)
 }
 
-func NewConditionCmd(refID string, ccj []ConditionJSON) (*ConditionsCmd, error) {
+func NewConditionCmd(refID string, ccj []ConditionJSON, mode ConditionsMode) (*ConditionsCmd, error) {
+	switch mode {
+	case "", ConditionsModeSingle, ConditionsModePerSeries:
+	default:
+		return nil, fmt.Errorf("classic conditions mode %s is not supported. Supported only: [%s,%s]", mode, ConditionsModeSingle, ConditionsModePerSeries)
+	}
 	c := &ConditionsCmd{
 		RefID: refID,
+		Mode:  mode,
 	}
 
 	var err error
@@ -333,5 +477,13 @@ func UnmarshalConditionsCmd(rawQuery map[string]any, refID string) (*ConditionsC
 	if err = json.Unmarshal(jsonFromM, &ccj); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal remarshaled classic condition body: %w", err)
 	}
-	return NewConditionCmd(refID, ccj)
+	var mode ConditionsMode
+	if rawMode, ok := rawQuery["mode"]; ok {
+		m, ok := rawMode.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected classic conditions mode to be a string, got %T", rawMode)
+		}
+		mode = ConditionsMode(m)
+	}
+	return NewConditionCmd(refID, ccj, mode)
 }
@@ -606,6 +606,121 @@ func TestConditionsCmd(t *testing.T) {
 	}
 }
 
+func TestConditionsCmdPerSeries(t *testing.T) {
+	hostA := data.Labels{"host": "a"}
+	hostB := data.Labels{"host": "b"}
+	outcome := func(labels data.Labels, f *float64, matches ...EvalMatch) mathexp.Number {
+		v := mathexp.NewNumber("", labels)
+		v.SetValue(f)
+		v.SetMeta(append([]EvalMatch{}, matches...))
+		return v
+	}
+	unlabeled := func(f float64) mathexp.Number {
+		n := mathexp.NewNumber("B", nil)
+		n.SetValue(&f)
+		return n
+	}
+
+	tests := []struct {
+		name     string
+		vars     mathexp.Vars
+		cmd      *ConditionsCmd
+		expected mathexp.Results
+	}{{
+		name: "each series is evaluated and a value without labels applies to all series",
+		vars: mathexp.Vars{
+			"A": newResults(
+				newSeriesWithLabels(hostA, util.Pointer(1.0), util.Pointer(5.0)),
+				newSeriesWithLabels(hostB, util.Pointer(1.0), util.Pointer(1.0)),
+			),
+			"B": newResults(unlabeled(3)),
+		},
+		cmd: &ConditionsCmd{
+			Mode: ConditionsModePerSeries,
+			Conditions: []condition{
+				{
+					InputRefID: "A",
+					Reducer:    reducer("max"),
+					Operator:   "and",
+					Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 2},
+				},
+				{
+					InputRefID: "B",
+					Reducer:    reducer("last"),
+					Operator:   "or",
+					Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 2},
+				},
+			},
+		},
+		expected: newResults(
+			outcome(hostA, util.Pointer(1.0),
+				EvalMatch{Value: util.Pointer(5.0), Labels: hostA, Condition: 1},
+				EvalMatch{Value: util.Pointer(3.0), Metric: "B", Condition: 2}),
+			outcome(hostB, util.Pointer(1.0),
+				EvalMatch{Value: util.Pointer(3.0), Metric: "B", Condition: 2}),
+		),
+	}, {
+		name: "a condition without a value for a series has no data for it",
+		vars: mathexp.Vars{
+			"A": newResults(
+				newSeriesWithLabels(hostA, util.Pointer(5.0)),
+				newSeriesWithLabels(hostB, util.Pointer(5.0)),
+			),
+			"B": newResults(newSeriesWithLabels(hostA, util.Pointer(5.0))),
+		},
+		cmd: &ConditionsCmd{
+			Mode: ConditionsModePerSeries,
+			Conditions: []condition{
+				{
+					InputRefID: "A",
+					Reducer:    reducer("last"),
+					Operator:   "and",
+					Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 2},
+				},
+				{
+					InputRefID: "B",
+					Reducer:    reducer("last"),
+					Operator:   "and",
+					Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 2},
+				},
+			},
+		},
+		expected: newResults(
+			outcome(hostA, util.Pointer(1.0),
+				EvalMatch{Value: util.Pointer(5.0), Labels: hostA, Condition: 1},
+				EvalMatch{Value: util.Pointer(5.0), Labels: hostA, Condition: 2}),
+			outcome(hostB, util.Pointer(0.0),
+				EvalMatch{Value: util.Pointer(5.0), Labels: hostB, Condition: 1},
+				EvalMatch{Metric: "NoData", Labels: hostB, Condition: 2}),
+		),
+	}, {
+		name: "series without labels are evaluated like the single mode",
+		vars: mathexp.Vars{
+			"A": newResults(newSeries(util.Pointer(5.0))),
+		},
+		cmd: &ConditionsCmd{
+			Mode: ConditionsModePerSeries,
+			Conditions: []condition{
+				{
+					InputRefID: "A",
+					Reducer:    reducer("last"),
+					Operator:   "and",
+					Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 2},
+				},
+			},
+		},
+		expected: newResults(outcome(nil, util.Pointer(1.0), EvalMatch{Value: util.Pointer(5.0)})),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := tt.cmd.Execute(context.Background(), time.Now(), tt.vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
+
 func TestUnmarshalConditionsCmd(t *testing.T) {
 	var tests = []struct {
 		name            string
@@ -692,6 +807,47 @@ func TestUnmarshalConditionsCmd(t *testing.T) {
 			},
 			needsVars: []string{"A"},
 		},
+		{
+			name: "per series mode",
+			rawJSON: `{
+				"conditions": [
+				  {
+					"evaluator": {
+					  "params": [
+						2
+					  ],
+					  "type": "gt"
+					},
+					"operator": {
+					  "type": "and"
+					},
+					"query": {
+					  "params": [
+						"A"
+					  ]
+					},
+					"reducer": {
+					  "params": [],
+					  "type": "avg"
+					},
+					"type": "query"
+				  }
+				],
+				"mode": "per_series"
+			}`,
+			expectedCommand: &ConditionsCmd{
+				Mode: ConditionsModePerSeries,
+				Conditions: []condition{
+					{
+						InputRefID: "A",
+						Reducer:    reducer("avg"),
+						Operator:   "and",
+						Evaluator:  &thresholdEvaluator{Type: "gt", Threshold: 2},
+					},
+				},
+			},
+			needsVars: []string{"A"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -708,3 +864,8 @@ func TestUnmarshalConditionsCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestNewConditionCmdMode(t *testing.T) {
+	_, err := NewConditionCmd("B", nil, "per_label")
+	require.Error(t, err)
+}
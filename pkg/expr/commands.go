@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -83,6 +84,7 @@ func (gm *MathCommand) Type() string {
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
 	Reducer      mathexp.ReducerID
+	ReducerArgs  *mathexp.ReducerArgs
 	VarToReduce  string
 	refID        string
 	seriesMapper mathexp.ReduceMapper
@@ -90,13 +92,19 @@ type ReduceCommand struct {
 
 // NewReduceCommand creates a new ReduceCMD.
 func NewReduceCommand(refID string, reducer mathexp.ReducerID, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
+	return NewReduceCommandWithArgs(refID, reducer, nil, varToReduce, mapper)
+}
+
+// NewReduceCommandWithArgs creates a new ReduceCMD for a reducer that can be parameterised, such as percentile.
+func NewReduceCommandWithArgs(refID string, reducer mathexp.ReducerID, args *mathexp.ReducerArgs, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	_, err := mathexp.GetSeriesReduceFunc(reducer, args)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ReduceCommand{
 		Reducer:      reducer,
+		ReducerArgs:  args,
 		VarToReduce:  varToReduce,
 		refID:        refID,
 		seriesMapper: mapper,
@@ -125,6 +133,24 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 	}
 	redFunc := mathexp.ReducerID(strings.ToLower(redString))
 
+	var args *mathexp.ReducerArgs
+	rawArgs, ok := rn.Query["reducerArgs"]
+	if ok {
+		switch a := rawArgs.(type) {
+		case map[string]any:
+			args = &mathexp.ReducerArgs{}
+			if rawPercentile, ok := a["percentile"]; ok {
+				percentile, ok := rawPercentile.(float64)
+				if !ok {
+					return nil, fmt.Errorf("reducer argument percentile must be a number, got %T", rawPercentile)
+				}
+				args.Percentile = &percentile
+			}
+		default:
+			return nil, fmt.Errorf("field reducerArgs must be an object, got %T for refId %v", a, rn.RefID)
+		}
+	}
+
 	var mapper mathexp.ReduceMapper = nil
 	settings, ok := rn.Query["settings"]
 	if ok {
@@ -154,7 +180,7 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", s, rn.RefID)
 		}
 	}
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+	return NewReduceCommandWithArgs(rn.RefID, redFunc, args, varToReduce, mapper)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -175,7 +201,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 	for i, val := range vars[gr.VarToReduce].Values {
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
+			num, err := v.ReduceWithArgs(gr.refID, gr.Reducer, gr.ReducerArgs, gr.seriesMapper)
 			if err != nil {
 				return newRes, err
 			}
@@ -325,6 +351,463 @@ func (gr *ResampleCommand) Type() string {
 	return TypeResample.String()
 }
 
+// AggregateCommand is an expression command that groups series or numbers by labels
+// and aggregates each group, such as a sum by cluster or the top 5 by service.
+type AggregateCommand struct {
+	Aggregation     mathexp.AggregationType
+	AggregationArgs *mathexp.AggregationArgs
+	By              []string
+	VarToAggregate  string
+	refID           string
+}
+
+// NewAggregateCommand creates a new AggregateCMD.
+func NewAggregateCommand(refID string, aggregation mathexp.AggregationType, by []string, args *mathexp.AggregationArgs, varToAggregate string) (*AggregateCommand, error) {
+	if err := mathexp.ValidateAggregation(aggregation, args); err != nil {
+		return nil, err
+	}
+
+	return &AggregateCommand{
+		Aggregation:     aggregation,
+		AggregationArgs: args,
+		By:              by,
+		VarToAggregate:  varToAggregate,
+		refID:           refID,
+	}, nil
+}
+
+// UnmarshalAggregateCommand creates an AggregateCMD from Grafana's frontend query.
+func UnmarshalAggregateCommand(rn *rawNode) (*AggregateCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("no expression ID is specified to aggregate. Must be a reference to an existing query or expression")
+	}
+	varToAggregate, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expression ID is expected to be a string, got %T", rawVar)
+	}
+	varToAggregate = strings.TrimPrefix(varToAggregate, "$")
+
+	rawAggregation, ok := rn.Query["aggregation"]
+	if !ok {
+		return nil, errors.New("no aggregation specified")
+	}
+	aggString, ok := rawAggregation.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected aggregation to be a string, got %T", rawAggregation)
+	}
+	aggregation := mathexp.AggregationType(strings.ToLower(aggString))
+
+	var by []string
+	if rawBy, ok := rn.Query["by"]; ok {
+		labels, ok := rawBy.([]any)
+		if !ok {
+			return nil, fmt.Errorf("field by must be a list of label names, got %T for refId %v", rawBy, rn.RefID)
+		}
+		for _, rawLabel := range labels {
+			label, ok := rawLabel.(string)
+			if !ok {
+				return nil, fmt.Errorf("field by must be a list of label names, got %T in the list for refId %v", rawLabel, rn.RefID)
+			}
+			by = append(by, label)
+		}
+	}
+
+	var args *mathexp.AggregationArgs
+	if rawArgs, ok := rn.Query["aggregationArgs"]; ok {
+		switch a := rawArgs.(type) {
+		case map[string]any:
+			args = &mathexp.AggregationArgs{}
+			if rawK, ok := a["k"]; ok {
+				k, ok := rawK.(float64)
+				if !ok || k != math.Trunc(k) {
+					return nil, fmt.Errorf("aggregation argument k must be an integer, got %v", rawK)
+				}
+				kInt := int(k)
+				args.K = &kInt
+			}
+			if rawLabel, ok := a["valueLabel"]; ok {
+				valueLabel, ok := rawLabel.(string)
+				if !ok {
+					return nil, fmt.Errorf("aggregation argument valueLabel must be a string, got %T", rawLabel)
+				}
+				args.ValueLabel = valueLabel
+			}
+		default:
+			return nil, fmt.Errorf("field aggregationArgs must be an object, got %T for refId %v", a, rn.RefID)
+		}
+	}
+
+	return NewAggregateCommand(rn.RefID, aggregation, by, args, varToAggregate)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ga *AggregateCommand) NeedsVars() []string {
+	return []string{ga.VarToAggregate}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ga *AggregateCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAggregate")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("aggregation", string(ga.Aggregation)))
+
+	vals, err := mathexp.Aggregate(ga.refID, vars[ga.VarToAggregate].Values, ga.Aggregation, ga.By, ga.AggregationArgs)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	return mathexp.Results{Values: vals}, nil
+}
+
+func (ga *AggregateCommand) Type() string {
+	return TypeAggregate.String()
+}
+
+// AnomalyCommand is an expression command that fits a baseline to each series and
+// scores how far its points deviate from it, or returns the baseline with its bands.
+type AnomalyCommand struct {
+	Options    mathexp.AnomalyOptions
+	VarToQuery string
+	refID      string
+}
+
+// NewAnomalyCommand creates a new AnomalyCMD.
+func NewAnomalyCommand(refID string, opts mathexp.AnomalyOptions, varToQuery string) (*AnomalyCommand, error) {
+	if err := mathexp.ValidateAnomalyOptions(opts); err != nil {
+		return nil, err
+	}
+
+	return &AnomalyCommand{
+		Options:    opts,
+		VarToQuery: varToQuery,
+		refID:      refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCMD from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("no expression ID is specified to detect anomalies. Must be a reference to an existing query or expression")
+	}
+	varToQuery, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expression ID is expected to be a string, got %T", rawVar)
+	}
+	varToQuery = strings.TrimPrefix(varToQuery, "$")
+
+	rawAlgorithm, ok := rn.Query["algorithm"]
+	if !ok {
+		return nil, errors.New("no anomaly detection algorithm specified")
+	}
+	algorithm, ok := rawAlgorithm.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected algorithm to be a string, got %T", rawAlgorithm)
+	}
+
+	seasonality := ""
+	if rawSeasonality, ok := rn.Query["seasonality"]; ok {
+		seasonality, ok = rawSeasonality.(string)
+		if !ok {
+			return nil, fmt.Errorf("field seasonality must be a duration string, got %T for refId %v", rawSeasonality, rn.RefID)
+		}
+	}
+
+	sensitivity := mathexp.DefaultAnomalySensitivity
+	if rawSensitivity, ok := rn.Query["sensitivity"]; ok {
+		sensitivity, ok = rawSensitivity.(float64)
+		if !ok {
+			return nil, fmt.Errorf("field sensitivity must be a number, got %T for refId %v", rawSensitivity, rn.RefID)
+		}
+	}
+
+	output := mathexp.AnomalyOutputScore
+	if rawOutput, ok := rn.Query["output"]; ok {
+		o, ok := rawOutput.(string)
+		if !ok {
+			return nil, fmt.Errorf("field output must be a string, got %T for refId %v", rawOutput, rn.RefID)
+		}
+		output = mathexp.AnomalyOutput(o)
+	}
+
+	var args *mathexp.AnomalyAlgorithmArgs
+	if rawArgs, ok := rn.Query["algorithmArgs"]; ok {
+		a, ok := rawArgs.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field algorithmArgs must be an object, got %T for refId %v", rawArgs, rn.RefID)
+		}
+		readArg := func(name string) (*float64, error) {
+			rawValue, ok := a[name]
+			if !ok {
+				return nil, nil
+			}
+			value, ok := rawValue.(float64)
+			if !ok {
+				return nil, fmt.Errorf("algorithm argument %s must be a number, got %T", name, rawValue)
+			}
+			return &value, nil
+		}
+		args = &mathexp.AnomalyAlgorithmArgs{}
+		var err error
+		if args.Alpha, err = readArg("alpha"); err != nil {
+			return nil, err
+		}
+		if args.Beta, err = readArg("beta"); err != nil {
+			return nil, err
+		}
+		if args.Gamma, err = readArg("gamma"); err != nil {
+			return nil, err
+		}
+	}
+
+	return newAnomalyCommand(rn.RefID, mathexp.AnomalyAlgorithm(strings.ToLower(algorithm)), seasonality, sensitivity, output, args, varToQuery)
+}
+
+// newAnomalyCommand parses the seasonality of the query before creating the AnomalyCMD.
+func newAnomalyCommand(refID string, algorithm mathexp.AnomalyAlgorithm, rawSeasonality string, sensitivity float64, output mathexp.AnomalyOutput, args *mathexp.AnomalyAlgorithmArgs, varToQuery string) (*AnomalyCommand, error) {
+	var seasonality time.Duration
+	if rawSeasonality != "" {
+		var err error
+		seasonality, err = gtime.ParseDuration(rawSeasonality)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "seasonality" duration field %q: %w`, rawSeasonality, err)
+		}
+	}
+	if output == "" {
+		output = mathexp.AnomalyOutputScore
+	}
+
+	return NewAnomalyCommand(refID, mathexp.AnomalyOptions{
+		Algorithm:   algorithm,
+		Seasonality: seasonality,
+		Sensitivity: sensitivity,
+		Output:      output,
+		Args:        args,
+	}, varToQuery)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AnomalyCommand) NeedsVars() []string {
+	return []string{ac.VarToQuery}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("algorithm", string(ac.Options.Algorithm)))
+
+	vals, err := mathexp.DetectAnomalies(ac.refID, vars[ac.VarToQuery].Values, ac.Options)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	return mathexp.Results{Values: vals}, nil
+}
+
+func (ac *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
+
+// ForecastCommand is an expression command that extrapolates each series and returns a number
+// per series, such as the predicted disk usage in a day or the time until a quota is exhausted.
+type ForecastCommand struct {
+	Options       mathexp.ForecastOptions
+	VarToForecast string
+	// Resample evenly spaces the points of the series before the forecast. It is nil when the series are not resampled.
+	Resample *ResampleCommand
+	refID    string
+}
+
+// NewForecastCommand creates a new ForecastCMD.
+func NewForecastCommand(refID string, opts mathexp.ForecastOptions, varToForecast string) (*ForecastCommand, error) {
+	if err := mathexp.ValidateForecastOptions(opts); err != nil {
+		return nil, err
+	}
+
+	return &ForecastCommand{
+		Options:       opts,
+		VarToForecast: varToForecast,
+		refID:         refID,
+	}, nil
+}
+
+// NewResampledForecastCommand creates a new ForecastCMD that resamples the series to the interval with the mean of
+// each interval before the forecast. The series are resampled over the window of the forecast, or over the time
+// range if the window is empty.
+func NewResampledForecastCommand(refID string, opts mathexp.ForecastOptions, rawInterval string, tr TimeRange, varToForecast string) (*ForecastCommand, error) {
+	fc, err := NewForecastCommand(refID, opts, varToForecast)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Window > 0 {
+		tr = RelativeTimeRange{From: -opts.Window, To: 0}
+	}
+	if tr == nil {
+		return nil, fmt.Errorf("resampling the series to forecast requires a window or a time range for refID %s", refID)
+	}
+	fc.Resample, err = NewResampleCommand(refID, rawInterval, varToForecast, mathexp.ReducerMean, mathexp.UpsamplerPad, tr)
+	if err != nil {
+		return nil, err
+	}
+	return fc, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCMD from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, errors.New("no expression ID is specified to forecast. Must be a reference to an existing query or expression")
+	}
+	varToForecast, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expression ID is expected to be a string, got %T", rawVar)
+	}
+	varToForecast = strings.TrimPrefix(varToForecast, "$")
+
+	rawMethod, ok := rn.Query["method"]
+	if !ok {
+		return nil, errors.New("no forecast method specified")
+	}
+	method, ok := rawMethod.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected method to be a string, got %T", rawMethod)
+	}
+
+	var output mathexp.ForecastOutput
+	if rawOutput, ok := rn.Query["output"]; ok {
+		o, ok := rawOutput.(string)
+		if !ok {
+			return nil, fmt.Errorf("field output must be a string, got %T for refId %v", rawOutput, rn.RefID)
+		}
+		output = mathexp.ForecastOutput(o)
+	}
+
+	durations := map[string]string{}
+	for _, field := range []string{"offset", "window", "resampleInterval"} {
+		rawDuration, ok := rn.Query[field]
+		if !ok {
+			continue
+		}
+		d, ok := rawDuration.(string)
+		if !ok {
+			return nil, fmt.Errorf("field %s must be a duration string, got %T for refId %v", field, rawDuration, rn.RefID)
+		}
+		durations[field] = d
+	}
+
+	var threshold *float64
+	if rawThreshold, ok := rn.Query["threshold"]; ok {
+		t, ok := rawThreshold.(float64)
+		if !ok {
+			return nil, fmt.Errorf("field threshold must be a number, got %T for refId %v", rawThreshold, rn.RefID)
+		}
+		threshold = &t
+	}
+
+	var args *mathexp.ForecastMethodArgs
+	if rawArgs, ok := rn.Query["methodArgs"]; ok {
+		a, ok := rawArgs.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field methodArgs must be an object, got %T for refId %v", rawArgs, rn.RefID)
+		}
+		readArg := func(name string) (*float64, error) {
+			rawValue, ok := a[name]
+			if !ok {
+				return nil, nil
+			}
+			value, ok := rawValue.(float64)
+			if !ok {
+				return nil, fmt.Errorf("method argument %s must be a number, got %T", name, rawValue)
+			}
+			return &value, nil
+		}
+		args = &mathexp.ForecastMethodArgs{}
+		var err error
+		if args.Alpha, err = readArg("alpha"); err != nil {
+			return nil, err
+		}
+		if args.Beta, err = readArg("beta"); err != nil {
+			return nil, err
+		}
+	}
+
+	return newForecastCommand(rn.RefID, mathexp.ForecastMethod(strings.ToLower(method)), output, durations["offset"], threshold, durations["window"], durations["resampleInterval"], args, rn.TimeRange, varToForecast)
+}
+
+// newForecastCommand parses the durations of the query before creating the ForecastCMD.
+func newForecastCommand(refID string, method mathexp.ForecastMethod, output mathexp.ForecastOutput, rawOffset string, threshold *float64, rawWindow string, rawResampleInterval string, args *mathexp.ForecastMethodArgs, tr TimeRange, varToForecast string) (*ForecastCommand, error) {
+	var offset, window time.Duration
+	var err error
+	if rawOffset != "" {
+		offset, err = gtime.ParseDuration(rawOffset)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse forecast "offset" duration field %q: %w`, rawOffset, err)
+		}
+	}
+	if rawWindow != "" {
+		window, err = gtime.ParseDuration(rawWindow)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse forecast "window" duration field %q: %w`, rawWindow, err)
+		}
+	}
+	if output == "" {
+		output = mathexp.ForecastOutputValue
+	}
+
+	opts := mathexp.ForecastOptions{
+		Method:    method,
+		Output:    output,
+		Offset:    offset,
+		Threshold: threshold,
+		Window:    window,
+		Args:      args,
+	}
+	if rawResampleInterval != "" {
+		return NewResampledForecastCommand(refID, opts, rawResampleInterval, tr, varToForecast)
+	}
+	return NewForecastCommand(refID, opts, varToForecast)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.VarToForecast}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (fc *ForecastCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteForecast")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("method", string(fc.Options.Method)), attribute.String("output", string(fc.Options.Output)))
+
+	vals := vars[fc.VarToForecast].Values
+	if fc.Resample != nil {
+		resampled, err := fc.Resample.Execute(ctx, now, vars, tracer)
+		if err != nil {
+			return mathexp.Results{}, err
+		}
+		vals = resampled.Values
+	}
+
+	vals, err := mathexp.Forecast(fc.refID, vals, now, fc.Options)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
+	return mathexp.Results{Values: vals}, nil
+}
+
+func (fc *ForecastCommand) Type() string {
+	return TypeForecast.String()
+}
+
 // CommandType is the type of the expression command.
 type CommandType int
 
@@ -343,6 +826,14 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAggregate is the CMDType for aggregating series or numbers by labels.
+	TypeAggregate
+	// TypeAnomaly is the CMDType for detecting anomalies in series.
+	TypeAnomaly
+	// TypeForecast is the CMDType for forecasting series.
+	TypeForecast
+	// TypeRecovery is the CMDType for a condition with a separate recovery condition.
+	TypeRecovery
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +850,14 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAggregate:
+		return "aggregate"
+	case TypeAnomaly:
+		return "anomaly"
+	case TypeForecast:
+		return "forecast"
+	case TypeRecovery:
+		return "recovery"
 	default:
 		return "unknown"
 	}
@@ -379,6 +878,14 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "aggregate":
+		return TypeAggregate, nil
+	case "anomaly":
+		return TypeAnomaly, nil
+	case "forecast":
+		return TypeForecast, nil
+	case "recovery":
+		return TypeRecovery, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -99,11 +99,70 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_ReducerArgs(t *testing.T) {
+	var tests = []struct {
+		name         string
+		query        string
+		isError      bool
+		expectedArgs *mathexp.ReducerArgs
+	}{
+		{
+			name:         "no arguments when reducerArgs is not specified",
+			query:        `{ "expression" : "$A", "reducer": "median" }`,
+			expectedArgs: nil,
+		},
+		{
+			name:         "percentile argument is read",
+			query:        `{ "expression" : "$A", "reducer": "percentile", "reducerArgs": { "percentile": 95 } }`,
+			expectedArgs: &mathexp.ReducerArgs{Percentile: util.Pointer(95.0)},
+		},
+		{
+			name:    "error when percentile argument is missing",
+			query:   `{ "expression" : "$A", "reducer": "percentile" }`,
+			isError: true,
+		},
+		{
+			name:    "error when percentile argument is not a number",
+			query:   `{ "expression" : "$A", "reducer": "percentile", "reducerArgs": { "percentile": "95" } }`,
+			isError: true,
+		},
+		{
+			name:    "error when reducerArgs is not object",
+			query:   `{ "expression" : "$A", "reducer": "percentile", "reducerArgs": 95 }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalReduceCommand(&rawNode{
+				RefID:      "A",
+				Query:      qmap,
+				QueryType:  "",
+				TimeRange:  RelativeTimeRange{},
+				DataSource: nil,
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NotNil(t, cmd)
+
+			require.Equal(t, test.expectedArgs, cmd.ReducerArgs)
+		})
+	}
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 
 	t.Run("when mapper is nil", func(t *testing.T) {
-		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
+		cmd, err := newRandomReduceCommand(varToReduce, nil)
 		require.NoError(t, err)
 
 		t.Run("should noop if Number", func(t *testing.T) {
@@ -161,7 +220,7 @@ func TestReduceExecute(t *testing.T) {
 		}
 
 		t.Run("drop all non numbers if mapper is DropNonNumber", func(t *testing.T) {
-			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, &mathexp.DropNonNumber{})
+			cmd, err := newRandomReduceCommand(varToReduce, &mathexp.DropNonNumber{})
 			require.NoError(t, err)
 			execute, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 			require.NoError(t, err)
@@ -169,7 +228,7 @@ func TestReduceExecute(t *testing.T) {
 		})
 
 		t.Run("replace all non numbers if mapper is ReplaceNonNumberWithValue", func(t *testing.T) {
-			cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, &mathexp.ReplaceNonNumberWithValue{Value: 1})
+			cmd, err := newRandomReduceCommand(varToReduce, &mathexp.ReplaceNonNumberWithValue{Value: 1})
 			require.NoError(t, err)
 			execute, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 			require.NoError(t, err)
@@ -192,7 +251,7 @@ func TestReduceExecute(t *testing.T) {
 				Values: noData,
 			},
 		}
-		cmd, err := NewReduceCommand(util.GenerateShortUID(), randomReduceFunc(), varToReduce, nil)
+		cmd, err := newRandomReduceCommand(varToReduce, nil)
 		require.NoError(t, err)
 		results, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
 		require.NoError(t, err)
@@ -215,6 +274,16 @@ func randomReduceFunc() mathexp.ReducerID {
 	return res[rand.Intn(len(res))]
 }
 
+// newRandomReduceCommand creates a reduce command with a random reducer, and a random percentile if the reducer is percentile.
+func newRandomReduceCommand(varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	reducer := randomReduceFunc()
+	var args *mathexp.ReducerArgs
+	if reducer == mathexp.ReducerPercentile {
+		args = &mathexp.ReducerArgs{Percentile: util.Pointer(rand.Float64() * 100)}
+	}
+	return NewReduceCommandWithArgs(util.GenerateShortUID(), reducer, args, varToReduce, mapper)
+}
+
 func TestResampleCommand_Execute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 	tr := RelativeTimeRange{
@@ -273,3 +342,476 @@ func TestResampleCommand_Execute(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func Test_UnmarshalAggregateCommand(t *testing.T) {
+	var tests = []struct {
+		name         string
+		query        string
+		isError      bool
+		expectedBy   []string
+		expectedArgs *mathexp.AggregationArgs
+	}{
+		{
+			name:       "by labels are read",
+			query:      `{ "expression" : "$A", "aggregation": "sum", "by": ["cluster", "service"] }`,
+			expectedBy: []string{"cluster", "service"},
+		},
+		{
+			name:         "k argument is read",
+			query:        `{ "expression" : "$A", "aggregation": "topk", "aggregationArgs": { "k": 3 } }`,
+			expectedArgs: &mathexp.AggregationArgs{K: util.Pointer(3)},
+		},
+		{
+			name:         "valueLabel argument is read",
+			query:        `{ "expression" : "$A", "aggregation": "count_values", "aggregationArgs": { "valueLabel": "code" } }`,
+			expectedArgs: &mathexp.AggregationArgs{ValueLabel: "code"},
+		},
+		{
+			name:    "error when aggregation is missing",
+			query:   `{ "expression" : "$A" }`,
+			isError: true,
+		},
+		{
+			name:    "error when aggregation is not supported",
+			query:   `{ "expression" : "$A", "aggregation": "stddev" }`,
+			isError: true,
+		},
+		{
+			name:    "error when by is not a list of strings",
+			query:   `{ "expression" : "$A", "aggregation": "sum", "by": [1] }`,
+			isError: true,
+		},
+		{
+			name:    "error when k is not an integer",
+			query:   `{ "expression" : "$A", "aggregation": "topk", "aggregationArgs": { "k": 1.5 } }`,
+			isError: true,
+		},
+		{
+			name:    "error when k is missing",
+			query:   `{ "expression" : "$A", "aggregation": "bottomk" }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalAggregateCommand(&rawNode{
+				RefID:      "B",
+				Query:      qmap,
+				QueryType:  "",
+				TimeRange:  RelativeTimeRange{},
+				DataSource: nil,
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NotNil(t, cmd)
+
+			require.Equal(t, "A", cmd.VarToAggregate)
+			require.Equal(t, test.expectedBy, cmd.By)
+			require.Equal(t, test.expectedArgs, cmd.AggregationArgs)
+		})
+	}
+}
+
+func TestAggregateCommand_Execute(t *testing.T) {
+	varToAggregate := util.GenerateShortUID()
+	cmd, err := NewAggregateCommand(util.GenerateShortUID(), mathexp.AggregationSum, []string{"cluster"}, nil, varToAggregate)
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name         string
+		vals         mathexp.Values
+		isError      bool
+		expectedLen  int
+		expectedType parse.ReturnType
+	}{
+		{
+			name: "should aggregate numbers by label",
+			vals: mathexp.Values{
+				mathexp.NewNumber("test", data.Labels{"cluster": "eu", "host": "a"}),
+				mathexp.NewNumber("test", data.Labels{"cluster": "eu", "host": "b"}),
+				mathexp.NewNumber("test", data.Labels{"cluster": "us", "host": "c"}),
+			},
+			expectedLen:  2,
+			expectedType: parse.TypeNumberSet,
+		},
+		{
+			name: "should aggregate series by label",
+			vals: mathexp.Values{
+				mathexp.NewSeries("test", data.Labels{"cluster": "eu", "host": "a"}, 10),
+				mathexp.NewSeries("test", data.Labels{"cluster": "eu", "host": "b"}, 10),
+			},
+			expectedLen:  1,
+			expectedType: parse.TypeSeriesSet,
+		},
+		{
+			name:         "should return NoData when input NoData",
+			vals:         mathexp.Values{mathexp.NoData{}},
+			expectedLen:  1,
+			expectedType: parse.TypeNoData,
+		},
+		{
+			name:    "should return error when input Scalar",
+			vals:    mathexp.Values{mathexp.NewScalar("test", util.Pointer(rand.Float64()))},
+			isError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+				varToAggregate: mathexp.Results{Values: test.vals},
+			}, tracing.InitializeTracerForTest())
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, result.Values, test.expectedLen)
+			for _, res := range result.Values {
+				require.Equal(t, test.expectedType, res.Type())
+			}
+		})
+	}
+}
+
+func Test_UnmarshalAnomalyCommand(t *testing.T) {
+	var tests = []struct {
+		name            string
+		query           string
+		isError         bool
+		expectedOptions mathexp.AnomalyOptions
+	}{
+		{
+			name:  "defaults are set",
+			query: `{ "expression" : "$A", "algorithm": "mad" }`,
+			expectedOptions: mathexp.AnomalyOptions{
+				Algorithm:   mathexp.AnomalyAlgorithmMAD,
+				Sensitivity: mathexp.DefaultAnomalySensitivity,
+				Output:      mathexp.AnomalyOutputScore,
+			},
+		},
+		{
+			name:  "all fields are read",
+			query: `{ "expression" : "$A", "algorithm": "holt_winters", "seasonality": "1d", "sensitivity": 2.5, "output": "bands", "algorithmArgs": { "alpha": 0.3, "gamma": 0.2 } }`,
+			expectedOptions: mathexp.AnomalyOptions{
+				Algorithm:   mathexp.AnomalyAlgorithmHoltWinters,
+				Seasonality: 24 * time.Hour,
+				Sensitivity: 2.5,
+				Output:      mathexp.AnomalyOutputBands,
+				Args:        &mathexp.AnomalyAlgorithmArgs{Alpha: util.Pointer(0.3), Gamma: util.Pointer(0.2)},
+			},
+		},
+		{
+			name:    "error when algorithm is missing",
+			query:   `{ "expression" : "$A" }`,
+			isError: true,
+		},
+		{
+			name:    "error when algorithm is not supported",
+			query:   `{ "expression" : "$A", "algorithm": "prophet" }`,
+			isError: true,
+		},
+		{
+			name:    "error when seasonality is not a duration",
+			query:   `{ "expression" : "$A", "algorithm": "mad", "seasonality": "daily" }`,
+			isError: true,
+		},
+		{
+			name:    "error when decomposition has no seasonality",
+			query:   `{ "expression" : "$A", "algorithm": "decomposition" }`,
+			isError: true,
+		},
+		{
+			name:    "error when sensitivity is not positive",
+			query:   `{ "expression" : "$A", "algorithm": "zscore", "sensitivity": 0 }`,
+			isError: true,
+		},
+		{
+			name:    "error when output is not supported",
+			query:   `{ "expression" : "$A", "algorithm": "zscore", "output": "alert" }`,
+			isError: true,
+		},
+		{
+			name:    "error when algorithm arguments are not numbers",
+			query:   `{ "expression" : "$A", "algorithm": "holt_winters", "algorithmArgs": { "alpha": "0.3" } }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalAnomalyCommand(&rawNode{
+				RefID:      "B",
+				Query:      qmap,
+				QueryType:  "",
+				TimeRange:  RelativeTimeRange{},
+				DataSource: nil,
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NotNil(t, cmd)
+
+			require.Equal(t, "A", cmd.VarToQuery)
+			require.Equal(t, test.expectedOptions, cmd.Options)
+		})
+	}
+}
+
+func TestAnomalyCommand_Execute(t *testing.T) {
+	varToQuery := util.GenerateShortUID()
+
+	var tests = []struct {
+		name         string
+		output       mathexp.AnomalyOutput
+		vals         mathexp.Values
+		isError      bool
+		expectedLen  int
+		expectedType parse.ReturnType
+	}{
+		{
+			name:   "should return a score per series",
+			output: mathexp.AnomalyOutputScore,
+			vals: mathexp.Values{
+				mathexp.NewSeries("test", data.Labels{"host": "a"}, 10),
+				mathexp.NewSeries("test", data.Labels{"host": "b"}, 10),
+			},
+			expectedLen:  2,
+			expectedType: parse.TypeNumberSet,
+		},
+		{
+			name:   "should return three bands per series",
+			output: mathexp.AnomalyOutputBands,
+			vals: mathexp.Values{
+				mathexp.NewSeries("test", data.Labels{"host": "a"}, 10),
+			},
+			expectedLen:  3,
+			expectedType: parse.TypeSeriesSet,
+		},
+		{
+			name:         "should return NoData when input NoData",
+			output:       mathexp.AnomalyOutputScore,
+			vals:         mathexp.Values{mathexp.NoData{}},
+			expectedLen:  1,
+			expectedType: parse.TypeNoData,
+		},
+		{
+			name:    "should return error when input Number",
+			output:  mathexp.AnomalyOutputScore,
+			vals:    mathexp.Values{mathexp.NewNumber("test", data.Labels{"host": "a"})},
+			isError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cmd, err := NewAnomalyCommand(util.GenerateShortUID(), mathexp.AnomalyOptions{
+				Algorithm:   mathexp.AnomalyAlgorithmZScore,
+				Sensitivity: mathexp.DefaultAnomalySensitivity,
+				Output:      test.output,
+			}, varToQuery)
+			require.NoError(t, err)
+
+			result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+				varToQuery: mathexp.Results{Values: test.vals},
+			}, tracing.InitializeTracerForTest())
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, result.Values, test.expectedLen)
+			for _, res := range result.Values {
+				require.Equal(t, test.expectedType, res.Type())
+			}
+		})
+	}
+}
+
+func Test_UnmarshalForecastCommand(t *testing.T) {
+	var tests = []struct {
+		name            string
+		query           string
+		isError         bool
+		expectedOptions mathexp.ForecastOptions
+	}{
+		{
+			name:  "output defaults to value",
+			query: `{ "expression" : "$A", "method": "linear", "offset": "4h" }`,
+			expectedOptions: mathexp.ForecastOptions{
+				Method: mathexp.ForecastMethodLinear,
+				Output: mathexp.ForecastOutputValue,
+				Offset: 4 * time.Hour,
+			},
+		},
+		{
+			name:  "all fields are read",
+			query: `{ "expression" : "$A", "method": "holt", "output": "time_to_threshold", "threshold": 90, "window": "1d", "methodArgs": { "alpha": 0.3, "beta": 0.2 } }`,
+			expectedOptions: mathexp.ForecastOptions{
+				Method:    mathexp.ForecastMethodHolt,
+				Output:    mathexp.ForecastOutputTimeToThreshold,
+				Threshold: util.Pointer(90.0),
+				Window:    24 * time.Hour,
+				Args:      &mathexp.ForecastMethodArgs{Alpha: util.Pointer(0.3), Beta: util.Pointer(0.2)},
+			},
+		},
+		{
+			name:    "error when method is missing",
+			query:   `{ "expression" : "$A" }`,
+			isError: true,
+		},
+		{
+			name:    "error when resample interval is not a duration",
+			query:   `{ "expression" : "$A", "method": "holt", "window": "1d", "resampleInterval": "often" }`,
+			isError: true,
+		},
+		{
+			name:    "error when method is not supported",
+			query:   `{ "expression" : "$A", "method": "arima" }`,
+			isError: true,
+		},
+		{
+			name:    "error when offset is not a duration",
+			query:   `{ "expression" : "$A", "method": "linear", "offset": "soon" }`,
+			isError: true,
+		},
+		{
+			name:    "error when threshold is missing",
+			query:   `{ "expression" : "$A", "method": "linear", "output": "time_to_threshold" }`,
+			isError: true,
+		},
+		{
+			name:    "error when threshold is not a number",
+			query:   `{ "expression" : "$A", "method": "linear", "output": "time_to_threshold", "threshold": "90" }`,
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var qmap = make(map[string]any)
+			require.NoError(t, json.Unmarshal([]byte(test.query), &qmap))
+
+			cmd, err := UnmarshalForecastCommand(&rawNode{
+				RefID:      "B",
+				Query:      qmap,
+				QueryType:  "",
+				TimeRange:  RelativeTimeRange{},
+				DataSource: nil,
+			})
+
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NotNil(t, cmd)
+
+			require.Equal(t, "A", cmd.VarToForecast)
+			require.Equal(t, test.expectedOptions, cmd.Options)
+			require.Nil(t, cmd.Resample)
+		})
+	}
+
+	t.Run("resample interval resamples the series over the window", func(t *testing.T) {
+		var qmap = make(map[string]any)
+		require.NoError(t, json.Unmarshal([]byte(`{ "expression" : "$A", "method": "holt", "window": "1d", "resampleInterval": "5m" }`), &qmap))
+		cmd, err := UnmarshalForecastCommand(&rawNode{RefID: "B", Query: qmap, TimeRange: RelativeTimeRange{From: -time.Hour}})
+		require.NoError(t, err)
+		require.NotNil(t, cmd.Resample)
+		require.Equal(t, "A", cmd.Resample.VarToResample)
+		require.Equal(t, 5*time.Minute, cmd.Resample.Window)
+		require.Equal(t, mathexp.ReducerMean, cmd.Resample.Downsampler)
+		require.Equal(t, RelativeTimeRange{From: -24 * time.Hour}, cmd.Resample.TimeRange)
+	})
+}
+
+func TestForecastCommand_Execute(t *testing.T) {
+	varToForecast := util.GenerateShortUID()
+	cmd, err := NewForecastCommand(util.GenerateShortUID(), mathexp.ForecastOptions{
+		Method: mathexp.ForecastMethodLinear,
+		Output: mathexp.ForecastOutputValue,
+		Offset: time.Hour,
+	}, varToForecast)
+	require.NoError(t, err)
+
+	var tests = []struct {
+		name         string
+		vals         mathexp.Values
+		isError      bool
+		expectedLen  int
+		expectedType parse.ReturnType
+	}{
+		{
+			name: "should return a number per series",
+			vals: mathexp.Values{
+				mathexp.NewSeries("test", data.Labels{"host": "a"}, 10),
+				mathexp.NewSeries("test", data.Labels{"host": "b"}, 10),
+			},
+			expectedLen:  2,
+			expectedType: parse.TypeNumberSet,
+		},
+		{
+			name:         "should return NoData when input NoData",
+			vals:         mathexp.Values{mathexp.NoData{}},
+			expectedLen:  1,
+			expectedType: parse.TypeNoData,
+		},
+		{
+			name:    "should return error when input Number",
+			vals:    mathexp.Values{mathexp.NewNumber("test", data.Labels{"host": "a"})},
+			isError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+				varToForecast: mathexp.Results{Values: test.vals},
+			}, tracing.InitializeTracerForTest())
+			if test.isError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, result.Values, test.expectedLen)
+			for _, res := range result.Values {
+				require.Equal(t, test.expectedType, res.Type())
+			}
+		})
+	}
+}
+
+func TestForecastCommand_ExecuteResampled(t *testing.T) {
+	now := time.Unix(60, 0)
+	cmd, err := NewResampledForecastCommand("B", mathexp.ForecastOptions{
+		Method: mathexp.ForecastMethodHolt,
+		Output: mathexp.ForecastOutputValue,
+		Window: time.Minute,
+	}, "10s", nil, "A")
+	require.NoError(t, err)
+
+	// a series that grows by 10 every 10 seconds, with an extra point in the last interval
+	series := mathexp.NewSeries("A", nil, 7)
+	for i, p := range [][2]int64{{10, 10}, {20, 20}, {30, 30}, {40, 40}, {50, 50}, {55, 60}, {60, 60}} {
+		series.SetPoint(i, time.Unix(p[0], 0), util.Pointer(float64(p[1])))
+	}
+
+	result, err := cmd.Execute(context.Background(), now, mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{series}},
+	}, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	require.Len(t, result.Values, 1)
+	require.InDelta(t, 60, *result.Values[0].(mathexp.Number).GetFloat64Value(), 1e-9)
+}
@@ -0,0 +1,373 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// PipelineExplanation describes how a pipeline was executed: its nodes in execution order with
+// their dependencies, the shape of their inputs and outputs, and how long they took.
+type PipelineExplanation struct {
+	Nodes []NodeExplanation `json:"nodes"`
+}
+
+// NodeExplanation describes the execution of a single node of a pipeline.
+type NodeExplanation struct {
+	RefID    string `json:"refId"`
+	NodeType string `json:"nodeType"`
+	// Command is the type of the expression command, or the type of the data source of a query.
+	Command       string   `json:"command,omitempty"`
+	DatasourceUID string   `json:"datasourceUid,omitempty"`
+	DependsOn     []string `json:"dependsOn,omitempty"`
+	// Step is the position of the node in the execution order, starting at 1.
+	Step int `json:"step"`
+	// Executed is false when the node did not run, because the pipeline failed before or a dependency failed.
+	Executed bool `json:"executed"`
+	// Response is the shape of the frames returned by the data source, before they are converted.
+	Response *FramesShape  `json:"response,omitempty"`
+	Inputs   []ValuesShape `json:"inputs,omitempty"`
+	Output   ValuesShape   `json:"output"`
+	// DroppedLabelSets is the number of label sets of the inputs that no output has, such as
+	// series without a match in a math expression or series merged by an aggregation.
+	DroppedLabelSets int `json:"droppedLabelSets,omitempty"`
+	// ExecutionTime includes the time waiting for the data source.
+	ExecutionTime     time.Duration `json:"executionTime"`
+	DatasourceLatency time.Duration `json:"datasourceLatency,omitempty"`
+	Error             string        `json:"error,omitempty"`
+}
+
+// ValuesShape summarizes the values of a node.
+type ValuesShape struct {
+	RefID      string `json:"refId,omitempty"`
+	Series     int    `json:"series"`
+	Numbers    int    `json:"numbers"`
+	Scalars    int    `json:"scalars,omitempty"`
+	Tables     int    `json:"tables,omitempty"`
+	NoData     bool   `json:"noData,omitempty"`
+	Points     int    `json:"points"`
+	NullValues int    `json:"nullValues"`
+	LabelSets  int    `json:"labelSets"`
+}
+
+// FramesShape summarizes data frames.
+type FramesShape struct {
+	Frames     int `json:"frames"`
+	Rows       int `json:"rows"`
+	NullValues int `json:"nullValues"`
+}
+
+type explainKey struct{}
+
+// WithExplain returns a context in which Service.ExecutePipeline explains how it executes the pipeline.
+// The explanation is filled in the returned PipelineExplanation, and is added to the frames of the
+// response of each node as stats and a notice.
+func WithExplain(ctx context.Context) (context.Context, *PipelineExplanation) {
+	e := &explainer{explanation: &PipelineExplanation{}, nodes: map[string]int{}}
+	return context.WithValue(ctx, explainKey{}, e), e.explanation
+}
+
+func explainerFromContext(ctx context.Context) *explainer {
+	e, _ := ctx.Value(explainKey{}).(*explainer)
+	return e
+}
+
+// explainer records the explanation of a pipeline while it is executed. All its methods do nothing on a nil explainer.
+type explainer struct {
+	mu          sync.Mutex
+	explanation *PipelineExplanation
+	// nodes is the index of the explanation of each node by refID.
+	nodes map[string]int
+}
+
+func (e *explainer) start(pipeline DataPipeline) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.explanation.Nodes = make([]NodeExplanation, 0, len(pipeline))
+	for i, node := range pipeline {
+		n := NodeExplanation{
+			RefID:     node.RefID(),
+			NodeType:  node.NodeType().String(),
+			DependsOn: node.NeedsVars(),
+			Step:      i + 1,
+		}
+		switch t := node.(type) {
+		case *CMDNode:
+			n.Command = t.CMDType.String()
+		case *DSNode:
+			if t.datasource != nil {
+				n.Command = t.datasource.Type
+				n.DatasourceUID = t.datasource.UID
+			}
+		case *MLNode:
+			n.Command = t.command.Type()
+		}
+		if len(n.DependsOn) == 0 {
+			n.DependsOn = nil
+		}
+		e.nodes[n.RefID] = len(e.explanation.Nodes)
+		e.explanation.Nodes = append(e.explanation.Nodes, n)
+	}
+}
+
+// datasourceQueried records the latency of a data source request and the frames returned for each of its queries.
+func (e *explainer) datasourceQueried(refIDs []string, latency time.Duration, resp *backend.QueryDataResponse) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, refID := range refIDs {
+		i, ok := e.nodes[refID]
+		if !ok {
+			continue
+		}
+		e.explanation.Nodes[i].DatasourceLatency = latency
+		if resp == nil {
+			continue
+		}
+		if r, ok := resp.Responses[refID]; ok {
+			shape := framesShape(r.Frames)
+			e.explanation.Nodes[i].Response = &shape
+		}
+	}
+}
+
+// nodeExecuted records the inputs and the result of a node.
+func (e *explainer) nodeExecuted(node Node, vars mathexp.Vars, res mathexp.Results, executionTime time.Duration) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i, ok := e.nodes[node.RefID()]
+	if !ok {
+		return
+	}
+	n := &e.explanation.Nodes[i]
+	n.Executed = true
+	n.ExecutionTime = executionTime
+	n.Output = valuesShape("", res.Values)
+	if res.Error != nil {
+		n.Error = res.Error.Error()
+	}
+
+	inputLabels := map[string]struct{}{}
+	for _, refID := range node.NeedsVars() {
+		input, ok := vars[refID]
+		if !ok {
+			continue
+		}
+		n.Inputs = append(n.Inputs, valuesShape(refID, input.Values))
+		for _, l := range labelSets(input.Values) {
+			inputLabels[l] = struct{}{}
+		}
+	}
+	if node.NodeType() == TypeCMDNode && res.Error == nil {
+		for _, l := range labelSets(res.Values) {
+			delete(inputLabels, l)
+		}
+		n.DroppedLabelSets = len(inputLabels)
+	}
+}
+
+// nodeFailed records a node that did not run because of an error.
+func (e *explainer) nodeFailed(refID string, err error) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if i, ok := e.nodes[refID]; ok && err != nil {
+		e.explanation.Nodes[i].Error = err.Error()
+	}
+}
+
+func valuesShape(refID string, vals mathexp.Values) ValuesShape {
+	shape := ValuesShape{RefID: refID}
+	for _, val := range vals {
+		switch v := val.(type) {
+		case mathexp.Series:
+			shape.Series++
+			for i := 0; i < v.Len(); i++ {
+				_, f := v.GetPoint(i)
+				shape.Points++
+				if f == nil {
+					shape.NullValues++
+				}
+			}
+		case mathexp.Number:
+			shape.Numbers++
+			shape.Points++
+			if v.GetFloat64Value() == nil {
+				shape.NullValues++
+			}
+		case mathexp.Scalar:
+			shape.Scalars++
+			shape.Points++
+			if v.GetFloat64Value() == nil {
+				shape.NullValues++
+			}
+		case mathexp.TableData:
+			shape.Tables++
+			f := framesShape(data.Frames{v.Frame})
+			shape.Points += f.Rows
+			shape.NullValues += f.NullValues
+		case mathexp.NoData:
+			shape.NoData = true
+		}
+	}
+	shape.LabelSets = len(labelSets(vals))
+	return shape
+}
+
+// labelSets returns the distinct label sets of the series and numbers.
+func labelSets(vals mathexp.Values) []string {
+	seen := map[string]struct{}{}
+	var sets []string
+	for _, val := range vals {
+		switch val.(type) {
+		case mathexp.Series, mathexp.Number:
+		default:
+			continue
+		}
+		l := val.GetLabels().String()
+		if _, ok := seen[l]; ok {
+			continue
+		}
+		seen[l] = struct{}{}
+		sets = append(sets, l)
+	}
+	return sets
+}
+
+func framesShape(frames data.Frames) FramesShape {
+	shape := FramesShape{Frames: len(frames)}
+	for _, f := range frames {
+		if f == nil {
+			continue
+		}
+		shape.Rows += f.Rows()
+		for _, field := range f.Fields {
+			if !field.Nullable() {
+				continue
+			}
+			for i := 0; i < field.Len(); i++ {
+				if _, ok := field.ConcreteAt(i); !ok {
+					shape.NullValues++
+				}
+			}
+		}
+	}
+	return shape
+}
+
+func (s ValuesShape) String() string {
+	if s.NoData && s.Series+s.Numbers+s.Scalars+s.Tables == 0 {
+		return "no data"
+	}
+	var parts []string
+	for _, c := range []struct {
+		n    int
+		name string
+	}{{s.Series, "series"}, {s.Numbers, "numbers"}, {s.Scalars, "scalars"}, {s.Tables, "tables"}} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.name))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return fmt.Sprintf("%s with %d points, %d null values and %d label sets", strings.Join(parts, ", "), s.Points, s.NullValues, s.LabelSets)
+}
+
+func (n NodeExplanation) String() string {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "Step %d: %s %s", n.Step, n.NodeType, n.RefID)
+	if n.Command != "" {
+		fmt.Fprintf(&b, " (%s)", n.Command)
+	}
+	if len(n.DependsOn) > 0 {
+		fmt.Fprintf(&b, " depends on %s", strings.Join(n.DependsOn, ", "))
+	}
+	if !n.Executed {
+		b.WriteString(". Not executed")
+		if n.Error != "" {
+			fmt.Fprintf(&b, ": %s", n.Error)
+		}
+		return b.String()
+	}
+	fmt.Fprintf(&b, ". Executed in %s", n.ExecutionTime)
+	if n.DatasourceLatency > 0 {
+		fmt.Fprintf(&b, ", of which %s waiting for the data source", n.DatasourceLatency)
+	}
+	if n.Response != nil {
+		fmt.Fprintf(&b, ". Response: %d frames with %d rows and %d null values", n.Response.Frames, n.Response.Rows, n.Response.NullValues)
+	}
+	for _, in := range n.Inputs {
+		fmt.Fprintf(&b, ". Input %s: %s", in.RefID, in)
+	}
+	fmt.Fprintf(&b, ". Output: %s", n.Output)
+	if n.DroppedLabelSets > 0 {
+		fmt.Fprintf(&b, ". Dropped label sets: %d", n.DroppedLabelSets)
+	}
+	if n.Error != "" {
+		fmt.Fprintf(&b, ". Error: %s", n.Error)
+	}
+	return b.String()
+}
+
+// addExplanation adds the explanation of each node to the frames of its response: the explanation as
+// an info notice and the timings and the output shape as stats. Responses without frames are left unchanged.
+func addExplanation(res backend.Responses, explanation *PipelineExplanation) {
+	for _, n := range explanation.Nodes {
+		r, ok := res[n.RefID]
+		if !ok || len(r.Frames) == 0 {
+			continue
+		}
+		// frames can be shared between the results of nodes, so the explanation is added to a copy
+		f := *r.Frames[0]
+		frame := &f
+		r.Frames = append(data.Frames{frame}, r.Frames[1:]...)
+		meta := data.FrameMeta{}
+		if frame.Meta != nil {
+			meta = *frame.Meta
+		}
+		meta.Notices = append(append([]data.Notice{}, meta.Notices...), data.Notice{
+			Severity: data.NoticeSeverityInfo,
+			Text:     n.String(),
+		})
+		meta.Stats = append(append([]data.QueryStat{}, meta.Stats...), explanationStats(n)...)
+		frame.Meta = &meta
+		res[n.RefID] = r
+	}
+}
+
+func explanationStats(n NodeExplanation) []data.QueryStat {
+	stat := func(name, unit string, v float64) data.QueryStat {
+		return data.QueryStat{FieldConfig: data.FieldConfig{DisplayName: name, Unit: unit}, Value: v}
+	}
+	stats := []data.QueryStat{
+		stat("Expression pipeline step", "", float64(n.Step)),
+		stat("Execution time", "ms", float64(n.ExecutionTime)/float64(time.Millisecond)),
+	}
+	if n.DatasourceLatency > 0 {
+		stats = append(stats, stat("Data source latency", "ms", float64(n.DatasourceLatency)/float64(time.Millisecond)))
+	}
+	return append(stats,
+		stat("Output series", "", float64(n.Output.Series)),
+		stat("Output numbers", "", float64(n.Output.Numbers)),
+		stat("Output null values", "", float64(n.Output.NullValues)),
+		stat("Dropped label sets", "", float64(n.DroppedLabelSets)),
+	)
+}
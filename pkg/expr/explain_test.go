@@ -0,0 +1,158 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginconfig"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestExecutePipelineExplain(t *testing.T) {
+	series := func(host string, values ...*float64) *data.Frame {
+		return data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(2, 0)}),
+			data.NewField("value", data.Labels{"host": host}, values))
+	}
+	dsA := series("a", fp(1), nil)
+	dsB := series("b", fp(2), fp(3))
+
+	me := &mockEndpoint{
+		Responses: map[string]backend.DataResponse{
+			"A": {Frames: data.Frames{dsA, dsB}},
+		},
+	}
+
+	pCtxProvider := plugincontext.ProvideService(setting.NewCfg(), nil, &pluginstore.FakePluginStore{
+		PluginList: []pluginstore.Plugin{
+			{JSONData: plugins.JSONData{ID: "test"}},
+		},
+	}, &datafakes.FakeCacheService{}, &datafakes.FakeDataSourceService{}, nil, pluginconfig.NewFakePluginRequestConfigProvider())
+
+	features := featuremgmt.WithFeatures()
+	s := Service{
+		cfg:          setting.NewCfg(),
+		dataService:  me,
+		pCtxProvider: pCtxProvider,
+		features:     features,
+		tracer:       tracing.InitializeTracerForTest(),
+		metrics:      newMetrics(nil),
+		converter: &ResultConverter{
+			Features: features,
+			Tracer:   tracing.InitializeTracerForTest(),
+		},
+	}
+
+	queries := []Query{
+		{
+			RefID: "A",
+			DataSource: &datasources.DataSource{
+				OrgID: 1,
+				UID:   "test",
+				Type:  "test",
+			},
+			JSON:      json.RawMessage(`{ "datasource": { "uid": "1" }, "intervalMs": 1000, "maxDataPoints": 1000 }`),
+			TimeRange: AbsoluteTimeRange{},
+		},
+		{
+			RefID:      "B",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$A * 2" }`),
+		},
+		{
+			RefID:      "C",
+			DataSource: dataSourceModel(),
+			JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "aggregate", "expression": "$B", "aggregation": "sum" }`),
+		},
+	}
+
+	pl, err := s.BuildPipeline(&Request{Queries: queries, User: &user.SignedInUser{}})
+	require.NoError(t, err)
+
+	t.Run("responses are not changed without explain", func(t *testing.T) {
+		res, err := s.ExecutePipeline(context.Background(), time.Now(), pl)
+		require.NoError(t, err)
+		for _, r := range res.Responses {
+			for _, f := range r.Frames {
+				if f.Meta != nil {
+					require.Empty(t, f.Meta.Stats)
+					require.Empty(t, f.Meta.Notices)
+				}
+			}
+		}
+	})
+
+	ctx, explanation := WithExplain(context.Background())
+	res, err := s.ExecutePipeline(ctx, time.Now(), pl)
+	require.NoError(t, err)
+
+	t.Run("nodes are explained in execution order", func(t *testing.T) {
+		require.Len(t, explanation.Nodes, 3)
+
+		a := explanation.Nodes[0]
+		require.Equal(t, "A", a.RefID)
+		require.Equal(t, "Datasource", a.NodeType)
+		require.Equal(t, "test", a.Command)
+		require.Equal(t, "test", a.DatasourceUID)
+		require.True(t, a.Executed)
+		require.Equal(t, &FramesShape{Frames: 2, Rows: 4, NullValues: 1}, a.Response)
+		require.Equal(t, ValuesShape{Series: 2, Points: 4, NullValues: 1, LabelSets: 2}, a.Output)
+		require.Positive(t, a.DatasourceLatency)
+
+		b := explanation.Nodes[1]
+		require.Equal(t, "B", b.RefID)
+		require.Equal(t, "math", b.Command)
+		require.Equal(t, []string{"A"}, b.DependsOn)
+		require.Equal(t, []ValuesShape{{RefID: "A", Series: 2, Points: 4, NullValues: 1, LabelSets: 2}}, b.Inputs)
+		require.Equal(t, ValuesShape{Series: 2, Points: 4, NullValues: 1, LabelSets: 2}, b.Output)
+		require.Zero(t, b.DroppedLabelSets)
+		require.Zero(t, b.DatasourceLatency)
+
+		c := explanation.Nodes[2]
+		require.Equal(t, "aggregate", c.Command)
+		require.Equal(t, 1, c.Output.Series)
+		require.Equal(t, 2, c.DroppedLabelSets)
+	})
+
+	t.Run("explanation is added to the responses", func(t *testing.T) {
+		for i, refID := range []string{"A", "B", "C"} {
+			frames := res.Responses[refID].Frames
+			require.NotEmpty(t, frames)
+			meta := frames[0].Meta
+			require.NotNil(t, meta)
+			require.Len(t, meta.Notices, 1)
+			require.Equal(t, explanation.Nodes[i].String(), meta.Notices[0].Text)
+			require.NotEmpty(t, meta.Stats)
+		}
+		require.True(t, strings.HasPrefix(res.Responses["C"].Frames[0].Meta.Notices[0].Text, "Step 3: Expression C (aggregate) depends on B. Executed in"))
+
+		// the frames returned by the data source are not changed
+		require.Nil(t, dsA.Meta)
+	})
+}
+
+func TestAddExplanation(t *testing.T) {
+	res := backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("A")}},
+		"B": {},
+	}
+	addExplanation(res, &PipelineExplanation{Nodes: []NodeExplanation{{RefID: "A", Executed: true}, {RefID: "B", Executed: true}}})
+
+	require.Len(t, res["A"].Frames[0].Meta.Notices, 1)
+	require.Empty(t, res["B"].Frames, "frames are not added to responses without frames")
+}
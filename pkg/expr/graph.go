@@ -62,6 +62,7 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	explainer := explainerFromContext(c)
 
 	groupByDSFlag := s.features.IsEnabled(c, featuremgmt.FlagSseGroupByDatasource)
 	// Execute datasource nodes first, and grouped by datasource.
@@ -93,6 +94,7 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 						Error: MakeDependencyError(node.RefID(), neededVar),
 					}
 					vars[node.RefID()] = errResult
+					explainer.nodeFailed(node.RefID(), errResult.Error)
 					hasDepError = true
 					break
 				}
@@ -115,10 +117,12 @@ func (dp *DataPipeline) execute(c context.Context, now time.Time, s *Service) (m
 			return vars, makeUnexpectedNodeTypeError(node.RefID(), node.NodeType().String())
 		}
 
+		start := time.Now()
 		res, err := execNode.Execute(c, now, vars, s)
 		if err != nil {
 			res.Error = err
 		}
+		explainer.nodeExecuted(node, vars, res, time.Since(start))
 
 		vars[node.RefID()] = res
 	}
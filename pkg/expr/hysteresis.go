@@ -2,6 +2,7 @@ package expr
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 type Fingerprints map[data.Fingerprint]struct{}
@@ -91,6 +93,141 @@ func NewHysteresisCommand(refID string, referenceVar string, loadCondition Thres
 	}, nil
 }
 
+// RecoveryCommand generalizes HysteresisCommand to conditions that are not simple thresholds. It references two conditions:
+// - firing condition - is used when the metric is determined as not loaded, i.e. it was not firing during the previous evaluation.
+// - recovery condition - is used when the metric is determined as loaded. The metric keeps firing until the recovery condition
+// has a non-zero value for the same labels. If the recovery condition has no value for the labels, the firing condition is used.
+// Both conditions can be any expression that returns numbers, such as Math, classic conditions or SQL.
+// To determine whether a metric is loaded, the command uses LoadedDimensions the same way as HysteresisCommand does.
+// The result of the execution of the command is 0 or 1 for each metric of the firing condition.
+type RecoveryCommand struct {
+	RefID            string
+	ReferenceVar     string
+	RecoveryVar      string
+	LoadedDimensions Fingerprints
+}
+
+func NewRecoveryCommand(refID string, referenceVar string, recoveryVar string, l Fingerprints) (*RecoveryCommand, error) {
+	if referenceVar == recoveryVar {
+		return nil, fmt.Errorf("recovery condition must be different from the firing condition %s", referenceVar)
+	}
+	return &RecoveryCommand{
+		RefID:            refID,
+		ReferenceVar:     referenceVar,
+		RecoveryVar:      recoveryVar,
+		LoadedDimensions: l,
+	}, nil
+}
+
+// UnmarshalRecoveryCommand creates a RecoveryCommand from Grafana's frontend query.
+func UnmarshalRecoveryCommand(rn *rawNode, features featuremgmt.FeatureToggles) (*RecoveryCommand, error) {
+	q := RecoveryQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, &q); err != nil {
+		return nil, fmt.Errorf("failed to parse the recovery command: %w", err)
+	}
+	return newRecoveryCommand(rn.RefID, q, features)
+}
+
+// newRecoveryCommand checks the feature toggle and reads the loaded dimensions of the query before creating the RecoveryCommand.
+func newRecoveryCommand(refID string, q RecoveryQuery, features featuremgmt.FeatureToggles) (*RecoveryCommand, error) {
+	if !features.IsEnabledGlobally(featuremgmt.FlagRecoveryThreshold) {
+		return nil, fmt.Errorf("recovery expression requires the feature toggle %s", featuremgmt.FlagRecoveryThreshold)
+	}
+	referenceVar, err := getReferenceVar(q.Expression, refID)
+	if err != nil {
+		return nil, err
+	}
+	recoveryVar, err := getReferenceVar(q.RecoverWhen, refID)
+	if err != nil {
+		return nil, fmt.Errorf("no recovery condition specified: %w", err)
+	}
+	var d Fingerprints
+	if q.LoadedDimensions != nil {
+		d, err = FingerprintsFromFrame(q.LoadedDimensions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse loaded dimensions: %w", err)
+		}
+	}
+	return NewRecoveryCommand(refID, referenceVar, recoveryVar, d)
+}
+
+func (r *RecoveryCommand) NeedsVars() []string {
+	return []string{r.ReferenceVar, r.RecoveryVar}
+}
+
+func (r *RecoveryCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteRecovery")
+	defer span.End()
+
+	results := vars[r.ReferenceVar]
+
+	// shortcut for NoData
+	if results.IsNoData() {
+		return mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}}, nil
+	}
+
+	// the recovery condition is only evaluated for the metrics that are loaded.
+	var recoverAll *float64
+	recovery := make(map[data.Fingerprint]*float64)
+	if len(r.LoadedDimensions) > 0 {
+		for _, value := range vars[r.RecoveryVar].Values {
+			switch v := value.(type) {
+			case mathexp.Number:
+				recovery[v.GetLabels().Fingerprint()] = v.GetFloat64Value()
+			case mathexp.Scalar:
+				recoverAll = v.GetFloat64Value()
+			case mathexp.NoData, nil:
+				continue
+			default:
+				return mathexp.Results{}, fmt.Errorf("recovery condition %s must return numbers, got type %v", r.RecoveryVar, value.Type())
+			}
+		}
+	}
+
+	newVals := make(mathexp.Values, 0, len(results.Values))
+	for _, value := range results.Values {
+		var firing *float64
+		switch v := value.(type) {
+		case mathexp.Number:
+			firing = v.GetFloat64Value()
+		case mathexp.Scalar:
+			firing = v.GetFloat64Value()
+		default:
+			return mathexp.Results{}, fmt.Errorf("firing condition %s must return numbers, got type %v", r.ReferenceVar, value.Type())
+		}
+
+		labels := value.GetLabels()
+		n := mathexp.NewNumber(r.RefID, labels)
+		var recovered *float64
+		if _, ok := r.LoadedDimensions[labels.Fingerprint()]; ok {
+			if recovered, ok = recovery[labels.Fingerprint()]; !ok {
+				recovered = recoverAll
+			}
+		}
+		if recovered != nil {
+			// the metric keeps firing until the recovery condition is met
+			n.SetValue(boolToFloat(*recovered == 0))
+		} else if firing != nil {
+			// the metric is not loaded, or the recovery condition has no value for it, so the firing condition is used
+			n.SetValue(boolToFloat(*firing != 0))
+		}
+		newVals = append(newVals, n)
+	}
+	return mathexp.Results{Values: newVals}, nil
+}
+
+func (r *RecoveryCommand) Type() string {
+	return TypeRecovery.String()
+}
+
+func boolToFloat(b bool) *float64 {
+	var v float64
+	if b {
+		v = 1
+	}
+	return &v
+}
+
 // FingerprintsFromFrame converts data.Frame to Fingerprints.
 // The input data frame must have a single field of uint64 type.
 // Returns error if the input data frame has invalid format
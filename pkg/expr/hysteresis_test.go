@@ -11,6 +11,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestHysteresisExecute(t *testing.T) {
@@ -119,6 +121,176 @@ func TestHysteresisExecute(t *testing.T) {
 	}
 }
 
+func TestRecoveryExecute(t *testing.T) {
+	number := func(refID, label string, value *float64) mathexp.Number {
+		n := mathexp.NewNumber(refID, data.Labels{"label": label})
+		n.SetValue(value)
+		return n
+	}
+	fingerprint := func(label string) data.Fingerprint {
+		return data.Labels{"label": label}.Fingerprint()
+	}
+	scalar := func(value float64) mathexp.Scalar {
+		return mathexp.NewScalar("C", &value)
+	}
+
+	tracer := tracing.InitializeTracerForTest()
+
+	testCases := []struct {
+		name             string
+		loadedDimensions Fingerprints
+		firing           mathexp.Values
+		recovery         mathexp.Values
+		expected         mathexp.Values
+		expectedError    bool
+	}{
+		{
+			name:             "return NoData when no data",
+			loadedDimensions: Fingerprints{fingerprint("value1"): {}},
+			firing:           mathexp.Values{mathexp.NewNoData()},
+			recovery:         mathexp.Values{number("C", "value1", util.Pointer(1.0))},
+			expected:         mathexp.Values{mathexp.NewNoData()},
+		},
+		{
+			name:             "use only firing condition if no loaded metrics",
+			loadedDimensions: Fingerprints{},
+			firing: mathexp.Values{
+				number("B", "value1", util.Pointer(5.0)),
+				number("B", "value2", util.Pointer(0.0)),
+				number("B", "value3", nil),
+			},
+			recovery: mathexp.Values{
+				number("C", "value1", util.Pointer(1.0)),
+				number("C", "value2", util.Pointer(0.0)),
+			},
+			expected: mathexp.Values{
+				number("D", "value1", util.Pointer(1.0)),
+				number("D", "value2", util.Pointer(0.0)),
+				number("D", "value3", nil),
+			},
+		},
+		{
+			name: "loaded metrics keep firing until the recovery condition is met",
+			loadedDimensions: Fingerprints{
+				fingerprint("value2"): {},
+				fingerprint("value3"): {},
+			},
+			firing: mathexp.Values{
+				number("B", "value1", util.Pointer(0.0)),
+				number("B", "value2", util.Pointer(0.0)),
+				number("B", "value3", util.Pointer(0.0)),
+			},
+			recovery: mathexp.Values{
+				number("C", "value1", util.Pointer(1.0)),
+				number("C", "value2", util.Pointer(1.0)),
+				number("C", "value3", util.Pointer(0.0)),
+			},
+			expected: mathexp.Values{
+				number("D", "value1", util.Pointer(0.0)),
+				number("D", "value2", util.Pointer(0.0)),
+				number("D", "value3", util.Pointer(1.0)),
+			},
+		},
+		{
+			name: "loaded metrics without a recovery value use the firing condition",
+			loadedDimensions: Fingerprints{
+				fingerprint("value1"): {},
+				fingerprint("value2"): {},
+				fingerprint("value3"): {},
+			},
+			firing: mathexp.Values{
+				number("B", "value1", util.Pointer(0.0)),
+				number("B", "value2", util.Pointer(1.0)),
+				number("B", "value3", util.Pointer(0.0)),
+			},
+			recovery: mathexp.Values{
+				number("C", "value3", nil),
+			},
+			expected: mathexp.Values{
+				number("D", "value1", util.Pointer(0.0)),
+				number("D", "value2", util.Pointer(1.0)),
+				number("D", "value3", util.Pointer(0.0)),
+			},
+		},
+		{
+			name:             "scalar recovery condition applies to all loaded metrics",
+			loadedDimensions: Fingerprints{fingerprint("value1"): {}},
+			firing: mathexp.Values{
+				number("B", "value1", util.Pointer(1.0)),
+				number("B", "value2", util.Pointer(1.0)),
+			},
+			recovery: mathexp.Values{scalar(1)},
+			expected: mathexp.Values{
+				number("D", "value1", util.Pointer(0.0)),
+				number("D", "value2", util.Pointer(1.0)),
+			},
+		},
+		{
+			name:             "fail if the firing condition returns series",
+			loadedDimensions: Fingerprints{},
+			firing:           mathexp.Values{mathexp.NewSeries("B", nil, 0)},
+			expectedError:    true,
+		},
+		{
+			name:             "fail if the recovery condition returns series",
+			loadedDimensions: Fingerprints{fingerprint("value1"): {}},
+			firing:           mathexp.Values{number("B", "value1", util.Pointer(1.0))},
+			recovery:         mathexp.Values{mathexp.NewSeries("C", nil, 0)},
+			expectedError:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd, err := NewRecoveryCommand("D", "B", "C", tc.loadedDimensions)
+			require.NoError(t, err)
+
+			result, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+				"B": mathexp.Results{Values: tc.firing},
+				"C": mathexp.Results{Values: tc.recovery},
+			}, tracer)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, tc.expected, result.Values)
+		})
+	}
+}
+
+func TestUnmarshalRecoveryCommand(t *testing.T) {
+	features := featuremgmt.WithFeatures(featuremgmt.FlagRecoveryThreshold)
+
+	t.Run("reads the conditions and loaded dimensions", func(t *testing.T) {
+		query := map[string]any{"type": "recovery", "expression": "$B", "recoverWhen": "C"}
+		require.NoError(t, SetLoadedDimensionsToHysteresisCommand(query, Fingerprints{1: {}, 2: {}}))
+		raw, err := json.Marshal(query)
+		require.NoError(t, err)
+
+		cmd, err := UnmarshalRecoveryCommand(&rawNode{RefID: "D", QueryRaw: raw}, features)
+		require.NoError(t, err)
+		require.Equal(t, "B", cmd.ReferenceVar)
+		require.Equal(t, "C", cmd.RecoveryVar)
+		require.Equal(t, []string{"B", "C"}, cmd.NeedsVars())
+		require.Equal(t, Fingerprints{1: {}, 2: {}}, cmd.LoadedDimensions)
+	})
+
+	t.Run("fails if the recovery condition is missing", func(t *testing.T) {
+		_, err := UnmarshalRecoveryCommand(&rawNode{RefID: "D", QueryRaw: []byte(`{"type":"recovery","expression":"$B"}`)}, features)
+		require.Error(t, err)
+	})
+
+	t.Run("fails if the recovery condition is the firing condition", func(t *testing.T) {
+		_, err := UnmarshalRecoveryCommand(&rawNode{RefID: "D", QueryRaw: []byte(`{"type":"recovery","expression":"$B","recoverWhen":"$B"}`)}, features)
+		require.Error(t, err)
+	})
+
+	t.Run("fails if the feature is disabled", func(t *testing.T) {
+		_, err := UnmarshalRecoveryCommand(&rawNode{RefID: "D", QueryRaw: []byte(`{"type":"recovery","expression":"$B","recoverWhen":"$C"}`)}, featuremgmt.WithFeatures())
+		require.Error(t, err)
+	})
+}
+
 func TestLoadedDimensionsFromFrame(t *testing.T) {
 	correctType := &data.FrameMeta{Type: "fingerprints", TypeVersion: data.FrameTypeVersion{1, 0}}
 	testCases := []struct {
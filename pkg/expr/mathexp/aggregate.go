@@ -0,0 +1,365 @@
+package mathexp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// AggregationType is the function used to aggregate the values of a group of series or numbers.
+// +enum
+type AggregationType string
+
+const (
+	// The sum of the values of the group
+	AggregationSum AggregationType = "sum"
+	// The mean of the values of the group
+	AggregationAvg AggregationType = "avg"
+	// The minimum of the values of the group
+	AggregationMin AggregationType = "min"
+	// The maximum of the values of the group
+	AggregationMax AggregationType = "max"
+	// The number of values of the group
+	AggregationCount AggregationType = "count"
+	// The k largest values of the group, which keep their labels. Requires the k argument
+	AggregationTopK AggregationType = "topk"
+	// The k smallest values of the group, which keep their labels. Requires the k argument
+	AggregationBottomK AggregationType = "bottomk"
+	// The number of values of the group that have the same value, which is added as a label
+	AggregationCountValues AggregationType = "count_values"
+)
+
+// defaultValueLabel is the label that holds the value for count_values when no label is specified.
+const defaultValueLabel = "value"
+
+// AggregationArgs are the arguments of aggregations that are parameterised, such as topk.
+type AggregationArgs struct {
+	// The number of values to keep per group. Only valid when the aggregation is topk or bottomk
+	K *int `json:"k,omitempty"`
+
+	// The label that holds the counted value. Only valid when the aggregation is count_values, defaults to "value"
+	ValueLabel string `json:"valueLabel,omitempty"`
+}
+
+// GetSupportedAggregations returns collection of supported aggregation names
+func GetSupportedAggregations() []AggregationType {
+	return []AggregationType{
+		AggregationSum, AggregationAvg, AggregationMin, AggregationMax, AggregationCount,
+		AggregationTopK, AggregationBottomK, AggregationCountValues,
+	}
+}
+
+// ValidateAggregation returns an error if the aggregation is not supported or its arguments are invalid.
+func ValidateAggregation(agg AggregationType, args *AggregationArgs) error {
+	switch agg {
+	case AggregationSum, AggregationAvg, AggregationMin, AggregationMax, AggregationCount, AggregationCountValues:
+		return nil
+	case AggregationTopK, AggregationBottomK:
+		if args == nil || args.K == nil {
+			return fmt.Errorf("argument k must be specified for aggregation %s", agg)
+		}
+		if *args.K < 1 {
+			return fmt.Errorf("argument k of aggregation %s must be greater than 0, got %d", agg, *args.K)
+		}
+		return nil
+	default:
+		supported := make([]string, 0, len(GetSupportedAggregations()))
+		for _, a := range GetSupportedAggregations() {
+			supported = append(supported, string(a))
+		}
+		return fmt.Errorf("aggregation %s is not supported. Supported only: [%s]", agg, strings.Join(supported, ","))
+	}
+}
+
+type aggInput struct {
+	labels data.Labels
+	points map[int64]*float64
+}
+
+type aggGroup struct {
+	labels  data.Labels
+	members []int
+	times   []time.Time
+}
+
+type aggSample struct {
+	input int
+	f     float64
+}
+
+// aggOutput is the points of a single value of the result of an aggregation.
+type aggOutput struct {
+	labels data.Labels
+	points []seriesPoint
+}
+
+// Aggregate groups the values by the given labels and aggregates each group with the aggregation.
+// Values that do not have a label to group by are grouped as if the label was empty, and when no
+// labels are given all values are aggregated into a single group. The values must either be all
+// series or all numbers. Series are aggregated point by point at each time of the series of the group.
+// Null values are ignored. NoData values are skipped, and NoData is returned if there is nothing to aggregate.
+func Aggregate(refID string, vals Values, agg AggregationType, by []string, args *AggregationArgs) (Values, error) {
+	if err := ValidateAggregation(agg, args); err != nil {
+		return nil, err
+	}
+
+	var isSeries, isNumber bool
+	inputs := make([]aggInput, 0, len(vals))
+	var times [][]time.Time
+	for _, val := range vals {
+		in := aggInput{points: map[int64]*float64{}}
+		var inputTimes []time.Time
+		switch v := val.(type) {
+		case Series:
+			isSeries = true
+			in.labels = v.GetLabels()
+			for _, p := range sortedPoints(v) {
+				in.points[p.t.UnixNano()] = p.f
+				inputTimes = append(inputTimes, p.t)
+			}
+		case Number:
+			isNumber = true
+			in.labels = v.GetLabels()
+			in.points[0] = v.GetFloat64Value()
+			inputTimes = append(inputTimes, time.Unix(0, 0))
+		case NoData, nil:
+			continue
+		default:
+			return nil, fmt.Errorf("can only aggregate type series or number, got type %v", val.Type())
+		}
+		inputs = append(inputs, in)
+		times = append(times, inputTimes)
+	}
+	if isSeries && isNumber {
+		return nil, errors.New("can not aggregate a mix of series and numbers")
+	}
+	if len(inputs) == 0 {
+		return Values{NewNoData()}, nil
+	}
+
+	groups := make([]*aggGroup, 0)
+	groupsByKey := map[string]*aggGroup{}
+	for i, in := range inputs {
+		l := groupLabels(in.labels, by)
+		key := l.String()
+		g, ok := groupsByKey[key]
+		if !ok {
+			g = &aggGroup{labels: l}
+			groupsByKey[key] = g
+			groups = append(groups, g)
+		}
+		g.members = append(g.members, i)
+		g.times = append(g.times, times[i]...)
+	}
+
+	var outputs []*aggOutput
+	for _, g := range groups {
+		g.times = uniqueSortedTimes(g.times)
+		switch agg {
+		case AggregationTopK, AggregationBottomK:
+			outputs = append(outputs, aggregateK(g, inputs, agg == AggregationTopK, *args.K)...)
+		case AggregationCountValues:
+			valueLabel := defaultValueLabel
+			if args != nil && args.ValueLabel != "" {
+				valueLabel = args.ValueLabel
+			}
+			outputs = append(outputs, aggregateCountValues(g, inputs, valueLabel)...)
+		default:
+			outputs = append(outputs, aggregateGroup(g, inputs, aggregationFuncs[agg]))
+		}
+	}
+
+	newVals := make(Values, 0, len(outputs))
+	for _, out := range outputs {
+		if isSeries {
+			s := NewSeries(refID, out.labels, len(out.points))
+			for i, p := range out.points {
+				s.SetPoint(i, p.t, p.f)
+			}
+			newVals = append(newVals, s)
+			continue
+		}
+		n := NewNumber(refID, out.labels)
+		n.SetValue(out.points[0].f)
+		newVals = append(newVals, n)
+	}
+	if len(newVals) == 0 {
+		return Values{NewNoData()}, nil
+	}
+	return newVals, nil
+}
+
+var aggregationFuncs = map[AggregationType]func(vals []float64) float64{
+	AggregationSum: func(vals []float64) float64 {
+		var sum float64
+		for _, v := range vals {
+			sum += v
+		}
+		return sum
+	},
+	AggregationAvg: func(vals []float64) float64 {
+		var sum float64
+		for _, v := range vals {
+			sum += v
+		}
+		return sum / float64(len(vals))
+	},
+	AggregationMin: func(vals []float64) float64 {
+		m := vals[0]
+		for _, v := range vals[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	},
+	AggregationMax: func(vals []float64) float64 {
+		m := vals[0]
+		for _, v := range vals[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	},
+	AggregationCount: func(vals []float64) float64 {
+		return float64(len(vals))
+	},
+}
+
+// aggregateGroup returns a single value for the group, with the result of aggF at each time of the group.
+// If all values at a time are null, the point is null.
+func aggregateGroup(g *aggGroup, inputs []aggInput, aggF func(vals []float64) float64) *aggOutput {
+	out := &aggOutput{labels: g.labels, points: make([]seriesPoint, 0, len(g.times))}
+	for _, t := range g.times {
+		samples := groupSamples(g, inputs, t)
+		if len(samples) == 0 {
+			out.points = append(out.points, seriesPoint{t: t})
+			continue
+		}
+		vals := make([]float64, len(samples))
+		for i, s := range samples {
+			vals[i] = s.f
+		}
+		f := aggF(vals)
+		out.points = append(out.points, seriesPoint{t: t, f: &f})
+	}
+	return out
+}
+
+// aggregateK returns the members of the group that are within the k largest (or smallest) values
+// at any time of the group. Each value keeps its labels and only has points at the times it was selected.
+func aggregateK(g *aggGroup, inputs []aggInput, largest bool, k int) []*aggOutput {
+	selected := map[int]*aggOutput{}
+	for _, t := range g.times {
+		samples := groupSamples(g, inputs, t)
+		sort.SliceStable(samples, func(i, j int) bool {
+			a, b := samples[i].f, samples[j].f
+			if math.IsNaN(a) || math.IsNaN(b) {
+				return !math.IsNaN(a)
+			}
+			if largest {
+				return a > b
+			}
+			return a < b
+		})
+		if len(samples) > k {
+			samples = samples[:k]
+		}
+		for _, s := range samples {
+			out, ok := selected[s.input]
+			if !ok {
+				var l data.Labels
+				if inputs[s.input].labels != nil {
+					l = inputs[s.input].labels.Copy()
+				}
+				out = &aggOutput{labels: l}
+				selected[s.input] = out
+			}
+			f := s.f
+			out.points = append(out.points, seriesPoint{t: t, f: &f})
+		}
+	}
+	outputs := make([]*aggOutput, 0, len(selected))
+	for _, idx := range g.members {
+		if out, ok := selected[idx]; ok {
+			outputs = append(outputs, out)
+		}
+	}
+	return outputs
+}
+
+// aggregateCountValues returns a value for each distinct value of the group, labeled with the group labels
+// and the distinct value in valueLabel, with the number of times the value occurs at each time of the group.
+func aggregateCountValues(g *aggGroup, inputs []aggInput, valueLabel string) []*aggOutput {
+	var outputs []*aggOutput
+	byValue := map[string]*aggOutput{}
+	for _, t := range g.times {
+		counts := map[string]float64{}
+		var order []string
+		for _, s := range groupSamples(g, inputs, t) {
+			v := strconv.FormatFloat(s.f, 'f', -1, 64)
+			if _, ok := counts[v]; !ok {
+				order = append(order, v)
+			}
+			counts[v]++
+		}
+		for _, v := range order {
+			out, ok := byValue[v]
+			if !ok {
+				l := data.Labels{}
+				for name, value := range g.labels {
+					l[name] = value
+				}
+				l[valueLabel] = v
+				out = &aggOutput{labels: l}
+				byValue[v] = out
+				outputs = append(outputs, out)
+			}
+			f := counts[v]
+			out.points = append(out.points, seriesPoint{t: t, f: &f})
+		}
+	}
+	return outputs
+}
+
+// groupSamples returns the non-null values of the members of the group at the time.
+func groupSamples(g *aggGroup, inputs []aggInput, t time.Time) []aggSample {
+	samples := make([]aggSample, 0, len(g.members))
+	for _, idx := range g.members {
+		if f := inputs[idx].points[t.UnixNano()]; f != nil {
+			samples = append(samples, aggSample{input: idx, f: *f})
+		}
+	}
+	return samples
+}
+
+// groupLabels returns the subset of the labels that are in by, or nil if there are none.
+func groupLabels(l data.Labels, by []string) data.Labels {
+	var g data.Labels
+	for _, name := range by {
+		if v, ok := l[name]; ok {
+			if g == nil {
+				g = data.Labels{}
+			}
+			g[name] = v
+		}
+	}
+	return g
+}
+
+func uniqueSortedTimes(times []time.Time) []time.Time {
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+	unique := times[:0]
+	for i, t := range times {
+		if i > 0 && t.Equal(unique[len(unique)-1]) {
+			continue
+		}
+		unique = append(unique, t)
+	}
+	return unique
+}
@@ -0,0 +1,165 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var clusterNumbers = Values{
+	makeNumber("", data.Labels{"cluster": "eu", "host": "a"}, float64Pointer(1)),
+	makeNumber("", data.Labels{"cluster": "eu", "host": "b"}, float64Pointer(3)),
+	makeNumber("", data.Labels{"cluster": "us", "host": "c"}, float64Pointer(5)),
+	makeNumber("", data.Labels{"cluster": "us", "host": "d"}, nil),
+	makeNumber("", data.Labels{"host": "e"}, float64Pointer(3)),
+}
+
+var clusterSeries = Values{
+	makeSeries("", data.Labels{"cluster": "eu", "host": "a"},
+		tp{time.Unix(10, 0), float64Pointer(1)},
+		tp{time.Unix(20, 0), float64Pointer(4)}),
+	makeSeries("", data.Labels{"cluster": "eu", "host": "b"},
+		tp{time.Unix(20, 0), float64Pointer(2)},
+		tp{time.Unix(10, 0), float64Pointer(3)},
+		tp{time.Unix(30, 0), nil}),
+}
+
+func TestAggregate(t *testing.T) {
+	var tests = []struct {
+		name        string
+		vals        Values
+		aggregation AggregationType
+		by          []string
+		args        *AggregationArgs
+		errIs       require.ErrorAssertionFunc
+		expected    Values
+	}{
+		{
+			name:        "sum by cluster of numbers",
+			vals:        clusterNumbers,
+			aggregation: AggregationSum,
+			by:          []string{"cluster"},
+			errIs:       require.NoError,
+			expected: Values{
+				makeNumber("B", data.Labels{"cluster": "eu"}, float64Pointer(4)),
+				makeNumber("B", data.Labels{"cluster": "us"}, float64Pointer(5)),
+				makeNumber("B", nil, float64Pointer(3)),
+			},
+		},
+		{
+			name:        "count without labels aggregates everything",
+			vals:        clusterNumbers,
+			aggregation: AggregationCount,
+			errIs:       require.NoError,
+			expected: Values{
+				makeNumber("B", nil, float64Pointer(4)),
+			},
+		},
+		{
+			name:        "avg of a group with only null values is null",
+			vals:        Values{makeNumber("", data.Labels{"cluster": "us"}, nil)},
+			aggregation: AggregationAvg,
+			by:          []string{"cluster"},
+			errIs:       require.NoError,
+			expected: Values{
+				makeNumber("B", data.Labels{"cluster": "us"}, nil),
+			},
+		},
+		{
+			name:        "max by cluster of series aligns points by time",
+			vals:        clusterSeries,
+			aggregation: AggregationMax,
+			by:          []string{"cluster"},
+			errIs:       require.NoError,
+			expected: Values{
+				makeSeries("B", data.Labels{"cluster": "eu"},
+					tp{time.Unix(10, 0), float64Pointer(3)},
+					tp{time.Unix(20, 0), float64Pointer(4)},
+					tp{time.Unix(30, 0), nil}),
+			},
+		},
+		{
+			name:        "topk by cluster of numbers keeps the labels",
+			vals:        clusterNumbers,
+			aggregation: AggregationTopK,
+			by:          []string{"cluster"},
+			args:        &AggregationArgs{K: util.Pointer(1)},
+			errIs:       require.NoError,
+			expected: Values{
+				makeNumber("B", data.Labels{"cluster": "eu", "host": "b"}, float64Pointer(3)),
+				makeNumber("B", data.Labels{"cluster": "us", "host": "c"}, float64Pointer(5)),
+				makeNumber("B", data.Labels{"host": "e"}, float64Pointer(3)),
+			},
+		},
+		{
+			name:        "bottomk of series only keeps the selected points",
+			vals:        clusterSeries,
+			aggregation: AggregationBottomK,
+			args:        &AggregationArgs{K: util.Pointer(1)},
+			errIs:       require.NoError,
+			expected: Values{
+				makeSeries("B", data.Labels{"cluster": "eu", "host": "a"},
+					tp{time.Unix(10, 0), float64Pointer(1)}),
+				makeSeries("B", data.Labels{"cluster": "eu", "host": "b"},
+					tp{time.Unix(20, 0), float64Pointer(2)}),
+			},
+		},
+		{
+			name:        "count_values adds the value label",
+			vals:        clusterNumbers,
+			aggregation: AggregationCountValues,
+			args:        &AggregationArgs{ValueLabel: "status"},
+			errIs:       require.NoError,
+			expected: Values{
+				makeNumber("B", data.Labels{"status": "1"}, float64Pointer(1)),
+				makeNumber("B", data.Labels{"status": "3"}, float64Pointer(2)),
+				makeNumber("B", data.Labels{"status": "5"}, float64Pointer(1)),
+			},
+		},
+		{
+			name:        "no data is returned when there is nothing to aggregate",
+			vals:        Values{NewNoData()},
+			aggregation: AggregationSum,
+			errIs:       require.NoError,
+			expected:    Values{NewNoData()},
+		},
+		{
+			name:        "topk without k should error",
+			vals:        clusterNumbers,
+			aggregation: AggregationTopK,
+			errIs:       require.Error,
+		},
+		{
+			name:        "unknown aggregation should error",
+			vals:        clusterNumbers,
+			aggregation: "stddev",
+			errIs:       require.Error,
+		},
+		{
+			name:        "a mix of series and numbers should error",
+			vals:        Values{clusterNumbers[0], clusterSeries[0]},
+			aggregation: AggregationSum,
+			errIs:       require.Error,
+		},
+		{
+			name:        "scalar should error",
+			vals:        Values{NewScalar("", float64Pointer(1))},
+			aggregation: AggregationSum,
+			errIs:       require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Aggregate("B", tt.vals, tt.aggregation, tt.by, tt.args)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
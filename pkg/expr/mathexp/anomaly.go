@@ -0,0 +1,533 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// AnomalyAlgorithm is the method used to compute the expected value of each point of a series.
+// +enum
+type AnomalyAlgorithm string
+
+const (
+	// The median of the points in the same phase of the season, with the median absolute deviation as spread
+	AnomalyAlgorithmMAD AnomalyAlgorithm = "mad"
+	// The mean of the points in the same phase of the season, with the standard deviation as spread
+	AnomalyAlgorithmZScore AnomalyAlgorithm = "zscore"
+	// The one step ahead forecast of additive Holt-Winters exponential smoothing, with the standard deviation of the forecast errors as spread
+	AnomalyAlgorithmHoltWinters AnomalyAlgorithm = "holt_winters"
+	// The sum of the moving average trend and the seasonal component of a seasonal decomposition, with the median absolute deviation of the remainder as spread. Requires a seasonality
+	AnomalyAlgorithmDecomposition AnomalyAlgorithm = "decomposition"
+)
+
+// AnomalyOutput is what the anomaly detection returns for each series.
+// +enum
+type AnomalyOutput string
+
+const (
+	// A number with the score of the last point of the series
+	AnomalyOutputScore AnomalyOutput = "score"
+	// A series with the score of each point
+	AnomalyOutputScores AnomalyOutput = "scores"
+	// The baseline, lower and upper series of the expected values, labeled with anomaly_band
+	AnomalyOutputBands AnomalyOutput = "bands"
+)
+
+// AnomalyBandLabel is the label that tells apart the series of the bands output.
+const AnomalyBandLabel = "anomaly_band"
+
+// DefaultAnomalySensitivity is the number of deviations from the baseline that the bands are at by default.
+const DefaultAnomalySensitivity = 3.0
+
+const (
+	// madScale scales the median absolute deviation to be comparable to the standard deviation of normally distributed values.
+	madScale = 1.4826
+	// meanADScale scales the mean absolute deviation to be comparable to the standard deviation of normally distributed values.
+	meanADScale = 1.2533
+	// zeroSpread is the spread relative to the magnitude of the values below which the values are considered equal.
+	zeroSpread = 1e-9
+)
+
+// AnomalyAlgorithmArgs are the arguments of anomaly detection algorithms that are parameterised.
+type AnomalyAlgorithmArgs struct {
+	// The smoothing factor of the level, between 0 and 1. Only valid when the algorithm is holt_winters, defaults to 0.5
+	Alpha *float64 `json:"alpha,omitempty"`
+
+	// The smoothing factor of the trend, between 0 and 1. Only valid when the algorithm is holt_winters, defaults to 0.1
+	Beta *float64 `json:"beta,omitempty"`
+
+	// The smoothing factor of the seasonal component, between 0 and 1. Only valid when the algorithm is holt_winters, defaults to 0.1
+	Gamma *float64 `json:"gamma,omitempty"`
+}
+
+// AnomalyOptions configures DetectAnomalies.
+type AnomalyOptions struct {
+	Algorithm AnomalyAlgorithm
+	// Seasonality is the period of the seasonal pattern of the series. The series have no seasonality when it is zero.
+	Seasonality time.Duration
+	// Sensitivity is the number of deviations from the baseline that the lower and upper bands are at.
+	Sensitivity float64
+	Output      AnomalyOutput
+	Args        *AnomalyAlgorithmArgs
+}
+
+// GetSupportedAnomalyAlgorithms returns collection of supported anomaly detection algorithm names
+func GetSupportedAnomalyAlgorithms() []AnomalyAlgorithm {
+	return []AnomalyAlgorithm{
+		AnomalyAlgorithmMAD, AnomalyAlgorithmZScore, AnomalyAlgorithmHoltWinters, AnomalyAlgorithmDecomposition,
+	}
+}
+
+// ValidateAnomalyOptions returns an error if the algorithm or the output is not supported or the options are invalid.
+func ValidateAnomalyOptions(opts AnomalyOptions) error {
+	switch opts.Algorithm {
+	case AnomalyAlgorithmMAD, AnomalyAlgorithmZScore, AnomalyAlgorithmHoltWinters:
+	case AnomalyAlgorithmDecomposition:
+		if opts.Seasonality <= 0 {
+			return fmt.Errorf("algorithm %s requires a seasonality", opts.Algorithm)
+		}
+	default:
+		supported := make([]string, 0, len(GetSupportedAnomalyAlgorithms()))
+		for _, a := range GetSupportedAnomalyAlgorithms() {
+			supported = append(supported, string(a))
+		}
+		return fmt.Errorf("anomaly detection algorithm %s is not supported. Supported only: [%s]", opts.Algorithm, strings.Join(supported, ","))
+	}
+
+	switch opts.Output {
+	case AnomalyOutputScore, AnomalyOutputScores, AnomalyOutputBands:
+	default:
+		return fmt.Errorf("anomaly detection output %s is not supported. Supported only: [%s,%s,%s]", opts.Output, AnomalyOutputScore, AnomalyOutputScores, AnomalyOutputBands)
+	}
+
+	if opts.Seasonality < 0 {
+		return fmt.Errorf("seasonality must not be negative, got %s", opts.Seasonality)
+	}
+	if opts.Sensitivity <= 0 || math.IsNaN(opts.Sensitivity) || math.IsInf(opts.Sensitivity, 0) {
+		return fmt.Errorf("sensitivity must be a positive number, got %v", opts.Sensitivity)
+	}
+
+	if opts.Args != nil {
+		for name, v := range map[string]*float64{"alpha": opts.Args.Alpha, "beta": opts.Args.Beta, "gamma": opts.Args.Gamma} {
+			if v == nil {
+				continue
+			}
+			if opts.Algorithm != AnomalyAlgorithmHoltWinters {
+				return fmt.Errorf("argument %s is only valid for algorithm %s", name, AnomalyAlgorithmHoltWinters)
+			}
+			if *v <= 0 || *v > 1 {
+				return fmt.Errorf("argument %s must be greater than 0 and at most 1, got %v", name, *v)
+			}
+		}
+	}
+	return nil
+}
+
+// anomalyModel is the expected value and the spread around it of each point of a series.
+type anomalyModel struct {
+	baseline []float64
+	spread   []float64
+}
+
+// DetectAnomalies computes the expected values of each series with the algorithm, and returns for each
+// series the score of the points, or the bands of expected values, depending on the output.
+//
+// The score of a point is its distance from the baseline in number of deviations, so that a point is
+// outside of the bands when its score is greater than the sensitivity. Null and NaN points are ignored
+// to fit the model and have null scores, and series with too few points for the algorithm, such as
+// less than two seasons for decomposition, only have null scores. NoData values are skipped, and NoData
+// is returned if there are no series.
+func DetectAnomalies(refID string, vals Values, opts AnomalyOptions) (Values, error) {
+	if err := ValidateAnomalyOptions(opts); err != nil {
+		return nil, err
+	}
+
+	newVals := make(Values, 0, len(vals))
+	for _, val := range vals {
+		var s Series
+		switch v := val.(type) {
+		case Series:
+			s = v
+		case NoData, nil:
+			continue
+		default:
+			return nil, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+
+		points := sortedPoints(s)
+		// the model is fit to the points that have a value
+		var times []time.Time
+		var values []float64
+		for _, p := range points {
+			if p.f != nil && !math.IsNaN(*p.f) && !math.IsInf(*p.f, 0) {
+				times = append(times, p.t)
+				values = append(values, *p.f)
+			}
+		}
+
+		model, _ := fitAnomalyModel(times, values, opts)
+		newVals = append(newVals, anomalyOutput(refID, s.GetLabels(), points, values, model, opts)...)
+	}
+	if len(newVals) == 0 {
+		return Values{NewNoData()}, nil
+	}
+	return newVals, nil
+}
+
+// fitAnomalyModel returns the model of the values, or false if there are not enough values to fit it.
+// The seasonality is converted to a number of points with the median interval between the points.
+func fitAnomalyModel(times []time.Time, values []float64, opts AnomalyOptions) (anomalyModel, bool) {
+	if len(values) == 0 {
+		return anomalyModel{}, false
+	}
+	var interval time.Duration
+	period := 0
+	if opts.Seasonality > 0 {
+		interval = medianInterval(times)
+		if interval <= 0 {
+			return anomalyModel{}, false
+		}
+		period = int(math.Round(float64(opts.Seasonality) / float64(interval)))
+		if period < 2 {
+			// the seasonality is not longer than the interval between points, so each point is its own season
+			period = 0
+		}
+	}
+
+	switch opts.Algorithm {
+	case AnomalyAlgorithmMAD, AnomalyAlgorithmZScore:
+		return phaseModel(times, values, interval, period, opts.Algorithm), true
+	case AnomalyAlgorithmHoltWinters:
+		return holtWintersModel(values, period, opts.Args), true
+	default:
+		if period == 0 || len(values) < 2*period {
+			return anomalyModel{}, false
+		}
+		return decompositionModel(values, period), true
+	}
+}
+
+// medianInterval returns the median of the intervals between consecutive times.
+func medianInterval(times []time.Time) time.Duration {
+	if len(times) < 2 {
+		return 0
+	}
+	intervals := make([]float64, 0, len(times)-1)
+	for i := 1; i < len(times); i++ {
+		intervals = append(intervals, float64(times[i].Sub(times[i-1])))
+	}
+	return time.Duration(median(intervals))
+}
+
+// phaseModel groups the points by their phase in the season and returns the location and the spread of
+// the group of each point, for the mad and zscore algorithms. Without seasonality, all points are in one group.
+// Groups without spread, such as groups of a single point, use the spread of all points instead.
+func phaseModel(times []time.Time, values []float64, interval time.Duration, period int, algorithm AnomalyAlgorithm) anomalyModel {
+	stats := func(vals []float64) (float64, float64) {
+		if algorithm == AnomalyAlgorithmMAD {
+			m := median(vals)
+			return m, robustSpread(vals, m)
+		}
+		return mean(vals), stddev(vals)
+	}
+	scale := valueScale(values)
+
+	phases := make([]int64, len(times))
+	groups := map[int64][]float64{}
+	for i, t := range times {
+		if period > 0 {
+			// the phase is the number of intervals since the start of the season, counting seasons from the epoch
+			phases[i] = int64(math.Round(float64(t.UnixNano())/float64(interval))) % int64(period)
+		}
+		groups[phases[i]] = append(groups[phases[i]], values[i])
+	}
+	_, globalSpread := stats(values)
+
+	type groupStats struct{ location, spread float64 }
+	computed := make(map[int64]groupStats, len(groups))
+	for phase, vals := range groups {
+		location, spread := stats(vals)
+		if spread <= scale*zeroSpread {
+			spread = globalSpread
+		}
+		computed[phase] = groupStats{location: location, spread: spread}
+	}
+
+	model := anomalyModel{baseline: make([]float64, len(values)), spread: make([]float64, len(values))}
+	for i := range values {
+		g := computed[phases[i]]
+		model.baseline[i] = g.location
+		model.spread[i] = g.spread
+	}
+	return model
+}
+
+// holtWintersModel returns the one step ahead forecasts of additive Holt-Winters exponential smoothing,
+// or of Holt's linear trend method when there is no seasonality or less than two seasons of points.
+// The spread is the standard deviation of the forecast errors after the first season.
+func holtWintersModel(values []float64, period int, args *AnomalyAlgorithmArgs) anomalyModel {
+	alpha, beta, gamma := 0.5, 0.1, 0.1
+	if args != nil {
+		if args.Alpha != nil {
+			alpha = *args.Alpha
+		}
+		if args.Beta != nil {
+			beta = *args.Beta
+		}
+		if args.Gamma != nil {
+			gamma = *args.Gamma
+		}
+	}
+	if len(values) < 2*period {
+		period = 0
+	}
+
+	n := len(values)
+	forecast := make([]float64, n)
+	var level, trend float64
+	seasonal := make([]float64, max(period, 1))
+	start := 1
+	if period > 0 {
+		// initialize the level and seasonal components from the first season, and the trend from the difference with the second
+		level = mean(values[:period])
+		trend = (mean(values[period:2*period]) - level) / float64(period)
+		for i := 0; i < period; i++ {
+			seasonal[i] = values[i] - level
+			forecast[i] = level + seasonal[i]
+		}
+		start = period
+	} else {
+		level = values[0]
+		if n > 1 {
+			trend = values[1] - values[0]
+		}
+		forecast[0] = values[0]
+	}
+
+	for i := start; i < n; i++ {
+		s := 0.0
+		if period > 0 {
+			s = seasonal[i%period]
+		}
+		forecast[i] = level + trend + s
+		prevLevel := level
+		level = alpha*(values[i]-s) + (1-alpha)*(level+trend)
+		trend = beta*(level-prevLevel) + (1-beta)*trend
+		if period > 0 {
+			seasonal[i%period] = gamma*(values[i]-level) + (1-gamma)*s
+		}
+	}
+
+	errs := make([]float64, 0, n-start)
+	for i := start; i < n; i++ {
+		errs = append(errs, values[i]-forecast[i])
+	}
+	spread := 0.0
+	if len(errs) > 0 {
+		spread = math.Sqrt(meanSquare(errs))
+	}
+	return constantSpread(forecast, spread)
+}
+
+// decompositionModel decomposes the values into a centered moving average trend, a seasonal component that is
+// the mean of the detrended values of each phase, and a remainder. The baseline is the trend and the seasonal
+// component, and the spread is the scaled median absolute deviation of the remainder.
+func decompositionModel(values []float64, period int) anomalyModel {
+	n := len(values)
+	trend := make([]float64, n)
+	half := period / 2
+	for i := half; i < n-half; i++ {
+		if period%2 == 1 {
+			trend[i] = mean(values[i-half : i+half+1])
+			continue
+		}
+		// a 2 x period moving average, so that it is centered for even periods
+		sum := (values[i-half] + values[i+half]) / 2
+		for j := i - half + 1; j < i+half; j++ {
+			sum += values[j]
+		}
+		trend[i] = sum / float64(period)
+	}
+	// the trend is extended to the points at the ends that do not have a full window
+	for i := 0; i < half; i++ {
+		trend[i] = trend[half]
+		trend[n-1-i] = trend[n-1-half]
+	}
+
+	phaseSums := make([]float64, period)
+	phaseCounts := make([]float64, period)
+	for i, v := range values {
+		phaseSums[i%period] += v - trend[i]
+		phaseCounts[i%period]++
+	}
+	seasonal := make([]float64, period)
+	for i := range seasonal {
+		seasonal[i] = phaseSums[i] / phaseCounts[i]
+	}
+	// the seasonal component is centered around zero so that the level is part of the trend
+	seasonalMean := mean(seasonal)
+	for i := range seasonal {
+		seasonal[i] -= seasonalMean
+	}
+
+	baseline := make([]float64, n)
+	remainder := make([]float64, n)
+	for i, v := range values {
+		baseline[i] = trend[i] + seasonal[i%period]
+		remainder[i] = v - baseline[i]
+	}
+	return constantSpread(baseline, robustSpread(remainder, median(remainder)))
+}
+
+// robustSpread returns the median absolute deviation of the values from the median, scaled to be comparable
+// to the standard deviation. When more than half of the values are equal to the median, it returns the scaled
+// mean absolute deviation instead, so that the other values still have a spread.
+func robustSpread(values []float64, m float64) float64 {
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - m)
+	}
+	if spread := madScale * median(deviations); spread > valueScale(values)*zeroSpread {
+		return spread
+	}
+	return meanADScale * mean(deviations)
+}
+
+// valueScale returns the magnitude of the values, to tell spreads that are only floating point errors from zero.
+func valueScale(values []float64) float64 {
+	scale := 1.0
+	for _, v := range values {
+		scale = math.Max(scale, math.Abs(v))
+	}
+	return scale
+}
+
+func constantSpread(baseline []float64, spread float64) anomalyModel {
+	model := anomalyModel{baseline: baseline, spread: make([]float64, len(baseline))}
+	for i := range model.spread {
+		model.spread[i] = spread
+	}
+	return model
+}
+
+// anomalyScore returns the distance of the value from the baseline in number of deviations.
+// Without spread, values equal to the baseline have a score of 0 and other values an infinite score.
+func anomalyScore(v, baseline, spread float64) float64 {
+	d := math.Abs(v - baseline)
+	if spread <= valueScale([]float64{v, baseline})*zeroSpread {
+		if d <= valueScale([]float64{v, baseline})*zeroSpread {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return d / spread
+}
+
+// anomalyOutput returns the values of the output for a series, where values and the model are for the points
+// with a value, in the same order.
+func anomalyOutput(refID string, labels data.Labels, points []seriesPoint, values []float64, model anomalyModel, opts AnomalyOptions) Values {
+	type result struct {
+		baseline, lower, upper, score *float64
+	}
+	results := make([]result, len(points))
+	j := 0
+	for i, p := range points {
+		if p.f == nil || math.IsNaN(*p.f) || math.IsInf(*p.f, 0) || model.baseline == nil {
+			continue
+		}
+		b, s := model.baseline[j], model.spread[j]
+		lower, upper := b-opts.Sensitivity*s, b+opts.Sensitivity*s
+		score := anomalyScore(values[j], b, s)
+		results[i] = result{baseline: &b, lower: &lower, upper: &upper, score: &score}
+		j++
+	}
+
+	switch opts.Output {
+	case AnomalyOutputScore:
+		n := NewNumber(refID, copyLabels(labels))
+		for i := len(results) - 1; i >= 0; i-- {
+			if results[i].score != nil {
+				n.SetValue(results[i].score)
+				break
+			}
+		}
+		return Values{n}
+	case AnomalyOutputScores:
+		s := NewSeries(refID, copyLabels(labels), len(points))
+		for i, p := range points {
+			s.SetPoint(i, p.t, results[i].score)
+		}
+		return Values{s}
+	}
+
+	bands := make(Values, 0, 3)
+	for _, band := range []struct {
+		name  string
+		value func(r result) *float64
+	}{
+		{"baseline", func(r result) *float64 { return r.baseline }},
+		{"lower", func(r result) *float64 { return r.lower }},
+		{"upper", func(r result) *float64 { return r.upper }},
+	} {
+		l := copyLabels(labels)
+		if l == nil {
+			l = data.Labels{}
+		}
+		l[AnomalyBandLabel] = band.name
+		s := NewSeries(refID, l, len(points))
+		for i, p := range points {
+			s.SetPoint(i, p.t, band.value(results[i]))
+		}
+		bands = append(bands, s)
+	}
+	return bands
+}
+
+func copyLabels(l data.Labels) data.Labels {
+	if l == nil {
+		return nil
+	}
+	return l.Copy()
+}
+
+func mean(vals []float64) float64 {
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	return sum / float64(len(vals))
+}
+
+func meanSquare(vals []float64) float64 {
+	var sum float64
+	for _, v := range vals {
+		sum += v * v
+	}
+	return sum / float64(len(vals))
+}
+
+// stddev returns the population standard deviation of the values.
+func stddev(vals []float64) float64 {
+	m := mean(vals)
+	var sq float64
+	for _, v := range vals {
+		sq += (v - m) * (v - m)
+	}
+	return math.Sqrt(sq / float64(len(vals)))
+}
+
+func median(vals []float64) float64 {
+	sorted := make([]float64, len(vals))
+	copy(sorted, vals)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
@@ -0,0 +1,191 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func anomalySeries(name string, labels data.Labels, values ...*float64) Series {
+	points := make([]tp, len(values))
+	for i, v := range values {
+		points[i] = tp{time.Unix(int64(i+1)*10, 0), v}
+	}
+	return makeSeries(name, labels, points...)
+}
+
+func TestDetectAnomalies(t *testing.T) {
+	hostA := data.Labels{"host": "a"}
+	withBand := func(band string) data.Labels {
+		return data.Labels{"host": "a", AnomalyBandLabel: band}
+	}
+	twoPoints := anomalySeries("", hostA, float64Pointer(1), float64Pointer(3), nil)
+
+	var tests = []struct {
+		name     string
+		vals     Values
+		opts     AnomalyOptions
+		errIs    require.ErrorAssertionFunc
+		expected Values
+	}{
+		{
+			name:  "zscore bands are at sensitivity deviations from the mean",
+			vals:  Values{twoPoints},
+			opts:  AnomalyOptions{Algorithm: AnomalyAlgorithmZScore, Sensitivity: 3, Output: AnomalyOutputBands},
+			errIs: require.NoError,
+			expected: Values{
+				makeSeries("B", withBand("baseline"),
+					tp{time.Unix(10, 0), float64Pointer(2)},
+					tp{time.Unix(20, 0), float64Pointer(2)},
+					tp{time.Unix(30, 0), nil}),
+				makeSeries("B", withBand("lower"),
+					tp{time.Unix(10, 0), float64Pointer(-1)},
+					tp{time.Unix(20, 0), float64Pointer(-1)},
+					tp{time.Unix(30, 0), nil}),
+				makeSeries("B", withBand("upper"),
+					tp{time.Unix(10, 0), float64Pointer(5)},
+					tp{time.Unix(20, 0), float64Pointer(5)},
+					tp{time.Unix(30, 0), nil}),
+			},
+		},
+		{
+			name:  "zscore scores of each point",
+			vals:  Values{twoPoints},
+			opts:  AnomalyOptions{Algorithm: AnomalyAlgorithmZScore, Sensitivity: 3, Output: AnomalyOutputScores},
+			errIs: require.NoError,
+			expected: Values{
+				makeSeries("B", hostA,
+					tp{time.Unix(10, 0), float64Pointer(1)},
+					tp{time.Unix(20, 0), float64Pointer(1)},
+					tp{time.Unix(30, 0), nil}),
+			},
+		},
+		{
+			name:  "score is the score of the last point with a value",
+			vals:  Values{twoPoints},
+			opts:  AnomalyOptions{Algorithm: AnomalyAlgorithmZScore, Sensitivity: 3, Output: AnomalyOutputScore},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, float64Pointer(1)),
+			},
+		},
+		{
+			name: "decomposition baseline follows the seasonal pattern",
+			vals: Values{anomalySeries("", hostA,
+				float64Pointer(1), float64Pointer(3), float64Pointer(1), float64Pointer(3),
+				float64Pointer(1), float64Pointer(3))},
+			opts:  AnomalyOptions{Algorithm: AnomalyAlgorithmDecomposition, Seasonality: 20 * time.Second, Sensitivity: 3, Output: AnomalyOutputScores},
+			errIs: require.NoError,
+			expected: Values{anomalySeries("B", hostA,
+				float64Pointer(0), float64Pointer(0), float64Pointer(0), float64Pointer(0),
+				float64Pointer(0), float64Pointer(0))},
+		},
+		{
+			name:  "decomposition without two seasons of points has null scores",
+			vals:  Values{anomalySeries("", hostA, float64Pointer(1), float64Pointer(3))},
+			opts:  AnomalyOptions{Algorithm: AnomalyAlgorithmDecomposition, Seasonality: 20 * time.Second, Sensitivity: 3, Output: AnomalyOutputScore},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, nil),
+			},
+		},
+		{
+			name:     "no data is returned when there are no series",
+			vals:     Values{NewNoData()},
+			opts:     AnomalyOptions{Algorithm: AnomalyAlgorithmMAD, Sensitivity: 3, Output: AnomalyOutputScore},
+			errIs:    require.NoError,
+			expected: Values{NewNoData()},
+		},
+		{
+			name:  "numbers should error",
+			vals:  Values{makeNumber("", hostA, float64Pointer(1))},
+			opts:  AnomalyOptions{Algorithm: AnomalyAlgorithmMAD, Sensitivity: 3, Output: AnomalyOutputScore},
+			errIs: require.Error,
+		},
+		{
+			name:  "unknown algorithm should error",
+			vals:  Values{twoPoints},
+			opts:  AnomalyOptions{Algorithm: "prophet", Sensitivity: 3, Output: AnomalyOutputScore},
+			errIs: require.Error,
+		},
+		{
+			name:  "decomposition without seasonality should error",
+			vals:  Values{twoPoints},
+			opts:  AnomalyOptions{Algorithm: AnomalyAlgorithmDecomposition, Sensitivity: 3, Output: AnomalyOutputScore},
+			errIs: require.Error,
+		},
+		{
+			name:  "sensitivity must be positive",
+			vals:  Values{twoPoints},
+			opts:  AnomalyOptions{Algorithm: AnomalyAlgorithmMAD, Output: AnomalyOutputScore},
+			errIs: require.Error,
+		},
+		{
+			name: "smoothing factors are only valid for holt_winters",
+			vals: Values{twoPoints},
+			opts: AnomalyOptions{Algorithm: AnomalyAlgorithmMAD, Sensitivity: 3, Output: AnomalyOutputScore,
+				Args: &AnomalyAlgorithmArgs{Alpha: util.Pointer(0.5)}},
+			errIs: require.Error,
+		},
+		{
+			name: "smoothing factors must be at most 1",
+			vals: Values{twoPoints},
+			opts: AnomalyOptions{Algorithm: AnomalyAlgorithmHoltWinters, Sensitivity: 3, Output: AnomalyOutputScore,
+				Args: &AnomalyAlgorithmArgs{Gamma: util.Pointer(1.5)}},
+			errIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := DetectAnomalies("B", tt.vals, tt.opts)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestDetectAnomaliesScores(t *testing.T) {
+	lastScore := func(t *testing.T, s Series, opts AnomalyOptions) float64 {
+		t.Helper()
+		opts.Sensitivity = DefaultAnomalySensitivity
+		opts.Output = AnomalyOutputScore
+		res, err := DetectAnomalies("B", Values{s}, opts)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		return *res[0].(Number).GetFloat64Value()
+	}
+
+	t.Run("mad scores the distance from the median in median absolute deviations", func(t *testing.T) {
+		s := anomalySeries("", nil, float64Pointer(1), float64Pointer(2), float64Pointer(3), float64Pointer(2),
+			float64Pointer(1), float64Pointer(2), float64Pointer(100))
+		require.InDelta(t, 98/madScale, lastScore(t, s, AnomalyOptions{Algorithm: AnomalyAlgorithmMAD}), 1e-9)
+	})
+
+	t.Run("mad with seasonality compares points in the same phase", func(t *testing.T) {
+		s := anomalySeries("", nil, float64Pointer(1), float64Pointer(10), float64Pointer(1), float64Pointer(10),
+			float64Pointer(1), float64Pointer(10), float64Pointer(1), float64Pointer(50))
+		// more than half of the points of the phase are equal to the median, so the mean absolute deviation is used
+		require.InDelta(t, 40/(meanADScale*10), lastScore(t, s, AnomalyOptions{Algorithm: AnomalyAlgorithmMAD, Seasonality: 20 * time.Second}), 1e-9)
+	})
+
+	t.Run("holt_winters scores the forecast error in root mean square errors", func(t *testing.T) {
+		s := anomalySeries("", nil, float64Pointer(5), float64Pointer(5), float64Pointer(5), float64Pointer(5),
+			float64Pointer(5), float64Pointer(10))
+		require.InDelta(t, math.Sqrt(5), lastScore(t, s, AnomalyOptions{Algorithm: AnomalyAlgorithmHoltWinters}), 1e-9)
+	})
+
+	t.Run("points without spread that differ from the baseline have an infinite score", func(t *testing.T) {
+		s := anomalySeries("", nil, float64Pointer(5), float64Pointer(5), float64Pointer(5), float64Pointer(5),
+			float64Pointer(5), float64Pointer(5))
+		require.Equal(t, 0.0, lastScore(t, s, AnomalyOptions{Algorithm: AnomalyAlgorithmZScore}))
+		require.True(t, math.IsInf(anomalyScore(6, 5, 0), 1))
+	})
+}
@@ -38,7 +38,7 @@ type Vars map[string]Results
 
 // New creates a new expression tree
 func New(expr string, funcs ...map[string]parse.Func) (*Expr, error) {
-	funcs = append(funcs, builtins)
+	funcs = append(funcs, builtins, seriesBuiltins)
 	t, err := parse.Parse(expr, funcs...)
 	if err != nil {
 		return nil, err
@@ -214,28 +214,8 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 	aMatched := make([]bool, len(aResults.Values))
 	bMatched := make([]bool, len(bResults.Values))
 	collectDrops := func() {
-		check := func(v string, matchArray []bool, r *Results) {
-			for i, b := range matchArray {
-				if b {
-					continue
-				}
-				if e.Drops == nil {
-					e.Drops = make(map[string]map[string][]data.Labels)
-				}
-				if e.Drops[biNode.String()] == nil {
-					e.Drops[biNode.String()] = make(map[string][]data.Labels)
-				}
-
-				if r.Values[i].Type() == parse.TypeNoData {
-					continue
-				}
-
-				e.DropCount++
-				e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
-			}
-		}
-		check(aVar, aMatched, &aResults)
-		check(bVar, bMatched, &bResults)
+		e.collectDrops(biNode, aVar, aMatched, aResults)
+		e.collectDrops(biNode, bVar, bMatched, bResults)
 	}
 
 	aValueLen := len(aResults.Values)
@@ -306,6 +286,131 @@ func (e *State) union(aResults, bResults Results, biNode *parse.BinaryNode) []*U
 	return unions
 }
 
+// matchingUnion creates Union objects for a binary operation with label matching modifiers.
+// Items are matched by their labels listed in on(...), or by all their labels except those
+// listed in ignoring(...). Without group_left or group_right each item can only match one item
+// on the other side, and the result takes the matching labels. With group_left (group_right)
+// many items on the left (right) side can match one item on the other side, and the result takes
+// the labels of the "many" side plus the labels listed in group_left(...) (group_right(...)) from the "one" side.
+// Items that do not match are collected as drops.
+func (e *State) matchingUnion(aResults, bResults Results, biNode *parse.BinaryNode) ([]*Union, error) {
+	unions := []*Union{}
+	if len(aResults.Values) == 0 || len(bResults.Values) == 0 {
+		return unions, nil
+	}
+	aNoData := aResults.Values[0].Type() == parse.TypeNoData
+	bNoData := bResults.Values[0].Type() == parse.TypeNoData
+	if aNoData || bNoData {
+		return append(unions, &Union{A: aResults.Values[0], B: bResults.Values[0]}), nil
+	}
+
+	m := biNode.Matching
+	many, one := aResults, bResults
+	manyVar, oneVar := biNode.Args[0].String(), biNode.Args[1].String()
+	if m.Card == parse.MatchOneToMany {
+		many, one = bResults, aResults
+		manyVar, oneVar = oneVar, manyVar
+	}
+
+	oneByGroup := make(map[string]int, len(one.Values))
+	for i, v := range one.Values {
+		group := matchGroup(v.GetLabels(), m)
+		if j, ok := oneByGroup[group.String()]; ok {
+			return nil, fmt.Errorf("found duplicate series for the match group {%s} on the %s side of %q: {%s} and {%s}; many-to-many matching is not allowed, use group_left or group_right if one side is expected to have many items for a match group",
+				group, oneSide(m.Card), biNode.String(), one.Values[j].GetLabels(), v.GetLabels())
+		}
+		oneByGroup[group.String()] = i
+	}
+
+	manyMatched := make([]bool, len(many.Values))
+	oneMatched := make([]bool, len(one.Values))
+	manyByGroup := make(map[string]int, len(many.Values))
+	for i, v := range many.Values {
+		group := matchGroup(v.GetLabels(), m)
+		j, ok := oneByGroup[group.String()]
+		if !ok {
+			continue
+		}
+		if m.Card == parse.MatchOneToOne {
+			if k, dup := manyByGroup[group.String()]; dup {
+				return nil, fmt.Errorf("found duplicate series for the match group {%s} on the left side of %q: {%s} and {%s}; many-to-many matching is not allowed, use group_left or group_right if one side is expected to have many items for a match group",
+					group, biNode.String(), many.Values[k].GetLabels(), v.GetLabels())
+			}
+			manyByGroup[group.String()] = i
+		}
+
+		labels := group
+		if m.Card != parse.MatchOneToOne {
+			labels = v.GetLabels().Copy()
+			oneLabels := one.Values[j].GetLabels()
+			for _, l := range m.Include {
+				if val, ok := oneLabels[l]; ok {
+					labels[l] = val
+				} else {
+					delete(labels, l)
+				}
+			}
+		}
+		u := &Union{Labels: labels, A: v, B: one.Values[j]}
+		if m.Card == parse.MatchOneToMany {
+			u.A, u.B = u.B, u.A
+		}
+		unions = append(unions, u)
+		manyMatched[i] = true
+		oneMatched[j] = true
+	}
+
+	e.collectDrops(biNode, manyVar, manyMatched, many)
+	e.collectDrops(biNode, oneVar, oneMatched, one)
+	return unions, nil
+}
+
+// matchGroup returns the labels that are used to match an item with the given labels.
+func matchGroup(labels data.Labels, m *parse.VectorMatching) data.Labels {
+	group := data.Labels{}
+	if m.On {
+		for _, l := range m.MatchingLabels {
+			if v, ok := labels[l]; ok {
+				group[l] = v
+			}
+		}
+		return group
+	}
+	for k, v := range labels {
+		group[k] = v
+	}
+	for _, l := range m.MatchingLabels {
+		delete(group, l)
+	}
+	return group
+}
+
+// oneSide returns the side of the binary operation where each match group must be unique.
+func oneSide(card parse.MatchCardinality) string {
+	if card == parse.MatchOneToMany {
+		return "left"
+	}
+	return "right"
+}
+
+// collectDrops records the items of the results that were not matched in the binary operation
+// so they can be reported in a notice.
+func (e *State) collectDrops(biNode *parse.BinaryNode, v string, matched []bool, r Results) {
+	for i, b := range matched {
+		if b || r.Values[i].Type() == parse.TypeNoData {
+			continue
+		}
+		if e.Drops == nil {
+			e.Drops = make(map[string]map[string][]data.Labels)
+		}
+		if e.Drops[biNode.String()] == nil {
+			e.Drops[biNode.String()] = make(map[string][]data.Labels)
+		}
+		e.DropCount++
+		e.Drops[biNode.String()][v] = append(e.Drops[biNode.String()][v], r.Values[i].GetLabels())
+	}
+}
+
 func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	res := Results{Values: Values{}}
 	ar, err := e.walk(node.Args[0])
@@ -316,7 +421,15 @@ func (e *State) walkBinary(node *parse.BinaryNode) (Results, error) {
 	if err != nil {
 		return res, err
 	}
-	unions := e.union(ar, br, node)
+	var unions []*Union
+	if node.Matching != nil {
+		unions, err = e.matchingUnion(ar, br, node)
+		if err != nil {
+			return res, err
+		}
+	} else {
+		unions = e.union(ar, br, node)
+	}
 	for _, uni := range unions {
 		var value Value
 		switch at := uni.A.(type) {
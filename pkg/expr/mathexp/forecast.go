@@ -0,0 +1,242 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ForecastMethod is the method used to extrapolate a series.
+// +enum
+type ForecastMethod string
+
+const (
+	// A least squares linear regression over the points of the series, like predict_linear in Prometheus
+	ForecastMethodLinear ForecastMethod = "linear"
+	// Holt's linear exponential smoothing, which gives more weight to the recent points of the series
+	ForecastMethodHolt ForecastMethod = "holt"
+)
+
+// ForecastOutput is what the forecast returns for each series.
+// +enum
+type ForecastOutput string
+
+const (
+	// The predicted value of the series at the offset from the evaluation time
+	ForecastOutputValue ForecastOutput = "value"
+	// The number of seconds from the evaluation time until the predicted value reaches the threshold
+	ForecastOutputTimeToThreshold ForecastOutput = "time_to_threshold"
+)
+
+// ForecastMethodArgs are the arguments of forecast methods that are parameterised.
+type ForecastMethodArgs struct {
+	// The smoothing factor of the level, between 0 and 1. Only valid when the method is holt, defaults to 0.5
+	Alpha *float64 `json:"alpha,omitempty"`
+
+	// The smoothing factor of the trend, between 0 and 1. Only valid when the method is holt, defaults to 0.1
+	Beta *float64 `json:"beta,omitempty"`
+}
+
+// ForecastOptions configures Forecast.
+type ForecastOptions struct {
+	Method ForecastMethod
+	Output ForecastOutput
+	// Offset is the duration after the evaluation time at which the value is predicted.
+	Offset time.Duration
+	// Threshold is the value the time is predicted for. Required by the time_to_threshold output.
+	Threshold *float64
+	// Window limits the fit to the points in the window before the evaluation time. All points are used when it is zero.
+	Window time.Duration
+	Args   *ForecastMethodArgs
+}
+
+// ValidateForecastOptions returns an error if the method or the output is not supported or the options are invalid.
+func ValidateForecastOptions(opts ForecastOptions) error {
+	switch opts.Method {
+	case ForecastMethodLinear, ForecastMethodHolt:
+	default:
+		return fmt.Errorf("forecast method %s is not supported. Supported only: [%s,%s]", opts.Method, ForecastMethodLinear, ForecastMethodHolt)
+	}
+
+	switch opts.Output {
+	case ForecastOutputValue:
+	case ForecastOutputTimeToThreshold:
+		if opts.Threshold == nil {
+			return fmt.Errorf("forecast output %s requires a threshold", opts.Output)
+		}
+	default:
+		return fmt.Errorf("forecast output %s is not supported. Supported only: [%s,%s]", opts.Output, ForecastOutputValue, ForecastOutputTimeToThreshold)
+	}
+
+	if opts.Offset < 0 {
+		return fmt.Errorf("offset must not be negative, got %s", opts.Offset)
+	}
+	if opts.Window < 0 {
+		return fmt.Errorf("window must not be negative, got %s", opts.Window)
+	}
+	if opts.Threshold != nil && (math.IsNaN(*opts.Threshold) || math.IsInf(*opts.Threshold, 0)) {
+		return fmt.Errorf("threshold must be a finite number, got %v", *opts.Threshold)
+	}
+
+	if opts.Args != nil {
+		for _, arg := range []struct {
+			name  string
+			value *float64
+		}{{"alpha", opts.Args.Alpha}, {"beta", opts.Args.Beta}} {
+			if arg.value == nil {
+				continue
+			}
+			if opts.Method != ForecastMethodHolt {
+				return fmt.Errorf("argument %s is only valid for method %s", arg.name, ForecastMethodHolt)
+			}
+			if *arg.value <= 0 || *arg.value > 1 {
+				return fmt.Errorf("argument %s must be greater than 0 and at most 1, got %v", arg.name, *arg.value)
+			}
+		}
+	}
+	return nil
+}
+
+// trendLine is a line through the value of a series at a point in time.
+type trendLine struct {
+	at    time.Time
+	value float64
+	// slope is the change of the value per second.
+	slope float64
+}
+
+// Forecast extrapolates each series and returns a Number per series with the prediction.
+// The prediction is null when the series does not have enough points to fit a trend,
+// or when the output is time_to_threshold and the trend is flat. The time to the threshold
+// is zero when the last value of the series has already reached it in the direction of the trend,
+// and null when the trend has passed it at the evaluation time but the series has not reached it.
+func Forecast(refID string, vals Values, now time.Time, opts ForecastOptions) (Values, error) {
+	if err := ValidateForecastOptions(opts); err != nil {
+		return nil, err
+	}
+
+	newVals := make(Values, 0, len(vals))
+	for _, val := range vals {
+		var s Series
+		switch v := val.(type) {
+		case Series:
+			s = v
+		case NoData, nil:
+			continue
+		default:
+			return nil, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+
+		var times []time.Time
+		var values []float64
+		for _, p := range sortedPoints(s) {
+			if p.f == nil || math.IsNaN(*p.f) || math.IsInf(*p.f, 0) {
+				continue
+			}
+			if opts.Window > 0 && (p.t.Before(now.Add(-opts.Window)) || p.t.After(now)) {
+				continue
+			}
+			times = append(times, p.t)
+			values = append(values, *p.f)
+		}
+
+		n := NewNumber(refID, copyLabels(s.GetLabels()))
+		var line trendLine
+		var ok bool
+		switch opts.Method {
+		case ForecastMethodLinear:
+			line, ok = linearTrend(times, values, now)
+		case ForecastMethodHolt:
+			line, ok = holtTrend(times, values, opts.Args)
+		}
+		if ok {
+			n.SetValue(line.predict(now, opts, values[len(values)-1]))
+		}
+		newVals = append(newVals, n)
+	}
+	if len(newVals) == 0 {
+		return Values{NewNoData()}, nil
+	}
+	return newVals, nil
+}
+
+// predict returns the prediction of the line for the output. last is the last value of the series,
+// which tells whether the series has already reached the threshold.
+func (l trendLine) predict(now time.Time, opts ForecastOptions, last float64) *float64 {
+	var v float64
+	switch opts.Output {
+	case ForecastOutputValue:
+		v = l.value + l.slope*now.Add(opts.Offset).Sub(l.at).Seconds()
+	case ForecastOutputTimeToThreshold:
+		if l.slope == 0 {
+			return nil
+		}
+		if reached(last, l.slope, *opts.Threshold) {
+			v = 0
+			break
+		}
+		v = (*opts.Threshold-l.value)/l.slope + l.at.Sub(now).Seconds()
+		if v < 0 {
+			// The trend has passed the threshold but the series has not reached it.
+			return nil
+		}
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
+
+// reached returns true if the last value is at or past the threshold in the direction of the trend,
+// whether or not the series crossed it within the window.
+func reached(last, slope, threshold float64) bool {
+	return (slope > 0 && last >= threshold) || (slope < 0 && last <= threshold)
+}
+
+// linearTrend fits a least squares regression line to the points and returns it at now.
+func linearTrend(times []time.Time, values []float64, now time.Time) (trendLine, bool) {
+	if len(values) < 2 {
+		return trendLine{}, false
+	}
+	xs := make([]float64, len(times))
+	for i, t := range times {
+		xs[i] = t.Sub(now).Seconds()
+	}
+	xMean, yMean := mean(xs), mean(values)
+	var cov, variance float64
+	for i, x := range xs {
+		cov += (x - xMean) * (values[i] - yMean)
+		variance += (x - xMean) * (x - xMean)
+	}
+	if variance == 0 {
+		return trendLine{}, false
+	}
+	slope := cov / variance
+	return trendLine{at: now, value: yMean - slope*xMean, slope: slope}, true
+}
+
+// holtTrend smooths the level and the trend of the points, which are assumed to be evenly spaced
+// at the median interval, and returns the line at the last point.
+func holtTrend(times []time.Time, values []float64, args *ForecastMethodArgs) (trendLine, bool) {
+	alpha, beta := 0.5, 0.1
+	if args != nil {
+		if args.Alpha != nil {
+			alpha = *args.Alpha
+		}
+		if args.Beta != nil {
+			beta = *args.Beta
+		}
+	}
+	interval := medianInterval(times)
+	if len(values) < 2 || interval <= 0 {
+		return trendLine{}, false
+	}
+
+	level, trend := values[0], values[1]-values[0]
+	for _, v := range values[1:] {
+		prevLevel := level
+		level = alpha*v + (1-alpha)*(level+trend)
+		trend = beta*(level-prevLevel) + (1-beta)*trend
+	}
+	return trendLine{at: times[len(times)-1], value: level, slope: trend / interval.Seconds()}, true
+}
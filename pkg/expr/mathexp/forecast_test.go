@@ -0,0 +1,245 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestForecast(t *testing.T) {
+	now := time.Unix(40, 0)
+	hostA := data.Labels{"host": "a"}
+	// a series that grows by 2 every second, with a gap
+	growing := makeSeries("", hostA,
+		tp{time.Unix(10, 0), float64Pointer(20)},
+		tp{time.Unix(20, 0), float64Pointer(40)},
+		tp{time.Unix(30, 0), nil},
+		tp{time.Unix(40, 0), float64Pointer(80)},
+	)
+	// a series that shrinks by 2 every second
+	decreasing := makeSeries("", hostA,
+		tp{time.Unix(10, 0), float64Pointer(100)},
+		tp{time.Unix(20, 0), float64Pointer(80)},
+		tp{time.Unix(30, 0), float64Pointer(60)},
+		tp{time.Unix(40, 0), float64Pointer(40)},
+	)
+
+	var tests = []struct {
+		name     string
+		vals     Values
+		opts     ForecastOptions
+		errIs    require.ErrorAssertionFunc
+		expected Values
+	}{
+		{
+			name:  "linear value at offset",
+			vals:  Values{growing},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputValue, Offset: 10 * time.Second},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, float64Pointer(100)),
+			},
+		},
+		{
+			name:  "linear time to threshold",
+			vals:  Values{growing},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputTimeToThreshold, Threshold: float64Pointer(200)},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, float64Pointer(60)),
+			},
+		},
+		{
+			name:  "time to threshold is zero when the threshold is already crossed",
+			vals:  Values{growing},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputTimeToThreshold, Threshold: float64Pointer(50)},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, float64Pointer(0)),
+			},
+		},
+		{
+			name: "time to threshold is zero when the series is past the threshold for the whole window",
+			vals: Values{makeSeries("", hostA,
+				tp{time.Unix(20, 0), float64Pointer(95)},
+				tp{time.Unix(40, 0), float64Pointer(97)},
+			)},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputTimeToThreshold, Threshold: float64Pointer(90)},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, float64Pointer(0)),
+			},
+		},
+		{
+			name: "time to threshold is null when the trend has passed the threshold but the series has not reached it",
+			vals: Values{makeSeries("", hostA,
+				tp{time.Unix(10, 0), float64Pointer(0)},
+				tp{time.Unix(20, 0), float64Pointer(100)},
+				tp{time.Unix(30, 0), float64Pointer(100)},
+				tp{time.Unix(40, 0), float64Pointer(50)},
+			)},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputTimeToThreshold, Threshold: float64Pointer(70)},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, nil),
+			},
+		},
+		{
+			name:  "decreasing series time to threshold",
+			vals:  Values{decreasing},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputTimeToThreshold, Threshold: float64Pointer(20)},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, float64Pointer(10)),
+			},
+		},
+		{
+			name:  "decreasing series time to threshold is zero when the threshold is already crossed",
+			vals:  Values{decreasing},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputTimeToThreshold, Threshold: float64Pointer(50)},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, float64Pointer(0)),
+			},
+		},
+		{
+			name:  "decreasing series time to threshold is zero when the series is past the threshold for the whole window",
+			vals:  Values{decreasing},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputTimeToThreshold, Threshold: float64Pointer(120)},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, float64Pointer(0)),
+			},
+		},
+		{
+			name:  "decreasing series value at offset",
+			vals:  Values{decreasing},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputValue, Offset: 5 * time.Second},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, float64Pointer(30)),
+			},
+		},
+		{
+			name: "time to threshold is null when the trend is flat",
+			vals: Values{makeSeries("", hostA,
+				tp{time.Unix(10, 0), float64Pointer(5)},
+				tp{time.Unix(20, 0), float64Pointer(5)},
+			)},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputTimeToThreshold, Threshold: float64Pointer(50)},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, nil),
+			},
+		},
+		{
+			name:  "window excludes older points",
+			vals:  Values{growing},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputValue, Window: 20 * time.Second},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, float64Pointer(80)),
+			},
+		},
+		{
+			name:  "value is null without two points",
+			vals:  Values{growing},
+			opts:  ForecastOptions{Method: ForecastMethodHolt, Output: ForecastOutputValue, Window: 5 * time.Second},
+			errIs: require.NoError,
+			expected: Values{
+				makeNumber("B", hostA, nil),
+			},
+		},
+		{
+			name:     "no data is returned when there are no series",
+			vals:     Values{NewNoData()},
+			opts:     ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputValue},
+			errIs:    require.NoError,
+			expected: Values{NewNoData()},
+		},
+		{
+			name:  "numbers should error",
+			vals:  Values{makeNumber("", hostA, float64Pointer(1))},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputValue},
+			errIs: require.Error,
+		},
+		{
+			name:  "unknown method should error",
+			vals:  Values{growing},
+			opts:  ForecastOptions{Method: "arima", Output: ForecastOutputValue},
+			errIs: require.Error,
+		},
+		{
+			name:  "time to threshold without threshold should error",
+			vals:  Values{growing},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputTimeToThreshold},
+			errIs: require.Error,
+		},
+		{
+			name:  "negative offset should error",
+			vals:  Values{growing},
+			opts:  ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputValue, Offset: -time.Second},
+			errIs: require.Error,
+		},
+		{
+			name: "smoothing factors are only valid for holt",
+			vals: Values{growing},
+			opts: ForecastOptions{Method: ForecastMethodLinear, Output: ForecastOutputValue,
+				Args: &ForecastMethodArgs{Alpha: util.Pointer(0.5)}},
+			errIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Forecast("B", tt.vals, now, tt.opts)
+			tt.errIs(t, err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestForecastHolt(t *testing.T) {
+	now := time.Unix(50, 0)
+	forecast := func(t *testing.T, s Series, opts ForecastOptions) float64 {
+		t.Helper()
+		opts.Method = ForecastMethodHolt
+		res, err := Forecast("B", Values{s}, now, opts)
+		require.NoError(t, err)
+		require.Len(t, res, 1)
+		return *res[0].(Number).GetFloat64Value()
+	}
+	// a series that grows by 1 every second, evaluated 10 seconds after its last point
+	s := makeSeries("", nil,
+		tp{time.Unix(10, 0), float64Pointer(10)},
+		tp{time.Unix(20, 0), float64Pointer(20)},
+		tp{time.Unix(30, 0), float64Pointer(30)},
+		tp{time.Unix(40, 0), float64Pointer(40)},
+	)
+
+	t.Run("value at offset extrapolates from the last point", func(t *testing.T) {
+		require.InDelta(t, 60, forecast(t, s, ForecastOptions{Output: ForecastOutputValue, Offset: 10 * time.Second}), 1e-9)
+	})
+
+	t.Run("time to threshold is from the evaluation time", func(t *testing.T) {
+		require.InDelta(t, 50, forecast(t, s, ForecastOptions{Output: ForecastOutputTimeToThreshold, Threshold: float64Pointer(100)}), 1e-9)
+	})
+
+	t.Run("smoothing factors weight the recent points", func(t *testing.T) {
+		jump := makeSeries("", nil,
+			tp{time.Unix(10, 0), float64Pointer(10)},
+			tp{time.Unix(20, 0), float64Pointer(10)},
+			tp{time.Unix(30, 0), float64Pointer(10)},
+			tp{time.Unix(40, 0), float64Pointer(50)},
+		)
+		slow := forecast(t, jump, ForecastOptions{Output: ForecastOutputValue, Args: &ForecastMethodArgs{Alpha: util.Pointer(0.1)}})
+		fast := forecast(t, jump, ForecastOptions{Output: ForecastOutputValue, Args: &ForecastMethodArgs{Alpha: util.Pointer(1.0)}})
+		require.Less(t, slow, fast)
+	})
+}
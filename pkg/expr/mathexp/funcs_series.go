@@ -0,0 +1,312 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+var seriesBuiltins = map[string]parse.Func{
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkDurationArg(1),
+	},
+	"rolling_min": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      rollingMin,
+		Check:  checkDurationArg(1),
+	},
+	"rolling_max": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      rollingMax,
+		Check:  checkDurationArg(1),
+	},
+	"rolling_sum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      rollingSum,
+		Check:  checkDurationArg(1),
+	},
+	"offset": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      offset,
+		Check:  checkDurationArg(1),
+	},
+	"derivative": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      derivative,
+	},
+	"integral": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      integral,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumsum,
+	},
+	"clamp_min": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMin,
+	},
+	"clamp_max": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clampMax,
+	},
+}
+
+// checkDurationArg returns a parse time check that the argument at the given index is a valid duration.
+func checkDurationArg(idx int) func(*parse.Tree, *parse.FuncNode) error {
+	return func(_ *parse.Tree, f *parse.FuncNode) error {
+		arg, ok := f.Args[idx].(*parse.StringNode)
+		if !ok {
+			return fmt.Errorf("parse: expected a duration for argument %v of %s", idx, f.Name)
+		}
+		d, err := gtime.ParseDuration(arg.Text)
+		if err != nil {
+			return fmt.Errorf("parse: invalid duration %s for argument %v of %s: %w", arg.Text, idx, f.Name, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("parse: duration for argument %v of %s must be positive, got %s", idx, f.Name, arg.Text)
+		}
+		return nil
+	}
+}
+
+// movingAvg returns, for each point of each series, the average of the non-null values within the trailing window.
+func movingAvg(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64) float64 {
+		var sum float64
+		for _, v := range vals {
+			sum += v
+		}
+		return sum / float64(len(vals))
+	})
+}
+
+// rollingMin returns, for each point of each series, the minimum of the non-null values within the trailing window.
+func rollingMin(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64) float64 {
+		m := vals[0]
+		for _, v := range vals[1:] {
+			m = math.Min(m, v)
+		}
+		return m
+	})
+}
+
+// rollingMax returns, for each point of each series, the maximum of the non-null values within the trailing window.
+func rollingMax(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64) float64 {
+		m := vals[0]
+		for _, v := range vals[1:] {
+			m = math.Max(m, v)
+		}
+		return m
+	})
+}
+
+// rollingSum returns, for each point of each series, the sum of the non-null values within the trailing window.
+func rollingSum(e *State, varSet Results, window string) (Results, error) {
+	return perWindow(e, varSet, window, func(vals []float64) float64 {
+		var sum float64
+		for _, v := range vals {
+			sum += v
+		}
+		return sum
+	})
+}
+
+// offset shifts the time of every point of each series forward by the duration, so that
+// the values from the past line up with the present, e.g. for week over week comparisons.
+func offset(e *State, varSet Results, duration string) (Results, error) {
+	d, err := gtime.ParseDuration(duration)
+	if err != nil {
+		return Results{}, err
+	}
+	return perSeries(e, varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries
+	})
+}
+
+// derivative returns the per-second rate of change between each point of each series and the previous one.
+// The first point is dropped since it has no previous point. If either value is null, the result is null.
+func derivative(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, func(s Series) Series {
+		points := sortedPoints(s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+		for i := 1; i < len(points); i++ {
+			prev, cur := points[i-1], points[i]
+			elapsed := cur.t.Sub(prev.t).Seconds()
+			if prev.f == nil || cur.f == nil || elapsed == 0 {
+				newSeries.AppendPoint(cur.t, nil)
+				continue
+			}
+			f := (*cur.f - *prev.f) / elapsed
+			newSeries.AppendPoint(cur.t, &f)
+		}
+		return newSeries
+	})
+}
+
+// integral returns the cumulative integral of each series over time in value-seconds, computed with the trapezoidal rule.
+// Intervals where either value is null do not contribute to the integral, and null points stay null.
+func integral(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, func(s Series) Series {
+		points := sortedPoints(s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), len(points))
+		var total float64
+		for i, p := range points {
+			if i > 0 && p.f != nil && points[i-1].f != nil {
+				total += (*p.f + *points[i-1].f) / 2 * p.t.Sub(points[i-1].t).Seconds()
+			}
+			if p.f == nil {
+				newSeries.SetPoint(i, p.t, nil)
+				continue
+			}
+			f := total
+			newSeries.SetPoint(i, p.t, &f)
+		}
+		return newSeries
+	})
+}
+
+// cumsum returns the cumulative sum of the values of each series. Null values are skipped and stay null.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, varSet, func(s Series) Series {
+		points := sortedPoints(s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), len(points))
+		var total float64
+		for i, p := range points {
+			if p.f == nil {
+				newSeries.SetPoint(i, p.t, nil)
+				continue
+			}
+			total += *p.f
+			f := total
+			newSeries.SetPoint(i, p.t, &f)
+		}
+		return newSeries
+	})
+}
+
+// clampMin returns the value for each result in NumberSet, SeriesSet, or Scalar, or the minimum if the value is lower.
+func clampMin(e *State, varSet Results, minSet Results) (Results, error) {
+	return perClamp(e, varSet, minSet, math.Max)
+}
+
+// clampMax returns the value for each result in NumberSet, SeriesSet, or Scalar, or the maximum if the value is greater.
+func clampMax(e *State, varSet Results, maxSet Results) (Results, error) {
+	return perClamp(e, varSet, maxSet, math.Min)
+}
+
+func perClamp(e *State, varSet Results, boundSet Results, clampF func(x, bound float64) float64) (Results, error) {
+	bound := math.NaN()
+	if len(boundSet.Values) == 1 {
+		if sc, ok := boundSet.Values[0].(Scalar); ok && sc.GetFloat64Value() != nil {
+			bound = *sc.GetFloat64Value()
+		}
+	}
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			if math.IsNaN(bound) {
+				return math.NaN()
+			}
+			return clampF(f, bound)
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// perWindow calls windowF for each point of each series with the non-null values within the trailing window
+// (t - window, t] of the point. If there are no values in the window, the point is null.
+func perWindow(e *State, varSet Results, window string, windowF func(vals []float64) float64) (Results, error) {
+	w, err := gtime.ParseDuration(window)
+	if err != nil {
+		return Results{}, err
+	}
+	return perSeries(e, varSet, func(s Series) Series {
+		points := sortedPoints(s)
+		newSeries := NewSeries(e.RefID, s.GetLabels(), len(points))
+		start := 0
+		vals := make([]float64, 0, len(points))
+		for i, p := range points {
+			for !points[start].t.After(p.t.Add(-w)) {
+				start++
+			}
+			vals = vals[:0]
+			for _, wp := range points[start : i+1] {
+				if wp.f != nil {
+					vals = append(vals, *wp.f)
+				}
+			}
+			if len(vals) == 0 {
+				newSeries.SetPoint(i, p.t, nil)
+				continue
+			}
+			f := windowF(vals)
+			newSeries.SetPoint(i, p.t, &f)
+		}
+		return newSeries
+	})
+}
+
+// perSeries calls seriesF for each Series in the results. NoData is passed through,
+// while other types return an error as the functions need the time of the values.
+func perSeries(e *State, varSet Results, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newRes.Values = append(newRes.Values, seriesF(v))
+		case NoData:
+			newRes.Values = append(newRes.Values, NewNoData())
+		default:
+			return newRes, fmt.Errorf("function can only be applied to series, got type %v", res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+type seriesPoint struct {
+	t time.Time
+	f *float64
+}
+
+// sortedPoints returns the points of the series sorted by time in ascending order,
+// without modifying the series that might be shared with other nodes.
+func sortedPoints(s Series) []seriesPoint {
+	points := make([]seriesPoint, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, f := s.GetPoint(i)
+		points[i] = seriesPoint{t: t, f: f}
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].t.Before(points[j].t)
+	})
+	return points
+}
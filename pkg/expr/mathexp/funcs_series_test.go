@@ -0,0 +1,217 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+var windowSeries = Vars{
+	"A": resultValuesNoErr(
+		makeSeries("", data.Labels{"host": "a"},
+			tp{time.Unix(0, 0), float64Pointer(1)},
+			tp{time.Unix(60, 0), float64Pointer(3)},
+			tp{time.Unix(120, 0), nil},
+			tp{time.Unix(180, 0), float64Pointer(8)}),
+	),
+}
+
+func TestSeriesFuncs(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+		results   Results
+	}{
+		{
+			name:      "moving_avg over the trailing window skips null values",
+			expr:      "moving_avg($A, 2m)",
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(2)},
+					tp{time.Unix(120, 0), float64Pointer(3)},
+					tp{time.Unix(180, 0), float64Pointer(8)}),
+			),
+		},
+		{
+			name:      "rolling_max over the trailing window",
+			expr:      "rolling_max($A, 5m)",
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(3)},
+					tp{time.Unix(120, 0), float64Pointer(3)},
+					tp{time.Unix(180, 0), float64Pointer(8)}),
+			),
+		},
+		{
+			name:      "rolling_min with a window that only contains null is null",
+			expr:      "rolling_min($A, 30s)",
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(3)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), float64Pointer(8)}),
+			),
+		},
+		{
+			name:      "offset shifts the time forward",
+			expr:      "offset($A, 1d)",
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0).Add(24 * time.Hour), float64Pointer(1)},
+					tp{time.Unix(60, 0).Add(24 * time.Hour), float64Pointer(3)},
+					tp{time.Unix(120, 0).Add(24 * time.Hour), nil},
+					tp{time.Unix(180, 0).Add(24 * time.Hour), float64Pointer(8)}),
+			),
+		},
+		{
+			name:      "derivative is per second and drops the first point",
+			expr:      "derivative($A)",
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(60, 0), float64Pointer(2.0 / 60)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), nil}),
+			),
+		},
+		{
+			name:      "integral uses the trapezoidal rule",
+			expr:      "integral($A)",
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(0)},
+					tp{time.Unix(60, 0), float64Pointer(120)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), float64Pointer(120)}),
+			),
+		},
+		{
+			name:      "cumsum skips null values",
+			expr:      "cumsum($A)",
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(0, 0), float64Pointer(1)},
+					tp{time.Unix(60, 0), float64Pointer(4)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), float64Pointer(12)}),
+			),
+		},
+		{
+			name:      "comparison with the offset series",
+			expr:      "$A - offset($A, 1m)",
+			vars:      windowSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", data.Labels{"host": "a"},
+					tp{time.Unix(60, 0), float64Pointer(2)},
+					tp{time.Unix(120, 0), nil},
+					tp{time.Unix(180, 0), nil}),
+			),
+		},
+		{
+			name: "clamp_min on number",
+			expr: "clamp_min($A, 0)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(-7))),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   resultValuesNoErr(makeNumber("", nil, float64Pointer(0))),
+		},
+		{
+			name: "clamp_max on series",
+			expr: "clamp_max($A, 2)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(5, 0), float64Pointer(1)},
+						tp{time.Unix(10, 0), float64Pointer(3)}),
+				),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(5, 0), float64Pointer(1)},
+					tp{time.Unix(10, 0), float64Pointer(2)}),
+			),
+		},
+		{
+			name: "moving_avg on number should error",
+			expr: "moving_avg($A, 5m)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "moving_avg passes through no data",
+			expr:      "moving_avg($A, 5m)",
+			vars:      Vars{"A": resultValuesNoErr(NewNoData())},
+			newErrIs:  require.NoError,
+			execErrIs: require.NoError,
+			results:   resultValuesNoErr(NewNoData()),
+		},
+		{
+			name:     "moving_avg with an invalid duration should error",
+			expr:     "moving_avg($A, 5x)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "moving_avg without a duration should error",
+			expr:     "moving_avg($A, 5)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "duration outside of a function should error",
+			expr:     "$A + 5m",
+			newErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e != nil {
+				res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+				tt.execErrIs(t, err)
+				if err != nil {
+					return
+				}
+				require.Equal(t, tt.results, res)
+			}
+		})
+	}
+}
@@ -56,8 +56,9 @@ const (
 	itemRightParen
 	itemString
 	itemFunc
-	itemVar // e.g. $A
-	itemPow // '**'
+	itemVar      // e.g. $A
+	itemPow      // '**'
+	itemDuration // e.g. 5m
 )
 
 const eof = -1
@@ -210,10 +211,27 @@ func lexNumber(l *lexer) stateFn {
 	if !l.scanNumber() {
 		return l.errorf("bad number syntax: %q", l.input[l.start:l.pos])
 	}
+	if unicode.IsLetter(l.peek()) {
+		return lexDuration
+	}
 	l.emit(itemNumber)
 	return lexItem
 }
 
+// lexDuration scans the unit of a duration such as 5m or 1d, the number has already been scanned.
+func lexDuration(l *lexer) stateFn {
+	for {
+		switch r := l.next(); {
+		case unicode.IsLetter(r):
+			// absorb
+		default:
+			l.backup()
+			l.emit(itemDuration)
+			return lexItem
+		}
+	}
+}
+
 func (l *lexer) scanNumber() bool {
 	// Is it hex?
 	digits := "0123456789"
@@ -276,7 +294,7 @@ func lexSymbol(l *lexer) stateFn {
 func lexFunc(l *lexer) stateFn {
 	for {
 		switch r := l.next(); {
-		case unicode.IsLetter(r) || r == '_':
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
 			// absorb
 		default:
 			l.backup()
@@ -33,6 +33,7 @@ var itemName = map[itemType]string{
 	itemRightParen: ")",
 	itemString:     "string",
 	itemFunc:       "func",
+	itemDuration:   "duration",
 }
 
 func (i itemType) String() string {
@@ -113,6 +114,15 @@ var lexTests = []lexTest{
 		{itemVar, 0, "$A"},
 		tEOF,
 	}},
+	{"func with duration", "moving_avg($A, 5m)", []item{
+		{itemFunc, 0, "moving_avg"},
+		{itemLeftParen, 0, "("},
+		{itemVar, 0, "$A"},
+		{itemComma, 0, ","},
+		{itemDuration, 0, "5m"},
+		{itemRightParen, 0, ")"},
+		tEOF,
+	}},
 	// errors
 	{"unclosed quote", "\"", []item{
 		{itemError, 0, "unterminated string"},
@@ -9,6 +9,7 @@ package parse
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // A Node is an element in the parse tree. The interface is trivial.
@@ -300,6 +301,8 @@ type BinaryNode struct {
 	Args     [2]Node
 	Operator item
 	OpStr    string
+	// Matching is set when the operator has label matching modifiers such as on(...) or group_left.
+	Matching *VectorMatching
 }
 
 func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
@@ -308,19 +311,77 @@ func newBinary(operator item, arg1, arg2 Node) *BinaryNode {
 
 // String returns the string representation of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) String() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s %s %s", b.Args[0], b.Operator.val, b.Matching, b.Args[1])
+	}
 	return fmt.Sprintf("%s %s %s", b.Args[0], b.Operator.val, b.Args[1])
 }
 
 // StringAST returns the string representation of abstract syntax tree of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) StringAST() string {
+	if b.Matching != nil {
+		return fmt.Sprintf("%s %s(%s, %s)", b.Operator.val, b.Matching, b.Args[0], b.Args[1])
+	}
 	return fmt.Sprintf("%s(%s, %s)", b.Operator.val, b.Args[0], b.Args[1])
 }
 
 // Check performs parse time checking on the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Check(t *Tree) error {
+	for _, arg := range b.Args {
+		if rt := arg.Return(); b.Matching != nil && rt == TypeScalar {
+			return fmt.Errorf("parse: label matching in %s requires numbers or series on both sides, got %s", b, rt)
+		}
+		if err := arg.Check(t); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// MatchCardinality is the cardinality of the matching between the two sides of a binary operation.
+type MatchCardinality int
+
+const (
+	// MatchOneToOne matches each item on one side with at most one item on the other side.
+	MatchOneToOne MatchCardinality = iota
+	// MatchManyToOne matches many items on the left side with one item on the right side (group_left).
+	MatchManyToOne
+	// MatchOneToMany matches one item on the left side with many items on the right side (group_right).
+	MatchOneToMany
+)
+
+// VectorMatching describes how the items of the two sides of a binary operation are matched by their labels.
+type VectorMatching struct {
+	Card MatchCardinality
+	// On is true when items are matched only by MatchingLabels, otherwise items are
+	// matched by all their labels except MatchingLabels.
+	On             bool
+	MatchingLabels []string
+	// Include are the labels of the "one" side that are copied to the result with group_left or group_right.
+	Include []string
+}
+
+// String returns the modifiers as they are written in an expression.
+func (m *VectorMatching) String() string {
+	s := "ignoring"
+	if m.On {
+		s = "on"
+	}
+	s += "(" + strings.Join(m.MatchingLabels, ", ") + ")"
+	switch m.Card {
+	case MatchManyToOne:
+		s += " group_left"
+	case MatchOneToMany:
+		s += " group_right"
+	default:
+		return s
+	}
+	if len(m.Include) > 0 {
+		s += "(" + strings.Join(m.Include, ", ") + ")"
+	}
+	return s
+}
+
 // Return returns the result type of the BinaryNode so it fulfills the Node interface.
 func (b *BinaryNode) Return() ReturnType {
 	t0 := b.Args[0].Return()
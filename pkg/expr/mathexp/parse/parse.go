@@ -103,7 +103,6 @@ func (t *Tree) expect(expected itemType, context string) item {
 }
 
 // expectOneOf consumes the next token and guarantees it has one of the required types.
-// nolint:unused
 func (t *Tree) expectOneOf(expected1, expected2 itemType, context string) item {
 	token := t.next()
 	if token.typ != expected1 && token.typ != expected2 {
@@ -174,7 +173,8 @@ E -> F {( "**" ) F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
 Func -> name "(" param {"," param} ")"
-param -> number | "string" | queryVar
+param -> number | "string" | queryVar | duration
+matching -> ("on" | "ignoring") "(" labels ")" [("group_left" | "group_right") ["(" labels ")"]]
 */
 
 // expr:
@@ -185,7 +185,7 @@ func (t *Tree) O() Node {
 	for {
 		switch t.peek().typ {
 		case itemOr:
-			n = newBinary(t.next(), n, t.A())
+			n = t.binary(t.next(), n, t.A)
 		default:
 			return n
 		}
@@ -198,7 +198,7 @@ func (t *Tree) A() Node {
 	for {
 		switch t.peek().typ {
 		case itemAnd:
-			n = newBinary(t.next(), n, t.C())
+			n = t.binary(t.next(), n, t.C)
 		default:
 			return n
 		}
@@ -211,7 +211,7 @@ func (t *Tree) C() Node {
 	for {
 		switch t.peek().typ {
 		case itemEq, itemNotEq, itemGreater, itemGreaterEq, itemLess, itemLessEq:
-			n = newBinary(t.next(), n, t.P())
+			n = t.binary(t.next(), n, t.P)
 		default:
 			return n
 		}
@@ -224,7 +224,7 @@ func (t *Tree) P() Node {
 	for {
 		switch t.peek().typ {
 		case itemPlus, itemMinus:
-			n = newBinary(t.next(), n, t.M())
+			n = t.binary(t.next(), n, t.M)
 		default:
 			return n
 		}
@@ -237,7 +237,7 @@ func (t *Tree) M() Node {
 	for {
 		switch t.peek().typ {
 		case itemMult, itemDiv, itemMod:
-			n = newBinary(t.next(), n, t.E())
+			n = t.binary(t.next(), n, t.E)
 		default:
 			return n
 		}
@@ -250,13 +250,81 @@ func (t *Tree) E() Node {
 	for {
 		switch t.peek().typ {
 		case itemPow:
-			n = newBinary(t.next(), n, t.F())
+			n = t.binary(t.next(), n, t.F)
 		default:
 			return n
 		}
 	}
 }
 
+// binary parses the optional label matching modifiers after the operator, then the right side of the operation.
+func (t *Tree) binary(operator item, lhs Node, rhs func() Node) Node {
+	matching := t.matching()
+	n := newBinary(operator, lhs, rhs())
+	n.Matching = matching
+	return n
+}
+
+// matching parses the label matching modifiers of a binary operation:
+// ("on" | "ignoring") "(" labels ")" [("group_left" | "group_right") ["(" labels ")"]]
+func (t *Tree) matching() *VectorMatching {
+	token := t.peek()
+	if token.typ != itemFunc || (token.val != "on" && token.val != "ignoring") {
+		return nil
+	}
+	t.next()
+	m := &VectorMatching{On: token.val == "on"}
+	m.MatchingLabels = t.labels(token.val)
+
+	token = t.peek()
+	if token.typ != itemFunc || (token.val != "group_left" && token.val != "group_right") {
+		return m
+	}
+	t.next()
+	m.Card = MatchManyToOne
+	if token.val == "group_right" {
+		m.Card = MatchOneToMany
+	}
+	if t.peek().typ == itemLeftParen {
+		m.Include = t.labels(token.val)
+	}
+	if m.On {
+		for _, l := range m.Include {
+			for _, ml := range m.MatchingLabels {
+				if l == ml {
+					t.errorf("label %s must not occur in on() and %s() at the same time", l, token.val)
+				}
+			}
+		}
+	}
+	return m
+}
+
+// labels parses a parenthesized and comma separated list of label names.
+func (t *Tree) labels(context string) []string {
+	t.expect(itemLeftParen, context)
+	labels := []string{}
+	for {
+		switch token := t.next(); token.typ {
+		case itemRightParen:
+			return labels
+		case itemFunc:
+			labels = append(labels, token.val)
+		case itemString:
+			l, err := strconv.Unquote(token.val)
+			if err != nil {
+				t.errorf("Unquoting error: %s", err)
+			}
+			labels = append(labels, l)
+		default:
+			t.unexpected(token, context)
+		}
+		if t.expectOneOf(itemComma, itemRightParen, context).typ == itemRightParen {
+			return labels
+		}
+	}
+}
+
 // F is v | "(" O ")" | "!" O | "-" O in the grammar.
 func (t *Tree) F() Node {
 	switch token := t.peek(); token.typ {
@@ -314,6 +382,10 @@ func (t *Tree) Func() (f *FuncNode) {
 	}
 	f = newFunc(token.pos, token.val, funcv)
 	t.expect(itemLeftParen, "func")
+	if t.peek().typ == itemRightParen {
+		t.next()
+		return
+	}
 	for {
 		switch token = t.next(); token.typ {
 		default:
@@ -329,7 +401,10 @@ func (t *Tree) Func() (f *FuncNode) {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
-		case itemRightParen:
+		case itemDuration:
+			f.append(newString(token.pos, token.val, token.val))
+		}
+		if t.expectOneOf(itemComma, itemRightParen, "func").typ == itemRightParen {
 			return
 		}
 	}
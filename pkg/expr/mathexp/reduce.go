@@ -1,14 +1,20 @@
 package mathexp
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"sort"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
 type ReducerFunc = func(fv *Float64Field) *float64
 
+// SeriesReducerFunc reduces a whole series to a single value. Unlike ReducerFunc
+// it has access to the timestamps of the points, which is required by reducers such as rate.
+type SeriesReducerFunc = func(s Series) *float64
+
 // The reducer function
 // +enum
 type ReducerID string
@@ -20,11 +26,38 @@ const (
 	ReducerMax   ReducerID = "max"
 	ReducerCount ReducerID = "count"
 	ReducerLast  ReducerID = "last"
+	// The first value of the series
+	ReducerFirst ReducerID = "first"
+	// The median of the values of the series
+	ReducerMedian ReducerID = "median"
+	// The percentile of the values of the series, requires the percentile argument
+	ReducerPercentile ReducerID = "percentile"
+	// The population standard deviation of the values of the series
+	ReducerStdDev ReducerID = "stddev"
+	// The population variance of the values of the series
+	ReducerVariance ReducerID = "variance"
+	// The difference between the last and the first value of the series
+	ReducerDelta ReducerID = "delta"
+	// The difference between the last two values of the series
+	ReducerDiff ReducerID = "diff"
+	// The increase of a counter over the series, accounting for counter resets
+	ReducerIncrease ReducerID = "increase"
+	// The per-second rate of increase of a counter over the series, accounting for counter resets
+	ReducerRate ReducerID = "rate"
 )
 
+// ReducerArgs are the arguments of reducers that are parameterised, such as percentile.
+type ReducerArgs struct {
+	// The percentile to compute, in the range [0, 100]. Only valid when the reducer is percentile
+	Percentile *float64 `json:"percentile,omitempty"`
+}
+
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast}
+	return []ReducerID{
+		ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast,
+		ReducerFirst, ReducerMedian, ReducerStdDev, ReducerVariance, ReducerDelta, ReducerDiff, ReducerIncrease, ReducerRate, ReducerPercentile,
+	}
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -98,6 +131,149 @@ func Last(fv *Float64Field) *float64 {
 	return fv.GetValue(fv.Len() - 1)
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// Percentile returns a reducer that computes the p-th percentile of the values, where p is in the range [0, 100].
+// The percentile is linearly interpolated between the two closest ranks.
+func Percentile(p float64) ReducerFunc {
+	return func(fv *Float64Field) *float64 {
+		vals, ok := sortedValues(fv)
+		if !ok || len(vals) == 0 {
+			nan := math.NaN()
+			return &nan
+		}
+		rank := p / 100 * float64(len(vals)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		f := vals[lower] + (vals[upper]-vals[lower])*(rank-float64(lower))
+		return &f
+	}
+}
+
+func Median(fv *Float64Field) *float64 {
+	return Percentile(50)(fv)
+}
+
+func Variance(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	mean := Avg(fv)
+	if math.IsNaN(*mean) {
+		return mean
+	}
+	var sum float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *mean
+		sum += d * d
+	}
+	f := sum / float64(fv.Len())
+	return &f
+}
+
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+func Delta(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	first, last := fv.GetValue(0), fv.GetValue(fv.Len()-1)
+	if first == nil || last == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *last - *first
+	return &f
+}
+
+func Diff(fv *Float64Field) *float64 {
+	if fv.Len() < 2 {
+		nan := math.NaN()
+		return &nan
+	}
+	prev, last := fv.GetValue(fv.Len()-2), fv.GetValue(fv.Len()-1)
+	if prev == nil || last == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *last - *prev
+	return &f
+}
+
+// Increase returns the increase of a counter. When a value is lower than the previous one,
+// the counter is considered to have been reset and the value is counted as the increase since the reset.
+func Increase(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var increase float64
+	var prev float64
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			nan := math.NaN()
+			return &nan
+		}
+		if i > 0 {
+			if *v < prev {
+				increase += *v
+			} else {
+				increase += *v - prev
+			}
+		}
+		prev = *v
+	}
+	return &increase
+}
+
+// Rate returns the per-second rate of increase of a counter between the first and the last point of the series.
+// Counter resets are handled the same way as in Increase.
+func Rate(s Series) *float64 {
+	if s.Len() < 2 {
+		nan := math.NaN()
+		return &nan
+	}
+	elapsed := s.GetTime(s.Len() - 1).Sub(s.GetTime(0)).Seconds()
+	if elapsed <= 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	fv := Float64Field(*s.Frame.Fields[seriesTypeValIdx])
+	increase := Increase(&fv)
+	f := *increase / elapsed
+	return &f
+}
+
+// sortedValues returns the values of the field sorted in ascending order.
+// It returns false if the field contains a nil or NaN value.
+func sortedValues(fv *Float64Field) ([]float64, bool) {
+	vals := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		vals = append(vals, *v)
+	}
+	sort.Float64s(vals)
+	return vals, true
+}
+
+// GetReduceFunc returns the reduction function for reducers that depend only on the values of a series
+// and do not take any arguments. Use GetSeriesReduceFunc for all other reducers.
 func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 	switch rFunc {
 	case ReducerSum:
@@ -112,15 +288,69 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 		return Count, nil
 	case ReducerLast:
 		return Last, nil
+	case ReducerFirst:
+		return First, nil
+	case ReducerMedian:
+		return Median, nil
+	case ReducerStdDev:
+		return StdDev, nil
+	case ReducerVariance:
+		return Variance, nil
+	case ReducerDelta:
+		return Delta, nil
+	case ReducerDiff:
+		return Diff, nil
+	case ReducerIncrease:
+		return Increase, nil
+	case ReducerPercentile:
+		return nil, fmt.Errorf("reduction %v requires the percentile argument", rFunc)
+	case ReducerRate:
+		return nil, fmt.Errorf("reduction %v requires the time of the points", rFunc)
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
+// GetSeriesReduceFunc returns the reduction function for the given reducer and its arguments.
+// Args can be nil for reducers that do not take any arguments.
+func GetSeriesReduceFunc(rFunc ReducerID, args *ReducerArgs) (SeriesReducerFunc, error) {
+	switch rFunc {
+	case ReducerRate:
+		return Rate, nil
+	case ReducerPercentile:
+		if args == nil || args.Percentile == nil {
+			return nil, errors.New("percentile argument must be specified for reduction percentile")
+		}
+		p := *args.Percentile
+		if math.IsNaN(p) || p < 0 || p > 100 {
+			return nil, fmt.Errorf("percentile argument must be in the range [0, 100], got %v", p)
+		}
+		return valuesReducer(Percentile(p)), nil
+	}
+	reduceFunc, err := GetReduceFunc(rFunc)
+	if err != nil {
+		return nil, err
+	}
+	return valuesReducer(reduceFunc), nil
+}
+
+// valuesReducer adapts a ReducerFunc to a SeriesReducerFunc.
+func valuesReducer(reduceFunc ReducerFunc) SeriesReducerFunc {
+	return func(s Series) *float64 {
+		fv := Float64Field(*s.Frame.Fields[seriesTypeValIdx])
+		return reduceFunc(&fv)
+	}
+}
+
 // Reduce turns the Series into a Number based on the given reduction function
 // if ReduceMapper is defined it applies it to the provided series and performs reduction of the resulting series.
 // Otherwise, the reduction operation is done against the original series.
 func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Number, error) {
+	return s.ReduceWithArgs(refID, rFunc, nil, mapper)
+}
+
+// ReduceWithArgs is the same as Reduce but accepts the arguments of parameterised reducers such as percentile.
+func (s Series) ReduceWithArgs(refID string, rFunc ReducerID, args *ReducerArgs, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
@@ -131,13 +361,11 @@ func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Numb
 	if mapper != nil {
 		series = mapSeries(s, mapper)
 	}
-	fVec := series.Frame.Fields[seriesTypeValIdx]
-	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
+	reduceFunc, err := GetSeriesReduceFunc(rFunc, args)
 	if err != nil {
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
-	f = reduceFunc(&floatField)
+	f = reduceFunc(series)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
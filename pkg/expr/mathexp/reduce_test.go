@@ -21,6 +21,18 @@ var seriesWithNil = Vars{
 	),
 }
 
+// counterSeries is a counter that is reset between the third and the fourth point.
+var counterSeries = Vars{
+	"A": resultValuesNoErr(
+		makeSeries("temp", nil,
+			tp{time.Unix(0, 0), float64Pointer(2)},
+			tp{time.Unix(5, 0), float64Pointer(5)},
+			tp{time.Unix(10, 0), float64Pointer(8)},
+			tp{time.Unix(15, 0), float64Pointer(0)},
+			tp{time.Unix(20, 0), float64Pointer(4)}),
+	),
+}
+
 var seriesEmpty = Vars{
 	"A": resultValuesNoErr(
 		makeSeries("temp", nil),
@@ -179,6 +191,140 @@ func TestSeriesReduce(t *testing.T) {
 			resultsIs:   require.Equal,
 			results:     resultValuesNoErr(makeNumber("", nil, nil)),
 		},
+		{
+			name:        "first series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(2))),
+		},
+		{
+			name:        "first empty series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "median series",
+			red:         "median",
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(4))),
+		},
+		{
+			name:        "median series with a nil value",
+			red:         "median",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "stddev series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(0.5))),
+		},
+		{
+			name:        "variance series",
+			red:         "variance",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(0.25))),
+		},
+		{
+			name:        "variance empty series",
+			red:         "variance",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "delta series",
+			red:         "delta",
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(2))),
+		},
+		{
+			name:        "diff series",
+			red:         "diff",
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(4))),
+		},
+		{
+			name:        "diff series with a single point",
+			red:         "diff",
+			varToReduce: "A",
+			vars:        Vars{"A": resultValuesNoErr(makeSeries("temp", nil, tp{time.Unix(5, 0), float64Pointer(2)}))},
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "increase series with counter reset",
+			red:         "increase",
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(10))),
+		},
+		{
+			name:        "increase series with a nil value",
+			red:         "increase",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "rate series with counter reset",
+			red:         "rate",
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(0.5))),
+		},
+		{
+			name:        "rate empty series",
+			red:         "rate",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "percentile without argument will error",
+			red:         "percentile",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
 	}
 
 	for _, tt := range tests {
@@ -204,6 +350,94 @@ func TestSeriesReduce(t *testing.T) {
 	}
 }
 
+func TestSeriesReduceWithArgs(t *testing.T) {
+	var tests = []struct {
+		name        string
+		red         ReducerID
+		args        *ReducerArgs
+		vars        Vars
+		varToReduce string
+		errIs       require.ErrorAssertionFunc
+		results     Results
+	}{
+		{
+			name:        "percentile series",
+			red:         "percentile",
+			args:        &ReducerArgs{Percentile: float64Pointer(75)},
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(5))),
+		},
+		{
+			name:        "percentile series is interpolated between ranks",
+			red:         "percentile",
+			args:        &ReducerArgs{Percentile: float64Pointer(90)},
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(6.8))),
+		},
+		{
+			name:        "percentile empty series",
+			red:         "percentile",
+			args:        &ReducerArgs{Percentile: float64Pointer(95)},
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "percentile out of range will error",
+			red:         "percentile",
+			args:        &ReducerArgs{Percentile: float64Pointer(101)},
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.Error,
+		},
+		{
+			name:        "arguments are ignored by reducers that take none",
+			red:         "max",
+			args:        &ReducerArgs{Percentile: float64Pointer(50)},
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.NoError,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(2))),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := Results{}
+			seriesSet := tt.vars[tt.varToReduce]
+			for _, series := range seriesSet.Values {
+				ns, err := series.Value().(*Series).ReduceWithArgs("", tt.red, tt.args, nil)
+				tt.errIs(t, err)
+				if err != nil {
+					return
+				}
+				results.Values = append(results.Values, ns)
+			}
+			require.Len(t, results.Values, len(tt.results.Values))
+			for i, v := range results.Values {
+				expected := tt.results.Values[i].(Number)
+				actual := v.(Number)
+				require.Equal(t, expected.GetLabels(), actual.GetLabels())
+				expectedValue, actualValue := expected.GetFloat64Value(), actual.GetFloat64Value()
+				if expectedValue == nil || actualValue == nil {
+					require.Equal(t, expectedValue, actualValue)
+					continue
+				}
+				if math.IsNaN(*expectedValue) {
+					require.True(t, math.IsNaN(*actualValue), "expected NaN, got %v", *actualValue)
+					continue
+				}
+				require.InDelta(t, *expectedValue, *actualValue, 1e-9)
+			}
+		})
+	}
+}
+
 var seriesNonNumbers = Vars{
 	"A": resultValuesNoErr(
 		makeSeries("temp", nil,
@@ -74,22 +74,11 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 		} else { // downsampling
 			fVec := data.NewField("", s.GetLabels(), vals)
 			ff := Float64Field(*fVec)
-			var tmp *float64
-			switch downsampler {
-			case ReducerSum:
-				tmp = Sum(&ff)
-			case ReducerMean:
-				tmp = Avg(&ff)
-			case ReducerMin:
-				tmp = Min(&ff)
-			case ReducerMax:
-				tmp = Max(&ff)
-			case ReducerLast:
-				tmp = Last(&ff)
-			default:
-				return s, fmt.Errorf("downsampling %v not implemented", downsampler)
+			reduceFunc, err := GetReduceFunc(downsampler)
+			if err != nil {
+				return s, fmt.Errorf("downsampling %v not implemented: %w", downsampler, err)
 			}
-			value = tmp
+			value = reduceFunc(&ff)
 		}
 		resampled.SetPoint(idx, t, value)
 		t = t.Add(interval)
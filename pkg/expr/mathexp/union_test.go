@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -300,3 +301,139 @@ func Test_union(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchingUnion(t *testing.T) {
+	errorCounts := Results{
+		Values: Values{
+			makeNumber("", data.Labels{"cluster": "a", "code": "500"}, float64Pointer(10)),
+			makeNumber("", data.Labels{"cluster": "b", "code": "500"}, float64Pointer(4)),
+			makeNumber("", data.Labels{"cluster": "b", "code": "503"}, float64Pointer(6)),
+		},
+	}
+	requests := Results{
+		Values: Values{
+			makeNumber("", data.Labels{"cluster": "a", "instance": "x"}, float64Pointer(100)),
+			makeNumber("", data.Labels{"cluster": "b", "instance": "y"}, float64Pointer(200)),
+			makeNumber("", data.Labels{"cluster": "c", "instance": "z"}, float64Pointer(300)),
+		},
+	}
+
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  assert.ErrorAssertionFunc
+		execErrIs assert.ErrorAssertionFunc
+		results   Results
+		dropCount int64
+	}{
+		{
+			name:      "on matches only by the listed labels",
+			expr:      "$A / on(cluster) $B",
+			vars:      Vars{"A": Results{Values: errorCounts.Values[:2]}, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(0.1)),
+					makeNumber("", data.Labels{"cluster": "b"}, float64Pointer(0.02)),
+				},
+			},
+			dropCount: 1,
+		},
+		{
+			name:      "ignoring matches by all other labels",
+			expr:      "$A / ignoring(code) $B",
+			vars:      Vars{"A": Results{Values: errorCounts.Values[:1]}, "B": Results{Values: Values{makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(100))}}},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"cluster": "a"}, float64Pointer(0.1)),
+				},
+			},
+		},
+		{
+			name:      "group_left keeps the labels of the many side and includes labels of the one side",
+			expr:      "$A / on(cluster) group_left(instance) $B",
+			vars:      Vars{"A": errorCounts, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"cluster": "a", "code": "500", "instance": "x"}, float64Pointer(0.1)),
+					makeNumber("", data.Labels{"cluster": "b", "code": "500", "instance": "y"}, float64Pointer(0.02)),
+					makeNumber("", data.Labels{"cluster": "b", "code": "503", "instance": "y"}, float64Pointer(0.03)),
+				},
+			},
+			dropCount: 1,
+		},
+		{
+			name:      "group_right is the mirror of group_left",
+			expr:      "$B * on(cluster) group_right $A",
+			vars:      Vars{"A": errorCounts, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.NoError,
+			results: Results{
+				Values: Values{
+					makeNumber("", data.Labels{"cluster": "a", "code": "500"}, float64Pointer(1000)),
+					makeNumber("", data.Labels{"cluster": "b", "code": "500"}, float64Pointer(800)),
+					makeNumber("", data.Labels{"cluster": "b", "code": "503"}, float64Pointer(1200)),
+				},
+			},
+			dropCount: 1,
+		},
+		{
+			name:      "many-to-many matching is an error",
+			expr:      "$A / on(cluster) $B",
+			vars:      Vars{"A": errorCounts, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+		},
+		{
+			name:      "duplicate match groups on the one side is an error",
+			expr:      "$B / on(cluster) group_left $A",
+			vars:      Vars{"A": errorCounts, "B": requests},
+			newErrIs:  assert.NoError,
+			execErrIs: assert.Error,
+		},
+		{
+			name:     "label matching with a scalar is an error",
+			expr:     "$A / on(cluster) 2",
+			newErrIs: assert.Error,
+		},
+		{
+			name:     "group_left without on or ignoring is an error",
+			expr:     "$A / group_left $B",
+			newErrIs: assert.Error,
+		},
+		{
+			name:     "label in both on and group_left is an error",
+			expr:     "$A / on(cluster) group_left(cluster) $B",
+			newErrIs: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if e == nil {
+				return
+			}
+			s := &State{Expr: e, Vars: tt.vars, tracer: tracing.InitializeTracerForTest()}
+			res, err := s.walk(e.Tree.Root)
+			tt.execErrIs(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tt.results, res)
+			assert.Equal(t, tt.dropCount, s.DropCount)
+		})
+	}
+}
+
+func TestMatchingString(t *testing.T) {
+	e, err := New("$A / ignoring(code, k8s_cluster) group_left(instance) $B")
+	assert.NoError(t, err)
+	assert.Equal(t, "$A / ignoring(code, k8s_cluster) group_left(instance) $B", e.Tree.Root.String())
+}
@@ -158,6 +158,14 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 		node.Command, err = UnmarshalThresholdCommand(rn, toggles)
 	case TypeSQL:
 		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeAggregate:
+		node.Command, err = UnmarshalAggregateCommand(rn)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
+	case TypeForecast:
+		node.Command, err = UnmarshalForecastCommand(rn)
+	case TypeRecovery:
+		node.Command, err = UnmarshalRecoveryCommand(rn, toggles)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -261,15 +269,18 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 		byDS[k] = append(byDS[k], node)
 	}
 
+	explainer := explainerFromContext(ctx)
 	for _, nodeGroup := range byDS {
 		func() {
 			ctx, span := s.tracer.Start(ctx, "SSE.ExecuteDatasourceQuery")
 			defer span.End()
+			start := time.Now()
 			firstNode := nodeGroup[0]
 			pCtx, err := s.pCtxProvider.GetWithDataSource(ctx, firstNode.datasource.Type, firstNode.request.User, firstNode.datasource)
 			if err != nil {
 				for _, dn := range nodeGroup {
 					vars[dn.refID] = mathexp.Results{Error: datasources.ErrDataSourceNotFound}
+					explainer.nodeFailed(dn.refID, datasources.ErrDataSourceNotFound)
 				}
 				return
 			}
@@ -315,10 +326,13 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				s.metrics.dsRequests.WithLabelValues(respStatus, fmt.Sprintf("%t", useDataplane), firstNode.datasource.Type).Inc()
 			}
 
+			queryStart := time.Now()
 			resp, err := s.dataService.QueryData(ctx, req)
+			explainer.datasourceQueried(refIDsOf(nodeGroup), time.Since(queryStart), resp)
 			if err != nil {
 				for _, dn := range nodeGroup {
 					vars[dn.refID] = mathexp.Results{Error: MakeQueryError(firstNode.refID, firstNode.datasource.UID, err)}
+					explainer.nodeExecuted(dn, vars, vars[dn.refID], time.Since(start))
 				}
 				instrument(err, "")
 				return
@@ -328,6 +342,7 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				dataFrames, err := getResponseFrame(logger, resp, dn.refID)
 				if err != nil {
 					vars[dn.refID] = mathexp.Results{Error: MakeQueryError(dn.refID, dn.datasource.UID, err)}
+					explainer.nodeExecuted(dn, vars, vars[dn.refID], time.Since(start))
 					instrument(err, "")
 					return
 				}
@@ -339,6 +354,7 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				}
 				instrument(err, responseType)
 				vars[dn.refID] = result
+				explainer.nodeExecuted(dn, vars, result, time.Since(start))
 			}
 		}()
 	}
@@ -390,7 +406,9 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 		s.metrics.dsRequests.WithLabelValues(respStatus, fmt.Sprintf("%t", useDataplane), dn.datasource.Type).Inc()
 	}()
 
+	queryStart := time.Now()
 	resp, err := s.dataService.QueryData(ctx, req)
+	explainerFromContext(ctx).datasourceQueried([]string{dn.refID}, time.Since(queryStart), resp)
 	if err != nil {
 		return mathexp.Results{}, MakeQueryError(dn.refID, dn.datasource.UID, err)
 	}
@@ -407,3 +425,11 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 	}
 	return result, err
 }
+
+func refIDsOf(nodes []*DSNode) []string {
+	refIDs := make([]string, 0, len(nodes))
+	for _, dn := range nodes {
+		refIDs = append(refIDs, dn.refID)
+	}
+	return refIDs
+}
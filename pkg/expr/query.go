@@ -3,6 +3,8 @@ package expr
 import (
 	"embed"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 )
@@ -27,8 +29,20 @@ const (
 	// Threshold
 	QueryTypeThreshold QueryType = "threshold"
 
-	// SQL query via DuckDB
+	// SQL query over the results of other queries
 	QueryTypeSQL QueryType = "sql"
+
+	// Aggregate query results by labels
+	QueryTypeAggregate QueryType = "aggregate"
+
+	// Detect anomalies in query results
+	QueryTypeAnomaly QueryType = "anomaly"
+
+	// Forecast query results
+	QueryTypeForecast QueryType = "forecast"
+
+	// Condition with a separate recovery condition
+	QueryTypeRecovery QueryType = "recovery"
 )
 
 type MathQuery struct {
@@ -43,6 +57,9 @@ type ReduceQuery struct {
 	// The reducer
 	Reducer mathexp.ReducerID `json:"reducer"`
 
+	// Arguments of parameterised reducers, such as the percentile
+	ReducerArgs *mathexp.ReducerArgs `json:"reducerArgs,omitempty"`
+
 	// Reducer Options
 	Settings *ReduceSettings `json:"settings,omitempty"`
 }
@@ -62,6 +79,81 @@ type ResampleQuery struct {
 	Upsampler mathexp.Upsampler `json:"upsampler"`
 }
 
+// QueryType = aggregate
+type AggregateQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The aggregation
+	Aggregation mathexp.AggregationType `json:"aggregation"`
+
+	// The labels to group by. All values are aggregated into a single group when empty
+	By []string `json:"by,omitempty"`
+
+	// Arguments of parameterised aggregations, such as the k of topk
+	AggregationArgs *mathexp.AggregationArgs `json:"aggregationArgs,omitempty"`
+}
+
+// QueryType = anomaly
+type AnomalyQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The algorithm that fits the baseline of each series
+	Algorithm mathexp.AnomalyAlgorithm `json:"algorithm"`
+
+	// The period of the seasonal pattern of the series. Required by the decomposition algorithm
+	Seasonality string `json:"seasonality,omitempty" jsonschema:"example=1d,example=1h"`
+
+	// The number of deviations from the baseline that the bands are at. Defaults to 3
+	Sensitivity *float64 `json:"sensitivity,omitempty"`
+
+	// The output of the expression. Defaults to score
+	Output mathexp.AnomalyOutput `json:"output,omitempty"`
+
+	// The smoothing factors of the holt_winters algorithm
+	AlgorithmArgs *mathexp.AnomalyAlgorithmArgs `json:"algorithmArgs,omitempty"`
+}
+
+// QueryType = forecast
+type ForecastQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The method that extrapolates each series
+	Method mathexp.ForecastMethod `json:"method"`
+
+	// The prediction of the expression. Defaults to value
+	Output mathexp.ForecastOutput `json:"output,omitempty"`
+
+	// The time after the evaluation time at which the value is predicted
+	Offset string `json:"offset,omitempty" jsonschema:"example=4h,example=7d"`
+
+	// The value the time is predicted for. Required by the time_to_threshold output
+	Threshold *float64 `json:"threshold,omitempty"`
+
+	// Only the points in this time before the evaluation time are used. All points are used when empty
+	Window string `json:"window,omitempty" jsonschema:"example=6h,example=1d"`
+
+	// Resample the series to this interval with the mean of each interval before the forecast, which evenly spaces the points for the holt method
+	ResampleInterval string `json:"resampleInterval,omitempty" jsonschema:"example=1m,example=1h"`
+
+	// The smoothing factors of the holt method
+	MethodArgs *mathexp.ForecastMethodArgs `json:"methodArgs,omitempty"`
+}
+
+// QueryType = recovery
+type RecoveryQuery struct {
+	// Reference to the condition that fires the metric
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$B"`
+
+	// Reference to the condition that resolves the metric once it fires
+	RecoverWhen string `json:"recoverWhen" jsonschema:"minLength=1,example=$C"`
+
+	// The fingerprints of the metrics that fired in the previous evaluation. Set by the alert rule evaluation
+	LoadedDimensions *data.Frame `json:"loadedDimensions,omitempty"`
+}
+
 type ThresholdQuery struct {
 	// Reference to single query result
 	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
@@ -72,6 +164,9 @@ type ThresholdQuery struct {
 
 type ClassicQuery struct {
 	Conditions []classic.ConditionJSON `json:"conditions"`
+
+	// How the conditions are evaluated. Defaults to single
+	Mode classic.ConditionsMode `json:"mode,omitempty"`
 }
 
 // SQLQuery requires the sqlExpression feature flag
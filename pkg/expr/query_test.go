@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/expr/classic"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestQueryTypeDefinitions(t *testing.T) {
@@ -26,7 +27,13 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(mathexp.UpsamplerPad), // pick an example value (not the root)
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
+				reflect.TypeOf(mathexp.AggregationSum),       // pick an example value (not the root)
+				reflect.TypeOf(mathexp.AnomalyAlgorithmMAD),  // pick an example value (not the root)
+				reflect.TypeOf(mathexp.AnomalyOutputScore),   // pick an example value (not the root)
+				reflect.TypeOf(mathexp.ForecastMethodLinear), // pick an example value (not the root)
+				reflect.TypeOf(mathexp.ForecastOutputValue),  // pick an example value (not the root)
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(classic.ConditionsModeSingle), // pick an example value (not the root)
 			},
 		})
 	require.NoError(t, err)
@@ -80,6 +87,101 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAggregate),
+			GoType:         reflect.TypeOf(&AggregateQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "sum by cluster",
+					SaveModel: data.AsUnstructured(AggregateQuery{
+						Expression:  "$A",
+						Aggregation: mathexp.AggregationSum,
+						By:          []string{"cluster"},
+					}),
+				},
+				{
+					Name: "top 3 by service",
+					SaveModel: data.AsUnstructured(AggregateQuery{
+						Expression:  "$A",
+						Aggregation: mathexp.AggregationTopK,
+						By:          []string{"service"},
+						AggregationArgs: &mathexp.AggregationArgs{
+							K: util.Pointer(3),
+						},
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAnomaly),
+			GoType:         reflect.TypeOf(&AnomalyQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "score against the same time of day",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression:  "$A",
+						Algorithm:   mathexp.AnomalyAlgorithmMAD,
+						Seasonality: "1d",
+						Output:      mathexp.AnomalyOutputScore,
+					}),
+				},
+				{
+					Name: "holt-winters bands",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression:  "$A",
+						Algorithm:   mathexp.AnomalyAlgorithmHoltWinters,
+						Seasonality: "1h",
+						Sensitivity: util.Pointer(2.0),
+						Output:      mathexp.AnomalyOutputBands,
+						AlgorithmArgs: &mathexp.AnomalyAlgorithmArgs{
+							Alpha: util.Pointer(0.3),
+						},
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeForecast),
+			GoType:         reflect.TypeOf(&ForecastQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "disk usage in 4 hours",
+					SaveModel: data.AsUnstructured(ForecastQuery{
+						Expression: "$A",
+						Method:     mathexp.ForecastMethodLinear,
+						Offset:     "4h",
+						Window:     "6h",
+					}),
+				},
+				{
+					Name: "seconds until the quota is exhausted",
+					SaveModel: data.AsUnstructured(ForecastQuery{
+						Expression:       "$A",
+						Method:           mathexp.ForecastMethodHolt,
+						Output:           mathexp.ForecastOutputTimeToThreshold,
+						Threshold:        util.Pointer(1000.0),
+						Window:           "1d",
+						ResampleInterval: "5m",
+						MethodArgs: &mathexp.ForecastMethodArgs{
+							Alpha: util.Pointer(0.3),
+						},
+					}),
+				},
+			},
+		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeRecovery),
+			GoType:         reflect.TypeOf(&RecoveryQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "fire on errors and resolve after the error rate is low",
+					SaveModel: data.AsUnstructured(RecoveryQuery{
+						Expression:  "$B",
+						RecoverWhen: "$C",
+					}),
+				},
+			},
+		},
 		schemabuilder.QueryTypeInfo{
 			Discriminators: data.NewDiscriminators("type", QueryTypeSQL),
 			GoType:         reflect.TypeOf(&SQLExpression{}),
@@ -87,8 +87,8 @@ func (h *ExpressionQueryReader) ReadQuery(
 		}
 		if err == nil {
 			eq.Properties = q
-			eq.Command, err = NewReduceCommand(common.RefID,
-				q.Reducer, referenceVar, mapper)
+			eq.Command, err = NewReduceCommandWithArgs(common.RefID,
+				q.Reducer, q.ReducerArgs, referenceVar, mapper)
 		}
 
 	case QueryTypeResample:
@@ -115,12 +115,60 @@ func (h *ExpressionQueryReader) ReadQuery(
 			)
 		}
 
+	case QueryTypeAggregate:
+		q := &AggregateQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewAggregateCommand(common.RefID,
+				q.Aggregation, q.By, q.AggregationArgs, referenceVar)
+		}
+
+	case QueryTypeAnomaly:
+		q := &AnomalyQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			sensitivity := mathexp.DefaultAnomalySensitivity
+			if q.Sensitivity != nil {
+				sensitivity = *q.Sensitivity
+			}
+			eq.Properties = q
+			eq.Command, err = newAnomalyCommand(common.RefID,
+				q.Algorithm, q.Seasonality, sensitivity, q.Output, q.AlgorithmArgs, referenceVar)
+		}
+
+	case QueryTypeForecast:
+		q := &ForecastQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			var tr TimeRange
+			if common.TimeRange != nil {
+				r := gtime.NewTimeRange(common.TimeRange.From, common.TimeRange.To)
+				tr = AbsoluteTimeRange{
+					From: r.GetFromAsTimeUTC(),
+					To:   r.GetToAsTimeUTC(),
+				}
+			}
+			eq.Properties = q
+			eq.Command, err = newForecastCommand(common.RefID,
+				q.Method, q.Output, q.Offset, q.Threshold, q.Window, q.ResampleInterval, q.MethodArgs, tr, referenceVar)
+		}
+
 	case QueryTypeClassic:
 		q := &ClassicQuery{}
 		err = iter.ReadVal(q)
 		if err == nil {
 			eq.Properties = q
-			eq.Command, err = classic.NewConditionCmd(common.RefID, q.Conditions)
+			eq.Command, err = classic.NewConditionCmd(common.RefID, q.Conditions, q.Mode)
 		}
 
 	case QueryTypeSQL:
@@ -131,6 +179,14 @@ func (h *ExpressionQueryReader) ReadQuery(
 			eq.Command, err = NewSQLCommand(common.RefID, q.Expression)
 		}
 
+	case QueryTypeRecovery:
+		q := &RecoveryQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = newRecoveryCommand(common.RefID, *q, h.features)
+		}
+
 	case QueryTypeThreshold:
 		q := &ThresholdQuery{}
 		err = iter.ReadVal(q)
@@ -104,10 +104,13 @@ func (s *Service) BuildPipeline(req *Request) (DataPipeline, error) {
 }
 
 // ExecutePipeline executes an expression pipeline and returns all the results.
+// When the context is created by WithExplain, the results include how each node was executed.
 func (s *Service) ExecutePipeline(ctx context.Context, now time.Time, pipeline DataPipeline) (*backend.QueryDataResponse, error) {
 	ctx, span := s.tracer.Start(ctx, "SSE.ExecutePipeline")
 	defer span.End()
 	res := backend.NewQueryDataResponse()
+	explainer := explainerFromContext(ctx)
+	explainer.start(pipeline)
 	vars, err := pipeline.execute(ctx, now, s)
 	if err != nil {
 		return nil, err
@@ -118,6 +121,9 @@ func (s *Service) ExecutePipeline(ctx context.Context, now time.Time, pipeline D
 			Error:  val.Error,
 		}
 	}
+	if explainer != nil {
+		addExplanation(res.Responses, explainer.explanation)
+	}
 	return res, nil
 }
 
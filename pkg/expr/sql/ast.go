@@ -0,0 +1,418 @@
+package sql
+
+import (
+	"strconv"
+	"strings"
+)
+
+// selectStmt is a parsed SELECT statement, including its common table expressions.
+type selectStmt struct {
+	with     []cte
+	distinct bool
+	columns  []selectItem
+	from     tableExpr
+	where    expr
+	groupBy  []expr
+	having   expr
+	orderBy  []orderItem
+	limit    expr
+	offset   expr
+}
+
+// cte is a common table expression, defined with WITH name AS (query).
+type cte struct {
+	name  string
+	query *selectStmt
+}
+
+type selectItem struct {
+	// star is set for * and table.*, in which case expr is nil
+	star      bool
+	starTable string
+	expr      expr
+	alias     string
+	pos       Pos
+}
+
+type orderItem struct {
+	expr       expr
+	desc       bool
+	nullsFirst bool
+}
+
+// tableExpr is an item of the FROM clause.
+type tableExpr interface {
+	tableExpr()
+}
+
+type tableRef struct {
+	name  string
+	alias string
+	pos   Pos
+}
+
+type subqueryRef struct {
+	query *selectStmt
+	alias string
+	pos   Pos
+}
+
+type joinKind int
+
+const (
+	joinInner joinKind = iota
+	joinLeft
+	joinRight
+	joinFull
+	joinCross
+)
+
+type joinExpr struct {
+	kind  joinKind
+	left  tableExpr
+	right tableExpr
+	on    expr
+	pos   Pos
+}
+
+func (tableRef) tableExpr()    {}
+func (subqueryRef) tableExpr() {}
+func (joinExpr) tableExpr()    {}
+
+// expr is a SQL expression.
+type expr interface {
+	position() Pos
+}
+
+type literal struct {
+	val any
+	pos Pos
+}
+
+type columnRef struct {
+	table string
+	name  string
+	pos   Pos
+}
+
+type binaryExpr struct {
+	op    string
+	left  expr
+	right expr
+	pos   Pos
+}
+
+type unaryExpr struct {
+	op  string
+	x   expr
+	pos Pos
+}
+
+type isNullExpr struct {
+	x   expr
+	not bool
+	pos Pos
+}
+
+type inExpr struct {
+	x    expr
+	list []expr
+	not  bool
+	pos  Pos
+}
+
+type betweenExpr struct {
+	x    expr
+	low  expr
+	high expr
+	not  bool
+	pos  Pos
+}
+
+type likeExpr struct {
+	x          expr
+	pattern    expr
+	not        bool
+	ignoreCase bool
+	pos        Pos
+}
+
+type whenClause struct {
+	cond   expr
+	result expr
+}
+
+type caseExpr struct {
+	operand expr
+	whens   []whenClause
+	els     expr
+	pos     Pos
+}
+
+type castExpr struct {
+	x   expr
+	typ dataType
+	pos Pos
+}
+
+type funcCall struct {
+	name     string
+	args     []expr
+	star     bool
+	distinct bool
+	over     *windowSpec
+	pos      Pos
+}
+
+type windowSpec struct {
+	partitionBy []expr
+	orderBy     []orderItem
+	frame       *windowFrame
+}
+
+// windowFrame is a ROWS BETWEEN start AND end frame, where the offsets are relative to the current row.
+// Unbounded offsets are nil.
+type windowFrame struct {
+	start *int64
+	end   *int64
+}
+
+func (e *literal) position() Pos     { return e.pos }
+func (e *columnRef) position() Pos   { return e.pos }
+func (e *binaryExpr) position() Pos  { return e.pos }
+func (e *unaryExpr) position() Pos   { return e.pos }
+func (e *isNullExpr) position() Pos  { return e.pos }
+func (e *inExpr) position() Pos      { return e.pos }
+func (e *betweenExpr) position() Pos { return e.pos }
+func (e *likeExpr) position() Pos    { return e.pos }
+func (e *caseExpr) position() Pos    { return e.pos }
+func (e *castExpr) position() Pos    { return e.pos }
+func (e *funcCall) position() Pos    { return e.pos }
+
+// exprString returns the SQL text of the expression, which is used as the name of the output column.
+func exprString(e expr) string {
+	var sb strings.Builder
+	writeExpr(&sb, e, false)
+	return sb.String()
+}
+
+// exprKey returns a key of the expression that is equal for expressions that only differ
+// in the case of identifiers, so that for example GROUP BY Host matches SELECT host.
+func exprKey(e expr) string {
+	var sb strings.Builder
+	writeExpr(&sb, e, true)
+	return sb.String()
+}
+
+func writeExpr(sb *strings.Builder, e expr, key bool) {
+	ident := func(s string) string {
+		if key {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+	switch e := e.(type) {
+	case *literal:
+		switch v := e.val.(type) {
+		case nil:
+			sb.WriteString("NULL")
+		case string:
+			sb.WriteString("'" + strings.ReplaceAll(v, "'", "''") + "'")
+		case int64:
+			sb.WriteString(strconv.FormatInt(v, 10))
+		case float64:
+			sb.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+		case bool:
+			sb.WriteString(strings.ToUpper(strconv.FormatBool(v)))
+		}
+	case *columnRef:
+		if e.table != "" {
+			sb.WriteString(ident(e.table) + ".")
+		}
+		sb.WriteString(ident(e.name))
+	case *binaryExpr:
+		sb.WriteString("(")
+		writeExpr(sb, e.left, key)
+		sb.WriteString(" " + e.op + " ")
+		writeExpr(sb, e.right, key)
+		sb.WriteString(")")
+	case *unaryExpr:
+		if e.op == "NOT" {
+			sb.WriteString("NOT ")
+		} else {
+			sb.WriteString(e.op)
+		}
+		writeExpr(sb, e.x, key)
+	case *isNullExpr:
+		writeExpr(sb, e.x, key)
+		if e.not {
+			sb.WriteString(" IS NOT NULL")
+		} else {
+			sb.WriteString(" IS NULL")
+		}
+	case *inExpr:
+		writeExpr(sb, e.x, key)
+		if e.not {
+			sb.WriteString(" NOT")
+		}
+		sb.WriteString(" IN (")
+		writeExprs(sb, e.list, key)
+		sb.WriteString(")")
+	case *betweenExpr:
+		writeExpr(sb, e.x, key)
+		if e.not {
+			sb.WriteString(" NOT")
+		}
+		sb.WriteString(" BETWEEN ")
+		writeExpr(sb, e.low, key)
+		sb.WriteString(" AND ")
+		writeExpr(sb, e.high, key)
+	case *likeExpr:
+		writeExpr(sb, e.x, key)
+		if e.not {
+			sb.WriteString(" NOT")
+		}
+		if e.ignoreCase {
+			sb.WriteString(" ILIKE ")
+		} else {
+			sb.WriteString(" LIKE ")
+		}
+		writeExpr(sb, e.pattern, key)
+	case *caseExpr:
+		sb.WriteString("CASE")
+		if e.operand != nil {
+			sb.WriteString(" ")
+			writeExpr(sb, e.operand, key)
+		}
+		for _, w := range e.whens {
+			sb.WriteString(" WHEN ")
+			writeExpr(sb, w.cond, key)
+			sb.WriteString(" THEN ")
+			writeExpr(sb, w.result, key)
+		}
+		if e.els != nil {
+			sb.WriteString(" ELSE ")
+			writeExpr(sb, e.els, key)
+		}
+		sb.WriteString(" END")
+	case *castExpr:
+		sb.WriteString("CAST(")
+		writeExpr(sb, e.x, key)
+		sb.WriteString(" AS " + e.typ.String() + ")")
+	case *funcCall:
+		sb.WriteString(e.name + "(")
+		if e.distinct {
+			sb.WriteString("DISTINCT ")
+		}
+		if e.star {
+			sb.WriteString("*")
+		}
+		writeExprs(sb, e.args, key)
+		sb.WriteString(")")
+		if e.over != nil {
+			sb.WriteString(" OVER (")
+			writeWindow(sb, e.over, key)
+			sb.WriteString(")")
+		}
+	}
+}
+
+func writeExprs(sb *strings.Builder, list []expr, key bool) {
+	for i, e := range list {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		writeExpr(sb, e, key)
+	}
+}
+
+func writeWindow(sb *strings.Builder, w *windowSpec, key bool) {
+	sep := ""
+	if len(w.partitionBy) > 0 {
+		sb.WriteString("PARTITION BY ")
+		writeExprs(sb, w.partitionBy, key)
+		sep = " "
+	}
+	if len(w.orderBy) > 0 {
+		sb.WriteString(sep + "ORDER BY ")
+		for i, o := range w.orderBy {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			writeExpr(sb, o.expr, key)
+			if o.desc {
+				sb.WriteString(" DESC")
+			}
+			if o.nullsFirst {
+				sb.WriteString(" NULLS FIRST")
+			}
+		}
+		sep = " "
+	}
+	if w.frame != nil {
+		sb.WriteString(sep + "ROWS BETWEEN " + frameBound(w.frame.start, "PRECEDING") + " AND " + frameBound(w.frame.end, "FOLLOWING"))
+	}
+}
+
+func frameBound(offset *int64, unbounded string) string {
+	switch {
+	case offset == nil:
+		return "UNBOUNDED " + unbounded
+	case *offset == 0:
+		return "CURRENT ROW"
+	case *offset < 0:
+		return strconv.FormatInt(-*offset, 10) + " PRECEDING"
+	default:
+		return strconv.FormatInt(*offset, 10) + " FOLLOWING"
+	}
+}
+
+// walkExpr calls fn for the expression and its sub expressions, until fn returns false.
+// Window specifications are only walked into when windows is set.
+func walkExpr(e expr, windows bool, fn func(expr) bool) {
+	if e == nil || !fn(e) {
+		return
+	}
+	switch e := e.(type) {
+	case *binaryExpr:
+		walkExpr(e.left, windows, fn)
+		walkExpr(e.right, windows, fn)
+	case *unaryExpr:
+		walkExpr(e.x, windows, fn)
+	case *isNullExpr:
+		walkExpr(e.x, windows, fn)
+	case *inExpr:
+		walkExpr(e.x, windows, fn)
+		for _, x := range e.list {
+			walkExpr(x, windows, fn)
+		}
+	case *betweenExpr:
+		walkExpr(e.x, windows, fn)
+		walkExpr(e.low, windows, fn)
+		walkExpr(e.high, windows, fn)
+	case *likeExpr:
+		walkExpr(e.x, windows, fn)
+		walkExpr(e.pattern, windows, fn)
+	case *caseExpr:
+		walkExpr(e.operand, windows, fn)
+		for _, w := range e.whens {
+			walkExpr(w.cond, windows, fn)
+			walkExpr(w.result, windows, fn)
+		}
+		walkExpr(e.els, windows, fn)
+	case *castExpr:
+		walkExpr(e.x, windows, fn)
+	case *funcCall:
+		for _, x := range e.args {
+			walkExpr(x, windows, fn)
+		}
+		if e.over != nil && windows {
+			for _, x := range e.over.partitionBy {
+				walkExpr(x, windows, fn)
+			}
+			for _, o := range e.over.orderBy {
+				walkExpr(o.expr, windows, fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,603 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+type column struct {
+	table string
+	name  string
+	typ   dataType
+}
+
+// slot is an expression that is already computed at an index of the row,
+// such as an aggregate or a window function.
+type slot struct {
+	idx int
+	typ dataType
+}
+
+// scope resolves the columns and computed expressions that an expression can reference.
+type scope struct {
+	columns []column
+	// slots are the computed expressions by their exprKey.
+	slots map[string]slot
+	// grouped is set for the scope after GROUP BY, where columns can only be referenced through
+	// the GROUP BY expressions, which are in columnSlots by the index of the column.
+	grouped     bool
+	columnSlots map[int]slot
+	now         time.Time
+}
+
+// evaluator is a compiled expression.
+type evaluator struct {
+	typ  dataType
+	eval func(row []any) (any, error)
+}
+
+func newError(pos Pos, format string, args ...any) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// resolve returns the index of the column that the reference refers to.
+func (s *scope) resolve(ref *columnRef) (int, error) {
+	var matches, exact []int
+	for i, c := range s.columns {
+		if ref.table != "" && !strings.EqualFold(ref.table, c.table) {
+			continue
+		}
+		if !strings.EqualFold(ref.name, c.name) {
+			continue
+		}
+		matches = append(matches, i)
+		if c.name == ref.name && (ref.table == "" || c.table == ref.table) {
+			exact = append(exact, i)
+		}
+	}
+	if len(matches) > 1 && len(exact) > 0 {
+		matches = exact
+	}
+	switch len(matches) {
+	case 0:
+		return 0, newError(ref.pos, "column %s not found", quoteRef(ref))
+	case 1:
+		return matches[0], nil
+	}
+	return 0, newError(ref.pos, "column reference %s is ambiguous", quoteRef(ref))
+}
+
+func quoteRef(ref *columnRef) string {
+	if ref.table != "" {
+		return fmt.Sprintf(`"%s"."%s"`, ref.table, ref.name)
+	}
+	return fmt.Sprintf(`"%s"`, ref.name)
+}
+
+func (s *scope) compile(e expr) (*evaluator, error) {
+	if sl, ok := s.slots[exprKey(e)]; ok {
+		return slotEvaluator(sl), nil
+	}
+	switch e := e.(type) {
+	case *literal:
+		return constEvaluator(e.val), nil
+	case *columnRef:
+		idx, err := s.resolve(e)
+		if err != nil {
+			return nil, err
+		}
+		if s.grouped {
+			sl, ok := s.columnSlots[idx]
+			if !ok {
+				return nil, newError(e.pos, "column %s must appear in the GROUP BY clause or be used in an aggregate function", quoteRef(e))
+			}
+			return slotEvaluator(sl), nil
+		}
+		return slotEvaluator(slot{idx: idx, typ: s.columns[idx].typ}), nil
+	case *binaryExpr:
+		return s.compileBinary(e)
+	case *unaryExpr:
+		return s.compileUnary(e)
+	case *isNullExpr:
+		x, err := s.compile(e.x)
+		if err != nil {
+			return nil, err
+		}
+		return &evaluator{typ: typeBool, eval: func(row []any) (any, error) {
+			v, err := x.eval(row)
+			if err != nil {
+				return nil, err
+			}
+			return (v == nil) != e.not, nil
+		}}, nil
+	case *inExpr:
+		return s.compileIn(e)
+	case *betweenExpr:
+		return s.compile(&binaryExpr{
+			op:    "AND",
+			left:  &binaryExpr{op: ">=", left: e.x, right: e.low, pos: e.pos},
+			right: &binaryExpr{op: "<=", left: e.x, right: e.high, pos: e.pos},
+			pos:   e.pos,
+		})
+	case *likeExpr:
+		return s.compileLike(e)
+	case *caseExpr:
+		return s.compileCase(e)
+	case *castExpr:
+		x, err := s.compile(e.x)
+		if err != nil {
+			return nil, err
+		}
+		return &evaluator{typ: e.typ, eval: func(row []any) (any, error) {
+			v, err := x.eval(row)
+			if err != nil {
+				return nil, err
+			}
+			v, err = castValue(v, e.typ)
+			if err != nil {
+				return nil, newError(e.pos, "%s", err)
+			}
+			return v, nil
+		}}, nil
+	case *funcCall:
+		return s.compileFunc(e)
+	}
+	return nil, newError(e.position(), "unsupported expression")
+}
+
+// compileBool compiles an expression that must be a boolean, such as a WHERE condition.
+func (s *scope) compileBool(e expr, clause string) (*evaluator, error) {
+	ev, err := s.compile(e)
+	if err != nil {
+		return nil, err
+	}
+	if ev.typ != typeBool && ev.typ != typeNull {
+		return nil, newError(e.position(), "argument of %s must be a boolean, got %s", clause, ev.typ)
+	}
+	return ev, nil
+}
+
+func slotEvaluator(sl slot) *evaluator {
+	return &evaluator{typ: sl.typ, eval: func(row []any) (any, error) {
+		return row[sl.idx], nil
+	}}
+}
+
+func constEvaluator(v any) *evaluator {
+	typ := typeNull
+	switch v.(type) {
+	case bool:
+		typ = typeBool
+	case int64:
+		typ = typeInt
+	case float64:
+		typ = typeFloat
+	case string:
+		typ = typeString
+	case time.Time:
+		typ = typeTime
+	}
+	return &evaluator{typ: typ, eval: func([]any) (any, error) { return v, nil }}
+}
+
+// comparable returns the evaluators converted so that they can be compared. A string literal
+// that is compared with a time is converted to a time, such as time > '2024-01-01'.
+func comparable(pos Pos, a, b *evaluator, aExpr, bExpr expr) (*evaluator, *evaluator, error) {
+	timeLiteral := func(ev *evaluator, e expr) (*evaluator, error) {
+		lit, ok := e.(*literal)
+		if !ok {
+			return nil, newError(e.position(), "can not compare %s with %s", typeTime, ev.typ)
+		}
+		t, err := parseTime(lit.val.(string))
+		if err != nil {
+			return nil, newError(e.position(), "%s", err)
+		}
+		return constEvaluator(t), nil
+	}
+	var err error
+	switch {
+	case a.typ == typeTime && b.typ == typeString:
+		b, err = timeLiteral(b, bExpr)
+	case a.typ == typeString && b.typ == typeTime:
+		a, err = timeLiteral(a, aExpr)
+	default:
+		if _, ok := commonType(a.typ, b.typ); !ok {
+			err = newError(pos, "can not compare %s with %s", a.typ, b.typ)
+		}
+	}
+	return a, b, err
+}
+
+func (s *scope) compileBinary(e *binaryExpr) (*evaluator, error) {
+	left, err := s.compile(e.left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := s.compile(e.right)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "AND", "OR":
+		for _, ev := range []*evaluator{left, right} {
+			if ev.typ != typeBool && ev.typ != typeNull {
+				return nil, newError(e.pos, "arguments of %s must be booleans, got %s", e.op, ev.typ)
+			}
+		}
+		// the result is decided by a false operand for AND and a true operand for OR, even if the other one is NULL
+		decisive := e.op == "OR"
+		return &evaluator{typ: typeBool, eval: func(row []any) (any, error) {
+			l, err := left.eval(row)
+			if err != nil {
+				return nil, err
+			}
+			if l == decisive {
+				return decisive, nil
+			}
+			r, err := right.eval(row)
+			if err != nil {
+				return nil, err
+			}
+			if r == decisive {
+				return decisive, nil
+			}
+			if l == nil || r == nil {
+				return nil, nil
+			}
+			return !decisive, nil
+		}}, nil
+	case "=", "<>", "<", "<=", ">", ">=":
+		left, right, err = comparable(e.pos, left, right, e.left, e.right)
+		if err != nil {
+			return nil, err
+		}
+		test := comparisons[e.op]
+		return &evaluator{typ: typeBool, eval: func(row []any) (any, error) {
+			l, r, err := evalBoth(left, right, row)
+			if l == nil || r == nil || err != nil {
+				return nil, err
+			}
+			return test(compareValues(l, r)), nil
+		}}, nil
+	case "||":
+		return &evaluator{typ: typeString, eval: func(row []any) (any, error) {
+			l, r, err := evalBoth(left, right, row)
+			if l == nil || r == nil || err != nil {
+				return nil, err
+			}
+			return formatValue(l) + formatValue(r), nil
+		}}, nil
+	}
+
+	// arithmetic
+	for _, ev := range []*evaluator{left, right} {
+		if ev.typ != typeNull && !ev.typ.isNumeric() {
+			return nil, newError(e.pos, "operator %s is not defined for %s and %s", e.op, left.typ, right.typ)
+		}
+	}
+	typ, _ := commonType(left.typ, right.typ)
+	if typ == typeNull {
+		typ = typeInt
+	}
+	if e.op == "/" {
+		// division always returns a float, so that 1 / 2 is 0.5
+		typ = typeFloat
+	}
+	op := e.op
+	return &evaluator{typ: typ, eval: func(row []any) (any, error) {
+		l, r, err := evalBoth(left, right, row)
+		if l == nil || r == nil || err != nil {
+			return nil, err
+		}
+		li, lInt := l.(int64)
+		ri, rInt := r.(int64)
+		if lInt && rInt && typ == typeInt {
+			switch op {
+			case "+":
+				return li + ri, nil
+			case "-":
+				return li - ri, nil
+			case "*":
+				return li * ri, nil
+			case "%":
+				if ri == 0 {
+					return nil, nil
+				}
+				return li % ri, nil
+			}
+		}
+		lf, rf := toFloat(l), toFloat(r)
+		switch op {
+		case "+":
+			return lf + rf, nil
+		case "-":
+			return lf - rf, nil
+		case "*":
+			return lf * rf, nil
+		case "/":
+			if rf == 0 {
+				return nil, nil
+			}
+			return lf / rf, nil
+		}
+		if rf == 0 {
+			return nil, nil
+		}
+		return math.Mod(lf, rf), nil
+	}}, nil
+}
+
+var comparisons = map[string]func(c int) bool{
+	"=":  func(c int) bool { return c == 0 },
+	"<>": func(c int) bool { return c != 0 },
+	"<":  func(c int) bool { return c < 0 },
+	"<=": func(c int) bool { return c <= 0 },
+	">":  func(c int) bool { return c > 0 },
+	">=": func(c int) bool { return c >= 0 },
+}
+
+func evalBoth(left, right *evaluator, row []any) (any, any, error) {
+	l, err := left.eval(row)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := right.eval(row)
+	return l, r, err
+}
+
+func (s *scope) compileUnary(e *unaryExpr) (*evaluator, error) {
+	x, err := s.compile(e.x)
+	if err != nil {
+		return nil, err
+	}
+	if e.op == "NOT" {
+		if x.typ != typeBool && x.typ != typeNull {
+			return nil, newError(e.pos, "argument of NOT must be a boolean, got %s", x.typ)
+		}
+		return &evaluator{typ: typeBool, eval: func(row []any) (any, error) {
+			v, err := x.eval(row)
+			if v == nil || err != nil {
+				return nil, err
+			}
+			return !v.(bool), nil
+		}}, nil
+	}
+	if x.typ != typeNull && !x.typ.isNumeric() {
+		return nil, newError(e.pos, "operator - is not defined for %s", x.typ)
+	}
+	return &evaluator{typ: x.typ, eval: func(row []any) (any, error) {
+		v, err := x.eval(row)
+		if v == nil || err != nil {
+			return nil, err
+		}
+		if i, ok := v.(int64); ok {
+			return -i, nil
+		}
+		return -v.(float64), nil
+	}}, nil
+}
+
+func (s *scope) compileIn(e *inExpr) (*evaluator, error) {
+	x, err := s.compile(e.x)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*evaluator, len(e.list))
+	for i, item := range e.list {
+		ev, err := s.compile(item)
+		if err != nil {
+			return nil, err
+		}
+		if _, list[i], err = comparable(item.position(), x, ev, e.x, item); err != nil {
+			return nil, err
+		}
+	}
+	return &evaluator{typ: typeBool, eval: func(row []any) (any, error) {
+		v, err := x.eval(row)
+		if v == nil || err != nil {
+			return nil, err
+		}
+		sawNull := false
+		for _, ev := range list {
+			item, err := ev.eval(row)
+			if err != nil {
+				return nil, err
+			}
+			if item == nil {
+				sawNull = true
+				continue
+			}
+			if compareValues(v, item) == 0 {
+				return !e.not, nil
+			}
+		}
+		if sawNull {
+			return nil, nil
+		}
+		return e.not, nil
+	}}, nil
+}
+
+func (s *scope) compileLike(e *likeExpr) (*evaluator, error) {
+	x, err := s.compile(e.x)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := s.compile(e.pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, ev := range []*evaluator{x, pattern} {
+		if ev.typ != typeString && ev.typ != typeNull {
+			return nil, newError(e.pos, "arguments of LIKE must be strings, got %s", ev.typ)
+		}
+	}
+	var mtx sync.Mutex
+	cache := map[string]*regexp.Regexp{}
+	return &evaluator{typ: typeBool, eval: func(row []any) (any, error) {
+		v, p, err := evalBoth(x, pattern, row)
+		if v == nil || p == nil || err != nil {
+			return nil, err
+		}
+		mtx.Lock()
+		re, ok := cache[p.(string)]
+		if !ok {
+			re = likeRegexp(p.(string), e.ignoreCase)
+			cache[p.(string)] = re
+		}
+		mtx.Unlock()
+		return re.MatchString(v.(string)) != e.not, nil
+	}}, nil
+}
+
+// likeRegexp converts a LIKE pattern, where % matches any string and _ matches any character, to a regular expression.
+func likeRegexp(pattern string, ignoreCase bool) *regexp.Regexp {
+	var sb strings.Builder
+	if ignoreCase {
+		sb.WriteString("(?i)")
+	}
+	sb.WriteString("(?s)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+func (s *scope) compileCase(e *caseExpr) (*evaluator, error) {
+	var operand *evaluator
+	var err error
+	if e.operand != nil {
+		if operand, err = s.compile(e.operand); err != nil {
+			return nil, err
+		}
+	}
+	conds := make([]*evaluator, len(e.whens))
+	results := make([]*evaluator, len(e.whens), len(e.whens)+1)
+	typ := typeNull
+	for i, w := range e.whens {
+		if operand != nil {
+			if conds[i], err = s.compile(w.cond); err != nil {
+				return nil, err
+			}
+			if _, conds[i], err = comparable(w.cond.position(), operand, conds[i], e.operand, w.cond); err != nil {
+				return nil, err
+			}
+		} else if conds[i], err = s.compileBool(w.cond, "WHEN"); err != nil {
+			return nil, err
+		}
+		if results[i], err = s.compile(w.result); err != nil {
+			return nil, err
+		}
+		var ok bool
+		if typ, ok = commonType(typ, results[i].typ); !ok {
+			return nil, newError(w.result.position(), "CASE types %s and %s can not be matched", typ, results[i].typ)
+		}
+	}
+	els := constEvaluator(nil)
+	if e.els != nil {
+		if els, err = s.compile(e.els); err != nil {
+			return nil, err
+		}
+		var ok bool
+		if typ, ok = commonType(typ, els.typ); !ok {
+			return nil, newError(e.els.position(), "CASE types %s and %s can not be matched", typ, els.typ)
+		}
+	}
+	return &evaluator{typ: typ, eval: func(row []any) (any, error) {
+		var op any
+		if operand != nil {
+			var err error
+			if op, err = operand.eval(row); err != nil {
+				return nil, err
+			}
+		}
+		for i, cond := range conds {
+			c, err := cond.eval(row)
+			if err != nil {
+				return nil, err
+			}
+			matched := c == true
+			if operand != nil {
+				matched = op != nil && c != nil && compareValues(op, c) == 0
+			}
+			if matched {
+				v, err := results[i].eval(row)
+				return convert(v, typ), err
+			}
+		}
+		v, err := els.eval(row)
+		return convert(v, typ), err
+	}}, nil
+}
+
+func (s *scope) compileFunc(e *funcCall) (*evaluator, error) {
+	if e.over != nil {
+		return nil, newError(e.pos, "window function %s is not allowed here", e.name)
+	}
+	if isAggregate(e) {
+		return nil, newError(e.pos, "aggregate function %s is not allowed here", e.name)
+	}
+	if windowFuncs[e.name] {
+		return nil, newError(e.pos, "window function %s requires an OVER clause", e.name)
+	}
+	if e.name == "now" || e.name == "current_timestamp" {
+		if len(e.args) > 0 {
+			return nil, newError(e.pos, "function %s does not take arguments", e.name)
+		}
+		return constEvaluator(s.now), nil
+	}
+	f, ok := scalarFuncs[e.name]
+	if !ok {
+		return nil, newError(e.pos, "function %s does not exist", e.name)
+	}
+	if e.star || e.distinct {
+		return nil, newError(e.pos, "function %s is not an aggregate function", e.name)
+	}
+	if len(e.args) < f.minArgs || (f.maxArgs >= 0 && len(e.args) > f.maxArgs) {
+		return nil, newError(e.pos, "wrong number of arguments for function %s: got %d", e.name, len(e.args))
+	}
+	args := make([]*evaluator, len(e.args))
+	types := make([]dataType, len(e.args))
+	for i, a := range e.args {
+		ev, err := s.compile(a)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = ev
+		types[i] = ev.typ
+	}
+	typ, err := f.returns(types)
+	if err != nil {
+		return nil, newError(e.pos, "invalid arguments for function %s: %s", e.name, err)
+	}
+	return &evaluator{typ: typ, eval: func(row []any) (any, error) {
+		values := make([]any, len(args))
+		for i, a := range args {
+			v, err := a.eval(row)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil && !f.nullSafe {
+				return nil, nil
+			}
+			values[i] = convert(v, types[i])
+		}
+		v, err := f.call(values)
+		if err != nil {
+			return nil, newError(e.pos, "%s: %s", e.name, err)
+		}
+		return convert(v, typ), nil
+	}}, nil
+}
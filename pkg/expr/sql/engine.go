@@ -0,0 +1,1059 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// relation is a table of rows, such as an input table or the result of a query.
+type relation struct {
+	columns []column
+	rows    [][]any
+}
+
+type execContext struct {
+	ctx    context.Context
+	now    time.Time
+	tables map[string]*relation
+	// n counts the rows that have been processed, to check for cancellation every so often.
+	n int
+}
+
+// checkCancel returns the error of the context every 1024 calls, when it is done.
+func (ec *execContext) checkCancel() error {
+	ec.n++
+	if ec.n%1024 == 0 {
+		return ec.ctx.Err()
+	}
+	return nil
+}
+
+// lookupTable returns a relation by name, where common table expressions shadow input tables.
+// Names are matched case-insensitively when there is no exact match.
+func lookupTable(name string, tables ...map[string]*relation) (*relation, bool) {
+	for _, t := range tables {
+		if r, ok := t[name]; ok {
+			return r, true
+		}
+		for n, r := range t {
+			if strings.EqualFold(n, name) {
+				return r, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func (ec *execContext) executeSelect(stmt *selectStmt, ctes map[string]*relation) (*relation, error) {
+	if len(stmt.with) > 0 {
+		scoped := make(map[string]*relation, len(ctes)+len(stmt.with))
+		for k, v := range ctes {
+			scoped[k] = v
+		}
+		for _, c := range stmt.with {
+			rel, err := ec.executeSelect(c.query, scoped)
+			if err != nil {
+				return nil, err
+			}
+			scoped[c.name] = rel
+		}
+		ctes = scoped
+	}
+
+	input := &relation{rows: [][]any{{}}}
+	if stmt.from != nil {
+		var err error
+		if input, err = ec.from(stmt.from, ctes); err != nil {
+			return nil, err
+		}
+	}
+	sc := &scope{columns: input.columns, slots: map[string]slot{}, now: ec.now}
+	rows := input.rows
+
+	if stmt.where != nil {
+		cond, err := sc.compileBool(stmt.where, "WHERE")
+		if err != nil {
+			return nil, err
+		}
+		if rows, err = ec.filter(rows, cond); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if sc, rows, err = ec.group(stmt, sc, rows); err != nil {
+		return nil, err
+	}
+
+	if stmt.having != nil {
+		cond, err := sc.compileBool(stmt.having, "HAVING")
+		if err != nil {
+			return nil, err
+		}
+		if rows, err = ec.filter(rows, cond); err != nil {
+			return nil, err
+		}
+	}
+
+	if rows, err = ec.windows(stmt, sc, rows); err != nil {
+		return nil, err
+	}
+
+	return ec.project(stmt, sc, rows)
+}
+
+func (ec *execContext) filter(rows [][]any, cond *evaluator) ([][]any, error) {
+	var filtered [][]any
+	for _, row := range rows {
+		if err := ec.checkCancel(); err != nil {
+			return nil, err
+		}
+		v, err := cond.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		if v == true {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered, nil
+}
+
+func (ec *execContext) from(te tableExpr, ctes map[string]*relation) (*relation, error) {
+	switch te := te.(type) {
+	case *tableRef:
+		rel, ok := lookupTable(te.name, ctes, ec.tables)
+		if !ok {
+			return nil, newError(te.pos, "table %s not found", te.name)
+		}
+		name := te.name
+		if te.alias != "" {
+			name = te.alias
+		}
+		return withTable(rel, name), nil
+	case *subqueryRef:
+		rel, err := ec.executeSelect(te.query, ctes)
+		if err != nil {
+			return nil, err
+		}
+		return withTable(rel, te.alias), nil
+	case *joinExpr:
+		left, err := ec.from(te.left, ctes)
+		if err != nil {
+			return nil, err
+		}
+		right, err := ec.from(te.right, ctes)
+		if err != nil {
+			return nil, err
+		}
+		return ec.join(te, left, right)
+	}
+	return nil, fmt.Errorf("unsupported table expression %T", te)
+}
+
+// withTable returns the relation with its columns qualified by the table name.
+func withTable(rel *relation, table string) *relation {
+	columns := make([]column, len(rel.columns))
+	for i, c := range rel.columns {
+		columns[i] = column{table: table, name: c.name, typ: c.typ}
+	}
+	return &relation{columns: columns, rows: rel.rows}
+}
+
+func (ec *execContext) join(j *joinExpr, left, right *relation) (*relation, error) {
+	columns := make([]column, 0, len(left.columns)+len(right.columns))
+	columns = append(append(columns, left.columns...), right.columns...)
+	out := &relation{columns: columns}
+
+	var on *evaluator
+	var leftKeys, rightKeys []*evaluator
+	if j.on != nil {
+		sc := &scope{columns: columns, now: ec.now}
+		var err error
+		if on, err = sc.compileBool(j.on, "JOIN"); err != nil {
+			return nil, err
+		}
+		leftKeys, rightKeys = equiJoinKeys(j.on, left, right, ec.now)
+	}
+
+	// with equality conditions, only the rows of the right side with the same key are candidates for a match
+	var buckets map[string][]int
+	if len(leftKeys) > 0 {
+		buckets = map[string][]int{}
+		for i, row := range right.rows {
+			key, ok, err := joinKey(rightKeys, row)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				buckets[key] = append(buckets[key], i)
+			}
+		}
+	}
+	all := make([]int, len(right.rows))
+	for i := range all {
+		all[i] = i
+	}
+
+	concat := func(l, r []any) []any {
+		row := make([]any, 0, len(columns))
+		if l == nil {
+			l = make([]any, len(left.columns))
+		}
+		if r == nil {
+			r = make([]any, len(right.columns))
+		}
+		return append(append(row, l...), r...)
+	}
+
+	matchedRight := make([]bool, len(right.rows))
+	for _, l := range left.rows {
+		candidates := all
+		if buckets != nil {
+			key, ok, err := joinKey(leftKeys, l)
+			if err != nil {
+				return nil, err
+			}
+			candidates = nil
+			if ok {
+				candidates = buckets[key]
+			}
+		}
+		matched := false
+		for _, i := range candidates {
+			if err := ec.checkCancel(); err != nil {
+				return nil, err
+			}
+			row := concat(l, right.rows[i])
+			if on != nil {
+				v, err := on.eval(row)
+				if err != nil {
+					return nil, err
+				}
+				if v != true {
+					continue
+				}
+			}
+			matched = true
+			matchedRight[i] = true
+			out.rows = append(out.rows, row)
+		}
+		if !matched && (j.kind == joinLeft || j.kind == joinFull) {
+			out.rows = append(out.rows, concat(l, nil))
+		}
+	}
+	if j.kind == joinRight || j.kind == joinFull {
+		for i, r := range right.rows {
+			if !matchedRight[i] {
+				out.rows = append(out.rows, concat(nil, r))
+			}
+		}
+	}
+	return out, nil
+}
+
+// equiJoinKeys returns the expressions of the equality conditions of the ON clause that compare
+// an expression of the left side with an expression of the right side, such as A.name = B.name.
+func equiJoinKeys(on expr, left, right *relation, now time.Time) ([]*evaluator, []*evaluator) {
+	leftScope := &scope{columns: left.columns, now: now}
+	rightScope := &scope{columns: right.columns, now: now}
+	var leftKeys, rightKeys []*evaluator
+	for _, c := range conjuncts(on) {
+		b, ok := c.(*binaryExpr)
+		if !ok || b.op != "=" {
+			continue
+		}
+		for _, sides := range [][2]expr{{b.left, b.right}, {b.right, b.left}} {
+			l, err := leftScope.compile(sides[0])
+			if err != nil {
+				continue
+			}
+			r, err := rightScope.compile(sides[1])
+			if err != nil {
+				continue
+			}
+			if _, ok := commonType(l.typ, r.typ); !ok {
+				continue
+			}
+			leftKeys = append(leftKeys, l)
+			rightKeys = append(rightKeys, r)
+			break
+		}
+	}
+	return leftKeys, rightKeys
+}
+
+func conjuncts(e expr) []expr {
+	if b, ok := e.(*binaryExpr); ok && b.op == "AND" {
+		return append(conjuncts(b.left), conjuncts(b.right)...)
+	}
+	return []expr{e}
+}
+
+// joinKey returns the key of the row for the join keys, or false if any of them is NULL, since NULL never matches.
+func joinKey(keys []*evaluator, row []any) (string, bool, error) {
+	values := make([]any, len(keys))
+	for i, k := range keys {
+		v, err := k.eval(row)
+		if err != nil || v == nil {
+			return "", false, err
+		}
+		values[i] = v
+	}
+	return rowKey(values), true, nil
+}
+
+// aggregateCall is an aggregate function of a grouped query.
+type aggregateCall struct {
+	call *funcCall
+	fn   aggregateFunc
+	arg  *evaluator
+	typ  dataType
+}
+
+func (a *aggregateCall) newState() aggState {
+	argType := typeNull
+	if a.arg != nil {
+		argType = a.arg.typ
+	}
+	s := a.fn.newState(argType)
+	if a.call.distinct {
+		s = &distinctState{aggState: s, seen: map[string]bool{}}
+	}
+	return s
+}
+
+// add adds the value of the argument for the row to the state. NULL values are ignored, except for count(*).
+func (a *aggregateCall) add(s aggState, row []any) error {
+	if a.arg == nil {
+		s.add(true)
+		return nil
+	}
+	v, err := a.arg.eval(row)
+	if err != nil || v == nil {
+		return err
+	}
+	s.add(convert(v, a.arg.typ))
+	return nil
+}
+
+// compileAggregate compiles the argument of an aggregate function in the scope of the input rows.
+func compileAggregate(sc *scope, f *funcCall) (*aggregateCall, error) {
+	fn := aggregateFuncs[f.name]
+	a := &aggregateCall{call: f, fn: fn}
+	switch {
+	case f.star:
+		if f.name != "count" {
+			return nil, newError(f.pos, "%s(*) is not supported", f.name)
+		}
+	case len(f.args) != 1:
+		return nil, newError(f.pos, "wrong number of arguments for function %s: got %d", f.name, len(f.args))
+	default:
+		arg, err := sc.compile(f.args[0])
+		if err != nil {
+			return nil, err
+		}
+		a.arg = arg
+	}
+	argType := typeNull
+	if a.arg != nil {
+		argType = a.arg.typ
+	}
+	typ, err := fn.returns(argType)
+	if err != nil {
+		return nil, newError(f.pos, "invalid arguments for function %s: %s", f.name, err)
+	}
+	a.typ = typ
+	return a, nil
+}
+
+// group groups the rows by the GROUP BY expressions and computes the aggregate functions. It returns
+// the scope of the grouped rows, where each row holds the values of the GROUP BY expressions followed
+// by the values of the aggregate functions. Queries without GROUP BY or aggregates are returned as they are.
+func (ec *execContext) group(stmt *selectStmt, sc *scope, rows [][]any) (*scope, [][]any, error) {
+	var calls []*funcCall
+	seen := map[string]bool{}
+	collect := func(e expr) {
+		walkExpr(e, true, func(e expr) bool {
+			f, ok := e.(*funcCall)
+			if !ok || !isAggregate(f) {
+				return true
+			}
+			if key := exprKey(f); !seen[key] {
+				seen[key] = true
+				calls = append(calls, f)
+			}
+			return false
+		})
+	}
+	for _, item := range stmt.columns {
+		collect(item.expr)
+	}
+	collect(stmt.having)
+	for _, o := range stmt.orderBy {
+		collect(o.expr)
+	}
+	if len(stmt.groupBy) == 0 && len(calls) == 0 && stmt.having == nil {
+		return sc, rows, nil
+	}
+
+	groupExprs := make([]expr, len(stmt.groupBy))
+	for i, g := range stmt.groupBy {
+		e, err := groupByExpr(sc, stmt, g)
+		if err != nil {
+			return nil, nil, err
+		}
+		groupExprs[i] = e
+	}
+
+	grouped := &scope{columns: sc.columns, slots: map[string]slot{}, grouped: true, columnSlots: map[int]slot{}, now: sc.now}
+	keys := make([]*evaluator, len(groupExprs))
+	for i, g := range groupExprs {
+		ev, err := sc.compile(g)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys[i] = ev
+		sl := slot{idx: i, typ: ev.typ}
+		grouped.slots[exprKey(g)] = sl
+		if ref, ok := g.(*columnRef); ok {
+			idx, _ := sc.resolve(ref)
+			grouped.columnSlots[idx] = sl
+		}
+	}
+	aggs := make([]*aggregateCall, len(calls))
+	for i, f := range calls {
+		a, err := compileAggregate(sc, f)
+		if err != nil {
+			return nil, nil, err
+		}
+		aggs[i] = a
+		grouped.slots[exprKey(f)] = slot{idx: len(keys) + i, typ: a.typ}
+	}
+
+	type group struct {
+		key    []any
+		states []aggState
+	}
+	newGroup := func(key []any) *group {
+		g := &group{key: key, states: make([]aggState, len(aggs))}
+		for i, a := range aggs {
+			g.states[i] = a.newState()
+		}
+		return g
+	}
+	var groups []*group
+	index := map[string]*group{}
+	for _, row := range rows {
+		if err := ec.checkCancel(); err != nil {
+			return nil, nil, err
+		}
+		key := make([]any, len(keys))
+		for i, k := range keys {
+			v, err := k.eval(row)
+			if err != nil {
+				return nil, nil, err
+			}
+			key[i] = v
+		}
+		rk := rowKey(key)
+		g, ok := index[rk]
+		if !ok {
+			g = newGroup(key)
+			index[rk] = g
+			groups = append(groups, g)
+		}
+		for i, a := range aggs {
+			if err := a.add(g.states[i], row); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	// aggregates without GROUP BY return a single row, even when there are no input rows
+	if len(groups) == 0 && len(keys) == 0 {
+		groups = append(groups, newGroup(nil))
+	}
+
+	out := make([][]any, len(groups))
+	for i, g := range groups {
+		row := make([]any, 0, len(keys)+len(aggs))
+		row = append(row, g.key...)
+		for j, s := range g.states {
+			row = append(row, convert(s.result(), aggs[j].typ))
+		}
+		out[i] = row
+	}
+	return grouped, out, nil
+}
+
+// groupByExpr returns the expression of a GROUP BY item, which can also be the position
+// or the alias of an item of the select list.
+func groupByExpr(sc *scope, stmt *selectStmt, g expr) (expr, error) {
+	switch g := g.(type) {
+	case *literal:
+		n, ok := g.val.(int64)
+		if !ok {
+			return g, nil
+		}
+		if n < 1 || int(n) > len(stmt.columns) {
+			return nil, newError(g.pos, "GROUP BY position %d is not in select list", n)
+		}
+		item := stmt.columns[n-1]
+		if item.star {
+			return nil, newError(g.pos, "GROUP BY position %d refers to *", n)
+		}
+		return item.expr, nil
+	case *columnRef:
+		if _, err := sc.resolve(g); err == nil || g.table != "" {
+			return g, nil
+		}
+		for _, item := range stmt.columns {
+			if item.alias != "" && strings.EqualFold(item.alias, g.name) {
+				return item.expr, nil
+			}
+		}
+	}
+	return g, nil
+}
+
+// windowCall is a window function, computed for each row of its partition.
+type windowCall struct {
+	call        *funcCall
+	args        []*evaluator
+	partitionBy []*evaluator
+	orderBy     []*evaluator
+	typ         dataType
+}
+
+// windows computes the window functions of the select list and the ORDER BY clause, and appends their values to the rows.
+func (ec *execContext) windows(stmt *selectStmt, sc *scope, rows [][]any) ([][]any, error) {
+	var calls []*funcCall
+	seen := map[string]bool{}
+	collect := func(e expr) {
+		walkExpr(e, false, func(e expr) bool {
+			f, ok := e.(*funcCall)
+			if !ok || f.over == nil {
+				return true
+			}
+			if key := exprKey(f); !seen[key] {
+				seen[key] = true
+				calls = append(calls, f)
+			}
+			return false
+		})
+	}
+	for _, item := range stmt.columns {
+		collect(item.expr)
+	}
+	for _, o := range stmt.orderBy {
+		collect(o.expr)
+	}
+	if len(calls) == 0 {
+		return rows, nil
+	}
+
+	// copy the rows, since they can be shared with the input tables
+	width := 0
+	if len(rows) > 0 {
+		width = len(rows[0])
+	}
+	out := make([][]any, len(rows))
+	for i, row := range rows {
+		out[i] = make([]any, width, width+len(calls))
+		copy(out[i], row)
+	}
+
+	windows := make([]*windowCall, len(calls))
+	for i, f := range calls {
+		w, err := compileWindow(sc, f)
+		if err != nil {
+			return nil, err
+		}
+		windows[i] = w
+	}
+	for i, w := range windows {
+		values, err := ec.computeWindow(w, rows)
+		if err != nil {
+			return nil, err
+		}
+		for j := range out {
+			out[j] = append(out[j], convert(values[j], w.typ))
+		}
+		sc.slots[exprKey(w.call)] = slot{idx: width + i, typ: w.typ}
+	}
+	return out, nil
+}
+
+func compileWindow(sc *scope, f *funcCall) (*windowCall, error) {
+	w := &windowCall{call: f}
+	compileAll := func(exprs []expr) ([]*evaluator, error) {
+		evs := make([]*evaluator, len(exprs))
+		for i, e := range exprs {
+			ev, err := sc.compile(e)
+			if err != nil {
+				return nil, err
+			}
+			evs[i] = ev
+		}
+		return evs, nil
+	}
+	var err error
+	if w.args, err = compileAll(f.args); err != nil {
+		return nil, err
+	}
+	if w.partitionBy, err = compileAll(f.over.partitionBy); err != nil {
+		return nil, err
+	}
+	orderExprs := make([]expr, len(f.over.orderBy))
+	for i, o := range f.over.orderBy {
+		orderExprs[i] = o.expr
+	}
+	if w.orderBy, err = compileAll(orderExprs); err != nil {
+		return nil, err
+	}
+
+	argCount := func(lo, hi int) error {
+		if len(f.args) < lo || len(f.args) > hi {
+			return newError(f.pos, "wrong number of arguments for function %s: got %d", f.name, len(f.args))
+		}
+		return nil
+	}
+	switch f.name {
+	case "row_number", "rank", "dense_rank":
+		if err := argCount(0, 0); err != nil {
+			return nil, err
+		}
+		w.typ = typeInt
+	case "lag", "lead":
+		if err := argCount(1, 3); err != nil {
+			return nil, err
+		}
+		if len(w.args) > 1 && w.args[1].typ != typeInt && w.args[1].typ != typeNull {
+			return nil, newError(f.pos, "offset of %s must be an integer, got %s", f.name, w.args[1].typ)
+		}
+		w.typ = w.args[0].typ
+		if len(w.args) > 2 {
+			var ok bool
+			if w.typ, ok = commonType(w.typ, w.args[2].typ); !ok {
+				return nil, newError(f.pos, "default of %s must be of type %s, got %s", f.name, w.args[0].typ, w.args[2].typ)
+			}
+		}
+	case "first_value", "last_value":
+		if err := argCount(1, 1); err != nil {
+			return nil, err
+		}
+		w.typ = w.args[0].typ
+	default:
+		fn, ok := aggregateFuncs[f.name]
+		if !ok {
+			return nil, newError(f.pos, "function %s is not a window function", f.name)
+		}
+		if f.star {
+			if f.name != "count" {
+				return nil, newError(f.pos, "%s(*) is not supported", f.name)
+			}
+		} else if err := argCount(1, 1); err != nil {
+			return nil, err
+		}
+		argType := typeNull
+		if len(w.args) > 0 {
+			argType = w.args[0].typ
+		}
+		if w.typ, err = fn.returns(argType); err != nil {
+			return nil, newError(f.pos, "invalid arguments for function %s: %s", f.name, err)
+		}
+	}
+	return w, nil
+}
+
+// partitionRow is a row of a window partition.
+type partitionRow struct {
+	idx   int
+	args  []any
+	order []any
+}
+
+func (ec *execContext) computeWindow(w *windowCall, rows [][]any) ([]any, error) {
+	var partitions [][]partitionRow
+	index := map[string]int{}
+	for i, row := range rows {
+		if err := ec.checkCancel(); err != nil {
+			return nil, err
+		}
+		key, err := evalAll(w.partitionBy, row)
+		if err != nil {
+			return nil, err
+		}
+		pr := partitionRow{idx: i}
+		if pr.args, err = evalAll(w.args, row); err != nil {
+			return nil, err
+		}
+		if pr.order, err = evalAll(w.orderBy, row); err != nil {
+			return nil, err
+		}
+		rk := rowKey(key)
+		p, ok := index[rk]
+		if !ok {
+			p = len(partitions)
+			index[rk] = p
+			partitions = append(partitions, nil)
+		}
+		partitions[p] = append(partitions[p], pr)
+	}
+
+	orderBy := w.call.over.orderBy
+	compareOrder := func(a, b partitionRow) int {
+		for i, o := range orderBy {
+			c := compareNullable(a.order[i], b.order[i], o.nullsFirst)
+			if o.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+
+	values := make([]any, len(rows))
+	for _, p := range partitions {
+		sort.SliceStable(p, func(i, j int) bool { return compareOrder(p[i], p[j]) < 0 })
+		// lastPeer is the position of the last row with the same ORDER BY values as the row at each position
+		lastPeer := make([]int, len(p))
+		for i := len(p) - 1; i >= 0; i-- {
+			lastPeer[i] = i
+			if i < len(p)-1 && compareOrder(p[i], p[i+1]) == 0 {
+				lastPeer[i] = lastPeer[i+1]
+			}
+		}
+		frame := func(pos int) (int, int) {
+			f := w.call.over.frame
+			switch {
+			case f != nil:
+				start, end := 0, len(p)-1
+				if f.start != nil {
+					start = max(pos+int(*f.start), 0)
+				}
+				if f.end != nil {
+					end = min(pos+int(*f.end), len(p)-1)
+				}
+				return start, end
+			case len(orderBy) > 0:
+				return 0, lastPeer[pos]
+			}
+			return 0, len(p) - 1
+		}
+		if err := computePartition(w, p, values, frame); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func computePartition(w *windowCall, p []partitionRow, values []any, frame func(pos int) (int, int)) error {
+	f := w.call
+	switch f.name {
+	case "row_number":
+		for pos, r := range p {
+			values[r.idx] = int64(pos + 1)
+		}
+		return nil
+	case "rank", "dense_rank":
+		rank, dense := 1, 0
+		for pos, r := range p {
+			if pos == 0 || !samePeers(p[pos-1], r) {
+				rank = pos + 1
+				dense++
+			}
+			if f.name == "rank" {
+				values[r.idx] = int64(rank)
+			} else {
+				values[r.idx] = int64(dense)
+			}
+		}
+		return nil
+	case "lag", "lead":
+		for pos, r := range p {
+			offset := int64(1)
+			if len(r.args) > 1 {
+				if r.args[1] == nil {
+					values[r.idx] = nil
+					continue
+				}
+				offset = r.args[1].(int64)
+			}
+			if f.name == "lag" {
+				offset = -offset
+			}
+			target := pos + int(offset)
+			switch {
+			case target >= 0 && target < len(p):
+				values[r.idx] = p[target].args[0]
+			case len(r.args) > 2:
+				values[r.idx] = r.args[2]
+			default:
+				values[r.idx] = nil
+			}
+		}
+		return nil
+	case "first_value", "last_value":
+		for pos, r := range p {
+			start, end := frame(pos)
+			switch {
+			case start > end:
+				values[r.idx] = nil
+			case f.name == "first_value":
+				values[r.idx] = p[start].args[0]
+			default:
+				values[r.idx] = p[end].args[0]
+			}
+		}
+		return nil
+	}
+
+	agg := &aggregateCall{call: f, fn: aggregateFuncs[f.name], typ: w.typ}
+	if len(w.args) > 0 {
+		agg.arg = w.args[0]
+	}
+	add := func(s aggState, r partitionRow) {
+		if agg.arg == nil {
+			s.add(true)
+		} else if r.args[0] != nil {
+			s.add(convert(r.args[0], agg.arg.typ))
+		}
+	}
+	// frames that start at the beginning of the partition only grow, so the state can be reused for the next row
+	if f.over.frame == nil || f.over.frame.start == nil {
+		s := agg.newState()
+		added := 0
+		for pos, r := range p {
+			_, end := frame(pos)
+			for ; added <= end; added++ {
+				add(s, p[added])
+			}
+			values[r.idx] = s.result()
+		}
+		return nil
+	}
+	for pos, r := range p {
+		s := agg.newState()
+		start, end := frame(pos)
+		for i := start; i <= end; i++ {
+			add(s, p[i])
+		}
+		values[r.idx] = s.result()
+	}
+	return nil
+}
+
+// samePeers returns whether the rows have the same ORDER BY values.
+func samePeers(a, b partitionRow) bool {
+	for i := range a.order {
+		if compareNullable(a.order[i], b.order[i], false) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func evalAll(evs []*evaluator, row []any) ([]any, error) {
+	values := make([]any, len(evs))
+	for i, ev := range evs {
+		v, err := ev.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// outputRow is a row of the result with the values to sort it by.
+type outputRow struct {
+	values []any
+	order  []any
+}
+
+// project evaluates the select list for the rows, and applies ORDER BY, DISTINCT, OFFSET and LIMIT.
+func (ec *execContext) project(stmt *selectStmt, sc *scope, rows [][]any) (*relation, error) {
+	out := &relation{}
+	var items []*evaluator
+	var itemExprs []expr
+	for _, item := range stmt.columns {
+		if !item.star {
+			ev, err := sc.compile(item.expr)
+			if err != nil {
+				return nil, err
+			}
+			name := item.alias
+			if name == "" {
+				if ref, ok := item.expr.(*columnRef); ok {
+					name = ref.name
+				} else {
+					name = exprString(item.expr)
+				}
+			}
+			out.columns = append(out.columns, column{name: name, typ: ev.typ})
+			items = append(items, ev)
+			itemExprs = append(itemExprs, item.expr)
+			continue
+		}
+		if sc.grouped {
+			return nil, newError(item.pos, "SELECT * can not be used with GROUP BY or aggregate functions")
+		}
+		found := false
+		for i, c := range sc.columns {
+			if item.starTable != "" && !strings.EqualFold(item.starTable, c.table) {
+				continue
+			}
+			found = true
+			out.columns = append(out.columns, column{name: c.name, typ: c.typ})
+			items = append(items, slotEvaluator(slot{idx: i, typ: c.typ}))
+			itemExprs = append(itemExprs, &columnRef{table: c.table, name: c.name, pos: item.pos})
+		}
+		if item.starTable != "" && !found {
+			return nil, newError(item.pos, "table %s not found", item.starTable)
+		}
+	}
+
+	// ORDER BY items are either the position or the name of an output column, or expressions of the input
+	orderColumns := make([]int, len(stmt.orderBy))
+	orderExprs := make([]*evaluator, len(stmt.orderBy))
+	for i, o := range stmt.orderBy {
+		orderColumns[i] = outputColumn(o.expr, out.columns, itemExprs)
+		if lit, ok := o.expr.(*literal); ok {
+			if n, ok := lit.val.(int64); ok {
+				if n < 1 || int(n) > len(out.columns) {
+					return nil, newError(lit.pos, "ORDER BY position %d is not in select list", n)
+				}
+				orderColumns[i] = int(n) - 1
+			}
+		}
+		if orderColumns[i] >= 0 {
+			continue
+		}
+		if stmt.distinct {
+			return nil, newError(o.expr.position(), "for SELECT DISTINCT, ORDER BY expressions must appear in select list")
+		}
+		ev, err := sc.compile(o.expr)
+		if err != nil {
+			return nil, err
+		}
+		orderExprs[i] = ev
+	}
+
+	result := make([]outputRow, 0, len(rows))
+	for _, row := range rows {
+		if err := ec.checkCancel(); err != nil {
+			return nil, err
+		}
+		values, err := evalAll(items, row)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			values[i] = convert(v, out.columns[i].typ)
+		}
+		r := outputRow{values: values, order: make([]any, len(stmt.orderBy))}
+		for i := range stmt.orderBy {
+			if orderColumns[i] >= 0 {
+				r.order[i] = values[orderColumns[i]]
+				continue
+			}
+			if r.order[i], err = orderExprs[i].eval(row); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, r)
+	}
+
+	if len(stmt.orderBy) > 0 {
+		sort.SliceStable(result, func(i, j int) bool {
+			for k, o := range stmt.orderBy {
+				c := compareNullable(result[i].order[k], result[j].order[k], o.nullsFirst)
+				if o.desc {
+					c = -c
+				}
+				if c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+
+	if stmt.distinct {
+		seen := map[string]bool{}
+		distinct := result[:0]
+		for _, r := range result {
+			k := rowKey(r.values)
+			if !seen[k] {
+				seen[k] = true
+				distinct = append(distinct, r)
+			}
+		}
+		result = distinct
+	}
+
+	offset, err := ec.constantInt(stmt.offset, "OFFSET")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := ec.constantInt(stmt.limit, "LIMIT")
+	if err != nil {
+		return nil, err
+	}
+	if offset != nil {
+		result = result[min(int(*offset), len(result)):]
+	}
+	if limit != nil {
+		result = result[:min(int(*limit), len(result))]
+	}
+
+	out.rows = make([][]any, len(result))
+	for i, r := range result {
+		out.rows[i] = r.values
+	}
+	return out, nil
+}
+
+// outputColumn returns the index of the output column that an ORDER BY expression refers to, or -1.
+func outputColumn(e expr, columns []column, itemExprs []expr) int {
+	if ref, ok := e.(*columnRef); ok && ref.table == "" {
+		for i, c := range columns {
+			if strings.EqualFold(c.name, ref.name) {
+				return i
+			}
+		}
+	}
+	key := exprKey(e)
+	for i, item := range itemExprs {
+		if exprKey(item) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// constantInt evaluates the expression of a LIMIT or OFFSET clause, which must be a non-negative integer constant.
+func (ec *execContext) constantInt(e expr, clause string) (*int64, error) {
+	if e == nil {
+		return nil, nil
+	}
+	ev, err := (&scope{now: ec.now}).compile(e)
+	if err != nil {
+		return nil, err
+	}
+	v, err := ev.eval(nil)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	n, ok := v.(int64)
+	if !ok || n < 0 {
+		return nil, newError(e.position(), "%s must be a non-negative integer", clause)
+	}
+	return &n, nil
+}
@@ -0,0 +1,292 @@
+package sql
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func testFrames() []*data.Frame {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{t0, t0.Add(time.Minute), t0.Add(2 * time.Minute), t0.Add(3 * time.Minute)}),
+		data.NewField("host", nil, []string{"a", "b", "a", "c"}),
+		data.NewField("value", nil, []*float64{fp(1), fp(2), fp(3), nil}),
+		data.NewField("count", nil, []int32{10, 20, 30, 40}),
+	)
+	a.RefID = "A"
+	b := data.NewFrame("",
+		data.NewField("host", nil, []string{"a", "b", "d"}),
+		data.NewField("region", nil, []string{"eu", "us", "ap"}),
+	)
+	b.RefID = "B"
+	return []*data.Frame{a, b}
+}
+
+func fp(f float64) *float64     { return &f }
+func ip(i int64) *int64         { return &i }
+func sp(s string) *string       { return &s }
+func bp(b bool) *bool           { return &b }
+func tp(t time.Time) *time.Time { return &t }
+
+func execute(t *testing.T, rawSQL string, frames []*data.Frame) (*data.Frame, error) {
+	t.Helper()
+	q, err := Parse(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	return q.Execute(context.Background(), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), "C", frames)
+}
+
+type expectedField struct {
+	name   string
+	values any
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name   string
+		sql    string
+		fields []expectedField
+	}{
+		{
+			name: "select with where and order by",
+			sql:  "SELECT host, value FROM A WHERE value > 1 ORDER BY value DESC",
+			fields: []expectedField{
+				{"host", []*string{sp("a"), sp("b")}},
+				{"value", []*float64{fp(3), fp(2)}},
+			},
+		},
+		{
+			name: "integer fields are widened to int64",
+			sql:  "SELECT count * 2 AS doubled, count / 20 AS ratio FROM A LIMIT 2",
+			fields: []expectedField{
+				{"doubled", []*int64{ip(20), ip(40)}},
+				{"ratio", []*float64{fp(0.5), fp(1)}},
+			},
+		},
+		{
+			name: "nulls sort last",
+			sql:  "SELECT value FROM A ORDER BY value",
+			fields: []expectedField{
+				{"value", []*float64{fp(1), fp(2), fp(3), nil}},
+			},
+		},
+		{
+			name: "group by with aggregates",
+			sql:  "SELECT host, count(*) AS n, sum(value) AS total, avg(count) FROM A GROUP BY host ORDER BY host",
+			fields: []expectedField{
+				{"host", []*string{sp("a"), sp("b"), sp("c")}},
+				{"n", []*int64{ip(2), ip(1), ip(1)}},
+				{"total", []*float64{fp(4), fp(2), nil}},
+				{"avg(count)", []*float64{fp(20), fp(20), fp(40)}},
+			},
+		},
+		{
+			name: "having",
+			sql:  "SELECT host FROM A GROUP BY 1 HAVING count(*) > 1",
+			fields: []expectedField{
+				{"host", []*string{sp("a")}},
+			},
+		},
+		{
+			name: "aggregate without rows",
+			sql:  "SELECT count(*) AS n, max(value) AS m FROM A WHERE host = 'z'",
+			fields: []expectedField{
+				{"n", []*int64{ip(0)}},
+				{"m", []*float64{nil}},
+			},
+		},
+		{
+			name: "inner join",
+			sql:  "SELECT A.host, B.region FROM A JOIN B ON A.host = B.host ORDER BY A.time",
+			fields: []expectedField{
+				{"host", []*string{sp("a"), sp("b"), sp("a")}},
+				{"region", []*string{sp("eu"), sp("us"), sp("eu")}},
+			},
+		},
+		{
+			name: "full join",
+			sql:  "SELECT a.host AS l, b.host AS r FROM (SELECT DISTINCT host FROM A) a FULL OUTER JOIN B b ON a.host = b.host ORDER BY l, r",
+			fields: []expectedField{
+				{"l", []*string{sp("a"), sp("b"), sp("c"), nil}},
+				{"r", []*string{sp("a"), sp("b"), nil, sp("d")}},
+			},
+		},
+		{
+			name: "window functions",
+			sql: `SELECT host, count,
+				row_number() OVER (PARTITION BY host ORDER BY time) AS rn,
+				sum(count) OVER (ORDER BY time ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) AS moving,
+				lag(count) OVER (ORDER BY time) AS prev
+			FROM A ORDER BY time`,
+			fields: []expectedField{
+				{"host", []*string{sp("a"), sp("b"), sp("a"), sp("c")}},
+				{"count", []*int64{ip(10), ip(20), ip(30), ip(40)}},
+				{"rn", []*int64{ip(1), ip(1), ip(2), ip(1)}},
+				{"moving", []*int64{ip(10), ip(30), ip(50), ip(70)}},
+				{"prev", []*int64{nil, ip(10), ip(20), ip(30)}},
+			},
+		},
+		{
+			name: "rank",
+			sql:  "SELECT host, rank() OVER (ORDER BY host) AS r, dense_rank() OVER (ORDER BY host) AS d FROM A ORDER BY host",
+			fields: []expectedField{
+				{"host", []*string{sp("a"), sp("a"), sp("b"), sp("c")}},
+				{"r", []*int64{ip(1), ip(1), ip(3), ip(4)}},
+				{"d", []*int64{ip(1), ip(1), ip(2), ip(3)}},
+			},
+		},
+		{
+			name: "common table expressions",
+			sql:  "WITH hosts AS (SELECT host, max(count) AS m FROM A GROUP BY host) SELECT h.host FROM hosts h WHERE h.m >= 30 ORDER BY h.m DESC",
+			fields: []expectedField{
+				{"host", []*string{sp("c"), sp("a")}},
+			},
+		},
+		{
+			name: "time comparison with a string literal",
+			sql:  "SELECT count FROM A WHERE time >= '2024-01-01 00:02:00'",
+			fields: []expectedField{
+				{"count", []*int64{ip(30), ip(40)}},
+			},
+		},
+		{
+			name: "case, cast, coalesce and string functions",
+			sql: `SELECT CASE WHEN value IS NULL THEN 'none' ELSE upper(host) END AS label,
+				CAST(count AS VARCHAR) || '!' AS text,
+				coalesce(value, 0) AS v,
+				host IN ('a', 'b') AS known
+			FROM A LIMIT 2 OFFSET 2`,
+			fields: []expectedField{
+				{"label", []*string{sp("A"), sp("none")}},
+				{"text", []*string{sp("30!"), sp("40!")}},
+				{"v", []*float64{fp(3), fp(0)}},
+				{"known", []*bool{bp(true), bp(false)}},
+			},
+		},
+		{
+			name: "select without from",
+			sql:  "SELECT 1 AS one, NULL AS nothing, now() AS ts",
+			fields: []expectedField{
+				{"one", []*int64{ip(1)}},
+				{"nothing", []*float64{nil}},
+				{"ts", []*time.Time{tp(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frame, err := execute(t, tt.sql, testFrames())
+			require.NoError(t, err)
+			require.Equal(t, "C", frame.Name)
+			require.Len(t, frame.Fields, len(tt.fields))
+			for i, f := range tt.fields {
+				require.Equal(t, f.name, frame.Fields[i].Name)
+				require.Equal(t, data.NewField(f.name, nil, f.values), frame.Fields[i])
+			}
+		})
+	}
+}
+
+func TestExecuteLabels(t *testing.T) {
+	series := func(host string, v float64) *data.Frame {
+		f := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.Unix(0, 0).UTC()}),
+			data.NewField("value", data.Labels{"host": host}, []float64{v}),
+		)
+		f.RefID = "A"
+		return f
+	}
+
+	frame, err := execute(t, "SELECT host, value FROM A ORDER BY host", []*data.Frame{series("b", 2), series("a", 1)})
+	require.NoError(t, err)
+	require.Equal(t, data.NewField("host", nil, []*string{sp("a"), sp("b")}), frame.Fields[0])
+	require.Equal(t, data.NewField("value", nil, []*float64{fp(1), fp(2)}), frame.Fields[1])
+}
+
+func TestExecuteNaN(t *testing.T) {
+	a := data.NewFrame("", data.NewField("value", nil, []float64{math.NaN(), 1}))
+	a.RefID = "A"
+
+	frame, err := execute(t, "SELECT value FROM A ORDER BY value", []*data.Frame{a})
+	require.NoError(t, err)
+	require.Equal(t, 1.0, *frame.Fields[0].At(0).(*float64))
+	require.True(t, math.IsNaN(*frame.Fields[0].At(1).(*float64)))
+}
+
+func TestExecuteErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		err  string
+	}{
+		{
+			name: "unknown column",
+			sql:  "SELECT host,\n  valu FROM A",
+			err:  `line 2, column 3: column "valu" not found`,
+		},
+		{
+			name: "unknown table",
+			sql:  "SELECT * FROM Z",
+			err:  `line 1, column 15: table Z not found`,
+		},
+		{
+			name: "ambiguous column",
+			sql:  "SELECT host FROM A JOIN B ON A.host = B.host",
+			err:  `line 1, column 8: column reference "host" is ambiguous`,
+		},
+		{
+			name: "column not in group by",
+			sql:  "SELECT host, value FROM A GROUP BY host",
+			err:  `line 1, column 14: column "value" must appear in the GROUP BY clause or be used in an aggregate function`,
+		},
+		{
+			name: "aggregate in where",
+			sql:  "SELECT host FROM A WHERE sum(value) > 1",
+			err:  `line 1, column 26: aggregate function sum is not allowed here`,
+		},
+		{
+			name: "type mismatch",
+			sql:  "SELECT host FROM A WHERE host > 1",
+			err:  `line 1, column 31: can not compare VARCHAR with BIGINT`,
+		},
+		{
+			name: "unknown function",
+			sql:  "SELECT foo(value) FROM A",
+			err:  `line 1, column 8: function foo does not exist`,
+		},
+		{
+			name: "invalid cast",
+			sql:  "SELECT CAST(host AS INTEGER) FROM A",
+			err:  `line 1, column 8: could not convert string 'a' to BIGINT`,
+		},
+		{
+			name: "syntax error",
+			sql:  "SELECT host FROM A WHERE",
+			err:  `line 1, column 25: syntax error near end of query`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := execute(t, tt.sql, testFrames())
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestExecuteCanceled(t *testing.T) {
+	q, err := Parse("SELECT * FROM A, A AS A2, A AS A3, A AS A4, A AS A5, A AS A6")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = q.Execute(ctx, time.Now(), "C", testFrames())
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,244 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Execute runs the query over the frames, where each frame is a table named by its RefID. Frames
+// with the same RefID are combined into one table. The result is returned as a frame with the name.
+func (q *Query) Execute(ctx context.Context, now time.Time, name string, frames []*data.Frame) (*data.Frame, error) {
+	tables, err := framesToTables(frames)
+	if err != nil {
+		return nil, err
+	}
+	ec := &execContext{ctx: ctx, now: now, tables: tables}
+	rel, err := ec.executeSelect(q.stmt, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return relationToFrame(name, rel), nil
+}
+
+// framesToTables converts the frames to tables. The columns of a table are the fields of its frames,
+// followed by the labels of the fields as string columns, sorted by name. Fields take precedence
+// over labels with the same name.
+func framesToTables(frames []*data.Frame) (map[string]*relation, error) {
+	byRef := map[string][]*data.Frame{}
+	var refs []string
+	for _, f := range frames {
+		if f == nil {
+			continue
+		}
+		if _, ok := byRef[f.RefID]; !ok {
+			refs = append(refs, f.RefID)
+		}
+		byRef[f.RefID] = append(byRef[f.RefID], f)
+	}
+
+	tables := make(map[string]*relation, len(refs))
+	for _, ref := range refs {
+		rel, err := framesToRelation(ref, byRef[ref])
+		if err != nil {
+			return nil, err
+		}
+		tables[ref] = rel
+	}
+	return tables, nil
+}
+
+func framesToRelation(ref string, frames []*data.Frame) (*relation, error) {
+	rel := &relation{}
+	index := map[string]int{}
+	for _, f := range frames {
+		for _, field := range f.Fields {
+			typ := fieldType(field.Type())
+			i, ok := index[field.Name]
+			if !ok {
+				index[field.Name] = len(rel.columns)
+				rel.columns = append(rel.columns, column{name: field.Name, typ: typ})
+				continue
+			}
+			common, ok := commonType(rel.columns[i].typ, typ)
+			if !ok {
+				return nil, fmt.Errorf("column %q of table %s has conflicting types %s and %s", field.Name, ref, rel.columns[i].typ, typ)
+			}
+			rel.columns[i].typ = common
+		}
+	}
+	labelSet := map[string]bool{}
+	for _, f := range frames {
+		for _, field := range f.Fields {
+			for k := range field.Labels {
+				if _, ok := index[k]; !ok {
+					labelSet[k] = true
+				}
+			}
+		}
+	}
+	labels := make([]string, 0, len(labelSet))
+	for k := range labelSet {
+		labels = append(labels, k)
+	}
+	sort.Strings(labels)
+	for _, k := range labels {
+		index[k] = len(rel.columns)
+		rel.columns = append(rel.columns, column{name: k, typ: typeString})
+	}
+
+	for _, f := range frames {
+		n := f.Rows()
+		if n == 0 {
+			continue
+		}
+		fieldIdx := make([]int, len(f.Fields))
+		labelValues := map[int]string{}
+		for i, field := range f.Fields {
+			fieldIdx[i] = index[field.Name]
+			for k, v := range field.Labels {
+				if !labelSet[k] {
+					continue
+				}
+				if _, ok := labelValues[index[k]]; !ok {
+					labelValues[index[k]] = v
+				}
+			}
+		}
+		for r := 0; r < n; r++ {
+			row := make([]any, len(rel.columns))
+			for i, field := range f.Fields {
+				if r >= field.Len() {
+					continue
+				}
+				v, ok := field.ConcreteAt(r)
+				if !ok {
+					continue
+				}
+				col := fieldIdx[i]
+				row[col] = convert(fieldValue(v), rel.columns[col].typ)
+			}
+			for idx, v := range labelValues {
+				row[idx] = v
+			}
+			rel.rows = append(rel.rows, row)
+		}
+	}
+	return rel, nil
+}
+
+// fieldType returns the column type of a field type. Integers that fit in an int64 are integers, other numbers
+// are floats, and types without a matching column type, such as JSON, are strings.
+func fieldType(t data.FieldType) dataType {
+	switch t.NonNullableType() {
+	case data.FieldTypeInt8, data.FieldTypeInt16, data.FieldTypeInt32, data.FieldTypeInt64,
+		data.FieldTypeUint8, data.FieldTypeUint16, data.FieldTypeUint32:
+		return typeInt
+	case data.FieldTypeUint64, data.FieldTypeFloat32, data.FieldTypeFloat64:
+		return typeFloat
+	case data.FieldTypeBool:
+		return typeBool
+	case data.FieldTypeTime:
+		return typeTime
+	}
+	return typeString
+}
+
+// fieldValue converts a value of a field to the value of its column type, see fieldType.
+func fieldValue(v any) any {
+	switch v := v.(type) {
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	case bool:
+		return v
+	case string:
+		return v
+	case time.Time:
+		return v
+	case json.RawMessage:
+		return string(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// relationToFrame converts the result of a query to a frame with nullable fields. Columns
+// that are always NULL, such as SELECT NULL, are returned as float fields.
+func relationToFrame(name string, rel *relation) *data.Frame {
+	fields := make([]*data.Field, len(rel.columns))
+	for i, c := range rel.columns {
+		var values any
+		switch c.typ {
+		case typeBool:
+			vals := make([]*bool, len(rel.rows))
+			for r, row := range rel.rows {
+				if v, ok := row[i].(bool); ok {
+					vals[r] = &v
+				}
+			}
+			values = vals
+		case typeInt:
+			vals := make([]*int64, len(rel.rows))
+			for r, row := range rel.rows {
+				if v, ok := row[i].(int64); ok {
+					vals[r] = &v
+				}
+			}
+			values = vals
+		case typeString:
+			vals := make([]*string, len(rel.rows))
+			for r, row := range rel.rows {
+				if v, ok := row[i].(string); ok {
+					vals[r] = &v
+				}
+			}
+			values = vals
+		case typeTime:
+			vals := make([]*time.Time, len(rel.rows))
+			for r, row := range rel.rows {
+				if v, ok := row[i].(time.Time); ok {
+					vals[r] = &v
+				}
+			}
+			values = vals
+		default:
+			vals := make([]*float64, len(rel.rows))
+			for r, row := range rel.rows {
+				switch v := row[i].(type) {
+				case float64:
+					vals[r] = &v
+				case int64:
+					f := float64(v)
+					vals[r] = &f
+				}
+			}
+			values = vals
+		}
+		fields[i] = data.NewField(c.name, nil, values)
+	}
+	return data.NewFrame(name, fields...)
+}
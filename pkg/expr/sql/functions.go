@@ -0,0 +1,505 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// scalarFunc is a function that returns a value for each row.
+type scalarFunc struct {
+	minArgs int
+	// maxArgs is -1 for functions with a variable number of arguments
+	maxArgs int
+	returns func(args []dataType) (dataType, error)
+	call    func(args []any) (any, error)
+	// nullSafe functions are called with NULL arguments, other functions return NULL if any argument is NULL
+	nullSafe bool
+}
+
+var scalarFuncs = map[string]scalarFunc{
+	"abs":     {minArgs: 1, maxArgs: 1, returns: sameNumeric, call: numericFunc(func(i int64) int64 { return max(i, -i) }, math.Abs)},
+	"ceil":    {minArgs: 1, maxArgs: 1, returns: sameNumeric, call: numericFunc(identity, math.Ceil)},
+	"ceiling": {minArgs: 1, maxArgs: 1, returns: sameNumeric, call: numericFunc(identity, math.Ceil)},
+	"floor":   {minArgs: 1, maxArgs: 1, returns: sameNumeric, call: numericFunc(identity, math.Floor)},
+	"round": {minArgs: 1, maxArgs: 2, returns: sameNumeric, call: func(args []any) (any, error) {
+		digits := int64(0)
+		if len(args) == 2 {
+			d, ok := args[1].(int64)
+			if !ok {
+				return nil, fmt.Errorf("the number of digits of round must be an integer")
+			}
+			digits = d
+		}
+		if f, ok := args[0].(float64); ok {
+			p := math.Pow(10, float64(digits))
+			return math.Round(f*p) / p, nil
+		}
+		return args[0], nil
+	}},
+	"sqrt":  {minArgs: 1, maxArgs: 1, returns: floatOfNumeric, call: floatFunc(math.Sqrt)},
+	"exp":   {minArgs: 1, maxArgs: 1, returns: floatOfNumeric, call: floatFunc(math.Exp)},
+	"ln":    {minArgs: 1, maxArgs: 1, returns: floatOfNumeric, call: floatFunc(math.Log)},
+	"log":   {minArgs: 1, maxArgs: 1, returns: floatOfNumeric, call: floatFunc(math.Log10)},
+	"log10": {minArgs: 1, maxArgs: 1, returns: floatOfNumeric, call: floatFunc(math.Log10)},
+	"log2":  {minArgs: 1, maxArgs: 1, returns: floatOfNumeric, call: floatFunc(math.Log2)},
+	"power": {minArgs: 2, maxArgs: 2, returns: floatOfNumeric, call: func(args []any) (any, error) {
+		return math.Pow(toFloat(args[0]), toFloat(args[1])), nil
+	}},
+	"pow": {minArgs: 2, maxArgs: 2, returns: floatOfNumeric, call: func(args []any) (any, error) {
+		return math.Pow(toFloat(args[0]), toFloat(args[1])), nil
+	}},
+	"sign": {minArgs: 1, maxArgs: 1, returns: intOfNumeric, call: func(args []any) (any, error) {
+		f := toFloat(args[0])
+		switch {
+		case f > 0:
+			return int64(1), nil
+		case f < 0:
+			return int64(-1), nil
+		}
+		return int64(0), nil
+	}},
+	"greatest": {minArgs: 1, maxArgs: -1, returns: commonOfArgs, nullSafe: true, call: func(args []any) (any, error) {
+		return extreme(args, 1), nil
+	}},
+	"least": {minArgs: 1, maxArgs: -1, returns: commonOfArgs, nullSafe: true, call: func(args []any) (any, error) {
+		return extreme(args, -1), nil
+	}},
+	"coalesce": {minArgs: 1, maxArgs: -1, returns: commonOfArgs, nullSafe: true, call: coalesce},
+	"ifnull":   {minArgs: 2, maxArgs: 2, returns: commonOfArgs, nullSafe: true, call: coalesce},
+	"nullif": {minArgs: 2, maxArgs: 2, returns: comparableArgs, nullSafe: true, call: func(args []any) (any, error) {
+		if args[0] != nil && args[1] != nil && compareValues(args[0], args[1]) == 0 {
+			return nil, nil
+		}
+		return args[0], nil
+	}},
+	"lower": {minArgs: 1, maxArgs: 1, returns: stringOfStrings, call: stringFunc(strings.ToLower)},
+	"upper": {minArgs: 1, maxArgs: 1, returns: stringOfStrings, call: stringFunc(strings.ToUpper)},
+	"trim":  {minArgs: 1, maxArgs: 1, returns: stringOfStrings, call: stringFunc(strings.TrimSpace)},
+	"ltrim": {minArgs: 1, maxArgs: 1, returns: stringOfStrings, call: stringFunc(func(s string) string {
+		return strings.TrimLeft(s, " \t\r\n")
+	})},
+	"rtrim": {minArgs: 1, maxArgs: 1, returns: stringOfStrings, call: stringFunc(func(s string) string {
+		return strings.TrimRight(s, " \t\r\n")
+	})},
+	"length": {minArgs: 1, maxArgs: 1, returns: argTypes(typeInt, typeString), call: func(args []any) (any, error) {
+		return int64(utf8.RuneCountInString(args[0].(string))), nil
+	}},
+	"concat": {minArgs: 1, maxArgs: -1, returns: func([]dataType) (dataType, error) { return typeString, nil }, nullSafe: true, call: func(args []any) (any, error) {
+		var sb strings.Builder
+		for _, a := range args {
+			if a != nil {
+				sb.WriteString(formatValue(a))
+			}
+		}
+		return sb.String(), nil
+	}},
+	"substr":    {minArgs: 2, maxArgs: 3, returns: argTypes(typeString, typeString, typeInt, typeInt), call: substring},
+	"substring": {minArgs: 2, maxArgs: 3, returns: argTypes(typeString, typeString, typeInt, typeInt), call: substring},
+	"replace": {minArgs: 3, maxArgs: 3, returns: argTypes(typeString, typeString, typeString, typeString), call: func(args []any) (any, error) {
+		return strings.ReplaceAll(args[0].(string), args[1].(string), args[2].(string)), nil
+	}},
+	"contains": {minArgs: 2, maxArgs: 2, returns: argTypes(typeBool, typeString, typeString), call: func(args []any) (any, error) {
+		return strings.Contains(args[0].(string), args[1].(string)), nil
+	}},
+	"date_trunc": {minArgs: 2, maxArgs: 2, returns: argTypes(typeTime, typeString, typeTime), call: func(args []any) (any, error) {
+		return truncateTime(args[0].(string), args[1].(time.Time))
+	}},
+	"epoch": {minArgs: 1, maxArgs: 1, returns: argTypes(typeFloat, typeTime), call: func(args []any) (any, error) {
+		return float64(args[0].(time.Time).UnixNano()) / float64(time.Second), nil
+	}},
+	"epoch_ms": {minArgs: 1, maxArgs: 1, returns: argTypes(typeInt, typeTime), call: func(args []any) (any, error) {
+		return args[0].(time.Time).UnixMilli(), nil
+	}},
+	"to_timestamp": {minArgs: 1, maxArgs: 1, returns: func(args []dataType) (dataType, error) {
+		if err := expectNumeric(args[0]); err != nil {
+			return typeNull, err
+		}
+		return typeTime, nil
+	}, call: func(args []any) (any, error) {
+		return time.Unix(0, int64(toFloat(args[0])*float64(time.Second))).UTC(), nil
+	}},
+}
+
+// aggregateFunc is a function that returns a value for a group of rows, or for the frame of a window.
+type aggregateFunc struct {
+	returns  func(arg dataType) (dataType, error)
+	newState func(typ dataType) aggState
+}
+
+// aggState accumulates the non-null values of a group.
+type aggState interface {
+	add(v any)
+	// result returns the aggregated value of the values that have been added so far.
+	result() any
+}
+
+var aggregateFuncs = map[string]aggregateFunc{
+	"count": {
+		returns:  func(dataType) (dataType, error) { return typeInt, nil },
+		newState: func(dataType) aggState { return &countState{} },
+	},
+	"sum": {
+		returns:  sameNumericArg,
+		newState: func(typ dataType) aggState { return &sumState{isInt: typ == typeInt} },
+	},
+	"avg": {
+		returns:  floatOfNumericArg,
+		newState: func(dataType) aggState { return &avgState{} },
+	},
+	"mean": {
+		returns:  floatOfNumericArg,
+		newState: func(dataType) aggState { return &avgState{} },
+	},
+	"min": {
+		returns:  func(typ dataType) (dataType, error) { return typ, nil },
+		newState: func(dataType) aggState { return &extremeState{sign: -1} },
+	},
+	"max": {
+		returns:  func(typ dataType) (dataType, error) { return typ, nil },
+		newState: func(dataType) aggState { return &extremeState{sign: 1} },
+	},
+	"median": {
+		returns:  floatOfNumericArg,
+		newState: func(dataType) aggState { return &valuesState{f: median} },
+	},
+	"stddev": {
+		returns:  floatOfNumericArg,
+		newState: func(dataType) aggState { return &valuesState{f: stddevFunc(1)} },
+	},
+	"stddev_samp": {
+		returns:  floatOfNumericArg,
+		newState: func(dataType) aggState { return &valuesState{f: stddevFunc(1)} },
+	},
+	"stddev_pop": {
+		returns:  floatOfNumericArg,
+		newState: func(dataType) aggState { return &valuesState{f: stddevFunc(0)} },
+	},
+	"variance": {
+		returns:  floatOfNumericArg,
+		newState: func(dataType) aggState { return &valuesState{f: varianceFunc(1)} },
+	},
+	"var_samp": {
+		returns:  floatOfNumericArg,
+		newState: func(dataType) aggState { return &valuesState{f: varianceFunc(1)} },
+	},
+	"var_pop": {
+		returns:  floatOfNumericArg,
+		newState: func(dataType) aggState { return &valuesState{f: varianceFunc(0)} },
+	},
+}
+
+// windowFuncs are the functions that can only be used with an OVER clause. Aggregate functions can be used as well.
+var windowFuncs = map[string]bool{
+	"row_number":  true,
+	"rank":        true,
+	"dense_rank":  true,
+	"lag":         true,
+	"lead":        true,
+	"first_value": true,
+	"last_value":  true,
+}
+
+func isAggregate(f *funcCall) bool {
+	_, ok := aggregateFuncs[f.name]
+	return ok && f.over == nil
+}
+
+func identity(i int64) int64 { return i }
+
+func numericFunc(intF func(int64) int64, floatF func(float64) float64) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		if i, ok := args[0].(int64); ok {
+			return intF(i), nil
+		}
+		return floatF(args[0].(float64)), nil
+	}
+}
+
+func floatFunc(f func(float64) float64) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		return f(toFloat(args[0])), nil
+	}
+}
+
+func stringFunc(f func(string) string) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		return f(args[0].(string)), nil
+	}
+}
+
+func toFloat(v any) float64 {
+	if i, ok := v.(int64); ok {
+		return float64(i)
+	}
+	return v.(float64)
+}
+
+func expectNumeric(t dataType) error {
+	if t != typeNull && !t.isNumeric() {
+		return fmt.Errorf("expected a numeric argument, got %s", t)
+	}
+	return nil
+}
+
+func sameNumeric(args []dataType) (dataType, error) {
+	for _, t := range args {
+		if err := expectNumeric(t); err != nil {
+			return typeNull, err
+		}
+	}
+	if args[0] == typeNull {
+		return typeFloat, nil
+	}
+	return args[0], nil
+}
+
+func floatOfNumeric(args []dataType) (dataType, error) {
+	for _, t := range args {
+		if err := expectNumeric(t); err != nil {
+			return typeNull, err
+		}
+	}
+	return typeFloat, nil
+}
+
+func intOfNumeric(args []dataType) (dataType, error) {
+	if _, err := floatOfNumeric(args); err != nil {
+		return typeNull, err
+	}
+	return typeInt, nil
+}
+
+func commonOfArgs(args []dataType) (dataType, error) {
+	typ := typeNull
+	for _, t := range args {
+		c, ok := commonType(typ, t)
+		if !ok {
+			return typeNull, fmt.Errorf("arguments of types %s and %s can not be combined", typ, t)
+		}
+		typ = c
+	}
+	return typ, nil
+}
+
+func comparableArgs(args []dataType) (dataType, error) {
+	if _, err := commonOfArgs(args); err != nil {
+		return typeNull, err
+	}
+	return args[0], nil
+}
+
+func stringOfStrings(args []dataType) (dataType, error) {
+	return argTypes(typeString, typeString)(args)
+}
+
+// argTypes returns a returns function for a function with fixed argument types.
+func argTypes(ret dataType, params ...dataType) func(args []dataType) (dataType, error) {
+	return func(args []dataType) (dataType, error) {
+		for i, t := range args {
+			if t != typeNull && t != params[i] && !(params[i] == typeFloat && t == typeInt) {
+				return typeNull, fmt.Errorf("expected argument %d to be %s, got %s", i+1, params[i], t)
+			}
+		}
+		return ret, nil
+	}
+}
+
+func sameNumericArg(typ dataType) (dataType, error) {
+	return sameNumeric([]dataType{typ})
+}
+
+func floatOfNumericArg(typ dataType) (dataType, error) {
+	return floatOfNumeric([]dataType{typ})
+}
+
+func coalesce(args []any) (any, error) {
+	for _, a := range args {
+		if a != nil {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+// extreme returns the largest non-null value when sign is 1, or the smallest when sign is -1.
+func extreme(args []any, sign int) any {
+	var m any
+	for _, a := range args {
+		if a != nil && (m == nil || compareValues(a, m)*sign > 0) {
+			m = a
+		}
+	}
+	return m
+}
+
+func substring(args []any) (any, error) {
+	runes := []rune(args[0].(string))
+	start := args[1].(int64) - 1
+	end := int64(len(runes))
+	if len(args) == 3 {
+		end = start + args[2].(int64)
+	}
+	start = max(0, min(start, int64(len(runes))))
+	end = max(start, min(end, int64(len(runes))))
+	return string(runes[start:end]), nil
+}
+
+func truncateTime(unit string, t time.Time) (any, error) {
+	t = t.UTC()
+	switch strings.ToLower(unit) {
+	case "second":
+		return t.Truncate(time.Second), nil
+	case "minute":
+		return t.Truncate(time.Minute), nil
+	case "hour":
+		return t.Truncate(time.Hour), nil
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	case "week":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)), nil
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	case "year":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return nil, fmt.Errorf("unknown date part %s, expected one of second, minute, hour, day, week, month or year", unit)
+}
+
+type countState struct{ n int64 }
+
+func (s *countState) add(any)     { s.n++ }
+func (s *countState) result() any { return s.n }
+
+type sumState struct {
+	isInt bool
+	n     int
+	i     int64
+	f     float64
+}
+
+func (s *sumState) add(v any) {
+	s.n++
+	if i, ok := v.(int64); ok {
+		s.i += i
+		return
+	}
+	s.f += v.(float64)
+}
+
+func (s *sumState) result() any {
+	switch {
+	case s.n == 0:
+		return nil
+	case s.isInt:
+		return s.i
+	}
+	return s.f + float64(s.i)
+}
+
+type avgState struct {
+	n   int
+	sum float64
+}
+
+func (s *avgState) add(v any) {
+	s.n++
+	s.sum += toFloat(v)
+}
+
+func (s *avgState) result() any {
+	if s.n == 0 {
+		return nil
+	}
+	return s.sum / float64(s.n)
+}
+
+type extremeState struct {
+	sign int
+	m    any
+}
+
+func (s *extremeState) add(v any) {
+	if s.m == nil || compareValues(v, s.m)*s.sign > 0 {
+		s.m = v
+	}
+}
+
+func (s *extremeState) result() any { return s.m }
+
+// valuesState keeps all values to compute aggregations that need all of them, such as the median.
+type valuesState struct {
+	values []float64
+	f      func(values []float64) any
+}
+
+func (s *valuesState) add(v any) {
+	s.values = append(s.values, toFloat(v))
+}
+
+func (s *valuesState) result() any {
+	if len(s.values) == 0 {
+		return nil
+	}
+	return s.f(s.values)
+}
+
+func median(values []float64) any {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// varianceFunc returns the variance, where ddof is 1 for the sample variance and 0 for the population variance.
+func varianceFunc(ddof int) func(values []float64) any {
+	return func(values []float64) any {
+		if len(values) <= ddof {
+			return nil
+		}
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		mean := sum / float64(len(values))
+		var sq float64
+		for _, v := range values {
+			sq += (v - mean) * (v - mean)
+		}
+		return sq / float64(len(values)-ddof)
+	}
+}
+
+func stddevFunc(ddof int) func(values []float64) any {
+	variance := varianceFunc(ddof)
+	return func(values []float64) any {
+		v := variance(values)
+		if v == nil {
+			return nil
+		}
+		return math.Sqrt(v.(float64))
+	}
+}
+
+// distinctState only adds the first occurrence of each value to the wrapped state.
+type distinctState struct {
+	aggState
+	seen map[string]bool
+}
+
+func (s *distinctState) add(v any) {
+	k := valueKey(v)
+	if s.seen[k] {
+		return
+	}
+	s.seen[k] = true
+	s.aggState.add(v)
+}
@@ -0,0 +1,245 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdent
+	tokenQuotedIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+	tokenDot
+	tokenSemicolon
+)
+
+// Pos is the position of a token in a SQL query.
+type Pos struct {
+	Line   int
+	Column int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+type token struct {
+	typ tokenType
+	val string
+	pos Pos
+}
+
+func (t token) String() string {
+	switch t.typ {
+	case tokenEOF:
+		return "end of query"
+	case tokenString:
+		return "'" + t.val + "'"
+	case tokenQuotedIdent:
+		return `"` + t.val + `"`
+	}
+	return t.val
+}
+
+// Error is an error in a SQL query, pointing at the token that caused it.
+type Error struct {
+	Pos   Pos
+	Token string
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	if e.Token == "" {
+		return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s near %s", e.Pos, e.Msg, e.Token)
+}
+
+func errorAt(t token, format string, args ...any) *Error {
+	return &Error{Pos: t.pos, Token: t.String(), Msg: fmt.Sprintf(format, args...)}
+}
+
+// operators are the operators that the lexer recognizes, longest first.
+var operators = []string{"<>", "!=", "<=", ">=", "||", "::", "=", "<", ">", "+", "-", "*", "/", "%"}
+
+// lex splits a SQL query into tokens. Comments and whitespace are skipped.
+func lex(input string) ([]token, error) {
+	l := &lexer{input: input, line: 1, col: 1}
+	var tokens []token
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+		if t.typ == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+type lexer struct {
+	input string
+	off   int
+	line  int
+	col   int
+}
+
+func (l *lexer) peek() rune {
+	if l.off >= len(l.input) {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(l.input[l.off:])
+	return r
+}
+
+func (l *lexer) advance() rune {
+	r, w := utf8.DecodeRuneInString(l.input[l.off:])
+	l.off += w
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+func (l *lexer) skipSpaceAndComments() error {
+	for l.off < len(l.input) {
+		switch {
+		case unicode.IsSpace(l.peek()):
+			l.advance()
+		case strings.HasPrefix(l.input[l.off:], "--"):
+			for l.off < len(l.input) && l.peek() != '\n' {
+				l.advance()
+			}
+		case strings.HasPrefix(l.input[l.off:], "/*"):
+			pos := Pos{Line: l.line, Column: l.col}
+			end := strings.Index(l.input[l.off+2:], "*/")
+			if end < 0 {
+				return &Error{Pos: pos, Msg: "unterminated comment"}
+			}
+			for n := l.off + 2 + end + 2; l.off < n; {
+				l.advance()
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *lexer) next() (token, error) {
+	if err := l.skipSpaceAndComments(); err != nil {
+		return token{}, err
+	}
+	pos := Pos{Line: l.line, Column: l.col}
+	if l.off >= len(l.input) {
+		return token{typ: tokenEOF, pos: pos}, nil
+	}
+	start := l.off
+	r := l.peek()
+	switch {
+	case r == '(':
+		l.advance()
+		return token{typ: tokenLeftParen, val: "(", pos: pos}, nil
+	case r == ')':
+		l.advance()
+		return token{typ: tokenRightParen, val: ")", pos: pos}, nil
+	case r == ',':
+		l.advance()
+		return token{typ: tokenComma, val: ",", pos: pos}, nil
+	case r == ';':
+		l.advance()
+		return token{typ: tokenSemicolon, val: ";", pos: pos}, nil
+	case r == '.' && !isDigit(l.peekAt(1)):
+		l.advance()
+		return token{typ: tokenDot, val: ".", pos: pos}, nil
+	case r == '\'':
+		s, err := l.quoted('\'', pos)
+		return token{typ: tokenString, val: s, pos: pos}, err
+	case r == '"' || r == '`':
+		s, err := l.quoted(r, pos)
+		return token{typ: tokenQuotedIdent, val: s, pos: pos}, err
+	case isDigit(r) || r == '.':
+		l.number()
+		return token{typ: tokenNumber, val: l.input[start:l.off], pos: pos}, nil
+	case r == '_' || unicode.IsLetter(r):
+		for r := l.peek(); r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r); r = l.peek() {
+			l.advance()
+		}
+		return token{typ: tokenIdent, val: l.input[start:l.off], pos: pos}, nil
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(l.input[l.off:], op) {
+			for range op {
+				l.advance()
+			}
+			return token{typ: tokenOperator, val: op, pos: pos}, nil
+		}
+	}
+	return token{}, &Error{Pos: pos, Token: string(r), Msg: "unexpected character"}
+}
+
+func (l *lexer) peekAt(n int) rune {
+	if l.off+n >= len(l.input) {
+		return 0
+	}
+	return rune(l.input[l.off+n])
+}
+
+// quoted reads a string or identifier enclosed in the quote rune, where a doubled quote is an escaped quote.
+func (l *lexer) quoted(quote rune, pos Pos) (string, error) {
+	l.advance()
+	var sb strings.Builder
+	for {
+		if l.off >= len(l.input) {
+			return "", &Error{Pos: pos, Msg: fmt.Sprintf("unterminated quoted string, expected %c", quote)}
+		}
+		r := l.advance()
+		if r == quote {
+			if l.peek() != quote {
+				return sb.String(), nil
+			}
+			l.advance()
+		}
+		sb.WriteRune(r)
+	}
+}
+
+func (l *lexer) number() {
+	for isDigit(l.peek()) {
+		l.advance()
+	}
+	if l.peek() == '.' {
+		l.advance()
+		for isDigit(l.peek()) {
+			l.advance()
+		}
+	}
+	if r := l.peek(); r == 'e' || r == 'E' {
+		next := l.peekAt(1)
+		if isDigit(next) || ((next == '+' || next == '-') && isDigit(l.peekAt(2))) {
+			l.advance()
+			l.advance()
+			for isDigit(l.peek()) {
+				l.advance()
+			}
+		}
+	}
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
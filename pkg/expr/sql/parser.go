@@ -1,90 +1,935 @@
 package sql
 
 import (
-	"encoding/json"
-	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/log"
-	"github.com/jeremywohl/flatten"
-	"github.com/scottlepp/go-duck/duck"
-)
-
-const (
-	TABLE_NAME    = "table_name"
-	ERROR         = ".error"
-	ERROR_MESSAGE = ".error_message"
 )
 
 var logger = log.New("sql_expr")
 
+// Query is a parsed SQL query that can be executed over data frames.
+// A Query is safe for concurrent use.
+type Query struct {
+	raw  string
+	stmt *selectStmt
+}
+
+// Parse parses a SQL query. The error is an *Error pointing at the offending token if the query is invalid.
+func Parse(rawSQL string) (*Query, error) {
+	tokens, err := lex(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	stmt, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{raw: rawSQL, stmt: stmt}, nil
+}
+
+// String returns the SQL of the query.
+func (q *Query) String() string {
+	return q.raw
+}
+
+// Tables returns the sorted names of the tables that the query reads from,
+// which excludes the common table expressions that are defined in the query.
+func (q *Query) Tables() []string {
+	ctes := map[string]bool{}
+	tables := []string{}
+	var walkStmt func(s *selectStmt)
+	var walkTable func(t tableExpr)
+	walkStmt = func(s *selectStmt) {
+		for _, c := range s.with {
+			ctes[strings.ToLower(c.name)] = true
+			walkStmt(c.query)
+		}
+		// subqueries are only supported in the FROM clause, so only it can reference tables
+		if s.from != nil {
+			walkTable(s.from)
+		}
+	}
+	walkTable = func(t tableExpr) {
+		switch t := t.(type) {
+		case *tableRef:
+			if !existsInList(t.name, tables) {
+				tables = append(tables, t.name)
+			}
+		case *subqueryRef:
+			walkStmt(t.query)
+		case *joinExpr:
+			walkTable(t.left)
+			walkTable(t.right)
+		}
+	}
+	walkStmt(q.stmt)
+
+	result := []string{}
+	for _, t := range tables {
+		if !ctes[strings.ToLower(t)] {
+			result = append(result, t)
+		}
+	}
+	sort.Strings(result)
+
+	logger.Debug("tables found in sql", "tables", result)
+
+	return result
+}
+
 // TablesList returns a list of tables for the sql statement
 func TablesList(rawSQL string) ([]string, error) {
-	duckDB := duck.NewInMemoryDB()
-	rawSQL = strings.Replace(rawSQL, "'", "''", -1)
-	cmd := fmt.Sprintf("SELECT json_serialize_sql('%s')", rawSQL)
-	ret, err := duckDB.RunCommands([]string{cmd})
+	q, err := Parse(rawSQL)
 	if err != nil {
-		logger.Error("error serializing sql", "error", err.Error(), "sql", rawSQL, "cmd", cmd)
-		return nil, fmt.Errorf("error serializing sql: %s", err.Error())
+		return nil, err
+	}
+	return q.Tables(), nil
+}
+
+func existsInList(table string, list []string) bool {
+	for _, t := range list {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// reserved are the keywords that can not be used as identifiers without quoting them.
+var reserved = map[string]bool{
+	"ALL": true, "AND": true, "AS": true, "ASC": true, "BETWEEN": true, "BY": true, "CASE": true,
+	"CAST": true, "CROSS": true, "DESC": true, "DISTINCT": true, "ELSE": true, "END": true, "EXCEPT": true,
+	"FALSE": true, "FROM": true, "FULL": true, "GROUP": true, "HAVING": true, "ILIKE": true, "IN": true,
+	"INNER": true, "INTERSECT": true, "IS": true, "JOIN": true, "LEFT": true, "LIKE": true, "LIMIT": true,
+	"NOT": true, "NULL": true, "NULLS": true, "OFFSET": true, "ON": true, "OR": true, "ORDER": true,
+	"OUTER": true, "OVER": true, "PARTITION": true, "RIGHT": true, "ROWS": true, "SELECT": true,
+	"THEN": true, "TRUE": true, "UNION": true, "USING": true, "WHEN": true, "WHERE": true, "WITH": true,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekN(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
 	}
+	return t
+}
+
+func isKeyword(t token, kw string) bool {
+	return t.typ == tokenIdent && strings.EqualFold(t.val, kw)
+}
+
+// acceptKeyword consumes the keywords if the next tokens are the keywords.
+func (p *parser) acceptKeyword(kws ...string) bool {
+	for i, kw := range kws {
+		if !isKeyword(p.peekN(i), kw) {
+			return false
+		}
+	}
+	p.pos += len(kws)
+	return true
+}
+
+func (p *parser) expectKeyword(kws ...string) error {
+	for _, kw := range kws {
+		t := p.next()
+		if !isKeyword(t, kw) {
+			return errorAt(t, "expected %s", kw)
+		}
+	}
+	return nil
+}
+
+func (p *parser) accept(typ tokenType) bool {
+	if p.peek().typ == typ {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(typ tokenType, what string) (token, error) {
+	t := p.next()
+	if t.typ != typ {
+		return t, errorAt(t, "expected %s", what)
+	}
+	return t, nil
+}
+
+func (p *parser) acceptOperator(op string) bool {
+	if t := p.peek(); t.typ == tokenOperator && t.val == op {
+		p.next()
+		return true
+	}
+	return false
+}
+
+// isIdent returns true if the token can be used as an identifier.
+func isIdent(t token) bool {
+	return t.typ == tokenQuotedIdent || (t.typ == tokenIdent && !reserved[strings.ToUpper(t.val)])
+}
+
+func (p *parser) ident(what string) (token, error) {
+	t := p.next()
+	if !isIdent(t) {
+		return t, errorAt(t, "expected %s", what)
+	}
+	return t, nil
+}
 
-	ast := []map[string]any{}
-	err = json.Unmarshal([]byte(ret), &ast)
+func (p *parser) parseQuery() (*selectStmt, error) {
+	stmt, err := p.parseSelectWithCTE()
 	if err != nil {
-		logger.Error("error converting json sql to ast", "error", err.Error(), "ret", ret)
-		return nil, fmt.Errorf("error converting json to ast: %s", err.Error())
+		return nil, err
 	}
+	p.accept(tokenSemicolon)
+	if t := p.peek(); t.typ != tokenEOF {
+		return nil, errorAt(t, "syntax error")
+	}
+	return stmt, nil
+}
 
-	return tablesFromAST(ast)
+func (p *parser) parseSelectWithCTE() (*selectStmt, error) {
+	var ctes []cte
+	if p.acceptKeyword("WITH") {
+		for {
+			name, err := p.ident("common table expression name")
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("AS"); err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokenLeftParen, "("); err != nil {
+				return nil, err
+			}
+			q, err := p.parseSelectWithCTE()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokenRightParen, ")"); err != nil {
+				return nil, err
+			}
+			ctes = append(ctes, cte{name: name.val, query: q})
+			if !p.accept(tokenComma) {
+				break
+			}
+		}
+	}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	stmt.with = ctes
+	return stmt, nil
 }
 
-// tablesFromAST returns a list of tables from the ast
-func tablesFromAST(ast []map[string]any) ([]string, error) {
-	flat, err := flatten.Flatten(ast[0], "", flatten.DotStyle)
+func (p *parser) parseSelect() (*selectStmt, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	stmt := &selectStmt{}
+	if p.acceptKeyword("DISTINCT") {
+		stmt.distinct = true
+	} else {
+		p.acceptKeyword("ALL")
+	}
+
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		stmt.columns = append(stmt.columns, item)
+		if !p.accept(tokenComma) {
+			break
+		}
+	}
+
+	var err error
+	if p.acceptKeyword("FROM") {
+		stmt.from, err = p.parseFrom()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("WHERE") {
+		if stmt.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("GROUP", "BY") {
+		if stmt.groupBy, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("HAVING") {
+		if stmt.having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if t := p.peek(); isKeyword(t, "UNION") || isKeyword(t, "EXCEPT") || isKeyword(t, "INTERSECT") {
+		return nil, errorAt(t, "set operations are not supported")
+	}
+	if p.acceptKeyword("ORDER", "BY") {
+		if stmt.orderBy, err = p.parseOrderBy(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		if stmt.limit, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("OFFSET") {
+		if stmt.offset, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseSelectItem() (selectItem, error) {
+	t := p.peek()
+	if t.typ == tokenOperator && t.val == "*" {
+		p.next()
+		return selectItem{star: true, pos: t.pos}, nil
+	}
+	if isIdent(t) && p.peekN(1).typ == tokenDot && p.peekN(2).typ == tokenOperator && p.peekN(2).val == "*" {
+		p.pos += 3
+		return selectItem{star: true, starTable: t.val, pos: t.pos}, nil
+	}
+	e, err := p.parseExpr()
 	if err != nil {
-		logger.Error("error flattening ast", "error", err.Error(), "ast", ast)
-		return nil, fmt.Errorf("error flattening ast: %s", err.Error())
+		return selectItem{}, err
 	}
+	item := selectItem{expr: e, pos: t.pos}
+	item.alias, err = p.parseAlias()
+	return item, err
+}
 
-	tables := []string{}
-	for k, v := range flat {
-		if strings.HasSuffix(k, ERROR) {
-			v, ok := v.(bool)
-			if ok && v {
-				logger.Error("error in sql", "error", k)
-				return nil, astError(k, flat)
+// parseAlias parses an optional alias, with or without AS.
+func (p *parser) parseAlias() (string, error) {
+	if p.acceptKeyword("AS") {
+		t := p.next()
+		if !isIdent(t) && t.typ != tokenString {
+			return "", errorAt(t, "expected alias")
+		}
+		return t.val, nil
+	}
+	if t := p.peek(); isIdent(t) {
+		p.next()
+		return t.val, nil
+	}
+	return "", nil
+}
+
+func (p *parser) parseFrom() (tableExpr, error) {
+	left, err := p.parseJoin()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().typ == tokenComma {
+		t := p.next()
+		right, err := p.parseJoin()
+		if err != nil {
+			return nil, err
+		}
+		left = &joinExpr{kind: joinCross, left: left, right: right, pos: t.pos}
+	}
+	return left, nil
+}
+
+func (p *parser) parseJoin() (tableExpr, error) {
+	left, err := p.parseTablePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		var kind joinKind
+		switch {
+		case p.acceptKeyword("JOIN"), p.acceptKeyword("INNER", "JOIN"):
+			kind = joinInner
+		case p.acceptKeyword("LEFT", "JOIN"), p.acceptKeyword("LEFT", "OUTER", "JOIN"):
+			kind = joinLeft
+		case p.acceptKeyword("RIGHT", "JOIN"), p.acceptKeyword("RIGHT", "OUTER", "JOIN"):
+			kind = joinRight
+		case p.acceptKeyword("FULL", "JOIN"), p.acceptKeyword("FULL", "OUTER", "JOIN"):
+			kind = joinFull
+		case p.acceptKeyword("CROSS", "JOIN"):
+			kind = joinCross
+		default:
+			return left, nil
+		}
+		right, err := p.parseTablePrimary()
+		if err != nil {
+			return nil, err
+		}
+		join := &joinExpr{kind: kind, left: left, right: right, pos: t.pos}
+		if kind != joinCross {
+			if u := p.peek(); isKeyword(u, "USING") {
+				return nil, errorAt(u, "USING is not supported, use ON instead")
+			}
+			if err := p.expectKeyword("ON"); err != nil {
+				return nil, err
+			}
+			if join.on, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+		}
+		left = join
+	}
+}
+
+func (p *parser) parseTablePrimary() (tableExpr, error) {
+	t := p.peek()
+	if t.typ == tokenLeftParen {
+		p.next()
+		if inner := p.peek(); isKeyword(inner, "SELECT") || isKeyword(inner, "WITH") {
+			q, err := p.parseSelectWithCTE()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokenRightParen, ")"); err != nil {
+				return nil, err
+			}
+			alias, err := p.parseAlias()
+			if err != nil {
+				return nil, err
+			}
+			return &subqueryRef{query: q, alias: alias, pos: t.pos}, nil
+		}
+		join, err := p.parseFrom()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return join, nil
+	}
+	name, err := p.ident("table name")
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().typ == tokenLeftParen {
+		return nil, errorAt(name, "table functions are not supported")
+	}
+	alias, err := p.parseAlias()
+	if err != nil {
+		return nil, err
+	}
+	return &tableRef{name: name.val, alias: alias, pos: name.pos}, nil
+}
+
+func (p *parser) parseOrderBy() ([]orderItem, error) {
+	var items []orderItem
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		item := orderItem{expr: e}
+		if p.acceptKeyword("DESC") {
+			item.desc = true
+		} else {
+			p.acceptKeyword("ASC")
+		}
+		if p.acceptKeyword("NULLS", "FIRST") {
+			item.nullsFirst = true
+		} else {
+			p.acceptKeyword("NULLS", "LAST")
+		}
+		items = append(items, item)
+		if !p.accept(tokenComma) {
+			return items, nil
+		}
+	}
+}
+
+func (p *parser) parseExprList() ([]expr, error) {
+	var list []expr
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+		if !p.accept(tokenComma) {
+			return list, nil
+		}
+	}
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	return p.parseOr()
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !p.acceptKeyword("OR") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: "OR", left: left, right: right, pos: t.pos}
+	}
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !p.acceptKeyword("AND") {
+			return left, nil
+		}
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: "AND", left: left, right: right, pos: t.pos}
+	}
+}
+
+func (p *parser) parseNot() (expr, error) {
+	t := p.peek()
+	if p.acceptKeyword("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryExpr{op: "NOT", x: x, pos: t.pos}, nil
+	}
+	return p.parseComparison()
+}
+
+var comparisonOperators = map[string]string{"=": "=", "<>": "<>", "!=": "<>", "<": "<", "<=": "<=", ">": ">", ">=": ">="}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if op, ok := comparisonOperators[t.val]; ok && t.typ == tokenOperator {
+			p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			left = &binaryExpr{op: op, left: left, right: right, pos: t.pos}
+			continue
+		}
+		if p.acceptKeyword("IS") {
+			not := p.acceptKeyword("NOT")
+			if err := p.expectKeyword("NULL"); err != nil {
+				return nil, err
 			}
+			left = &isNullExpr{x: left, not: not, pos: t.pos}
+			continue
 		}
-		if strings.Contains(k, TABLE_NAME) {
-			table, ok := v.(string)
-			if ok && !existsInList(table, tables) {
-				tables = append(tables, v.(string))
+		not := false
+		if isKeyword(t, "NOT") {
+			next := p.peekN(1)
+			if !isKeyword(next, "IN") && !isKeyword(next, "BETWEEN") && !isKeyword(next, "LIKE") && !isKeyword(next, "ILIKE") {
+				return left, nil
 			}
+			p.next()
+			not = true
+		}
+		switch {
+		case p.acceptKeyword("IN"):
+			if _, err := p.expect(tokenLeftParen, "("); err != nil {
+				return nil, err
+			}
+			if s := p.peek(); isKeyword(s, "SELECT") || isKeyword(s, "WITH") {
+				return nil, errorAt(s, "subqueries are only supported in the FROM clause")
+			}
+			list, err := p.parseExprList()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokenRightParen, ")"); err != nil {
+				return nil, err
+			}
+			left = &inExpr{x: left, list: list, not: not, pos: t.pos}
+		case p.acceptKeyword("BETWEEN"):
+			low, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("AND"); err != nil {
+				return nil, err
+			}
+			high, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			left = &betweenExpr{x: left, low: low, high: high, not: not, pos: t.pos}
+		case isKeyword(p.peek(), "LIKE"), isKeyword(p.peek(), "ILIKE"):
+			ignoreCase := isKeyword(p.next(), "ILIKE")
+			pattern, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			left = &likeExpr{x: left, pattern: pattern, not: not, ignoreCase: ignoreCase, pos: t.pos}
+		default:
+			return left, nil
 		}
 	}
-	sort.Strings(tables)
+}
 
-	logger.Debug("tables found in sql", "tables", tables)
+func (p *parser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.typ != tokenOperator || (t.val != "+" && t.val != "-" && t.val != "||") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: t.val, left: left, right: right, pos: t.pos}
+	}
+}
 
-	return tables, nil
+func (p *parser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.typ != tokenOperator || (t.val != "*" && t.val != "/" && t.val != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: t.val, left: left, right: right, pos: t.pos}
+	}
 }
 
-func astError(k string, flat map[string]any) error {
-	key := strings.Replace(k, ERROR, "", 1)
-	message, ok := flat[key+ERROR_MESSAGE]
-	if !ok {
-		message = "unknown error in sql"
+func (p *parser) parseUnary() (expr, error) {
+	t := p.peek()
+	if t.typ == tokenOperator && (t.val == "-" || t.val == "+") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if t.val == "+" {
+			return x, nil
+		}
+		// fold negative number literals so that they can be used where a constant is expected
+		if lit, ok := x.(*literal); ok {
+			switch v := lit.val.(type) {
+			case int64:
+				return &literal{val: -v, pos: t.pos}, nil
+			case float64:
+				return &literal{val: -v, pos: t.pos}, nil
+			}
+		}
+		return &unaryExpr{op: "-", x: x, pos: t.pos}, nil
 	}
-	return fmt.Errorf("error in sql: %s", message)
+	return p.parsePostfix()
 }
 
-func existsInList(table string, list []string) bool {
-	for _, t := range list {
-		if t == table {
-			return true
+func (p *parser) parsePostfix() (expr, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if !p.acceptOperator("::") {
+			return x, nil
+		}
+		typ, err := p.parseTypeName()
+		if err != nil {
+			return nil, err
 		}
+		x = &castExpr{x: x, typ: typ, pos: t.pos}
 	}
-	return false
+}
+
+func (p *parser) parseTypeName() (dataType, error) {
+	t := p.next()
+	typ, ok := typeNames[strings.ToUpper(t.val)]
+	if t.typ != tokenIdent || !ok {
+		return typeNull, errorAt(t, "expected type name")
+	}
+	// precision and scale, such as DECIMAL(10, 2) or VARCHAR(255), do not change the type
+	if p.accept(tokenLeftParen) {
+		for !p.accept(tokenRightParen) {
+			if n := p.next(); n.typ != tokenNumber && n.typ != tokenComma {
+				return typeNull, errorAt(n, "expected )")
+			}
+		}
+	}
+	return typ, nil
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.typ {
+	case tokenNumber:
+		if !strings.ContainsAny(t.val, ".eE") {
+			if i, err := strconv.ParseInt(t.val, 10, 64); err == nil {
+				return &literal{val: i, pos: t.pos}, nil
+			}
+		}
+		f, err := strconv.ParseFloat(t.val, 64)
+		if err != nil {
+			return nil, errorAt(t, "invalid number")
+		}
+		return &literal{val: f, pos: t.pos}, nil
+	case tokenString:
+		return &literal{val: t.val, pos: t.pos}, nil
+	case tokenLeftParen:
+		if s := p.peek(); isKeyword(s, "SELECT") || isKeyword(s, "WITH") {
+			return nil, errorAt(s, "subqueries are only supported in the FROM clause")
+		}
+		x, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	case tokenQuotedIdent:
+		return p.parseColumnRef(t)
+	case tokenIdent:
+		switch strings.ToUpper(t.val) {
+		case "NULL":
+			return &literal{val: nil, pos: t.pos}, nil
+		case "TRUE":
+			return &literal{val: true, pos: t.pos}, nil
+		case "FALSE":
+			return &literal{val: false, pos: t.pos}, nil
+		case "CASE":
+			return p.parseCase(t)
+		case "CAST":
+			return p.parseCast(t)
+		case "TIMESTAMP":
+			if s := p.peek(); s.typ == tokenString {
+				p.next()
+				return &castExpr{x: &literal{val: s.val, pos: s.pos}, typ: typeTime, pos: t.pos}, nil
+			}
+		case "EXISTS":
+			return nil, errorAt(t, "subqueries are only supported in the FROM clause")
+		}
+		if p.peek().typ == tokenLeftParen {
+			return p.parseFuncCall(t)
+		}
+		if reserved[strings.ToUpper(t.val)] {
+			return nil, errorAt(t, "syntax error")
+		}
+		return p.parseColumnRef(t)
+	}
+	return nil, errorAt(t, "syntax error")
+}
+
+func (p *parser) parseColumnRef(t token) (expr, error) {
+	if p.peek().typ != tokenDot {
+		return &columnRef{name: t.val, pos: t.pos}, nil
+	}
+	p.next()
+	name, err := p.ident("column name")
+	if err != nil {
+		return nil, err
+	}
+	return &columnRef{table: t.val, name: name.val, pos: t.pos}, nil
+}
+
+func (p *parser) parseCase(t token) (expr, error) {
+	c := &caseExpr{pos: t.pos}
+	var err error
+	if !isKeyword(p.peek(), "WHEN") {
+		if c.operand, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	for p.acceptKeyword("WHEN") {
+		var w whenClause
+		if w.cond, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		if w.result, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		c.whens = append(c.whens, w)
+	}
+	if len(c.whens) == 0 {
+		return nil, errorAt(p.peek(), "expected WHEN")
+	}
+	if p.acceptKeyword("ELSE") {
+		if c.els, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("END"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *parser) parseCast(t token) (expr, error) {
+	if _, err := p.expect(tokenLeftParen, "("); err != nil {
+		return nil, err
+	}
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	typ, err := p.parseTypeName()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokenRightParen, ")"); err != nil {
+		return nil, err
+	}
+	return &castExpr{x: x, typ: typ, pos: t.pos}, nil
+}
+
+func (p *parser) parseFuncCall(t token) (expr, error) {
+	p.next() // (
+	f := &funcCall{name: strings.ToLower(t.val), pos: t.pos}
+	if p.acceptKeyword("DISTINCT") {
+		f.distinct = true
+	}
+	switch {
+	case p.acceptOperator("*"):
+		f.star = true
+	case p.peek().typ != tokenRightParen:
+		args, err := p.parseExprList()
+		if err != nil {
+			return nil, err
+		}
+		f.args = args
+	}
+	if _, err := p.expect(tokenRightParen, ")"); err != nil {
+		return nil, err
+	}
+	if p.acceptKeyword("OVER") {
+		w, err := p.parseWindow()
+		if err != nil {
+			return nil, err
+		}
+		f.over = w
+	}
+	return f, nil
+}
+
+func (p *parser) parseWindow() (*windowSpec, error) {
+	if _, err := p.expect(tokenLeftParen, "("); err != nil {
+		return nil, err
+	}
+	w := &windowSpec{}
+	var err error
+	if p.acceptKeyword("PARTITION", "BY") {
+		if w.partitionBy, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("ORDER", "BY") {
+		if w.orderBy, err = p.parseOrderBy(); err != nil {
+			return nil, err
+		}
+	}
+	if t := p.peek(); isKeyword(t, "RANGE") || isKeyword(t, "GROUPS") {
+		return nil, errorAt(t, "only ROWS window frames are supported")
+	}
+	if p.acceptKeyword("ROWS") {
+		frame := &windowFrame{}
+		if p.acceptKeyword("BETWEEN") {
+			if frame.start, err = p.parseFrameBound(); err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("AND"); err != nil {
+				return nil, err
+			}
+			if frame.end, err = p.parseFrameBound(); err != nil {
+				return nil, err
+			}
+		} else {
+			if frame.start, err = p.parseFrameBound(); err != nil {
+				return nil, err
+			}
+			frame.end = new(int64)
+		}
+		w.frame = frame
+	}
+	if _, err := p.expect(tokenRightParen, ")"); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// parseFrameBound returns the offset of the bound relative to the current row, or nil if it is unbounded.
+func (p *parser) parseFrameBound() (*int64, error) {
+	if p.acceptKeyword("UNBOUNDED", "PRECEDING") || p.acceptKeyword("UNBOUNDED", "FOLLOWING") {
+		return nil, nil
+	}
+	if p.acceptKeyword("CURRENT", "ROW") {
+		return new(int64), nil
+	}
+	t := p.next()
+	n, err := strconv.ParseInt(t.val, 10, 64)
+	if t.typ != tokenNumber || err != nil {
+		return nil, errorAt(t, "expected frame bound")
+	}
+	switch {
+	case p.acceptKeyword("PRECEDING"):
+		n = -n
+	case p.acceptKeyword("FOLLOWING"):
+	default:
+		return nil, errorAt(p.peek(), "expected PRECEDING or FOLLOWING")
+	}
+	return &n, nil
 }
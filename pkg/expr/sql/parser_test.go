@@ -7,7 +7,6 @@ import (
 )
 
 func TestParse(t *testing.T) {
-	t.Skip()
 	sql := "select * from foo"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -16,7 +15,6 @@ func TestParse(t *testing.T) {
 }
 
 func TestParseWithComma(t *testing.T) {
-	t.Skip()
 	sql := "select * from foo,bar"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -26,7 +24,6 @@ func TestParseWithComma(t *testing.T) {
 }
 
 func TestParseWithCommas(t *testing.T) {
-	t.Skip()
 	sql := "select * from foo,bar,baz"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -37,7 +34,6 @@ func TestParseWithCommas(t *testing.T) {
 }
 
 func TestArray(t *testing.T) {
-	t.Skip()
 	sql := "SELECT array_value(1, 2, 3)"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -46,7 +42,7 @@ func TestArray(t *testing.T) {
 }
 
 func TestArray2(t *testing.T) {
-	t.Skip()
+	t.Skip("array syntax is not supported")
 	sql := "SELECT array_value(1, 2, 3)[2]"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -55,7 +51,7 @@ func TestArray2(t *testing.T) {
 }
 
 func TestXxx(t *testing.T) {
-	t.Skip()
+	t.Skip("array syntax is not supported")
 	sql := "SELECT [3, 2, 1]::INT[3];"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -64,7 +60,6 @@ func TestXxx(t *testing.T) {
 }
 
 func TestParseSubquery(t *testing.T) {
-	t.Skip()
 	sql := "select * from (select * from people limit 1)"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -74,7 +69,6 @@ func TestParseSubquery(t *testing.T) {
 }
 
 func TestJoin(t *testing.T) {
-	t.Skip()
 	sql := `select * from A
 	JOIN B ON A.name = B.name
 	LIMIT 10`
@@ -87,7 +81,6 @@ func TestJoin(t *testing.T) {
 }
 
 func TestRightJoin(t *testing.T) {
-	t.Skip()
 	sql := `select * from A
 	RIGHT JOIN B ON A.name = B.name
 	LIMIT 10`
@@ -100,7 +93,6 @@ func TestRightJoin(t *testing.T) {
 }
 
 func TestAliasWithJoin(t *testing.T) {
-	t.Skip()
 	sql := `select * from A as X
 	RIGHT JOIN B ON A.name = X.name
 	LIMIT 10`
@@ -113,7 +105,6 @@ func TestAliasWithJoin(t *testing.T) {
 }
 
 func TestAlias(t *testing.T) {
-	t.Skip()
 	sql := `select * from A as X LIMIT 10`
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -123,14 +114,12 @@ func TestAlias(t *testing.T) {
 }
 
 func TestError(t *testing.T) {
-	t.Skip()
 	sql := `select * from zzz aaa zzz`
 	_, err := TablesList((sql))
 	assert.NotNil(t, err)
 }
 
 func TestParens(t *testing.T) {
-	t.Skip()
 	sql := `SELECT  t1.Col1,
 	t2.Col1,
 	t3.Col1
@@ -149,7 +138,6 @@ func TestParens(t *testing.T) {
 }
 
 func TestWith(t *testing.T) {
-	t.Skip()
 	sql := `WITH
 
 	current_month AS (
@@ -191,14 +179,13 @@ func TestWith(t *testing.T) {
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
 
-	assert.Equal(t, 5, len(tables))
+	assert.Equal(t, 3, len(tables))
 	assert.Equal(t, "A", tables[0])
 	assert.Equal(t, "B", tables[1])
 	assert.Equal(t, "BEE", tables[2])
 }
 
 func TestWithQuote(t *testing.T) {
-	t.Skip()
 	sql := "select *,'junk' from foo"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -207,7 +194,6 @@ func TestWithQuote(t *testing.T) {
 }
 
 func TestWithQuote2(t *testing.T) {
-	t.Skip()
 	sql := "SELECT json_serialize_sql('SELECT 1')"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -0,0 +1,317 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dataType is the type of a column or expression. Values are held as nil (NULL),
+// bool, int64, float64, string or time.Time, depending on the type.
+type dataType int
+
+const (
+	// typeNull is the type of the NULL literal, which can be used as any other type.
+	typeNull dataType = iota
+	typeBool
+	typeInt
+	typeFloat
+	typeString
+	typeTime
+)
+
+func (t dataType) String() string {
+	switch t {
+	case typeBool:
+		return "BOOLEAN"
+	case typeInt:
+		return "BIGINT"
+	case typeFloat:
+		return "DOUBLE"
+	case typeString:
+		return "VARCHAR"
+	case typeTime:
+		return "TIMESTAMP"
+	default:
+		return "NULL"
+	}
+}
+
+func (t dataType) isNumeric() bool {
+	return t == typeInt || t == typeFloat
+}
+
+// typeNames maps the names of the types that can be used in a CAST to the type.
+var typeNames = map[string]dataType{
+	"BOOLEAN":   typeBool,
+	"BOOL":      typeBool,
+	"TINYINT":   typeInt,
+	"SMALLINT":  typeInt,
+	"INTEGER":   typeInt,
+	"INT":       typeInt,
+	"BIGINT":    typeInt,
+	"HUGEINT":   typeInt,
+	"FLOAT":     typeFloat,
+	"REAL":      typeFloat,
+	"DOUBLE":    typeFloat,
+	"DECIMAL":   typeFloat,
+	"NUMERIC":   typeFloat,
+	"VARCHAR":   typeString,
+	"TEXT":      typeString,
+	"STRING":    typeString,
+	"CHAR":      typeString,
+	"TIMESTAMP": typeTime,
+	"DATETIME":  typeTime,
+}
+
+// commonType returns the type that values of both types are converted to when they are combined,
+// such as the branches of a CASE expression. Integers are widened to floats.
+func commonType(a, b dataType) (dataType, bool) {
+	switch {
+	case a == b:
+		return a, true
+	case a == typeNull:
+		return b, true
+	case b == typeNull:
+		return a, true
+	case a.isNumeric() && b.isNumeric():
+		return typeFloat, true
+	}
+	return typeNull, false
+}
+
+// convert converts a value of a compatible type to the type, as returned by commonType.
+func convert(v any, t dataType) any {
+	if i, ok := v.(int64); ok && t == typeFloat {
+		return float64(i)
+	}
+	return v
+}
+
+// castValue converts a value to the type, for CAST expressions.
+func castValue(v any, t dataType) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch t {
+	case typeBool:
+		switch v := v.(type) {
+		case bool:
+			return v, nil
+		case int64:
+			return v != 0, nil
+		case float64:
+			return v != 0, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("could not convert string '%s' to BOOLEAN", v)
+			}
+			return b, nil
+		}
+	case typeInt:
+		switch v := v.(type) {
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case int64:
+			return v, nil
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("could not convert %v to BIGINT", v)
+			}
+			return int64(math.Round(v)), nil
+		case string:
+			s := strings.TrimSpace(v)
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not convert string '%s' to BIGINT", v)
+			}
+			return int64(math.Round(f)), nil
+		case time.Time:
+			return v.UnixMilli(), nil
+		}
+	case typeFloat:
+		switch v := v.(type) {
+		case bool:
+			if v {
+				return float64(1), nil
+			}
+			return float64(0), nil
+		case int64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not convert string '%s' to DOUBLE", v)
+			}
+			return f, nil
+		case time.Time:
+			return float64(v.UnixMilli()), nil
+		}
+	case typeString:
+		return formatValue(v), nil
+	case typeTime:
+		switch v := v.(type) {
+		case time.Time:
+			return v, nil
+		case int64:
+			return time.UnixMilli(v).UTC(), nil
+		case float64:
+			return time.UnixMilli(int64(v)).UTC(), nil
+		case string:
+			return parseTime(v)
+		}
+	}
+	return nil, fmt.Errorf("could not convert %s to %s", formatValue(v), t)
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("could not convert string '%s' to TIMESTAMP", s)
+}
+
+// formatValue returns the string representation of a value, as returned when it is cast to a string.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format("2006-01-02 15:04:05.999999999")
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// compareValues compares two non-null values of compatible types, returning a negative number
+// when a < b, zero when they are equal and a positive number when a > b. NaN is larger than any number.
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case bool:
+		b := b.(bool)
+		switch {
+		case a == b:
+			return 0
+		case !a:
+			return -1
+		default:
+			return 1
+		}
+	case int64:
+		if b, ok := b.(int64); ok {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			}
+			return 0
+		}
+		return compareFloats(float64(a), b.(float64))
+	case float64:
+		if bi, ok := b.(int64); ok {
+			return compareFloats(a, float64(bi))
+		}
+		return compareFloats(a, b.(float64))
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	return 0
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b):
+		return 0
+	case math.IsNaN(a):
+		return 1
+	case math.IsNaN(b):
+		return -1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareNullable compares two values where NULL sorts as the largest value unless nullsFirst is set.
+func compareNullable(a, b any, nullsFirst bool) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		if nullsFirst {
+			return -1
+		}
+		return 1
+	case b == nil:
+		if nullsFirst {
+			return 1
+		}
+		return -1
+	}
+	return compareValues(a, b)
+}
+
+// valueKey returns a key for the value that is equal for equal values, for grouping and joining.
+func valueKey(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "\x00"
+	case bool:
+		return "b" + strconv.FormatBool(v)
+	case int64:
+		return "n" + strconv.FormatFloat(float64(v), 'g', -1, 64)
+	case float64:
+		return "n" + strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return "s" + v
+	case time.Time:
+		return "t" + strconv.FormatInt(v.UnixNano(), 10)
+	}
+	return fmt.Sprintf("?%v", v)
+}
+
+// rowKey returns a key for the values, see valueKey.
+func rowKey(values []any) string {
+	var sb strings.Builder
+	for _, v := range values {
+		k := valueKey(v)
+		sb.WriteString(strconv.Itoa(len(k)))
+		sb.WriteByte(':')
+		sb.WriteString(k)
+	}
+	return sb.String()
+}
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
-	"github.com/scottlepp/go-duck/duck"
 
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
@@ -17,7 +16,7 @@ import (
 
 // SQLCommand is an expression to run SQL over results
 type SQLCommand struct {
-	query       string
+	query       *sql.Query
 	varsToQuery []string
 	refID       string
 }
@@ -28,13 +27,14 @@ func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
 		return nil, errutil.BadRequest("sql-missing-query",
 			errutil.WithPublicMessage("missing SQL query"))
 	}
-	tables, err := sql.TablesList(rawSQL)
+	query, err := sql.Parse(rawSQL)
 	if err != nil {
 		logger.Warn("invalid sql query", "sql", rawSQL, "error", err)
 		return nil, errutil.BadRequest("sql-invalid-sql",
-			errutil.WithPublicMessage("error reading SQL command"),
-		)
+			errutil.WithPublicMessage(fmt.Sprintf("error reading SQL command: %s", err)),
+		).Errorf("error reading SQL command: %w", err)
 	}
+	tables := query.Tables()
 	if len(tables) == 0 {
 		logger.Warn("no tables found in SQL query", "sql", rawSQL)
 	}
@@ -42,7 +42,7 @@ func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
 		logger.Debug("REF tables", "tables", tables, "sql", rawSQL)
 	}
 	return &SQLCommand{
-		query:       rawSQL,
+		query:       query,
 		varsToQuery: tables,
 		refID:       refID,
 	}, nil
@@ -78,7 +78,7 @@ func (gr *SQLCommand) NeedsVars() []string {
 // Execute runs the command and returns the results or an error if the command
 // failed to execute.
 func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
-	_, span := tracer.Start(ctx, "SSE.ExecuteSQL")
+	ctx, span := tracer.Start(ctx, "SSE.ExecuteSQL")
 	defer span.End()
 
 	allFrames := []*data.Frame{}
@@ -94,11 +94,8 @@ func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.V
 
 	rsp := mathexp.Results{}
 
-	duckDB := duck.NewInMemoryDB()
-	var frame = &data.Frame{}
-
 	logger.Debug("Executing query", "query", gr.query, "frames", len(allFrames))
-	err := duckDB.QueryFramesInto(gr.refID, gr.query, allFrames, frame)
+	frame, err := gr.query.Execute(ctx, now, gr.refID, allFrames)
 	if err != nil {
 		logger.Error("Failed to query frames", "error", err.Error())
 		rsp.Error = err
@@ -112,6 +109,7 @@ func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.V
 		rsp.Values = mathexp.Values{
 			mathexp.NoData{Frame: frame},
 		}
+		return rsp, nil
 	}
 
 	rsp.Values = mathexp.Values{
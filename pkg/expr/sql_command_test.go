@@ -1,12 +1,19 @@
 package expr
 
 import (
+	"context"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 )
 
 func TestNewCommand(t *testing.T) {
-	t.Skip()
 	cmd, err := NewSQLCommand("a", "select a from foo, bar")
 	if err != nil && strings.Contains(err.Error(), "feature is not enabled") {
 		return
@@ -25,3 +32,54 @@ func TestNewCommand(t *testing.T) {
 		return
 	}
 }
+
+func TestNewCommandInvalidSQL(t *testing.T) {
+	_, err := NewSQLCommand("a", "select a from")
+	require.ErrorContains(t, err, "error reading SQL command: line 1, column 14")
+}
+
+func TestSQLCommandExecute(t *testing.T) {
+	cmd, err := NewSQLCommand("B", "SELECT host, sum(value) AS total FROM A GROUP BY host ORDER BY host")
+	require.NoError(t, err)
+	require.Equal(t, []string{"A"}, cmd.NeedsVars())
+
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{mathexp.TableData{Frame: data.NewFrame("",
+			data.NewField("host", nil, []string{"a", "b", "a"}),
+			data.NewField("value", nil, []float64{1, 2, 3}),
+		)}}},
+	}
+
+	t.Run("returns a table", func(t *testing.T) {
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Values, 1)
+
+		frame := rsp.Values[0].(mathexp.TableData).Frame
+		require.Equal(t, "B", frame.RefID)
+		a, b := "a", "b"
+		four, two := 4.0, 2.0
+		require.Equal(t, data.NewField("host", nil, []*string{&a, &b}), frame.Fields[0])
+		require.Equal(t, data.NewField("total", nil, []*float64{&four, &two}), frame.Fields[1])
+	})
+
+	t.Run("returns no data without rows", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT * FROM A WHERE value > 10")
+		require.NoError(t, err)
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, rsp.Values, 1)
+		require.IsType(t, mathexp.NoData{}, rsp.Values[0])
+	})
+
+	t.Run("returns the error of the query", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT missing FROM A")
+		require.NoError(t, err)
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.EqualError(t, rsp.Error, `line 1, column 8: column "missing" not found`)
+	})
+}
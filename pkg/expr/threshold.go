@@ -204,7 +204,11 @@ type ThresholdConditionJSON struct {
 // - field 'type' has value "threshold",
 // - field 'conditions' is array of objects and has exactly one element
 // - field 'conditions[0].unloadEvaluator is not nil
+// or a recovery command, i.e. field 'type' has value "recovery".
 func IsHysteresisExpression(query map[string]any) bool {
+	if isRecoveryExpression(query) {
+		return true
+	}
 	c, err := getConditionForHysteresisCommand(query)
 	if err != nil {
 		return false
@@ -213,7 +217,12 @@ func IsHysteresisExpression(query map[string]any) bool {
 }
 
 // SetLoadedDimensionsToHysteresisCommand mutates the input map and sets field "conditions[0].loadedMetrics" with the data frame created from the provided fingerprints.
+// If the input map is a recovery command, it sets field "loadedDimensions" instead.
 func SetLoadedDimensionsToHysteresisCommand(query map[string]any, fingerprints Fingerprints) error {
+	if isRecoveryExpression(query) {
+		query["loadedDimensions"] = FingerprintsToFrame(fingerprints)
+		return nil
+	}
 	condition, err := getConditionForHysteresisCommand(query)
 	if err != nil {
 		return err
@@ -226,6 +235,11 @@ func SetLoadedDimensionsToHysteresisCommand(query map[string]any, fingerprints F
 	return nil
 }
 
+func isRecoveryExpression(query map[string]any) bool {
+	t, err := GetExpressionCommandType(query)
+	return err == nil && t == TypeRecovery
+}
+
 func getConditionForHysteresisCommand(query map[string]any) (map[string]any, error) {
 	t, err := GetExpressionCommandType(query)
 	if err != nil {
@@ -312,6 +312,11 @@ func TestIsHysteresisExpression(t *testing.T) {
 			input:    json.RawMessage(`{ "type": "threshold", "conditions": [{ "unloadEvaluator" : {}}] }`),
 			expected: true,
 		},
+		{
+			name:     "true if type is recovery",
+			input:    json.RawMessage(`{ "type": "recovery", "expression": "B", "recoverWhen": "C" }`),
+			expected: true,
+		},
 	}
 
 	for _, tc := range cases {
@@ -15,6 +15,7 @@ import (
 // Request is similar to plugins.DataQuery but with the Time Ranges is per Query.
 type Request struct {
 	Headers map[string]string
+	// Debug adds the explanation of how each node is executed to the responses.
 	Debug   bool
 	OrgId   int64
 	Queries []Query
@@ -93,7 +94,10 @@ func (s *Service) TransformData(ctx context.Context, now time.Time, req *Request
 		return nil, err
 	}
 
-	// Execute the pipeline
+	// Execute the pipeline, explaining how each node is executed in the responses when debugging
+	if req.Debug {
+		ctx, _ = WithExplain(ctx)
+	}
 	responses, err := s.ExecutePipeline(ctx, now, pipeline)
 	if err != nil {
 		return nil, err
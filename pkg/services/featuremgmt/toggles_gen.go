@@ -580,7 +580,7 @@ const (
 	FlagPromQLScope = "promQLScope"
 
 	// FlagSqlExpressions
-	// Enables using SQL and DuckDB functions as Expressions.
+	// Enables using SQL queries over query results as Expressions.
 	FlagSqlExpressions = "sqlExpressions"
 
 	// FlagNodeGraphDotLayout
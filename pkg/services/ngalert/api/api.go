@@ -63,6 +63,7 @@ type API struct {
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	StateManager         *state.Manager
+	EvaluationDelays     EvaluationDelayReader
 	AccessControl        ac.AccessControl
 	Policies             *provisioning.NotificationPolicyService
 	ReceiverService      *notifier.ReceiverService
@@ -70,12 +71,17 @@ type API struct {
 	Templates            *provisioning.TemplateService
 	MuteTimings          *provisioning.MuteTimingService
 	AlertRules           *provisioning.AlertRuleService
+	Configuration        *provisioning.ConfigurationService
 	AlertsRouter         *sender.AlertsRouter
 	EvaluatorFactory     eval.EvaluatorFactory
 	FeatureManager       featuremgmt.FeatureToggles
 	Historian            Historian
 	Tracer               tracing.Tracer
 	AppUrl               *url.URL
+	// RecordTargetValidator validates the targets of recording rules when they are saved.
+	RecordTargetValidator RecordTargetValidator
+	// LocalTSDB provides the results of recording rules written to the local time series database.
+	LocalTSDB LocalTSDB
 
 	// Hooks can be used to replace API handlers for specific paths.
 	Hooks *Hooks
@@ -107,13 +113,16 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 				api.RuleStore,
 				ruleAuthzService,
 			),
+			ruleStore:            api.RuleStore,
+			ruleAuthz:            ruleAuthzService,
+			disableGrafanaFolder: api.Cfg.UnifiedAlerting.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel),
 		},
 	), m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkingProm(
 		api.DatasourceCache,
 		NewLotexProm(proxy, logger),
-		&PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore, authz: ruleAuthzService},
+		&PrometheusSrv{log: logger, manager: api.StateManager, delays: api.EvaluationDelays, store: api.RuleStore, authz: ruleAuthzService, localTSDB: api.LocalTSDB, queryEngine: newLocalQueryEngine()},
 	), m)
 	// Register endpoints for proxying to Cortex Ruler-compatible backends.
 	api.RegisterRulerApiEndpoints(NewForkingRuler(
@@ -131,6 +140,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			amConfigStore:      api.AlertingStore,
 			amRefresher:        api.MultiOrgAlertmanager,
 			featureManager:     api.FeatureManager,
+			recordTargets:      api.RecordTargetValidator,
 		},
 	), m)
 	api.RegisterTestingApiEndpoints(NewTestingApi(
@@ -146,6 +156,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			appUrl:          api.AppUrl,
 			tracer:          api.Tracer,
 			folderService:   api.RuleStore,
+			amConfig:        api.MultiOrgAlertmanager,
 		}), m)
 	api.RegisterConfigurationApiEndpoints(NewConfiguration(
 		&ConfigSrv{
@@ -164,6 +175,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		templates:           api.Templates,
 		muteTimings:         api.MuteTimings,
 		alertRules:          api.AlertRules,
+		configuration:       api.Configuration,
 		// XXX: Used to flag recording rules, remove when FT is removed
 		featureManager: api.FeatureManager,
 	}), m)
@@ -173,6 +185,13 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		hist:   api.Historian,
 	}), m)
 
+	api.RegisterStateApiEndpoints(NewStateApi(&StateSrv{
+		logger:   logger,
+		store:    api.RuleStore,
+		authz:    ruleAuthzService,
+		transfer: api.StateManager,
+	}), m)
+
 	api.RegisterNotificationsApiEndpoints(NewNotificationsApi(&NotificationSrv{
 		logger:            logger,
 		receiverService:   api.ReceiverService,
@@ -33,6 +33,10 @@ type AlertmanagerSrv struct {
 	mam        *notifier.MultiOrgAlertmanager
 	crypto     notifier.Crypto
 	silenceSvc SilenceService
+	ruleStore  RuleStore
+	ruleAuthz  RuleAccessControlService
+	// disableGrafanaFolder is true if the grafana_folder label is not added to the alerts of the rules.
+	disableGrafanaFolder bool
 }
 
 type UnknownReceiverError struct {
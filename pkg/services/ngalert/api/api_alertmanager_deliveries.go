@@ -0,0 +1,64 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+const defaultNotificationDeliveriesLimit = 100
+
+// RouteGetNotificationDeliveries returns the latest notification deliveries of Grafana AM, the most recent first.
+func (srv AlertmanagerSrv) RouteGetNotificationDeliveries(c *contextmodel.ReqContext) response.Response {
+	status := notifier.NotificationDeliveryStatus(c.Query("status"))
+	switch status {
+	case "", notifier.NotificationDeliverySent, notifier.NotificationDeliveryRetrying, notifier.NotificationDeliveryFailed:
+	default:
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid status '%s', must be one of sent, retrying or failed", status), "")
+	}
+	limit := c.QueryInt("limit")
+	if limit < 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid limit %d, must be positive", limit), "")
+	}
+	if limit == 0 {
+		limit = defaultNotificationDeliveriesLimit
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.SignedInUser.GetOrgID())
+	if errResp != nil {
+		return errResp
+	}
+	deliveries, err := am.GetNotificationDeliveries(c.Req.Context(), notifier.NotificationDeliveryQuery{
+		Receiver:    c.Query("receiver"),
+		Integration: c.Query("integration"),
+		GroupKey:    c.Query("groupKey"),
+		Status:      status,
+		Limit:       limit,
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification deliveries")
+	}
+
+	result := make(apimodels.NotificationDeliveries, 0, len(deliveries))
+	for _, d := range deliveries {
+		result = append(result, apimodels.NotificationDelivery{
+			Timestamp:        d.Timestamp,
+			Receiver:         d.Receiver,
+			Integration:      d.Integration,
+			IntegrationIndex: d.IntegrationIndex,
+			GroupKey:         d.GroupKey,
+			PayloadHash:      d.PayloadHash,
+			Alerts:           d.Alerts,
+			Status:           string(d.Status),
+			StatusCode:       d.StatusCode,
+			Retries:          d.Retries,
+			DurationMs:       d.Duration.Milliseconds(),
+			Error:            d.Error,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
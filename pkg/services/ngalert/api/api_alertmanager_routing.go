@@ -0,0 +1,228 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// RoutePostRoutingExplain explains how Grafana AM routes an alert with the given labels, or an alert it already has.
+func (srv AlertmanagerSrv) RoutePostRoutingExplain(c *contextmodel.ReqContext, body apimodels.PostableRoutingExplain) response.Response {
+	if (len(body.Labels) == 0) == (body.Fingerprint == "") {
+		return ErrResp(http.StatusBadRequest, errors.New("either labels or fingerprint must be set"), "")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.SignedInUser.GetOrgID())
+	if errResp != nil {
+		return errResp
+	}
+
+	lset := make(model.LabelSet, len(body.Labels))
+	if body.Fingerprint != "" {
+		alerts, err := am.GetAlerts(c.Req.Context(), true, true, true, nil, "")
+		if err != nil {
+			if errors.Is(err, alertingNotify.ErrGetAlertsBadPayload) {
+				return ErrResp(http.StatusBadRequest, err, "")
+			}
+			if errors.Is(err, alertingNotify.ErrGetAlertsUnavailable) {
+				return ErrResp(http.StatusServiceUnavailable, err, "")
+			}
+			return ErrResp(http.StatusInternalServerError, err, "")
+		}
+		idx := slices.IndexFunc(alerts, func(a *apimodels.GettableAlert) bool {
+			return a.Fingerprint != nil && *a.Fingerprint == body.Fingerprint
+		})
+		if idx < 0 {
+			return ErrResp(http.StatusNotFound, errors.New("alert not found"), "")
+		}
+		for k, v := range alerts[idx].Labels {
+			lset[model.LabelName(k)] = model.LabelValue(v)
+		}
+	} else {
+		for k, v := range body.Labels {
+			lset[model.LabelName(k)] = model.LabelValue(v)
+		}
+		if err := lset.Validate(); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid labels")
+		}
+	}
+
+	explanations, err := am.ExplainRouting(c.Req.Context(), []model.LabelSet{lset})
+	if err != nil {
+		return routingErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, routingExplanationToApi(explanations[0]))
+}
+
+// RouteGetRulesRouting returns the routes the alerts of each alert rule the user has access to are routed to, given
+// the labels of the rule. Recording rules are skipped because they do not send alerts.
+func (srv AlertmanagerSrv) RouteGetRulesRouting(c *contextmodel.ReqContext) response.Response {
+	orgID := c.SignedInUser.GetOrgID()
+	am, errResp := srv.AlertmanagerFor(orgID)
+	if errResp != nil {
+		return errResp
+	}
+
+	namespaces, err := srv.ruleStore.GetUserVisibleNamespaces(c.Req.Context(), orgID, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get folders")
+	}
+	if len(namespaces) == 0 {
+		return response.JSON(http.StatusOK, apimodels.RulesRouting{})
+	}
+	namespaceUIDs := make([]string, 0, len(namespaces))
+	for uid := range namespaces {
+		namespaceUIDs = append(namespaceUIDs, uid)
+	}
+	rules, err := srv.ruleStore.ListAlertRules(c.Req.Context(), &ngmodels.ListAlertRulesQuery{
+		OrgID:         orgID,
+		NamespaceUIDs: namespaceUIDs,
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+
+	var authorized ngmodels.RulesGroup
+	for _, group := range ngmodels.GroupByAlertRuleGroupKey(rules) {
+		ok, err := srv.ruleAuthz.HasAccessToRuleGroup(c.Req.Context(), c.SignedInUser, group)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to authorize access to alert rules")
+		}
+		if !ok {
+			continue
+		}
+		for _, rule := range group {
+			if rule.Type() == ngmodels.RuleTypeRecording {
+				continue
+			}
+			authorized = append(authorized, rule)
+		}
+	}
+	sort.SliceStable(authorized, func(i, j int) bool {
+		if authorized[i].NamespaceUID != authorized[j].NamespaceUID {
+			return authorized[i].NamespaceUID < authorized[j].NamespaceUID
+		}
+		if authorized[i].RuleGroup != authorized[j].RuleGroup {
+			return authorized[i].RuleGroup < authorized[j].RuleGroup
+		}
+		return authorized[i].RuleGroupIndex < authorized[j].RuleGroupIndex
+	})
+
+	result := make(apimodels.RulesRouting, 0, len(authorized))
+	labelSets := make([]model.LabelSet, 0, len(authorized))
+	for _, rule := range authorized {
+		folderTitle := ""
+		if f, ok := namespaces[rule.NamespaceUID]; ok {
+			folderTitle = f.Fullpath
+		}
+		labels := make(map[string]string, len(rule.Labels)+4)
+		templated := false
+		for k, v := range rule.Labels {
+			labels[k] = v
+			templated = templated || strings.Contains(v, "{{")
+		}
+		for k, v := range state.GetRuleExtraLabels(srv.log, rule, folderTitle, !srv.disableGrafanaFolder) {
+			labels[k] = v
+		}
+		lset := make(model.LabelSet, len(labels))
+		for k, v := range labels {
+			lset[model.LabelName(k)] = model.LabelValue(v)
+		}
+		labelSets = append(labelSets, lset)
+		result = append(result, apimodels.RuleRouting{
+			RuleUID:         rule.UID,
+			Title:           rule.Title,
+			FolderUID:       rule.NamespaceUID,
+			RuleGroup:       rule.RuleGroup,
+			Labels:          labels,
+			TemplatedLabels: templated,
+		})
+	}
+
+	explanations, err := am.ExplainRouting(c.Req.Context(), labelSets)
+	if err != nil {
+		return routingErrorResponse(err)
+	}
+	for i, e := range explanations {
+		explanation := routingExplanationToApi(e)
+		result[i].Routes = explanation.Routes
+		result[i].Receivers = make([]string, 0, len(explanation.Routes))
+		for _, r := range explanation.Routes {
+			if !slices.Contains(result[i].Receivers, r.Receiver) {
+				result[i].Receivers = append(result[i].Receivers, r.Receiver)
+			}
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func routingErrorResponse(err error) response.Response {
+	if errors.Is(err, notifier.ErrAlertmanagerNotReady) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to explain routing")
+}
+
+func routingExplanationToApi(e notifier.RoutingExplanation) apimodels.RoutingExplanation {
+	result := apimodels.RoutingExplanation{
+		Labels: labelSetToMap(e.Labels),
+		Routes: make([]apimodels.RoutingMatchedRoute, 0, len(e.Routes)),
+	}
+	for _, r := range e.Routes {
+		route := apimodels.RoutingMatchedRoute{
+			Path:                make([]apimodels.RoutingRouteStep, 0, len(r.Path)),
+			Receiver:            r.Receiver,
+			GroupBy:             r.GroupBy,
+			GroupLabels:         labelSetToMap(r.GroupLabels),
+			GroupWait:           model.Duration(r.GroupWait),
+			GroupInterval:       model.Duration(r.GroupInterval),
+			RepeatInterval:      model.Duration(r.RepeatInterval),
+			MuteTimeIntervals:   r.MuteTimeIntervals,
+			ActiveTimeIntervals: r.ActiveTimeIntervals,
+			Muted:               r.Muted,
+			MutedBy:             r.MutedBy,
+		}
+		for _, s := range r.Path {
+			route.Path = append(route.Path, apimodels.RoutingRouteStep{
+				Index:    s.Index,
+				Receiver: s.Receiver,
+				Matchers: s.Matchers,
+				Continue: s.Continue,
+			})
+		}
+		result.Routes = append(result.Routes, route)
+	}
+	for _, i := range e.InhibitedBy {
+		inhibition := apimodels.RoutingInhibition{
+			SourceMatchers: i.SourceMatchers,
+			TargetMatchers: i.TargetMatchers,
+			Equal:          i.Equal,
+			SourceAlerts:   make([]map[string]string, 0, len(i.SourceAlerts)),
+		}
+		for _, a := range i.SourceAlerts {
+			inhibition.SourceAlerts = append(inhibition.SourceAlerts, labelSetToMap(a))
+		}
+		result.InhibitedBy = append(result.InhibitedBy, inhibition)
+	}
+	return result
+}
+
+func labelSetToMap(lset model.LabelSet) map[string]string {
+	result := make(map[string]string, len(lset))
+	for k, v := range lset {
+		result[string(k)] = string(v)
+	}
+	return result
+}
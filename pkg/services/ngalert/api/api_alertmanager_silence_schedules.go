@@ -0,0 +1,129 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// RouteGetSilenceTemplates is the silence templates list GET endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteGetSilenceTemplates(c *contextmodel.ReqContext) response.Response {
+	templates, err := srv.silenceSvc.ListSilenceTemplates(c.Req.Context(), c.SignedInUser)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list silence templates", err)
+	}
+	return response.JSON(http.StatusOK, SilenceTemplatesToAPI(templates))
+}
+
+// RouteCreateSilenceTemplate is the silence template POST (create + update) endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteCreateSilenceTemplate(c *contextmodel.ReqContext, template apimodels.SilenceTemplate) response.Response {
+	uid, err := srv.silenceSvc.SaveSilenceTemplate(c.Req.Context(), c.SignedInUser, SilenceTemplateFromAPI(template))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create/update silence template", err)
+	}
+	return response.JSON(http.StatusAccepted, apimodels.PostSilenceTemplateOKBody{
+		UID: uid,
+	})
+}
+
+// RouteDeleteSilenceTemplate is the silence template DELETE endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteDeleteSilenceTemplate(c *contextmodel.ReqContext, uid string) response.Response {
+	if err := srv.silenceSvc.DeleteSilenceTemplate(c.Req.Context(), c.SignedInUser, uid); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete silence template", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "silence template deleted"})
+}
+
+// RouteGetSilenceSchedules is the silence schedules list GET endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteGetSilenceSchedules(c *contextmodel.ReqContext) response.Response {
+	schedules, err := srv.silenceSvc.ListSilenceSchedules(c.Req.Context(), c.SignedInUser)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list silence schedules", err)
+	}
+	return response.JSON(http.StatusOK, SilenceSchedulesToAPI(schedules))
+}
+
+// RouteCreateSilenceSchedule is the silence schedule POST (create + update) endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteCreateSilenceSchedule(c *contextmodel.ReqContext, schedule apimodels.SilenceSchedule) response.Response {
+	s := SilenceScheduleFromAPI(schedule)
+	if s.CreatedBy == "" {
+		s.CreatedBy = c.SignedInUser.GetLogin()
+	}
+	uid, err := srv.silenceSvc.SaveSilenceSchedule(c.Req.Context(), c.SignedInUser, s)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create/update silence schedule", err)
+	}
+	return response.JSON(http.StatusAccepted, apimodels.PostSilenceScheduleOKBody{
+		UID: uid,
+	})
+}
+
+// RouteDeleteSilenceSchedule is the silence schedule DELETE endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteDeleteSilenceSchedule(c *contextmodel.ReqContext, uid string) response.Response {
+	if err := srv.silenceSvc.DeleteSilenceSchedule(c.Req.Context(), c.SignedInUser, uid); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete silence schedule", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "silence schedule deleted"})
+}
+
+// RoutePreviewSilence returns the alerts of Grafana AM that a silence with the given matchers would silence,
+// including the alerts that are already silenced or inhibited.
+func (srv AlertmanagerSrv) RoutePreviewSilence(c *contextmodel.ReqContext, preview apimodels.PostableSilencePreview) response.Response {
+	if err := preview.Matchers.Validate(strfmt.Default); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "silence preview failed validation")
+	}
+	filter, err := silenceMatchersToFilter(preview.Matchers)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "silence preview failed validation")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.SignedInUser.GetOrgID())
+	if errResp != nil {
+		return errResp
+	}
+	alerts, err := am.GetAlerts(c.Req.Context(), true, true, true, filter, "")
+	if err != nil {
+		if errors.Is(err, alertingNotify.ErrGetAlertsBadPayload) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		if errors.Is(err, alertingNotify.ErrGetAlertsUnavailable) {
+			return ErrResp(http.StatusServiceUnavailable, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+	return response.JSON(http.StatusOK, alerts)
+}
+
+// silenceMatchersToFilter converts the matchers of a silence to the filter of the alerts API.
+func silenceMatchersToFilter(matchers amv2.Matchers) ([]string, error) {
+	filter := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		t := labels.MatchEqual
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		switch {
+		case *m.IsRegex && isEqual:
+			t = labels.MatchRegexp
+		case *m.IsRegex:
+			t = labels.MatchNotRegexp
+		case !isEqual:
+			t = labels.MatchNotEqual
+		}
+		matcher, err := labels.NewMatcher(t, *m.Name, *m.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid matcher for label '%s': %w", *m.Name, err)
+		}
+		filter = append(filter, matcher.String())
+	}
+	return filter, nil
+}
@@ -23,6 +23,13 @@ type SilenceService interface {
 	DeleteSilence(ctx context.Context, user identity.Requester, silenceID string) error
 	WithAccessControlMetadata(ctx context.Context, user identity.Requester, silencesWithMetadata ...*models.SilenceWithMetadata) error
 	WithRuleMetadata(ctx context.Context, user identity.Requester, silences ...*models.SilenceWithMetadata) error
+
+	ListSilenceTemplates(ctx context.Context, user identity.Requester) ([]*models.SilenceTemplate, error)
+	SaveSilenceTemplate(ctx context.Context, user identity.Requester, t models.SilenceTemplate) (string, error)
+	DeleteSilenceTemplate(ctx context.Context, user identity.Requester, uid string) error
+	ListSilenceSchedules(ctx context.Context, user identity.Requester) ([]*models.SilenceSchedule, error)
+	SaveSilenceSchedule(ctx context.Context, user identity.Requester, s models.SilenceSchedule) (string, error)
+	DeleteSilenceSchedule(ctx context.Context, user identity.Requester, uid string) error
 }
 
 // RouteGetSilence is the single silence GET endpoint for Grafana AM.
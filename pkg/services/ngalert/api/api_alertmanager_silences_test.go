@@ -162,3 +162,21 @@ func TestRouteCreateSilence(t *testing.T) {
 		})
 	}
 }
+
+func TestSilenceMatchersToFilter(t *testing.T) {
+	matcher := func(name, value string, isEqual, isRegex bool) *amv2.Matcher {
+		return &amv2.Matcher{Name: &name, Value: &value, IsEqual: &isEqual, IsRegex: &isRegex}
+	}
+
+	filter, err := silenceMatchersToFilter(amv2.Matchers{
+		matcher("team", "a", true, false),
+		matcher("env", "prod", false, false),
+		matcher("severity", "crit.*", true, true),
+		matcher("region", "eu-.*", false, true),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{`team="a"`, `env!="prod"`, `severity=~"crit.*"`, `region!~"eu-.*"`}, filter)
+
+	_, err = silenceMatchersToFilter(amv2.Matchers{matcher("team", "(", true, true)})
+	require.ErrorContains(t, err, "invalid matcher for label 'team'")
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/prometheus/alertmanager/pkg/labels"
 	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/prometheus/promql"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -27,8 +28,13 @@ import (
 type PrometheusSrv struct {
 	log     log.Logger
 	manager state.AlertInstanceManager
+	delays  EvaluationDelayReader
 	store   RuleStore
 	authz   RuleAccessControlService
+	// localTSDB and queryEngine serve the queries on the results of recording rules written to the local time series
+	// database.
+	localTSDB   LocalTSDB
+	queryEngine *promql.Engine
 }
 
 const queryIncludeInternalLabels = "includeInternalLabels"
@@ -190,6 +196,14 @@ type RuleGroupStatusesOptions struct {
 	Query              url.Values
 	Namespaces         map[string]string
 	AuthorizeRuleGroup func(rules []*ngmodels.AlertRule) (bool, error)
+	// Delays reports the rules whose evaluation is delayed by the evaluation budgets. Optional.
+	Delays EvaluationDelayReader
+}
+
+// EvaluationDelayReader provides the rules whose last evaluation waited for the evaluation budgets of their
+// organization or data sources for longer than the scheduler interval.
+type EvaluationDelayReader interface {
+	EvaluationDelay(key ngmodels.AlertRuleKey) (time.Duration, bool)
 }
 
 type ListAlertRulesStore interface {
@@ -227,6 +241,7 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *contextmodel.ReqContext) respon
 		OrgID:      c.OrgID,
 		Query:      c.Req.Form,
 		Namespaces: namespaces,
+		Delays:     srv.delays,
 		AuthorizeRuleGroup: func(rules []*ngmodels.AlertRule) (bool, error) {
 			return srv.authz.HasAccessToRuleGroup(c.Req.Context(), c.SignedInUser, rules)
 		},
@@ -370,7 +385,7 @@ func PrepareRuleGroupStatuses(log log.Logger, manager state.AlertInstanceManager
 			continue
 		}
 
-		ruleGroup, totals := toRuleGroup(log, manager, groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions)
+		ruleGroup, totals := toRuleGroup(log, manager, opts.Delays, groupKey, folder, rules, limitAlertsPerRule, withStatesFast, matchers, labelOptions)
 		ruleGroup.Totals = totals
 		for k, v := range totals {
 			rulesTotals[k] += v
@@ -428,7 +443,7 @@ func matchersMatch(matchers []*labels.Matcher, labels map[string]string) bool {
 	return true
 }
 
-func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ngmodels.AlertRuleGroupKey, folderFullPath string, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption) (*apimodels.RuleGroup, map[string]int64) {
+func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, delays EvaluationDelayReader, groupKey ngmodels.AlertRuleGroupKey, folderFullPath string, rules []*ngmodels.AlertRule, limitAlerts int64, withStates map[eval.State]struct{}, matchers labels.Matchers, labelOptions []ngmodels.LabelOption) (*apimodels.RuleGroup, map[string]int64) {
 	newGroup := &apimodels.RuleGroup{
 		Name: groupKey.RuleGroup,
 		// file is what Prometheus uses for provisioning, we replace it with namespace which is the folder in Grafana.
@@ -532,7 +547,14 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 			rulesTotals[alertingRule.State] += 1
 		}
 
-		if newRule.Health == "error" || newRule.Health == "nodata" {
+		if delays != nil && newRule.Health == "ok" {
+			if delay, ok := delays.EvaluationDelay(rule.GetKey()); ok {
+				newRule.Health = "delayed"
+				newRule.LastError = fmt.Sprintf("evaluation was delayed by %s because of evaluation budgets", delay.Round(time.Second))
+			}
+		}
+
+		if newRule.Health == "error" || newRule.Health == "nodata" || newRule.Health == "delayed" {
 			rulesTotals[newRule.Health] += 1
 		}
 
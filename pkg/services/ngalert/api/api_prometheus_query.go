@@ -0,0 +1,339 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/util/annotations"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// LocalTSDB provides the results of recording rules written to the local time series database.
+type LocalTSDB interface {
+	// LocalQueryable returns the series of the organization, or false if the local time series database is disabled.
+	LocalQueryable(orgID int64) (storage.Queryable, bool)
+}
+
+const (
+	// localQueryTimeout, localQueryMaxSamples and localQueryMaxPoints are the defaults of Prometheus.
+	localQueryTimeout    = 2 * time.Minute
+	localQueryMaxSamples = 50000000
+	localQueryMaxPoints  = 11000
+)
+
+var (
+	errLocalTSDBDisabled = errors.New("the local time series database is not enabled")
+
+	// The time range of the label and series queries without a start or an end.
+	localQueryMinTime = int64(math.MinInt64)
+	localQueryMaxTime = int64(math.MaxInt64)
+)
+
+func newLocalQueryEngine() *promql.Engine {
+	return promql.NewEngine(promql.EngineOpts{
+		MaxSamples:           localQueryMaxSamples,
+		Timeout:              localQueryTimeout,
+		EnableAtModifier:     true,
+		EnableNegativeOffset: true,
+	})
+}
+
+// RouteQuery evaluates an instant query on the results of recording rules written to the local time series database,
+// like the query endpoint of the Prometheus HTTP API.
+func (srv PrometheusSrv) RouteQuery(c *contextmodel.ReqContext) response.Response {
+	queryable, ok := srv.localQueryable(c)
+	if !ok {
+		return queryErrorResponse(http.StatusNotFound, apiv1.ErrBadData, errLocalTSDBDisabled)
+	}
+	if err := c.Req.ParseForm(); err != nil {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, err)
+	}
+	ts := time.Now()
+	if v := c.Req.Form.Get("time"); v != "" {
+		var err error
+		if ts, err = parsePrometheusTime(v); err != nil {
+			return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, fmt.Errorf("invalid parameter 'time': %w", err))
+		}
+	}
+	q, err := srv.queryEngine.NewInstantQuery(c.Req.Context(), queryable, nil, c.Req.Form.Get("query"), ts)
+	if err != nil {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, fmt.Errorf("invalid parameter 'query': %w", err))
+	}
+	return execQuery(c.Req.Context(), q)
+}
+
+// RouteQueryRange evaluates a range query on the results of recording rules written to the local time series
+// database, like the query_range endpoint of the Prometheus HTTP API.
+func (srv PrometheusSrv) RouteQueryRange(c *contextmodel.ReqContext) response.Response {
+	queryable, ok := srv.localQueryable(c)
+	if !ok {
+		return queryErrorResponse(http.StatusNotFound, apiv1.ErrBadData, errLocalTSDBDisabled)
+	}
+	if err := c.Req.ParseForm(); err != nil {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, err)
+	}
+	start, err := parsePrometheusTime(c.Req.Form.Get("start"))
+	if err != nil {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, fmt.Errorf("invalid parameter 'start': %w", err))
+	}
+	end, err := parsePrometheusTime(c.Req.Form.Get("end"))
+	if err != nil {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, fmt.Errorf("invalid parameter 'end': %w", err))
+	}
+	if end.Before(start) {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, errors.New("end timestamp must not be before start time"))
+	}
+	step, err := parsePrometheusDuration(c.Req.Form.Get("step"))
+	if err != nil {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, fmt.Errorf("invalid parameter 'step': %w", err))
+	}
+	if step <= 0 {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, errors.New("zero or negative query resolution step widths are not accepted"))
+	}
+	if end.Sub(start)/step > localQueryMaxPoints {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, fmt.Errorf("exceeded maximum resolution of %d points per timeseries", localQueryMaxPoints))
+	}
+	q, err := srv.queryEngine.NewRangeQuery(c.Req.Context(), queryable, nil, c.Req.Form.Get("query"), start, end, step)
+	if err != nil {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, fmt.Errorf("invalid parameter 'query': %w", err))
+	}
+	return execQuery(c.Req.Context(), q)
+}
+
+// RouteGetLabels returns the label names of the results of recording rules written to the local time series
+// database, like the labels endpoint of the Prometheus HTTP API.
+func (srv PrometheusSrv) RouteGetLabels(c *contextmodel.ReqContext) response.Response {
+	return srv.labelsResponse(c, func(ctx context.Context, q storage.Querier, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+		return q.LabelNames(ctx, matchers...)
+	})
+}
+
+// RouteGetLabelValues returns the values of a label of the results of recording rules written to the local time series
+// database, like the label values endpoint of the Prometheus HTTP API.
+func (srv PrometheusSrv) RouteGetLabelValues(c *contextmodel.ReqContext, name string) response.Response {
+	if !model.LabelName(name).IsValid() {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, fmt.Errorf("invalid label name: %q", name))
+	}
+	return srv.labelsResponse(c, func(ctx context.Context, q storage.Querier, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+		return q.LabelValues(ctx, name, matchers...)
+	})
+}
+
+// RouteGetSeries returns the series of the results of recording rules written to the local time series database
+// that match the selectors, like the series endpoint of the Prometheus HTTP API.
+func (srv PrometheusSrv) RouteGetSeries(c *contextmodel.ReqContext) response.Response {
+	queryable, ok := srv.localQueryable(c)
+	if !ok {
+		return queryErrorResponse(http.StatusNotFound, apiv1.ErrBadData, errLocalTSDBDisabled)
+	}
+	start, end, matcherSets, err := parseSeriesParams(c)
+	if err != nil {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, err)
+	}
+	if len(matcherSets) == 0 {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, errors.New("no match[] parameter provided"))
+	}
+	q, err := queryable.Querier(start, end)
+	if err != nil {
+		return queryErrorResponse(http.StatusInternalServerError, apiv1.ErrServer, err)
+	}
+	defer func() { _ = q.Close() }()
+
+	hints := &storage.SelectHints{Start: start, End: end, Func: "series"}
+	seen := make(map[string]struct{})
+	result := apimodels.PrometheusSeriesResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{Status: "success"},
+		Data:          []map[string]string{},
+	}
+	var warnings annotations.Annotations
+	for _, matchers := range matcherSets {
+		set := q.Select(c.Req.Context(), false, hints, matchers...)
+		for set.Next() {
+			lbls := set.At().Labels()
+			if _, ok := seen[lbls.String()]; ok {
+				continue
+			}
+			seen[lbls.String()] = struct{}{}
+			result.Data = append(result.Data, lbls.Map())
+		}
+		if err := set.Err(); err != nil {
+			return queryErrorResponse(http.StatusInternalServerError, apiv1.ErrServer, err)
+		}
+		warnings.Merge(set.Warnings())
+	}
+	result.Warnings = warnings.AsStrings("", 0)
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv PrometheusSrv) localQueryable(c *contextmodel.ReqContext) (storage.Queryable, bool) {
+	if srv.localTSDB == nil {
+		return nil, false
+	}
+	return srv.localTSDB.LocalQueryable(c.SignedInUser.GetOrgID())
+}
+
+// labelsResponse returns the sorted union of the label names or values returned by get for each selector.
+func (srv PrometheusSrv) labelsResponse(c *contextmodel.ReqContext, get func(context.Context, storage.Querier, ...*labels.Matcher) ([]string, annotations.Annotations, error)) response.Response {
+	queryable, ok := srv.localQueryable(c)
+	if !ok {
+		return queryErrorResponse(http.StatusNotFound, apiv1.ErrBadData, errLocalTSDBDisabled)
+	}
+	start, end, matcherSets, err := parseSeriesParams(c)
+	if err != nil {
+		return queryErrorResponse(http.StatusBadRequest, apiv1.ErrBadData, err)
+	}
+	q, err := queryable.Querier(start, end)
+	if err != nil {
+		return queryErrorResponse(http.StatusInternalServerError, apiv1.ErrServer, err)
+	}
+	defer func() { _ = q.Close() }()
+
+	if len(matcherSets) == 0 {
+		matcherSets = [][]*labels.Matcher{nil}
+	}
+	unique := make(map[string]struct{})
+	var warnings annotations.Annotations
+	for _, matchers := range matcherSets {
+		values, w, err := get(c.Req.Context(), q, matchers...)
+		if err != nil {
+			return queryErrorResponse(http.StatusInternalServerError, apiv1.ErrServer, err)
+		}
+		warnings.Merge(w)
+		for _, v := range values {
+			unique[v] = struct{}{}
+		}
+	}
+	result := apimodels.PrometheusLabelsResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{Status: "success"},
+		Data:          make([]string, 0, len(unique)),
+		Warnings:      warnings.AsStrings("", 0),
+	}
+	for v := range unique {
+		result.Data = append(result.Data, v)
+	}
+	sort.Strings(result.Data)
+	return response.JSON(http.StatusOK, result)
+}
+
+func execQuery(ctx context.Context, q promql.Query) response.Response {
+	defer q.Close()
+	res := q.Exec(ctx)
+	if res.Err != nil {
+		var timeout promql.ErrQueryTimeout
+		var canceled promql.ErrQueryCanceled
+		var storageErr promql.ErrStorage
+		switch {
+		case errors.As(res.Err, &timeout):
+			return queryErrorResponse(http.StatusServiceUnavailable, apiv1.ErrTimeout, res.Err)
+		case errors.As(res.Err, &canceled):
+			return queryErrorResponse(http.StatusServiceUnavailable, apiv1.ErrCanceled, res.Err)
+		case errors.As(res.Err, &storageErr):
+			return queryErrorResponse(http.StatusInternalServerError, apiv1.ErrServer, res.Err)
+		default:
+			return queryErrorResponse(http.StatusUnprocessableEntity, apiv1.ErrExec, res.Err)
+		}
+	}
+
+	// Empty results are returned as empty lists, like Prometheus does.
+	value := res.Value
+	switch v := value.(type) {
+	case promql.Vector:
+		if v == nil {
+			value = promql.Vector{}
+		}
+	case promql.Matrix:
+		if v == nil {
+			value = promql.Matrix{}
+		}
+	}
+	return response.JSON(http.StatusOK, apimodels.PrometheusQueryResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{Status: "success"},
+		Data: &apimodels.PrometheusQueryData{
+			ResultType: string(res.Value.Type()),
+			Result:     value,
+		},
+		Warnings: res.Warnings.AsStrings(q.Statement().String(), 0),
+	})
+}
+
+func queryErrorResponse(status int, errorType apiv1.ErrorType, err error) response.Response {
+	return response.JSON(status, apimodels.DiscoveryBase{
+		Status:    "error",
+		ErrorType: errorType,
+		Error:     err.Error(),
+	})
+}
+
+// parseSeriesParams returns the time range in milliseconds and the selectors of the label and series queries.
+func parseSeriesParams(c *contextmodel.ReqContext) (int64, int64, [][]*labels.Matcher, error) {
+	if err := c.Req.ParseForm(); err != nil {
+		return 0, 0, nil, err
+	}
+	start, end := localQueryMinTime, localQueryMaxTime
+	if v := c.Req.Form.Get("start"); v != "" {
+		t, err := parsePrometheusTime(v)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid parameter 'start': %w", err)
+		}
+		start = t.UnixMilli()
+	}
+	if v := c.Req.Form.Get("end"); v != "" {
+		t, err := parsePrometheusTime(v)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid parameter 'end': %w", err)
+		}
+		end = t.UnixMilli()
+	}
+	var matcherSets [][]*labels.Matcher
+	for _, s := range c.Req.Form["match[]"] {
+		matchers, err := parser.ParseMetricSelector(s)
+		if err != nil {
+			return 0, 0, nil, fmt.Errorf("invalid parameter 'match[]': %w", err)
+		}
+		matcherSets = append(matcherSets, matchers)
+	}
+	return start, end, matcherSets, nil
+}
+
+// parsePrometheusTime parses an RFC 3339 timestamp or a Unix timestamp in seconds, like Prometheus does.
+func parsePrometheusTime(s string) (time.Time, error) {
+	if t, err := strconv.ParseFloat(s, 64); err == nil {
+		sec, frac := math.Modf(t)
+		frac = math.Round(frac*1000) / 1000
+		return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+}
+
+// parsePrometheusDuration parses a duration or a number of seconds, like Prometheus does.
+func parsePrometheusDuration(s string) (time.Duration, error) {
+	if d, err := strconv.ParseFloat(s, 64); err == nil {
+		ts := d * float64(time.Second)
+		if ts > float64(math.MaxInt64) || ts < float64(math.MinInt64) {
+			return 0, fmt.Errorf("cannot parse %q to a valid duration. It overflows int64", s)
+		}
+		return time.Duration(ts), nil
+	}
+	if d, err := model.ParseDuration(s); err == nil {
+		return time.Duration(d), nil
+	}
+	return 0, fmt.Errorf("cannot parse %q to a valid duration", s)
+}
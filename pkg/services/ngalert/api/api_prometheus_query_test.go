@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/util/teststorage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+type fakeLocalTSDB struct {
+	queryable storage.Queryable
+	orgID     int64
+}
+
+func (f *fakeLocalTSDB) LocalQueryable(orgID int64) (storage.Queryable, bool) {
+	f.orgID = orgID
+	return f.queryable, f.queryable != nil
+}
+
+func TestRouteQueryLocalTSDB(t *testing.T) {
+	orgID := int64(2)
+	db := teststorage.New(t)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+	now := time.Now().Truncate(time.Second)
+	app := db.Appender(context.Background())
+	for i := 0; i < 3; i++ {
+		ts := now.Add(time.Duration(i-2) * time.Minute).UnixMilli()
+		_, err := app.Append(0, labels.FromStrings(labels.MetricName, "test_metric", "instance", "a"), ts, float64(i))
+		require.NoError(t, err)
+		_, err = app.Append(0, labels.FromStrings(labels.MetricName, "other_metric", "job", "b"), ts, float64(10*i))
+		require.NoError(t, err)
+	}
+	require.NoError(t, app.Commit())
+
+	tsdb := &fakeLocalTSDB{queryable: db}
+	srv := PrometheusSrv{log: log.NewNopLogger(), localTSDB: tsdb, queryEngine: newLocalQueryEngine()}
+	request := func(method, path string, values url.Values) *http.Request {
+		if method == http.MethodPost {
+			req := httptest.NewRequest(method, path, strings.NewReader(values.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			return req
+		}
+		return httptest.NewRequest(method, path+"?"+values.Encode(), nil)
+	}
+
+	t.Run("instant query", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodPost} {
+			c := createRequestContext(orgID, nil)
+			c.Req = request(method, "/api/prometheus/grafana/api/v1/query", url.Values{"query": {"test_metric"}, "time": {now.Format(time.RFC3339)}})
+
+			resp := srv.RouteQuery(c)
+			require.Equal(t, http.StatusOK, resp.Status(), string(resp.Body()))
+			assert.Equal(t, orgID, tsdb.orgID)
+			assert.JSONEq(t, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"test_metric","instance":"a"},"value":[`+
+				jsonTimestamp(now)+`,"2"]}]}}`, string(resp.Body()))
+		}
+	})
+
+	t.Run("range query", func(t *testing.T) {
+		c := createRequestContext(orgID, nil)
+		c.Req = request(http.MethodPost, "/api/prometheus/grafana/api/v1/query_range", url.Values{
+			"query": {"sum(other_metric)"},
+			"start": {jsonTimestamp(now.Add(-time.Minute))},
+			"end":   {jsonTimestamp(now)},
+			"step":  {"1m"},
+		})
+
+		resp := srv.RouteQueryRange(c)
+		require.Equal(t, http.StatusOK, resp.Status(), string(resp.Body()))
+		assert.JSONEq(t, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[`+
+			jsonTimestamp(now.Add(-time.Minute))+`,"10"],[`+jsonTimestamp(now)+`,"20"]]}]}}`, string(resp.Body()))
+	})
+
+	t.Run("labels, label values and series", func(t *testing.T) {
+		c := createRequestContext(orgID, nil)
+		c.Req = request(http.MethodGet, "/api/prometheus/grafana/api/v1/labels", url.Values{})
+		resp := srv.RouteGetLabels(c)
+		require.Equal(t, http.StatusOK, resp.Status(), string(resp.Body()))
+		var labelsResult apimodels.PrometheusLabelsResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &labelsResult))
+		assert.Equal(t, []string{labels.MetricName, "instance", "job"}, labelsResult.Data)
+
+		c = createRequestContext(orgID, nil)
+		c.Req = request(http.MethodGet, "/api/prometheus/grafana/api/v1/label/__name__/values", url.Values{"match[]": {`{job="b"}`}})
+		resp = srv.RouteGetLabelValues(c, labels.MetricName)
+		require.Equal(t, http.StatusOK, resp.Status(), string(resp.Body()))
+		require.NoError(t, json.Unmarshal(resp.Body(), &labelsResult))
+		assert.Equal(t, []string{"other_metric"}, labelsResult.Data)
+
+		c = createRequestContext(orgID, nil)
+		c.Req = request(http.MethodGet, "/api/prometheus/grafana/api/v1/series", url.Values{"match[]": {"test_metric", `{instance="a"}`}})
+		resp = srv.RouteGetSeries(c)
+		require.Equal(t, http.StatusOK, resp.Status(), string(resp.Body()))
+		var seriesResult apimodels.PrometheusSeriesResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &seriesResult))
+		assert.Equal(t, []map[string]string{{labels.MetricName: "test_metric", "instance": "a"}}, seriesResult.Data)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		c := createRequestContext(orgID, nil)
+		c.Req = request(http.MethodGet, "/api/prometheus/grafana/api/v1/query", url.Values{"query": {"sum("}})
+		resp := srv.RouteQuery(c)
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+		assert.Contains(t, string(resp.Body()), `"errorType":"bad_data"`)
+
+		c = createRequestContext(orgID, nil)
+		c.Req = request(http.MethodGet, "/api/prometheus/grafana/api/v1/query_range", url.Values{
+			"query": {"test_metric"}, "start": {"10"}, "end": {"5"}, "step": {"1"},
+		})
+		resp = srv.RouteQueryRange(c)
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+
+		c = createRequestContext(orgID, nil)
+		c.Req = request(http.MethodGet, "/api/prometheus/grafana/api/v1/series", url.Values{})
+		resp = srv.RouteGetSeries(c)
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("not found if the local time series database is disabled", func(t *testing.T) {
+		srv := PrometheusSrv{log: log.NewNopLogger(), localTSDB: &fakeLocalTSDB{}, queryEngine: newLocalQueryEngine()}
+		c := createRequestContext(orgID, nil)
+		c.Req = request(http.MethodGet, "/api/prometheus/grafana/api/v1/query", url.Values{"query": {"test_metric"}})
+		resp := srv.RouteQuery(c)
+		require.Equal(t, http.StatusNotFound, resp.Status())
+		assert.Contains(t, string(resp.Body()), errLocalTSDBDisabled.Error())
+	})
+}
+
+// jsonTimestamp returns the timestamp in seconds, as in the responses of the Prometheus HTTP API.
+func jsonTimestamp(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
@@ -528,6 +528,39 @@ func TestRouteGetRuleStatuses(t *testing.T) {
 		})
 	})
 
+	t.Run("should report rules delayed by evaluation budgets", func(t *testing.T) {
+		ruleStore := fakes.NewRuleStore(t)
+		fakeAIM := NewFakeAlertInstanceManager(t)
+		rules := gen.With(gen.WithGroupKey(ngmodels.GenerateGroupKey(orgID))).GenerateManyRef(2)
+		ruleStore.PutRule(context.Background(), rules...)
+
+		api := PrometheusSrv{
+			log:     log.NewNopLogger(),
+			manager: fakeAIM,
+			delays:  fakeEvaluationDelays{rules[0].GetKey(): 35 * time.Second},
+			store:   ruleStore,
+			authz:   &fakeRuleAccessControlService{},
+		}
+
+		response := api.RouteGetRuleStatuses(c)
+		require.Equal(t, http.StatusOK, response.Status())
+		result := &apimodels.RuleResponse{}
+		require.NoError(t, json.Unmarshal(response.Body(), result))
+
+		require.Len(t, result.Data.RuleGroups, 1)
+		require.Len(t, result.Data.RuleGroups[0].Rules, 2)
+		for _, rule := range result.Data.RuleGroups[0].Rules {
+			if rule.Name == rules[0].Title {
+				require.Equal(t, "delayed", rule.Health)
+				require.Contains(t, rule.LastError, "35s")
+			} else {
+				require.Equal(t, "ok", rule.Health)
+				require.Empty(t, rule.LastError)
+			}
+		}
+		require.Equal(t, int64(1), result.Data.Totals["delayed"])
+	})
+
 	t.Run("test folder, group and rule name query params", func(t *testing.T) {
 		ruleStore := fakes.NewRuleStore(t)
 		fakeAIM := NewFakeAlertInstanceManager(t)
@@ -1501,3 +1534,10 @@ func withExpressionsMultiQuery() ngmodels.AlertRuleMutator {
 		r.Data = queries
 	}
 }
+
+type fakeEvaluationDelays map[ngmodels.AlertRuleKey]time.Duration
+
+func (f fakeEvaluationDelays) EvaluationDelay(key ngmodels.AlertRuleKey) (time.Duration, bool) {
+	d, ok := f[key]
+	return d, ok
+}
@@ -30,6 +30,7 @@ type ProvisioningSrv struct {
 	templates           TemplateService
 	muteTimings         MuteTimingService
 	alertRules          AlertRuleService
+	configuration       AlertingConfigurationService
 	folderSvc           folder.Service
 
 	// XXX: Used to flag recording rules, remove when FT is removed
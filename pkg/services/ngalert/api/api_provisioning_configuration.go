@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	alerting_models "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type AlertingConfigurationService interface {
+	GetConfiguration(ctx context.Context, user identity.Requester) (provisioning.AlertingConfiguration, string, error)
+	PlanConfiguration(ctx context.Context, user identity.Requester, desired provisioning.AlertingConfiguration, provenance alerting_models.Provenance) (provisioning.ConfigurationPlan, error)
+	ApplyConfiguration(ctx context.Context, user identity.Requester, desired provisioning.AlertingConfiguration, provenance alerting_models.Provenance, version string) (provisioning.ConfigurationPlan, error)
+}
+
+func (srv *ProvisioningSrv) RouteGetAlertingConfiguration(c *contextmodel.ReqContext) response.Response {
+	cfg, version, err := srv.configuration.GetConfiguration(c.Req.Context(), c.SignedInUser)
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+	}
+	result := ApiAlertingConfigurationFromAlertingConfiguration(cfg)
+	result.Version = version
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertingConfigurationPlan(c *contextmodel.ReqContext, body definitions.AlertingConfiguration) response.Response {
+	cfg, err := AlertingConfigurationFromApiAlertingConfiguration(body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	provenance := determineProvenance(c)
+	plan, err := srv.configuration.PlanConfiguration(c.Req.Context(), c.SignedInUser, cfg, alerting_models.Provenance(provenance))
+	if err != nil {
+		return alertingConfigurationErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, ApiAlertingConfigurationPlanFromConfigurationPlan(plan))
+}
+
+func (srv *ProvisioningSrv) RoutePostAlertingConfigurationApply(c *contextmodel.ReqContext, body definitions.AlertingConfiguration) response.Response {
+	cfg, err := AlertingConfigurationFromApiAlertingConfiguration(body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	provenance := determineProvenance(c)
+	plan, err := srv.configuration.ApplyConfiguration(c.Req.Context(), c.SignedInUser, cfg, alerting_models.Provenance(provenance), body.Version)
+	if err != nil {
+		return alertingConfigurationErrorResponse(err)
+	}
+	return response.JSON(http.StatusOK, ApiAlertingConfigurationPlanFromConfigurationPlan(plan))
+}
+
+func alertingConfigurationErrorResponse(err error) response.Response {
+	if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if errors.Is(err, provisioning.ErrValidation) ||
+		errors.Is(err, alerting_models.ErrAlertRuleFailedValidation) ||
+		errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, "", err)
+}
+
+// AlertingConfigurationFromApiAlertingConfiguration converts the API model of the alerting configuration. The kinds of
+// resources that are not set in the request stay nil, so that they are not managed.
+func AlertingConfigurationFromApiAlertingConfiguration(a definitions.AlertingConfiguration) (provisioning.AlertingConfiguration, error) {
+	result := provisioning.AlertingConfiguration{
+		ContactPoints: a.ContactPoints,
+		Policies:      a.Policies,
+		MuteTimings:   a.MuteTimings,
+		Templates:     a.Templates,
+	}
+	if a.RuleGroups != nil {
+		result.RuleGroups = make([]alerting_models.AlertRuleGroup, 0, len(a.RuleGroups))
+		for _, ag := range a.RuleGroups {
+			group, err := AlertRuleGroupFromApiAlertRuleGroup(ag)
+			if err != nil {
+				return provisioning.AlertingConfiguration{}, err
+			}
+			result.RuleGroups = append(result.RuleGroups, group)
+		}
+	}
+	return result, nil
+}
+
+func ApiAlertingConfigurationFromAlertingConfiguration(cfg provisioning.AlertingConfiguration) definitions.AlertingConfiguration {
+	result := definitions.AlertingConfiguration{
+		RuleGroups:    make([]definitions.AlertRuleGroup, 0, len(cfg.RuleGroups)),
+		ContactPoints: cfg.ContactPoints,
+		Policies:      cfg.Policies,
+		MuteTimings:   cfg.MuteTimings,
+		Templates:     cfg.Templates,
+	}
+	for _, group := range cfg.RuleGroups {
+		result.RuleGroups = append(result.RuleGroups, ApiAlertRuleGroupFromAlertRuleGroup(group))
+	}
+	return result
+}
+
+func ApiAlertingConfigurationPlanFromConfigurationPlan(plan provisioning.ConfigurationPlan) definitions.AlertingConfigurationPlan {
+	result := definitions.AlertingConfigurationPlan{
+		Version: plan.Version,
+		Changes: make([]definitions.AlertingConfigurationChange, 0, len(plan.Changes)),
+	}
+	for _, change := range plan.Changes {
+		c := definitions.AlertingConfigurationChange{
+			Kind:      string(change.Kind),
+			FolderUID: change.FolderUID,
+			UID:       change.UID,
+			Name:      change.Name,
+			Action:    string(change.Action),
+		}
+		for _, d := range change.Diff {
+			c.Diff = append(c.Diff, definitions.AlertingConfigurationDiff{
+				Path: d.Path,
+				From: ruleVersionChangeValue(d.Left),
+				To:   ruleVersionChangeValue(d.Right),
+			})
+		}
+		result.Changes = append(result.Changes, c)
+	}
+	return result
+}
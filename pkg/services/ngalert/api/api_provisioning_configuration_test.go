@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/util/cmputil"
+)
+
+func TestProvisioningApiAlertingConfiguration(t *testing.T) {
+	plan := provisioning.ConfigurationPlan{
+		Version: "version",
+		Changes: []provisioning.ConfigurationChange{
+			{
+				Kind:   provisioning.ConfigurationResourceContactPoint,
+				UID:    "cp",
+				Name:   "email",
+				Action: provisioning.ConfigurationActionUpdate,
+				Diff: cmputil.DiffReport{{
+					Path:  "settings.addresses",
+					Left:  reflect.ValueOf("old@example.com"),
+					Right: reflect.ValueOf("new@example.com"),
+				}},
+			},
+		},
+	}
+
+	t.Run("get returns the configuration and its version", func(t *testing.T) {
+		svc := &fakeAlertingConfigurationService{
+			cfg: provisioning.AlertingConfiguration{
+				RuleGroups: []models.AlertRuleGroup{{Title: "group", FolderUID: "folder", Interval: 60}},
+				Templates:  []definitions.NotificationTemplate{{Name: "template", Template: "{{ define \"template\" }}{{ end }}"}},
+			},
+			version: "version",
+		}
+		sut := ProvisioningSrv{configuration: svc}
+		rc := createTestRequestCtx()
+
+		resp := sut.RouteGetAlertingConfiguration(&rc)
+
+		require.Equal(t, http.StatusOK, resp.Status())
+		var result definitions.AlertingConfiguration
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		assert.Equal(t, "version", result.Version)
+		require.Len(t, result.RuleGroups, 1)
+		assert.Equal(t, "folder", result.RuleGroups[0].FolderUID)
+		assert.Len(t, result.Templates, 1)
+	})
+
+	t.Run("plan does not manage the kinds that are not in the request", func(t *testing.T) {
+		svc := &fakeAlertingConfigurationService{plan: plan}
+		sut := ProvisioningSrv{configuration: svc}
+		rc := createTestRequestCtx()
+
+		resp := sut.RoutePostAlertingConfigurationPlan(&rc, definitions.AlertingConfiguration{
+			ContactPoints: []definitions.EmbeddedContactPoint{},
+		})
+
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.NotNil(t, svc.desired.ContactPoints)
+		assert.Nil(t, svc.desired.RuleGroups)
+		assert.Nil(t, svc.desired.Policies)
+		assert.Nil(t, svc.desired.MuteTimings)
+		assert.Nil(t, svc.desired.Templates)
+		assert.Equal(t, models.ProvenanceAPI, svc.provenance)
+
+		var result definitions.AlertingConfigurationPlan
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		assert.Equal(t, "version", result.Version)
+		require.Len(t, result.Changes, 1)
+		assert.Equal(t, definitions.AlertingConfigurationChange{
+			Kind:   "contact_point",
+			UID:    "cp",
+			Name:   "email",
+			Action: "update",
+			Diff: []definitions.AlertingConfigurationDiff{
+				{Path: "settings.addresses", From: "old@example.com", To: "new@example.com"},
+			},
+		}, result.Changes[0])
+	})
+
+	t.Run("apply passes the version and the provenance", func(t *testing.T) {
+		svc := &fakeAlertingConfigurationService{plan: plan}
+		sut := ProvisioningSrv{configuration: svc}
+		rc := createTestRequestCtx()
+		rc.Req.Header.Add(disableProvenanceHeaderName, "true")
+
+		resp := sut.RoutePostAlertingConfigurationApply(&rc, definitions.AlertingConfiguration{Version: "version"})
+
+		require.Equal(t, http.StatusOK, resp.Status())
+		assert.Equal(t, "version", svc.version)
+		assert.Equal(t, models.ProvenanceNone, svc.provenance)
+	})
+
+	t.Run("errors are mapped to status codes", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			err    error
+			status int
+		}{
+			{name: "validation", err: provisioning.ErrValidation, status: http.StatusBadRequest},
+			{name: "rule validation", err: models.ErrAlertRuleFailedValidation, status: http.StatusBadRequest},
+			{name: "version conflict", err: provisioning.ErrVersionConflict.Errorf("version conflict"), status: http.StatusConflict},
+			{name: "provenance", err: provisioning.MakeErrProvenanceChangeNotAllowed(models.ProvenanceFile, models.ProvenanceAPI), status: http.StatusForbidden},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				sut := ProvisioningSrv{configuration: &fakeAlertingConfigurationService{err: tc.err}}
+				rc := createTestRequestCtx()
+
+				resp := sut.RoutePostAlertingConfigurationApply(&rc, definitions.AlertingConfiguration{})
+
+				require.Equal(t, tc.status, resp.Status())
+			})
+		}
+	})
+}
+
+type fakeAlertingConfigurationService struct {
+	cfg     provisioning.AlertingConfiguration
+	version string
+	plan    provisioning.ConfigurationPlan
+	err     error
+
+	desired    provisioning.AlertingConfiguration
+	provenance models.Provenance
+}
+
+func (f *fakeAlertingConfigurationService) GetConfiguration(_ context.Context, _ identity.Requester) (provisioning.AlertingConfiguration, string, error) {
+	return f.cfg, f.version, f.err
+}
+
+func (f *fakeAlertingConfigurationService) PlanConfiguration(_ context.Context, _ identity.Requester, desired provisioning.AlertingConfiguration, provenance models.Provenance) (provisioning.ConfigurationPlan, error) {
+	f.desired = desired
+	f.provenance = provenance
+	return f.plan, f.err
+}
+
+func (f *fakeAlertingConfigurationService) ApplyConfiguration(_ context.Context, _ identity.Requester, desired provisioning.AlertingConfiguration, provenance models.Provenance, version string) (provisioning.ConfigurationPlan, error) {
+	f.desired = desired
+	f.provenance = provenance
+	f.version = version
+	return f.plan, f.err
+}
@@ -36,6 +36,11 @@ type ConditionValidator interface {
 	Validate(ctx eval.EvaluationContext, condition ngmodels.Condition) error
 }
 
+// RecordTargetValidator validates that the results of recording rules can be written to their target by the user.
+type RecordTargetValidator interface {
+	ValidateTarget(ctx context.Context, user identity.Requester, orgID int64, target *ngmodels.RecordTarget) error
+}
+
 type AMConfigStore interface {
 	GetLatestAlertmanagerConfiguration(ctx context.Context, orgID int64) (*ngmodels.AlertConfiguration, error)
 }
@@ -57,6 +62,7 @@ type RulerSrv struct {
 	amConfigStore  AMConfigStore
 	amRefresher    AMRefresher
 	featureManager featuremgmt.FeatureToggles
+	recordTargets  RecordTargetValidator
 }
 
 var (
@@ -310,7 +316,12 @@ func (srv RulerSrv) RouteGetRuleByUID(c *contextmodel.ReqContext, ruleUID string
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule provenance", err)
 	}
 
-	result := toGettableExtendedRuleNode(rule, map[string]ngmodels.Provenance{rule.ResourceID(): provenance})
+	group, err := srv.store.GetAlertRulesGroupByRuleUID(ctx, &ngmodels.GetAlertRulesGroupByRuleUIDQuery{UID: rule.UID, OrgID: orgID})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule group", err)
+	}
+
+	result := toGettableExtendedRuleNode(rule, ngmodels.NewGroupDependencies(group), map[string]ngmodels.Provenance{rule.ResourceID(): provenance})
 
 	return response.JSON(http.StatusOK, result)
 }
@@ -353,16 +364,45 @@ func (srv RulerSrv) checkGroupLimits(group apimodels.PostableRuleGroupConfig) er
 
 // updateAlertRulesInGroup calculates changes (rules to add,update,delete), verifies that the user is authorized to do the calculated changes and updates database.
 // All operations are performed in a single transaction
+func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
+	return srv.applyGroupChanges(c, groupKey, func(ctx context.Context) (*store.GroupDelta, error) {
+		return store.CalculateChanges(ctx, srv.store, groupKey, rules)
+	})
+}
+
+// applyGroupChanges verifies that the user is authorized to do the changes calculated by the function and updates database.
+// All operations are performed in a single transaction
+func (srv RulerSrv) applyGroupChanges(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, calculateChanges func(context.Context) (*store.GroupDelta, error)) response.Response {
+	finalChanges, dbConfig, err := srv.saveGroupChanges(c.Req.Context(), c, groupKey, calculateChanges)
+	if err != nil {
+		return groupChangesErrorResponse(err)
+	}
+
+	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
+		// This isn't strictly necessary since the alertmanager config is periodically synced.
+		err := srv.amRefresher.ApplyConfig(c.Req.Context(), groupKey.OrgID, dbConfig)
+		if err != nil {
+			srv.log.Warn("Failed to refresh Alertmanager config for org after change in notification settings", "org", c.SignedInUser.GetOrgID(), "error", err)
+		}
+	}
+
+	return changesToResponse(finalChanges)
+}
+
+// saveGroupChanges verifies that the user is authorized to do the changes calculated by the function and updates
+// database in a transaction. If ctx already carries a transaction, the changes are made in it.
+// It returns the changes that were made, and the Alertmanager configuration if the notification settings of the rules
+// changed.
 //
 //nolint:gocyclo
-func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
+func (srv RulerSrv) saveGroupChanges(ctx context.Context, c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, calculateChanges func(context.Context) (*store.GroupDelta, error)) (*store.GroupDelta, *ngmodels.AlertConfiguration, error) {
 	var finalChanges *store.GroupDelta
 	var dbConfig *ngmodels.AlertConfiguration
-	err := srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
+	err := srv.xactManager.InTransaction(ctx, func(tranCtx context.Context) error {
 		userNamespace, id := c.SignedInUser.GetNamespacedID()
 		logger := srv.log.New("namespace_uid", groupKey.NamespaceUID, "group",
 			groupKey.RuleGroup, "org_id", groupKey.OrgID, "user_id", id, "userNamespace", userNamespace)
-		groupChanges, err := store.CalculateChanges(tranCtx, srv.store, groupKey, rules)
+		groupChanges, err := calculateChanges(tranCtx)
 		if err != nil {
 			return err
 		}
@@ -382,6 +422,12 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			return err
 		}
 
+		if srv.recordTargets != nil {
+			if err := validateRecordTargets(c.Req.Context(), groupChanges, srv.recordTargets, c.SignedInUser); err != nil {
+				return err
+			}
+		}
+
 		newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
 		if len(newOrUpdatedNotificationSettings) > 0 {
 			dbConfig, err = srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), groupChanges.GroupKey.OrgID)
@@ -405,6 +451,7 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 		}
 
 		finalChanges = store.UpdateCalculatedRuleFields(groupChanges)
+		updatedBy := ngmodels.NewUserUID(c.SignedInUser)
 		logger.Debug("Updating database with the authorized changes", "add", len(finalChanges.New), "update", len(finalChanges.New), "delete", len(finalChanges.Delete))
 
 		// Delete first as this could prevent future unique constraint violations.
@@ -423,6 +470,7 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 			updates := make([]ngmodels.UpdateRule, 0, len(finalChanges.Update))
 			for _, update := range finalChanges.Update {
 				logger.Debug("Updating rule", "rule_uid", update.New.UID, "diff", update.Diff.String())
+				update.New.UpdatedBy = updatedBy
 				updates = append(updates, ngmodels.UpdateRule{
 					Existing: update.Existing,
 					New:      *update.New,
@@ -437,6 +485,7 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 		if len(finalChanges.New) > 0 {
 			inserts := make([]ngmodels.AlertRule, 0, len(finalChanges.New))
 			for _, rule := range finalChanges.New {
+				rule.UpdatedBy = updatedBy
 				inserts = append(inserts, *rule)
 			}
 			added, err := srv.store.InsertAlertRules(tranCtx, inserts)
@@ -468,31 +517,23 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 		}
 		return nil
 	})
+	return finalChanges, dbConfig, err
+}
 
-	if err != nil {
-		if errors.As(err, &errutil.Error{}) {
-			return response.Err(err)
-		} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
-			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrQuotaReached) {
-			return ErrResp(http.StatusForbidden, err, "")
-		} else if errors.Is(err, store.ErrOptimisticLock) {
-			return ErrResp(http.StatusConflict, err, "")
-		}
-		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
-	}
-
-	if srv.featureManager.IsEnabled(c.Req.Context(), featuremgmt.FlagAlertingSimplifiedRouting) && dbConfig != nil {
-		// This isn't strictly necessary since the alertmanager config is periodically synced.
-		err := srv.amRefresher.ApplyConfig(c.Req.Context(), groupKey.OrgID, dbConfig)
-		if err != nil {
-			srv.log.Warn("Failed to refresh Alertmanager config for org after change in notification settings", "org", c.SignedInUser.GetOrgID(), "error", err)
-		}
-	}
-
-	return changesToResponse(finalChanges)
+// groupChangesErrorResponse returns the response to an error returned by saveGroupChanges.
+func groupChangesErrorResponse(err error) response.Response {
+	if errors.As(err, &errutil.Error{}) {
+		return response.Err(err)
+	} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, errProvisionedResource) {
+		return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrQuotaReached) {
+		return ErrResp(http.StatusForbidden, err, "")
+	} else if errors.Is(err, store.ErrOptimisticLock) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 }
 
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
@@ -525,8 +566,9 @@ func toGettableRuleGroupConfig(groupName string, rules ngmodels.RulesGroup, prov
 	if len(rules) > 0 {
 		interval = time.Duration(rules[0].IntervalSeconds) * time.Second
 	}
+	deps := ngmodels.NewGroupDependencies(rules)
 	for _, r := range rules {
-		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, provenanceRecords))
+		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, deps, provenanceRecords))
 	}
 	return apimodels.GettableRuleGroupConfig{
 		Name:     groupName,
@@ -535,7 +577,7 @@ func toGettableRuleGroupConfig(groupName string, rules ngmodels.RulesGroup, prov
 	}
 }
 
-func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[string]ngmodels.Provenance) apimodels.GettableExtendedRuleNode {
+func toGettableExtendedRuleNode(r ngmodels.AlertRule, deps *ngmodels.GroupDependencies, provenanceRecords map[string]ngmodels.Provenance) apimodels.GettableExtendedRuleNode {
 	provenance := ngmodels.ProvenanceNone
 	if prov, exists := provenanceRecords[r.ResourceID()]; exists {
 		provenance = prov
@@ -560,6 +602,8 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 			IsPaused:             r.IsPaused,
 			NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(r.NotificationSettings),
 			Record:               ApiRecordFromModelRecord(r.Record),
+			DependsOn:            r.DependsOn,
+			Dependencies:         ApiRuleDependenciesFromModelRuleDependencies(deps.Of(&r)),
 		},
 	}
 	forDuration := model.Duration(r.For)
@@ -627,6 +671,34 @@ func validateQueries(ctx context.Context, groupChanges *store.GroupDelta, valida
 	return nil
 }
 
+// validateRecordTargets checks that the results of the new and updated recording rules can be written to their targets.
+// Authorization errors are returned as is.
+func validateRecordTargets(ctx context.Context, groupChanges *store.GroupDelta, validator RecordTargetValidator, user identity.Requester) error {
+	for _, rule := range groupChanges.New {
+		if rule.Record == nil {
+			continue
+		}
+		if err := validator.ValidateTarget(ctx, user, rule.OrgID, rule.Record.Target); err != nil {
+			if errors.Is(err, authz.ErrAuthorizationBase) {
+				return err
+			}
+			return fmt.Errorf("%w '%s': invalid recording rule target: %s", ngmodels.ErrAlertRuleFailedValidation, rule.Title, err.Error())
+		}
+	}
+	for _, upd := range groupChanges.Update {
+		if upd.New.Record == nil || !shouldValidate(upd) {
+			continue
+		}
+		if err := validator.ValidateTarget(ctx, user, upd.New.OrgID, upd.New.Record.Target); err != nil {
+			if errors.Is(err, authz.ErrAuthorizationBase) {
+				return err
+			}
+			return fmt.Errorf("%w '%s' (UID: %s): invalid recording rule target: %s", ngmodels.ErrAlertRuleFailedValidation, upd.New.Title, upd.New.UID, err.Error())
+		}
+	}
+	return nil
+}
+
 // shouldValidate returns true if the rule is not paused and there are changes in the rule that are not ignored
 func shouldValidate(delta store.RuleDelta) bool {
 	for _, diff := range delta.Diff {
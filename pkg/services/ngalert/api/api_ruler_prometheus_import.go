@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/prom"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// prometheusDefaultEvaluationInterval is the evaluation interval of the imported Prometheus rule groups that do not set
+// one. It is the default global evaluation interval of Prometheus.
+const prometheusDefaultEvaluationInterval = time.Minute
+
+// RouteImportPrometheusRules converts the Prometheus rule groups in the request to Grafana-managed rule groups and
+// saves them in the folder, unless it is a dry run. The rules that cannot be converted are skipped, and the groups that
+// already exist in the folder are not imported. All groups are saved in a single transaction.
+func (srv RulerSrv) RouteImportPrometheusRules(c *contextmodel.ReqContext, body apimodels.PrometheusRulesImport, namespaceUID string) response.Response {
+	if body.DatasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("datasourceUid must be set"), "")
+	}
+	if len(body.Groups) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("no rule group to import"), "")
+	}
+	namespace, err := srv.store.GetNamespaceByUID(c.Req.Context(), namespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	existing, err := srv.store.ListAlertRules(c.Req.Context(), &ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.GetOrgID(),
+		NamespaceUIDs: []string{namespace.UID},
+	})
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the rules of the folder")
+	}
+	titles := make([]string, 0, len(existing))
+	groupNames := make(map[string]struct{})
+	for _, rule := range existing {
+		titles = append(titles, rule.Title)
+		groupNames[rule.RuleGroup] = struct{}{}
+	}
+
+	limits := RuleLimitsFromConfig(srv.cfg, srv.featureManager)
+	converter := prom.NewConverter(prom.Config{
+		DatasourceUID:   body.DatasourceUID,
+		DefaultInterval: prometheusDefaultEvaluationInterval,
+		BaseInterval:    limits.BaseInterval,
+		RecordingRules:  limits.RecordingRulesAllowed,
+	}, titles)
+
+	type importedGroup struct {
+		result int
+		key    ngmodels.AlertRuleGroupKey
+		rules  []*ngmodels.AlertRuleWithOptionals
+	}
+	result := apimodels.PrometheusRulesImportResult{
+		DryRun: body.DryRun,
+		Groups: make([]apimodels.PrometheusRuleGroupImportResult, 0, len(body.Groups)),
+	}
+	var imported []importedGroup
+	for _, group := range body.Groups {
+		converted, groupResult := converter.ConvertRuleGroup(group)
+		result.Groups = append(result.Groups, groupResult)
+		if groupResult.Error != "" {
+			continue
+		}
+		r := &result.Groups[len(result.Groups)-1]
+		if _, ok := groupNames[group.Name]; ok {
+			r.Error = "a rule group with the same name already exists in the folder"
+			continue
+		}
+		groupNames[group.Name] = struct{}{}
+
+		rules, err := ValidateRuleGroup(&converted, c.SignedInUser.GetOrgID(), namespace.UID, limits)
+		if err != nil {
+			r.Error = err.Error()
+			continue
+		}
+		imported = append(imported, importedGroup{
+			result: len(result.Groups) - 1,
+			key: ngmodels.AlertRuleGroupKey{
+				OrgID:        c.SignedInUser.GetOrgID(),
+				NamespaceUID: namespace.UID,
+				RuleGroup:    converted.Name,
+			},
+			rules: rules,
+		})
+	}
+	if body.DryRun || len(imported) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	err = srv.xactManager.InTransaction(c.Req.Context(), func(ctx context.Context) error {
+		for _, group := range imported {
+			changes, _, err := srv.saveGroupChanges(ctx, c, group.key, func(ctx context.Context) (*store.GroupDelta, error) {
+				return store.CalculateChanges(ctx, srv.store, group.key, group.rules)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to import rule group '%s': %w", group.key.RuleGroup, err)
+			}
+			uids := make(map[string]string, len(changes.New))
+			for _, rule := range changes.New {
+				uids[rule.Title] = rule.UID
+			}
+			rules := result.Groups[group.result].Rules
+			for i := range rules {
+				rules[i].UID = uids[rules[i].Title]
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return groupChangesErrorResponse(err)
+	}
+	return response.JSON(http.StatusAccepted, result)
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+)
+
+func TestRouteImportPrometheusRules(t *testing.T) {
+	orgID := int64(1)
+
+	setup := func(t *testing.T) (*fakes.RuleStore, *RulerSrv, string) {
+		t.Helper()
+		folder := randFolder()
+		ruleStore := fakes.NewRuleStore(t)
+		ruleStore.Folders[orgID] = append(ruleStore.Folders[orgID], folder)
+		existing := models.RuleGen.With(
+			models.RuleGen.WithOrgID(orgID),
+			models.RuleGen.WithNamespace(folder),
+			models.RuleGen.WithGroupName("existing"),
+			models.RuleGen.WithTitle("HighLoad"),
+		).GenerateRef()
+		ruleStore.PutRule(context.Background(), existing)
+
+		srv := createService(ruleStore)
+		srv.authz = &fakeRuleAccessControlService{}
+		srv.conditionValidator = &recordingConditionValidator{}
+		srv.QuotaService = quotatest.New(false, nil)
+		return ruleStore, srv, folder.UID
+	}
+
+	body := func(dryRun bool) apimodels.PrometheusRulesImport {
+		return apimodels.PrometheusRulesImport{
+			DatasourceUID: "prometheus",
+			DryRun:        dryRun,
+			Groups: []apimodels.PrometheusRuleGroup{
+				{
+					Name: "node",
+					Rules: []apimodels.ApiRuleNode{
+						{Alert: "HighLoad", Expr: "node_load1 > 10"},
+						{Record: "instance:node_load1:avg", Expr: "avg by (instance) (node_load1)"},
+					},
+				},
+				{
+					Name:  "existing",
+					Rules: []apimodels.ApiRuleNode{{Alert: "Down", Expr: "up == 0"}},
+				},
+			},
+		}
+	}
+
+	getInserted := func(ruleStore *fakes.RuleStore) []any {
+		return ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.AlertRule)
+			return c, ok
+		})
+	}
+
+	t.Run("should save the rules that can be converted", func(t *testing.T) {
+		ruleStore, srv, folderUID := setup(t)
+
+		resp := srv.RouteImportPrometheusRules(createRequestContext(orgID, nil), body(false), folderUID)
+		require.Equal(t, http.StatusAccepted, resp.Status())
+		var result apimodels.PrometheusRulesImportResult
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		assert.False(t, result.DryRun)
+		require.Len(t, result.Groups, 2)
+
+		node := result.Groups[0]
+		assert.Empty(t, node.Error)
+		require.Len(t, node.Rules, 2)
+		assert.Equal(t, "HighLoad (2)", node.Rules[0].Title, "the title should not conflict with the existing rule")
+		assert.Empty(t, node.Rules[0].Error)
+		assert.Equal(t, "recording rules are not enabled in Grafana", node.Rules[1].Error)
+
+		assert.Equal(t, "a rule group with the same name already exists in the folder", result.Groups[1].Error)
+
+		inserted := getInserted(ruleStore)
+		require.Len(t, inserted, 1)
+		rules := inserted[0].([]models.AlertRule)
+		require.Len(t, rules, 1)
+		assert.Equal(t, "HighLoad (2)", rules[0].Title)
+		assert.Equal(t, "node", rules[0].RuleGroup)
+		assert.Equal(t, folderUID, rules[0].NamespaceUID)
+	})
+
+	t.Run("should not save anything in a dry run", func(t *testing.T) {
+		ruleStore, srv, folderUID := setup(t)
+
+		resp := srv.RouteImportPrometheusRules(createRequestContext(orgID, nil), body(true), folderUID)
+		require.Equal(t, http.StatusOK, resp.Status())
+		var result apimodels.PrometheusRulesImportResult
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		assert.True(t, result.DryRun)
+		require.Len(t, result.Groups, 2)
+		assert.Equal(t, "HighLoad (2)", result.Groups[0].Rules[0].Title)
+		assert.Empty(t, getInserted(ruleStore))
+	})
+
+	t.Run("should return BadRequest if the data source is not set", func(t *testing.T) {
+		_, srv, folderUID := setup(t)
+		b := body(false)
+		b.DatasourceUID = ""
+
+		resp := srv.RouteImportPrometheusRules(createRequestContext(orgID, nil), b, folderUID)
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("should return NotFound if the folder does not exist", func(t *testing.T) {
+		ruleStore, srv, folderUID := setup(t)
+		ruleStore.Hook = func(cmd any) error {
+			if q, ok := cmd.(fakes.GenericRecordedQuery); ok && q.Name == "GetNamespaceByUID" {
+				return dashboards.ErrFolderNotFound
+			}
+			return nil
+		}
+
+		resp := srv.RouteImportPrometheusRules(createRequestContext(orgID, nil), body(false), folderUID)
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+}
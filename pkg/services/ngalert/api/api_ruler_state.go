@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// StateTransfer exports and imports the state of alert instances.
+type StateTransfer interface {
+	ExportStates(ctx context.Context, orgID int64, ruleUIDs ...string) ([]ngmodels.AlertInstance, error)
+	ImportStates(ctx context.Context, rules map[string]*ngmodels.AlertRule, instances []ngmodels.AlertInstance) error
+}
+
+type StateSrv struct {
+	logger   log.Logger
+	store    RuleStore
+	authz    RuleAccessControlService
+	transfer StateTransfer
+}
+
+func (srv *StateSrv) RouteExportAlertInstanceStates(c *contextmodel.ReqContext) response.Response {
+	rules, err := srv.authorizedRules(c.Req.Context(), c.SignedInUser, c.QueryStrings("ruleUID"))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+
+	result := apimodels.AlertInstanceStateExport{
+		Instances: []apimodels.AlertInstanceState{},
+	}
+	if len(rules) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+	uids := make([]string, 0, len(rules))
+	for uid := range rules {
+		uids = append(uids, uid)
+	}
+	instances, err := srv.transfer.ExportStates(c.Req.Context(), c.SignedInUser.GetOrgID(), uids...)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to export alert instance states")
+	}
+	for _, instance := range instances {
+		result.Instances = append(result.Instances, state.AlertInstanceStateFromInstance(instance))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv *StateSrv) RouteImportAlertInstanceStates(c *contextmodel.ReqContext, body apimodels.AlertInstanceStateImport) response.Response {
+	orgID := c.SignedInUser.GetOrgID()
+	if len(body.Instances) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("no alert instances to import"), "")
+	}
+
+	uids := make([]string, 0, len(body.Instances))
+	instances := make([]ngmodels.AlertInstance, 0, len(body.Instances))
+	for _, s := range body.Instances {
+		uid := s.RuleUID
+		if mapped, ok := body.RuleUIDMapping[uid]; ok {
+			uid = mapped
+		}
+		uids = append(uids, uid)
+		instances = append(instances, state.InstanceFromAlertInstanceState(orgID, s))
+	}
+
+	// Instances of the rules the user cannot access are skipped as if the rules did not exist.
+	rules, err := srv.authorizedRules(c.Req.Context(), c.SignedInUser, uids)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+	// Importing states changes the rules, so the user must be allowed to update all of them.
+	if err := srv.authorizeRuleUpdates(c.Req.Context(), c.SignedInUser, rules); err != nil {
+		return errorToResponse(err)
+	}
+	namespaces, err := srv.store.GetUserVisibleNamespaces(c.Req.Context(), orgID, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get folders")
+	}
+	folderTitles := make(map[string]string, len(namespaces))
+	for uid, f := range namespaces {
+		folderTitles[uid] = f.Fullpath
+	}
+
+	imported, skipped := state.PrepareInstancesForImport(srv.logger, orgID, instances, body.RuleUIDMapping, rules, folderTitles)
+	if err := srv.transfer.ImportStates(c.Req.Context(), rules, imported); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to import alert instance states")
+	}
+
+	result := apimodels.AlertInstanceStateImportResult{
+		Imported: len(imported),
+	}
+	for _, s := range skipped {
+		result.Skipped = append(result.Skipped, apimodels.SkippedAlertInstanceState{
+			RuleUID: s.Instance.RuleUID,
+			Labels:  s.Instance.Labels,
+			Reason:  s.Reason,
+		})
+	}
+	srv.logger.Info("Imported alert instance states", "imported", result.Imported, "skipped", len(result.Skipped))
+	return response.JSON(http.StatusOK, result)
+}
+
+// authorizedRules returns the alert rules of the organization the user has access to, by UID.
+// If rule UIDs are provided, only these rules are returned.
+func (srv *StateSrv) authorizedRules(ctx context.Context, user identity.Requester, ruleUIDs []string) (map[string]*ngmodels.AlertRule, error) {
+	rules, err := srv.store.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{
+		OrgID:    user.GetOrgID(),
+		RuleUIDs: ruleUIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*ngmodels.AlertRule, len(rules))
+	for _, group := range ngmodels.GroupByAlertRuleGroupKey(rules) {
+		ok, err := srv.authz.HasAccessToRuleGroup(ctx, user, group)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		for _, rule := range group {
+			result[rule.UID] = rule
+		}
+	}
+	return result, nil
+}
+
+// authorizeRuleUpdates checks that the user can update the rules, group by group.
+func (srv *StateSrv) authorizeRuleUpdates(ctx context.Context, user identity.Requester, rules map[string]*ngmodels.AlertRule) error {
+	list := make([]*ngmodels.AlertRule, 0, len(rules))
+	for _, rule := range rules {
+		list = append(list, rule)
+	}
+	for key, group := range ngmodels.GroupByAlertRuleGroupKey(list) {
+		delta := &store.GroupDelta{
+			GroupKey:       key,
+			AffectedGroups: map[ngmodels.AlertRuleGroupKey]ngmodels.RulesGroup{key: group},
+			Update:         make([]store.RuleDelta, 0, len(group)),
+		}
+		for _, rule := range group {
+			delta.Update = append(delta.Update, store.RuleDelta{Existing: rule, New: rule})
+		}
+		if err := srv.authz.AuthorizeRuleChanges(ctx, user, delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
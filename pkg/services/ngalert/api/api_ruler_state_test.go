@@ -0,0 +1,194 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	alertingModels "github.com/grafana/alerting/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+type fakeStateTransfer struct {
+	instances []ngmodels.AlertInstance
+	imported  []ngmodels.AlertInstance
+}
+
+func (f *fakeStateTransfer) ExportStates(_ context.Context, orgID int64, ruleUIDs ...string) ([]ngmodels.AlertInstance, error) {
+	var result []ngmodels.AlertInstance
+	for _, instance := range f.instances {
+		for _, uid := range ruleUIDs {
+			if instance.RuleOrgID == orgID && instance.RuleUID == uid {
+				result = append(result, instance)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeStateTransfer) ImportStates(_ context.Context, _ map[string]*ngmodels.AlertRule, instances []ngmodels.AlertInstance) error {
+	f.imported = append(f.imported, instances...)
+	return nil
+}
+
+func TestRouteExportAlertInstanceStates(t *testing.T) {
+	orgID := int64(1)
+	gen := ngmodels.RuleGen
+	allowed := gen.With(gen.WithOrgID(orgID)).GenerateRef()
+	denied := gen.With(gen.WithOrgID(orgID)).GenerateRef()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.PutRule(context.Background(), allowed, denied)
+
+	transfer := &fakeStateTransfer{
+		instances: []ngmodels.AlertInstance{
+			{AlertInstanceKey: ngmodels.AlertInstanceKey{RuleOrgID: orgID, RuleUID: allowed.UID}, CurrentState: ngmodels.InstanceStateFiring, Labels: ngmodels.InstanceLabels{"instance": "a"}},
+			{AlertInstanceKey: ngmodels.AlertInstanceKey{RuleOrgID: orgID, RuleUID: denied.UID}, CurrentState: ngmodels.InstanceStateFiring, Labels: ngmodels.InstanceLabels{"instance": "b"}},
+		},
+	}
+	srv := &StateSrv{
+		logger:   log.NewNopLogger(),
+		store:    ruleStore,
+		authz:    accesscontrol.NewRuleService(acimpl.ProvideAccessControl(featuremgmt.WithFeatures())),
+		transfer: transfer,
+	}
+
+	t.Run("should export only the state of rules the user can access", func(t *testing.T) {
+		c := createRequestContextWithPerms(orgID, createPermissionsForRules([]*ngmodels.AlertRule{allowed}, orgID), nil)
+
+		resp := srv.RouteExportAlertInstanceStates(c)
+		require.Equal(t, http.StatusOK, resp.Status())
+		var result apimodels.AlertInstanceStateExport
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Len(t, result.Instances, 1)
+		assert.Equal(t, allowed.UID, result.Instances[0].RuleUID)
+		assert.Equal(t, "Alerting", result.Instances[0].State)
+	})
+
+	t.Run("should return empty export if the user cannot access any rule", func(t *testing.T) {
+		c := createRequestContextWithPerms(orgID, map[int64]map[string][]string{}, nil)
+
+		resp := srv.RouteExportAlertInstanceStates(c)
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.JSONEq(t, `{"instances": []}`, string(resp.Body()))
+	})
+}
+
+func TestRouteImportAlertInstanceStates(t *testing.T) {
+	orgID := int64(1)
+	gen := ngmodels.RuleGen
+	rule := gen.With(gen.WithOrgID(orgID), gen.WithNoNotificationSettings()).GenerateRef()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.PutRule(context.Background(), rule)
+
+	transfer := &fakeStateTransfer{}
+	srv := &StateSrv{
+		logger:   log.NewNopLogger(),
+		store:    ruleStore,
+		authz:    &fakeRuleAccessControlService{},
+		transfer: transfer,
+	}
+
+	t.Run("should remap rule UIDs and report skipped instances", func(t *testing.T) {
+		c := createRequestContext(orgID, nil)
+		body := apimodels.AlertInstanceStateImport{
+			Instances: []apimodels.AlertInstanceState{
+				{
+					RuleUID:    "exported-uid",
+					Labels:     map[string]string{alertingModels.RuleUIDLabel: "exported-uid", "instance": "a"},
+					State:      "Pending",
+					StateSince: time.Unix(100, 0),
+				},
+				{
+					RuleUID: "unknown-uid",
+					Labels:  map[string]string{"instance": "b"},
+					State:   "Alerting",
+				},
+			},
+			RuleUIDMapping: map[string]string{"exported-uid": rule.UID},
+		}
+
+		resp := srv.RouteImportAlertInstanceStates(c, body)
+		require.Equal(t, http.StatusOK, resp.Status())
+		var result apimodels.AlertInstanceStateImportResult
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		assert.Equal(t, 1, result.Imported)
+		require.Len(t, result.Skipped, 1)
+		assert.Equal(t, "unknown-uid", result.Skipped[0].RuleUID)
+
+		require.Len(t, transfer.imported, 1)
+		imported := transfer.imported[0]
+		assert.Equal(t, orgID, imported.RuleOrgID)
+		assert.Equal(t, rule.UID, imported.RuleUID)
+		assert.Equal(t, rule.UID, imported.Labels[alertingModels.RuleUIDLabel])
+		assert.Equal(t, ngmodels.InstanceStatePending, imported.CurrentState)
+		assert.Equal(t, int64(100), imported.CurrentStateSince.Unix())
+	})
+
+	t.Run("should set the folder label to the full path of the folder", func(t *testing.T) {
+		transfer := &fakeStateTransfer{}
+		srv := &StateSrv{
+			logger:   log.NewNopLogger(),
+			store:    ruleStore,
+			authz:    &fakeRuleAccessControlService{},
+			transfer: transfer,
+		}
+		nested := ruleStore.Folders[orgID][0]
+		nested.ParentUID = "parent-uid"
+		nested.Fullpath = "Parent/" + nested.Title
+
+		c := createRequestContext(orgID, nil)
+		body := apimodels.AlertInstanceStateImport{
+			Instances: []apimodels.AlertInstanceState{
+				{
+					RuleUID: rule.UID,
+					Labels:  map[string]string{ngmodels.FolderTitleLabel: "Old folder", "instance": "a"},
+					State:   "Alerting",
+				},
+			},
+		}
+
+		resp := srv.RouteImportAlertInstanceStates(c, body)
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.Len(t, transfer.imported, 1)
+		assert.Equal(t, "Parent/"+nested.Title, transfer.imported[0].Labels[ngmodels.FolderTitleLabel])
+	})
+
+	t.Run("should fail if the user cannot update the rules", func(t *testing.T) {
+		srv := &StateSrv{
+			logger:   log.NewNopLogger(),
+			store:    ruleStore,
+			authz:    accesscontrol.NewRuleService(acimpl.ProvideAccessControl(featuremgmt.WithFeatures())),
+			transfer: &fakeStateTransfer{},
+		}
+		// The user can read the rule but not update it.
+		c := createRequestContextWithPerms(orgID, createPermissionsForRules([]*ngmodels.AlertRule{rule}, orgID), nil)
+		body := apimodels.AlertInstanceStateImport{
+			Instances: []apimodels.AlertInstanceState{
+				{RuleUID: rule.UID, Labels: map[string]string{"instance": "a"}, State: "Alerting"},
+			},
+		}
+
+		resp := srv.RouteImportAlertInstanceStates(c, body)
+		require.Equal(t, http.StatusForbidden, resp.Status())
+		require.Empty(t, srv.transfer.(*fakeStateTransfer).imported)
+	})
+
+	t.Run("should fail if there are no instances", func(t *testing.T) {
+		c := createRequestContext(orgID, nil)
+
+		resp := srv.RouteImportAlertInstanceStates(c, apimodels.AlertInstanceStateImport{})
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -72,6 +73,7 @@ func validateRuleNode(
 		IntervalSeconds: intervalSeconds,
 		NamespaceUID:    namespaceUID,
 		RuleGroup:       groupName,
+		DependsOn:       ruleDependsOn(ruleNode.GrafanaManagedAlert.DependsOn),
 	}
 
 	if isRecordingRule {
@@ -344,3 +346,17 @@ func validateNotificationSettings(n *apimodels.AlertRuleNotificationSettings) ([
 		s,
 	}, nil
 }
+
+// ruleDependsOn returns the deduplicated list of declared dependencies, or nil if there are none.
+func ruleDependsOn(uids []string) []string {
+	if len(uids) == 0 {
+		return nil
+	}
+	result := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		if !slices.Contains(result, uid) {
+			result = append(result, uid)
+		}
+	}
+	return result
+}
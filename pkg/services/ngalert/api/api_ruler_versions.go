@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// ruleVersionFieldsToIgnoreInDiff contains the fields of the rule that are not part of its definition or that are not
+// stored in the versions of the rule.
+var ruleVersionFieldsToIgnoreInDiff = [...]string{"ID", "Version", "Updated", "UpdatedBy", "RuleGroupIndex", "DashboardUID", "PanelID"}
+
+// ruleVersionChangeFields maps the fields of the rule to the names of the changes in apimodels.RuleVersionChange.
+var ruleVersionChangeFields = map[string]string{
+	"Title":                "title",
+	"Condition":            "condition",
+	"Data":                 "queries",
+	"Labels":               "labels",
+	"Annotations":          "annotations",
+	"NotificationSettings": "notification_settings",
+	"For":                  "for",
+	"NoDataState":          "no_data_state",
+	"ExecErrState":         "exec_err_state",
+	"IsPaused":             "is_paused",
+	"Record":               "record",
+	"DependsOn":            "depends_on",
+	"IntervalSeconds":      "interval",
+	"NamespaceUID":         "folder",
+	"RuleGroup":            "group",
+}
+
+// RouteGetRuleVersions returns the versions of the alert rule with the given UID, the most recent first.
+func (srv RulerSrv) RouteGetRuleVersions(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	rule, versions, err := srv.getAuthorizedRuleVersions(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return ruleVersionsErrorResponse(err)
+	}
+
+	result := make(apimodels.GettableRuleVersions, 0, len(versions))
+	for _, v := range versions {
+		versionRule := ngmodels.AlertRuleFromVersion(v)
+		versionRule.ID = rule.ID
+		result = append(result, apimodels.GettableRuleVersion{
+			Version:       v.Version,
+			ParentVersion: v.ParentVersion,
+			Created:       v.Created,
+			CreatedBy:     userUIDToString(v.CreatedBy),
+			Rule:          toGettableExtendedRuleNode(versionRule, ngmodels.NewGroupDependencies(nil), nil),
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteGetRuleVersionsDiff returns the changes made to the alert rule with the given UID between two versions.
+// By default, it returns the changes made by the most recent version.
+func (srv RulerSrv) RouteGetRuleVersionsDiff(c *contextmodel.ReqContext, ruleUID string) response.Response {
+	_, versions, err := srv.getAuthorizedRuleVersions(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return ruleVersionsErrorResponse(err)
+	}
+
+	toVersion := versions[0]
+	if to := c.QueryInt64("to"); to != 0 {
+		toVersion = findRuleVersion(versions, to)
+		if toVersion == nil {
+			return ErrResp(http.StatusNotFound, fmt.Errorf("version %d of the rule does not exist", to), "")
+		}
+	}
+	from := c.QueryInt64("from")
+	if from == 0 {
+		from = toVersion.ParentVersion
+		if from == 0 {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("version %d of the rule has no previous version", toVersion.Version), "")
+		}
+	}
+	fromVersion := findRuleVersion(versions, from)
+	if fromVersion == nil {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("version %d of the rule does not exist", from), "")
+	}
+
+	return response.JSON(http.StatusOK, apimodels.RuleVersionDiff{
+		From:    fromVersion.Version,
+		To:      toVersion.Version,
+		Changes: diffRuleVersions(fromVersion, toVersion),
+	})
+}
+
+// RouteRestoreRuleVersion restores the definition of the alert rule with the given UID to a previous version.
+// The rule stays in its current folder and group, and keeps the settings of the group and its paused state.
+// The change is calculated, authorized and validated like any other change of the rule group.
+func (srv RulerSrv) RouteRestoreRuleVersion(c *contextmodel.ReqContext, ruleUID string, version string) response.Response {
+	v, err := strconv.ParseInt(version, 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("invalid version '%s': %w", version, err), "")
+	}
+	rule, versions, err := srv.getAuthorizedRuleVersions(c.Req.Context(), c, ruleUID)
+	if err != nil {
+		return ruleVersionsErrorResponse(err)
+	}
+	target := findRuleVersion(versions, v)
+	if target == nil {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("version %d of the rule does not exist", v), "")
+	}
+
+	restored := ngmodels.AlertRuleFromVersion(target)
+	restored.ID = rule.ID
+	restored.Version = rule.Version
+	restored.NamespaceUID = rule.NamespaceUID
+	restored.RuleGroup = rule.RuleGroup
+	restored.RuleGroupIndex = rule.RuleGroupIndex
+	restored.IntervalSeconds = rule.IntervalSeconds
+	restored.DashboardUID = rule.DashboardUID
+	restored.PanelID = rule.PanelID
+	restored.IsPaused = rule.IsPaused
+
+	srv.log.Info("Restoring alert rule version", "rule_uid", rule.UID, "version", target.Version, "current_version", rule.Version)
+	return srv.applyGroupChanges(c, rule.GetGroupKey(), func(ctx context.Context) (*store.GroupDelta, error) {
+		return store.CalculateRuleUpdate(ctx, srv.store, &ngmodels.AlertRuleWithOptionals{AlertRule: restored, HasPause: true})
+	})
+}
+
+// getAuthorizedRuleVersions fetches the rule by UID, checks whether the user is authorized to read it and returns the
+// rule and its versions, the most recent first.
+func (srv RulerSrv) getAuthorizedRuleVersions(ctx context.Context, c *contextmodel.ReqContext, ruleUID string) (ngmodels.AlertRule, []*ngmodels.AlertRuleVersion, error) {
+	rule, err := srv.getAuthorizedRuleByUid(ctx, c, ruleUID)
+	if err != nil {
+		return ngmodels.AlertRule{}, nil, err
+	}
+	versions, err := srv.store.GetAlertRuleVersions(ctx, &ngmodels.GetAlertRuleVersionsQuery{
+		UID:   rule.UID,
+		OrgID: rule.OrgID,
+	})
+	if err != nil {
+		return ngmodels.AlertRule{}, nil, err
+	}
+	return rule, versions, nil
+}
+
+func ruleVersionsErrorResponse(err error) response.Response {
+	if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return response.Empty(http.StatusNotFound)
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, "failed to get rule versions", err)
+}
+
+func findRuleVersion(versions []*ngmodels.AlertRuleVersion, version int64) *ngmodels.AlertRuleVersion {
+	for _, v := range versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
+
+// diffRuleVersions returns the changes made to the definition of the rule between two versions.
+func diffRuleVersions(from, to *ngmodels.AlertRuleVersion) []apimodels.RuleVersionChange {
+	fromRule := ngmodels.AlertRuleFromVersion(from)
+	toRule := ngmodels.AlertRuleFromVersion(to)
+	report := fromRule.Diff(&toRule, ruleVersionFieldsToIgnoreInDiff[:]...)
+
+	result := make([]apimodels.RuleVersionChange, 0, len(report))
+	for _, d := range report {
+		field, _, _ := strings.Cut(d.Path, ".")
+		field, _, _ = strings.Cut(field, "[")
+		if name, ok := ruleVersionChangeFields[field]; ok {
+			field = name
+		}
+		result = append(result, apimodels.RuleVersionChange{
+			Field: field,
+			Path:  d.Path,
+			From:  ruleVersionChangeValue(d.Left),
+			To:    ruleVersionChangeValue(d.Right),
+		})
+	}
+	return result
+}
+
+// ruleVersionChangeValue converts a value of the diff to a value that can be serialized to JSON.
+func ruleVersionChangeValue(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	switch value := v.Interface().(type) {
+	case time.Duration:
+		return model.Duration(value).String()
+	case string:
+		// The models of the queries are compared as strings.
+		if json.Valid([]byte(value)) && strings.HasPrefix(value, "{") {
+			return json.RawMessage(value)
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+func userUIDToString(uid *ngmodels.UserUID) string {
+	if uid == nil {
+		return ""
+	}
+	return string(*uid)
+}
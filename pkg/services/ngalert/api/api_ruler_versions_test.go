@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func ruleVersionOf(rule *models.AlertRule, version int64, parent int64, createdBy string, mutators ...models.AlertRuleMutator) *models.AlertRuleVersion {
+	r := models.CopyRule(rule, mutators...)
+	v := &models.AlertRuleVersion{
+		RuleOrgID:            r.OrgID,
+		RuleUID:              r.UID,
+		RuleNamespaceUID:     r.NamespaceUID,
+		RuleGroup:            r.RuleGroup,
+		RuleGroupIndex:       r.RuleGroupIndex,
+		ParentVersion:        parent,
+		Version:              version,
+		Created:              time.Unix(version*100, 0).UTC(),
+		Title:                r.Title,
+		Condition:            r.Condition,
+		Data:                 r.Data,
+		IntervalSeconds:      r.IntervalSeconds,
+		Record:               r.Record,
+		NoDataState:          r.NoDataState,
+		ExecErrState:         r.ExecErrState,
+		For:                  r.For,
+		Annotations:          r.Annotations,
+		Labels:               r.Labels,
+		IsPaused:             r.IsPaused,
+		NotificationSettings: r.NotificationSettings,
+		DependsOn:            r.DependsOn,
+	}
+	if createdBy != "" {
+		uid := models.UserUID(createdBy)
+		v.CreatedBy = &uid
+	}
+	return v
+}
+
+func setupRuleVersions(t *testing.T, orgID int64) (*fakes.RuleStore, *models.AlertRule) {
+	t.Helper()
+	gen := models.RuleGen
+	rule := gen.With(
+		gen.WithOrgID(orgID),
+		gen.WithNoNotificationSettings(),
+		gen.WithIsPaused(false),
+		func(rule *models.AlertRule) {
+			rule.Version = 2
+		},
+		gen.WithLabels(map[string]string{"severity": "critical"}),
+	).GenerateRef()
+	ruleStore := fakes.NewRuleStore(t)
+	ruleStore.PutRule(context.Background(), rule)
+	ruleStore.Versions[orgID] = []*models.AlertRuleVersion{
+		ruleVersionOf(rule, 1, 0, "author", gen.WithTitle("previous title"), gen.WithLabels(map[string]string{"severity": "warning"})),
+		ruleVersionOf(rule, 2, 1, "editor"),
+	}
+	return ruleStore, rule
+}
+
+func TestRouteGetRuleVersions(t *testing.T) {
+	orgID := int64(1)
+
+	t.Run("should return versions of the rule, the most recent first", func(t *testing.T) {
+		ruleStore, rule := setupRuleVersions(t, orgID)
+		req := createRequestContextWithPerms(orgID, createPermissionsForRules([]*models.AlertRule{rule}, orgID), nil)
+
+		resp := createService(ruleStore).RouteGetRuleVersions(req, rule.UID)
+		require.Equal(t, http.StatusOK, resp.Status())
+		var result apimodels.GettableRuleVersions
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Len(t, result, 2)
+
+		assert.Equal(t, int64(2), result[0].Version)
+		assert.Equal(t, int64(1), result[0].ParentVersion)
+		assert.Equal(t, "editor", result[0].CreatedBy)
+		assert.Equal(t, rule.Title, result[0].Rule.GrafanaManagedAlert.Title)
+
+		assert.Equal(t, int64(1), result[1].Version)
+		assert.Equal(t, "author", result[1].CreatedBy)
+		assert.Equal(t, "previous title", result[1].Rule.GrafanaManagedAlert.Title)
+		assert.Equal(t, rule.ID, result[1].Rule.GrafanaManagedAlert.ID)
+	})
+
+	t.Run("should return Forbidden if user cannot access the rule", func(t *testing.T) {
+		ruleStore, rule := setupRuleVersions(t, orgID)
+		req := createRequestContextWithPerms(orgID, map[int64]map[string][]string{}, nil)
+
+		resp := createService(ruleStore).RouteGetRuleVersions(req, rule.UID)
+		require.Equal(t, http.StatusForbidden, resp.Status())
+	})
+
+	t.Run("should return NotFound if rule does not exist", func(t *testing.T) {
+		ruleStore, _ := setupRuleVersions(t, orgID)
+		req := createRequestContext(orgID, nil)
+
+		resp := createService(ruleStore).RouteGetRuleVersions(req, "missing")
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+}
+
+func TestRouteGetRuleVersionsDiff(t *testing.T) {
+	orgID := int64(1)
+	ruleStore, rule := setupRuleVersions(t, orgID)
+	srv := createService(ruleStore)
+	srv.authz = &fakeRuleAccessControlService{}
+
+	getDiff := func(t *testing.T, query url.Values) (int, apimodels.RuleVersionDiff) {
+		t.Helper()
+		req := createRequestContext(orgID, nil)
+		req.Req.Form = query
+		resp := srv.RouteGetRuleVersionsDiff(req, rule.UID)
+		var result apimodels.RuleVersionDiff
+		if resp.Status() == http.StatusOK {
+			require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		}
+		return resp.Status(), result
+	}
+
+	t.Run("should return changes of the most recent version by default", func(t *testing.T) {
+		status, result := getDiff(t, url.Values{})
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, int64(1), result.From)
+		assert.Equal(t, int64(2), result.To)
+
+		changes := make(map[string]apimodels.RuleVersionChange, len(result.Changes))
+		for _, c := range result.Changes {
+			changes[c.Path] = c
+		}
+		require.Len(t, changes, 2)
+		assert.Equal(t, apimodels.RuleVersionChange{Field: "title", Path: "Title", From: "previous title", To: rule.Title}, changes["Title"])
+		assert.Equal(t, apimodels.RuleVersionChange{Field: "labels", Path: "Labels[severity]", From: "warning", To: "critical"}, changes["Labels[severity]"])
+	})
+
+	t.Run("should compare the requested versions", func(t *testing.T) {
+		status, result := getDiff(t, url.Values{"from": {"2"}, "to": {"1"}})
+		require.Equal(t, http.StatusOK, status)
+		assert.Equal(t, int64(2), result.From)
+		assert.Equal(t, int64(1), result.To)
+		require.Len(t, result.Changes, 2)
+
+		status, result = getDiff(t, url.Values{"from": {"2"}, "to": {"2"}})
+		require.Equal(t, http.StatusOK, status)
+		assert.Empty(t, result.Changes)
+	})
+
+	t.Run("should return BadRequest if version has no previous version", func(t *testing.T) {
+		status, _ := getDiff(t, url.Values{"to": {"1"}})
+		require.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("should return NotFound if version does not exist", func(t *testing.T) {
+		status, _ := getDiff(t, url.Values{"from": {"5"}})
+		require.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+func TestRouteRestoreRuleVersion(t *testing.T) {
+	orgID := int64(1)
+
+	createRestoreService := func(ruleStore *fakes.RuleStore) *RulerSrv {
+		srv := createService(ruleStore)
+		srv.authz = &fakeRuleAccessControlService{}
+		srv.conditionValidator = &recordingConditionValidator{}
+		return srv
+	}
+
+	t.Run("should update the rule with the definition of the version", func(t *testing.T) {
+		ruleStore, rule := setupRuleVersions(t, orgID)
+		// The group settings are not restored.
+		ruleStore.Versions[orgID][0].IntervalSeconds = rule.IntervalSeconds + 60
+		req := createRequestContext(orgID, nil)
+		req.SignedInUser = &user.SignedInUser{OrgID: orgID, UserID: 1, UserUID: "restorer"}
+
+		resp := createRestoreService(ruleStore).RouteRestoreRuleVersion(req, rule.UID, "1")
+		require.Equal(t, http.StatusAccepted, resp.Status())
+
+		updates := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.UpdateRule)
+			return c, ok
+		})
+		require.Len(t, updates, 1)
+		update := updates[0].([]models.UpdateRule)
+		require.Len(t, update, 1)
+		assert.Equal(t, rule, update[0].Existing)
+		assert.Equal(t, rule.ID, update[0].New.ID)
+		assert.Equal(t, "previous title", update[0].New.Title)
+		assert.Equal(t, map[string]string{"severity": "warning"}, update[0].New.Labels)
+		assert.Equal(t, rule.IntervalSeconds, update[0].New.IntervalSeconds)
+		assert.Equal(t, rule.GetGroupKey(), update[0].New.GetGroupKey())
+		require.NotNil(t, update[0].New.UpdatedBy)
+		assert.Equal(t, models.UserUID("restorer"), *update[0].New.UpdatedBy)
+	})
+
+	t.Run("should do nothing if the version is the current definition", func(t *testing.T) {
+		ruleStore, rule := setupRuleVersions(t, orgID)
+
+		resp := createRestoreService(ruleStore).RouteRestoreRuleVersion(createRequestContext(orgID, nil), rule.UID, "2")
+		require.Equal(t, http.StatusAccepted, resp.Status())
+		var result apimodels.UpdateRuleGroupResponse
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		assert.Empty(t, result.Updated)
+	})
+
+	t.Run("should return NotFound if version does not exist", func(t *testing.T) {
+		ruleStore, rule := setupRuleVersions(t, orgID)
+
+		resp := createRestoreService(ruleStore).RouteRestoreRuleVersion(createRequestContext(orgID, nil), rule.UID, "5")
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("should return BadRequest if version is not a number", func(t *testing.T) {
+		ruleStore, rule := setupRuleVersions(t, orgID)
+
+		resp := createRestoreService(ruleStore).RouteRestoreRuleVersion(createRequestContext(orgID, nil), rule.UID, "latest")
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+}
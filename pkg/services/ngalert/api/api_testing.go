@@ -18,6 +18,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -39,6 +40,10 @@ type folderService interface {
 	GetNamespaceByUID(ctx context.Context, uid string, orgID int64, user identity.Requester) (*folder.Folder, error)
 }
 
+type alertmanagerConfigProvider interface {
+	GetAlertmanagerConfiguration(ctx context.Context, org int64, withAutogen bool) (apimodels.GettableUserConfig, error)
+}
+
 type TestingApiSrv struct {
 	*AlertingProxy
 	DatasourceCache datasources.CacheService
@@ -51,6 +56,7 @@ type TestingApiSrv struct {
 	appUrl          *url.URL
 	tracer          tracing.Tracer
 	folderService   folderService
+	amConfig        alertmanagerConfigProvider
 }
 
 // RouteTestGrafanaRuleConfig returns a list of potential alerts for a given rule configuration. This is intended to be
@@ -194,7 +200,11 @@ func (srv TestingApiSrv) RouteEvalQueries(c *contextmodel.ReqContext, cmd apimod
 		now = timeNow()
 	}
 
-	evalResults, err := evaluator.EvaluateRaw(c.Req.Context(), now)
+	ctx := c.Req.Context()
+	if cmd.Explain {
+		ctx, _ = expr.WithExplain(ctx)
+	}
+	evalResults, err := evaluator.EvaluateRaw(ctx, now)
 
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "Failed to evaluate queries and expressions")
@@ -271,12 +281,13 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		Labels:          cmd.Labels,
 	}
 
+	if cmd.Notifications {
+		return srv.backtestNotifications(c, cmd, rule)
+	}
+
 	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
 	if err != nil {
-		if errors.Is(err, backtesting.ErrInvalidInputData) {
-			return ErrResp(400, err, "Failed to evaluate")
-		}
-		return ErrResp(500, err, "Failed to evaluate")
+		return backtestErrorResponse(err)
 	}
 
 	body, err := data.FrameToJSON(result, data.IncludeAll)
@@ -285,3 +296,69 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+// backtestNotifications tests the rule and simulates the notifications that the Alertmanager of the organization would
+// have sent for the resulting alerts.
+func (srv TestingApiSrv) backtestNotifications(c *contextmodel.ReqContext, cmd apimodels.BacktestConfig, rule *ngmodels.AlertRule) response.Response {
+	folderTitle := ""
+	if cmd.NamespaceUID != "" {
+		folder, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), cmd.NamespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+		if err != nil {
+			return toNamespaceErrorResponse(dashboards.ErrFolderAccessDenied)
+		}
+		rule.NamespaceUID = folder.UID
+		folderTitle = folder.Fullpath
+	}
+	rule.NotificationSettings = NotificationSettingsFromAlertRuleNotificationSettings(cmd.NotificationSettings)
+	for _, ns := range rule.NotificationSettings {
+		if err := ns.Validate(); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "Invalid notification settings")
+		}
+	}
+
+	amConfig, err := srv.amConfig.GetAlertmanagerConfiguration(c.Req.Context(), c.SignedInUser.GetOrgID(), true)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "Failed to get the Alertmanager configuration")
+	}
+
+	includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel)
+	extraLabels := state.GetRuleExtraLabels(log.New("backtesting"), rule, folderTitle, includeFolder)
+
+	states, notifications, err := srv.backtesting.TestNotifications(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To, extraLabels, amConfig.AlertmanagerConfig.Config)
+	if err != nil {
+		return backtestErrorResponse(err)
+	}
+
+	result := apimodels.BacktestNotificationsResult{
+		States:        states,
+		Notifications: make([]apimodels.BacktestNotification, 0, len(notifications)),
+	}
+	for _, n := range notifications {
+		alerts := make([]apimodels.BacktestNotificationAlert, 0, len(n.Alerts))
+		for _, a := range n.Alerts {
+			alerts = append(alerts, apimodels.BacktestNotificationAlert{
+				Labels:   a.Labels,
+				Status:   a.Status,
+				StartsAt: a.StartsAt,
+				EndsAt:   a.EndsAt,
+			})
+		}
+		result.Notifications = append(result.Notifications, apimodels.BacktestNotification{
+			Time:        n.Time,
+			Receiver:    n.Receiver,
+			GroupKey:    n.GroupKey,
+			GroupLabels: n.GroupLabels,
+			Alerts:      alerts,
+			Muted:       n.Muted,
+			MutedBy:     n.MutedBy,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func backtestErrorResponse(err error) response.Response {
+	if errors.Is(err, backtesting.ErrInvalidInputData) {
+		return ErrResp(400, err, "Failed to evaluate")
+	}
+	return ErrResp(500, err, "Failed to evaluate")
+}
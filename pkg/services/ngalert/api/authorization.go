@@ -40,17 +40,34 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodGet + "/api/ruler/grafana/api/v1/rules",
 		http.MethodGet + "/api/ruler/grafana/api/v1/export/rules":
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
-	case http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}":
+	case http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}",
+		http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions",
+		http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/diff":
 		eval = ac.EvalAll(
 			ac.EvalPermission(ac.ActionAlertingRuleRead),
 			ac.EvalPermission(dashboards.ActionFoldersRead),
 		)
+	case http.MethodPost + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore":
+		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
+		eval = ac.EvalAll(
+			ac.EvalPermission(ac.ActionAlertingRuleRead),
+			ac.EvalPermission(dashboards.ActionFoldersRead),
+			ac.EvalPermission(ac.ActionAlertingRuleUpdate),
+		)
 	case http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}/export":
 		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":Namespace"))
 		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
 		eval = ac.EvalAll(ac.EvalPermission(ac.ActionAlertingRuleRead, scope),
 			ac.EvalPermission(dashboards.ActionFoldersRead, scope),
 		)
+	case http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}/import/prometheus":
+		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":Namespace"))
+		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
+		eval = ac.EvalAll(
+			ac.EvalPermission(ac.ActionAlertingRuleRead, scope),
+			ac.EvalPermission(dashboards.ActionFoldersRead, scope),
+			ac.EvalPermission(ac.ActionAlertingRuleCreate, scope),
+		)
 	case http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}":
 		scope := dashboards.ScopeFoldersProvider.GetResourceScopeUID(ac.Parameter(":Namespace"))
 		// more granular permissions are enforced by the handler via "authorizeRuleChanges"
@@ -68,6 +85,17 @@ func (api *API) authorize(method, path string) web.Handler {
 	case http.MethodGet + "/api/v1/rules/history":
 		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
 
+	// Grafana alert instance state paths
+	case http.MethodGet + "/api/v1/rules/state/export":
+		// additional authorization is done in the request handler
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+	case http.MethodPost + "/api/v1/rules/state/import":
+		// additional authorization is done in the request handler
+		eval = ac.EvalAll(
+			ac.EvalPermission(ac.ActionAlertingRuleRead),
+			ac.EvalPermission(ac.ActionAlertingRuleUpdate),
+		)
+
 	// Grafana receivers paths
 	case http.MethodGet + "/api/v1/notifications/receivers":
 		// additional authorization is done at the service level
@@ -126,7 +154,9 @@ func (api *API) authorize(method, path string) web.Handler {
 
 	// Silences for Grafana paths.
 	// These permissions are required but not sufficient, further authorization is done in the request handler.
-	case http.MethodDelete + "/api/alertmanager/grafana/api/v2/silence/{SilenceId}": // Delete endpoint is used for silence expiration.
+	case http.MethodDelete + "/api/alertmanager/grafana/api/v2/silence/{SilenceId}", // Delete endpoint is used for silence expiration.
+		http.MethodDelete + "/api/alertmanager/grafana/api/v2/silence-template/{TemplateUID}",
+		http.MethodDelete + "/api/alertmanager/grafana/api/v2/silence-schedule/{ScheduleUID}":
 		eval = ac.EvalAll(
 			ac.EvalAny(
 				ac.EvalPermission(ac.ActionAlertingInstanceRead),
@@ -142,12 +172,16 @@ func (api *API) authorize(method, path string) web.Handler {
 			ac.EvalPermission(ac.ActionAlertingInstanceRead),
 			ac.EvalPermission(ac.ActionAlertingSilencesRead),
 		)
-	case http.MethodGet + "/api/alertmanager/grafana/api/v2/silences":
+	case http.MethodGet + "/api/alertmanager/grafana/api/v2/silences",
+		http.MethodGet + "/api/alertmanager/grafana/api/v2/silence-templates",
+		http.MethodGet + "/api/alertmanager/grafana/api/v2/silence-schedules":
 		eval = ac.EvalAny(
 			ac.EvalPermission(ac.ActionAlertingInstanceRead),
 			ac.EvalPermission(ac.ActionAlertingSilencesRead),
 		)
-	case http.MethodPost + "/api/alertmanager/grafana/api/v2/silences":
+	case http.MethodPost + "/api/alertmanager/grafana/api/v2/silences",
+		http.MethodPost + "/api/alertmanager/grafana/api/v2/silence-templates",
+		http.MethodPost + "/api/alertmanager/grafana/api/v2/silence-schedules":
 		eval = ac.EvalAll(
 			ac.EvalAny(
 				ac.EvalPermission(ac.ActionAlertingInstanceRead),
@@ -166,11 +200,26 @@ func (api *API) authorize(method, path string) web.Handler {
 		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
 	case http.MethodGet + "/api/alertmanager/grafana/api/v2/alerts":
 		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
+	case http.MethodPost + "/api/alertmanager/grafana/api/v2/silences/preview":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
 
 	// Grafana Prometheus-compatible Paths
 	case http.MethodGet + "/api/prometheus/grafana/api/v1/alerts":
 		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
 
+	// The results of recording rules written to the local time series database can be read by the users that can read
+	// alert rules.
+	case http.MethodGet + "/api/prometheus/grafana/api/v1/query",
+		http.MethodPost + "/api/prometheus/grafana/api/v1/query",
+		http.MethodGet + "/api/prometheus/grafana/api/v1/query_range",
+		http.MethodPost + "/api/prometheus/grafana/api/v1/query_range",
+		http.MethodGet + "/api/prometheus/grafana/api/v1/labels",
+		http.MethodPost + "/api/prometheus/grafana/api/v1/labels",
+		http.MethodGet + "/api/prometheus/grafana/api/v1/label/{LabelName}/values",
+		http.MethodGet + "/api/prometheus/grafana/api/v1/series",
+		http.MethodPost + "/api/prometheus/grafana/api/v1/series":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+
 	// Silences. External AM.
 	case http.MethodDelete + "/api/alertmanager/{DatasourceUID}/api/v2/silence/{SilenceId}":
 		eval = ac.EvalPermission(ac.ActionAlertingInstancesExternalWrite, datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":DatasourceUID")))
@@ -211,10 +260,17 @@ func (api *API) authorize(method, path string) web.Handler {
 		eval = ac.EvalAny(ac.EvalPermission(ac.ActionAlertingNotificationsWrite))
 	case http.MethodGet + "/api/alertmanager/grafana/config/api/v1/receivers":
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
+	case http.MethodGet + "/api/alertmanager/grafana/config/api/v1/deliveries":
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
 	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/receivers/test":
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsWrite)
 	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/templates/test":
 		eval = ac.EvalPermission(ac.ActionAlertingNotificationsWrite)
+	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/routing/explain":
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
+	case http.MethodGet + "/api/alertmanager/grafana/config/api/v1/routing/rules":
+		// the rules the user cannot access are filtered out in the request handler
+		eval = ac.EvalAll(ac.EvalPermission(ac.ActionAlertingNotificationsRead), ac.EvalPermission(ac.ActionAlertingRuleRead))
 
 	// External Alertmanager Paths
 	case http.MethodDelete + "/api/alertmanager/{DatasourceUID}/config/api/v1/alerts":
@@ -304,7 +360,23 @@ func (api *API) authorize(method, path string) web.Handler {
 			ac.EvalPermission(ac.ActionAlertingNotificationsRead),
 		)
 
+	// The configuration contains both the rules and the notification resources, so only the permissions for all kinds of
+	// resources are accepted.
+	case http.MethodGet + "/api/v1/provisioning/configuration":
+		eval = ac.EvalAny(
+			ac.EvalPermission(ac.ActionAlertingProvisioningRead),
+			ac.EvalPermission(ac.ActionAlertingProvisioningReadSecrets),
+		)
+
+	// Planning the configuration does not change anything, but it compares the supplied secure settings of contact
+	// points with the decrypted ones, so it requires the permission to read the secrets.
+	case http.MethodPost + "/api/v1/provisioning/configuration/plan":
+		eval = ac.EvalPermission(ac.ActionAlertingProvisioningReadSecrets)
+
 	// Grafana-only Provisioning Write Paths
+	case http.MethodPost + "/api/v1/provisioning/configuration/apply":
+		eval = ac.EvalPermission(ac.ActionAlertingProvisioningWrite) // organization scope
+
 	case http.MethodPost + "/api/v1/provisioning/alert-rules":
 		eval = ac.EvalAny(
 			ac.EvalPermission(ac.ActionAlertingProvisioningWrite),
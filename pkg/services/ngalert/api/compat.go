@@ -33,6 +33,7 @@ func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (mode
 		IsPaused:             a.IsPaused,
 		NotificationSettings: NotificationSettingsFromAlertRuleNotificationSettings(a.NotificationSettings),
 		Record:               ModelRecordFromApiRecord(a.Record),
+		DependsOn:            ruleDependsOn(a.DependsOn),
 	}, nil
 }
 
@@ -57,6 +58,7 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(rule.NotificationSettings),
 		Record:               ApiRecordFromModelRecord(rule.Record),
+		DependsOn:            rule.DependsOn,
 	}
 }
 
@@ -202,6 +204,9 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 	if rule.Labels != nil {
 		result.Labels = &rule.Labels
 	}
+	if len(rule.DependsOn) > 0 {
+		result.DependsOn = &rule.DependsOn
+	}
 	return result, nil
 }
 
@@ -460,10 +465,33 @@ func AlertRuleRecordExportFromRecord(r *models.Record) *definitions.AlertRuleRec
 	if r == nil {
 		return nil
 	}
-	return &definitions.AlertRuleRecordExport{
+	export := &definitions.AlertRuleRecordExport{
 		Metric: r.Metric,
 		From:   r.From,
 	}
+	if r.Target != nil {
+		export.Target = &definitions.AlertRuleRecordTargetExport{
+			Type:          string(r.Target.Type),
+			DatasourceUID: r.Target.DatasourceUID,
+			Table:         r.Target.Table,
+		}
+	}
+	return export
+}
+
+// ApiRuleDependenciesFromModelRuleDependencies converts the dependencies of a rule to the API model.
+func ApiRuleDependenciesFromModelRuleDependencies(deps []models.RuleDependency) []definitions.RuleDependency {
+	if len(deps) == 0 {
+		return nil
+	}
+	result := make([]definitions.RuleDependency, 0, len(deps))
+	for _, dep := range deps {
+		result = append(result, definitions.RuleDependency{
+			UID:      dep.UID,
+			Inferred: dep.Inferred,
+		})
+	}
+	return result
 }
 
 func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
@@ -473,6 +501,18 @@ func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
 	return &models.Record{
 		Metric: r.Metric,
 		From:   r.From,
+		Target: ModelRecordTargetFromApiRecordTarget(r.Target),
+	}
+}
+
+func ModelRecordTargetFromApiRecordTarget(t *definitions.RecordTarget) *models.RecordTarget {
+	if t == nil {
+		return nil
+	}
+	return &models.RecordTarget{
+		Type:          models.RecordTargetType(t.Type),
+		DatasourceUID: t.DatasourceUID,
+		Table:         t.Table,
 	}
 }
 
@@ -483,5 +523,17 @@ func ApiRecordFromModelRecord(r *models.Record) *definitions.Record {
 	return &definitions.Record{
 		Metric: r.Metric,
 		From:   r.From,
+		Target: ApiRecordTargetFromModelRecordTarget(r.Target),
+	}
+}
+
+func ApiRecordTargetFromModelRecordTarget(t *models.RecordTarget) *definitions.RecordTarget {
+	if t == nil {
+		return nil
+	}
+	return &definitions.RecordTarget{
+		Type:          string(t.Type),
+		DatasourceUID: t.DatasourceUID,
+		Table:         t.Table,
 	}
 }
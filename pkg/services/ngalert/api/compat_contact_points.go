@@ -47,7 +47,7 @@ func ContactPointToContactPointExport(cp definitions.ContactPoint) (notify.APIRe
 	j.RegisterExtension(&contactPointsExtension{})
 
 	contactPointsLength := len(cp.Alertmanager) + len(cp.Dingding) + len(cp.Discord) + len(cp.Email) +
-		len(cp.Googlechat) + len(cp.Kafka) + len(cp.Line) + len(cp.Opsgenie) +
+		len(cp.Googlechat) + len(cp.HTTP) + len(cp.Kafka) + len(cp.Line) + len(cp.Opsgenie) +
 		len(cp.Pagerduty) + len(cp.OnCall) + len(cp.Pushover) + len(cp.Sensugo) +
 		len(cp.Sns) + len(cp.Slack) + len(cp.Teams) + len(cp.Telegram) +
 		len(cp.Threema) + len(cp.Victorops) + len(cp.Webhook) + len(cp.Wecom) +
@@ -91,6 +91,13 @@ func ContactPointToContactPointExport(cp definitions.ContactPoint) (notify.APIRe
 		}
 		integration = append(integration, el)
 	}
+	for _, i := range cp.HTTP {
+		el, err := marshallIntegration(j, "http", i, i.DisableResolveMessage)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		integration = append(integration, el)
+	}
 	for _, i := range cp.Kafka {
 		el, err := marshallIntegration(j, "kafka", i, i.DisableResolveMessage)
 		if err != nil {
@@ -264,6 +271,11 @@ func parseIntegration(json jsoniter.API, result *definitions.ContactPoint, recei
 		if err = json.Unmarshal(data, &integration); err == nil {
 			result.Googlechat = append(result.Googlechat, integration)
 		}
+	case "http":
+		integration := definitions.HTTPIntegration{DisableResolveMessage: disable}
+		if err = json.Unmarshal(data, &integration); err == nil {
+			result.HTTP = append(result.HTTP, integration)
+		}
 	case "kafka":
 		integration := definitions.KafkaIntegration{DisableResolveMessage: disable}
 		if err = json.Unmarshal(data, &integration); err == nil {
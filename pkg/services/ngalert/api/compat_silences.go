@@ -2,6 +2,9 @@ package api
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -67,3 +70,78 @@ func SilencePermissionToAPI(p models.SilencePermission) (definitions.SilencePerm
 		return "", fmt.Errorf("unknown permission: %s", p)
 	}
 }
+
+func SilenceTemplateFromAPI(t definitions.SilenceTemplate) models.SilenceTemplate {
+	return models.SilenceTemplate{
+		UID:      t.UID,
+		Name:     t.Name,
+		Comment:  t.Comment,
+		Matchers: t.Matchers,
+		Duration: time.Duration(t.Duration),
+	}
+}
+
+func SilenceTemplatesToAPI(templates []*models.SilenceTemplate) definitions.SilenceTemplates {
+	res := make(definitions.SilenceTemplates, 0, len(templates))
+	for _, t := range templates {
+		res = append(res, definitions.SilenceTemplate{
+			UID:      t.UID,
+			Name:     t.Name,
+			Comment:  t.Comment,
+			Matchers: t.Matchers,
+			Duration: model.Duration(t.Duration),
+		})
+	}
+	return res
+}
+
+func SilenceScheduleFromAPI(s definitions.SilenceSchedule) models.SilenceSchedule {
+	result := models.SilenceSchedule{
+		UID:         s.UID,
+		TemplateUID: s.TemplateUID,
+		Matchers:    s.Matchers,
+		Comment:     s.Comment,
+		CreatedBy:   s.CreatedBy,
+		StartsAt:    s.StartsAt,
+		EndsAt:      s.EndsAt,
+	}
+	if s.Recurrence != nil {
+		result.Recurrence = &models.SilenceRecurrence{
+			Frequency: models.SilenceFrequency(s.Recurrence.Frequency),
+			Interval:  s.Recurrence.Interval,
+			Until:     s.Recurrence.Until,
+			Timezone:  s.Recurrence.Timezone,
+		}
+	}
+	return result
+}
+
+func SilenceSchedulesToAPI(schedules []*models.SilenceSchedule) definitions.SilenceSchedules {
+	res := make(definitions.SilenceSchedules, 0, len(schedules))
+	for _, s := range schedules {
+		apiSchedule := definitions.SilenceSchedule{
+			UID:         s.UID,
+			TemplateUID: s.TemplateUID,
+			Matchers:    s.Matchers,
+			Comment:     s.Comment,
+			CreatedBy:   s.CreatedBy,
+			StartsAt:    s.StartsAt,
+			EndsAt:      s.EndsAt,
+			SilenceID:   s.SilenceID,
+		}
+		if s.Recurrence != nil {
+			apiSchedule.Recurrence = &definitions.SilenceRecurrence{
+				Frequency: string(s.Recurrence.Frequency),
+				Interval:  s.Recurrence.Interval,
+				Until:     s.Recurrence.Until,
+				Timezone:  s.Recurrence.Timezone,
+			}
+		}
+		if !s.SilenceStartsAt.IsZero() {
+			startsAt := s.SilenceStartsAt
+			apiSchedule.SilenceStartsAt = &startsAt
+		}
+		res = append(res, apiSchedule)
+	}
+	return res
+}
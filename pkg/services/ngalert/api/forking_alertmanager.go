@@ -182,10 +182,22 @@ func (f *AlertmanagerApiHandler) handleRoutePostGrafanaAlertingConfig(ctx *conte
 	return f.GrafanaSvc.RoutePostAlertingConfig(ctx, conf)
 }
 
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaNotificationDeliveries(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetNotificationDeliveries(ctx)
+}
+
 func (f *AlertmanagerApiHandler) handleRouteGetGrafanaReceivers(ctx *contextmodel.ReqContext) response.Response {
 	return f.GrafanaSvc.RouteGetReceivers(ctx)
 }
 
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaRoutingExplain(ctx *contextmodel.ReqContext, conf apimodels.PostableRoutingExplain) response.Response {
+	return f.GrafanaSvc.RoutePostRoutingExplain(ctx, conf)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaRulesRouting(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetRulesRouting(ctx)
+}
+
 func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaReceivers(ctx *contextmodel.ReqContext, conf apimodels.TestReceiversConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestReceivers(ctx, conf)
 }
@@ -193,3 +205,31 @@ func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaReceivers(ctx *contex
 func (f *AlertmanagerApiHandler) handleRoutePostTestGrafanaTemplates(ctx *contextmodel.ReqContext, conf apimodels.TestTemplatesConfigBodyParams) response.Response {
 	return f.GrafanaSvc.RoutePostTestTemplates(ctx, conf)
 }
+
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaSilenceTemplates(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetSilenceTemplates(ctx)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteCreateGrafanaSilenceTemplate(ctx *contextmodel.ReqContext, body apimodels.SilenceTemplate) response.Response {
+	return f.GrafanaSvc.RouteCreateSilenceTemplate(ctx, body)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteDeleteGrafanaSilenceTemplate(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.GrafanaSvc.RouteDeleteSilenceTemplate(ctx, uid)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaSilenceSchedules(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetSilenceSchedules(ctx)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteCreateGrafanaSilenceSchedule(ctx *contextmodel.ReqContext, body apimodels.SilenceSchedule) response.Response {
+	return f.GrafanaSvc.RouteCreateSilenceSchedule(ctx, body)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteDeleteGrafanaSilenceSchedule(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.GrafanaSvc.RouteDeleteSilenceSchedule(ctx, uid)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePreviewGrafanaSilence(ctx *contextmodel.ReqContext, body apimodels.PostableSilencePreview) response.Response {
+	return f.GrafanaSvc.RoutePreviewSilence(ctx, body)
+}
@@ -46,6 +46,42 @@ func (f *PrometheusApiHandler) handleRouteGetGrafanaRuleStatuses(ctx *contextmod
 	return f.GrafanaSvc.RouteGetRuleStatuses(ctx)
 }
 
+func (f *PrometheusApiHandler) handleRouteGetGrafanaQuery(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteQuery(ctx)
+}
+
+func (f *PrometheusApiHandler) handleRoutePostGrafanaQuery(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteQuery(ctx)
+}
+
+func (f *PrometheusApiHandler) handleRouteGetGrafanaQueryRange(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteQueryRange(ctx)
+}
+
+func (f *PrometheusApiHandler) handleRoutePostGrafanaQueryRange(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteQueryRange(ctx)
+}
+
+func (f *PrometheusApiHandler) handleRouteGetGrafanaLabels(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetLabels(ctx)
+}
+
+func (f *PrometheusApiHandler) handleRoutePostGrafanaLabels(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetLabels(ctx)
+}
+
+func (f *PrometheusApiHandler) handleRouteGetGrafanaLabelValues(ctx *contextmodel.ReqContext, labelName string) response.Response {
+	return f.GrafanaSvc.RouteGetLabelValues(ctx, labelName)
+}
+
+func (f *PrometheusApiHandler) handleRouteGetGrafanaSeries(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetSeries(ctx)
+}
+
+func (f *PrometheusApiHandler) handleRoutePostGrafanaSeries(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetSeries(ctx)
+}
+
 func (f *PrometheusApiHandler) getService(ctx *contextmodel.ReqContext) (*LotexProm, error) {
 	_, err := getDatasourceByUID(ctx, f.DatasourceCache, apimodels.LoTexRulerBackend)
 	if err != nil {
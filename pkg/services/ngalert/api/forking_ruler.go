@@ -97,6 +97,22 @@ func (f *RulerApiHandler) handleRouteGetRuleByUID(ctx *contextmodel.ReqContext,
 	return f.GrafanaRuler.RouteGetRuleByUID(ctx, ruleUID)
 }
 
+func (f *RulerApiHandler) handleRouteGetRuleVersions(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleVersions(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRouteGetRuleVersionsDiff(ctx *contextmodel.ReqContext, ruleUID string) response.Response {
+	return f.GrafanaRuler.RouteGetRuleVersionsDiff(ctx, ruleUID)
+}
+
+func (f *RulerApiHandler) handleRouteRestoreRuleVersion(ctx *contextmodel.ReqContext, ruleUID string, version string) response.Response {
+	return f.GrafanaRuler.RouteRestoreRuleVersion(ctx, ruleUID, version)
+}
+
+func (f *RulerApiHandler) handleRouteImportPrometheusRules(ctx *contextmodel.ReqContext, conf apimodels.PrometheusRulesImport, namespace string) response.Response {
+	return f.GrafanaRuler.RouteImportPrometheusRules(ctx, conf, namespace)
+}
+
 func (f *RulerApiHandler) handleRoutePostNameGrafanaRulesConfig(ctx *contextmodel.ReqContext, conf apimodels.PostableRuleGroupConfig, namespace string) response.Response {
 	payloadType := conf.Type()
 	if payloadType != apimodels.GrafanaBackend {
@@ -21,10 +21,14 @@ import (
 
 type AlertmanagerApi interface {
 	RouteCreateGrafanaSilence(*contextmodel.ReqContext) response.Response
+	RouteCreateGrafanaSilenceSchedule(*contextmodel.ReqContext) response.Response
+	RouteCreateGrafanaSilenceTemplate(*contextmodel.ReqContext) response.Response
 	RouteCreateSilence(*contextmodel.ReqContext) response.Response
 	RouteDeleteAlertingConfig(*contextmodel.ReqContext) response.Response
 	RouteDeleteGrafanaAlertingConfig(*contextmodel.ReqContext) response.Response
 	RouteDeleteGrafanaSilence(*contextmodel.ReqContext) response.Response
+	RouteDeleteGrafanaSilenceSchedule(*contextmodel.ReqContext) response.Response
+	RouteDeleteGrafanaSilenceTemplate(*contextmodel.ReqContext) response.Response
 	RouteDeleteSilence(*contextmodel.ReqContext) response.Response
 	RouteGetAMAlertGroups(*contextmodel.ReqContext) response.Response
 	RouteGetAMAlerts(*contextmodel.ReqContext) response.Response
@@ -35,8 +39,12 @@ type AlertmanagerApi interface {
 	RouteGetGrafanaAMStatus(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaAlertingConfig(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaAlertingConfigHistory(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaNotificationDeliveries(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaReceivers(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaRulesRouting(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaSilence(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaSilenceSchedules(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaSilenceTemplates(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaSilences(*contextmodel.ReqContext) response.Response
 	RouteGetSilence(*contextmodel.ReqContext) response.Response
 	RouteGetSilences(*contextmodel.ReqContext) response.Response
@@ -44,8 +52,10 @@ type AlertmanagerApi interface {
 	RoutePostAlertingConfig(*contextmodel.ReqContext) response.Response
 	RoutePostGrafanaAlertingConfig(*contextmodel.ReqContext) response.Response
 	RoutePostGrafanaAlertingConfigHistoryActivate(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaRoutingExplain(*contextmodel.ReqContext) response.Response
 	RoutePostTestGrafanaReceivers(*contextmodel.ReqContext) response.Response
 	RoutePostTestGrafanaTemplates(*contextmodel.ReqContext) response.Response
+	RoutePreviewGrafanaSilence(*contextmodel.ReqContext) response.Response
 }
 
 func (f *AlertmanagerApiHandler) RouteCreateGrafanaSilence(ctx *contextmodel.ReqContext) response.Response {
@@ -56,6 +66,22 @@ func (f *AlertmanagerApiHandler) RouteCreateGrafanaSilence(ctx *contextmodel.Req
 	}
 	return f.handleRouteCreateGrafanaSilence(ctx, conf)
 }
+func (f *AlertmanagerApiHandler) RouteCreateGrafanaSilenceSchedule(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.SilenceSchedule{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteCreateGrafanaSilenceSchedule(ctx, conf)
+}
+func (f *AlertmanagerApiHandler) RouteCreateGrafanaSilenceTemplate(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.SilenceTemplate{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteCreateGrafanaSilenceTemplate(ctx, conf)
+}
 func (f *AlertmanagerApiHandler) RouteCreateSilence(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	datasourceUIDParam := web.Params(ctx.Req)[":DatasourceUID"]
@@ -79,6 +105,16 @@ func (f *AlertmanagerApiHandler) RouteDeleteGrafanaSilence(ctx *contextmodel.Req
 	silenceIdParam := web.Params(ctx.Req)[":SilenceId"]
 	return f.handleRouteDeleteGrafanaSilence(ctx, silenceIdParam)
 }
+func (f *AlertmanagerApiHandler) RouteDeleteGrafanaSilenceSchedule(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	scheduleUIDParam := web.Params(ctx.Req)[":ScheduleUID"]
+	return f.handleRouteDeleteGrafanaSilenceSchedule(ctx, scheduleUIDParam)
+}
+func (f *AlertmanagerApiHandler) RouteDeleteGrafanaSilenceTemplate(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	templateUIDParam := web.Params(ctx.Req)[":TemplateUID"]
+	return f.handleRouteDeleteGrafanaSilenceTemplate(ctx, templateUIDParam)
+}
 func (f *AlertmanagerApiHandler) RouteDeleteSilence(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	silenceIdParam := web.Params(ctx.Req)[":SilenceId"]
@@ -120,14 +156,26 @@ func (f *AlertmanagerApiHandler) RouteGetGrafanaAlertingConfig(ctx *contextmodel
 func (f *AlertmanagerApiHandler) RouteGetGrafanaAlertingConfigHistory(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaAlertingConfigHistory(ctx)
 }
+func (f *AlertmanagerApiHandler) RouteGetGrafanaNotificationDeliveries(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaNotificationDeliveries(ctx)
+}
 func (f *AlertmanagerApiHandler) RouteGetGrafanaReceivers(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaReceivers(ctx)
 }
+func (f *AlertmanagerApiHandler) RouteGetGrafanaRulesRouting(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaRulesRouting(ctx)
+}
 func (f *AlertmanagerApiHandler) RouteGetGrafanaSilence(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	silenceIdParam := web.Params(ctx.Req)[":SilenceId"]
 	return f.handleRouteGetGrafanaSilence(ctx, silenceIdParam)
 }
+func (f *AlertmanagerApiHandler) RouteGetGrafanaSilenceSchedules(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaSilenceSchedules(ctx)
+}
+func (f *AlertmanagerApiHandler) RouteGetGrafanaSilenceTemplates(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaSilenceTemplates(ctx)
+}
 func (f *AlertmanagerApiHandler) RouteGetGrafanaSilences(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaSilences(ctx)
 }
@@ -175,6 +223,14 @@ func (f *AlertmanagerApiHandler) RoutePostGrafanaAlertingConfigHistoryActivate(c
 	idParam := web.Params(ctx.Req)[":id"]
 	return f.handleRoutePostGrafanaAlertingConfigHistoryActivate(ctx, idParam)
 }
+func (f *AlertmanagerApiHandler) RoutePostGrafanaRoutingExplain(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.PostableRoutingExplain{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostGrafanaRoutingExplain(ctx, conf)
+}
 func (f *AlertmanagerApiHandler) RoutePostTestGrafanaReceivers(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.TestReceiversConfigBodyParams{}
@@ -191,6 +247,14 @@ func (f *AlertmanagerApiHandler) RoutePostTestGrafanaTemplates(ctx *contextmodel
 	}
 	return f.handleRoutePostTestGrafanaTemplates(ctx, conf)
 }
+func (f *AlertmanagerApiHandler) RoutePreviewGrafanaSilence(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.PostableSilencePreview{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePreviewGrafanaSilence(ctx, conf)
+}
 
 func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -206,6 +270,30 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-schedules"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silence-schedules"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silence-schedules",
+				api.Hooks.Wrap(srv.RouteCreateGrafanaSilenceSchedule),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-templates"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silence-templates"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silence-templates",
+				api.Hooks.Wrap(srv.RouteCreateGrafanaSilenceTemplate),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/{DatasourceUID}/api/v2/silences"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -254,6 +342,30 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-schedule/{ScheduleUID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodDelete, "/api/alertmanager/grafana/api/v2/silence-schedule/{ScheduleUID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/grafana/api/v2/silence-schedule/{ScheduleUID}",
+				api.Hooks.Wrap(srv.RouteDeleteGrafanaSilenceSchedule),
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-template/{TemplateUID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodDelete, "/api/alertmanager/grafana/api/v2/silence-template/{TemplateUID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/grafana/api/v2/silence-template/{TemplateUID}",
+				api.Hooks.Wrap(srv.RouteDeleteGrafanaSilenceTemplate),
+				m,
+			),
+		)
 		group.Delete(
 			toMacaronPath("/api/alertmanager/{DatasourceUID}/api/v2/silence/{SilenceId}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -374,6 +486,18 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/config/api/v1/deliveries"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/config/api/v1/deliveries"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/config/api/v1/deliveries",
+				api.Hooks.Wrap(srv.RouteGetGrafanaNotificationDeliveries),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/grafana/config/api/v1/receivers"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -386,6 +510,18 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/config/api/v1/routing/rules"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/config/api/v1/routing/rules"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/config/api/v1/routing/rules",
+				api.Hooks.Wrap(srv.RouteGetGrafanaRulesRouting),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/grafana/api/v2/silence/{SilenceId}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -398,6 +534,30 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-schedules"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/api/v2/silence-schedules"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v2/silence-schedules",
+				api.Hooks.Wrap(srv.RouteGetGrafanaSilenceSchedules),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silence-templates"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/api/v2/silence-templates"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v2/silence-templates",
+				api.Hooks.Wrap(srv.RouteGetGrafanaSilenceTemplates),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/grafana/api/v2/silences"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -482,6 +642,18 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/config/api/v1/routing/explain"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/config/api/v1/routing/explain"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/config/api/v1/routing/explain",
+				api.Hooks.Wrap(srv.RoutePostGrafanaRoutingExplain),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/grafana/config/api/v1/receivers/test"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -506,5 +678,17 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/silences/preview"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/silences/preview"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/silences/preview",
+				api.Hooks.Wrap(srv.RoutePreviewGrafanaSilence),
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
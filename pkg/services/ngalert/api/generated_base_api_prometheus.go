@@ -21,8 +21,17 @@ import (
 type PrometheusApi interface {
 	RouteGetAlertStatuses(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaAlertStatuses(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaLabelValues(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaLabels(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaQuery(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaQueryRange(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaRuleStatuses(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaSeries(*contextmodel.ReqContext) response.Response
 	RouteGetRuleStatuses(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaLabels(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaQuery(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaQueryRange(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaSeries(*contextmodel.ReqContext) response.Response
 }
 
 func (f *PrometheusApiHandler) RouteGetAlertStatuses(ctx *contextmodel.ReqContext) response.Response {
@@ -33,14 +42,43 @@ func (f *PrometheusApiHandler) RouteGetAlertStatuses(ctx *contextmodel.ReqContex
 func (f *PrometheusApiHandler) RouteGetGrafanaAlertStatuses(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaAlertStatuses(ctx)
 }
+func (f *PrometheusApiHandler) RouteGetGrafanaLabelValues(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	labelNameParam := web.Params(ctx.Req)[":LabelName"]
+	return f.handleRouteGetGrafanaLabelValues(ctx, labelNameParam)
+}
+func (f *PrometheusApiHandler) RouteGetGrafanaLabels(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaLabels(ctx)
+}
+func (f *PrometheusApiHandler) RouteGetGrafanaQuery(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaQuery(ctx)
+}
+func (f *PrometheusApiHandler) RouteGetGrafanaQueryRange(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaQueryRange(ctx)
+}
 func (f *PrometheusApiHandler) RouteGetGrafanaRuleStatuses(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaRuleStatuses(ctx)
 }
+func (f *PrometheusApiHandler) RouteGetGrafanaSeries(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaSeries(ctx)
+}
 func (f *PrometheusApiHandler) RouteGetRuleStatuses(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	datasourceUIDParam := web.Params(ctx.Req)[":DatasourceUID"]
 	return f.handleRouteGetRuleStatuses(ctx, datasourceUIDParam)
 }
+func (f *PrometheusApiHandler) RoutePostGrafanaLabels(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRoutePostGrafanaLabels(ctx)
+}
+func (f *PrometheusApiHandler) RoutePostGrafanaQuery(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRoutePostGrafanaQuery(ctx)
+}
+func (f *PrometheusApiHandler) RoutePostGrafanaQueryRange(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRoutePostGrafanaQueryRange(ctx)
+}
+func (f *PrometheusApiHandler) RoutePostGrafanaSeries(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRoutePostGrafanaSeries(ctx)
+}
 
 func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -68,6 +106,54 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/prometheus/grafana/api/v1/label/{LabelName}/values"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/prometheus/grafana/api/v1/label/{LabelName}/values"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/prometheus/grafana/api/v1/label/{LabelName}/values",
+				api.Hooks.Wrap(srv.RouteGetGrafanaLabelValues),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/prometheus/grafana/api/v1/labels"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/prometheus/grafana/api/v1/labels"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/prometheus/grafana/api/v1/labels",
+				api.Hooks.Wrap(srv.RouteGetGrafanaLabels),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/prometheus/grafana/api/v1/query"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/prometheus/grafana/api/v1/query"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/prometheus/grafana/api/v1/query",
+				api.Hooks.Wrap(srv.RouteGetGrafanaQuery),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/prometheus/grafana/api/v1/query_range"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/prometheus/grafana/api/v1/query_range"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/prometheus/grafana/api/v1/query_range",
+				api.Hooks.Wrap(srv.RouteGetGrafanaQueryRange),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/prometheus/grafana/api/v1/rules"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -80,6 +166,18 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/prometheus/grafana/api/v1/series"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/prometheus/grafana/api/v1/series"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/prometheus/grafana/api/v1/series",
+				api.Hooks.Wrap(srv.RouteGetGrafanaSeries),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/prometheus/{DatasourceUID}/api/v1/rules"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -92,5 +190,53 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApi, m *metrics.API
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/prometheus/grafana/api/v1/labels"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/prometheus/grafana/api/v1/labels"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/prometheus/grafana/api/v1/labels",
+				api.Hooks.Wrap(srv.RoutePostGrafanaLabels),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/prometheus/grafana/api/v1/query"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/prometheus/grafana/api/v1/query"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/prometheus/grafana/api/v1/query",
+				api.Hooks.Wrap(srv.RoutePostGrafanaQuery),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/prometheus/grafana/api/v1/query_range"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/prometheus/grafana/api/v1/query_range"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/prometheus/grafana/api/v1/query_range",
+				api.Hooks.Wrap(srv.RoutePostGrafanaQueryRange),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/prometheus/grafana/api/v1/series"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/prometheus/grafana/api/v1/series"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/prometheus/grafana/api/v1/series",
+				api.Hooks.Wrap(srv.RoutePostGrafanaSeries),
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
@@ -33,6 +33,7 @@ type ProvisioningApi interface {
 	RouteGetAlertRuleGroupExport(*contextmodel.ReqContext) response.Response
 	RouteGetAlertRules(*contextmodel.ReqContext) response.Response
 	RouteGetAlertRulesExport(*contextmodel.ReqContext) response.Response
+	RouteGetAlertingConfiguration(*contextmodel.ReqContext) response.Response
 	RouteGetContactpoints(*contextmodel.ReqContext) response.Response
 	RouteGetContactpointsExport(*contextmodel.ReqContext) response.Response
 	RouteGetMuteTiming(*contextmodel.ReqContext) response.Response
@@ -42,6 +43,8 @@ type ProvisioningApi interface {
 	RouteGetTemplate(*contextmodel.ReqContext) response.Response
 	RouteGetTemplates(*contextmodel.ReqContext) response.Response
 	RoutePostAlertRule(*contextmodel.ReqContext) response.Response
+	RoutePostAlertingConfigurationApply(*contextmodel.ReqContext) response.Response
+	RoutePostAlertingConfigurationPlan(*contextmodel.ReqContext) response.Response
 	RoutePostContactpoints(*contextmodel.ReqContext) response.Response
 	RoutePostMuteTiming(*contextmodel.ReqContext) response.Response
 	RoutePutAlertRule(*contextmodel.ReqContext) response.Response
@@ -115,6 +118,9 @@ func (f *ProvisioningApiHandler) RouteGetAlertRules(ctx *contextmodel.ReqContext
 func (f *ProvisioningApiHandler) RouteGetAlertRulesExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetAlertRulesExport(ctx)
 }
+func (f *ProvisioningApiHandler) RouteGetAlertingConfiguration(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetAlertingConfiguration(ctx)
+}
 func (f *ProvisioningApiHandler) RouteGetContactpoints(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetContactpoints(ctx)
 }
@@ -151,6 +157,22 @@ func (f *ProvisioningApiHandler) RoutePostAlertRule(ctx *contextmodel.ReqContext
 	}
 	return f.handleRoutePostAlertRule(ctx, conf)
 }
+func (f *ProvisioningApiHandler) RoutePostAlertingConfigurationApply(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.AlertingConfiguration{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostAlertingConfigurationApply(ctx, conf)
+}
+func (f *ProvisioningApiHandler) RoutePostAlertingConfigurationPlan(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.AlertingConfiguration{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRoutePostAlertingConfigurationPlan(ctx, conf)
+}
 func (f *ProvisioningApiHandler) RoutePostContactpoints(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.EmbeddedContactPoint{}
@@ -388,6 +410,18 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/configuration"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/configuration"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/configuration",
+				api.Hooks.Wrap(srv.RouteGetAlertingConfiguration),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/provisioning/contact-points"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -496,6 +530,30 @@ func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/configuration/apply"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/configuration/apply"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/configuration/apply",
+				api.Hooks.Wrap(srv.RoutePostAlertingConfigurationApply),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/configuration/plan"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/configuration/plan"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/configuration/plan",
+				api.Hooks.Wrap(srv.RoutePostAlertingConfigurationPlan),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/provisioning/contact-points"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
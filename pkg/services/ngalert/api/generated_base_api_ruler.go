@@ -29,12 +29,16 @@ type RulerApi interface {
 	RouteGetNamespaceGrafanaRulesConfig(*contextmodel.ReqContext) response.Response
 	RouteGetNamespaceRulesConfig(*contextmodel.ReqContext) response.Response
 	RouteGetRuleByUID(*contextmodel.ReqContext) response.Response
+	RouteGetRuleVersions(*contextmodel.ReqContext) response.Response
+	RouteGetRuleVersionsDiff(*contextmodel.ReqContext) response.Response
 	RouteGetRulegGroupConfig(*contextmodel.ReqContext) response.Response
 	RouteGetRulesConfig(*contextmodel.ReqContext) response.Response
 	RouteGetRulesForExport(*contextmodel.ReqContext) response.Response
+	RouteImportPrometheusRules(*contextmodel.ReqContext) response.Response
 	RoutePostNameGrafanaRulesConfig(*contextmodel.ReqContext) response.Response
 	RoutePostNameRulesConfig(*contextmodel.ReqContext) response.Response
 	RoutePostRulesGroupForExport(*contextmodel.ReqContext) response.Response
+	RouteRestoreRuleVersion(*contextmodel.ReqContext) response.Response
 }
 
 func (f *RulerApiHandler) RouteDeleteGrafanaRuleGroupConfig(ctx *contextmodel.ReqContext) response.Response {
@@ -86,6 +90,16 @@ func (f *RulerApiHandler) RouteGetRuleByUID(ctx *contextmodel.ReqContext) respon
 	ruleUIDParam := web.Params(ctx.Req)[":RuleUID"]
 	return f.handleRouteGetRuleByUID(ctx, ruleUIDParam)
 }
+func (f *RulerApiHandler) RouteGetRuleVersions(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	ruleUIDParam := web.Params(ctx.Req)[":RuleUID"]
+	return f.handleRouteGetRuleVersions(ctx, ruleUIDParam)
+}
+func (f *RulerApiHandler) RouteGetRuleVersionsDiff(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	ruleUIDParam := web.Params(ctx.Req)[":RuleUID"]
+	return f.handleRouteGetRuleVersionsDiff(ctx, ruleUIDParam)
+}
 func (f *RulerApiHandler) RouteGetRulegGroupConfig(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	datasourceUIDParam := web.Params(ctx.Req)[":DatasourceUID"]
@@ -101,6 +115,16 @@ func (f *RulerApiHandler) RouteGetRulesConfig(ctx *contextmodel.ReqContext) resp
 func (f *RulerApiHandler) RouteGetRulesForExport(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetRulesForExport(ctx)
 }
+func (f *RulerApiHandler) RouteImportPrometheusRules(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	namespaceParam := web.Params(ctx.Req)[":Namespace"]
+	// Parse Request Body
+	conf := apimodels.PrometheusRulesImport{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteImportPrometheusRules(ctx, conf, namespaceParam)
+}
 func (f *RulerApiHandler) RoutePostNameGrafanaRulesConfig(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	namespaceParam := web.Params(ctx.Req)[":Namespace"]
@@ -132,6 +156,12 @@ func (f *RulerApiHandler) RoutePostRulesGroupForExport(ctx *contextmodel.ReqCont
 	}
 	return f.handleRoutePostRulesGroupForExport(ctx, conf, namespaceParam)
 }
+func (f *RulerApiHandler) RouteRestoreRuleVersion(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	ruleUIDParam := web.Params(ctx.Req)[":RuleUID"]
+	versionParam := web.Params(ctx.Req)[":Version"]
+	return f.handleRouteRestoreRuleVersion(ctx, ruleUIDParam, versionParam)
+}
 
 func (api *API) RegisterRulerApiEndpoints(srv RulerApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
@@ -243,6 +273,30 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/rule/{RuleUID}/versions"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/rule/{RuleUID}/versions",
+				api.Hooks.Wrap(srv.RouteGetRuleVersions),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/diff"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/diff"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/diff",
+				api.Hooks.Wrap(srv.RouteGetRuleVersionsDiff),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/ruler/{DatasourceUID}/api/v1/rules/{Namespace}/{Groupname}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -279,6 +333,18 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/rules/{Namespace}/import/prometheus"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/rules/{Namespace}/import/prometheus"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/rules/{Namespace}/import/prometheus",
+				api.Hooks.Wrap(srv.RouteImportPrometheusRules),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/ruler/grafana/api/v1/rules/{Namespace}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -315,5 +381,17 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApi, m *metrics.API) {
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore",
+				api.Hooks.Wrap(srv.RouteRestoreRuleVersion),
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
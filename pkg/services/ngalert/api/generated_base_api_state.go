@@ -0,0 +1,66 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type StateApi interface {
+	RouteExportAlertInstanceStates(*contextmodel.ReqContext) response.Response
+	RouteImportAlertInstanceStates(*contextmodel.ReqContext) response.Response
+}
+
+func (f *StateApiHandler) RouteExportAlertInstanceStates(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteExportAlertInstanceStates(ctx)
+}
+func (f *StateApiHandler) RouteImportAlertInstanceStates(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.AlertInstanceStateImport{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteImportAlertInstanceStates(ctx, conf)
+}
+
+func (api *API) RegisterStateApiEndpoints(srv StateApi, m *metrics.API) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/rules/state/export"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/rules/state/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/rules/state/export",
+				api.Hooks.Wrap(srv.RouteExportAlertInstanceStates),
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/rules/state/import"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/v1/rules/state/import"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rules/state/import",
+				api.Hooks.Wrap(srv.RouteImportAlertInstanceStates),
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -139,3 +139,15 @@ func (f *ProvisioningApiHandler) handleRouteExportMuteTimings(ctx *contextmodel.
 func (f *ProvisioningApiHandler) handleRouteDeleteAlertRuleGroup(ctx *contextmodel.ReqContext, folderUID, group string) response.Response {
 	return f.svc.RouteDeleteAlertRuleGroup(ctx, folderUID, group)
 }
+
+func (f *ProvisioningApiHandler) handleRouteGetAlertingConfiguration(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteGetAlertingConfiguration(ctx)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostAlertingConfigurationPlan(ctx *contextmodel.ReqContext, body apimodels.AlertingConfiguration) response.Response {
+	return f.svc.RoutePostAlertingConfigurationPlan(ctx, body)
+}
+
+func (f *ProvisioningApiHandler) handleRoutePostAlertingConfigurationApply(ctx *contextmodel.ReqContext, body apimodels.AlertingConfiguration) response.Response {
+	return f.svc.RoutePostAlertingConfigurationApply(ctx, body)
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+type StateApiHandler struct {
+	svc *StateSrv
+}
+
+func NewStateApi(svc *StateSrv) *StateApiHandler {
+	return &StateApiHandler{
+		svc: svc,
+	}
+}
+
+func (f *StateApiHandler) handleRouteExportAlertInstanceStates(ctx *contextmodel.ReqContext) response.Response {
+	return f.svc.RouteExportAlertInstanceStates(ctx)
+}
+
+func (f *StateApiHandler) handleRouteImportAlertInstanceStates(ctx *contextmodel.ReqContext, body apimodels.AlertInstanceStateImport) response.Response {
+	return f.svc.RouteImportAlertInstanceStates(ctx, body)
+}
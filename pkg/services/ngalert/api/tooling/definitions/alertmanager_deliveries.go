@@ -0,0 +1,67 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /alertmanager/grafana/config/api/v1/deliveries alertmanager RouteGetGrafanaNotificationDeliveries
+//
+// get the latest notification deliveries of Grafana AM, the most recent first
+//
+//     Responses:
+//       200: NotificationDeliveries
+//       400: ValidationError
+
+// swagger:parameters RouteGetGrafanaNotificationDeliveries
+type NotificationDeliveriesParams struct {
+	// Only return the deliveries of this receiver.
+	// in:query
+	// required:false
+	Receiver string `json:"receiver"`
+	// Only return the deliveries of this type of integration, for example webhook.
+	// in:query
+	// required:false
+	Integration string `json:"integration"`
+	// Only return the deliveries of this alert group.
+	// in:query
+	// required:false
+	GroupKey string `json:"groupKey"`
+	// Only return the deliveries with this status.
+	// in:query
+	// required:false
+	// enum: sent,retrying,failed
+	Status string `json:"status"`
+	// Limit response to n deliveries.
+	// in:query
+	// required:false
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type NotificationDeliveries []NotificationDelivery
+
+// NotificationDelivery is the delivery of a notification by an integration of a receiver. The retries of the
+// notification update the same delivery.
+type NotificationDelivery struct {
+	// The time of the first attempt.
+	Timestamp        time.Time `json:"timestamp"`
+	Receiver         string    `json:"receiver"`
+	Integration      string    `json:"integration"`
+	IntegrationIndex int       `json:"integrationIndex"`
+	GroupKey         string    `json:"groupKey"`
+	// The SHA-256 hash of the request body when the integration sends a webhook, and of the alerts of the notification
+	// otherwise.
+	PayloadHash string `json:"payloadHash"`
+	// The number of alerts in the notification.
+	Alerts int `json:"alerts"`
+	// sent if the notification was sent, retrying if the last attempt failed and the notification is retried, and
+	// failed if the notification was not sent and is not retried anymore.
+	// enum: sent,retrying,failed
+	Status string `json:"status"`
+	// The HTTP status code of the response to the last attempt, if the integration sends a webhook.
+	StatusCode int `json:"statusCode,omitempty"`
+	Retries    int `json:"retries"`
+	// The duration of the last attempt in milliseconds.
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
@@ -0,0 +1,104 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route POST /alertmanager/grafana/config/api/v1/routing/explain alertmanager RoutePostGrafanaRoutingExplain
+//
+// explain how an alert is routed by the notification policies of Grafana AM
+//
+//     Responses:
+//       200: RoutingExplanation
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route GET /alertmanager/grafana/config/api/v1/routing/rules alertmanager RouteGetGrafanaRulesRouting
+//
+// get the contact points the alerts of each alert rule are routed to by the notification policies of Grafana AM
+//
+//     Responses:
+//       200: RulesRouting
+
+// swagger:parameters RoutePostGrafanaRoutingExplain
+type PostableRoutingExplainParams struct {
+	// in:body
+	Body PostableRoutingExplain
+}
+
+// PostableRoutingExplain is the alert to route. Either labels or the fingerprint of an alert of the Alertmanager must
+// be set.
+type PostableRoutingExplain struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	// Fingerprint of an alert of the Alertmanager. Its labels are routed.
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// RoutingExplanation explains how an alert is routed. Time intervals are evaluated at the current time, and
+// inhibition rules against the alerts that are currently firing.
+//
+// swagger:model
+type RoutingExplanation struct {
+	Labels map[string]string `json:"labels"`
+	// The routes that match the alert. There is more than one route when a matching route has continue set.
+	Routes []RoutingMatchedRoute `json:"routes"`
+	// The inhibition rules that inhibit the alert.
+	InhibitedBy []RoutingInhibition `json:"inhibitedBy,omitempty"`
+}
+
+// RoutingMatchedRoute is a route that matches an alert, with the options it inherits from its parents.
+type RoutingMatchedRoute struct {
+	// The path from the root route to the matched route.
+	Path     []RoutingRouteStep `json:"path"`
+	Receiver string             `json:"receiver"`
+	// The labels the alerts are grouped by, or "..." if they are grouped by all labels.
+	GroupBy []string `json:"groupBy"`
+	// The labels of the aggregation group of the alert.
+	GroupLabels         map[string]string `json:"groupLabels"`
+	GroupWait           model.Duration    `json:"groupWait"`
+	GroupInterval       model.Duration    `json:"groupInterval"`
+	RepeatInterval      model.Duration    `json:"repeatInterval"`
+	MuteTimeIntervals   []string          `json:"muteTimeIntervals,omitempty"`
+	ActiveTimeIntervals []string          `json:"activeTimeIntervals,omitempty"`
+	// True if the notifications of the route are muted now.
+	Muted bool `json:"muted"`
+	// The mute time intervals that are active, or the active time intervals of the route if none of them is active.
+	MutedBy []string `json:"mutedBy,omitempty"`
+}
+
+// RoutingRouteStep is a route on the path to a matched route.
+type RoutingRouteStep struct {
+	// The position of the route in the routes of its parent. It is 0 for the root route.
+	Index    int      `json:"index"`
+	Receiver string   `json:"receiver,omitempty"`
+	Matchers []string `json:"matchers,omitempty"`
+	Continue bool     `json:"continue,omitempty"`
+}
+
+// RoutingInhibition is an inhibition rule that inhibits an alert.
+type RoutingInhibition struct {
+	SourceMatchers []string `json:"sourceMatchers"`
+	TargetMatchers []string `json:"targetMatchers"`
+	Equal          []string `json:"equal,omitempty"`
+	// The labels of the firing alerts that inhibit the alert.
+	SourceAlerts []map[string]string `json:"sourceAlerts"`
+}
+
+// swagger:model
+type RulesRouting []RuleRouting
+
+// RuleRouting contains the routes the alerts of an alert rule are routed to, given the labels of the rule.
+type RuleRouting struct {
+	RuleUID   string `json:"ruleUid"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUid"`
+	RuleGroup string `json:"ruleGroup"`
+	// The labels of the rule, and the labels Grafana adds to its alerts.
+	Labels map[string]string `json:"labels"`
+	// True if some labels of the rule are templates. Their values are routed as is, although the alerts of the rule
+	// get the expanded values.
+	TemplatedLabels bool `json:"templatedLabels,omitempty"`
+	// The contact points of the routes.
+	Receivers []string              `json:"receivers"`
+	Routes    []RoutingMatchedRoute `json:"routes"`
+}
@@ -0,0 +1,174 @@
+package definitions
+
+import (
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /alertmanager/grafana/api/v2/silence-templates alertmanager RouteGetGrafanaSilenceTemplates
+//
+// get silence templates
+//
+//     Responses:
+//       200: SilenceTemplates
+//       403: ForbiddenError
+
+// swagger:route POST /alertmanager/grafana/api/v2/silence-templates alertmanager RouteCreateGrafanaSilenceTemplate
+//
+// create or update silence template
+//
+//     Responses:
+//       202: postSilenceTemplateOKBody
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: NotFound
+//       409: description: A silence template with the same name already exists.
+
+// swagger:route DELETE /alertmanager/grafana/api/v2/silence-template/{TemplateUID} alertmanager RouteDeleteGrafanaSilenceTemplate
+//
+// delete silence template
+//
+//     Responses:
+//       200: Ack
+//       403: ForbiddenError
+//       404: NotFound
+
+// swagger:route GET /alertmanager/grafana/api/v2/silence-schedules alertmanager RouteGetGrafanaSilenceSchedules
+//
+// get silence schedules
+//
+//     Responses:
+//       200: SilenceSchedules
+//       403: ForbiddenError
+
+// swagger:route POST /alertmanager/grafana/api/v2/silence-schedules alertmanager RouteCreateGrafanaSilenceSchedule
+//
+// create or update silence schedule
+//
+//     Responses:
+//       202: postSilenceScheduleOKBody
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: NotFound
+
+// swagger:route DELETE /alertmanager/grafana/api/v2/silence-schedule/{ScheduleUID} alertmanager RouteDeleteGrafanaSilenceSchedule
+//
+// delete silence schedule and expire its silence
+//
+//     Responses:
+//       200: Ack
+//       403: ForbiddenError
+//       404: NotFound
+
+// swagger:route POST /alertmanager/grafana/api/v2/silences/preview alertmanager RoutePreviewGrafanaSilence
+//
+// get the alerts that a silence with the given matchers would silence
+//
+//     Responses:
+//       200: gettableAlerts
+//       400: ValidationError
+
+// swagger:parameters RouteCreateGrafanaSilenceTemplate
+type CreateSilenceTemplateParams struct {
+	// in:body
+	Template SilenceTemplate
+}
+
+// swagger:parameters RouteDeleteGrafanaSilenceTemplate
+type DeleteSilenceTemplateParams struct {
+	// in:path
+	TemplateUID string
+}
+
+// swagger:parameters RouteCreateGrafanaSilenceSchedule
+type CreateSilenceScheduleParams struct {
+	// in:body
+	Schedule SilenceSchedule
+}
+
+// swagger:parameters RouteDeleteGrafanaSilenceSchedule
+type DeleteSilenceScheduleParams struct {
+	// in:path
+	ScheduleUID string
+}
+
+// swagger:parameters RoutePreviewGrafanaSilence
+type PreviewSilenceParams struct {
+	// in:body
+	Preview PostableSilencePreview
+}
+
+// swagger:model
+type SilenceTemplate struct {
+	// Empty to create a new template.
+	UID string `json:"uid,omitempty"`
+	// required: true
+	Name    string `json:"name"`
+	Comment string `json:"comment,omitempty"`
+	// required: true
+	Matchers amv2.Matchers `json:"matchers"`
+	// Default duration of the silences created from the template.
+	// example: 2h
+	Duration model.Duration `json:"duration,omitempty"`
+}
+
+// swagger:model
+type SilenceTemplates []SilenceTemplate
+
+// swagger:model postSilenceTemplateOKBody
+type PostSilenceTemplateOKBody struct {
+	UID string `json:"uid"`
+}
+
+// swagger:model
+type SilenceSchedule struct {
+	// Empty to create a new schedule.
+	UID string `json:"uid,omitempty"`
+	// UID of the template to copy the matchers and the comment from when the schedule has no matchers.
+	TemplateUID string        `json:"templateUid,omitempty"`
+	Matchers    amv2.Matchers `json:"matchers,omitempty"`
+	Comment     string        `json:"comment,omitempty"`
+	// Defaults to the login of the user.
+	CreatedBy string `json:"createdBy,omitempty"`
+	// Start of the first occurrence.
+	// required: true
+	StartsAt time.Time `json:"startsAt"`
+	// End of the first occurrence.
+	// required: true
+	EndsAt     time.Time          `json:"endsAt"`
+	Recurrence *SilenceRecurrence `json:"recurrence,omitempty"`
+	// ID of the silence of the current or next occurrence. Ignored on create and update.
+	SilenceID string `json:"silenceId,omitempty"`
+	// Start of the occurrence of the silence. Ignored on create and update.
+	SilenceStartsAt *time.Time `json:"silenceStartsAt,omitempty"`
+}
+
+// SilenceRecurrence describes how a silence schedule repeats.
+type SilenceRecurrence struct {
+	// required: true
+	// enum: daily,weekly
+	Frequency string `json:"frequency"`
+	// Number of days or weeks between two occurrences. Defaults to 1.
+	Interval int `json:"interval,omitempty"`
+	// Time after which no occurrence starts. The schedule repeats forever if not set.
+	Until *time.Time `json:"until,omitempty"`
+	// IANA name of the location the occurrences are calculated in. Defaults to UTC.
+	// example: Europe/Berlin
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// swagger:model
+type SilenceSchedules []SilenceSchedule
+
+// swagger:model postSilenceScheduleOKBody
+type PostSilenceScheduleOKBody struct {
+	UID string `json:"uid"`
+}
+
+// swagger:model
+type PostableSilencePreview struct {
+	// required: true
+	Matchers amv2.Matchers `json:"matchers"`
+}
@@ -62,6 +62,33 @@ type GooglechatIntegration struct {
 	Message *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
 }
 
+type HTTPIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	URL string `json:"url" yaml:"url" hcl:"url"`
+
+	HTTPMethod            *string            `json:"httpMethod,omitempty" yaml:"httpMethod,omitempty" hcl:"http_method"`
+	Headers               *map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" hcl:"headers"`
+	ContentType           *string            `json:"contentType,omitempty" yaml:"contentType,omitempty" hcl:"content_type"`
+	Body                  *string            `json:"body,omitempty" yaml:"body,omitempty" hcl:"body"`
+	AuthType              *string            `json:"authType,omitempty" yaml:"authType,omitempty" hcl:"auth_type"`
+	User                  *string            `json:"username,omitempty" yaml:"username,omitempty" hcl:"basic_auth_user"`
+	Password              *Secret            `json:"password,omitempty" yaml:"password,omitempty" hcl:"basic_auth_password"`
+	BearerToken           *Secret            `json:"bearerToken,omitempty" yaml:"bearerToken,omitempty" hcl:"bearer_token"`
+	OAuth2ClientID        *string            `json:"oauth2ClientId,omitempty" yaml:"oauth2ClientId,omitempty" hcl:"oauth2_client_id"`
+	OAuth2ClientSecret    *Secret            `json:"oauth2ClientSecret,omitempty" yaml:"oauth2ClientSecret,omitempty" hcl:"oauth2_client_secret"`
+	OAuth2TokenURL        *string            `json:"oauth2TokenUrl,omitempty" yaml:"oauth2TokenUrl,omitempty" hcl:"oauth2_token_url"`
+	OAuth2Scopes          *string            `json:"oauth2Scopes,omitempty" yaml:"oauth2Scopes,omitempty" hcl:"oauth2_scopes"`
+	HMACSecret            *Secret            `json:"hmacSecret,omitempty" yaml:"hmacSecret,omitempty" hcl:"hmac_secret"`
+	HMACHeader            *string            `json:"hmacHeader,omitempty" yaml:"hmacHeader,omitempty" hcl:"hmac_header"`
+	HMACTimestampHeader   *string            `json:"hmacTimestampHeader,omitempty" yaml:"hmacTimestampHeader,omitempty" hcl:"hmac_timestamp_header"`
+	TLSCACertificate      *string            `json:"tlsCACertificate,omitempty" yaml:"tlsCACertificate,omitempty" hcl:"tls_ca_certificate"`
+	TLSClientCertificate  *string            `json:"tlsClientCertificate,omitempty" yaml:"tlsClientCertificate,omitempty" hcl:"tls_client_certificate"`
+	TLSClientKey          *Secret            `json:"tlsClientKey,omitempty" yaml:"tlsClientKey,omitempty" hcl:"tls_client_key"`
+	TLSServerName         *string            `json:"tlsServerName,omitempty" yaml:"tlsServerName,omitempty" hcl:"tls_server_name"`
+	TLSInsecureSkipVerify *bool              `json:"tlsInsecureSkipVerify,omitempty" yaml:"tlsInsecureSkipVerify,omitempty" hcl:"tls_insecure_skip_verify"`
+}
+
 type KafkaIntegration struct {
 	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
 
@@ -297,6 +324,7 @@ type ContactPoint struct {
 	Discord      []DiscordIntegration      `json:"discord" yaml:"discord" hcl:"discord,block"`
 	Email        []EmailIntegration        `json:"email" yaml:"email" hcl:"email,block"`
 	Googlechat   []GooglechatIntegration   `json:"googlechat" yaml:"googlechat" hcl:"googlechat,block"`
+	HTTP         []HTTPIntegration         `json:"http" yaml:"http" hcl:"http,block"`
 	Kafka        []KafkaIntegration        `json:"kafka" yaml:"kafka" hcl:"kafka,block"`
 	Line         []LineIntegration         `json:"line" yaml:"line" hcl:"line,block"`
 	Opsgenie     []OpsgenieIntegration     `json:"opsgenie" yaml:"opsgenie" hcl:"opsgenie,block"`
@@ -218,7 +218,7 @@ type PathGetRulesParams struct {
 	PanelID int64
 }
 
-// swagger:parameters RouteGetRuleByUID
+// swagger:parameters RouteGetRuleByUID RouteGetRuleVersions RouteGetRuleVersionsDiff
 type PathGetRuleByUIDParams struct {
 	// in: path
 	RuleUID string
@@ -487,6 +487,22 @@ type Record struct {
 	// required: true
 	// example: A
 	From string `json:"from" yaml:"from"`
+	// Where the recorded metric is written to. If not set, it is written to the remote write endpoint configured for the instance.
+	Target *RecordTarget `json:"target,omitempty" yaml:"target,omitempty"`
+}
+
+type RecordTarget struct {
+	// Type of the target.
+	// required: true
+	// enum: remote_write,datasource,local
+	// example: datasource
+	Type string `json:"type" yaml:"type"`
+	// UID of the data source the recorded metric is written to. Only for the datasource target.
+	// example: my-postgres
+	DatasourceUID string `json:"datasource_uid,omitempty" yaml:"datasource_uid,omitempty"`
+	// Table the recorded metric is inserted into. Only for SQL data sources.
+	// example: recorded_metrics
+	Table string `json:"table,omitempty" yaml:"table,omitempty"`
 }
 
 // swagger:model
@@ -500,6 +516,8 @@ type PostableGrafanaRule struct {
 	IsPaused             *bool                          `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
 	Record               *Record                        `json:"record" yaml:"record"`
+	// UIDs of the rules of the same group that must be evaluated before this rule.
+	DependsOn []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
 }
 
 // swagger:model
@@ -521,6 +539,20 @@ type GettableGrafanaRule struct {
 	IsPaused             bool                           `json:"is_paused" yaml:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
+	// UIDs of the rules of the same group that must be evaluated before this rule.
+	DependsOn []string `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+	// Rules of the same group that are evaluated before this rule, declared or inferred from the metrics recorded by
+	// recording rules.
+	Dependencies []RuleDependency `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+}
+
+// RuleDependency is a dependency of a rule on another rule of the same group.
+type RuleDependency struct {
+	// UID of the rule that is evaluated first.
+	// example: my-recording-rule
+	UID string `json:"uid" yaml:"uid"`
+	// Whether the dependency is inferred from the metric recorded by the rule rather than declared.
+	Inferred bool `json:"inferred" yaml:"inferred"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -0,0 +1,192 @@
+package definitions
+
+// swagger:route GET /prometheus/grafana/api/v1/query prometheus RouteGetGrafanaQuery
+//
+// Evaluates an instant query on the results of recording rules written to the local time series database.
+//
+//     Responses:
+//       200: PrometheusQueryResponse
+//       400: PrometheusQueryResponse
+//       404: NotFound
+
+// swagger:route POST /prometheus/grafana/api/v1/query prometheus RoutePostGrafanaQuery
+//
+// Evaluates an instant query on the results of recording rules written to the local time series database.
+//
+//     Consumes:
+//     - application/x-www-form-urlencoded
+//
+//     Responses:
+//       200: PrometheusQueryResponse
+//       400: PrometheusQueryResponse
+//       404: NotFound
+
+// swagger:route GET /prometheus/grafana/api/v1/query_range prometheus RouteGetGrafanaQueryRange
+//
+// Evaluates a range query on the results of recording rules written to the local time series database.
+//
+//     Responses:
+//       200: PrometheusQueryResponse
+//       400: PrometheusQueryResponse
+//       404: NotFound
+
+// swagger:route POST /prometheus/grafana/api/v1/query_range prometheus RoutePostGrafanaQueryRange
+//
+// Evaluates a range query on the results of recording rules written to the local time series database.
+//
+//     Consumes:
+//     - application/x-www-form-urlencoded
+//
+//     Responses:
+//       200: PrometheusQueryResponse
+//       400: PrometheusQueryResponse
+//       404: NotFound
+
+// swagger:route GET /prometheus/grafana/api/v1/labels prometheus RouteGetGrafanaLabels
+//
+// Returns the label names of the results of recording rules written to the local time series database.
+//
+//     Responses:
+//       200: PrometheusLabelsResponse
+//       400: PrometheusLabelsResponse
+//       404: NotFound
+
+// swagger:route POST /prometheus/grafana/api/v1/labels prometheus RoutePostGrafanaLabels
+//
+// Returns the label names of the results of recording rules written to the local time series database.
+//
+//     Consumes:
+//     - application/x-www-form-urlencoded
+//
+//     Responses:
+//       200: PrometheusLabelsResponse
+//       400: PrometheusLabelsResponse
+//       404: NotFound
+
+// swagger:route GET /prometheus/grafana/api/v1/label/{LabelName}/values prometheus RouteGetGrafanaLabelValues
+//
+// Returns the values of a label of the results of recording rules written to the local time series database.
+//
+//     Responses:
+//       200: PrometheusLabelsResponse
+//       400: PrometheusLabelsResponse
+//       404: NotFound
+
+// swagger:route GET /prometheus/grafana/api/v1/series prometheus RouteGetGrafanaSeries
+//
+// Returns the series of the results of recording rules written to the local time series database.
+//
+//     Responses:
+//       200: PrometheusSeriesResponse
+//       400: PrometheusSeriesResponse
+//       404: NotFound
+
+// swagger:route POST /prometheus/grafana/api/v1/series prometheus RoutePostGrafanaSeries
+//
+// Returns the series of the results of recording rules written to the local time series database.
+//
+//     Consumes:
+//     - application/x-www-form-urlencoded
+//
+//     Responses:
+//       200: PrometheusSeriesResponse
+//       400: PrometheusSeriesResponse
+//       404: NotFound
+
+// swagger:parameters RouteGetGrafanaQuery RoutePostGrafanaQuery
+type PrometheusQueryParams struct {
+	// The PromQL query.
+	// in: query
+	// required: true
+	Query string `json:"query"`
+	// The evaluation time, as an RFC 3339 timestamp or a Unix timestamp in seconds. Defaults to the current time.
+	// in: query
+	// required: false
+	Time string `json:"time"`
+}
+
+// swagger:parameters RouteGetGrafanaQueryRange RoutePostGrafanaQueryRange
+type PrometheusQueryRangeParams struct {
+	// The PromQL query.
+	// in: query
+	// required: true
+	Query string `json:"query"`
+	// The start of the range, as an RFC 3339 timestamp or a Unix timestamp in seconds.
+	// in: query
+	// required: true
+	Start string `json:"start"`
+	// The end of the range, as an RFC 3339 timestamp or a Unix timestamp in seconds.
+	// in: query
+	// required: true
+	End string `json:"end"`
+	// The resolution of the range, as a duration or a number of seconds.
+	// in: query
+	// required: true
+	Step string `json:"step"`
+}
+
+// swagger:parameters RouteGetGrafanaLabels RoutePostGrafanaLabels RouteGetGrafanaSeries RoutePostGrafanaSeries
+type PrometheusSeriesParams struct {
+	// Series selectors. Only the series that match one of them are used.
+	// in: query
+	// required: false
+	Match []string `json:"match[]"`
+	// The start of the range, as an RFC 3339 timestamp or a Unix timestamp in seconds.
+	// in: query
+	// required: false
+	Start string `json:"start"`
+	// The end of the range, as an RFC 3339 timestamp or a Unix timestamp in seconds.
+	// in: query
+	// required: false
+	End string `json:"end"`
+}
+
+// swagger:parameters RouteGetGrafanaLabelValues
+type PrometheusLabelValuesParams struct {
+	// in: path
+	LabelName string
+	// Series selectors. Only the series that match one of them are used.
+	// in: query
+	// required: false
+	Match []string `json:"match[]"`
+	// The start of the range, as an RFC 3339 timestamp or a Unix timestamp in seconds.
+	// in: query
+	// required: false
+	Start string `json:"start"`
+	// The end of the range, as an RFC 3339 timestamp or a Unix timestamp in seconds.
+	// in: query
+	// required: false
+	End string `json:"end"`
+}
+
+// swagger:model
+type PrometheusQueryResponse struct {
+	DiscoveryBase
+	Data *PrometheusQueryData `json:"data,omitempty"`
+	// required: false
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// swagger:model
+type PrometheusQueryData struct {
+	// enum: vector,matrix,scalar,string
+	ResultType string `json:"resultType"`
+	// The result, in the format of the Prometheus HTTP API for the result type.
+	Result any `json:"result"`
+}
+
+// swagger:model
+type PrometheusLabelsResponse struct {
+	DiscoveryBase
+	Data []string `json:"data"`
+	// required: false
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// swagger:model
+type PrometheusSeriesResponse struct {
+	DiscoveryBase
+	Data []map[string]string `json:"data"`
+	// required: false
+	Warnings []string `json:"warnings,omitempty"`
+}
@@ -169,6 +169,9 @@ type ProvisionedAlertRule struct {
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings"`
 	//example: {"metric":"grafana_alerts_ratio", "from":"A"}
 	Record *Record `json:"record"`
+	// UIDs of the rules of the same group that must be evaluated before this rule.
+	// example: ["my-recording-rule"]
+	DependsOn []string `json:"dependsOn,omitempty"`
 }
 
 // swagger:route GET /v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RouteGetAlertRuleGroup
@@ -276,6 +279,7 @@ type AlertRuleExport struct {
 	IsPaused             bool                                 `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty" hcl:"notification_settings,block"`
 	Record               *AlertRuleRecordExport               `json:"record,omitempty" yaml:"record,omitempty" hcl:"record"`
+	DependsOn            *[]string                            `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty" hcl:"depends_on"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
@@ -307,6 +311,14 @@ type AlertRuleNotificationSettingsExport struct {
 
 // Record is the provisioned export of models.Record.
 type AlertRuleRecordExport struct {
-	Metric string `json:"metric" yaml:"metric" hcl:"metric"`
-	From   string `json:"from" yaml:"from" hcl:"from"`
+	Metric string                       `json:"metric" yaml:"metric" hcl:"metric"`
+	From   string                       `json:"from" yaml:"from" hcl:"from"`
+	Target *AlertRuleRecordTargetExport `json:"target,omitempty" yaml:"target,omitempty" hcl:"target,block"`
+}
+
+// AlertRuleRecordTargetExport is the provisioned export of models.RecordTarget.
+type AlertRuleRecordTargetExport struct {
+	Type          string `json:"type" yaml:"type" hcl:"type"`
+	DatasourceUID string `json:"datasourceUid,omitempty" yaml:"datasourceUid,omitempty" hcl:"datasource_uid"`
+	Table         string `json:"table,omitempty" yaml:"table,omitempty" hcl:"table"`
 }
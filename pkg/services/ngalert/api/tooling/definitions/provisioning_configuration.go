@@ -0,0 +1,95 @@
+package definitions
+
+// swagger:route GET /v1/provisioning/configuration provisioning stable RouteGetAlertingConfiguration
+//
+// Get the alerting configuration of the organization: rule groups, contact points, notification policies, mute timings and templates.
+//
+//     Responses:
+//       200: AlertingConfiguration
+//       404: description: Not found.
+
+// swagger:route POST /v1/provisioning/configuration/plan provisioning stable RoutePostAlertingConfigurationPlan
+//
+// Calculate the changes that are needed to get from the current alerting configuration to the one in the request, without applying them.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: AlertingConfigurationPlan
+//       400: ValidationError
+//       403: PermissionDenied
+
+// swagger:route POST /v1/provisioning/configuration/apply provisioning stable RoutePostAlertingConfigurationApply
+//
+// Apply the changes that are needed to get from the current alerting configuration to the one in the request, in a single transaction.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: AlertingConfigurationPlan
+//       400: ValidationError
+//       403: PermissionDenied
+//       409: GenericPublicError
+
+// swagger:parameters RoutePostAlertingConfigurationPlan RoutePostAlertingConfigurationApply
+type AlertingConfigurationPayload struct {
+	// in:body
+	Body AlertingConfiguration
+}
+
+// swagger:parameters RoutePostAlertingConfigurationApply
+type AlertingConfigurationHeaders struct {
+	// in:header
+	XDisableProvenance string `json:"X-Disable-Provenance"`
+}
+
+// AlertingConfiguration is the alerting configuration of an organization. A kind of resource that is not set is not
+// managed: it is left unchanged when the configuration is applied. Otherwise, the resources of this kind that are not in
+// the configuration are deleted.
+//
+// swagger:model
+type AlertingConfiguration struct {
+	// Version of the configuration. When it is set, the configuration is only applied if the current configuration
+	// still has this version.
+	Version string `json:"version,omitempty"`
+	// The rules without UID are matched with the rules of the same title in the folder.
+	RuleGroups []AlertRuleGroup `json:"ruleGroups"`
+	// Contact points are identified by their UID. Redacted secure settings keep their current value.
+	ContactPoints []EmbeddedContactPoint `json:"contactPoints"`
+	Policies      *Route                 `json:"policies"`
+	MuteTimings   []MuteTimeInterval     `json:"muteTimings"`
+	Templates     []NotificationTemplate `json:"templates"`
+}
+
+// swagger:model
+type AlertingConfigurationPlan struct {
+	// Version of the configuration the plan is calculated against.
+	Version string `json:"version"`
+	// The changes in the order they are applied.
+	Changes []AlertingConfigurationChange `json:"changes"`
+}
+
+// AlertingConfigurationChange is a change of a single resource of the alerting configuration.
+type AlertingConfigurationChange struct {
+	// enum: rule_group,contact_point,notification_policies,mute_timing,template
+	Kind string `json:"kind"`
+	// UID of the folder of a rule group.
+	FolderUID string `json:"folderUid,omitempty"`
+	// UID of a contact point.
+	UID  string `json:"uid,omitempty"`
+	Name string `json:"name,omitempty"`
+	// enum: create,update,delete
+	Action string `json:"action"`
+	// The fields of the resource that are updated. For rule groups, the rules that are created or deleted, and the fields
+	// of the rules that are updated. Secure settings of contact points are redacted.
+	Diff []AlertingConfigurationDiff `json:"diff,omitempty"`
+}
+
+// AlertingConfigurationDiff is a field of a resource that is changed.
+type AlertingConfigurationDiff struct {
+	Path string `json:"path"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
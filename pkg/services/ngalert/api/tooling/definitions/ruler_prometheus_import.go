@@ -0,0 +1,91 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route POST /ruler/grafana/api/v1/rules/{Namespace}/import/prometheus ruler RouteImportPrometheusRules
+//
+// Convert Prometheus rule groups to Grafana-managed rule groups and save them in a folder
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: PrometheusRulesImportResult
+//       202: PrometheusRulesImportResult
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:parameters RouteImportPrometheusRules
+type ImportPrometheusRulesParams struct {
+	// The UID of the rule folder
+	// in:path
+	Namespace string
+	// in:body
+	Body PrometheusRulesImport
+}
+
+// swagger:model
+type PrometheusRulesImport struct {
+	// UID of the Prometheus data source the converted rules query.
+	// required: true
+	DatasourceUID string `json:"datasourceUid"`
+	// Convert the rule groups and report the rules that cannot be converted, without saving anything.
+	DryRun bool `json:"dryRun,omitempty"`
+	// The rule groups of a Prometheus rule file.
+	// required: true
+	Groups []PrometheusRuleGroup `json:"groups"`
+}
+
+// PrometheusRuleFile is a Prometheus rule file.
+type PrometheusRuleFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// PrometheusRuleGroup is a group of a Prometheus rule file.
+type PrometheusRuleGroup struct {
+	// required: true
+	Name        string            `yaml:"name" json:"name"`
+	Interval    model.Duration    `yaml:"interval,omitempty" json:"interval,omitempty"`
+	QueryOffset *model.Duration   `yaml:"query_offset,omitempty" json:"query_offset,omitempty"`
+	Limit       int               `yaml:"limit,omitempty" json:"limit,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	// required: true
+	Rules []ApiRuleNode `yaml:"rules" json:"rules"`
+}
+
+// swagger:model
+type PrometheusRulesImportResult struct {
+	// True if the converted rule groups were not saved.
+	DryRun bool                              `json:"dryRun"`
+	Groups []PrometheusRuleGroupImportResult `json:"groups"`
+}
+
+// PrometheusRuleGroupImportResult is the result of the conversion of a Prometheus rule group.
+type PrometheusRuleGroupImportResult struct {
+	Name string `json:"name"`
+	// Why the group cannot be imported. No rule of the group is imported if set.
+	Error string `json:"error,omitempty"`
+	// Settings of the group that are not translated.
+	Warnings []string                     `json:"warnings,omitempty"`
+	Rules    []PrometheusRuleImportResult `json:"rules"`
+}
+
+// PrometheusRuleImportResult is the result of the conversion of a Prometheus alerting or recording rule.
+type PrometheusRuleImportResult struct {
+	// Name of the Prometheus alert or recorded metric.
+	Name string `json:"name"`
+	// Title of the Grafana-managed rule. Empty if the rule cannot be converted.
+	Title string `json:"title,omitempty"`
+	// UID of the Grafana-managed rule. Only set once the rule is saved.
+	UID string `json:"uid,omitempty"`
+	// Why the rule cannot be converted.
+	Error string `json:"error,omitempty"`
+	// Parts of the rule that are not translated, or that behave differently in Grafana.
+	Warnings []string `json:"warnings,omitempty"`
+}
@@ -0,0 +1,85 @@
+package definitions
+
+import "time"
+
+// swagger:route GET /v1/rules/state/export state RouteExportAlertInstanceStates
+//
+// Export the current state of the alert instances of the organization.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertInstanceStateExport
+
+// swagger:route POST /v1/rules/state/import state RouteImportAlertInstanceStates
+//
+// Import the state of alert instances exported from another Grafana instance or organization.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertInstanceStateImportResult
+//       400: ValidationError
+//       403: ForbiddenError
+
+// swagger:parameters RouteExportAlertInstanceStates
+type ExportAlertInstanceStatesParams struct {
+	// Only export the state of the alert rules with these UIDs.
+	// in:query
+	// required: false
+	RuleUID []string `json:"ruleUID"`
+}
+
+// swagger:parameters RouteImportAlertInstanceStates
+type ImportAlertInstanceStatesParams struct {
+	// in:body
+	Body AlertInstanceStateImport
+}
+
+// swagger:model
+type AlertInstanceStateExport struct {
+	Instances []AlertInstanceState `json:"instances"`
+}
+
+// swagger:model
+type AlertInstanceStateImport struct {
+	Instances []AlertInstanceState `json:"instances"`
+	// RuleUIDMapping maps the UIDs of the alert rules in the export to the UIDs of the alert rules in this
+	// organization. The instances of the rules that are not in the mapping are imported to the rules with the same UID.
+	RuleUIDMapping map[string]string `json:"ruleUIDMapping,omitempty"`
+}
+
+// AlertInstanceState is the state of an alert instance.
+// swagger:model
+type AlertInstanceState struct {
+	// example: okrd3I0Vz
+	RuleUID string            `json:"ruleUID"`
+	Labels  map[string]string `json:"labels"`
+	// enum: Alerting,Pending,Normal,NoData,Error
+	State string `json:"state"`
+	// example: MissingSeries
+	Reason            string    `json:"reason,omitempty"`
+	StateSince        time.Time `json:"stateSince"`
+	StateEnd          time.Time `json:"stateEnd"`
+	LastEvaluation    time.Time `json:"lastEvaluation"`
+	ResultFingerprint string    `json:"resultFingerprint,omitempty"`
+}
+
+// swagger:model
+type AlertInstanceStateImportResult struct {
+	// Imported is the number of alert instances that were imported.
+	Imported int `json:"imported"`
+	// Skipped contains the alert instances that were not imported, and why.
+	Skipped []SkippedAlertInstanceState `json:"skipped,omitempty"`
+}
+
+type SkippedAlertInstanceState struct {
+	RuleUID string            `json:"ruleUID"`
+	Labels  map[string]string `json:"labels"`
+	Reason  string            `json:"reason"`
+}
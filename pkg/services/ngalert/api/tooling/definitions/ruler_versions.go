@@ -0,0 +1,98 @@
+package definitions
+
+import "time"
+
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions ruler RouteGetRuleVersions
+//
+// Get the versions of a rule, the most recent first
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersions
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route Get /ruler/grafana/api/v1/rule/{RuleUID}/versions/diff ruler RouteGetRuleVersionsDiff
+//
+// Get the changes made to a rule between two versions
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersionDiff
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+
+// swagger:route POST /ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore ruler RouteRestoreRuleVersion
+//
+// Restore the definition of a rule to a previous version
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: UpdateRuleGroupResponse
+//       400: ValidationError
+//       403: ForbiddenError
+//       404: description: Not found.
+//       409: description: The rule was changed by another request.
+
+// swagger:parameters RouteGetRuleVersionsDiff
+type RuleVersionsDiffParams struct {
+	// The version to compare from. Defaults to the version before the one to compare to.
+	// in: query
+	// required: false
+	From int64 `json:"from"`
+	// The version to compare to. Defaults to the current version of the rule.
+	// in: query
+	// required: false
+	To int64 `json:"to"`
+}
+
+// swagger:parameters RouteRestoreRuleVersion
+type RestoreRuleVersionParams struct {
+	// in: path
+	RuleUID string
+	// in: path
+	Version int64
+}
+
+// swagger:model
+type GettableRuleVersions []GettableRuleVersion
+
+// GettableRuleVersion is the definition of a rule at a version, and who created it.
+type GettableRuleVersion struct {
+	// example: 3
+	Version int64 `json:"version"`
+	// example: 2
+	ParentVersion int64     `json:"parentVersion,omitempty"`
+	Created       time.Time `json:"created"`
+	// UID of the user who made the change that created this version. Empty if it is not known.
+	CreatedBy string                   `json:"createdBy,omitempty"`
+	Rule      GettableExtendedRuleNode `json:"rule"`
+}
+
+// swagger:model
+type RuleVersionDiff struct {
+	From    int64               `json:"from"`
+	To      int64               `json:"to"`
+	Changes []RuleVersionChange `json:"changes"`
+}
+
+// RuleVersionChange is a change made to a rule between two versions.
+type RuleVersionChange struct {
+	// The part of the rule that changed.
+	// enum: title,condition,queries,labels,annotations,notification_settings,for,no_data_state,exec_err_state,is_paused,record,depends_on,interval,folder,group
+	Field string `json:"field"`
+	// Path to the value that changed. Map keys and slice indexes are in square brackets.
+	// example: Labels[severity]
+	Path string `json:"path"`
+	// The value before the change. Absent if the value was added.
+	From any `json:"from,omitempty"`
+	// The value after the change. Absent if the value was removed.
+	To any `json:"to,omitempty"`
+}
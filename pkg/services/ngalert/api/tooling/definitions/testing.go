@@ -131,6 +131,8 @@ type EvalQueriesPayload struct {
 	Condition string       `json:"condition"`
 	Data      []AlertQuery `json:"data"`
 	Now       time.Time    `json:"now"`
+	// Explain adds the explanation of how each query and expression is executed to the results.
+	Explain bool `json:"explain,omitempty"`
 }
 
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
@@ -230,7 +232,40 @@ type BacktestConfig struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 
 	NoDataState NoDataState `json:"no_data_state"`
+
+	// Notifications enables the simulation of the notifications. The alerts are sent through the routing tree,
+	// mute timings and active timings of the Alertmanager configuration of the organization, and the response is a
+	// BacktestNotificationsResult.
+	Notifications bool `json:"notifications,omitempty"`
+	// NamespaceUID is the UID of the folder of the rule. It is used to add the folder label to the alerts when simulating notifications.
+	NamespaceUID         string                         `json:"namespace_uid,omitempty"`
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty"`
 }
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:model
+type BacktestNotificationsResult struct {
+	// States is the data frame of the states of the alerts, same as BacktestResult.
+	States        *data.Frame            `json:"states"`
+	Notifications []BacktestNotification `json:"notifications"`
+}
+
+type BacktestNotification struct {
+	Time        time.Time                   `json:"time"`
+	Receiver    string                      `json:"receiver"`
+	GroupKey    string                      `json:"groupKey"`
+	GroupLabels map[string]string           `json:"groupLabels"`
+	Alerts      []BacktestNotificationAlert `json:"alerts"`
+	// Muted is true if the notification was not sent because of a mute timing or outside of the active timings of the route.
+	Muted   bool     `json:"muted"`
+	MutedBy []string `json:"mutedBy,omitempty"`
+}
+
+type BacktestNotificationAlert struct {
+	Labels   map[string]string `json:"labels"`
+	Status   string            `json:"status"`
+	StartsAt time.Time         `json:"startsAt"`
+	EndsAt   time.Time         `json:"endsAt,omitempty"`
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
@@ -64,6 +65,25 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracin
 }
 
 func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
+	return e.test(ctx, user, rule, from, to, nil, nil)
+}
+
+// TestNotifications tests the rule like Test, and also sends the resulting alerts through the routing tree, mute timings
+// and active timings of the given Alertmanager configuration. It returns the notifications that would have been sent.
+// The extra labels are added to the alerts, same as the scheduler does, so that they can be matched by the routes.
+func (e *Engine) TestNotifications(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, extraLabels data.Labels, cfg apimodels.Config) (*data.Frame, []Notification, error) {
+	simulator, err := newNotificationSimulator(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := e.test(ctx, user, rule, from, to, extraLabels, simulator.Process)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, simulator.Finish(to), nil
+}
+
+func (e *Engine) test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, extraLabels data.Labels, onStates func(now time.Time, states []state.StateTransition)) (*data.Frame, error) {
 	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
@@ -97,7 +117,10 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
 			return nil
 		}
-		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil)
+		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, extraLabels)
+		if onStates != nil {
+			onStates(currentTime, states)
+		}
 		tsField.Set(idx, currentTime)
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
@@ -0,0 +1,319 @@
+package backtesting
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+const (
+	AlertStatusFiring   = "firing"
+	AlertStatusResolved = "resolved"
+)
+
+// Notification is a notification that the Alertmanager would have sent during the backtesting.
+type Notification struct {
+	// Time is the time at which the notification would have been sent.
+	Time     time.Time
+	Receiver string
+	// GroupKey identifies the aggregation group of the notification, same as in the Alertmanager.
+	GroupKey    string
+	GroupLabels map[string]string
+	Alerts      []NotificationAlert
+	// Muted is true if the notification would have been sent but was muted by a time interval.
+	// MutedBy contains the names of the mute time intervals that contain the time of the notification, or the names of
+	// the active time intervals of the route if the notification was sent outside of them.
+	Muted   bool
+	MutedBy []string
+}
+
+// NotificationAlert is an alert of a notification.
+type NotificationAlert struct {
+	Labels   map[string]string
+	Status   string
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// notificationSimulator replays the alerts of a backtesting through the routing tree of an Alertmanager configuration.
+// It mirrors the aggregation groups and the deduplication of the Alertmanager, but not inhibition rules.
+type notificationSimulator struct {
+	route     *dispatch.Route
+	intervals map[string][]timeinterval.TimeInterval
+
+	groups map[string]*simulatedGroup
+	// notified is the notification log: the alerts that were last notified by each aggregation group.
+	// Same as in the Alertmanager, it outlives the aggregation groups.
+	notified      map[string]*notificationLogEntry
+	notifications []Notification
+}
+
+type simulatedGroup struct {
+	key       string
+	route     *dispatch.Route
+	labels    model.LabelSet
+	alerts    map[model.Fingerprint]*simulatedAlert
+	nextFlush time.Time
+}
+
+type simulatedAlert struct {
+	labels   model.LabelSet
+	startsAt time.Time
+	endsAt   time.Time
+	resolved bool
+}
+
+type notificationLogEntry struct {
+	at       time.Time
+	firing   map[model.Fingerprint]struct{}
+	resolved map[model.Fingerprint]struct{}
+}
+
+func newNotificationSimulator(cfg apimodels.Config) (*notificationSimulator, error) {
+	if cfg.Route == nil {
+		return nil, fmt.Errorf("%w: the Alertmanager configuration does not have a root route", ErrInvalidInputData)
+	}
+
+	intervals := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals)+len(cfg.TimeIntervals))
+	for _, ti := range cfg.MuteTimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	for _, ti := range cfg.TimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+
+	return &notificationSimulator{
+		route:     dispatch.NewRoute(cfg.Route.AsAMRoute(), nil),
+		intervals: intervals,
+		groups:    make(map[string]*simulatedGroup),
+		notified:  make(map[string]*notificationLogEntry),
+	}, nil
+}
+
+// Process flushes the aggregation groups that are due before now, and then routes the alerts of the given states.
+// Active states are sent as firing alerts, and states that stopped being active are sent as resolved alerts, same as
+// the scheduler does.
+func (s *notificationSimulator) Process(now time.Time, transitions []state.StateTransition) {
+	s.flushUntil(now)
+
+	for _, t := range transitions {
+		active := isActive(t.State.State)
+		if !active && !isActive(t.PreviousState) {
+			continue
+		}
+		alert := state.StateToPostableAlert(t, nil)
+		labels := make(model.LabelSet, len(alert.Labels))
+		for k, v := range alert.Labels {
+			labels[model.LabelName(k)] = model.LabelValue(v)
+		}
+		sa := &simulatedAlert{
+			labels:   labels,
+			startsAt: t.State.StartsAt,
+			resolved: !active,
+		}
+		if !active {
+			sa.endsAt = now
+		}
+
+		for _, r := range s.route.Match(labels) {
+			s.insert(now, r, sa)
+		}
+	}
+}
+
+// Finish flushes the aggregation groups that are due before the end of the backtesting, and returns the notifications.
+func (s *notificationSimulator) Finish(to time.Time) []Notification {
+	s.flushUntil(to)
+	return s.notifications
+}
+
+func (s *notificationSimulator) insert(now time.Time, r *dispatch.Route, alert *simulatedAlert) {
+	groupLabels := make(model.LabelSet)
+	for ln, lv := range alert.labels {
+		if _, ok := r.RouteOpts.GroupBy[ln]; ok || r.RouteOpts.GroupByAll {
+			groupLabels[ln] = lv
+		}
+	}
+	key := fmt.Sprintf("%s:%s", r.Key(), groupLabels)
+
+	g, ok := s.groups[key]
+	if !ok {
+		// A new aggregation group waits for the group wait, unless the alert started long enough ago.
+		next := now.Add(r.RouteOpts.GroupWait)
+		if alert.startsAt.Add(r.RouteOpts.GroupWait).Before(now) {
+			next = now
+		}
+		g = &simulatedGroup{
+			key:       key,
+			route:     r,
+			labels:    groupLabels,
+			alerts:    make(map[model.Fingerprint]*simulatedAlert),
+			nextFlush: next,
+		}
+		s.groups[key] = g
+	}
+	g.alerts[alert.labels.Fingerprint()] = alert
+}
+
+// flushUntil flushes the aggregation groups in the order of their flush times, until the given time.
+func (s *notificationSimulator) flushUntil(until time.Time) {
+	for {
+		var next *simulatedGroup
+		for _, g := range s.groups {
+			if g.nextFlush.After(until) {
+				continue
+			}
+			if next == nil || g.nextFlush.Before(next.nextFlush) || (g.nextFlush.Equal(next.nextFlush) && g.key < next.key) {
+				next = g
+			}
+		}
+		if next == nil {
+			return
+		}
+		s.flush(next)
+	}
+}
+
+func (s *notificationSimulator) flush(g *simulatedGroup) {
+	now := g.nextFlush
+	g.nextFlush = now.Add(g.route.RouteOpts.GroupInterval)
+
+	firing := make(map[model.Fingerprint]struct{})
+	resolved := make(map[model.Fingerprint]struct{})
+	for fp, a := range g.alerts {
+		if a.resolved {
+			resolved[fp] = struct{}{}
+		} else {
+			firing[fp] = struct{}{}
+		}
+	}
+
+	entry := s.notified[g.key]
+	if needsUpdate(entry, firing, resolved, g.route.RouteOpts.RepeatInterval, now) {
+		n := Notification{
+			Time:        now,
+			Receiver:    g.route.RouteOpts.Receiver,
+			GroupKey:    g.key,
+			GroupLabels: labelSetToMap(g.labels),
+			Alerts:      notificationAlerts(g.alerts),
+		}
+		n.MutedBy, n.Muted = s.muted(g.route, now)
+		s.notifications = append(s.notifications, n)
+		// Muted notifications are not written to the notification log, and will be sent at the next flush if still needed.
+		if !n.Muted {
+			s.notified[g.key] = &notificationLogEntry{at: now, firing: firing, resolved: resolved}
+		}
+	}
+
+	// Resolved alerts are removed after the flush, and the group is deleted once empty.
+	for fp, a := range g.alerts {
+		if a.resolved {
+			delete(g.alerts, fp)
+		}
+	}
+	if len(g.alerts) == 0 {
+		delete(s.groups, g.key)
+	}
+}
+
+// muted returns whether notifications of the route are muted at the given time, and the names of the time intervals
+// responsible for it.
+func (s *notificationSimulator) muted(r *dispatch.Route, now time.Time) ([]string, bool) {
+	var mutedBy []string
+	for _, name := range r.RouteOpts.MuteTimeIntervals {
+		if s.contains(name, now) {
+			mutedBy = append(mutedBy, name)
+		}
+	}
+	if len(mutedBy) > 0 {
+		return mutedBy, true
+	}
+
+	if len(r.RouteOpts.ActiveTimeIntervals) == 0 {
+		return nil, false
+	}
+	for _, name := range r.RouteOpts.ActiveTimeIntervals {
+		if s.contains(name, now) {
+			return nil, false
+		}
+	}
+	return r.RouteOpts.ActiveTimeIntervals, true
+}
+
+func (s *notificationSimulator) contains(name string, now time.Time) bool {
+	for _, ti := range s.intervals[name] {
+		if ti.ContainsTime(now.UTC()) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsUpdate returns whether a notification needs to be sent. It follows the deduplication of the Alertmanager,
+// assuming that the receivers send resolved notifications.
+func needsUpdate(entry *notificationLogEntry, firing, resolved map[model.Fingerprint]struct{}, repeat time.Duration, now time.Time) bool {
+	// The group was never notified, so only notify if there are firing alerts.
+	if entry == nil {
+		return len(firing) > 0
+	}
+	if !isSubset(firing, entry.firing) {
+		return true
+	}
+	// All alerts are resolved. Notify only if the receiver was notified about firing alerts.
+	if len(firing) == 0 {
+		return len(entry.firing) > 0
+	}
+	if !isSubset(resolved, entry.resolved) {
+		return true
+	}
+	return entry.at.Before(now.Add(-repeat))
+}
+
+func isSubset(subset, set map[model.Fingerprint]struct{}) bool {
+	for fp := range subset {
+		if _, ok := set[fp]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func isActive(s eval.State) bool {
+	return s == eval.Alerting || s == eval.NoData || s == eval.Error
+}
+
+func notificationAlerts(alerts map[model.Fingerprint]*simulatedAlert) []NotificationAlert {
+	result := make([]NotificationAlert, 0, len(alerts))
+	for _, a := range alerts {
+		status := AlertStatusFiring
+		if a.resolved {
+			status = AlertStatusResolved
+		}
+		result = append(result, NotificationAlert{
+			Labels:   labelSetToMap(a.labels),
+			Status:   status,
+			StartsAt: a.startsAt,
+			EndsAt:   a.endsAt,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return model.LabelsToSignature(result[i].Labels) < model.LabelsToSignature(result[j].Labels)
+	})
+	return result
+}
+
+func labelSetToMap(ls model.LabelSet) map[string]string {
+	m := make(map[string]string, len(ls))
+	for k, v := range ls {
+		m[string(k)] = string(v)
+	}
+	return m
+}
@@ -0,0 +1,195 @@
+package backtesting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestNotificationSimulator(t *testing.T) {
+	from := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+
+	t.Run("should fail without root route", func(t *testing.T) {
+		_, err := newNotificationSimulator(apimodels.Config{})
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+
+	t.Run("should notify firing alerts after group wait and resolved alerts at the next group interval", func(t *testing.T) {
+		s := createTestSimulator(t)
+		a := data.Labels{"alertname": "test", "instance": "a"}
+
+		for i := 0; i < 10; i++ {
+			s.Process(from.Add(time.Duration(i)*time.Minute), []state.StateTransition{transitionAt(eval.Alerting, eval.Alerting, a, from)})
+		}
+		s.Process(from.Add(10*time.Minute), []state.StateTransition{transitionAt(eval.Alerting, eval.Normal, a, from)})
+		notifications := s.Finish(from.Add(time.Hour))
+
+		require.Len(t, notifications, 2)
+		require.Equal(t, from.Add(30*time.Second), notifications[0].Time)
+		require.Equal(t, "default", notifications[0].Receiver)
+		require.Equal(t, map[string]string{"alertname": "test"}, notifications[0].GroupLabels)
+		require.Equal(t, []NotificationAlert{{Labels: map[string]string(a), Status: AlertStatusFiring, StartsAt: from}}, notifications[0].Alerts)
+		require.False(t, notifications[0].Muted)
+
+		require.Equal(t, from.Add(10*time.Minute+30*time.Second), notifications[1].Time)
+		require.Equal(t, AlertStatusResolved, notifications[1].Alerts[0].Status)
+		require.Equal(t, from.Add(10*time.Minute), notifications[1].Alerts[0].EndsAt)
+	})
+
+	t.Run("should group alerts by the labels of the route", func(t *testing.T) {
+		s := createTestSimulator(t)
+
+		s.Process(from, []state.StateTransition{
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"alertname": "test", "instance": "a"}, from),
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"alertname": "test", "instance": "b"}, from),
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"alertname": "other", "instance": "a"}, from),
+			// Inactive alerts are not sent.
+			transitionAt(eval.Normal, eval.Pending, data.Labels{"alertname": "pending", "instance": "a"}, from),
+		})
+		notifications := s.Finish(from.Add(time.Minute))
+
+		require.Len(t, notifications, 2)
+		require.Equal(t, map[string]string{"alertname": "other"}, notifications[0].GroupLabels)
+		require.Len(t, notifications[0].Alerts, 1)
+		require.Equal(t, map[string]string{"alertname": "test"}, notifications[1].GroupLabels)
+		require.Len(t, notifications[1].Alerts, 2)
+	})
+
+	t.Run("should notify again after the repeat interval", func(t *testing.T) {
+		s := createTestSimulator(t)
+		a := data.Labels{"alertname": "test"}
+
+		for i := 0; i < 90; i++ {
+			s.Process(from.Add(time.Duration(i)*time.Minute), []state.StateTransition{transitionAt(eval.Alerting, eval.Alerting, a, from)})
+		}
+		notifications := s.Finish(from.Add(90 * time.Minute))
+
+		require.Len(t, notifications, 2)
+		require.Equal(t, from.Add(30*time.Second), notifications[0].Time)
+		require.Equal(t, from.Add(time.Hour+5*time.Minute+30*time.Second), notifications[1].Time)
+	})
+
+	t.Run("should mute notifications during mute timings", func(t *testing.T) {
+		s := createTestSimulator(t)
+		a := data.Labels{"alertname": "test", "team": "ops"}
+		start := from.Add(-110 * time.Minute) // 00:10
+
+		for i := 0; i < 60; i++ {
+			s.Process(start.Add(time.Duration(i)*time.Minute), []state.StateTransition{transitionAt(eval.Alerting, eval.Alerting, a, start)})
+		}
+		notifications := s.Finish(start.Add(time.Hour))
+
+		require.Equal(t, "ops", notifications[0].Receiver)
+		require.True(t, notifications[0].Muted)
+		require.Equal(t, []string{"first-hour"}, notifications[0].MutedBy)
+
+		last := notifications[len(notifications)-1]
+		require.False(t, last.Muted)
+		require.Equal(t, time.Date(2024, 1, 1, 1, 0, 30, 0, time.UTC), last.Time)
+		for _, n := range notifications[:len(notifications)-1] {
+			require.True(t, n.Muted)
+		}
+	})
+}
+
+func TestEngineTestNotifications(t *testing.T) {
+	evaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return eval.Results{}, nil
+		},
+	}
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	from := time.Date(2024, 1, 1, 2, 0, 0, 0, time.UTC)
+	lbls := data.Labels{"alertname": "test"}
+	manager := &fakeStateManager{
+		stateCallback: func(now time.Time) []state.StateTransition {
+			return []state.StateTransition{transitionAt(eval.Alerting, eval.Alerting, lbls, from)}
+		},
+	}
+	engine := &Engine{
+		createStateManager: func() stateManager {
+			return manager
+		},
+	}
+	gen := models.RuleGen
+	rule := gen.With(gen.WithInterval(time.Minute)).GenerateRef()
+
+	frame, notifications, err := engine.TestNotifications(context.Background(), nil, rule, from, from.Add(10*time.Minute), nil, testAlertmanagerConfig())
+	require.NoError(t, err)
+	require.Equal(t, 10, frame.Rows())
+	require.Len(t, notifications, 1)
+	require.Equal(t, "default", notifications[0].Receiver)
+}
+
+func createTestSimulator(t *testing.T) *notificationSimulator {
+	t.Helper()
+	s, err := newNotificationSimulator(testAlertmanagerConfig())
+	require.NoError(t, err)
+	return s
+}
+
+// testAlertmanagerConfig returns a configuration that groups alerts by alertname, and routes the alerts of the ops team
+// to a receiver that is muted during the first hour of the day.
+func testAlertmanagerConfig() apimodels.Config {
+	duration := func(d time.Duration) *model.Duration {
+		md := model.Duration(d)
+		return &md
+	}
+	matcher, _ := labels.NewMatcher(labels.MatchEqual, "team", "ops")
+
+	return apimodels.Config{
+		Route: &apimodels.Route{
+			Receiver:       "default",
+			GroupByStr:     []string{"alertname"},
+			GroupBy:        []model.LabelName{"alertname"},
+			GroupWait:      duration(30 * time.Second),
+			GroupInterval:  duration(5 * time.Minute),
+			RepeatInterval: duration(time.Hour),
+			Routes: []*apimodels.Route{
+				{
+					Receiver:          "ops",
+					ObjectMatchers:    apimodels.ObjectMatchers{matcher},
+					MuteTimeIntervals: []string{"first-hour"},
+				},
+			},
+		},
+		MuteTimeIntervals: []config.MuteTimeInterval{
+			{
+				Name: "first-hour",
+				TimeIntervals: []timeinterval.TimeInterval{
+					{Times: []timeinterval.TimeRange{{StartMinute: 0, EndMinute: 60}}},
+				},
+			},
+		},
+	}
+}
+
+func transitionAt(from, to eval.State, labels data.Labels, startsAt time.Time) state.StateTransition {
+	return state.StateTransition{
+		PreviousState: from,
+		State: &state.State{
+			State:    to,
+			Labels:   labels,
+			StartsAt: startsAt,
+		},
+	}
+}
@@ -356,7 +356,7 @@ func getExprRequest(ctx EvaluationContext, condition models.Condition, dsCacheSe
 
 		// TODO rewrite the code below and remove the mutable component from AlertQuery
 
-		// if the query is command expression and it's a hysteresis, patch it with the current state
+		// if the query is command expression and it's a hysteresis or a recovery, patch it with the current state
 		// it's important to do this before GetModel
 		if ds.Type == expr.DatasourceType {
 			isHysteresis, err := q.IsHysteresisExpression()
@@ -367,10 +367,10 @@ func getExprRequest(ctx EvaluationContext, condition models.Condition, dsCacheSe
 				// make sure we allow hysteresis expressions to be specified only as the alert condition.
 				// This guarantees us that the AlertResultsReader can be correctly applied to the expression tree.
 				if q.RefID != condition.Condition {
-					return nil, fmt.Errorf("recovery threshold '%s' is only allowed to be the alert condition", q.RefID)
+					return nil, fmt.Errorf("recovery threshold or condition '%s' is only allowed to be the alert condition", q.RefID)
 				}
 				if reader != nil {
-					logger.FromContext(ctx.Ctx).Debug("Detected hysteresis threshold command. Populating with the results")
+					logger.FromContext(ctx.Ctx).Debug("Detected hysteresis threshold or recovery command. Populating with the results")
 					err = q.PatchHysteresisExpression(reader.Read())
 					if err != nil {
 						return nil, fmt.Errorf("failed to amend hysteresis command '%s': %w", q.RefID, err)
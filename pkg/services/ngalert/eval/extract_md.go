@@ -87,6 +87,11 @@ func extractValues(frame *data.Frame) map[string]NumberValueCapture {
 		for i, match := range matches {
 			// In classic conditions we use refID and the condition position as a way to distinguish between values.
 			// We can guarantee determinism as conditions are ordered and this order is preserved when marshaling.
+			// Matches of classic conditions evaluated per series only include the conditions that matched,
+			// so their position is taken from the match instead.
+			if match.Condition > 0 {
+				i = match.Condition - 1
+			}
 			refID := fmt.Sprintf("%s%d", frame.RefID, i)
 			v[refID] = NumberValueCapture{
 				Var:    frame.RefID,
@@ -83,6 +83,14 @@ func TestExtractValues(t *testing.T) {
 			"A0": {Var: "A", Labels: data.Labels{"host": "foo"}, Value: util.Pointer(1.0)},
 			"A1": {Var: "A", Labels: data.Labels{"host": "foo"}, Value: util.Pointer(3.0)},
 		},
+	}, {
+		desc: "Classic condition frame evaluated per series uses the position of the condition",
+		inFrame: newMetaFrame([]classic.EvalMatch{
+			{Metric: "B", Labels: data.Labels{"host": "foo"}, Value: util.Pointer(3.0), Condition: 2},
+		}, util.Pointer(1.0), withRefID("A")),
+		values: map[string]NumberValueCapture{
+			"A1": {Var: "A", Labels: data.Labels{"host": "foo"}, Value: util.Pointer(3.0)},
+		},
 	}, {
 		desc: "Nil value",
 		inFrame: newMetaFrame([]NumberValueCapture{
@@ -31,6 +31,9 @@ type Scheduler struct {
 	UpdateSchedulableAlertRulesDuration prometheus.Histogram
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
+	EvaluationsQueued                   *prometheus.GaugeVec
+	EvaluationQueueDuration             *prometheus.HistogramVec
+	EvaluationsDelayed                  *prometheus.CounterVec
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -183,5 +186,33 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "name"},
 		),
+		EvaluationsQueued: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_rule_evaluations_queued",
+				Help:      "The number of rule evaluations waiting for an evaluation budget of the organization or a data source.",
+			},
+			[]string{"org"},
+		),
+		EvaluationQueueDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_rule_evaluation_queue_duration_seconds",
+				Help:      "The time rule evaluations waited for an evaluation budget of the organization or a data source.",
+				Buckets:   []float64{.01, .1, .5, 1, 5, 10, 15, 30, 60, 120},
+			},
+			[]string{"org"},
+		),
+		EvaluationsDelayed: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_rule_evaluations_delayed_total",
+				Help:      "The total number of rule evaluations that were delayed by more than the scheduler interval because of evaluation budgets.",
+			},
+			[]string{"org"},
+		),
 	}
 }
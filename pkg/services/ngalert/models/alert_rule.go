@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"hash/fnv"
+	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -20,6 +22,7 @@ import (
 
 	alertingModels "github.com/grafana/alerting/models"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/cmputil"
@@ -267,6 +270,26 @@ type AlertRule struct {
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// DependsOn contains the UIDs of the rules of the same group that are evaluated before this rule.
+	DependsOn []string `xorm:"depends_on"`
+	// UpdatedBy is the UID of the user who made the last change to the rule, if known.
+	UpdatedBy *UserUID `xorm:"updated_by"`
+}
+
+// UserUID is the UID of the user or service account that made a change.
+type UserUID string
+
+// NewUserUID returns the UID of the user or service account, or nil if the requester is neither.
+func NewUserUID(requester identity.Requester) *UserUID {
+	if requester == nil || requester.IsNil() {
+		return nil
+	}
+	id := requester.GetUID()
+	if !id.IsNamespace(identity.NamespaceUser, identity.NamespaceServiceAccount) || id.ID() == "" {
+		return nil
+	}
+	uid := UserUID(id.ID())
+	return &uid
 }
 
 // Namespaced describes a class of resources that are stored in a specific namespace.
@@ -541,6 +564,10 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.UID != "" && slices.Contains(alertRule.DependsOn, alertRule.UID) {
+		return fmt.Errorf("%w: rule cannot depend on itself", ErrAlertRuleFailedValidation)
+	}
+
 	if len(alertRule.Labels) > 0 {
 		for label := range alertRule.Labels {
 			if _, ok := LabelsUserCannotSpecify[label]; ok {
@@ -580,6 +607,11 @@ func validateRecordingRuleFields(rule *AlertRule) error {
 	if !prommodels.IsValidMetricName(metricName) {
 		return fmt.Errorf("%w: %s", ErrAlertRuleFailedValidation, "metric name for recording rule must be a valid Prometheus metric name")
 	}
+	if rule.Record.Target != nil {
+		if err := rule.Record.Target.Validate(); err != nil {
+			return fmt.Errorf("%w: invalid recording rule target: %s", ErrAlertRuleFailedValidation, err.Error())
+		}
+	}
 	return nil
 }
 
@@ -636,6 +668,38 @@ type AlertRuleVersion struct {
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings `xorm:"notification_settings"` // we use slice to workaround xorm mapping that does not serialize a struct to JSON unless it's a slice
+	// DependsOn contains the UIDs of the rules of the same group that are evaluated before this rule.
+	DependsOn []string `xorm:"depends_on"`
+	// CreatedBy is the UID of the user who made the change that created this version, if known.
+	CreatedBy *UserUID `xorm:"created_by"`
+}
+
+// AlertRuleFromVersion returns the alert rule as it was at the given version.
+// The returned rule does not have an ID because it is not stored in the version.
+func AlertRuleFromVersion(v *AlertRuleVersion) AlertRule {
+	return AlertRule{
+		OrgID:                v.RuleOrgID,
+		UID:                  v.RuleUID,
+		NamespaceUID:         v.RuleNamespaceUID,
+		RuleGroup:            v.RuleGroup,
+		RuleGroupIndex:       v.RuleGroupIndex,
+		Version:              v.Version,
+		Updated:              v.Created,
+		UpdatedBy:            v.CreatedBy,
+		Title:                v.Title,
+		Condition:            v.Condition,
+		Data:                 v.Data,
+		IntervalSeconds:      v.IntervalSeconds,
+		Record:               v.Record,
+		NoDataState:          v.NoDataState,
+		ExecErrState:         v.ExecErrState,
+		For:                  v.For,
+		Annotations:          v.Annotations,
+		Labels:               v.Labels,
+		IsPaused:             v.IsPaused,
+		NotificationSettings: v.NotificationSettings,
+		DependsOn:            v.DependsOn,
+	}
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -644,6 +708,12 @@ type GetAlertRuleByUIDQuery struct {
 	OrgID int64
 }
 
+// GetAlertRuleVersionsQuery is the query for retrieving the versions of an alert rule by UID and organisation ID.
+type GetAlertRuleVersionsQuery struct {
+	UID   string
+	OrgID int64
+}
+
 // GetAlertRulesGroupByRuleUIDQuery is the query for retrieving a group of alerts by UID of a rule that belongs to that group
 type GetAlertRulesGroupByRuleUIDQuery struct {
 	UID   string
@@ -819,6 +889,53 @@ type Record struct {
 	Metric string
 	// From contains a query RefID, indicating which expression node is the output of the recording rule.
 	From string
+	// Target indicates where the results are written to. If empty, they are written to the remote write endpoint
+	// configured for the instance.
+	Target *RecordTarget `json:",omitempty"`
+}
+
+// RecordTargetType is the type of the target the results of a recording rule are written to.
+type RecordTargetType string
+
+const (
+	// RecordTargetRemoteWrite writes the results to the remote write endpoint configured for the instance.
+	RecordTargetRemoteWrite RecordTargetType = "remote_write"
+	// RecordTargetDatasource writes the results to a data source. The supported data sources are SQL data sources,
+	// InfluxDB and Loki.
+	RecordTargetDatasource RecordTargetType = "datasource"
+	// RecordTargetLocal writes the results to the embedded time series database.
+	RecordTargetLocal RecordTargetType = "local"
+)
+
+// RecordTarget contains the target the results of a recording rule are written to.
+type RecordTarget struct {
+	Type RecordTargetType
+	// DatasourceUID is the UID of the data source the results are written to. Only for the datasource target.
+	DatasourceUID string `json:",omitempty"`
+	// Table is the table the results are inserted into. Only for SQL data sources.
+	Table string `json:",omitempty"`
+}
+
+var recordTableRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// Validate checks that the target is well-formed. It does not check that the data source exists, or that it supports writes.
+func (t *RecordTarget) Validate() error {
+	switch t.Type {
+	case RecordTargetRemoteWrite, RecordTargetLocal:
+		if t.DatasourceUID != "" || t.Table != "" {
+			return fmt.Errorf("data source and table can only be set for the %s target", RecordTargetDatasource)
+		}
+	case RecordTargetDatasource:
+		if t.DatasourceUID == "" {
+			return errors.New("data source UID must be set for the datasource target")
+		}
+		if t.Table != "" && !recordTableRegex.MatchString(t.Table) {
+			return fmt.Errorf("invalid table name %q", t.Table)
+		}
+	default:
+		return fmt.Errorf("unknown target type %q", t.Type)
+	}
+	return nil
 }
 
 func (r *Record) Fingerprint() data.Fingerprint {
@@ -833,5 +950,10 @@ func (r *Record) Fingerprint() data.Fingerprint {
 
 	writeString(r.Metric)
 	writeString(r.From)
+	if r.Target != nil {
+		writeString(string(r.Target.Type))
+		writeString(r.Target.DatasourceUID)
+		writeString(r.Target.Table)
+	}
 	return data.Fingerprint(h.Sum64())
 }
@@ -470,6 +470,11 @@ func TestDiff(t *testing.T) {
 			assert.Len(t, diff, 1)
 			difCnt++
 		}
+		if rule1.UpdatedBy != rule2.UpdatedBy {
+			diff := diffs.GetDiffsForField("UpdatedBy")
+			assert.Len(t, diff, 1)
+			difCnt++
+		}
 		if rule1.RuleGroup != rule2.RuleGroup {
 			diff := diffs.GetDiffsForField("RuleGroup")
 			assert.Len(t, diff, 1)
@@ -877,3 +882,55 @@ func TestTimeRangeYAML(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, yamlRaw, string(serialized))
 }
+
+func TestRecordTargetValidate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		target RecordTarget
+		expErr string
+	}{
+		{
+			name:   "remote write",
+			target: RecordTarget{Type: RecordTargetRemoteWrite},
+		},
+		{
+			name:   "local",
+			target: RecordTarget{Type: RecordTargetLocal},
+		},
+		{
+			name:   "datasource with table",
+			target: RecordTarget{Type: RecordTargetDatasource, DatasourceUID: "ds", Table: "public.recorded_metrics"},
+		},
+		{
+			name:   "unknown type",
+			target: RecordTarget{Type: "unknown"},
+			expErr: "unknown target type",
+		},
+		{
+			name:   "datasource without UID",
+			target: RecordTarget{Type: RecordTargetDatasource},
+			expErr: "data source UID must be set",
+		},
+		{
+			name:   "datasource with invalid table",
+			target: RecordTarget{Type: RecordTargetDatasource, DatasourceUID: "ds", Table: "metrics; DROP TABLE users"},
+			expErr: "invalid table name",
+		},
+		{
+			name:   "local with data source",
+			target: RecordTarget{Type: RecordTargetLocal, DatasourceUID: "ds"},
+			expErr: "can only be set for the datasource target",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.target.Validate()
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrRuleDependencyCycle is returned when the dependencies between the rules of a group form a cycle.
+var ErrRuleDependencyCycle = errors.New("rule dependencies form a cycle")
+
+// dependencyQueryFields are the fields of query models that can reference the metrics recorded by recording rules.
+var dependencyQueryFields = []string{"expr", "query", "rawSql"}
+
+// RuleDependency is a dependency of a rule on another rule of the same group.
+type RuleDependency struct {
+	// UID of the rule that must be evaluated first.
+	UID string
+	// Inferred is true if the dependency is not declared but inferred from the metric recorded by the rule.
+	Inferred bool
+}
+
+// RuleDependencies returns the dependencies of the rule on the other rules of the group. Use GroupDependencies to get
+// the dependencies of several rules of the same group.
+func RuleDependencies(rule *AlertRule, group []*AlertRule) []RuleDependency {
+	return NewGroupDependencies(group).Of(rule)
+}
+
+// GroupDependencies resolves the dependencies between the rules of a group. The queries of the rules are decoded and the
+// patterns of the recorded metrics are compiled once for the group, rather than for every pair of rules.
+// It is safe for concurrent use.
+type GroupDependencies struct {
+	group []*AlertRule
+	// queries contains the texts of the queries of the rules of the group that can reference metrics, by rule UID.
+	queries map[string][]string
+	// patterns contains the pattern of the metric recorded by each rule of the group, or nil if the rule does not record
+	// a metric.
+	patterns []*regexp.Regexp
+}
+
+// NewGroupDependencies decodes the queries of the rules of the group and compiles the patterns of their recorded metrics.
+func NewGroupDependencies(group []*AlertRule) *GroupDependencies {
+	d := &GroupDependencies{
+		group:    group,
+		queries:  make(map[string][]string, len(group)),
+		patterns: make([]*regexp.Regexp, len(group)),
+	}
+	for i, rule := range group {
+		if rule.UID != "" {
+			d.queries[rule.UID] = dependencyQueries(rule)
+		}
+		if rule.Record != nil {
+			d.patterns[i] = metricPattern(rule.Record.Metric)
+		}
+	}
+	return d
+}
+
+// Of returns the dependencies of the rule on the other rules of the group: the rules it declares in DependsOn, and the
+// recording rules whose metric is referenced by its queries. Declared dependencies on rules that are not in the group
+// are ignored.
+func (d *GroupDependencies) Of(rule *AlertRule) []RuleDependency {
+	queries, ok := d.queries[rule.UID]
+	if !ok {
+		queries = dependencyQueries(rule)
+	}
+	var result []RuleDependency
+	for i, other := range d.group {
+		if other == rule || other.UID == "" || other.UID == rule.UID {
+			continue
+		}
+		if slices.Contains(rule.DependsOn, other.UID) {
+			result = append(result, RuleDependency{UID: other.UID})
+			continue
+		}
+		if d.patterns[i] != nil && slices.ContainsFunc(queries, d.patterns[i].MatchString) {
+			result = append(result, RuleDependency{UID: other.UID, Inferred: true})
+		}
+	}
+	return result
+}
+
+// metricPattern returns the pattern that matches the metric in a query, or nil if there is no metric.
+func metricPattern(metric string) *regexp.Regexp {
+	if metric == "" {
+		return nil
+	}
+	re, err := regexp.Compile(`(^|[^a-zA-Z0-9_:])` + regexp.QuoteMeta(metric) + `($|[^a-zA-Z0-9_:])`)
+	if err != nil {
+		return nil
+	}
+	return re
+}
+
+// dependencyQueries returns the texts of the queries of the rule that can reference the metrics recorded by other rules.
+func dependencyQueries(rule *AlertRule) []string {
+	var result []string
+	for i := range rule.Data {
+		q := &rule.Data[i]
+		if isExpr, err := q.IsExpression(); err != nil || isExpr {
+			continue
+		}
+		// The model is decoded without caching it in the query because the rules can be shared between goroutines.
+		var props map[string]any
+		if err := json.Unmarshal(q.Model, &props); err != nil {
+			continue
+		}
+		for _, field := range dependencyQueryFields {
+			if s, ok := props[field].(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// SortByDependencies returns the rules of the group in an order in which every rule comes after the rules it depends
+// on. Rules that do not depend on each other keep their relative order.
+// Returns ErrRuleDependencyCycle if the dependencies form a cycle.
+func SortByDependencies(group []*AlertRule) ([]*AlertRule, error) {
+	return NewGroupDependencies(group).Sort()
+}
+
+// Sort returns the rules of the group in an order in which every rule comes after the rules it depends on, like
+// SortByDependencies.
+func (d *GroupDependencies) Sort() ([]*AlertRule, error) {
+	group := d.group
+	index := make(map[string]int, len(group))
+	for i, rule := range group {
+		if rule.UID != "" {
+			index[rule.UID] = i
+		}
+	}
+
+	// dependents[i] contains the rules that depend on the rule i.
+	dependents := make([][]int, len(group))
+	inDegree := make([]int, len(group))
+	for i, rule := range group {
+		for _, dep := range d.Of(rule) {
+			j := index[dep.UID]
+			dependents[j] = append(dependents[j], i)
+			inDegree[i]++
+		}
+	}
+
+	result := make([]*AlertRule, 0, len(group))
+	ready := make([]int, 0, len(group))
+	for i := range group {
+		if inDegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		i := ready[0]
+		ready = ready[1:]
+		result = append(result, group[i])
+		for _, j := range dependents[i] {
+			inDegree[j]--
+			if inDegree[j] == 0 {
+				ready = append(ready, j)
+				slices.Sort(ready)
+			}
+		}
+	}
+
+	if len(result) < len(group) {
+		titles := make([]string, 0, len(group)-len(result))
+		for i, rule := range group {
+			if inDegree[i] > 0 {
+				titles = append(titles, fmt.Sprintf("%q", rule.Title))
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrRuleDependencyCycle, strings.Join(titles, ", "))
+	}
+	return result, nil
+}
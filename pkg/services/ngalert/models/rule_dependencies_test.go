@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleDependencies(t *testing.T) {
+	promQuery := func(expr string) AlertQuery {
+		return AlertQuery{
+			RefID:         "A",
+			DatasourceUID: "prometheus",
+			Model:         json.RawMessage(`{"expr": "` + expr + `"}`),
+		}
+	}
+
+	recording := RuleGen.With(RuleMuts.WithAllRecordingRules(), RuleMuts.WithMetric("job:errors:rate5m")).GenerateRef()
+	other := RuleGen.GenerateRef()
+
+	t.Run("infers dependency on recorded metric", func(t *testing.T) {
+		rule := RuleGen.With(RuleMuts.WithQuery(promQuery(`sum(job:errors:rate5m{job=\"api\"}) > 1`))).GenerateRef()
+		deps := RuleDependencies(rule, []*AlertRule{recording, other, rule})
+		require.Equal(t, []RuleDependency{{UID: recording.UID, Inferred: true}}, deps)
+	})
+
+	t.Run("does not infer dependency on metric with the same prefix", func(t *testing.T) {
+		rule := RuleGen.With(RuleMuts.WithQuery(promQuery(`job:errors:rate5m_total > 1`))).GenerateRef()
+		require.Empty(t, RuleDependencies(rule, []*AlertRule{recording, other, rule}))
+	})
+
+	t.Run("does not infer dependency from expressions", func(t *testing.T) {
+		rule := RuleGen.With(RuleMuts.WithQuery(AlertQuery{
+			RefID:         "B",
+			DatasourceUID: "__expr__",
+			Model:         json.RawMessage(`{"type": "math", "expression": "job:errors:rate5m"}`),
+		})).GenerateRef()
+		require.Empty(t, RuleDependencies(rule, []*AlertRule{recording, rule}))
+	})
+
+	t.Run("returns declared dependencies", func(t *testing.T) {
+		rule := RuleGen.With(RuleMuts.WithDependsOn(other.UID, "missing")).GenerateRef()
+		deps := RuleDependencies(rule, []*AlertRule{recording, other, rule})
+		require.Equal(t, []RuleDependency{{UID: other.UID}}, deps)
+	})
+
+	t.Run("group dependencies resolve the dependencies of every rule of the group", func(t *testing.T) {
+		rule := RuleGen.With(RuleMuts.WithQuery(promQuery(`job:errors:rate5m > 1`)), RuleMuts.WithDependsOn(other.UID)).GenerateRef()
+		unrelated := RuleGen.With(RuleMuts.WithQuery(promQuery(`up == 0`))).GenerateRef()
+		group := []*AlertRule{recording, other, rule, unrelated}
+		deps := NewGroupDependencies(group)
+
+		expected := []RuleDependency{{UID: recording.UID, Inferred: true}, {UID: other.UID}}
+		require.Equal(t, expected, deps.Of(rule))
+		require.Empty(t, deps.Of(unrelated))
+		// A copy of a rule of the group, like the API converts, gets the same dependencies.
+		require.Equal(t, expected, deps.Of(CopyRule(rule)))
+		// A rule that is not in the group is resolved against the rules of the group.
+		require.Equal(t, []RuleDependency{{UID: recording.UID, Inferred: true}},
+			deps.Of(RuleGen.With(RuleMuts.WithQuery(promQuery(`job:errors:rate5m`))).GenerateRef()))
+	})
+}
+
+func TestSortByDependencies(t *testing.T) {
+	a := RuleGen.With(RuleMuts.WithTitle("a")).GenerateRef()
+	b := RuleGen.With(RuleMuts.WithTitle("b")).GenerateRef()
+	c := RuleGen.With(RuleMuts.WithTitle("c")).GenerateRef()
+
+	t.Run("keeps order of independent rules", func(t *testing.T) {
+		sorted, err := SortByDependencies([]*AlertRule{a, b, c})
+		require.NoError(t, err)
+		require.Equal(t, []*AlertRule{a, b, c}, sorted)
+	})
+
+	t.Run("sorts rules after their dependencies", func(t *testing.T) {
+		a := CopyRule(a, RuleMuts.WithDependsOn(c.UID))
+		b := CopyRule(b, RuleMuts.WithDependsOn(a.UID))
+		sorted, err := SortByDependencies([]*AlertRule{a, b, c})
+		require.NoError(t, err)
+		require.Equal(t, []*AlertRule{c, a, b}, sorted)
+	})
+
+	t.Run("returns error if dependencies form a cycle", func(t *testing.T) {
+		a := CopyRule(a, RuleMuts.WithDependsOn(b.UID))
+		b := CopyRule(b, RuleMuts.WithDependsOn(a.UID))
+		_, err := SortByDependencies([]*AlertRule{a, b, c})
+		require.True(t, errors.Is(err, ErrRuleDependencyCycle))
+		require.ErrorContains(t, err, `"a", "b"`)
+	})
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+// SilenceTemplate is a named, reusable definition of a silence. Templates are shared by all users of an organization and
+// can be used to create one-off silences or silence schedules.
+type SilenceTemplate struct {
+	UID      string        `json:"uid"`
+	Name     string        `json:"name"`
+	Comment  string        `json:"comment,omitempty"`
+	Matchers amv2.Matchers `json:"matchers"`
+	// Duration is the default duration of the silences created from the template.
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Validate checks that the template has a name and valid matchers.
+func (t SilenceTemplate) Validate() error {
+	if t.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	if t.Duration < 0 {
+		return errors.New("duration must not be negative")
+	}
+	return validateSilenceMatchers(t.Matchers)
+}
+
+// SilenceFrequency is the unit of the interval between two occurrences of a recurring silence.
+type SilenceFrequency string
+
+const (
+	SilenceFrequencyDaily  SilenceFrequency = "daily"
+	SilenceFrequencyWeekly SilenceFrequency = "weekly"
+)
+
+// SilenceRecurrence describes how a silence schedule repeats.
+type SilenceRecurrence struct {
+	Frequency SilenceFrequency `json:"frequency"`
+	// Interval is the number of days or weeks between two occurrences. Zero means every day or week.
+	Interval int `json:"interval,omitempty"`
+	// Until is the time after which no occurrence starts. Nil means the schedule repeats forever.
+	Until *time.Time `json:"until,omitempty"`
+	// Timezone is the IANA name of the location the occurrences are calculated in, so that they keep the same wall-clock
+	// time across daylight saving time changes. Empty means UTC.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// SilenceSchedule is a silence that starts in the future and, optionally, repeats. The Alertmanager silence of an
+// occurrence is created ahead of time, one occurrence at a time.
+type SilenceSchedule struct {
+	UID string `json:"uid"`
+	// TemplateUID is the UID of the template the matchers and the comment were copied from, if any.
+	TemplateUID string        `json:"templateUid,omitempty"`
+	Matchers    amv2.Matchers `json:"matchers"`
+	Comment     string        `json:"comment"`
+	CreatedBy   string        `json:"createdBy"`
+	// StartsAt and EndsAt are the bounds of the first occurrence.
+	StartsAt   time.Time          `json:"startsAt"`
+	EndsAt     time.Time          `json:"endsAt"`
+	Recurrence *SilenceRecurrence `json:"recurrence,omitempty"`
+
+	// SilenceID is the ID of the Alertmanager silence created for the occurrence that starts at SilenceStartsAt.
+	SilenceID       string    `json:"silenceId,omitempty"`
+	SilenceStartsAt time.Time `json:"silenceStartsAt,omitempty"`
+}
+
+// Validate checks that the schedule describes at least one valid occurrence.
+func (s SilenceSchedule) Validate() error {
+	if err := validateSilenceMatchers(s.Matchers); err != nil {
+		return err
+	}
+	if s.Comment == "" {
+		return errors.New("comment must not be empty")
+	}
+	if s.CreatedBy == "" {
+		return errors.New("createdBy must not be empty")
+	}
+	if s.StartsAt.IsZero() || s.EndsAt.IsZero() {
+		return errors.New("startsAt and endsAt must be set")
+	}
+	if !s.EndsAt.After(s.StartsAt) {
+		return errors.New("endsAt must be after startsAt")
+	}
+	if s.Recurrence == nil {
+		return nil
+	}
+	r := s.Recurrence
+	if r.Frequency != SilenceFrequencyDaily && r.Frequency != SilenceFrequencyWeekly {
+		return fmt.Errorf("unsupported frequency '%s', must be one of: %s, %s", r.Frequency, SilenceFrequencyDaily, SilenceFrequencyWeekly)
+	}
+	if r.Interval < 0 {
+		return errors.New("interval must not be negative")
+	}
+	if r.Until != nil && r.Until.Before(s.StartsAt) {
+		return errors.New("until must not be before startsAt")
+	}
+	if _, err := time.LoadLocation(r.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone '%s': %w", r.Timezone, err)
+	}
+	if s.EndsAt.Sub(s.StartsAt) > r.period() {
+		return errors.New("the duration of an occurrence must not be longer than the interval between two occurrences")
+	}
+	return nil
+}
+
+// NextOccurrence returns the bounds of the occurrence of the schedule that is active at the given time or, if there is
+// none, of the next one. It returns false if there is no such occurrence.
+func (s SilenceSchedule) NextOccurrence(now time.Time) (time.Time, time.Time, bool) {
+	if s.Recurrence == nil {
+		return s.StartsAt, s.EndsAt, s.EndsAt.After(now)
+	}
+
+	loc, err := time.LoadLocation(s.Recurrence.Timezone)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	first := s.StartsAt.In(loc)
+	duration := s.EndsAt.Sub(s.StartsAt)
+	days := s.Recurrence.days()
+
+	// Count the calendar days since the first occurrence to skip the elapsed occurrences, then move forward to the first
+	// occurrence that has not ended yet.
+	n := 0
+	if now.After(first) {
+		n = calendarDaysBetween(first, now.In(loc))/days - 1
+		if n < 0 {
+			n = 0
+		}
+	}
+	start := first.AddDate(0, 0, n*days)
+	for !start.Add(duration).After(now) {
+		n++
+		start = first.AddDate(0, 0, n*days)
+	}
+
+	if s.Recurrence.Until != nil && start.After(*s.Recurrence.Until) {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, start.Add(duration), true
+}
+
+// days returns the number of days between two occurrences.
+func (r SilenceRecurrence) days() int {
+	interval := r.Interval
+	if interval == 0 {
+		interval = 1
+	}
+	if r.Frequency == SilenceFrequencyWeekly {
+		return 7 * interval
+	}
+	return interval
+}
+
+// period returns the shortest duration between two occurrences.
+func (r SilenceRecurrence) period() time.Duration {
+	// A day can be one hour shorter because of daylight saving time.
+	return time.Duration(r.days())*24*time.Hour - time.Hour
+}
+
+// calendarDaysBetween returns the number of calendar days between the dates of from and to, in their own location.
+func calendarDaysBetween(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate) / (24 * time.Hour))
+}
+
+func validateSilenceMatchers(matchers amv2.Matchers) error {
+	if len(matchers) == 0 {
+		return errors.New("at least one matcher is required")
+	}
+	for _, m := range matchers {
+		if m == nil || m.Name == nil || *m.Name == "" || m.Value == nil || m.IsRegex == nil {
+			return errors.New("matchers must have a name, a value and isRegex set")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestSilenceSchedule_NextOccurrence(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	startsAt := time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)
+	until := startsAt.AddDate(0, 0, 14)
+
+	testCases := []struct {
+		name       string
+		recurrence *SilenceRecurrence
+		startsAt   time.Time
+		now        time.Time
+		expStart   time.Time
+		expOK      bool
+	}{
+		{
+			name:     "one-off before start",
+			startsAt: startsAt,
+			now:      startsAt.Add(-time.Hour),
+			expStart: startsAt,
+			expOK:    true,
+		},
+		{
+			name:     "one-off during occurrence",
+			startsAt: startsAt,
+			now:      startsAt.Add(time.Hour),
+			expStart: startsAt,
+			expOK:    true,
+		},
+		{
+			name:     "one-off after end",
+			startsAt: startsAt,
+			now:      startsAt.Add(3 * time.Hour),
+		},
+		{
+			name:       "daily before first occurrence",
+			recurrence: &SilenceRecurrence{Frequency: SilenceFrequencyDaily},
+			startsAt:   startsAt,
+			now:        startsAt.AddDate(0, 0, -5),
+			expStart:   startsAt,
+			expOK:      true,
+		},
+		{
+			name:       "daily during occurrence",
+			recurrence: &SilenceRecurrence{Frequency: SilenceFrequencyDaily},
+			startsAt:   startsAt,
+			now:        startsAt.AddDate(0, 0, 10).Add(time.Hour),
+			expStart:   startsAt.AddDate(0, 0, 10),
+			expOK:      true,
+		},
+		{
+			name:       "daily between occurrences",
+			recurrence: &SilenceRecurrence{Frequency: SilenceFrequencyDaily},
+			startsAt:   startsAt,
+			now:        startsAt.AddDate(0, 0, 10).Add(3 * time.Hour),
+			expStart:   startsAt.AddDate(0, 0, 11),
+			expOK:      true,
+		},
+		{
+			name:       "every two weeks",
+			recurrence: &SilenceRecurrence{Frequency: SilenceFrequencyWeekly, Interval: 2},
+			startsAt:   startsAt,
+			now:        startsAt.AddDate(0, 0, 15),
+			expStart:   startsAt.AddDate(0, 0, 28),
+			expOK:      true,
+		},
+		{
+			name:       "last occurrence before until",
+			recurrence: &SilenceRecurrence{Frequency: SilenceFrequencyWeekly, Until: &until},
+			startsAt:   startsAt,
+			now:        startsAt.AddDate(0, 0, 8),
+			expStart:   startsAt.AddDate(0, 0, 14),
+			expOK:      true,
+		},
+		{
+			name:       "no occurrence after until",
+			recurrence: &SilenceRecurrence{Frequency: SilenceFrequencyWeekly, Until: &until},
+			startsAt:   startsAt,
+			now:        startsAt.AddDate(0, 0, 15),
+		},
+		{
+			name:       "keeps the wall-clock time across daylight saving time changes",
+			recurrence: &SilenceRecurrence{Frequency: SilenceFrequencyDaily, Timezone: "Europe/Berlin"},
+			startsAt:   time.Date(2024, 3, 30, 22, 0, 0, 0, berlin),
+			now:        time.Date(2024, 3, 31, 12, 0, 0, 0, berlin),
+			expStart:   time.Date(2024, 3, 31, 22, 0, 0, 0, berlin),
+			expOK:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := SilenceSchedule{
+				StartsAt:   tc.startsAt,
+				EndsAt:     tc.startsAt.Add(2 * time.Hour),
+				Recurrence: tc.recurrence,
+			}
+			start, end, ok := s.NextOccurrence(tc.now)
+			require.Equal(t, tc.expOK, ok)
+			if !tc.expOK {
+				return
+			}
+			assert.Truef(t, tc.expStart.Equal(start), "expected start %s, got %s", tc.expStart, start)
+			assert.Truef(t, tc.expStart.Add(2*time.Hour).Equal(end), "expected end %s, got %s", tc.expStart.Add(2*time.Hour), end)
+		})
+	}
+}
+
+func TestSilenceSchedule_Validate(t *testing.T) {
+	startsAt := time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)
+	valid := func() SilenceSchedule {
+		return SilenceSchedule{
+			Matchers: amv2.Matchers{{
+				Name:    util.Pointer("team"),
+				Value:   util.Pointer("a"),
+				IsRegex: util.Pointer(false),
+			}},
+			Comment:    "maintenance",
+			CreatedBy:  "admin",
+			StartsAt:   startsAt,
+			EndsAt:     startsAt.Add(2 * time.Hour),
+			Recurrence: &SilenceRecurrence{Frequency: SilenceFrequencyDaily, Timezone: "Europe/Berlin"},
+		}
+	}
+	require.NoError(t, valid().Validate())
+
+	testCases := []struct {
+		name   string
+		mutate func(s *SilenceSchedule)
+		expErr string
+	}{
+		{
+			name:   "no matchers",
+			mutate: func(s *SilenceSchedule) { s.Matchers = nil },
+			expErr: "at least one matcher is required",
+		},
+		{
+			name:   "no comment",
+			mutate: func(s *SilenceSchedule) { s.Comment = "" },
+			expErr: "comment must not be empty",
+		},
+		{
+			name:   "ends before start",
+			mutate: func(s *SilenceSchedule) { s.EndsAt = s.StartsAt.Add(-time.Hour) },
+			expErr: "endsAt must be after startsAt",
+		},
+		{
+			name:   "unknown frequency",
+			mutate: func(s *SilenceSchedule) { s.Recurrence.Frequency = "monthly" },
+			expErr: "unsupported frequency",
+		},
+		{
+			name:   "unknown timezone",
+			mutate: func(s *SilenceSchedule) { s.Recurrence.Timezone = "Mars/Olympus" },
+			expErr: "invalid timezone",
+		},
+		{
+			name:   "occurrences overlap",
+			mutate: func(s *SilenceSchedule) { s.EndsAt = s.StartsAt.Add(24 * time.Hour) },
+			expErr: "must not be longer than the interval",
+		},
+		{
+			name: "until before start",
+			mutate: func(s *SilenceSchedule) {
+				until := s.StartsAt.Add(-time.Hour)
+				s.Recurrence.Until = &until
+			},
+			expErr: "until must not be before startsAt",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := valid()
+			tc.mutate(&s)
+			require.ErrorContains(t, s.Validate(), tc.expErr)
+		})
+	}
+}
@@ -92,6 +92,12 @@ func (g *AlertRuleGenerator) Generate() AlertRule {
 		ns = append(ns, NotificationSettingsGen()())
 	}
 
+	var updatedBy *UserUID
+	if rand.Int63()%2 == 0 {
+		u := UserUID(util.GenerateShortUID())
+		updatedBy = &u
+	}
+
 	rule := AlertRule{
 		ID:                   0,
 		OrgID:                rand.Int63n(1500) + 1, // Prevent OrgID=0 as this does not pass alert rule validation.
@@ -99,6 +105,7 @@ func (g *AlertRuleGenerator) Generate() AlertRule {
 		Condition:            "A",
 		Data:                 []AlertQuery{g.GenerateQuery()},
 		Updated:              time.Now().Add(-time.Duration(rand.Intn(100) + 1)),
+		UpdatedBy:            updatedBy,
 		IntervalSeconds:      rand.Int63n(60) + 1,
 		Version:              rand.Int63n(1500), // Don't generate a rule ID too big for postgres
 		UID:                  util.GenerateShortUID(),
@@ -522,6 +529,27 @@ func (a *AlertRuleMutators) WithRecordFrom(from string) AlertRuleMutator {
 	}
 }
 
+func (a *AlertRuleMutators) WithRecordTarget(target *RecordTarget) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		if rule.Record == nil {
+			rule.Record = &Record{}
+		}
+		rule.Record.Target = target
+	}
+}
+
+func (a *AlertRuleMutators) WithUpdatedBy(uid *UserUID) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.UpdatedBy = uid
+	}
+}
+
+func (a *AlertRuleMutators) WithDependsOn(uids ...string) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.DependsOn = uids
+	}
+}
+
 func (g *AlertRuleGenerator) GenerateLabels(min, max int, prefix string) data.Labels {
 	count := max
 	if min > max {
@@ -606,6 +634,11 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		Record:          r.Record,
 	}
 
+	if r.DependsOn != nil {
+		result.DependsOn = make([]string, len(r.DependsOn))
+		copy(result.DependsOn, r.DependsOn)
+	}
+
 	if r.DashboardUID != nil {
 		dash := *r.DashboardUID
 		result.DashboardUID = &dash
@@ -614,6 +647,10 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		p := *r.PanelID
 		result.PanelID = &p
 	}
+	if r.UpdatedBy != nil {
+		u := *r.UpdatedBy
+		result.UpdatedBy = &u
+	}
 
 	for _, d := range r.Data {
 		q := AlertQuery{
@@ -646,6 +683,10 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 			From:   r.Record.From,
 			Metric: r.Record.Metric,
 		}
+		if r.Record.Target != nil {
+			target := *r.Record.Target
+			result.Record.Target = &target
+		}
 	}
 
 	for _, s := range r.NotificationSettings {
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
@@ -133,6 +134,8 @@ type AlertNG struct {
 	ImageService        image.ImageService
 	schedule            schedule.ScheduleService
 	stateManager        *state.Manager
+	historian           Historian
+	recordingWriter     recordingRuleWriter
 	folderService       folder.Service
 	dashboardService    dashboards.DashboardService
 	Api                 *api.API
@@ -333,10 +336,11 @@ func (ng *AlertNG) init() error {
 
 	evalFactory := eval.NewEvaluatorFactory(ng.Cfg.UnifiedAlerting, ng.DataSourceCache, ng.ExpressionService, ng.pluginsStore)
 
-	recordingWriter, err := createRecordingWriter(ng.FeatureToggles, ng.Cfg.UnifiedAlerting.RecordingRules)
+	recordingWriter, err := createRecordingWriter(ng.FeatureToggles, ng.Cfg, ng.DataSourceService, ng.accesscontrol)
 	if err != nil {
 		return err
 	}
+	ng.recordingWriter = recordingWriter
 
 	schedCfg := schedule.SchedulerCfg{
 		MaxAttempts:          ng.Cfg.UnifiedAlerting.MaxAttempts,
@@ -354,15 +358,17 @@ func (ng *AlertNG) init() error {
 		Tracer:               ng.tracer,
 		Log:                  log.New("ngalert.scheduler"),
 		RecordingWriter:      recordingWriter,
+		EvaluationBudgets:    ng.Cfg.UnifiedAlerting.EvaluationBudgets,
 	}
 
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
 	ApplyStateHistoryFeatureToggles(&ng.Cfg.UnifiedAlerting.StateHistory, ng.FeatureToggles, ng.Log)
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer)
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.SQLStore, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer)
 	if err != nil {
 		return err
 	}
+	ng.historian = history
 	cfg := state.ManagerCfg{
 		Metrics:                        ng.Metrics.GetStateMetrics(),
 		ExternalURL:                    appUrl,
@@ -385,6 +391,17 @@ func (ng *AlertNG) init() error {
 		ticker := clock.New().Ticker(ng.Cfg.UnifiedAlerting.StatePeriodicSaveInterval)
 		statePersister = state.NewAsyncStatePersister(logger, ticker, cfg)
 	}
+	if ng.Cfg.UnifiedAlerting.HARuleShardingEnabled {
+		switch {
+		case ng.Cfg.UnifiedAlerting.HARedisAddr == "" && len(ng.Cfg.UnifiedAlerting.HAPeers) == 0:
+			ng.Log.Warn("Alert rule sharding is disabled because high availability is not configured")
+		case ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic):
+			ng.Log.Warn("Alert rule sharding is disabled because it is not compatible with periodic saving of the state", "featureToggle", featuremgmt.FlagAlertingSaveStatePeriodic)
+		default:
+			schedCfg.ClusterMembership = ng.MultiOrgAlertmanager.ClusterMembership()
+			cfg.HandoffStore = ng.KVStore
+		}
+	}
 	stateManager := state.NewManager(cfg, statePersister)
 	scheduler := schedule.NewScheduler(schedCfg, stateManager)
 
@@ -407,36 +424,42 @@ func (ng *AlertNG) init() error {
 		int64(ng.Cfg.UnifiedAlerting.DefaultRuleEvaluationInterval.Seconds()),
 		int64(ng.Cfg.UnifiedAlerting.BaseInterval.Seconds()),
 		ng.Cfg.UnifiedAlerting.RulesPerRuleGroupLimit, ng.Log, notifier.NewNotificationSettingsValidationService(ng.store),
-		ac.NewRuleService(ng.accesscontrol))
+		ac.NewRuleService(ng.accesscontrol), ng.recordingWriter)
+	configurationService := provisioning.NewConfigurationService(alertRuleService, contactPointService, policyService,
+		muteTimingService, templateService, ng.store, ng.store, ng.store, ng.Log)
 
 	ng.Api = &api.API{
-		Cfg:                  ng.Cfg,
-		DatasourceCache:      ng.DataSourceCache,
-		DatasourceService:    ng.DataSourceService,
-		RouteRegister:        ng.RouteRegister,
-		DataProxy:            ng.DataProxy,
-		QuotaService:         ng.QuotaService,
-		TransactionManager:   ng.store,
-		RuleStore:            ng.store,
-		AlertingStore:        ng.store,
-		AdminConfigStore:     ng.store,
-		ProvenanceStore:      ng.store,
-		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
-		StateManager:         ng.stateManager,
-		AccessControl:        ng.accesscontrol,
-		Policies:             policyService,
-		ReceiverService:      receiverService,
-		ContactPointService:  contactPointService,
-		Templates:            templateService,
-		MuteTimings:          muteTimingService,
-		AlertRules:           alertRuleService,
-		AlertsRouter:         alertsRouter,
-		EvaluatorFactory:     evalFactory,
-		FeatureManager:       ng.FeatureToggles,
-		AppUrl:               appUrl,
-		Historian:            history,
-		Hooks:                api.NewHooks(ng.Log),
-		Tracer:               ng.tracer,
+		Cfg:                   ng.Cfg,
+		DatasourceCache:       ng.DataSourceCache,
+		DatasourceService:     ng.DataSourceService,
+		RouteRegister:         ng.RouteRegister,
+		DataProxy:             ng.DataProxy,
+		QuotaService:          ng.QuotaService,
+		TransactionManager:    ng.store,
+		RuleStore:             ng.store,
+		AlertingStore:         ng.store,
+		AdminConfigStore:      ng.store,
+		ProvenanceStore:       ng.store,
+		MultiOrgAlertmanager:  ng.MultiOrgAlertmanager,
+		StateManager:          ng.stateManager,
+		EvaluationDelays:      scheduler,
+		AccessControl:         ng.accesscontrol,
+		Policies:              policyService,
+		ReceiverService:       receiverService,
+		ContactPointService:   contactPointService,
+		Templates:             templateService,
+		MuteTimings:           muteTimingService,
+		AlertRules:            alertRuleService,
+		Configuration:         configurationService,
+		AlertsRouter:          alertsRouter,
+		EvaluatorFactory:      evalFactory,
+		FeatureManager:        ng.FeatureToggles,
+		AppUrl:                appUrl,
+		Historian:             history,
+		RecordTargetValidator: recordingWriter,
+		LocalTSDB:             recordingWriter,
+		Hooks:                 api.NewHooks(ng.Log),
+		Tracer:                ng.tracer,
 	}
 	ng.Api.RegisterAPIEndpoints(ng.Metrics.GetAPIMetrics())
 
@@ -481,6 +504,16 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	if r, ok := ng.historian.(historian.Runner); ok {
+		children.Go(func() error {
+			return r.Run(subCtx)
+		})
+	}
+	if r, ok := ng.recordingWriter.(*writer.Router); ok {
+		children.Go(func() error {
+			return r.Run(subCtx)
+		})
+	}
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -524,7 +557,7 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian, l log.Logger, tracer tracing.Tracer) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, sqlStore db.DB, met *metrics.Historian, l log.Logger, tracer tracing.Tracer) (Historian, error) {
 	if !cfg.Enabled {
 		met.Info.WithLabelValues("noop").Set(0)
 		return historian.NewNopHistorian(), nil
@@ -537,9 +570,12 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 
 	met.Info.WithLabelValues(backend.String()).Set(1)
 	if backend == historian.BackendTypeMultiple {
+		if primary, err := historian.ParseBackendType(cfg.MultiPrimary); err == nil && primary == historian.BackendTypePrometheus {
+			return nil, fmt.Errorf("multi-backend target \"%s\" cannot be the primary as it does not support queries", cfg.MultiPrimary)
+		}
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l, tracer)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, sqlStore, met, l, tracer)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -548,7 +584,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l, tracer)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, sqlStore, met, l, tracer)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -579,6 +615,27 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		}
 		return backend, nil
 	}
+	if backend == historian.BackendTypePrometheus {
+		pcfg, err := historian.NewPrometheusConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prometheus state history configuration: %w", err)
+		}
+		req := historian.NewRequester()
+		prometheusBackendLogger := log.New("ngalert.state.historian", "backend", "prometheus")
+		backend, err := historian.NewPrometheusBackend(prometheusBackendLogger, pcfg, req, met)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prometheus state history configuration: %w", err)
+		}
+		return backend, nil
+	}
+	if backend == historian.BackendTypeSQL {
+		scfg, err := historian.NewSQLConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sql state history configuration: %w", err)
+		}
+		sqlBackendLogger := log.New("ngalert.state.historian", "backend", "sql")
+		return historian.NewSQLBackend(sqlBackendLogger, scfg, sqlStore, serverlock.ProvideService(sqlStore, tracer), met), nil
+	}
 
 	return nil, fmt.Errorf("unrecognized state history backend: %s", backend)
 }
@@ -632,12 +689,32 @@ func createRemoteAlertmanager(cfg remote.AlertmanagerConfig, kvstore kvstore.KVS
 	return remote.NewAlertmanager(cfg, notifier.NewFileStore(cfg.OrgID, kvstore), decryptFn, autogenFn, m, tracer)
 }
 
-func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings setting.RecordingRuleSettings) (schedule.RecordingWriter, error) {
+// recordingRuleWriter writes the results of recording rules, validates their targets when they are saved, and
+// provides the results written to the local time series database.
+type recordingRuleWriter interface {
+	schedule.RecordingWriter
+	api.RecordTargetValidator
+	api.LocalTSDB
+}
+
+func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, cfg *setting.Cfg, ds datasources.DataSourceService, ac accesscontrol.AccessControl) (recordingRuleWriter, error) {
+	settings := cfg.UnifiedAlerting.RecordingRules
 	logger := log.New("ngalert.writer")
 
-	if featureToggles.IsEnabledGlobally(featuremgmt.FlagGrafanaManagedRecordingRules) {
-		return writer.NewPrometheusWriter(settings, logger)
+	if !featureToggles.IsEnabledGlobally(featuremgmt.FlagGrafanaManagedRecordingRules) {
+		return writer.NoopWriter{}, nil
 	}
 
-	return writer.NoopWriter{}, nil
+	remoteWrite, err := writer.NewPrometheusWriter(settings, logger)
+	if err != nil {
+		return nil, err
+	}
+	var local *writer.LocalWriter
+	if settings.LocalTSDBEnabled {
+		local, err = writer.NewLocalWriter(settings, logger.New("target", "local"))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return writer.NewRouter(remoteWrite, local, ds, ac, cfg, logger), nil
 }
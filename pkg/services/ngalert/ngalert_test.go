@@ -68,7 +68,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			Backend: "invalid-backend",
 		}
 
-		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger, tracer)
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger, tracer)
 
 		require.ErrorContains(t, err, "unrecognized")
 	})
@@ -83,7 +83,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			MultiPrimary: "invalid-backend",
 		}
 
-		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger, tracer)
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger, tracer)
 
 		require.ErrorContains(t, err, "multi-backend target")
 		require.ErrorContains(t, err, "unrecognized")
@@ -100,7 +100,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			MultiSecondaries: []string{"annotations", "invalid-backend"},
 		}
 
-		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger, tracer)
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger, tracer)
 
 		require.ErrorContains(t, err, "multi-backend target")
 		require.ErrorContains(t, err, "unrecognized")
@@ -118,12 +118,62 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			LokiWriteURL: "http://gone.invalid",
 		}
 
-		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger, tracer)
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger, tracer)
 
 		require.NotNil(t, h)
 		require.NoError(t, err)
 	})
 
+	t.Run("fail initialization if invalid sql retention", func(t *testing.T) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		logger := log.NewNopLogger()
+		tracer := tracing.InitializeTracerForTest()
+		cfg := setting.UnifiedAlertingStateHistorySettings{
+			Enabled:               true,
+			Backend:               "sql",
+			SQLRetention:          24 * time.Hour,
+			SQLDownsampleAfter:    48 * time.Hour,
+			SQLDownsampleInterval: time.Hour,
+		}
+
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger, tracer)
+
+		require.ErrorContains(t, err, "invalid sql state history configuration")
+	})
+
+	t.Run("fail initialization if prometheus is the multi-backend primary", func(t *testing.T) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		logger := log.NewNopLogger()
+		tracer := tracing.InitializeTracerForTest()
+		cfg := setting.UnifiedAlertingStateHistorySettings{
+			Enabled:                  true,
+			Backend:                  "multiple",
+			MultiPrimary:             "prometheus",
+			MultiSecondaries:         []string{"annotations"},
+			PrometheusRemoteWriteURL: "http://localhost:9090/api/v1/write",
+			PrometheusMetricName:     "ALERTS",
+		}
+
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger, tracer)
+
+		require.ErrorContains(t, err, "cannot be the primary")
+	})
+
+	t.Run("fail initialization if prometheus backend has no remote write URL", func(t *testing.T) {
+		met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+		logger := log.NewNopLogger()
+		tracer := tracing.InitializeTracerForTest()
+		cfg := setting.UnifiedAlertingStateHistorySettings{
+			Enabled:              true,
+			Backend:              "prometheus",
+			PrometheusMetricName: "ALERTS",
+		}
+
+		_, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger, tracer)
+
+		require.ErrorContains(t, err, "invalid prometheus state history configuration")
+	})
+
 	t.Run("emit metric describing chosen backend", func(t *testing.T) {
 		reg := prometheus.NewRegistry()
 		met := metrics.NewHistorianMetrics(reg, metrics.Subsystem)
@@ -134,7 +184,7 @@ func TestConfigureHistorianBackend(t *testing.T) {
 			Backend: "annotations",
 		}
 
-		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger, tracer)
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger, tracer)
 
 		require.NotNil(t, h)
 		require.NoError(t, err)
@@ -156,7 +206,7 @@ grafana_alerting_state_history_info{backend="annotations"} 1
 			Enabled: false,
 		}
 
-		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, met, logger, tracer)
+		h, err := configureHistorianBackend(context.Background(), cfg, nil, nil, nil, nil, met, logger, tracer)
 
 		require.NotNil(t, h)
 		require.NoError(t, err)
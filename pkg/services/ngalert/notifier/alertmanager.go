@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/notify/nfstatus"
 	"github.com/grafana/alerting/receivers"
 	alertingTemplates "github.com/grafana/alerting/templates"
 	"github.com/prometheus/alertmanager/config"
@@ -20,6 +22,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/httpreceiver"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/setting"
@@ -44,6 +47,9 @@ type stateStore interface {
 	SaveNotificationLog(ctx context.Context, st alertingNotify.State) (int64, error)
 	GetSilences(ctx context.Context) (string, error)
 	GetNotificationLog(ctx context.Context) (string, error)
+	SaveDeliveryLog(ctx context.Context, instance string, st alertingNotify.State) (int64, error)
+	GetDeliveryLog(ctx context.Context, instance string) (string, error)
+	GetDeliveryLogs(ctx context.Context) (map[string]string, error)
 }
 
 type alertmanager struct {
@@ -59,6 +65,16 @@ type alertmanager struct {
 	decryptFn alertingNotify.GetDecryptedValueFn
 	orgID     int64
 
+	// config is the configuration that is currently applied, autogenerated routes included. It is guarded by the lock
+	// of the base Alertmanager.
+	config *apimodels.PostableApiAlertingConfig
+
+	// deliveryLog records the deliveries of the notifications. It is persisted every maintenance interval and when
+	// the Alertmanager stops.
+	deliveryLog *deliveryLog
+	stopc       chan struct{}
+	wg          sync.WaitGroup
+
 	withAutogen bool
 }
 
@@ -100,6 +116,10 @@ func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 	if err != nil {
 		return nil, err
 	}
+	deliveries, err := stateStore.GetDeliveryLog(ctx, cfg.InstanceName)
+	if err != nil {
+		return nil, err
+	}
 
 	silencesOptions := maintenanceOptions{
 		initialState:         silences,
@@ -130,6 +150,13 @@ func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 	}
 
 	l := log.New("ngalert.notifier.alertmanager", "org", orgID)
+	dl, err := newDeliveryLog(cfg.UnifiedAlerting.NotificationDeliveryLogLimit, deliveries)
+	if err != nil {
+		// The delivery log is not critical, start with an empty one.
+		l.Warn("Failed to load the notification delivery log", "error", err)
+		dl, _ = newDeliveryLog(cfg.UnifiedAlerting.NotificationDeliveryLogLimit, "")
+	}
+
 	gam, err := alertingNotify.NewGrafanaAlertmanager("orgID", orgID, amcfg, peer, l, alertingNotify.NewGrafanaAlertmanagerMetrics(m.Registerer))
 	if err != nil {
 		return nil, err
@@ -145,11 +172,19 @@ func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		decryptFn:           decryptFn,
 		stateStore:          stateStore,
 		logger:              l,
+		deliveryLog:         dl,
+		stopc:               make(chan struct{}),
 
 		// TODO: Preferably, logic around autogen would be outside of the specific alertmanager implementation so that remote alertmanager will get it for free.
 		withAutogen: withAutogen,
 	}
 
+	am.wg.Add(1)
+	go func() {
+		defer am.wg.Done()
+		am.runDeliveryLogMaintenance()
+	}()
+
 	return am, nil
 }
 
@@ -162,6 +197,8 @@ func (am *alertmanager) Ready() bool {
 
 func (am *alertmanager) StopAndWait() {
 	am.Base.StopAndWait()
+	close(am.stopc)
+	am.wg.Wait()
 }
 
 // SaveAndApplyDefaultConfig saves the default configuration to the database and applies it to the Alertmanager.
@@ -347,6 +384,7 @@ func (am *alertmanager) applyConfig(cfg *apimodels.PostableUserConfig) (bool, er
 	if err != nil {
 		return false, err
 	}
+	am.config = &cfg.AlertmanagerConfig
 
 	am.updateConfigMetrics(cfg)
 	return true, nil
@@ -376,28 +414,69 @@ func (am *alertmanager) AppURL() string {
 
 // buildReceiverIntegrations builds a list of integration notifiers off of a receiver config.
 func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template) ([]*alertingNotify.Integration, error) {
-	receiverCfg, err := alertingNotify.BuildReceiverConfiguration(context.Background(), receiver, am.decryptFn)
+	// HTTP integrations are built by Grafana, the other integrations by the alerting package.
+	httpIntegrations, err := am.buildHTTPIntegrations(receiver, tmpl)
 	if err != nil {
 		return nil, err
 	}
-	s := &sender{am.NotificationService}
-	img := newImageProvider(am.Store, log.New("ngalert.notifier.image-provider"))
-	integrations, err := alertingNotify.BuildReceiverIntegrations(
-		receiverCfg,
-		tmpl,
-		img,
-		LoggerFactory,
-		func(n receivers.Metadata) (receivers.WebhookSender, error) {
-			return s, nil
-		},
-		func(n receivers.Metadata) (receivers.EmailSender, error) {
-			return s, nil
-		},
-		am.orgID,
-		setting.BuildVersion,
-	)
-	if err != nil {
-		return nil, err
+	other := *receiver
+	other.Integrations = make([]*alertingNotify.GrafanaIntegrationConfig, 0, len(receiver.Integrations))
+	for _, i := range receiver.Integrations {
+		if i.Type != httpreceiver.Type {
+			other.Integrations = append(other.Integrations, i)
+		}
+	}
+	var integrations []*alertingNotify.Integration
+	if len(other.Integrations) > 0 || len(httpIntegrations) == 0 {
+		receiverCfg, err := alertingNotify.BuildReceiverConfiguration(context.Background(), &other, am.decryptFn)
+		if err != nil {
+			return nil, err
+		}
+		s := &sender{am.NotificationService}
+		img := newImageProvider(am.Store, log.New("ngalert.notifier.image-provider"))
+		integrations, err = alertingNotify.BuildReceiverIntegrations(
+			receiverCfg,
+			tmpl,
+			img,
+			LoggerFactory,
+			func(n receivers.Metadata) (receivers.WebhookSender, error) {
+				return s, nil
+			},
+			func(n receivers.Metadata) (receivers.EmailSender, error) {
+				return s, nil
+			},
+			am.orgID,
+			setting.BuildVersion,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	integrations = append(integrations, httpIntegrations...)
+	return withDeliveryLog(am.deliveryLog, receiver.Name, integrations), nil
+}
+
+// buildHTTPIntegrations builds the HTTP integrations of a receiver. Integrations are indexed by type, like the
+// integrations built by the alerting package.
+func (am *alertmanager) buildHTTPIntegrations(receiver *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template) ([]*alertingNotify.Integration, error) {
+	var integrations []*alertingNotify.Integration
+	for _, i := range receiver.Integrations {
+		if i.Type != httpreceiver.Type {
+			continue
+		}
+		cfg, err := httpreceiver.NewConfigFromIntegration(context.Background(), i, am.decryptFn)
+		if err != nil {
+			return nil, alertingNotify.IntegrationValidationError{Integration: i, Err: err}
+		}
+		meta := receivers.Metadata{
+			UID:                   i.UID,
+			Name:                  i.Name,
+			Type:                  i.Type,
+			DisableResolveMessage: i.DisableResolveMessage,
+		}
+		n := httpreceiver.New(cfg, meta, tmpl, LoggerFactory("ngalert.notifier."+i.Type, "notifierUID", i.UID)).
+			WithObserver(deliveryAttemptObserver{})
+		integrations = append(integrations, nfstatus.NewIntegration(n, n, i.Type, len(integrations), receiver.Name))
 	}
 	return integrations, nil
 }
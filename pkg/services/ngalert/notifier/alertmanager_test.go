@@ -2,10 +2,13 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	alertingNotify "github.com/grafana/alerting/notify"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -59,3 +62,32 @@ func TestAlertmanager_newAlertmanager(t *testing.T) {
 	am := setupAMTest(t)
 	require.False(t, am.Ready())
 }
+
+func TestAlertmanager_buildReceiverIntegrations(t *testing.T) {
+	am := setupAMTest(t)
+	tmpl := templateForTests(t)
+
+	receiver := &alertingNotify.APIReceiver{
+		ConfigReceiver: alertingNotify.ConfigReceiver{Name: "receiver"},
+		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+			Integrations: []*alertingNotify.GrafanaIntegrationConfig{
+				{UID: "http-1", Name: "receiver", Type: "http", Settings: json.RawMessage(`{"url": "https://example.com/1", "authType": "bearer", "bearerToken": "token"}`)},
+				{UID: "email", Name: "receiver", Type: "email", Settings: json.RawMessage(`{"addresses": "test@example.com"}`)},
+				{UID: "http-2", Name: "receiver", Type: "http", Settings: json.RawMessage(`{"url": "https://example.com/2"}`)},
+			},
+		},
+	}
+	integrations, err := am.buildReceiverIntegrations(receiver, tmpl)
+	require.NoError(t, err)
+	require.Len(t, integrations, 3)
+	assert.Equal(t, "email", integrations[0].Name())
+	assert.Equal(t, 0, integrations[0].Index())
+	assert.Equal(t, "http", integrations[1].Name())
+	assert.Equal(t, 0, integrations[1].Index())
+	assert.Equal(t, "http", integrations[2].Name())
+	assert.Equal(t, 1, integrations[2].Index())
+
+	receiver.Integrations[2].Settings = json.RawMessage(`{"url": "https://example.com/2", "authType": "bearer"}`)
+	_, err = am.buildReceiverIntegrations(receiver, tmpl)
+	require.ErrorAs(t, err, &alertingNotify.IntegrationValidationError{})
+}
@@ -965,6 +965,226 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "http",
+			Name:        "HTTP",
+			Description: "Sends HTTP requests with a templated body and configurable authentication and TLS",
+			Heading:     "HTTP settings",
+			Options: []NotifierOption{
+				{
+					Label:        "URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:   "HTTP Method",
+					Element: ElementTypeSelect,
+					SelectOptions: []SelectOption{
+						{
+							Value: "POST",
+							Label: "POST",
+						},
+						{
+							Value: "PUT",
+							Label: "PUT",
+						},
+						{
+							Value: "PATCH",
+							Label: "PATCH",
+						},
+					},
+					PropertyName: "httpMethod",
+				},
+				{
+					Label:        "Headers",
+					Description:  "Headers of the request. Values can use templates.",
+					Element:      ElementTypeKeyValueMap,
+					InputType:    InputTypeText,
+					PropertyName: "headers",
+				},
+				{
+					Label:        "Content Type",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "contentType",
+					Placeholder:  "application/json",
+				},
+				{
+					Label:        "Body",
+					Description:  "Templated body of the request. If empty, the body is the JSON of the notification data.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "body",
+				},
+				{
+					Label:   "Authentication",
+					Element: ElementTypeSelect,
+					SelectOptions: []SelectOption{
+						{
+							Value: "none",
+							Label: "None",
+						},
+						{
+							Value: "basic",
+							Label: "Basic",
+						},
+						{
+							Value: "bearer",
+							Label: "Bearer token",
+						},
+						{
+							Value: "oauth2",
+							Label: "OAuth2 client credentials",
+						},
+						{
+							Value: "hmac",
+							Label: "HMAC signature",
+						},
+					},
+					PropertyName: "authType",
+				},
+				{
+					Label:        "Username",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "username",
+					ShowWhen: ShowWhen{
+						Field: "authType",
+						Is:    "basic",
+					},
+				},
+				{
+					Label:        "Password",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "password",
+					Secure:       true,
+					ShowWhen: ShowWhen{
+						Field: "authType",
+						Is:    "basic",
+					},
+				},
+				{
+					Label:        "Bearer Token",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "bearerToken",
+					Secure:       true,
+					ShowWhen: ShowWhen{
+						Field: "authType",
+						Is:    "bearer",
+					},
+				},
+				{
+					Label:        "OAuth2 Client ID",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "oauth2ClientId",
+					ShowWhen: ShowWhen{
+						Field: "authType",
+						Is:    "oauth2",
+					},
+				},
+				{
+					Label:        "OAuth2 Client Secret",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "oauth2ClientSecret",
+					Secure:       true,
+					ShowWhen: ShowWhen{
+						Field: "authType",
+						Is:    "oauth2",
+					},
+				},
+				{
+					Label:        "OAuth2 Token URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "oauth2TokenUrl",
+					ShowWhen: ShowWhen{
+						Field: "authType",
+						Is:    "oauth2",
+					},
+				},
+				{
+					Label:        "OAuth2 Scopes",
+					Description:  "Comma-separated list of scopes.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "oauth2Scopes",
+					ShowWhen: ShowWhen{
+						Field: "authType",
+						Is:    "oauth2",
+					},
+				},
+				{
+					Label:        "HMAC Secret",
+					Description:  "Secret of the HMAC-SHA256 signature of the body.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypePassword,
+					PropertyName: "hmacSecret",
+					Secure:       true,
+					ShowWhen: ShowWhen{
+						Field: "authType",
+						Is:    "hmac",
+					},
+				},
+				{
+					Label:        "HMAC Signature Header",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "hmacHeader",
+					Placeholder:  "X-Grafana-Signature",
+					ShowWhen: ShowWhen{
+						Field: "authType",
+						Is:    "hmac",
+					},
+				},
+				{
+					Label:        "HMAC Timestamp Header",
+					Description:  "If set, the Unix timestamp of the request is sent in this header and signed with the body.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "hmacTimestampHeader",
+					ShowWhen: ShowWhen{
+						Field: "authType",
+						Is:    "hmac",
+					},
+				},
+				{
+					Label:        "TLS CA Certificate",
+					Description:  "PEM encoded certificate of the CA of the server.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tlsCACertificate",
+				},
+				{
+					Label:        "TLS Client Certificate",
+					Description:  "PEM encoded client certificate.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tlsClientCertificate",
+				},
+				{
+					Label:        "TLS Client Key",
+					Description:  "PEM encoded client key.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "tlsClientKey",
+					Secure:       true,
+				},
+				{
+					Label:        "TLS Server Name",
+					Description:  "Server name used to verify the certificate of the server.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "tlsServerName",
+				},
+				{
+					Label:        "Skip TLS Verification",
+					Element:      ElementTypeCheckbox,
+					PropertyName: "tlsInsecureSkipVerify",
+				},
+			},
+		},
 		{
 			Type:        "wecom",
 			Name:        "WeCom",
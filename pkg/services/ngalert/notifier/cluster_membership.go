@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	alertingCluster "github.com/grafana/alerting/cluster"
+	alertingNotify "github.com/grafana/alerting/notify"
+)
+
+// ClusterMembership reports the members of the high availability cluster the Alertmanagers of this instance are part of.
+type ClusterMembership struct {
+	peer alertingNotify.ClusterPeer
+}
+
+// ClusterMembership returns the membership of the high availability cluster. If clustering is not configured, the
+// cluster has no members.
+func (moa *MultiOrgAlertmanager) ClusterMembership() *ClusterMembership {
+	return &ClusterMembership{peer: moa.peer}
+}
+
+// Self returns the name of this instance in the cluster.
+func (m *ClusterMembership) Self() string {
+	switch p := m.peer.(type) {
+	case *redisPeer:
+		return p.withPrefix(p.name)
+	case *alertingCluster.Peer:
+		return p.Name()
+	}
+	return ""
+}
+
+// Members returns the names of the live members of the cluster, including this instance.
+func (m *ClusterMembership) Members() []string {
+	switch p := m.peer.(type) {
+	case *redisPeer:
+		return p.Members()
+	case *alertingCluster.Peer:
+		nodes := p.Peers()
+		members := make([]string, 0, len(nodes))
+		for _, n := range nodes {
+			members = append(members, n.Name())
+		}
+		return members
+	}
+	return nil
+}
@@ -0,0 +1,366 @@
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/notify/nfstatus"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// NotificationDeliveryStatus is the status of the delivery of a notification.
+type NotificationDeliveryStatus string
+
+const (
+	// NotificationDeliverySent means that the integration sent the notification.
+	NotificationDeliverySent NotificationDeliveryStatus = "sent"
+	// NotificationDeliveryRetrying means that the last attempt failed, and that the Alertmanager retries it.
+	NotificationDeliveryRetrying NotificationDeliveryStatus = "retrying"
+	// NotificationDeliveryFailed means that the notification was not sent, either because the error cannot be
+	// recovered or because the Alertmanager gave up retrying.
+	NotificationDeliveryFailed NotificationDeliveryStatus = "failed"
+)
+
+// NotificationDelivery is the record of the delivery of a notification by an integration of a receiver. The retries of
+// the notification update the same record.
+type NotificationDelivery struct {
+	// Timestamp is the time of the first attempt.
+	Timestamp        time.Time `json:"timestamp"`
+	Receiver         string    `json:"receiver"`
+	Integration      string    `json:"integration"`
+	IntegrationIndex int       `json:"integrationIndex"`
+	GroupKey         string    `json:"groupKey"`
+	// PayloadHash is the SHA-256 hash of the request body when the integration sends a webhook or an HTTP request, and
+	// of the alerts of the notification otherwise.
+	PayloadHash string                     `json:"payloadHash"`
+	Alerts      int                        `json:"alerts"`
+	Status      NotificationDeliveryStatus `json:"status"`
+	// StatusCode is the HTTP status code of the response to the last attempt, if the integration sends a webhook or an
+	// HTTP request.
+	StatusCode int `json:"statusCode,omitempty"`
+	Retries    int `json:"retries"`
+	// Duration is the duration of the last attempt.
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+
+	// deadline is the deadline of the notification pipeline, which identifies the attempts of the same notification.
+	deadline time.Time
+}
+
+// NotificationDeliveryQuery filters the notification deliveries. Empty fields match all deliveries.
+type NotificationDeliveryQuery struct {
+	Receiver    string
+	Integration string
+	GroupKey    string
+	Status      NotificationDeliveryStatus
+	// Limit is the maximum number of deliveries to return. 0 means no limit.
+	Limit int
+}
+
+// deliveryLogState is the persisted state of the delivery log.
+type deliveryLogState []NotificationDelivery
+
+func (s deliveryLogState) MarshalBinary() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// deliveryLog keeps the latest notification deliveries of an Alertmanager, up to a limit.
+type deliveryLog struct {
+	mtx     sync.Mutex
+	entries []*NotificationDelivery
+	limit   int
+	changed bool
+}
+
+func newDeliveryLog(limit int, initialState string) (*deliveryLog, error) {
+	l := &deliveryLog{limit: limit}
+	if initialState == "" {
+		return l, nil
+	}
+	var state deliveryLogState
+	if err := json.Unmarshal([]byte(initialState), &state); err != nil {
+		return nil, fmt.Errorf("failed to parse the notification delivery log: %w", err)
+	}
+	for i := range state {
+		l.entries = append(l.entries, &state[i])
+	}
+	l.truncate()
+	return l, nil
+}
+
+// record records an attempt to deliver a notification. An attempt of a notification that is retried updates the record
+// of the previous attempt.
+func (l *deliveryLog) record(entry NotificationDelivery, retry bool, err error) *NotificationDelivery {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.changed = true
+
+	var current *NotificationDelivery
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		e := l.entries[i]
+		if e.Status == NotificationDeliveryRetrying && !e.deadline.IsZero() && e.deadline.Equal(entry.deadline) &&
+			e.GroupKey == entry.GroupKey && e.Receiver == entry.Receiver && e.Integration == entry.Integration &&
+			e.IntegrationIndex == entry.IntegrationIndex {
+			current = e
+			break
+		}
+	}
+	if current == nil {
+		current = &entry
+		l.entries = append(l.entries, current)
+		l.truncate()
+	} else {
+		current.Retries++
+		current.PayloadHash = entry.PayloadHash
+		current.Alerts = entry.Alerts
+		current.StatusCode = entry.StatusCode
+		current.Duration = entry.Duration
+	}
+
+	current.Error = ""
+	switch {
+	case err == nil:
+		current.Status = NotificationDeliverySent
+	case retry:
+		current.Status = NotificationDeliveryRetrying
+		current.Error = err.Error()
+	default:
+		current.Status = NotificationDeliveryFailed
+		current.Error = err.Error()
+	}
+	return current
+}
+
+// giveUp marks a notification that is still retried as failed. It is called once the Alertmanager stops retrying it.
+func (l *deliveryLog) giveUp(entry *NotificationDelivery) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if entry.Status == NotificationDeliveryRetrying {
+		entry.Status = NotificationDeliveryFailed
+		l.changed = true
+	}
+}
+
+func (l *deliveryLog) truncate() {
+	if l.limit > 0 && len(l.entries) > l.limit {
+		l.entries = slices.Delete(l.entries, 0, len(l.entries)-l.limit)
+	}
+}
+
+// query returns the deliveries that match the query, the most recent first.
+func (l *deliveryLog) query(q NotificationDeliveryQuery) []NotificationDelivery {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	result := make([]NotificationDelivery, 0)
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		e := l.entries[i]
+		if (q.Receiver != "" && e.Receiver != q.Receiver) ||
+			(q.Integration != "" && e.Integration != q.Integration) ||
+			(q.GroupKey != "" && e.GroupKey != q.GroupKey) ||
+			(q.Status != "" && e.Status != q.Status) {
+			continue
+		}
+		result = append(result, *e)
+		if q.Limit > 0 && len(result) >= q.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// state returns the state to persist, and whether it changed since the last call.
+func (l *deliveryLog) state() (deliveryLogState, bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	changed := l.changed
+	l.changed = false
+	state := make(deliveryLogState, 0, len(l.entries))
+	for _, e := range l.entries {
+		state = append(state, *e)
+	}
+	return state, changed
+}
+
+// deliveryAttempt collects the details of an attempt that are only known to the sender of the notification.
+type deliveryAttempt struct {
+	mtx         sync.Mutex
+	statusCode  int
+	payloadHash string
+}
+
+func (a *deliveryAttempt) setPayload(body string) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.payloadHash = fmt.Sprintf("%x", sha256.Sum256([]byte(body)))
+}
+
+func (a *deliveryAttempt) setStatusCode(statusCode int) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.statusCode = statusCode
+}
+
+type deliveryAttemptKey struct{}
+
+func withDeliveryAttempt(ctx context.Context, a *deliveryAttempt) context.Context {
+	return context.WithValue(ctx, deliveryAttemptKey{}, a)
+}
+
+func deliveryAttemptFromContext(ctx context.Context) *deliveryAttempt {
+	a, _ := ctx.Value(deliveryAttemptKey{}).(*deliveryAttempt)
+	return a
+}
+
+// deliveryAttemptObserver records the requests of the HTTP integrations, which do not use the webhook sender, in the
+// delivery attempt of the notification.
+type deliveryAttemptObserver struct{}
+
+func (deliveryAttemptObserver) ObserveRequest(ctx context.Context, body []byte) {
+	if attempt := deliveryAttemptFromContext(ctx); attempt != nil {
+		attempt.setPayload(string(body))
+	}
+}
+
+func (deliveryAttemptObserver) ObserveResponse(ctx context.Context, statusCode int) {
+	if attempt := deliveryAttemptFromContext(ctx); attempt != nil {
+		attempt.setStatusCode(statusCode)
+	}
+}
+
+// deliveryLogNotifier records the attempts of an integration in the delivery log.
+type deliveryLogNotifier struct {
+	integration *alertingNotify.Integration
+	receiver    string
+	log         *deliveryLog
+}
+
+// withDeliveryLog wraps the integrations of a receiver so that their notifications are recorded in the delivery log.
+// The integrations of test notifications, which are built for a receiver without name, are not wrapped.
+func withDeliveryLog(l *deliveryLog, receiver string, integrations []*alertingNotify.Integration) []*alertingNotify.Integration {
+	if receiver == "" {
+		return integrations
+	}
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, i := range integrations {
+		n := &deliveryLogNotifier{integration: i, receiver: receiver, log: l}
+		result = append(result, nfstatus.NewIntegration(n, i, i.Name(), i.Index(), receiver))
+	}
+	return result
+}
+
+func (n *deliveryLogNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	attempt := &deliveryAttempt{}
+	start := time.Now()
+	retry, err := n.integration.Notify(withDeliveryAttempt(ctx, attempt), alerts...)
+	duration := time.Since(start)
+
+	groupKey, _ := notify.GroupKey(ctx)
+	deadline, _ := ctx.Deadline()
+	attempt.mtx.Lock()
+	entry := NotificationDelivery{
+		Timestamp:        start,
+		Receiver:         n.receiver,
+		Integration:      n.integration.Name(),
+		IntegrationIndex: n.integration.Index(),
+		GroupKey:         groupKey,
+		PayloadHash:      attempt.payloadHash,
+		Alerts:           len(alerts),
+		StatusCode:       attempt.statusCode,
+		Duration:         duration,
+		deadline:         deadline,
+	}
+	attempt.mtx.Unlock()
+	if entry.PayloadHash == "" {
+		entry.PayloadHash = alertsHash(alerts)
+	}
+
+	recorded := n.log.record(entry, retry, err)
+	if err != nil && retry {
+		// The Alertmanager retries the notification until the context of the pipeline is done.
+		context.AfterFunc(ctx, func() {
+			n.log.giveUp(recorded)
+		})
+	}
+	return retry, err
+}
+
+// alertsHash returns the SHA-256 hash of the fingerprints and statuses of the alerts.
+func alertsHash(alerts []*types.Alert) string {
+	keys := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		keys = append(keys, fmt.Sprintf("%s:%s", a.Fingerprint(), a.Status()))
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		_, _ = h.Write([]byte(k))
+		_, _ = h.Write([]byte{'\n'})
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// GetNotificationDeliveries returns the notification deliveries that match the query, the most recent first. In a high
+// availability setup, the deliveries of the other Grafana instances are read from their persisted logs, so they are up
+// to a maintenance interval late.
+func (am *alertmanager) GetNotificationDeliveries(ctx context.Context, q NotificationDeliveryQuery) ([]NotificationDelivery, error) {
+	result := am.deliveryLog.query(q)
+	logs, err := am.stateStore.GetDeliveryLogs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for instance, content := range logs {
+		// The log of this instance is more recent in memory.
+		if instance == am.Settings.InstanceName || content == "" {
+			continue
+		}
+		l, err := newDeliveryLog(0, content)
+		if err != nil {
+			am.logger.Warn("Failed to load the notification delivery log of another instance", "instance", instance, "error", err)
+			continue
+		}
+		result = append(result, l.query(q)...)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.After(result[j].Timestamp)
+	})
+	if q.Limit > 0 && len(result) > q.Limit {
+		result = result[:q.Limit]
+	}
+	return result, nil
+}
+
+// runDeliveryLogMaintenance persists the delivery log every maintenance interval, and once more when the Alertmanager
+// stops.
+func (am *alertmanager) runDeliveryLogMaintenance() {
+	t := time.NewTicker(maintenanceInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-am.stopc:
+			am.persistDeliveryLog()
+			return
+		case <-t.C:
+			am.persistDeliveryLog()
+		}
+	}
+}
+
+func (am *alertmanager) persistDeliveryLog() {
+	state, changed := am.deliveryLog.state()
+	if !changed {
+		return
+	}
+	// Detached context here is to make sure that when the service is shut down the persist operation is executed.
+	if _, err := am.stateStore.SaveDeliveryLog(context.Background(), am.Settings.InstanceName, state); err != nil {
+		am.logger.Error("Failed to persist the notification delivery log", "error", err)
+	}
+}
@@ -0,0 +1,261 @@
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/notify/nfstatus"
+	"github.com/grafana/alerting/receivers"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/httpreceiver"
+)
+
+type fakeDeliveryNotifier struct {
+	results []error
+	retry   bool
+	calls   int
+}
+
+func (n *fakeDeliveryNotifier) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	err := n.results[n.calls]
+	n.calls++
+	if attempt := deliveryAttemptFromContext(ctx); attempt != nil {
+		attempt.setPayload("body")
+		attempt.setStatusCode(500)
+		if err == nil {
+			attempt.setStatusCode(200)
+		}
+	}
+	return n.retry && err != nil, err
+}
+
+func (n *fakeDeliveryNotifier) SendResolved() bool {
+	return true
+}
+
+func TestDeliveryLog(t *testing.T) {
+	alerts := []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}}
+
+	newNotifier := func(l *deliveryLog, n *fakeDeliveryNotifier) *nfstatus.Integration {
+		integrations := withDeliveryLog(l, "receiver", []*nfstatus.Integration{nfstatus.NewIntegration(n, n, "webhook", 0, "receiver")})
+		require.Len(t, integrations, 1)
+		return integrations[0]
+	}
+	newContext := func(t *testing.T) (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithTimeout(notify.WithGroupKey(context.Background(), "group"), time.Minute)
+		t.Cleanup(cancel)
+		return ctx, cancel
+	}
+
+	t.Run("test notifications are not recorded", func(t *testing.T) {
+		l, err := newDeliveryLog(10, "")
+		require.NoError(t, err)
+		ctx, _ := newContext(t)
+		n := &fakeDeliveryNotifier{results: []error{nil}}
+		integrations := withDeliveryLog(l, "", []*nfstatus.Integration{nfstatus.NewIntegration(n, n, "webhook", 0, "")})
+		require.Len(t, integrations, 1)
+
+		_, err = integrations[0].Notify(ctx, alerts...)
+		require.NoError(t, err)
+		require.Empty(t, l.query(NotificationDeliveryQuery{}))
+	})
+
+	t.Run("the status of the integration is captured", func(t *testing.T) {
+		l, err := newDeliveryLog(10, "")
+		require.NoError(t, err)
+		ctx, _ := newContext(t)
+		i := newNotifier(l, &fakeDeliveryNotifier{results: []error{errors.New("unavailable")}})
+
+		_, err = i.Notify(ctx, alerts...)
+		require.Error(t, err)
+		lastAttempt, _, lastErr := i.GetReport()
+		require.False(t, lastAttempt.IsZero())
+		require.ErrorContains(t, lastErr, "unavailable")
+	})
+
+	t.Run("successful notification is recorded", func(t *testing.T) {
+		l, err := newDeliveryLog(10, "")
+		require.NoError(t, err)
+		ctx, _ := newContext(t)
+
+		_, err = newNotifier(l, &fakeDeliveryNotifier{results: []error{nil}}).Notify(ctx, alerts...)
+		require.NoError(t, err)
+
+		deliveries := l.query(NotificationDeliveryQuery{})
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, "receiver", deliveries[0].Receiver)
+		assert.Equal(t, "webhook", deliveries[0].Integration)
+		assert.Equal(t, "group", deliveries[0].GroupKey)
+		assert.Equal(t, NotificationDeliverySent, deliveries[0].Status)
+		assert.Equal(t, 200, deliveries[0].StatusCode)
+		assert.Equal(t, 1, deliveries[0].Alerts)
+		assert.Equal(t, 0, deliveries[0].Retries)
+		assert.NotEmpty(t, deliveries[0].PayloadHash)
+	})
+
+	t.Run("HTTP integrations record the status code and the payload", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		t.Cleanup(server.Close)
+		cfg, err := httpreceiver.NewConfig(json.RawMessage(`{"url": "`+server.URL+`", "body": "{{ .Status }}"}`), func(_ string, fallback string) string {
+			return fallback
+		})
+		require.NoError(t, err)
+		tmpl := alertingTemplates.ForTests(t)
+		tmpl.ExternalURL, err = url.Parse("http://localhost")
+		require.NoError(t, err)
+		n := httpreceiver.New(cfg, receivers.Metadata{Type: httpreceiver.Type}, tmpl, &logging.FakeLogger{}).
+			WithObserver(deliveryAttemptObserver{})
+		l, err := newDeliveryLog(10, "")
+		require.NoError(t, err)
+		ctx, _ := newContext(t)
+		integrations := withDeliveryLog(l, "receiver", []*nfstatus.Integration{nfstatus.NewIntegration(n, n, httpreceiver.Type, 0, "receiver")})
+
+		_, err = integrations[0].Notify(ctx, alerts...)
+		require.NoError(t, err)
+
+		deliveries := l.query(NotificationDeliveryQuery{})
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, http.StatusAccepted, deliveries[0].StatusCode)
+		assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256([]byte("firing"))), deliveries[0].PayloadHash)
+	})
+
+	t.Run("retries update the same delivery", func(t *testing.T) {
+		l, err := newDeliveryLog(10, "")
+		require.NoError(t, err)
+		ctx, _ := newContext(t)
+		i := newNotifier(l, &fakeDeliveryNotifier{results: []error{errors.New("unavailable"), nil}, retry: true})
+
+		retry, err := i.Notify(ctx, alerts...)
+		require.Error(t, err)
+		require.True(t, retry)
+		deliveries := l.query(NotificationDeliveryQuery{})
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, NotificationDeliveryRetrying, deliveries[0].Status)
+		assert.Equal(t, "unavailable", deliveries[0].Error)
+
+		_, err = i.Notify(ctx, alerts...)
+		require.NoError(t, err)
+		deliveries = l.query(NotificationDeliveryQuery{})
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, NotificationDeliverySent, deliveries[0].Status)
+		assert.Equal(t, 1, deliveries[0].Retries)
+		assert.Empty(t, deliveries[0].Error)
+	})
+
+	t.Run("notification fails when the Alertmanager stops retrying", func(t *testing.T) {
+		l, err := newDeliveryLog(10, "")
+		require.NoError(t, err)
+		ctx, cancel := newContext(t)
+
+		_, err = newNotifier(l, &fakeDeliveryNotifier{results: []error{errors.New("unavailable")}, retry: true}).Notify(ctx, alerts...)
+		require.Error(t, err)
+		cancel()
+
+		require.Eventually(t, func() bool {
+			deliveries := l.query(NotificationDeliveryQuery{})
+			return len(deliveries) == 1 && deliveries[0].Status == NotificationDeliveryFailed
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("notifications of different pipelines are different deliveries", func(t *testing.T) {
+		l, err := newDeliveryLog(10, "")
+		require.NoError(t, err)
+		n := &fakeDeliveryNotifier{results: []error{errors.New("bad request"), nil}}
+		i := newNotifier(l, n)
+
+		ctx, _ := newContext(t)
+		_, err = i.Notify(ctx, alerts...)
+		require.Error(t, err)
+		ctx, _ = newContext(t)
+		_, err = i.Notify(ctx, alerts...)
+		require.NoError(t, err)
+
+		deliveries := l.query(NotificationDeliveryQuery{})
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, NotificationDeliverySent, deliveries[0].Status)
+		assert.Equal(t, NotificationDeliveryFailed, deliveries[1].Status)
+
+		failed := l.query(NotificationDeliveryQuery{Status: NotificationDeliveryFailed})
+		require.Len(t, failed, 1)
+		assert.Equal(t, "bad request", failed[0].Error)
+	})
+
+	t.Run("log keeps the latest deliveries up to the limit", func(t *testing.T) {
+		l, err := newDeliveryLog(2, "")
+		require.NoError(t, err)
+		for _, receiver := range []string{"a", "b", "c"} {
+			l.record(NotificationDelivery{Receiver: receiver}, false, nil)
+		}
+
+		deliveries := l.query(NotificationDeliveryQuery{})
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, "c", deliveries[0].Receiver)
+		assert.Equal(t, "b", deliveries[1].Receiver)
+		assert.Len(t, l.query(NotificationDeliveryQuery{Limit: 1}), 1)
+		assert.Len(t, l.query(NotificationDeliveryQuery{Receiver: "b"}), 1)
+	})
+
+	t.Run("log is restored from its state", func(t *testing.T) {
+		l, err := newDeliveryLog(10, "")
+		require.NoError(t, err)
+		l.record(NotificationDelivery{Receiver: "a", Timestamp: time.Now().UTC().Truncate(time.Second)}, false, errors.New("failed"))
+
+		state, changed := l.state()
+		require.True(t, changed)
+		_, changed = l.state()
+		require.False(t, changed)
+		b, err := state.MarshalBinary()
+		require.NoError(t, err)
+
+		restored, err := newDeliveryLog(10, string(b))
+		require.NoError(t, err)
+		assert.Equal(t, l.query(NotificationDeliveryQuery{}), restored.query(NotificationDeliveryQuery{}))
+	})
+}
+
+func TestAlertmanager_GetNotificationDeliveries(t *testing.T) {
+	ctx := context.Background()
+	am := setupAMTest(t)
+	am.Settings.InstanceName = "instance-a"
+	now := time.Now().UTC().Truncate(time.Second)
+
+	// The persisted log of this instance is older than the one in memory, so it is ignored.
+	_, err := am.stateStore.SaveDeliveryLog(ctx, "instance-a", deliveryLogState{{Receiver: "stale", Timestamp: now}})
+	require.NoError(t, err)
+	_, err = am.stateStore.SaveDeliveryLog(ctx, "instance-b", deliveryLogState{
+		{Receiver: "b", Timestamp: now.Add(-2 * time.Minute), Status: NotificationDeliveryFailed},
+		{Receiver: "b", Timestamp: now, Status: NotificationDeliverySent},
+	})
+	require.NoError(t, err)
+	am.deliveryLog.record(NotificationDelivery{Receiver: "a", Timestamp: now.Add(-time.Minute)}, false, nil)
+
+	deliveries, err := am.GetNotificationDeliveries(ctx, NotificationDeliveryQuery{})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	for i, expected := range []time.Time{now, now.Add(-time.Minute), now.Add(-2 * time.Minute)} {
+		assert.Equal(t, expected, deliveries[i].Timestamp)
+	}
+	assert.Equal(t, "a", deliveries[1].Receiver)
+
+	deliveries, err = am.GetNotificationDeliveries(ctx, NotificationDeliveryQuery{Status: NotificationDeliverySent, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "b", deliveries[0].Receiver)
+}
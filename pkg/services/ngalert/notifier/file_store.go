@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"strings"
 
 	alertingNotify "github.com/grafana/alerting/notify"
 
@@ -15,6 +16,10 @@ const (
 	KVNamespace             = "alertmanager"
 	NotificationLogFilename = "notifications"
 	SilencesFilename        = "silences"
+
+	SilenceTemplatesFilename = "silence_templates"
+	SilenceSchedulesFilename = "silence_schedules"
+	DeliveryLogFilename      = "delivery_log"
 )
 
 // FileStore is in charge of persisting the alertmanager files to the database.
@@ -42,6 +47,44 @@ func (fileStore *FileStore) GetNotificationLog(ctx context.Context) (string, err
 	return fileStore.contentFor(ctx, NotificationLogFilename)
 }
 
+// GetSilenceTemplates returns the content of the silence templates file from kvstore.
+func (fileStore *FileStore) GetSilenceTemplates(ctx context.Context) (string, error) {
+	return fileStore.contentFor(ctx, SilenceTemplatesFilename)
+}
+
+// GetSilenceSchedule returns the content of the file of a silence schedule from kvstore.
+func (fileStore *FileStore) GetSilenceSchedule(ctx context.Context, uid string) (string, error) {
+	return fileStore.contentFor(ctx, silenceScheduleFilename(uid))
+}
+
+// GetSilenceSchedules returns the content of the files of all the silence schedules from kvstore, by schedule UID.
+func (fileStore *FileStore) GetSilenceSchedules(ctx context.Context) (map[string]string, error) {
+	return fileStore.contentsFor(ctx, silenceScheduleFilename(""))
+}
+
+// silenceScheduleFilename returns the kvstore key of a silence schedule. Each schedule is stored in its own key, so
+// that the instances of a high availability setup can save different schedules at the same time.
+func silenceScheduleFilename(uid string) string {
+	return SilenceSchedulesFilename + "." + uid
+}
+
+// GetDeliveryLog returns the content of the notification delivery log file of a Grafana instance from kvstore.
+func (fileStore *FileStore) GetDeliveryLog(ctx context.Context, instance string) (string, error) {
+	return fileStore.contentFor(ctx, deliveryLogFilename(instance))
+}
+
+// GetDeliveryLogs returns the content of the notification delivery log files of all Grafana instances from kvstore,
+// by instance.
+func (fileStore *FileStore) GetDeliveryLogs(ctx context.Context) (map[string]string, error) {
+	return fileStore.contentsFor(ctx, deliveryLogFilename(""))
+}
+
+// deliveryLogFilename returns the kvstore key of the notification delivery log of a Grafana instance. Every instance
+// records the notifications it sends, so each one persists its own log.
+func deliveryLogFilename(instance string) string {
+	return DeliveryLogFilename + "." + instance
+}
+
 // contentFor returns the content for the given Alertmanager kvstore key.
 func (fileStore *FileStore) contentFor(ctx context.Context, filename string) (string, error) {
 	// Then, let's attempt to read it from the database.
@@ -64,6 +107,27 @@ func (fileStore *FileStore) contentFor(ctx context.Context, filename string) (st
 	return string(bytes), err
 }
 
+// contentsFor returns the content for the Alertmanager kvstore keys that start with the prefix, by the rest of the key.
+func (fileStore *FileStore) contentsFor(ctx context.Context, prefix string) (map[string]string, error) {
+	keys, err := fileStore.kv.Keys(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("error listing files '%s*' from database: %w", prefix, err)
+	}
+	result := make(map[string]string, len(keys))
+	for _, key := range keys {
+		name, ok := strings.CutPrefix(key.Key, prefix)
+		if !ok {
+			continue
+		}
+		content, err := fileStore.contentFor(ctx, key.Key)
+		if err != nil {
+			return nil, err
+		}
+		result[name] = content
+	}
+	return result, nil
+}
+
 // SaveSilences saves the silences to the database and returns the size of the unencoded state.
 func (fileStore *FileStore) SaveSilences(ctx context.Context, st alertingNotify.State) (int64, error) {
 	return fileStore.persist(ctx, SilencesFilename, st)
@@ -74,6 +138,27 @@ func (fileStore *FileStore) SaveNotificationLog(ctx context.Context, st alerting
 	return fileStore.persist(ctx, NotificationLogFilename, st)
 }
 
+// SaveSilenceTemplates saves the silence templates to the database and returns the size of the unencoded state.
+func (fileStore *FileStore) SaveSilenceTemplates(ctx context.Context, st alertingNotify.State) (int64, error) {
+	return fileStore.persist(ctx, SilenceTemplatesFilename, st)
+}
+
+// SaveSilenceSchedule saves a silence schedule to the database and returns the size of the unencoded state.
+func (fileStore *FileStore) SaveSilenceSchedule(ctx context.Context, uid string, st alertingNotify.State) (int64, error) {
+	return fileStore.persist(ctx, silenceScheduleFilename(uid), st)
+}
+
+// SaveDeliveryLog saves the notification delivery log of a Grafana instance to the database and returns the size of the
+// unencoded state.
+func (fileStore *FileStore) SaveDeliveryLog(ctx context.Context, instance string, st alertingNotify.State) (int64, error) {
+	return fileStore.persist(ctx, deliveryLogFilename(instance), st)
+}
+
+// DeleteSilenceSchedule deletes a silence schedule from the database.
+func (fileStore *FileStore) DeleteSilenceSchedule(ctx context.Context, uid string) error {
+	return fileStore.kv.Del(ctx, silenceScheduleFilename(uid))
+}
+
 // persist takes care of persisting the binary representation of internal state to the database as a base64 encoded string.
 func (fileStore *FileStore) persist(ctx context.Context, filename string, st alertingNotify.State) (int64, error) {
 	var size int64
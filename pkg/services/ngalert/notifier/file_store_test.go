@@ -106,3 +106,28 @@ func TestFileStore_NotificationLog(t *testing.T) {
 		t.Errorf("Unexpected Diff: %v", cmp.Diff(newState, decoded))
 	}
 }
+
+func TestFileStore_DeliveryLog(t *testing.T) {
+	store := fakes.NewFakeKVStore(t)
+	ctx := context.Background()
+	fs := NewFileStore(1, store)
+
+	_, err := fs.SaveDeliveryLog(ctx, "instance-a", deliveryLogState{{Receiver: "a"}})
+	require.NoError(t, err)
+	_, err = fs.SaveDeliveryLog(ctx, "instance-b", deliveryLogState{{Receiver: "b"}})
+	require.NoError(t, err)
+	_, err = NewFileStore(2, store).SaveDeliveryLog(ctx, "instance-a", deliveryLogState{{Receiver: "other org"}})
+	require.NoError(t, err)
+
+	// Each instance reads its own log.
+	content, err := fs.GetDeliveryLog(ctx, "instance-a")
+	require.NoError(t, err)
+	require.JSONEq(t, `[{"timestamp":"0001-01-01T00:00:00Z","receiver":"a","integration":"","integrationIndex":0,"groupKey":"","payloadHash":"","alerts":0,"status":"","retries":0,"duration":0}]`, content)
+
+	// The logs of all the instances of the organization are returned by instance.
+	logs, err := fs.GetDeliveryLogs(ctx)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Contains(t, logs["instance-a"], `"receiver":"a"`)
+	require.Contains(t, logs["instance-b"], `"receiver":"b"`)
+}
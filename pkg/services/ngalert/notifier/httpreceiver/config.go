@@ -0,0 +1,272 @@
+package httpreceiver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	tmpltext "text/template"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	"github.com/prometheus/alertmanager/template"
+	"golang.org/x/net/http/httpguts"
+)
+
+// Type is the type of the HTTP contact point.
+const Type = "http"
+
+// AuthType is the scheme used to authenticate the requests.
+type AuthType string
+
+const (
+	AuthNone   AuthType = "none"
+	AuthBasic  AuthType = "basic"
+	AuthBearer AuthType = "bearer"
+	AuthOAuth2 AuthType = "oauth2"
+	AuthHMAC   AuthType = "hmac"
+)
+
+const (
+	DefaultContentType = "application/json"
+	DefaultHMACHeader  = "X-Grafana-Signature"
+)
+
+// Config is the configuration of the HTTP contact point.
+type Config struct {
+	URL         string
+	HTTPMethod  string
+	Headers     map[string]string
+	ContentType string
+	// Body is the template of the body of the request. If empty, the body is the JSON of the notification data.
+	Body string
+
+	Auth              AuthType
+	BasicAuthUser     string
+	BasicAuthPassword string
+	BearerToken       string
+	OAuth2            OAuth2Config
+	HMAC              HMACConfig
+
+	TLS *tls.Config
+}
+
+// OAuth2Config is the configuration of the OAuth2 client credentials flow.
+type OAuth2Config struct {
+	ClientID     string
+	ClientSecret string
+	TokenURL     string
+	Scopes       []string
+}
+
+// HMACConfig is the configuration of the HMAC signature of the requests. The signature is the hex-encoded HMAC-SHA256
+// of the body, prefixed by the timestamp and a dot if TimestampHeader is set.
+type HMACConfig struct {
+	Secret          string
+	Header          string
+	TimestampHeader string
+}
+
+// settings are the settings of the contact point as they are stored. Secure settings can be provided in plain text in
+// the settings, for example in file provisioning, in which case they are used as fallback.
+type settings struct {
+	URL         string            `json:"url,omitempty"`
+	HTTPMethod  string            `json:"httpMethod,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	ContentType string            `json:"contentType,omitempty"`
+	Body        string            `json:"body,omitempty"`
+
+	AuthType            string `json:"authType,omitempty"`
+	Username            string `json:"username,omitempty"`
+	Password            string `json:"password,omitempty"`
+	BearerToken         string `json:"bearerToken,omitempty"`
+	OAuth2ClientID      string `json:"oauth2ClientId,omitempty"`
+	OAuth2ClientSecret  string `json:"oauth2ClientSecret,omitempty"`
+	OAuth2TokenURL      string `json:"oauth2TokenUrl,omitempty"`
+	OAuth2Scopes        string `json:"oauth2Scopes,omitempty"`
+	HMACSecret          string `json:"hmacSecret,omitempty"`
+	HMACHeader          string `json:"hmacHeader,omitempty"`
+	HMACTimestampHeader string `json:"hmacTimestampHeader,omitempty"`
+
+	TLSCACertificate      string `json:"tlsCACertificate,omitempty"`
+	TLSClientCertificate  string `json:"tlsClientCertificate,omitempty"`
+	TLSClientKey          string `json:"tlsClientKey,omitempty"`
+	TLSServerName         string `json:"tlsServerName,omitempty"`
+	TLSInsecureSkipVerify bool   `json:"tlsInsecureSkipVerify,omitempty"`
+}
+
+// NewConfig parses and validates the settings of the HTTP contact point. The secure settings are decrypted with decrypt.
+func NewConfig(jsonData json.RawMessage, decrypt receivers.DecryptFunc) (Config, error) {
+	var s settings
+	if err := json.Unmarshal(jsonData, &s); err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	if s.URL == "" {
+		return Config{}, errors.New("required field 'url' is not specified")
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return Config{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Config{}, errors.New("invalid URL: must be an absolute http or https URL")
+	}
+	cfg := Config{
+		URL:         s.URL,
+		HTTPMethod:  strings.ToUpper(s.HTTPMethod),
+		ContentType: s.ContentType,
+		Body:        s.Body,
+		Headers:     make(map[string]string, len(s.Headers)),
+		Auth:        AuthType(s.AuthType),
+	}
+
+	switch cfg.HTTPMethod {
+	case "":
+		cfg.HTTPMethod = http.MethodPost
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return Config{}, fmt.Errorf("invalid HTTP method '%s', must be one of POST, PUT or PATCH", s.HTTPMethod)
+	}
+	if cfg.ContentType == "" {
+		cfg.ContentType = DefaultContentType
+	}
+	if cfg.Body != "" {
+		if err := validateTemplate(cfg.Body); err != nil {
+			return Config{}, fmt.Errorf("invalid body template: %w", err)
+		}
+	}
+	for k, v := range s.Headers {
+		if !httpguts.ValidHeaderFieldName(k) {
+			return Config{}, fmt.Errorf("invalid header name '%s'", k)
+		}
+		if err := validateTemplate(v); err != nil {
+			return Config{}, fmt.Errorf("invalid template of header '%s': %w", k, err)
+		}
+		cfg.Headers[textproto.CanonicalMIMEHeaderKey(k)] = v
+	}
+
+	switch cfg.Auth {
+	case "":
+		cfg.Auth = AuthNone
+	case AuthNone:
+	case AuthBasic:
+		cfg.BasicAuthUser = s.Username
+		cfg.BasicAuthPassword = decrypt("password", s.Password)
+		if cfg.BasicAuthUser == "" || cfg.BasicAuthPassword == "" {
+			return Config{}, errors.New("basic authentication requires both 'username' and 'password'")
+		}
+	case AuthBearer:
+		cfg.BearerToken = decrypt("bearerToken", s.BearerToken)
+		if cfg.BearerToken == "" {
+			return Config{}, errors.New("bearer authentication requires 'bearerToken'")
+		}
+	case AuthOAuth2:
+		cfg.OAuth2 = OAuth2Config{
+			ClientID:     s.OAuth2ClientID,
+			ClientSecret: decrypt("oauth2ClientSecret", s.OAuth2ClientSecret),
+			TokenURL:     s.OAuth2TokenURL,
+		}
+		for _, scope := range strings.Split(s.OAuth2Scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				cfg.OAuth2.Scopes = append(cfg.OAuth2.Scopes, scope)
+			}
+		}
+		if cfg.OAuth2.ClientID == "" || cfg.OAuth2.ClientSecret == "" || cfg.OAuth2.TokenURL == "" {
+			return Config{}, errors.New("OAuth2 authentication requires 'oauth2ClientId', 'oauth2ClientSecret' and 'oauth2TokenUrl'")
+		}
+		if u, err := url.Parse(cfg.OAuth2.TokenURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return Config{}, errors.New("invalid OAuth2 token URL: must be an absolute http or https URL")
+		}
+	case AuthHMAC:
+		cfg.HMAC = HMACConfig{
+			Secret:          decrypt("hmacSecret", s.HMACSecret),
+			Header:          s.HMACHeader,
+			TimestampHeader: s.HMACTimestampHeader,
+		}
+		if cfg.HMAC.Secret == "" {
+			return Config{}, errors.New("HMAC signature requires 'hmacSecret'")
+		}
+		if cfg.HMAC.Header == "" {
+			cfg.HMAC.Header = DefaultHMACHeader
+		}
+		for _, h := range []string{cfg.HMAC.Header, cfg.HMAC.TimestampHeader} {
+			if h == "" {
+				continue
+			}
+			if !httpguts.ValidHeaderFieldName(h) {
+				return Config{}, fmt.Errorf("invalid header name '%s'", h)
+			}
+			if _, ok := cfg.Headers[textproto.CanonicalMIMEHeaderKey(h)]; ok {
+				return Config{}, fmt.Errorf("header '%s' is set by the HMAC signature and cannot be configured", h)
+			}
+		}
+	default:
+		return Config{}, fmt.Errorf("invalid authentication type '%s', must be one of none, basic, bearer, oauth2 or hmac", s.AuthType)
+	}
+	if _, ok := cfg.Headers["Authorization"]; ok && (cfg.Auth == AuthBasic || cfg.Auth == AuthBearer || cfg.Auth == AuthOAuth2) {
+		return Config{}, fmt.Errorf("header 'Authorization' cannot be configured with %s authentication", cfg.Auth)
+	}
+
+	cfg.TLS, err = newTLSConfig(s, decrypt)
+	if err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func newTLSConfig(s settings, decrypt receivers.DecryptFunc) (*tls.Config, error) {
+	cfg := &tls.Config{
+		Renegotiation: tls.RenegotiateFreelyAsClient,
+		ServerName:    s.TLSServerName,
+		// nolint:gosec
+		InsecureSkipVerify: s.TLSInsecureSkipVerify,
+	}
+	if s.TLSCACertificate != "" {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM([]byte(s.TLSCACertificate)) {
+			return nil, errors.New("invalid TLS CA certificate: no PEM encoded certificate found")
+		}
+	}
+	clientKey := decrypt("tlsClientKey", s.TLSClientKey)
+	if s.TLSClientCertificate != "" || clientKey != "" {
+		if s.TLSClientCertificate == "" || clientKey == "" {
+			return nil, errors.New("TLS client authentication requires both 'tlsClientCertificate' and 'tlsClientKey'")
+		}
+		cert, err := tls.X509KeyPair([]byte(s.TLSClientCertificate), []byte(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS client certificate or key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// validateTemplate parses the template with the functions of the notification templates.
+func validateTemplate(text string) error {
+	_, err := tmpltext.New("").Option("missingkey=zero").Funcs(tmpltext.FuncMap(template.DefaultFuncs)).Parse(text)
+	return err
+}
+
+// NewConfigFromIntegration parses and validates the settings of an HTTP integration. The secure settings of the
+// integration are base64-encoded encrypted values that are decrypted with decryptFn.
+func NewConfigFromIntegration(ctx context.Context, integration *alertingNotify.GrafanaIntegrationConfig, decryptFn alertingNotify.GetDecryptedValueFn) (Config, error) {
+	secureSettings := make(map[string][]byte, len(integration.SecureSettings))
+	for k, v := range integration.SecureSettings {
+		d, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to decode secure setting '%s': %w", k, err)
+		}
+		secureSettings[k] = d
+	}
+	return NewConfig(integration.Settings, func(key string, fallback string) string {
+		return decryptFn(ctx, secureSettings, key, fallback)
+	})
+}
@@ -0,0 +1,220 @@
+package httpreceiver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConfig(t *testing.T) {
+	cert, key := generateCertificate(t)
+
+	testCases := []struct {
+		name           string
+		settings       string
+		secureSettings map[string]string
+		expected       func(cfg *Config)
+		expectedErr    string
+	}{
+		{
+			name:        "url is required",
+			settings:    `{}`,
+			expectedErr: "required field 'url' is not specified",
+		},
+		{
+			name:        "url must be an http URL",
+			settings:    `{"url": "ftp://example.com"}`,
+			expectedErr: "invalid URL: must be an absolute http or https URL",
+		},
+		{
+			name:     "defaults",
+			settings: `{"url": "https://example.com/alerts"}`,
+			expected: func(cfg *Config) {
+				assert.Equal(t, http.MethodPost, cfg.HTTPMethod)
+				assert.Equal(t, DefaultContentType, cfg.ContentType)
+				assert.Equal(t, AuthNone, cfg.Auth)
+				assert.Empty(t, cfg.Body)
+				assert.NotNil(t, cfg.TLS)
+			},
+		},
+		{
+			name:     "method, headers and body",
+			settings: `{"url": "https://example.com/alerts", "httpMethod": "patch", "headers": {"x-team": "{{ .CommonLabels.team }}"}, "body": "{\"status\": \"{{ .Status }}\"}"}`,
+			expected: func(cfg *Config) {
+				assert.Equal(t, http.MethodPatch, cfg.HTTPMethod)
+				assert.Equal(t, map[string]string{"X-Team": "{{ .CommonLabels.team }}"}, cfg.Headers)
+				assert.Equal(t, `{"status": "{{ .Status }}"}`, cfg.Body)
+			},
+		},
+		{
+			name:        "invalid method",
+			settings:    `{"url": "https://example.com/alerts", "httpMethod": "GET"}`,
+			expectedErr: "invalid HTTP method 'GET', must be one of POST, PUT or PATCH",
+		},
+		{
+			name:        "invalid body template",
+			settings:    `{"url": "https://example.com/alerts", "body": "{{ .Status "}`,
+			expectedErr: "invalid body template",
+		},
+		{
+			name:        "invalid header name",
+			settings:    `{"url": "https://example.com/alerts", "headers": {"x team": "a"}}`,
+			expectedErr: "invalid header name 'x team'",
+		},
+		{
+			name:           "basic authentication",
+			settings:       `{"url": "https://example.com/alerts", "authType": "basic", "username": "user"}`,
+			secureSettings: map[string]string{"password": "secret"},
+			expected: func(cfg *Config) {
+				assert.Equal(t, AuthBasic, cfg.Auth)
+				assert.Equal(t, "user", cfg.BasicAuthUser)
+				assert.Equal(t, "secret", cfg.BasicAuthPassword)
+			},
+		},
+		{
+			name:        "basic authentication requires a password",
+			settings:    `{"url": "https://example.com/alerts", "authType": "basic", "username": "user"}`,
+			expectedErr: "basic authentication requires both 'username' and 'password'",
+		},
+		{
+			name:     "bearer token falls back to the settings",
+			settings: `{"url": "https://example.com/alerts", "authType": "bearer", "bearerToken": "token"}`,
+			expected: func(cfg *Config) {
+				assert.Equal(t, "token", cfg.BearerToken)
+			},
+		},
+		{
+			name:           "authorization header cannot be configured with bearer authentication",
+			settings:       `{"url": "https://example.com/alerts", "authType": "bearer", "headers": {"authorization": "a"}}`,
+			secureSettings: map[string]string{"bearerToken": "token"},
+			expectedErr:    "header 'Authorization' cannot be configured with bearer authentication",
+		},
+		{
+			name:           "OAuth2 client credentials",
+			settings:       `{"url": "https://example.com/alerts", "authType": "oauth2", "oauth2ClientId": "client", "oauth2TokenUrl": "https://example.com/token", "oauth2Scopes": "a, b,"}`,
+			secureSettings: map[string]string{"oauth2ClientSecret": "secret"},
+			expected: func(cfg *Config) {
+				assert.Equal(t, OAuth2Config{ClientID: "client", ClientSecret: "secret", TokenURL: "https://example.com/token", Scopes: []string{"a", "b"}}, cfg.OAuth2)
+			},
+		},
+		{
+			name:        "OAuth2 requires a token URL",
+			settings:    `{"url": "https://example.com/alerts", "authType": "oauth2", "oauth2ClientId": "client", "oauth2ClientSecret": "secret"}`,
+			expectedErr: "OAuth2 authentication requires 'oauth2ClientId', 'oauth2ClientSecret' and 'oauth2TokenUrl'",
+		},
+		{
+			name:           "HMAC signature",
+			settings:       `{"url": "https://example.com/alerts", "authType": "hmac", "hmacTimestampHeader": "X-Timestamp"}`,
+			secureSettings: map[string]string{"hmacSecret": "secret"},
+			expected: func(cfg *Config) {
+				assert.Equal(t, HMACConfig{Secret: "secret", Header: DefaultHMACHeader, TimestampHeader: "X-Timestamp"}, cfg.HMAC)
+			},
+		},
+		{
+			name:           "HMAC headers cannot be configured",
+			settings:       `{"url": "https://example.com/alerts", "authType": "hmac", "headers": {"X-Grafana-Signature": "a"}}`,
+			secureSettings: map[string]string{"hmacSecret": "secret"},
+			expectedErr:    "header 'X-Grafana-Signature' is set by the HMAC signature and cannot be configured",
+		},
+		{
+			name:        "invalid authentication type",
+			settings:    `{"url": "https://example.com/alerts", "authType": "digest"}`,
+			expectedErr: "invalid authentication type 'digest'",
+		},
+		{
+			name:           "TLS settings",
+			settings:       mustJSON(t, map[string]any{"url": "https://example.com/alerts", "tlsCACertificate": cert, "tlsClientCertificate": cert, "tlsServerName": "server", "tlsInsecureSkipVerify": true}),
+			secureSettings: map[string]string{"tlsClientKey": key},
+			expected: func(cfg *Config) {
+				assert.NotNil(t, cfg.TLS.RootCAs)
+				assert.Len(t, cfg.TLS.Certificates, 1)
+				assert.Equal(t, "server", cfg.TLS.ServerName)
+				assert.True(t, cfg.TLS.InsecureSkipVerify)
+			},
+		},
+		{
+			name:        "invalid CA certificate",
+			settings:    `{"url": "https://example.com/alerts", "tlsCACertificate": "invalid"}`,
+			expectedErr: "invalid TLS CA certificate",
+		},
+		{
+			name:        "client certificate requires a key",
+			settings:    mustJSON(t, map[string]any{"url": "https://example.com/alerts", "tlsClientCertificate": cert}),
+			expectedErr: "TLS client authentication requires both 'tlsClientCertificate' and 'tlsClientKey'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			secureSettings := make(map[string]string, len(tc.secureSettings))
+			for k, v := range tc.secureSettings {
+				secureSettings[k] = base64.StdEncoding.EncodeToString([]byte(v))
+			}
+			integration := &alertingNotify.GrafanaIntegrationConfig{
+				Type:           Type,
+				Settings:       json.RawMessage(tc.settings),
+				SecureSettings: secureSettings,
+			}
+
+			cfg, err := NewConfigFromIntegration(context.Background(), integration, decryptForTesting)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			tc.expected(&cfg)
+		})
+	}
+}
+
+// decryptForTesting returns the secure setting as is, since the test values are not encrypted.
+func decryptForTesting(_ context.Context, sjd map[string][]byte, key string, fallback string) string {
+	if v, ok := sjd[key]; ok {
+		return string(v)
+	}
+	return fallback
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
+
+// generateCertificate returns a PEM encoded self-signed certificate and its key.
+func generateCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
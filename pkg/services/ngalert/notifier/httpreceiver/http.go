@@ -0,0 +1,192 @@
+package httpreceiver
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/types"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// maxResponseBodySize is the maximum size of the response body that is read to report errors.
+const maxResponseBodySize = 1024
+
+// Notifier sends notifications as HTTP requests whose body, headers, authentication and TLS settings are configurable.
+type Notifier struct {
+	*receivers.Base
+	log    logging.Logger
+	tmpl   *templates.Template
+	cfg    Config
+	client *http.Client
+	tokens oauth2.TokenSource
+	now    func() time.Time
+
+	// observer is notified of the body of the requests and the status code of the responses, if set.
+	observer Observer
+}
+
+// Observer is notified of the requests of the notifier, for example to record them in a delivery log. The context is
+// the context of the notification.
+type Observer interface {
+	ObserveRequest(ctx context.Context, body []byte)
+	ObserveResponse(ctx context.Context, statusCode int)
+}
+
+// WithObserver sets the observer of the requests of the notifier.
+func (n *Notifier) WithObserver(o Observer) *Notifier {
+	n.observer = o
+	return n
+}
+
+// New returns a new HTTP notifier.
+func New(cfg Config, meta receivers.Metadata, template *templates.Template, logger logging.Logger) *Notifier {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: cfg.TLS,
+			Proxy:           http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+	}
+	n := &Notifier{
+		Base:   receivers.NewBase(meta),
+		log:    logger,
+		tmpl:   template,
+		cfg:    cfg,
+		client: client,
+		now:    time.Now,
+	}
+	if cfg.Auth == AuthOAuth2 {
+		cc := clientcredentials.Config{
+			ClientID:     cfg.OAuth2.ClientID,
+			ClientSecret: cfg.OAuth2.ClientSecret,
+			TokenURL:     cfg.OAuth2.TokenURL,
+			Scopes:       cfg.OAuth2.Scopes,
+		}
+		// The token source caches the token until it expires. The token is requested with the same TLS settings as
+		// the notifications.
+		n.tokens = cc.TokenSource(context.WithValue(context.Background(), oauth2.HTTPClient, client))
+	}
+	return n
+}
+
+// Notify sends the notification. It returns true if the error is temporary and the notification should be retried.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, data := templates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+
+	var body []byte
+	if n.cfg.Body == "" {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal the notification: %w", err)
+		}
+		body = b
+	} else {
+		body = []byte(tmpl(n.cfg.Body))
+	}
+	headers := make(map[string]string, len(n.cfg.Headers))
+	for k, v := range n.cfg.Headers {
+		headers[k] = tmpl(v)
+	}
+	if tmplErr != nil {
+		return false, fmt.Errorf("failed to template the request: %w", tmplErr)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, n.cfg.HTTPMethod, n.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", n.cfg.ContentType)
+	req.Header.Set("User-Agent", "Grafana")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if err := n.authenticate(req, body); err != nil {
+		return true, err
+	}
+
+	if n.observer != nil {
+		n.observer.ObserveRequest(ctx, body)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, redactURL(err)
+	}
+	if n.observer != nil {
+		n.observer.ObserveResponse(ctx, resp.StatusCode)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			n.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	if resp.StatusCode/100 == 2 {
+		n.log.Debug("Notification sent", "statusCode", resp.StatusCode)
+		return false, nil
+	}
+
+	n.log.Debug("Notification failed", "statusCode", resp.StatusCode, "body", string(respBody))
+	retry := resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected response status %s", resp.Status)
+}
+
+// authenticate sets the headers of the authentication scheme of the request.
+func (n *Notifier) authenticate(req *http.Request, body []byte) error {
+	switch n.cfg.Auth {
+	case AuthBasic:
+		req.SetBasicAuth(n.cfg.BasicAuthUser, n.cfg.BasicAuthPassword)
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+n.cfg.BearerToken)
+	case AuthOAuth2:
+		token, err := n.tokens.Token()
+		if err != nil {
+			return fmt.Errorf("failed to get OAuth2 token: %w", redactURL(err))
+		}
+		token.SetAuthHeader(req)
+	case AuthHMAC:
+		mac := hmac.New(sha256.New, []byte(n.cfg.HMAC.Secret))
+		if n.cfg.HMAC.TimestampHeader != "" {
+			ts := strconv.FormatInt(n.now().Unix(), 10)
+			req.Header.Set(n.cfg.HMAC.TimestampHeader, ts)
+			_, _ = mac.Write([]byte(ts + "."))
+		}
+		_, _ = mac.Write(body)
+		req.Header.Set(n.cfg.HMAC.Header, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return nil
+}
+
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
+
+// redactURL removes the URL from the error, because it can contain secrets.
+func redactURL(err error) error {
+	var e *url.Error
+	if !errors.As(err, &e) {
+		return err
+	}
+	e.URL = "<redacted>"
+	return err
+}
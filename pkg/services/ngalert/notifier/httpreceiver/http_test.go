@@ -0,0 +1,232 @@
+package httpreceiver
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier(t *testing.T) {
+	tmpl, err := template.FromGlobs(nil)
+	require.NoError(t, err)
+	tmpl.ExternalURL, err = url.Parse("http://localhost")
+	require.NoError(t, err)
+
+	alerts := []*types.Alert{{
+		Alert: model.Alert{
+			Labels:   model.LabelSet{"alertname": "test", "team": "a"},
+			StartsAt: time.Now(),
+		},
+	}}
+	ctx := notify.WithGroupKey(notify.WithReceiverName(context.Background(), "receiver"), "group")
+
+	type request struct {
+		header http.Header
+		body   string
+	}
+	newServer := func(t *testing.T, status int) (*httptest.Server, chan request) {
+		requests := make(chan request, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			requests <- request{header: r.Header, body: string(b)}
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(server.Close)
+		return server, requests
+	}
+	newNotifier := func(t *testing.T, settings map[string]any) *Notifier {
+		cfg, err := NewConfig(json.RawMessage(mustJSON(t, settings)), func(_ string, fallback string) string {
+			return fallback
+		})
+		require.NoError(t, err)
+		return New(cfg, receivers.Metadata{Type: Type}, tmpl, &logging.FakeLogger{})
+	}
+
+	t.Run("sends the notification data by default", func(t *testing.T) {
+		server, requests := newServer(t, http.StatusOK)
+		n := newNotifier(t, map[string]any{"url": server.URL})
+
+		retry, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+		require.False(t, retry)
+
+		r := <-requests
+		assert.Equal(t, DefaultContentType, r.header.Get("Content-Type"))
+		var data map[string]any
+		require.NoError(t, json.Unmarshal([]byte(r.body), &data))
+		assert.Equal(t, "receiver", data["receiver"])
+		assert.Equal(t, "firing", data["status"])
+	})
+
+	t.Run("templates the body and the headers", func(t *testing.T) {
+		server, requests := newServer(t, http.StatusOK)
+		n := newNotifier(t, map[string]any{
+			"url":         server.URL,
+			"httpMethod":  http.MethodPut,
+			"contentType": "text/plain",
+			"headers":     map[string]string{"X-Team": "{{ .CommonLabels.team }}"},
+			"body":        `{{ .Status }}: {{ len .Alerts }} alerts`,
+		})
+
+		_, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+
+		r := <-requests
+		assert.Equal(t, "firing: 1 alerts", r.body)
+		assert.Equal(t, "a", r.header.Get("X-Team"))
+		assert.Equal(t, "text/plain", r.header.Get("Content-Type"))
+	})
+
+	t.Run("server errors are retried", func(t *testing.T) {
+		server, _ := newServer(t, http.StatusServiceUnavailable)
+		retry, err := newNotifier(t, map[string]any{"url": server.URL}).Notify(ctx, alerts...)
+		require.Error(t, err)
+		require.True(t, retry)
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		server, _ := newServer(t, http.StatusBadRequest)
+		retry, err := newNotifier(t, map[string]any{"url": server.URL}).Notify(ctx, alerts...)
+		require.Error(t, err)
+		require.False(t, retry)
+	})
+
+	t.Run("the observer is notified of the request and the response", func(t *testing.T) {
+		server, requests := newServer(t, http.StatusServiceUnavailable)
+		o := &fakeObserver{}
+		n := newNotifier(t, map[string]any{"url": server.URL, "body": "{{ .Status }}"}).WithObserver(o)
+
+		_, err := n.Notify(ctx, alerts...)
+		require.Error(t, err)
+		assert.Equal(t, (<-requests).body, string(o.body))
+		assert.Equal(t, http.StatusServiceUnavailable, o.statusCode)
+	})
+
+	t.Run("basic authentication", func(t *testing.T) {
+		server, requests := newServer(t, http.StatusOK)
+		n := newNotifier(t, map[string]any{"url": server.URL, "authType": "basic", "username": "user", "password": "secret"})
+
+		_, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+
+		req := &http.Request{Header: (<-requests).header}
+		user, password, ok := req.BasicAuth()
+		require.True(t, ok)
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "secret", password)
+	})
+
+	t.Run("bearer authentication", func(t *testing.T) {
+		server, requests := newServer(t, http.StatusOK)
+		n := newNotifier(t, map[string]any{"url": server.URL, "authType": "bearer", "bearerToken": "token"})
+
+		_, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer token", (<-requests).header.Get("Authorization"))
+	})
+
+	t.Run("OAuth2 client credentials", func(t *testing.T) {
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+			assert.Equal(t, "scope", r.Form.Get("scope"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "oauth2-token", "token_type": "Bearer", "expires_in": 3600}`))
+		}))
+		t.Cleanup(tokenServer.Close)
+		server, requests := newServer(t, http.StatusOK)
+		n := newNotifier(t, map[string]any{
+			"url":                server.URL,
+			"authType":           "oauth2",
+			"oauth2ClientId":     "client",
+			"oauth2ClientSecret": "secret",
+			"oauth2TokenUrl":     tokenServer.URL,
+			"oauth2Scopes":       "scope",
+		})
+
+		_, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer oauth2-token", (<-requests).header.Get("Authorization"))
+	})
+
+	t.Run("HMAC signature", func(t *testing.T) {
+		server, requests := newServer(t, http.StatusOK)
+		n := newNotifier(t, map[string]any{"url": server.URL, "authType": "hmac", "hmacSecret": "secret", "hmacTimestampHeader": "X-Timestamp"})
+		n.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+		_, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+
+		r := <-requests
+		assert.Equal(t, "1700000000", r.header.Get("X-Timestamp"))
+		mac := hmac.New(sha256.New, []byte("secret"))
+		_, _ = mac.Write([]byte("1700000000." + r.body))
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.header.Get(DefaultHMACHeader))
+	})
+
+	t.Run("mutual TLS", func(t *testing.T) {
+		cert, key := generateCertificate(t)
+		clientCAs := x509.NewCertPool()
+		require.True(t, clientCAs.AppendCertsFromPEM([]byte(cert)))
+
+		requests := make(chan *http.Request, 1)
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests <- r
+		}))
+		server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		serverCA := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+		n := newNotifier(t, map[string]any{
+			"url":                  server.URL,
+			"tlsCACertificate":     serverCA,
+			"tlsClientCertificate": cert,
+			"tlsClientKey":         key,
+		})
+		_, err := n.Notify(ctx, alerts...)
+		require.NoError(t, err)
+		r := <-requests
+		require.Len(t, r.TLS.PeerCertificates, 1)
+		assert.Equal(t, "test", r.TLS.PeerCertificates[0].Subject.CommonName)
+
+		// Without a client certificate, the server rejects the connection.
+		n = newNotifier(t, map[string]any{"url": server.URL, "tlsCACertificate": serverCA})
+		retry, err := n.Notify(ctx, alerts...)
+		require.Error(t, err)
+		require.True(t, retry)
+	})
+}
+
+type fakeObserver struct {
+	body       []byte
+	statusCode int
+}
+
+func (o *fakeObserver) ObserveRequest(_ context.Context, body []byte) {
+	o.body = body
+}
+
+func (o *fakeObserver) ObserveResponse(_ context.Context, statusCode int) {
+	o.statusCode = statusCode
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	prometheusModel "github.com/prometheus/common/model"
 
 	alertingCluster "github.com/grafana/alerting/cluster"
 
@@ -41,6 +42,10 @@ var (
 	ErrSilenceNotFound    = errutil.NotFound("alerting.notifications.silences.notFound")
 	ErrSilencesBadRequest = errutil.BadRequest("alerting.notifications.silences.badRequest")
 	ErrSilenceInternal    = errutil.Internal("alerting.notifications.silences.internal")
+
+	ErrSilenceTemplateNotFound = errutil.NotFound("alerting.notifications.silences.templates.notFound")
+	ErrSilenceTemplateConflict = errutil.Conflict("alerting.notifications.silences.templates.conflict")
+	ErrSilenceScheduleNotFound = errutil.NotFound("alerting.notifications.silences.schedules.notFound")
 )
 
 //go:generate mockery --name Alertmanager --structname AlertmanagerMock --with-expecter --output alertmanager_mock --outpkg alertmanager_mock
@@ -71,6 +76,12 @@ type Alertmanager interface {
 	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*TestReceiversResult, error)
 	TestTemplate(ctx context.Context, c apimodels.TestTemplatesConfigBodyParams) (*TestTemplatesResults, error)
 
+	// Routing
+	ExplainRouting(ctx context.Context, labelSets []prometheusModel.LabelSet) ([]RoutingExplanation, error)
+
+	// Notification deliveries
+	GetNotificationDeliveries(ctx context.Context, q NotificationDeliveryQuery) ([]NotificationDelivery, error)
+
 	// Lifecycle
 	StopAndWait()
 	Ready() bool
@@ -83,6 +94,11 @@ type MultiOrgAlertmanager struct {
 	alertmanagersMtx sync.RWMutex
 	alertmanagers    map[int64]Alertmanager
 
+	// silenceDefinitionsMtx serializes the changes to the silence templates and schedules of all organizations within this
+	// instance. The schedules are stored per key, so that the instances of a high availability setup do not overwrite each
+	// other.
+	silenceDefinitionsMtx sync.Mutex
+
 	settings       *setting.Cfg
 	featureManager featuremgmt.FeatureToggles
 	logger         log.Logger
@@ -259,6 +275,11 @@ func (moa *MultiOrgAlertmanager) LoadAndSyncAlertmanagersForOrgs(ctx context.Con
 	moa.metrics.DiscoveredConfigurations.Set(float64(len(orgIDs)))
 	moa.SyncAlertmanagersForOrgs(ctx, orgIDs)
 
+	// Only the first peer of the cluster creates the silences of the schedules, the others get them via gossip.
+	if moa.peer.Position() == 0 {
+		moa.syncSilenceSchedulesForOrgs(ctx, orgIDs, time.Now())
+	}
+
 	moa.logger.Debug("Done synchronizing Alertmanagers for orgs")
 
 	return nil
@@ -359,7 +380,7 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(ctx context.Context, o
 // saved to the kvstore after deletion on instance shutdown.
 func (moa *MultiOrgAlertmanager) cleanupOrphanLocalOrgState(ctx context.Context,
 	activeOrganizations map[int64]struct{}) {
-	storedFiles := []string{NotificationLogFilename, SilencesFilename}
+	storedFiles := []string{NotificationLogFilename, SilencesFilename, SilenceTemplatesFilename, SilenceSchedulesFilename, DeliveryLogFilename}
 	for _, fileName := range storedFiles {
 		keys, err := moa.kvStore.Keys(ctx, kvstore.AllOrganizations, KVNamespace, fileName)
 		if err != nil {
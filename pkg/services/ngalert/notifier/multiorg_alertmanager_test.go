@@ -105,6 +105,9 @@ grafana_alerting_discovered_configurations 4
 		err = mam.kvStore.Set(ctx, orgID, KVNamespace, NotificationLogFilename, "file_1")
 		require.NoError(t, err)
 
+		err = mam.kvStore.Set(ctx, orgID, KVNamespace, deliveryLogFilename("instance"), "file_1")
+		require.NoError(t, err)
+
 		// Now re run the sync job once.
 		require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
 
@@ -114,6 +117,9 @@ grafana_alerting_discovered_configurations 4
 
 		_, exists, _ = mam.kvStore.Get(ctx, orgID, KVNamespace, NotificationLogFilename)
 		require.False(t, exists)
+
+		_, exists, _ = mam.kvStore.Get(ctx, orgID, KVNamespace, deliveryLogFilename("instance"))
+		require.False(t, exists)
 	}
 }
 
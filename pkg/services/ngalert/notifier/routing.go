@@ -0,0 +1,246 @@
+package notifier
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/inhibit"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// RoutingExplanation explains how the Alertmanager routes an alert with the given labels.
+type RoutingExplanation struct {
+	Labels model.LabelSet
+	// Routes are the routes that match the alert. There is more than one route when a matching route has continue set.
+	Routes []MatchedRoute
+	// InhibitedBy contains the inhibition rules that inhibit the alert, given the alerts that are currently firing.
+	InhibitedBy []Inhibition
+}
+
+// MatchedRoute is a route that matches an alert, with the options it inherits from its parents.
+type MatchedRoute struct {
+	// Path is the path from the root route to the matched route.
+	Path     []RouteStep
+	Receiver string
+	// GroupBy contains the labels the alerts are grouped by, or "..." if they are grouped by all labels.
+	GroupBy []string
+	// GroupLabels are the labels of the aggregation group of the alert.
+	GroupLabels         model.LabelSet
+	GroupWait           time.Duration
+	GroupInterval       time.Duration
+	RepeatInterval      time.Duration
+	MuteTimeIntervals   []string
+	ActiveTimeIntervals []string
+	// Muted is true if the notifications of the route are muted at the time of the explanation. MutedBy contains the
+	// mute time intervals that are active, or the active time intervals of the route if none of them is active.
+	Muted   bool
+	MutedBy []string
+}
+
+// RouteStep is a route on the path to a matched route.
+type RouteStep struct {
+	// Index is the position of the route in the routes of its parent. It is 0 for the root route.
+	Index    int
+	Receiver string
+	Matchers []string
+	Continue bool
+}
+
+// Inhibition is an inhibition rule that inhibits an alert.
+type Inhibition struct {
+	SourceMatchers []string
+	TargetMatchers []string
+	Equal          []string
+	// SourceAlerts are the labels of the firing alerts that inhibit the alert.
+	SourceAlerts []model.LabelSet
+}
+
+// ExplainRouting explains how the Alertmanager routes alerts with the given label sets, using the configuration that
+// is currently applied, autogenerated routes included. Inhibitions are evaluated against the alerts currently in the
+// Alertmanager, and time intervals against the current time.
+func (am *alertmanager) ExplainRouting(_ context.Context, labelSets []model.LabelSet) ([]RoutingExplanation, error) {
+	var cfg *apimodels.PostableApiAlertingConfig
+	am.Base.WithLock(func() {
+		cfg = am.config
+	})
+	if cfg == nil || cfg.Route == nil {
+		return nil, ErrAlertmanagerNotReady
+	}
+
+	alerts, err := am.Base.GetAlerts(true, true, true, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	firing := make([]model.LabelSet, 0, len(alerts))
+	for _, a := range alerts {
+		if a.EndsAt != nil && time.Time(*a.EndsAt).Before(now) {
+			continue
+		}
+		ls := make(model.LabelSet, len(a.Labels))
+		for k, v := range a.Labels {
+			ls[model.LabelName(k)] = model.LabelValue(v)
+		}
+		firing = append(firing, ls)
+	}
+
+	return explainRouting(cfg.Config, firing, labelSets, now), nil
+}
+
+func explainRouting(cfg apimodels.Config, firing []model.LabelSet, labelSets []model.LabelSet, now time.Time) []RoutingExplanation {
+	root := dispatch.NewRoute(cfg.Route.AsAMRoute(), nil)
+	intervals := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals)+len(cfg.TimeIntervals))
+	for _, ti := range cfg.MuteTimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	for _, ti := range cfg.TimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	inhibitRules := make([]*inhibit.InhibitRule, 0, len(cfg.InhibitRules))
+	for _, r := range cfg.InhibitRules {
+		inhibitRules = append(inhibitRules, inhibit.NewInhibitRule(r))
+	}
+
+	result := make([]RoutingExplanation, 0, len(labelSets))
+	for _, lset := range labelSets {
+		explanation := RoutingExplanation{Labels: lset}
+		for _, m := range matchRoutes(root, lset, []RouteStep{routeStep(0, root)}) {
+			explanation.Routes = append(explanation.Routes, explainMatchedRoute(m.route, m.path, lset, intervals, now))
+		}
+		for _, r := range inhibitRules {
+			if i, ok := explainInhibition(r, lset, firing); ok {
+				explanation.InhibitedBy = append(explanation.InhibitedBy, i)
+			}
+		}
+		result = append(result, explanation)
+	}
+	return result
+}
+
+type routeMatch struct {
+	route *dispatch.Route
+	path  []RouteStep
+}
+
+// matchRoutes mirrors dispatch.Route.Match, and keeps track of the path to the matched routes.
+func matchRoutes(r *dispatch.Route, lset model.LabelSet, path []RouteStep) []routeMatch {
+	if !r.Matchers.Matches(lset) {
+		return nil
+	}
+	var all []routeMatch
+	for i, child := range r.Routes {
+		matches := matchRoutes(child, lset, append(slices.Clip(path), routeStep(i, child)))
+		all = append(all, matches...)
+		if matches != nil && !child.Continue {
+			break
+		}
+	}
+	if len(all) == 0 {
+		all = append(all, routeMatch{route: r, path: path})
+	}
+	return all
+}
+
+func routeStep(index int, r *dispatch.Route) RouteStep {
+	return RouteStep{
+		Index:    index,
+		Receiver: r.RouteOpts.Receiver,
+		Matchers: matchersToStrings(r.Matchers),
+		Continue: r.Continue,
+	}
+}
+
+func explainMatchedRoute(r *dispatch.Route, path []RouteStep, lset model.LabelSet, intervals map[string][]timeinterval.TimeInterval, now time.Time) MatchedRoute {
+	m := MatchedRoute{
+		Path:                path,
+		Receiver:            r.RouteOpts.Receiver,
+		GroupLabels:         make(model.LabelSet),
+		GroupWait:           r.RouteOpts.GroupWait,
+		GroupInterval:       r.RouteOpts.GroupInterval,
+		RepeatInterval:      r.RouteOpts.RepeatInterval,
+		MuteTimeIntervals:   r.RouteOpts.MuteTimeIntervals,
+		ActiveTimeIntervals: r.RouteOpts.ActiveTimeIntervals,
+	}
+	if r.RouteOpts.GroupByAll {
+		m.GroupBy = []string{"..."}
+	} else {
+		for ln := range r.RouteOpts.GroupBy {
+			m.GroupBy = append(m.GroupBy, string(ln))
+		}
+		sort.Strings(m.GroupBy)
+	}
+	for ln, lv := range lset {
+		if _, ok := r.RouteOpts.GroupBy[ln]; ok || r.RouteOpts.GroupByAll {
+			m.GroupLabels[ln] = lv
+		}
+	}
+
+	contains := func(name string) bool {
+		for _, ti := range intervals[name] {
+			if ti.ContainsTime(now.UTC()) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, name := range r.RouteOpts.MuteTimeIntervals {
+		if contains(name) {
+			m.MutedBy = append(m.MutedBy, name)
+		}
+	}
+	if len(m.MutedBy) == 0 && len(r.RouteOpts.ActiveTimeIntervals) > 0 && !slices.ContainsFunc(r.RouteOpts.ActiveTimeIntervals, contains) {
+		m.MutedBy = r.RouteOpts.ActiveTimeIntervals
+	}
+	m.Muted = len(m.MutedBy) > 0
+	return m
+}
+
+// explainInhibition mirrors inhibit.InhibitRule, and returns all the firing alerts that inhibit the alert instead of
+// the first one.
+func explainInhibition(r *inhibit.InhibitRule, lset model.LabelSet, firing []model.LabelSet) (Inhibition, bool) {
+	if !r.TargetMatchers.Matches(lset) {
+		return Inhibition{}, false
+	}
+	// An alert that matches both sides of the rule cannot inhibit another alert that matches both sides.
+	excludeTwoSidedMatch := r.SourceMatchers.Matches(lset)
+	i := Inhibition{
+		SourceMatchers: matchersToStrings(r.SourceMatchers),
+		TargetMatchers: matchersToStrings(r.TargetMatchers),
+	}
+	for ln := range r.Equal {
+		i.Equal = append(i.Equal, string(ln))
+	}
+	sort.Strings(i.Equal)
+
+outer:
+	for _, source := range firing {
+		if !r.SourceMatchers.Matches(source) {
+			continue
+		}
+		for ln := range r.Equal {
+			if source[ln] != lset[ln] {
+				continue outer
+			}
+		}
+		if excludeTwoSidedMatch && r.TargetMatchers.Matches(source) {
+			continue
+		}
+		i.SourceAlerts = append(i.SourceAlerts, source)
+	}
+	return i, len(i.SourceAlerts) > 0
+}
+
+func matchersToStrings(matchers labels.Matchers) []string {
+	result := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		result = append(result, m.String())
+	}
+	return result
+}
@@ -0,0 +1,139 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const routingTestConfig = `{
+	"alertmanager_config": {
+		"route": {
+			"receiver": "default",
+			"group_by": ["alertname"],
+			"group_wait": "10s",
+			"routes": [
+				{
+					"receiver": "team-a",
+					"object_matchers": [["team", "=", "a"]],
+					"group_interval": "1m",
+					"continue": true,
+					"mute_time_intervals": ["always"]
+				},
+				{
+					"receiver": "team-a-oncall",
+					"object_matchers": [["team", "=", "a"]],
+					"group_by": ["..."],
+					"routes": [
+						{
+							"receiver": "team-a-critical",
+							"object_matchers": [["severity", "=", "critical"]],
+							"repeat_interval": "1h",
+							"mute_time_intervals": ["never"]
+						}
+					]
+				}
+			]
+		},
+		"mute_time_intervals": [
+			{"name": "always", "time_intervals": [{}]}
+		],
+		"time_intervals": [
+			{"name": "never", "time_intervals": [{"years": ["1999"]}]}
+		],
+		"inhibit_rules": [
+			{
+				"source_matchers": ["severity=critical"],
+				"target_matchers": ["severity=warning"],
+				"equal": ["cluster"]
+			}
+		],
+		"receivers": [
+			{"name": "default", "grafana_managed_receiver_configs": [{"uid": "default", "name": "default", "type": "email", "settings": {"addresses": "default@example.com"}}]},
+			{"name": "team-a", "grafana_managed_receiver_configs": [{"uid": "team-a", "name": "team-a", "type": "email", "settings": {"addresses": "a@example.com"}}]},
+			{"name": "team-a-oncall", "grafana_managed_receiver_configs": [{"uid": "team-a-oncall", "name": "team-a-oncall", "type": "email", "settings": {"addresses": "oncall@example.com"}}]},
+			{"name": "team-a-critical", "grafana_managed_receiver_configs": [{"uid": "team-a-critical", "name": "team-a-critical", "type": "email", "settings": {"addresses": "critical@example.com"}}]}
+		]
+	}
+}`
+
+func TestExplainRouting(t *testing.T) {
+	cfg, err := Load([]byte(routingTestConfig))
+	require.NoError(t, err)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("alert that matches no route is routed to the root route", func(t *testing.T) {
+		lset := model.LabelSet{"alertname": "test", "team": "b"}
+		result := explainRouting(cfg.AlertmanagerConfig.Config, nil, []model.LabelSet{lset}, now)
+		require.Len(t, result, 1)
+		require.Len(t, result[0].Routes, 1)
+
+		route := result[0].Routes[0]
+		assert.Equal(t, "default", route.Receiver)
+		assert.Equal(t, []RouteStep{{Index: 0, Receiver: "default", Matchers: []string{}}}, route.Path)
+		assert.Equal(t, []string{"alertname"}, route.GroupBy)
+		assert.Equal(t, model.LabelSet{"alertname": "test"}, route.GroupLabels)
+		assert.Equal(t, 10*time.Second, route.GroupWait)
+		assert.False(t, route.Muted)
+		assert.Empty(t, result[0].InhibitedBy)
+	})
+
+	t.Run("continue routes match the next routes, and options are inherited", func(t *testing.T) {
+		lset := model.LabelSet{"alertname": "test", "team": "a", "severity": "critical"}
+		result := explainRouting(cfg.AlertmanagerConfig.Config, nil, []model.LabelSet{lset}, now)
+		require.Len(t, result, 1)
+		require.Len(t, result[0].Routes, 2)
+
+		first := result[0].Routes[0]
+		assert.Equal(t, "team-a", first.Receiver)
+		assert.Equal(t, []RouteStep{
+			{Index: 0, Receiver: "default", Matchers: []string{}},
+			{Index: 0, Receiver: "team-a", Matchers: []string{`team="a"`}, Continue: true},
+		}, first.Path)
+		assert.Equal(t, 10*time.Second, first.GroupWait)
+		assert.Equal(t, time.Minute, first.GroupInterval)
+		assert.True(t, first.Muted)
+		assert.Equal(t, []string{"always"}, first.MutedBy)
+
+		second := result[0].Routes[1]
+		assert.Equal(t, "team-a-critical", second.Receiver)
+		require.Len(t, second.Path, 3)
+		assert.Equal(t, 1, second.Path[1].Index)
+		assert.Equal(t, []string{`severity="critical"`}, second.Path[2].Matchers)
+		assert.Equal(t, []string{"..."}, second.GroupBy)
+		assert.Equal(t, lset, second.GroupLabels)
+		assert.Equal(t, time.Hour, second.RepeatInterval)
+		assert.Equal(t, []string{"never"}, second.MuteTimeIntervals)
+		assert.False(t, second.Muted, "the route is not muted outside of its mute time intervals")
+		assert.Empty(t, second.MutedBy)
+	})
+
+	t.Run("alert is inhibited by firing alerts that match the source matchers", func(t *testing.T) {
+		lset := model.LabelSet{"alertname": "test", "severity": "warning", "cluster": "eu"}
+		firing := []model.LabelSet{
+			{"alertname": "source", "severity": "critical", "cluster": "eu"},
+			{"alertname": "other-cluster", "severity": "critical", "cluster": "us"},
+			{"alertname": "not-critical", "severity": "info", "cluster": "eu"},
+		}
+		result := explainRouting(cfg.AlertmanagerConfig.Config, firing, []model.LabelSet{lset}, now)
+		require.Len(t, result, 1)
+		require.Len(t, result[0].InhibitedBy, 1)
+
+		inhibition := result[0].InhibitedBy[0]
+		assert.Equal(t, []string{`severity="critical"`}, inhibition.SourceMatchers)
+		assert.Equal(t, []string{`severity="warning"`}, inhibition.TargetMatchers)
+		assert.Equal(t, []string{"cluster"}, inhibition.Equal)
+		assert.Equal(t, []model.LabelSet{firing[0]}, inhibition.SourceAlerts)
+	})
+
+	t.Run("alert is not inhibited when no firing alert matches", func(t *testing.T) {
+		lset := model.LabelSet{"alertname": "test", "severity": "warning", "cluster": "ap"}
+		firing := []model.LabelSet{{"alertname": "source", "severity": "critical", "cluster": "eu"}}
+		result := explainRouting(cfg.AlertmanagerConfig.Config, firing, []model.LabelSet{lset}, now)
+		require.Len(t, result, 1)
+		assert.Empty(t, result[0].InhibitedBy)
+	})
+}
@@ -13,6 +13,18 @@ type sender struct {
 }
 
 func (s sender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSettings) error {
+	validation := cmd.Validation
+	// Record the payload and the status code of the response in the delivery log, if the notification is recorded.
+	if attempt := deliveryAttemptFromContext(ctx); attempt != nil {
+		attempt.setPayload(cmd.Body)
+		validation = func(body []byte, statusCode int) error {
+			attempt.setStatusCode(statusCode)
+			if cmd.Validation != nil {
+				return cmd.Validation(body, statusCode)
+			}
+			return nil
+		}
+	}
 	return s.ns.SendWebhookSync(ctx, &notifications.SendWebhookSync{
 		Url:         cmd.URL,
 		User:        cmd.User,
@@ -21,7 +33,7 @@ func (s sender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSetti
 		HttpMethod:  cmd.HTTPMethod,
 		HttpHeader:  cmd.HTTPHeader,
 		ContentType: cmd.ContentType,
-		Validation:  cmd.Validation,
+		Validation:  validation,
 	})
 }
 
@@ -0,0 +1,303 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// silenceTemplatesState is the persisted state of the silence templates of an organization.
+type silenceTemplatesState []*models.SilenceTemplate
+
+func (s silenceTemplatesState) MarshalBinary() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// silenceScheduleState is the persisted state of a silence schedule.
+type silenceScheduleState models.SilenceSchedule
+
+func (s silenceScheduleState) MarshalBinary() ([]byte, error) {
+	return json.Marshal(models.SilenceSchedule(s))
+}
+
+// ListSilenceTemplates lists the silence templates of the organization provided, sorted by name.
+func (moa *MultiOrgAlertmanager) ListSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, error) {
+	templates, err := moa.getSilenceTemplates(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(templates, func(a, b *models.SilenceTemplate) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return templates, nil
+}
+
+// GetSilenceTemplate gets a silence template for the organization and template UID provided.
+func (moa *MultiOrgAlertmanager) GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (*models.SilenceTemplate, error) {
+	templates, err := moa.getSilenceTemplates(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	idx := slices.IndexFunc(templates, func(t *models.SilenceTemplate) bool { return t.UID == uid })
+	if idx < 0 {
+		return nil, WithPublicError(ErrSilenceTemplateNotFound.Errorf("silence template %s not found", uid))
+	}
+	return templates[idx], nil
+}
+
+// SaveSilenceTemplate creates the silence template if it has no UID, or updates the existing one otherwise, and
+// returns its UID. The names of the templates are unique within the organization.
+func (moa *MultiOrgAlertmanager) SaveSilenceTemplate(ctx context.Context, orgID int64, t models.SilenceTemplate) (string, error) {
+	moa.silenceDefinitionsMtx.Lock()
+	defer moa.silenceDefinitionsMtx.Unlock()
+
+	templates, err := moa.getSilenceTemplates(ctx, orgID)
+	if err != nil {
+		return "", err
+	}
+	idx := -1
+	for i, existing := range templates {
+		if t.UID != "" && existing.UID == t.UID {
+			idx = i
+			continue
+		}
+		if existing.Name == t.Name {
+			return "", WithPublicError(ErrSilenceTemplateConflict.Errorf("silence template with name '%s' already exists", t.Name))
+		}
+	}
+	if t.UID == "" {
+		t.UID = util.GenerateShortUID()
+		templates = append(templates, &t)
+	} else if idx < 0 {
+		return "", WithPublicError(ErrSilenceTemplateNotFound.Errorf("silence template %s not found", t.UID))
+	} else {
+		templates[idx] = &t
+	}
+
+	if _, err := NewFileStore(orgID, moa.kvStore).SaveSilenceTemplates(ctx, silenceTemplatesState(templates)); err != nil {
+		return "", WithPublicError(ErrSilenceInternal.Errorf("failed to save silence templates: %w", err))
+	}
+	return t.UID, nil
+}
+
+// DeleteSilenceTemplate deletes a silence template of the organization provided. The schedules created from the
+// template are not affected.
+func (moa *MultiOrgAlertmanager) DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string) error {
+	moa.silenceDefinitionsMtx.Lock()
+	defer moa.silenceDefinitionsMtx.Unlock()
+
+	templates, err := moa.getSilenceTemplates(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	remaining := slices.DeleteFunc(templates, func(t *models.SilenceTemplate) bool { return t.UID == uid })
+	if len(remaining) == len(templates) {
+		return WithPublicError(ErrSilenceTemplateNotFound.Errorf("silence template %s not found", uid))
+	}
+
+	if _, err := NewFileStore(orgID, moa.kvStore).SaveSilenceTemplates(ctx, silenceTemplatesState(remaining)); err != nil {
+		return WithPublicError(ErrSilenceInternal.Errorf("failed to save silence templates: %w", err))
+	}
+	return nil
+}
+
+// ListSilenceSchedules lists the silence schedules of the organization provided, sorted by the start of their first
+// occurrence.
+func (moa *MultiOrgAlertmanager) ListSilenceSchedules(ctx context.Context, orgID int64) ([]*models.SilenceSchedule, error) {
+	contents, err := NewFileStore(orgID, moa.kvStore).GetSilenceSchedules(ctx)
+	if err != nil {
+		return nil, WithPublicError(ErrSilenceInternal.Errorf("failed to load silence schedules: %w", err))
+	}
+	schedules := make([]*models.SilenceSchedule, 0, len(contents))
+	for _, content := range contents {
+		s, err := decodeSilenceSchedule(content)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	slices.SortFunc(schedules, func(a, b *models.SilenceSchedule) int {
+		if c := a.StartsAt.Compare(b.StartsAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.UID, b.UID)
+	})
+	return schedules, nil
+}
+
+// GetSilenceSchedule gets a silence schedule for the organization and schedule UID provided.
+func (moa *MultiOrgAlertmanager) GetSilenceSchedule(ctx context.Context, orgID int64, uid string) (*models.SilenceSchedule, error) {
+	content, err := NewFileStore(orgID, moa.kvStore).GetSilenceSchedule(ctx, uid)
+	if err != nil {
+		return nil, WithPublicError(ErrSilenceInternal.Errorf("failed to load silence schedule: %w", err))
+	}
+	if content == "" {
+		return nil, WithPublicError(ErrSilenceScheduleNotFound.Errorf("silence schedule %s not found", uid))
+	}
+	return decodeSilenceSchedule(content)
+}
+
+// SaveSilenceSchedule creates the silence schedule if it has no UID, or updates the existing one otherwise, and returns
+// its UID. The silence of the current or next occurrence of the schedule is created right away.
+// When a schedule is updated, the silence created for the previous definition is expired.
+func (moa *MultiOrgAlertmanager) SaveSilenceSchedule(ctx context.Context, orgID int64, s models.SilenceSchedule) (string, error) {
+	moa.silenceDefinitionsMtx.Lock()
+	defer moa.silenceDefinitionsMtx.Unlock()
+
+	s.SilenceID, s.SilenceStartsAt = "", time.Time{}
+	if s.UID == "" {
+		s.UID = util.GenerateShortUID()
+	} else {
+		existing, err := moa.GetSilenceSchedule(ctx, orgID, s.UID)
+		if err != nil {
+			return "", err
+		}
+		moa.expireScheduledSilence(ctx, orgID, existing)
+	}
+
+	moa.syncSilenceSchedule(ctx, orgID, &s, time.Now())
+	if err := moa.saveSilenceSchedule(ctx, orgID, &s); err != nil {
+		return "", err
+	}
+	return s.UID, nil
+}
+
+// DeleteSilenceSchedule deletes a silence schedule of the organization provided, and expires the silence of its current
+// or next occurrence.
+func (moa *MultiOrgAlertmanager) DeleteSilenceSchedule(ctx context.Context, orgID int64, uid string) error {
+	moa.silenceDefinitionsMtx.Lock()
+	defer moa.silenceDefinitionsMtx.Unlock()
+
+	existing, err := moa.GetSilenceSchedule(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	moa.expireScheduledSilence(ctx, orgID, existing)
+
+	if err := NewFileStore(orgID, moa.kvStore).DeleteSilenceSchedule(ctx, uid); err != nil {
+		return WithPublicError(ErrSilenceInternal.Errorf("failed to delete silence schedule: %w", err))
+	}
+	return nil
+}
+
+// syncSilenceSchedulesForOrgs creates the silences of the current or next occurrence of the silence schedules of the
+// organizations provided.
+func (moa *MultiOrgAlertmanager) syncSilenceSchedulesForOrgs(ctx context.Context, orgIDs []int64, now time.Time) {
+	moa.silenceDefinitionsMtx.Lock()
+	defer moa.silenceDefinitionsMtx.Unlock()
+
+	for _, orgID := range orgIDs {
+		if _, isDisabledOrg := moa.settings.UnifiedAlerting.DisabledOrgs[orgID]; isDisabledOrg {
+			continue
+		}
+		fileStore := NewFileStore(orgID, moa.kvStore)
+		contents, err := fileStore.GetSilenceSchedules(ctx)
+		if err != nil {
+			moa.logger.Error("Failed to load silence schedules", "org", orgID, "error", err)
+			continue
+		}
+		for uid, content := range contents {
+			s, err := decodeSilenceSchedule(content)
+			if err != nil {
+				moa.logger.Error("Failed to load silence schedule", "org", orgID, "schedule", uid, "error", err)
+				continue
+			}
+			if !moa.syncSilenceSchedule(ctx, orgID, s, now) {
+				continue
+			}
+			// Another instance may have updated or deleted the schedule since it was loaded. Its silence is then
+			// already handled by that instance, so the one just created is expired rather than overwriting the change.
+			current, err := fileStore.GetSilenceSchedule(ctx, uid)
+			if err != nil || current != content {
+				moa.logger.Debug("Silence schedule changed during synchronization", "org", orgID, "schedule", uid, "error", err)
+				moa.expireScheduledSilence(ctx, orgID, s)
+				continue
+			}
+			if err := moa.saveSilenceSchedule(ctx, orgID, s); err != nil {
+				moa.logger.Error("Failed to save silence schedule", "org", orgID, "schedule", uid, "error", err)
+			}
+		}
+	}
+}
+
+// syncSilenceSchedule creates the silence of the current or next occurrence of the schedule, unless it already exists.
+// It returns true if the schedule changed.
+func (moa *MultiOrgAlertmanager) syncSilenceSchedule(ctx context.Context, orgID int64, s *models.SilenceSchedule, now time.Time) bool {
+	startsAt, endsAt, ok := s.NextOccurrence(now)
+	if !ok || (s.SilenceID != "" && s.SilenceStartsAt.Equal(startsAt)) {
+		return false
+	}
+	silenceID, err := moa.createScheduledSilence(ctx, orgID, s, startsAt, endsAt)
+	if err != nil {
+		// Leave the schedule as is, the silence is created by the next synchronization.
+		moa.logger.Error("Failed to create the silence of a silence schedule", "org", orgID, "schedule", s.UID, "startsAt", startsAt, "error", err)
+		return false
+	}
+	moa.logger.Debug("Created the silence of a silence schedule", "org", orgID, "schedule", s.UID, "silenceID", silenceID, "startsAt", startsAt, "endsAt", endsAt)
+	s.SilenceID = silenceID
+	s.SilenceStartsAt = startsAt
+	return true
+}
+
+func (moa *MultiOrgAlertmanager) createScheduledSilence(ctx context.Context, orgID int64, s *models.SilenceSchedule, startsAt, endsAt time.Time) (string, error) {
+	silence := models.Silence{
+		Silence: alertingNotify.Silence{
+			Matchers:  s.Matchers,
+			StartsAt:  util.Pointer(strfmt.DateTime(startsAt)),
+			EndsAt:    util.Pointer(strfmt.DateTime(endsAt)),
+			Comment:   util.Pointer(s.Comment),
+			CreatedBy: util.Pointer(s.CreatedBy),
+		},
+	}
+	return moa.CreateSilence(ctx, orgID, silence)
+}
+
+// expireScheduledSilence expires the silence created for the schedule, if it exists.
+func (moa *MultiOrgAlertmanager) expireScheduledSilence(ctx context.Context, orgID int64, s *models.SilenceSchedule) {
+	if s.SilenceID == "" {
+		return
+	}
+	err := moa.DeleteSilence(ctx, orgID, s.SilenceID)
+	if err != nil && !errors.Is(err, ErrSilenceNotFound) {
+		moa.logger.Warn("Failed to expire the silence of a silence schedule", "org", orgID, "schedule", s.UID, "silenceID", s.SilenceID, "error", err)
+	}
+}
+
+func (moa *MultiOrgAlertmanager) getSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, error) {
+	content, err := NewFileStore(orgID, moa.kvStore).GetSilenceTemplates(ctx)
+	if err != nil {
+		return nil, WithPublicError(ErrSilenceInternal.Errorf("failed to load silence templates: %w", err))
+	}
+	var templates silenceTemplatesState
+	if content != "" {
+		if err := json.Unmarshal([]byte(content), &templates); err != nil {
+			return nil, WithPublicError(ErrSilenceInternal.Errorf("failed to decode silence templates: %w", err))
+		}
+	}
+	return templates, nil
+}
+
+func decodeSilenceSchedule(content string) (*models.SilenceSchedule, error) {
+	var s models.SilenceSchedule
+	if err := json.Unmarshal([]byte(content), &s); err != nil {
+		return nil, WithPublicError(ErrSilenceInternal.Errorf("failed to decode silence schedule: %w", err))
+	}
+	return &s, nil
+}
+
+func (moa *MultiOrgAlertmanager) saveSilenceSchedule(ctx context.Context, orgID int64, s *models.SilenceSchedule) error {
+	if _, err := NewFileStore(orgID, moa.kvStore).SaveSilenceSchedule(ctx, s.UID, silenceScheduleState(*s)); err != nil {
+		return WithPublicError(ErrSilenceInternal.Errorf("failed to save silence schedule: %w", err))
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func equalMatchers(name, value string) amv2.Matchers {
+	return amv2.Matchers{{
+		Name:    util.Pointer(name),
+		Value:   util.Pointer(value),
+		IsRegex: util.Pointer(false),
+		IsEqual: util.Pointer(true),
+	}}
+}
+
+func TestMultiOrgAlertmanager_SilenceTemplates(t *testing.T) {
+	mam := setupMam(t, nil)
+	ctx := context.Background()
+	require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
+
+	maintenance, err := mam.SaveSilenceTemplate(ctx, 1, models.SilenceTemplate{Name: "maintenance", Matchers: equalMatchers("env", "staging")})
+	require.NoError(t, err)
+	deploy, err := mam.SaveSilenceTemplate(ctx, 1, models.SilenceTemplate{Name: "deploy", Matchers: equalMatchers("team", "a"), Duration: time.Hour})
+	require.NoError(t, err)
+
+	templates, err := mam.ListSilenceTemplates(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, deploy, templates[0].UID)
+	assert.Equal(t, time.Hour, templates[0].Duration)
+	assert.Equal(t, maintenance, templates[1].UID)
+
+	t.Run("templates are per organization", func(t *testing.T) {
+		templates, err := mam.ListSilenceTemplates(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, templates)
+	})
+
+	t.Run("names are unique", func(t *testing.T) {
+		_, err := mam.SaveSilenceTemplate(ctx, 1, models.SilenceTemplate{Name: "deploy", Matchers: equalMatchers("team", "b")})
+		require.ErrorIs(t, err, ErrSilenceTemplateConflict)
+		_, err = mam.SaveSilenceTemplate(ctx, 1, models.SilenceTemplate{UID: maintenance, Name: "deploy", Matchers: equalMatchers("team", "b")})
+		require.ErrorIs(t, err, ErrSilenceTemplateConflict)
+	})
+
+	t.Run("update existing template", func(t *testing.T) {
+		uid, err := mam.SaveSilenceTemplate(ctx, 1, models.SilenceTemplate{UID: deploy, Name: "deploy", Matchers: equalMatchers("team", "b")})
+		require.NoError(t, err)
+		require.Equal(t, deploy, uid)
+		template, err := mam.GetSilenceTemplate(ctx, 1, deploy)
+		require.NoError(t, err)
+		assert.Equal(t, equalMatchers("team", "b"), template.Matchers)
+
+		_, err = mam.SaveSilenceTemplate(ctx, 1, models.SilenceTemplate{UID: "missing", Name: "other", Matchers: equalMatchers("team", "b")})
+		require.ErrorIs(t, err, ErrSilenceTemplateNotFound)
+	})
+
+	t.Run("delete template", func(t *testing.T) {
+		require.NoError(t, mam.DeleteSilenceTemplate(ctx, 1, maintenance))
+		require.ErrorIs(t, mam.DeleteSilenceTemplate(ctx, 1, maintenance), ErrSilenceTemplateNotFound)
+		_, err := mam.GetSilenceTemplate(ctx, 1, maintenance)
+		require.ErrorIs(t, err, ErrSilenceTemplateNotFound)
+		templates, err := mam.ListSilenceTemplates(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+	})
+}
+
+func TestMultiOrgAlertmanager_SilenceSchedules(t *testing.T) {
+	mam := setupMam(t, nil)
+	ctx := context.Background()
+	require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
+
+	startsAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	schedule := models.SilenceSchedule{
+		Matchers:   equalMatchers("team", "a"),
+		Comment:    "weekly maintenance",
+		CreatedBy:  "admin",
+		StartsAt:   startsAt,
+		EndsAt:     startsAt.Add(2 * time.Hour),
+		Recurrence: &models.SilenceRecurrence{Frequency: models.SilenceFrequencyWeekly},
+	}
+
+	uid, err := mam.SaveSilenceSchedule(ctx, 1, schedule)
+	require.NoError(t, err)
+
+	saved, err := mam.GetSilenceSchedule(ctx, 1, uid)
+	require.NoError(t, err)
+	require.NotEmpty(t, saved.SilenceID, "the silence of the first occurrence should be created right away")
+	assert.True(t, startsAt.Equal(saved.SilenceStartsAt))
+
+	silence, err := mam.GetSilence(ctx, 1, saved.SilenceID)
+	require.NoError(t, err)
+	assert.Equal(t, string(types.SilenceStatePending), *silence.Status.State)
+	assert.True(t, startsAt.Equal(time.Time(*silence.StartsAt)))
+	assert.True(t, startsAt.Add(2*time.Hour).Equal(time.Time(*silence.EndsAt)))
+	assert.Equal(t, "weekly maintenance", *silence.Comment)
+	assert.Equal(t, schedule.Matchers, silence.Matchers)
+
+	t.Run("the silence of an occurrence is created once", func(t *testing.T) {
+		mam.syncSilenceSchedulesForOrgs(ctx, []int64{1}, time.Now())
+		synced, err := mam.GetSilenceSchedule(ctx, 1, uid)
+		require.NoError(t, err)
+		assert.Equal(t, saved.SilenceID, synced.SilenceID)
+	})
+
+	t.Run("the silence of the next occurrence is created after the previous one ended", func(t *testing.T) {
+		mam.syncSilenceSchedulesForOrgs(ctx, []int64{1}, startsAt.Add(3*time.Hour))
+		synced, err := mam.GetSilenceSchedule(ctx, 1, uid)
+		require.NoError(t, err)
+		require.NotEqual(t, saved.SilenceID, synced.SilenceID)
+		assert.True(t, startsAt.AddDate(0, 0, 7).Equal(synced.SilenceStartsAt))
+
+		silence, err := mam.GetSilence(ctx, 1, synced.SilenceID)
+		require.NoError(t, err)
+		assert.True(t, startsAt.AddDate(0, 0, 7).Equal(time.Time(*silence.StartsAt)))
+		saved = synced
+	})
+
+	t.Run("schedules are stored independently", func(t *testing.T) {
+		// A schedule saved by another instance in the meantime is not overwritten.
+		other := schedule
+		other.UID = "other"
+		_, err := NewFileStore(1, mam.kvStore).SaveSilenceSchedule(ctx, other.UID, silenceScheduleState(other))
+		require.NoError(t, err)
+
+		updated := *saved
+		updated.Comment = "updated maintenance"
+		_, err = mam.SaveSilenceSchedule(ctx, 1, updated)
+		require.NoError(t, err)
+
+		schedules, err := mam.ListSilenceSchedules(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, schedules, 2)
+		assert.ElementsMatch(t, []string{uid, other.UID}, []string{schedules[0].UID, schedules[1].UID})
+
+		require.NoError(t, mam.DeleteSilenceSchedule(ctx, 1, other.UID))
+		saved, err = mam.GetSilenceSchedule(ctx, 1, uid)
+		require.NoError(t, err)
+		assert.Equal(t, "updated maintenance", saved.Comment)
+	})
+
+	t.Run("delete schedule expires its silence", func(t *testing.T) {
+		require.NoError(t, mam.DeleteSilenceSchedule(ctx, 1, uid))
+		_, err := mam.GetSilenceSchedule(ctx, 1, uid)
+		require.ErrorIs(t, err, ErrSilenceScheduleNotFound)
+
+		silence, err := mam.GetSilence(ctx, 1, saved.SilenceID)
+		require.NoError(t, err)
+		assert.Equal(t, string(types.SilenceStateExpired), *silence.Status.State)
+
+		var e errutil.Error
+		require.ErrorAs(t, mam.DeleteSilenceSchedule(ctx, 1, uid), &e)
+		assert.Equal(t, errutil.StatusNotFound, e.Reason.Status())
+	})
+}
@@ -3,9 +3,11 @@ package notifier
 import (
 	"context"
 
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"golang.org/x/exp/maps"
 
 	alertingModels "github.com/grafana/alerting/models"
+	alertingNotify "github.com/grafana/alerting/notify"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -43,6 +45,16 @@ type SilenceStore interface {
 	CreateSilence(ctx context.Context, orgID int64, ps models.Silence) (string, error)
 	UpdateSilence(ctx context.Context, orgID int64, ps models.Silence) (string, error)
 	DeleteSilence(ctx context.Context, orgID int64, id string) error
+
+	ListSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, error)
+	GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (*models.SilenceTemplate, error)
+	SaveSilenceTemplate(ctx context.Context, orgID int64, t models.SilenceTemplate) (string, error)
+	DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string) error
+
+	ListSilenceSchedules(ctx context.Context, orgID int64) ([]*models.SilenceSchedule, error)
+	GetSilenceSchedule(ctx context.Context, orgID int64, uid string) (*models.SilenceSchedule, error)
+	SaveSilenceSchedule(ctx context.Context, orgID int64, s models.SilenceSchedule) (string, error)
+	DeleteSilenceSchedule(ctx context.Context, orgID int64, uid string) error
 }
 
 type RuleStore interface {
@@ -237,6 +249,160 @@ func (s *SilenceService) WithRuleMetadata(ctx context.Context, user identity.Req
 	return nil
 }
 
+// ListSilenceTemplates retrieves the silence templates that the user has access to. Like for silences, this includes
+// the rule-specific templates of the rules the user has access to as well as all general templates.
+func (s *SilenceService) ListSilenceTemplates(ctx context.Context, user identity.Requester) ([]*models.SilenceTemplate, error) {
+	templates, err := s.store.ListSilenceTemplates(ctx, user.GetOrgID())
+	if err != nil {
+		return nil, err
+	}
+
+	return filterByMatchersAccess(ctx, s.authz, user, templates, func(t *models.SilenceTemplate) amv2.Matchers {
+		return t.Matchers
+	})
+}
+
+// SaveSilenceTemplate creates a new silence template if it has no UID, or updates the existing one otherwise.
+// The user needs the permissions to create silences with the matchers of the template.
+func (s *SilenceService) SaveSilenceTemplate(ctx context.Context, user identity.Requester, t models.SilenceTemplate) (string, error) {
+	if err := t.Validate(); err != nil {
+		return "", WithPublicError(ErrSilencesBadRequest.Errorf("invalid silence template: %w", err))
+	}
+	if err := s.authz.AuthorizeCreateSilence(ctx, user, silenceWithMatchers(t.Matchers)); err != nil {
+		return "", err
+	}
+	if t.UID != "" {
+		existing, err := s.store.GetSilenceTemplate(ctx, user.GetOrgID(), t.UID)
+		if err != nil {
+			return "", err
+		}
+		if err := s.authz.AuthorizeUpdateSilence(ctx, user, silenceWithMatchers(existing.Matchers)); err != nil {
+			return "", err
+		}
+	}
+
+	return s.store.SaveSilenceTemplate(ctx, user.GetOrgID(), t)
+}
+
+// DeleteSilenceTemplate deletes a silence template by its UID.
+// The user needs the permissions to update silences with the matchers of the template.
+func (s *SilenceService) DeleteSilenceTemplate(ctx context.Context, user identity.Requester, uid string) error {
+	existing, err := s.store.GetSilenceTemplate(ctx, user.GetOrgID(), uid)
+	if err != nil {
+		return err
+	}
+	if err := s.authz.AuthorizeUpdateSilence(ctx, user, silenceWithMatchers(existing.Matchers)); err != nil {
+		return err
+	}
+
+	return s.store.DeleteSilenceTemplate(ctx, user.GetOrgID(), uid)
+}
+
+// ListSilenceSchedules retrieves the silence schedules that the user has access to. Like for silences, this includes
+// the rule-specific schedules of the rules the user has access to as well as all general schedules.
+func (s *SilenceService) ListSilenceSchedules(ctx context.Context, user identity.Requester) ([]*models.SilenceSchedule, error) {
+	schedules, err := s.store.ListSilenceSchedules(ctx, user.GetOrgID())
+	if err != nil {
+		return nil, err
+	}
+
+	return filterByMatchersAccess(ctx, s.authz, user, schedules, func(schedule *models.SilenceSchedule) amv2.Matchers {
+		return schedule.Matchers
+	})
+}
+
+// SaveSilenceSchedule creates a new silence schedule if it has no UID, or updates the existing one otherwise.
+// If the schedule refers to a template and has no matchers, the matchers and, if the schedule has none, the comment of
+// the template are copied to the schedule.
+// The user needs the same permissions as for creating or updating the silences of the schedule.
+func (s *SilenceService) SaveSilenceSchedule(ctx context.Context, user identity.Requester, schedule models.SilenceSchedule) (string, error) {
+	if schedule.TemplateUID != "" && len(schedule.Matchers) == 0 {
+		template, err := s.store.GetSilenceTemplate(ctx, user.GetOrgID(), schedule.TemplateUID)
+		if err != nil {
+			return "", err
+		}
+		schedule.Matchers = template.Matchers
+		if schedule.Comment == "" {
+			schedule.Comment = template.Comment
+		}
+	}
+	if err := schedule.Validate(); err != nil {
+		return "", WithPublicError(ErrSilencesBadRequest.Errorf("invalid silence schedule: %w", err))
+	}
+
+	silence := silenceWithMatchers(schedule.Matchers)
+	if schedule.UID == "" {
+		if err := s.authz.AuthorizeCreateSilence(ctx, user, silence); err != nil {
+			return "", err
+		}
+		return s.store.SaveSilenceSchedule(ctx, user.GetOrgID(), schedule)
+	}
+
+	if err := s.authz.AuthorizeUpdateSilence(ctx, user, silence); err != nil {
+		return "", err
+	}
+	existing, err := s.store.GetSilenceSchedule(ctx, user.GetOrgID(), schedule.UID)
+	if err != nil {
+		return "", err
+	}
+	if err := validateSilenceUpdate(silenceWithMatchers(existing.Matchers), *silence); err != nil {
+		return "", err
+	}
+	return s.store.SaveSilenceSchedule(ctx, user.GetOrgID(), schedule)
+}
+
+// DeleteSilenceSchedule deletes a silence schedule by its UID, and expires the silence of its current or next
+// occurrence.
+// The user needs the same permissions as for updating the silences of the schedule.
+func (s *SilenceService) DeleteSilenceSchedule(ctx context.Context, user identity.Requester, uid string) error {
+	existing, err := s.store.GetSilenceSchedule(ctx, user.GetOrgID(), uid)
+	if err != nil {
+		return err
+	}
+	if err := s.authz.AuthorizeUpdateSilence(ctx, user, silenceWithMatchers(existing.Matchers)); err != nil {
+		return err
+	}
+
+	return s.store.DeleteSilenceSchedule(ctx, user.GetOrgID(), uid)
+}
+
+// filterByMatchersAccess returns the items that the user could read the silences with the matchers of, in order.
+func filterByMatchersAccess[T any](ctx context.Context, authz SilenceAccessControlService, user identity.Requester, items []T, matchers func(T) amv2.Matchers) ([]T, error) {
+	byIndex := make(map[*models.Silence]int, len(items))
+	silences := make([]*models.Silence, 0, len(items))
+	for i, item := range items {
+		silence := silenceWithMatchers(matchers(item))
+		byIndex[silence] = i
+		silences = append(silences, silence)
+	}
+	allowed, err := authz.FilterByAccess(ctx, user, silences...)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make([]bool, len(items))
+	for _, silence := range allowed {
+		keep[byIndex[silence]] = true
+	}
+	result := make([]T, 0, len(allowed))
+	for i, item := range items {
+		if keep[i] {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// silenceWithMatchers returns a silence with the given matchers. It is used to authorize operations on silence
+// templates and schedules the same way as operations on the silences created from them.
+func silenceWithMatchers(matchers amv2.Matchers) *models.Silence {
+	return &models.Silence{
+		Silence: alertingNotify.Silence{
+			Matchers: matchers,
+		},
+	}
+}
+
 // validateSilenceUpdate validates the diff between an existing silence and a new silence. Currently, this is use to
 // prevent changing the rule UID matcher.
 // Alternatively, we could check WRITE permission on the old silence followed by CREATE permission on the new silence
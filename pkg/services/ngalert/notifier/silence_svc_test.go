@@ -2,9 +2,12 @@ package notifier
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 	"testing"
+	"time"
 
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -232,3 +235,119 @@ func TestUpdateSilence(t *testing.T) {
 		})
 	}
 }
+
+func TestListSilenceTemplates(t *testing.T) {
+	user := ac.BackgroundUser("test", 1, org.RoleNone, nil)
+	ruleMatchers := func(ruleUID string) amv2.Matchers {
+		return amv2.Matchers{{
+			Name:    util.Pointer(alertingmodels.RuleUIDLabel),
+			Value:   util.Pointer(ruleUID),
+			IsRegex: util.Pointer(false),
+			IsEqual: util.Pointer(true),
+		}}
+	}
+
+	t.Run("returns only the templates of the silences the user can read", func(t *testing.T) {
+		authz := fakes.FakeSilenceService{}
+		authz.FilterByAccessFunc = func(ctx context.Context, user identity.Requester, silences ...*models.Silence) ([]*models.Silence, error) {
+			result := make([]*models.Silence, 0, len(silences))
+			for _, silence := range silences {
+				if ruleUID := silence.GetRuleUID(); ruleUID == nil || *ruleUID != "forbidden" {
+					result = append(result, silence)
+				}
+			}
+			return result, nil
+		}
+		store := ngfakes.FakeSilenceStore{
+			Templates: map[string]*models.SilenceTemplate{
+				"allowed":   {UID: "allowed", Name: "allowed", Matchers: ruleMatchers("rule1")},
+				"forbidden": {UID: "forbidden", Name: "forbidden", Matchers: ruleMatchers("forbidden")},
+			},
+		}
+		svc := SilenceService{authz: &authz, store: &store}
+
+		templates, err := svc.ListSilenceTemplates(context.Background(), user)
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		assert.Equal(t, "allowed", templates[0].UID)
+	})
+}
+
+func TestSaveSilenceSchedule(t *testing.T) {
+	user := ac.BackgroundUser("test", 1, org.RoleNone, nil)
+	startsAt := time.Now().Add(time.Hour)
+	ruleMatchers := func(ruleUID string) amv2.Matchers {
+		return amv2.Matchers{{
+			Name:    util.Pointer(alertingmodels.RuleUIDLabel),
+			Value:   util.Pointer(ruleUID),
+			IsRegex: util.Pointer(false),
+			IsEqual: util.Pointer(true),
+		}}
+	}
+
+	t.Run("copies the matchers and the comment of the template", func(t *testing.T) {
+		authz := fakes.FakeSilenceService{}
+		store := ngfakes.FakeSilenceStore{
+			Templates: map[string]*models.SilenceTemplate{
+				"template": {UID: "template", Name: "maintenance", Comment: "planned maintenance", Matchers: ruleMatchers("rule1")},
+			},
+		}
+		svc := SilenceService{authz: &authz, store: &store}
+
+		uid, err := svc.SaveSilenceSchedule(context.Background(), user, models.SilenceSchedule{
+			TemplateUID: "template",
+			CreatedBy:   "test",
+			StartsAt:    startsAt,
+			EndsAt:      startsAt.Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		saved := store.Schedules[uid]
+		assert.Equal(t, ruleMatchers("rule1"), saved.Matchers)
+		assert.Equal(t, "planned maintenance", saved.Comment)
+		require.Len(t, authz.Calls, 1)
+		assert.Equal(t, "AuthorizeCreateSilence", authz.Calls[0].MethodName)
+		assert.Equal(t, ruleMatchers("rule1"), authz.Calls[0].Arguments[2].(*models.Silence).Matchers)
+	})
+
+	t.Run("is not saved if the user cannot create the silences", func(t *testing.T) {
+		authz := fakes.FakeSilenceService{}
+		authz.AuthorizeCreateSilenceFunc = func(ctx context.Context, user identity.Requester, silence *models.Silence) error {
+			return errors.New("forbidden")
+		}
+		store := ngfakes.FakeSilenceStore{}
+		svc := SilenceService{authz: &authz, store: &store}
+
+		_, err := svc.SaveSilenceSchedule(context.Background(), user, models.SilenceSchedule{
+			Matchers:  ruleMatchers("rule1"),
+			Comment:   "test",
+			CreatedBy: "test",
+			StartsAt:  startsAt,
+			EndsAt:    startsAt.Add(time.Hour),
+		})
+		require.ErrorContains(t, err, "forbidden")
+		assert.Empty(t, store.Schedules)
+	})
+
+	t.Run("updates that change rule_uid matcher error", func(t *testing.T) {
+		authz := fakes.FakeSilenceService{}
+		existing := models.SilenceSchedule{
+			UID:       "schedule",
+			Matchers:  ruleMatchers("rule1"),
+			Comment:   "test",
+			CreatedBy: "test",
+			StartsAt:  startsAt,
+			EndsAt:    startsAt.Add(time.Hour),
+		}
+		store := ngfakes.FakeSilenceStore{
+			Schedules: map[string]*models.SilenceSchedule{"schedule": &existing},
+		}
+		svc := SilenceService{authz: &authz, store: &store}
+
+		modified := existing
+		modified.Matchers = ruleMatchers("rule2")
+		_, err := svc.SaveSilenceSchedule(context.Background(), user, modified)
+		require.ErrorContains(t, err, alertingmodels.RuleUIDLabel)
+		assert.Equal(t, ruleMatchers("rule1"), store.Schedules["schedule"].Matchers)
+	})
+}
@@ -0,0 +1,300 @@
+package prom
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	// queryRefID is the RefID of the query of the Prometheus rule.
+	queryRefID = "A"
+	// valueRefID is the RefID of the math expression that captures the value of the query, so that it can be used in
+	// templates like $value in Prometheus.
+	valueRefID = "B"
+	// conditionRefID is the RefID of the condition of alerting rules, which is true for every series returned by the
+	// query, like in Prometheus.
+	conditionRefID = "C"
+
+	// queryTimeRange is the time range of the queries. Prometheus evaluates instant queries, so it only needs to be long
+	// enough for the data source to find the samples.
+	queryTimeRange = 10 * time.Minute
+)
+
+// Config configures the conversion of Prometheus rules to Grafana-managed rules.
+type Config struct {
+	// DatasourceUID is the UID of the Prometheus data source the rules query.
+	DatasourceUID string
+	// DefaultInterval is the evaluation interval of the groups that do not set one.
+	DefaultInterval time.Duration
+	// BaseInterval is the interval the evaluation intervals must be a multiple of. Intervals are rounded up to it.
+	BaseInterval time.Duration
+	// RecordingRules is true if recording rules can be converted to Grafana-managed recording rules.
+	RecordingRules bool
+}
+
+// Converter converts Prometheus rule groups to Grafana-managed rule groups. The titles of the converted rules are
+// unique among all the groups converted by the same converter.
+type Converter struct {
+	cfg    Config
+	titles map[string]struct{}
+}
+
+// NewConverter returns a converter. The titles of the converted rules are different from usedTitles, which are usually
+// the titles of the rules in the target folder.
+func NewConverter(cfg Config, usedTitles []string) *Converter {
+	titles := make(map[string]struct{}, len(usedTitles))
+	for _, t := range usedTitles {
+		titles[t] = struct{}{}
+	}
+	return &Converter{cfg: cfg, titles: titles}
+}
+
+// ConvertRuleGroup converts a Prometheus rule group to a Grafana-managed rule group. The rules that cannot be converted
+// are left out of the group. The result reports them, as well as the parts of the group and of the rules that are not
+// translated.
+func (c *Converter) ConvertRuleGroup(group apimodels.PrometheusRuleGroup) (apimodels.PostableRuleGroupConfig, apimodels.PrometheusRuleGroupImportResult) {
+	result := apimodels.PrometheusRuleGroupImportResult{
+		Name:  group.Name,
+		Rules: make([]apimodels.PrometheusRuleImportResult, 0, len(group.Rules)),
+	}
+	if group.Name == "" {
+		result.Error = "group name must not be empty"
+		return apimodels.PostableRuleGroupConfig{}, result
+	}
+
+	interval := time.Duration(group.Interval)
+	if interval == 0 {
+		interval = c.cfg.DefaultInterval
+	}
+	if base := c.cfg.BaseInterval; base > 0 && interval%base != 0 {
+		rounded := (interval/base + 1) * base
+		result.Warnings = append(result.Warnings, fmt.Sprintf("interval %s is rounded up to %s, a multiple of the base interval %s", model.Duration(interval), model.Duration(rounded), model.Duration(base)))
+		interval = rounded
+	}
+	var offset time.Duration
+	if group.QueryOffset != nil {
+		offset = time.Duration(*group.QueryOffset)
+	}
+	if group.Limit > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("limit %d is not supported and is ignored", group.Limit))
+	}
+
+	converted := apimodels.PostableRuleGroupConfig{
+		Name:     group.Name,
+		Interval: model.Duration(interval),
+	}
+	for _, rule := range group.Rules {
+		node, ruleResult := c.convertRule(rule, group.Labels, offset)
+		result.Rules = append(result.Rules, ruleResult)
+		if ruleResult.Error == "" {
+			converted.Rules = append(converted.Rules, node)
+		}
+	}
+	if len(converted.Rules) == 0 && result.Error == "" {
+		result.Error = "the group has no rule that can be converted"
+	}
+	return converted, result
+}
+
+func (c *Converter) convertRule(rule apimodels.ApiRuleNode, groupLabels map[string]string, offset time.Duration) (apimodels.PostableExtendedRuleNode, apimodels.PrometheusRuleImportResult) {
+	result := apimodels.PrometheusRuleImportResult{Name: rule.Alert}
+	if rule.Record != "" {
+		result.Name = rule.Record
+	}
+	fail := func(err error) (apimodels.PostableExtendedRuleNode, apimodels.PrometheusRuleImportResult) {
+		result.Error = err.Error()
+		result.Warnings = nil
+		return apimodels.PostableExtendedRuleNode{}, result
+	}
+
+	if (rule.Alert == "") == (rule.Record == "") {
+		return fail(errors.New("a rule must have either an alert or a record name"))
+	}
+	if rule.Expr == "" {
+		return fail(errors.New("expression must not be empty"))
+	}
+	if _, err := parser.ParseExpr(rule.Expr); err != nil {
+		return fail(fmt.Errorf("invalid expression: %w", err))
+	}
+
+	var labels map[string]string
+	if len(groupLabels) > 0 || len(rule.Labels) > 0 {
+		labels = make(map[string]string, len(groupLabels)+len(rule.Labels))
+		maps.Copy(labels, groupLabels)
+		maps.Copy(labels, rule.Labels)
+	}
+
+	query := c.query(rule.Expr, offset)
+	grafanaRule := &apimodels.PostableGrafanaRule{}
+	var annotations map[string]string
+	if rule.Record != "" {
+		if !c.cfg.RecordingRules {
+			return fail(errors.New("recording rules are not enabled in Grafana"))
+		}
+		grafanaRule.Data = []apimodels.AlertQuery{query}
+		grafanaRule.Record = &apimodels.Record{Metric: rule.Record, From: queryRefID}
+		result.Warnings = append(result.Warnings, "the recorded metric is written to the remote write target configured for Grafana")
+	} else {
+		var err error
+		if labels, err = translateTemplates(labels, &result.Warnings); err != nil {
+			return fail(err)
+		}
+		if annotations, err = translateTemplates(rule.Annotations, &result.Warnings); err != nil {
+			return fail(err)
+		}
+		if rule.KeepFiringFor != nil && *rule.KeepFiringFor > 0 {
+			result.Warnings = append(result.Warnings, "keep_firing_for is not supported and is ignored")
+		}
+		grafanaRule.Condition = conditionRefID
+		grafanaRule.Data = []apimodels.AlertQuery{
+			query,
+			mathExpression(valueRefID, "$"+queryRefID),
+			mathExpression(conditionRefID, fmt.Sprintf("is_number($%[1]s) || is_nan($%[1]s) || is_inf($%[1]s)", valueRefID)),
+		}
+		// Like in Prometheus, no series means no alert.
+		grafanaRule.NoDataState = apimodels.OK
+		grafanaRule.ExecErrState = apimodels.ErrorErrState
+	}
+
+	grafanaRule.Title = c.uniqueTitle(result.Name)
+	if grafanaRule.Title != result.Name {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("renamed to '%s' because the title is already used", grafanaRule.Title))
+	}
+	result.Title = grafanaRule.Title
+
+	return apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			For:         rule.For,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		GrafanaManagedAlert: grafanaRule,
+	}, result
+}
+
+// query returns the instant query of the expression of a rule.
+func (c *Converter) query(expression string, offset time.Duration) apimodels.AlertQuery {
+	m, _ := json.Marshal(map[string]any{
+		"refId":   queryRefID,
+		"expr":    expression,
+		"instant": true,
+		"range":   false,
+	})
+	return apimodels.AlertQuery{
+		RefID:         queryRefID,
+		DatasourceUID: c.cfg.DatasourceUID,
+		RelativeTimeRange: apimodels.RelativeTimeRange{
+			From: apimodels.Duration(queryTimeRange + offset),
+			To:   apimodels.Duration(offset),
+		},
+		Model: m,
+	}
+}
+
+func mathExpression(refID, expression string) apimodels.AlertQuery {
+	m, _ := json.Marshal(map[string]any{
+		"refId":      refID,
+		"type":       "math",
+		"expression": expression,
+		"datasource": map[string]string{
+			"type": expr.DatasourceType,
+			"uid":  expr.DatasourceUID,
+		},
+	})
+	return apimodels.AlertQuery{
+		RefID:         refID,
+		DatasourceUID: expr.DatasourceUID,
+		Model:         m,
+	}
+}
+
+// uniqueTitle returns the name, or the name with a number if it is already the title of another rule.
+func (c *Converter) uniqueTitle(name string) string {
+	title := name
+	for i := 2; ; i++ {
+		if _, ok := c.titles[title]; !ok {
+			break
+		}
+		title = fmt.Sprintf("%s (%d)", name, i)
+	}
+	c.titles[title] = struct{}{}
+	return title
+}
+
+var (
+	templateActionRegex     = regexp.MustCompile(`(?s){{.*?}}`)
+	valueVariableRegex      = regexp.MustCompile(`\$value\b`)
+	externalURLRegex        = regexp.MustCompile(`\$externalURL\b`)
+	externalLabelsRegex     = regexp.MustCompile(`\$externalLabels\b|\.ExternalLabels\b`)
+	queryFunctionRegex      = regexp.MustCompile(`(^|[\s(|{])query\s`)
+	dataValueFieldRegex     = regexp.MustCompile(`(^|[\s(|{])\.Value\b`)
+	translatedValueVariable = fmt.Sprintf("$values.%s.Value", valueRefID)
+)
+
+// translateTemplates translates the templates of the labels or annotations of a Prometheus alerting rule to Grafana
+// templates. It appends the differences that cannot be translated to warnings.
+func translateTemplates(templates map[string]string, warnings *[]string) (map[string]string, error) {
+	if templates == nil {
+		return nil, nil
+	}
+	result := make(map[string]string, len(templates))
+	for key, tmpl := range templates {
+		translated, err := translateTemplate(tmpl, warnings)
+		if err != nil {
+			return nil, fmt.Errorf("cannot translate the template of '%s': %w", key, err)
+		}
+		result[key] = translated
+	}
+	return result, nil
+}
+
+// translateTemplate translates a Prometheus template to a Grafana template. Grafana templates support the same
+// functions and the $labels variable, but:
+//   - $value is the evaluation string of the rule, so it is replaced with the value of the query.
+//   - $externalURL is not defined, so it is replaced with the externalURL function, which returns the URL of Grafana.
+//   - $externalLabels is not defined and cannot be translated.
+//   - the query function returns no result.
+func translateTemplate(tmpl string, warnings *[]string) (string, error) {
+	if !strings.Contains(tmpl, "{{") {
+		return tmpl, nil
+	}
+	var err error
+	translated := templateActionRegex.ReplaceAllStringFunc(tmpl, func(action string) string {
+		if externalLabelsRegex.MatchString(action) {
+			err = errors.New("$externalLabels is not supported")
+		}
+		if queryFunctionRegex.MatchString(action) {
+			appendOnce(warnings, "the query template function is not supported and returns no result")
+		}
+		if dataValueFieldRegex.MatchString(action) {
+			appendOnce(warnings, fmt.Sprintf(".Value is the evaluation string of the rule, use %s for the value of the query", translatedValueVariable))
+		}
+		if externalURLRegex.MatchString(action) {
+			appendOnce(warnings, "$externalURL is replaced with the URL of Grafana")
+			action = externalURLRegex.ReplaceAllString(action, "externalURL")
+		}
+		return valueVariableRegex.ReplaceAllLiteralString(action, translatedValueVariable)
+	})
+	if err != nil {
+		return "", err
+	}
+	return translated, nil
+}
+
+func appendOnce(warnings *[]string, warning string) {
+	if !slices.Contains(*warnings, warning) {
+		*warnings = append(*warnings, warning)
+	}
+}
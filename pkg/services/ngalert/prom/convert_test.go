@@ -0,0 +1,239 @@
+package prom
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func testConfig() Config {
+	return Config{
+		DatasourceUID:   "prometheus",
+		DefaultInterval: time.Minute,
+		BaseInterval:    10 * time.Second,
+		RecordingRules:  true,
+	}
+}
+
+func TestConvertRuleGroup_AlertingRule(t *testing.T) {
+	offset := model.Duration(time.Minute)
+	group := apimodels.PrometheusRuleGroup{
+		Name:        "node",
+		Interval:    model.Duration(30 * time.Second),
+		QueryOffset: &offset,
+		Labels:      map[string]string{"team": "infra", "severity": "info"},
+		Rules: []apimodels.ApiRuleNode{{
+			Alert:       "HighLoad",
+			Expr:        "node_load1 > 10",
+			For:         util.Pointer(model.Duration(5 * time.Minute)),
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: map[string]string{"summary": "Load of {{ $labels.instance }} is {{ $value }}"},
+		}},
+	}
+
+	converted, result := NewConverter(testConfig(), nil).ConvertRuleGroup(group)
+	require.Empty(t, result.Error)
+	require.Empty(t, result.Warnings)
+	require.Len(t, result.Rules, 1)
+	assert.Equal(t, apimodels.PrometheusRuleImportResult{Name: "HighLoad", Title: "HighLoad"}, result.Rules[0])
+
+	assert.Equal(t, "node", converted.Name)
+	assert.Equal(t, model.Duration(30*time.Second), converted.Interval)
+	require.Len(t, converted.Rules, 1)
+	rule := converted.Rules[0]
+	assert.Equal(t, map[string]string{"team": "infra", "severity": "warning"}, rule.Labels)
+	assert.Equal(t, map[string]string{"summary": "Load of {{ $labels.instance }} is {{ $values.B.Value }}"}, rule.Annotations)
+	assert.Equal(t, model.Duration(5*time.Minute), *rule.For)
+
+	grafanaRule := rule.GrafanaManagedAlert
+	assert.Equal(t, "HighLoad", grafanaRule.Title)
+	assert.Equal(t, "C", grafanaRule.Condition)
+	assert.Equal(t, apimodels.OK, grafanaRule.NoDataState)
+	assert.Equal(t, apimodels.ErrorErrState, grafanaRule.ExecErrState)
+	assert.Nil(t, grafanaRule.Record)
+	require.Len(t, grafanaRule.Data, 3)
+
+	query := grafanaRule.Data[0]
+	assert.Equal(t, "A", query.RefID)
+	assert.Equal(t, "prometheus", query.DatasourceUID)
+	assert.Equal(t, apimodels.RelativeTimeRange{
+		From: apimodels.Duration(11 * time.Minute),
+		To:   apimodels.Duration(time.Minute),
+	}, query.RelativeTimeRange)
+	var queryModel map[string]any
+	require.NoError(t, json.Unmarshal(query.Model, &queryModel))
+	assert.Equal(t, "node_load1 > 10", queryModel["expr"])
+	assert.Equal(t, true, queryModel["instant"])
+
+	for i, exp := range []string{"$A", "is_number($B) || is_nan($B) || is_inf($B)"} {
+		node := grafanaRule.Data[i+1]
+		assert.Equal(t, expr.DatasourceUID, node.DatasourceUID)
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(node.Model, &m))
+		assert.Equal(t, "math", m["type"])
+		assert.Equal(t, exp, m["expression"])
+	}
+}
+
+func TestConvertRuleGroup_RecordingRule(t *testing.T) {
+	group := apimodels.PrometheusRuleGroup{
+		Name: "node",
+		Rules: []apimodels.ApiRuleNode{{
+			Record: "instance:node_load1:avg",
+			Expr:   "avg by (instance) (node_load1)",
+		}},
+	}
+
+	t.Run("converted to a Grafana-managed recording rule", func(t *testing.T) {
+		converted, result := NewConverter(testConfig(), nil).ConvertRuleGroup(group)
+		require.Empty(t, result.Error)
+		require.Empty(t, result.Rules[0].Error)
+		assert.Equal(t, model.Duration(time.Minute), converted.Interval, "the default interval should be used")
+
+		grafanaRule := converted.Rules[0].GrafanaManagedAlert
+		assert.Equal(t, &apimodels.Record{Metric: "instance:node_load1:avg", From: "A"}, grafanaRule.Record)
+		assert.Empty(t, grafanaRule.Condition)
+		require.Len(t, grafanaRule.Data, 1)
+	})
+
+	t.Run("fails if recording rules are not enabled", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.RecordingRules = false
+		converted, result := NewConverter(cfg, nil).ConvertRuleGroup(group)
+		assert.Empty(t, converted.Rules)
+		assert.Equal(t, "recording rules are not enabled in Grafana", result.Rules[0].Error)
+		assert.Equal(t, "the group has no rule that can be converted", result.Error)
+	})
+}
+
+func TestConvertRuleGroup_InvalidRules(t *testing.T) {
+	group := apimodels.PrometheusRuleGroup{
+		Name: "invalid",
+		Rules: []apimodels.ApiRuleNode{
+			{Alert: "Valid", Expr: "up == 0"},
+			{Alert: "Both", Record: "both", Expr: "up"},
+			{Alert: "NoExpr"},
+			{Alert: "BadExpr", Expr: "sum(up"},
+			{Alert: "ExternalLabels", Expr: "up == 0", Annotations: map[string]string{"cluster": "{{ $externalLabels.cluster }}"}},
+		},
+	}
+
+	converted, result := NewConverter(testConfig(), nil).ConvertRuleGroup(group)
+	require.Empty(t, result.Error)
+	require.Len(t, converted.Rules, 1)
+	assert.Equal(t, "Valid", converted.Rules[0].GrafanaManagedAlert.Title)
+
+	require.Len(t, result.Rules, 5)
+	assert.Empty(t, result.Rules[0].Error)
+	assert.Equal(t, "a rule must have either an alert or a record name", result.Rules[1].Error)
+	assert.Equal(t, "expression must not be empty", result.Rules[2].Error)
+	assert.Contains(t, result.Rules[3].Error, "invalid expression")
+	assert.Contains(t, result.Rules[4].Error, "$externalLabels is not supported")
+	for _, r := range result.Rules[1:] {
+		assert.Empty(t, r.Title)
+	}
+}
+
+func TestConvertRuleGroup_Warnings(t *testing.T) {
+	group := apimodels.PrometheusRuleGroup{
+		Name:     "warnings",
+		Interval: model.Duration(45 * time.Second),
+		Limit:    10,
+		Rules: []apimodels.ApiRuleNode{{
+			Alert:         "Down",
+			Expr:          "up == 0",
+			KeepFiringFor: util.Pointer(model.Duration(time.Minute)),
+			Annotations: map[string]string{
+				"link":  "{{ $externalURL }}/alerting",
+				"value": "{{ .Value }}",
+				"other": `{{ query "up" }}`,
+			},
+		}},
+	}
+
+	converted, result := NewConverter(testConfig(), []string{"Down"}).ConvertRuleGroup(group)
+	require.Empty(t, result.Error)
+	assert.Equal(t, model.Duration(50*time.Second), converted.Interval)
+	assert.Len(t, result.Warnings, 2)
+
+	require.Len(t, converted.Rules, 1)
+	assert.Equal(t, "Down (2)", converted.Rules[0].GrafanaManagedAlert.Title)
+	assert.Equal(t, "{{ externalURL }}/alerting", converted.Rules[0].Annotations["link"])
+	assert.Equal(t, "Down (2)", result.Rules[0].Title)
+	assert.Len(t, result.Rules[0].Warnings, 5)
+}
+
+func TestConverter_UniqueTitles(t *testing.T) {
+	c := NewConverter(testConfig(), []string{"Down"})
+	for _, exp := range []string{"Down (2)", "Down (3)"} {
+		converted, _ := c.ConvertRuleGroup(apimodels.PrometheusRuleGroup{
+			Name:  "group",
+			Rules: []apimodels.ApiRuleNode{{Alert: "Down", Expr: "up == 0"}},
+		})
+		assert.Equal(t, exp, converted.Rules[0].GrafanaManagedAlert.Title)
+	}
+}
+
+func TestTranslateTemplate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tmpl        string
+		exp         string
+		expWarnings int
+		expErr      string
+	}{
+		{
+			name: "no template",
+			tmpl: "the value is $value",
+			exp:  "the value is $value",
+		},
+		{
+			name: "labels are kept",
+			tmpl: "{{ $labels.instance }} is down",
+			exp:  "{{ $labels.instance }} is down",
+		},
+		{
+			name: "value is replaced in every action",
+			tmpl: "{{ $value | humanize }} ({{ printf \"%.2f\" $value }})",
+			exp:  "{{ $values.B.Value | humanize }} ({{ printf \"%.2f\" $values.B.Value }})",
+		},
+		{
+			name: "variables that start with value are kept",
+			tmpl: "{{ $valueX := 1 }}{{ $valueX }}",
+			exp:  "{{ $valueX := 1 }}{{ $valueX }}",
+		},
+		{
+			name:        "external URL is replaced with the function",
+			tmpl:        "{{ $externalURL }}",
+			exp:         "{{ externalURL }}",
+			expWarnings: 1,
+		},
+		{
+			name:   "external labels are not supported",
+			tmpl:   "{{ .ExternalLabels.cluster }}",
+			expErr: "$externalLabels is not supported",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var warnings []string
+			translated, err := translateTemplate(tc.tmpl, &warnings)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.exp, translated)
+			assert.Len(t, warnings, tc.expWarnings)
+		})
+	}
+}
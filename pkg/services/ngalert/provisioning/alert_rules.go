@@ -32,6 +32,11 @@ type NotificationSettingsValidatorProvider interface {
 	Validator(ctx context.Context, orgID int64) (notifier.NotificationSettingsValidator, error)
 }
 
+// RecordTargetValidator validates that the results of recording rules can be written to their target by the user.
+type RecordTargetValidator interface {
+	ValidateTarget(ctx context.Context, user identity.Requester, orgID int64, target *models.RecordTarget) error
+}
+
 type AlertRuleService struct {
 	defaultIntervalSeconds int64
 	baseIntervalSeconds    int64
@@ -44,6 +49,8 @@ type AlertRuleService struct {
 	log                    log.Logger
 	nsValidatorProvider    NotificationSettingsValidatorProvider
 	authz                  ruleAccessControlService
+	// recordTargets validates the targets of recording rules. It is nil if they are not validated.
+	recordTargets RecordTargetValidator
 }
 
 func NewAlertRuleService(ruleStore RuleStore,
@@ -57,6 +64,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 	log log.Logger,
 	ns NotificationSettingsValidatorProvider,
 	authz RuleAccessControlService,
+	recordTargets RecordTargetValidator,
 ) *AlertRuleService {
 	return &AlertRuleService{
 		defaultIntervalSeconds: defaultIntervalSeconds,
@@ -70,6 +78,7 @@ func NewAlertRuleService(ruleStore RuleStore,
 		log:                    log,
 		nsValidatorProvider:    ns,
 		authz:                  newRuleAccessControlService(authz),
+		recordTargets:          recordTargets,
 	}
 }
 
@@ -219,6 +228,10 @@ func (service *AlertRuleService) CreateAlertRule(ctx context.Context, user ident
 		return models.AlertRule{}, err
 	}
 	rule.Updated = time.Now()
+	rule.UpdatedBy = models.NewUserUID(user)
+	if err := service.validateRecordTarget(ctx, user, &rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	if len(rule.NotificationSettings) > 0 {
 		validator, err := service.nsValidatorProvider.Validator(ctx, rule.OrgID)
 		if err != nil {
@@ -320,6 +333,7 @@ func (service *AlertRuleService) UpdateRuleGroup(ctx context.Context, user ident
 			}
 			newRule := *rule
 			newRule.IntervalSeconds = intervalSeconds
+			newRule.UpdatedBy = models.NewUserUID(user)
 			updateRules = append(updateRules, models.UpdateRule{
 				Existing: rule,
 				New:      newRule,
@@ -376,6 +390,12 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, user iden
 		return nil
 	}
 
+	return service.applyRuleGroupDelta(ctx, user, delta, provenance)
+}
+
+// applyRuleGroupDelta authorizes the changes to a rule group, validates the recording rule targets and the notification
+// settings of the new and updated rules, and persists the changes.
+func (service *AlertRuleService) applyRuleGroupDelta(ctx context.Context, user identity.Requester, delta *store.GroupDelta, provenance models.Provenance) error {
 	// check if the current user has permissions to all rules and can bypass the regular authorization validation.
 	can, err := service.authz.CanWriteAllRules(ctx, user)
 	if err != nil {
@@ -388,6 +408,17 @@ func (service *AlertRuleService) ReplaceRuleGroup(ctx context.Context, user iden
 		}
 	}
 
+	for _, rule := range delta.New {
+		if err := service.validateRecordTarget(ctx, user, rule); err != nil {
+			return err
+		}
+	}
+	for _, upd := range delta.Update {
+		if err := service.validateRecordTarget(ctx, user, upd.New); err != nil {
+			return err
+		}
+	}
+
 	newOrUpdatedNotificationSettings := delta.NewOrUpdatedNotificationSettings()
 	if len(newOrUpdatedNotificationSettings) > 0 {
 		validator, err := service.nsValidatorProvider.Validator(ctx, delta.GroupKey.OrgID)
@@ -505,6 +536,7 @@ func (service *AlertRuleService) persistDelta(ctx context.Context, user identity
 				if canUpdate := canUpdateProvenanceInRuleGroup(storedProvenance, provenance); !canUpdate {
 					return fmt.Errorf("cannot update with provided provenance '%s', needs '%s'", provenance, storedProvenance)
 				}
+				update.New.UpdatedBy = models.NewUserUID(user)
 				updates = append(updates, models.UpdateRule{
 					Existing: update.Existing,
 					New:      *update.New,
@@ -521,6 +553,11 @@ func (service *AlertRuleService) persistDelta(ctx context.Context, user identity
 		}
 
 		if len(delta.New) > 0 {
+			for _, rule := range delta.New {
+				if rule != nil {
+					rule.UpdatedBy = models.NewUserUID(user)
+				}
+			}
 			uids, err := service.ruleStore.InsertAlertRules(ctx, withoutNilAlertRules(delta.New))
 			if err != nil {
 				return fmt.Errorf("failed to insert alert rules: %w", err)
@@ -583,6 +620,9 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, user ident
 	if storedProvenance != provenance && storedProvenance != models.ProvenanceNone {
 		return models.AlertRule{}, fmt.Errorf("cannot change provenance from '%s' to '%s'", storedProvenance, provenance)
 	}
+	if err := service.validateRecordTarget(ctx, user, &rule); err != nil {
+		return models.AlertRule{}, err
+	}
 	if len(rule.NotificationSettings) > 0 {
 		validator, err := service.nsValidatorProvider.Validator(ctx, rule.OrgID)
 		if err != nil {
@@ -595,6 +635,7 @@ func (service *AlertRuleService) UpdateAlertRule(ctx context.Context, user ident
 		}
 	}
 	rule.Updated = time.Now()
+	rule.UpdatedBy = models.NewUserUID(user)
 	rule.ID = storedRule.ID
 	rule.IntervalSeconds = storedRule.IntervalSeconds
 	err = rule.SetDashboardAndPanelFromAnnotations()
@@ -817,6 +858,21 @@ func withoutNilAlertRules(ptrs []*models.AlertRule) []models.AlertRule {
 	return result
 }
 
+// validateRecordTarget checks that the results of the rule can be written to its target if it is a recording rule.
+// Authorization errors are returned as is.
+func (service *AlertRuleService) validateRecordTarget(ctx context.Context, user identity.Requester, rule *models.AlertRule) error {
+	if service.recordTargets == nil || rule == nil || rule.Record == nil {
+		return nil
+	}
+	if err := service.recordTargets.ValidateTarget(ctx, user, rule.OrgID, rule.Record.Target); err != nil {
+		if errors.Is(err, accesscontrol.ErrAuthorizationBase) {
+			return err
+		}
+		return errors.Join(models.ErrAlertRuleFailedValidation, fmt.Errorf("invalid recording rule target: %w", err))
+	}
+	return nil
+}
+
 func (service *AlertRuleService) checkGroupLimits(group models.AlertRuleGroup) error {
 	if service.rulesPerRuleGroupLimit > 0 && int64(len(group.Rules)) > service.rulesPerRuleGroupLimit {
 		service.log.Warn("Large rule group was edited. Large groups are discouraged and may be rejected in the future.",
@@ -904,6 +904,92 @@ func TestUpdateAlertRule(t *testing.T) {
 	})
 }
 
+func TestRecordTargetValidation(t *testing.T) {
+	orgID := rand.Int63()
+	u := &user.SignedInUser{OrgID: orgID}
+	gen := models.RuleGen
+	target := &models.RecordTarget{Type: models.RecordTargetDatasource, DatasourceUID: "ds"}
+	gen = gen.With(gen.WithOrgID(orgID), gen.WithAllRecordingRules(), gen.WithRecordTarget(target))
+
+	initServiceWithValidator := func(t *testing.T, err error) (*AlertRuleService, *fakes.RuleStore, *fakeRecordTargetValidator) {
+		service, ruleStore, _, ac := initService(t)
+		ac.CanWriteAllRulesFunc = func(ctx context.Context, user identity.Requester) (bool, error) {
+			return true, nil
+		}
+		validator := &fakeRecordTargetValidator{err: err}
+		service.recordTargets = validator
+		return service, ruleStore, validator
+	}
+
+	t.Run("create should validate the target with the user", func(t *testing.T) {
+		service, _, validator := initServiceWithValidator(t, nil)
+
+		_, err := service.CreateAlertRule(context.Background(), u, gen.Generate(), models.ProvenanceAPI)
+		require.NoError(t, err)
+		require.Equal(t, []*models.RecordTarget{target}, validator.targets)
+		require.Equal(t, []identity.Requester{u}, validator.users)
+	})
+
+	t.Run("create should fail validation if the target is invalid", func(t *testing.T) {
+		service, ruleStore, _ := initServiceWithValidator(t, errors.New("invalid target"))
+
+		_, err := service.CreateAlertRule(context.Background(), u, gen.Generate(), models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "invalid target")
+
+		inserts := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			a, ok := cmd.([]models.AlertRule)
+			return a, ok
+		})
+		require.Empty(t, inserts)
+	})
+
+	t.Run("create should return authorization errors as is", func(t *testing.T) {
+		service, _, _ := initServiceWithValidator(t, accesscontrol.NewAuthorizationErrorGeneric("write to data source"))
+
+		_, err := service.CreateAlertRule(context.Background(), u, gen.Generate(), models.ProvenanceAPI)
+		require.ErrorIs(t, err, accesscontrol.ErrAuthorizationBase)
+		require.NotErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("update should fail validation if the target is invalid", func(t *testing.T) {
+		service, ruleStore, _ := initServiceWithValidator(t, errors.New("invalid target"))
+		rule := gen.Generate()
+		ruleStore.PutRule(context.Background(), &rule)
+
+		_, err := service.UpdateAlertRule(context.Background(), u, rule, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("group replace should validate the targets of new rules", func(t *testing.T) {
+		service, _, validator := initServiceWithValidator(t, errors.New("invalid target"))
+		rule := gen.With(gen.WithIntervalSeconds(60)).Generate()
+		rule.UID = ""
+		group := models.AlertRuleGroup{
+			Title:     rule.RuleGroup,
+			FolderUID: rule.NamespaceUID,
+			Interval:  rule.IntervalSeconds,
+			Rules:     []models.AlertRule{rule},
+		}
+
+		err := service.ReplaceRuleGroup(context.Background(), u, group, models.ProvenanceAPI)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.Equal(t, []*models.RecordTarget{target}, validator.targets)
+	})
+}
+
+type fakeRecordTargetValidator struct {
+	err     error
+	users   []identity.Requester
+	targets []*models.RecordTarget
+}
+
+func (v *fakeRecordTargetValidator) ValidateTarget(_ context.Context, user identity.Requester, _ int64, target *models.RecordTarget) error {
+	v.users = append(v.users, user)
+	v.targets = append(v.targets, target)
+	return v.err
+}
+
 func TestDeleteAlertRule(t *testing.T) {
 	orgID := rand.Int63()
 	u := &user.SignedInUser{OrgID: orgID}
@@ -0,0 +1,1002 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util/cmputil"
+)
+
+// ConfigurationResourceKind is a kind of resource of the alerting configuration.
+type ConfigurationResourceKind string
+
+const (
+	ConfigurationResourceRuleGroup            ConfigurationResourceKind = "rule_group"
+	ConfigurationResourceContactPoint         ConfigurationResourceKind = "contact_point"
+	ConfigurationResourceNotificationPolicies ConfigurationResourceKind = "notification_policies"
+	ConfigurationResourceMuteTiming           ConfigurationResourceKind = "mute_timing"
+	ConfigurationResourceTemplate             ConfigurationResourceKind = "template"
+)
+
+// ConfigurationAction is what applying a change does to a resource.
+type ConfigurationAction string
+
+const (
+	ConfigurationActionCreate ConfigurationAction = "create"
+	ConfigurationActionUpdate ConfigurationAction = "update"
+	ConfigurationActionDelete ConfigurationAction = "delete"
+)
+
+// The order in which the changes are applied. The resources are created and updated before the resources that reference
+// them, and deleted after the resources that referenced them are updated or deleted.
+const (
+	applyTemplates = iota
+	applyMuteTimings
+	applyContactPoints
+	applyPolicies
+	applyRuleGroups
+	deleteRuleGroups
+	deleteContactPoints
+	deleteMuteTimings
+	deleteTemplates
+)
+
+// AlertingConfiguration is the alerting configuration of an organization. A nil field means that the kind of resource is
+// not managed by the configuration, and is left unchanged when the configuration is applied. Otherwise, the resources of
+// this kind that are not in the configuration are deleted.
+type AlertingConfiguration struct {
+	RuleGroups    []models.AlertRuleGroup
+	ContactPoints []definitions.EmbeddedContactPoint
+	Policies      *definitions.Route
+	MuteTimings   []definitions.MuteTimeInterval
+	Templates     []definitions.NotificationTemplate
+}
+
+// ConfigurationChange is a change of a single resource that is needed to get to the desired configuration.
+type ConfigurationChange struct {
+	Kind ConfigurationResourceKind
+	// FolderUID is the UID of the folder of a rule group.
+	FolderUID string
+	// UID is the UID of a contact point.
+	UID    string
+	Name   string
+	Action ConfigurationAction
+	// Diff contains the fields of the resource that are updated. For rule groups, it contains the rules that are created
+	// or deleted, and the fields of the rules that are updated.
+	Diff cmputil.DiffReport
+
+	order int
+	apply func(ctx context.Context) error
+}
+
+// ConfigurationPlan is the list of changes that are needed to get from the current configuration to the desired one.
+type ConfigurationPlan struct {
+	// Version of the configuration the plan is calculated against.
+	Version string
+	Changes []ConfigurationChange
+}
+
+// ConfigurationService manages the alerting configuration of an organization as a whole. It calculates the changes
+// between the current configuration and a desired one, and applies them in a single transaction.
+type ConfigurationService struct {
+	alertRules      *AlertRuleService
+	contactPoints   *ContactPointService
+	policies        *NotificationPolicyService
+	muteTimings     *MuteTimingService
+	templates       *TemplateService
+	configStore     alertmanagerConfigStore
+	provenanceStore ProvisioningStore
+	xact            TransactionManager
+	log             log.Logger
+}
+
+func NewConfigurationService(
+	alertRules *AlertRuleService,
+	contactPoints *ContactPointService,
+	policies *NotificationPolicyService,
+	muteTimings *MuteTimingService,
+	templates *TemplateService,
+	config AMConfigStore,
+	prov ProvisioningStore,
+	xact TransactionManager,
+	log log.Logger,
+) *ConfigurationService {
+	return &ConfigurationService{
+		alertRules:      alertRules,
+		contactPoints:   contactPoints,
+		policies:        policies,
+		muteTimings:     muteTimings,
+		templates:       templates,
+		configStore:     &alertmanagerConfigStoreImpl{store: config},
+		provenanceStore: prov,
+		xact:            xact,
+		log:             log,
+	}
+}
+
+// configurationState is the current alerting configuration of an organization.
+type configurationState struct {
+	orgID           int64
+	revision        *cfgRevision
+	rules           []*models.AlertRule
+	ruleProvenances map[string]models.Provenance
+	version         string
+}
+
+func (s *ConfigurationService) getState(ctx context.Context, user identity.Requester) (*configurationState, error) {
+	revision, err := s.configStore.Get(ctx, user.GetOrgID())
+	if err != nil {
+		return nil, err
+	}
+	rules, provenances, err := s.alertRules.GetAlertRules(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &configurationState{
+		orgID:           user.GetOrgID(),
+		revision:        revision,
+		rules:           rules,
+		ruleProvenances: provenances,
+		version:         calculateConfigurationVersion(revision, rules),
+	}, nil
+}
+
+// calculateConfigurationVersion returns a fingerprint of the Alertmanager configuration and of the versions of the alert
+// rules. It changes every time any resource of the configuration changes.
+func calculateConfigurationVersion(revision *cfgRevision, rules []*models.AlertRule) string {
+	versions := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		versions = append(versions, rule.UID+":"+strconv.FormatInt(rule.Version, 10))
+	}
+	sort.Strings(versions)
+
+	sum := fnv.New64()
+	_, _ = sum.Write([]byte(revision.concurrencyToken))
+	for _, v := range versions {
+		// add a byte sequence that cannot happen in UTF-8 strings.
+		_, _ = sum.Write([]byte{255})
+		_, _ = sum.Write([]byte(v))
+	}
+	return fmt.Sprintf("%016x", sum.Sum64())
+}
+
+// GetConfiguration returns the current alerting configuration of the user's organization and its version. Only the rules
+// the user can read are returned. The secure settings of contact points are redacted.
+func (s *ConfigurationService) GetConfiguration(ctx context.Context, user identity.Requester) (AlertingConfiguration, string, error) {
+	var result AlertingConfiguration
+	var version string
+	err := s.xact.InTransaction(ctx, func(ctx context.Context) error {
+		state, err := s.getState(ctx, user)
+		if err != nil {
+			return err
+		}
+		version = state.version
+
+		result.RuleGroups = currentRuleGroups(state)
+		if result.ContactPoints, err = s.currentContactPoints(ctx, state, false); err != nil {
+			return err
+		}
+		if result.Policies, err = s.currentPolicies(ctx, state); err != nil {
+			return err
+		}
+		if result.MuteTimings, err = s.muteTimings.GetMuteTimings(ctx, state.orgID); err != nil {
+			return err
+		}
+		result.Templates, err = s.currentTemplates(ctx, state)
+		return err
+	})
+	if err != nil {
+		return AlertingConfiguration{}, "", err
+	}
+	return result, version, nil
+}
+
+// PlanConfiguration calculates the changes that are needed to get from the current configuration to the desired one.
+// The changes would tag the created and updated resources with the given provenance.
+func (s *ConfigurationService) PlanConfiguration(ctx context.Context, user identity.Requester, desired AlertingConfiguration, provenance models.Provenance) (ConfigurationPlan, error) {
+	var plan ConfigurationPlan
+	err := s.xact.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		plan, err = s.plan(ctx, user, desired, provenance)
+		return err
+	})
+	return plan, err
+}
+
+// ApplyConfiguration calculates the changes that are needed to get from the current configuration to the desired one,
+// and applies them in a single transaction. If version is not empty, it must be the version of the current
+// configuration, otherwise ErrVersionConflict is returned and nothing is changed.
+func (s *ConfigurationService) ApplyConfiguration(ctx context.Context, user identity.Requester, desired AlertingConfiguration, provenance models.Provenance, version string) (ConfigurationPlan, error) {
+	var plan ConfigurationPlan
+	err := s.xact.InTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		plan, err = s.plan(ctx, user, desired, provenance)
+		if err != nil {
+			return err
+		}
+		if version != "" && version != plan.Version {
+			return ErrVersionConflict.Errorf("provided version %s of the alerting configuration does not match current version %s", version, plan.Version)
+		}
+		for _, change := range plan.Changes {
+			if err := change.apply(ctx); err != nil {
+				return fmt.Errorf("failed to %s %s '%s': %w", change.Action, change.Kind, change.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ConfigurationPlan{}, err
+	}
+	s.log.Info("Applied alerting configuration", "org", user.GetOrgID(), "changes", len(plan.Changes), "provenance", provenance)
+	return plan, nil
+}
+
+func (s *ConfigurationService) plan(ctx context.Context, user identity.Requester, desired AlertingConfiguration, provenance models.Provenance) (ConfigurationPlan, error) {
+	state, err := s.getState(ctx, user)
+	if err != nil {
+		return ConfigurationPlan{}, err
+	}
+	plan := ConfigurationPlan{Version: state.version}
+	add := func(changes []ConfigurationChange, err error) error {
+		plan.Changes = append(plan.Changes, changes...)
+		return err
+	}
+
+	if desired.Templates != nil {
+		if err := add(s.planTemplates(ctx, state, desired.Templates, provenance)); err != nil {
+			return ConfigurationPlan{}, err
+		}
+	}
+	if desired.MuteTimings != nil {
+		if err := add(s.planMuteTimings(ctx, state, desired.MuteTimings, provenance)); err != nil {
+			return ConfigurationPlan{}, err
+		}
+	}
+	if desired.ContactPoints != nil {
+		if err := add(s.planContactPoints(ctx, state, desired.ContactPoints, provenance)); err != nil {
+			return ConfigurationPlan{}, err
+		}
+	}
+	if desired.Policies != nil {
+		if err := add(s.planPolicies(ctx, state, desired, provenance)); err != nil {
+			return ConfigurationPlan{}, err
+		}
+	}
+	if desired.RuleGroups != nil {
+		if err := add(s.planRuleGroups(ctx, user, state, desired.RuleGroups, provenance)); err != nil {
+			return ConfigurationPlan{}, err
+		}
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		return plan.Changes[i].order < plan.Changes[j].order
+	})
+	return plan, nil
+}
+
+// resourcePlanner calculates the changes of a kind of resource of the Alertmanager configuration.
+type resourcePlanner[T any] struct {
+	kind                    ConfigurationResourceKind
+	applyOrder, deleteOrder int
+	name                    func(T) string
+	uid                     func(T) string
+	diff                    func(current, desired T) (cmputil.DiffReport, error)
+	create, update, delete  func(ctx context.Context, resource T) error
+	currentProvenances      map[string]models.Provenance
+	target                  models.Provenance
+	missingKeyError         string
+}
+
+func (p resourcePlanner[T]) key(resource T) string {
+	if p.uid != nil {
+		return p.uid(resource)
+	}
+	return p.name(resource)
+}
+
+func (p resourcePlanner[T]) change(resource T, action ConfigurationAction, diff cmputil.DiffReport, order int, apply func(ctx context.Context, resource T) error) ConfigurationChange {
+	c := ConfigurationChange{
+		Kind:   p.kind,
+		Name:   p.name(resource),
+		Action: action,
+		Diff:   diff,
+		order:  order,
+		apply: func(ctx context.Context) error {
+			return apply(ctx, resource)
+		},
+	}
+	if p.uid != nil {
+		c.UID = p.uid(resource)
+	}
+	return c
+}
+
+func (p resourcePlanner[T]) plan(current, desired []T) ([]ConfigurationChange, error) {
+	currentByKey := make(map[string]T, len(current))
+	for _, resource := range current {
+		currentByKey[p.key(resource)] = resource
+	}
+
+	var changes []ConfigurationChange
+	desiredKeys := make(map[string]struct{}, len(desired))
+	for _, resource := range desired {
+		key := p.key(resource)
+		if key == "" {
+			return nil, fmt.Errorf("%w: %s", ErrValidation, p.missingKeyError)
+		}
+		if _, ok := desiredKeys[key]; ok {
+			return nil, fmt.Errorf("%w: %s '%s' is defined more than once", ErrValidation, p.kind, key)
+		}
+		desiredKeys[key] = struct{}{}
+
+		existing, ok := currentByKey[key]
+		if !ok {
+			changes = append(changes, p.change(resource, ConfigurationActionCreate, nil, p.applyOrder, p.create))
+			continue
+		}
+		stored := p.currentProvenances[key]
+		if err := checkConfigurationProvenance(stored, p.target); err != nil {
+			return nil, err
+		}
+		diff, err := p.diff(existing, resource)
+		if err != nil {
+			return nil, err
+		}
+		if stored != p.target {
+			diff = append(diff, cmputil.Diff{Path: "provenance", Left: reflect.ValueOf(stored), Right: reflect.ValueOf(p.target)})
+		}
+		if len(diff) == 0 {
+			continue
+		}
+		changes = append(changes, p.change(resource, ConfigurationActionUpdate, diff, p.applyOrder, p.update))
+	}
+
+	for _, resource := range current {
+		if _, ok := desiredKeys[p.key(resource)]; ok {
+			continue
+		}
+		if err := checkConfigurationProvenance(p.currentProvenances[p.key(resource)], p.target); err != nil {
+			return nil, err
+		}
+		changes = append(changes, p.change(resource, ConfigurationActionDelete, nil, p.deleteOrder, p.delete))
+	}
+	return changes, nil
+}
+
+// checkConfigurationProvenance checks that a resource with the stored provenance can be changed by a configuration that
+// is applied with the target provenance.
+func checkConfigurationProvenance(stored, target models.Provenance) error {
+	if stored == models.ProvenanceNone || stored == target {
+		return nil
+	}
+	return MakeErrProvenanceChangeNotAllowed(stored, target)
+}
+
+func (s *ConfigurationService) currentTemplates(ctx context.Context, state *configurationState) ([]definitions.NotificationTemplate, error) {
+	provenances, err := s.provenanceStore.GetProvenances(ctx, state.orgID, (&definitions.NotificationTemplate{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+	templates := make([]definitions.NotificationTemplate, 0, len(state.revision.cfg.TemplateFiles))
+	for name, tmpl := range state.revision.cfg.TemplateFiles {
+		templates = append(templates, definitions.NotificationTemplate{
+			Name:       name,
+			Template:   tmpl,
+			Provenance: definitions.Provenance(provenances[name]),
+		})
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+func (s *ConfigurationService) planTemplates(ctx context.Context, state *configurationState, desired []definitions.NotificationTemplate, target models.Provenance) ([]ConfigurationChange, error) {
+	current, err := s.currentTemplates(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	provenances := make(map[string]models.Provenance, len(current))
+	for _, tmpl := range current {
+		provenances[tmpl.Name] = models.Provenance(tmpl.Provenance)
+	}
+	for i := range desired {
+		if err := desired[i].Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+		}
+	}
+
+	set := func(ctx context.Context, tmpl definitions.NotificationTemplate) error {
+		tmpl.Provenance = definitions.Provenance(target)
+		_, err := s.templates.SetTemplate(ctx, state.orgID, tmpl)
+		return err
+	}
+	return resourcePlanner[definitions.NotificationTemplate]{
+		kind:        ConfigurationResourceTemplate,
+		applyOrder:  applyTemplates,
+		deleteOrder: deleteTemplates,
+		name:        func(t definitions.NotificationTemplate) string { return t.Name },
+		diff: func(current, desired definitions.NotificationTemplate) (cmputil.DiffReport, error) {
+			current.Provenance, desired.Provenance = "", ""
+			return diffJSON(current, desired)
+		},
+		create: set,
+		update: set,
+		delete: func(ctx context.Context, tmpl definitions.NotificationTemplate) error {
+			return s.templates.DeleteTemplate(ctx, state.orgID, tmpl.Name)
+		},
+		currentProvenances: provenances,
+		target:             target,
+		missingKeyError:    "template name must not be empty",
+	}.plan(current, desired)
+}
+
+func (s *ConfigurationService) planMuteTimings(ctx context.Context, state *configurationState, desired []definitions.MuteTimeInterval, target models.Provenance) ([]ConfigurationChange, error) {
+	current, err := s.muteTimings.GetMuteTimings(ctx, state.orgID)
+	if err != nil {
+		return nil, err
+	}
+	provenances := make(map[string]models.Provenance, len(current))
+	versions := make(map[string]string, len(current))
+	for _, mt := range current {
+		provenances[mt.Name] = models.Provenance(mt.Provenance)
+		versions[mt.Name] = mt.Version
+	}
+	for i := range desired {
+		if err := desired[i].Validate(); err != nil {
+			return nil, MakeErrTimeIntervalInvalid(err)
+		}
+		if v, ok := versions[desired[i].Name]; ok && desired[i].Version != "" && desired[i].Version != v {
+			return nil, ErrVersionConflict.Errorf("provided version %s of time interval %s does not match current version %s", desired[i].Version, desired[i].Name, v)
+		}
+	}
+
+	return resourcePlanner[definitions.MuteTimeInterval]{
+		kind:        ConfigurationResourceMuteTiming,
+		applyOrder:  applyMuteTimings,
+		deleteOrder: deleteMuteTimings,
+		name:        func(mt definitions.MuteTimeInterval) string { return mt.Name },
+		diff: func(current, desired definitions.MuteTimeInterval) (cmputil.DiffReport, error) {
+			return diffJSON(current.MuteTimeInterval, desired.MuteTimeInterval)
+		},
+		create: func(ctx context.Context, mt definitions.MuteTimeInterval) error {
+			mt.Provenance, mt.Version = definitions.Provenance(target), ""
+			_, err := s.muteTimings.CreateMuteTiming(ctx, mt, state.orgID)
+			return err
+		},
+		update: func(ctx context.Context, mt definitions.MuteTimeInterval) error {
+			mt.Provenance, mt.Version = definitions.Provenance(target), ""
+			_, err := s.muteTimings.UpdateMuteTiming(ctx, mt, state.orgID)
+			return err
+		},
+		delete: func(ctx context.Context, mt definitions.MuteTimeInterval) error {
+			return s.muteTimings.DeleteMuteTiming(ctx, mt.Name, state.orgID, definitions.Provenance(target), "")
+		},
+		currentProvenances: provenances,
+		target:             target,
+		missingKeyError:    "time interval name must not be empty",
+	}.plan(current, desired)
+}
+
+// currentContactPoints returns the contact points of the current configuration. The secure settings are decrypted if
+// decrypt is true, and redacted otherwise.
+func (s *ConfigurationService) currentContactPoints(ctx context.Context, state *configurationState, decrypt bool) ([]definitions.EmbeddedContactPoint, error) {
+	provenances, err := s.provenanceStore.GetProvenances(ctx, state.orgID, (&definitions.EmbeddedContactPoint{}).ResourceType())
+	if err != nil {
+		return nil, err
+	}
+	var result []definitions.EmbeddedContactPoint
+	for _, receiver := range state.revision.cfg.AlertmanagerConfig.Receivers {
+		for _, integration := range receiver.GrafanaManagedReceivers {
+			cp, err := PostableGrafanaReceiverToEmbeddedContactPoint(integration, provenances[integration.UID], s.contactPoints.decryptValueOrRedacted(decrypt, integration.UID))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, cp)
+		}
+	}
+	return result, nil
+}
+
+func (s *ConfigurationService) planContactPoints(ctx context.Context, state *configurationState, desired []definitions.EmbeddedContactPoint, target models.Provenance) ([]ConfigurationChange, error) {
+	current, err := s.currentContactPoints(ctx, state, true)
+	if err != nil {
+		return nil, err
+	}
+	provenances := make(map[string]models.Provenance, len(current))
+	currentByUID := make(map[string]definitions.EmbeddedContactPoint, len(current))
+	for _, cp := range current {
+		provenances[cp.UID] = models.Provenance(cp.Provenance)
+		currentByUID[cp.UID] = cp
+	}
+
+	// The secure settings that are redacted in the desired contact points keep their current value. The settings are
+	// copied to not change the desired configuration.
+	merged := make([]definitions.EmbeddedContactPoint, 0, len(desired))
+	secrets := make(map[string][]string, len(desired))
+	for _, cp := range desired {
+		if cp.Settings == nil {
+			return nil, fmt.Errorf("%w: settings of contact point '%s' should not be empty", ErrValidation, cp.Name)
+		}
+		keys, err := channels_config.GetSecretKeysForContactPointType(cp.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+		}
+		secrets[cp.UID] = keys
+		b, err := cp.Settings.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		if cp.Settings, err = simplejson.NewJson(b); err != nil {
+			return nil, err
+		}
+		existing, exists := currentByUID[cp.UID]
+		for _, key := range keys {
+			if cp.Settings.Get(key).MustString() != definitions.RedactedValue {
+				continue
+			}
+			if !exists {
+				return nil, fmt.Errorf("%w: secure setting '%s' of new contact point '%s' must not be redacted", ErrValidation, key, cp.Name)
+			}
+			cp.Settings.Set(key, existing.Settings.Get(key).MustString())
+		}
+		if err := ValidateContactPoint(ctx, cp, s.contactPoints.encryptionService.GetDecryptedValue); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+		}
+		merged = append(merged, cp)
+	}
+
+	return resourcePlanner[definitions.EmbeddedContactPoint]{
+		kind:        ConfigurationResourceContactPoint,
+		applyOrder:  applyContactPoints,
+		deleteOrder: deleteContactPoints,
+		name:        func(cp definitions.EmbeddedContactPoint) string { return cp.Name },
+		uid:         func(cp definitions.EmbeddedContactPoint) string { return cp.UID },
+		diff: func(current, desired definitions.EmbeddedContactPoint) (cmputil.DiffReport, error) {
+			current.Provenance, desired.Provenance = "", ""
+			diff, err := diffJSON(current, desired)
+			if err != nil {
+				return nil, err
+			}
+			// The current contact point is decrypted and can have a different type, so the secure settings of both
+			// types are redacted.
+			keys := secrets[desired.UID]
+			if current.Type != desired.Type {
+				currentKeys, err := channels_config.GetSecretKeysForContactPointType(current.Type)
+				if err != nil {
+					return nil, err
+				}
+				keys = append(slices.Clone(keys), currentKeys...)
+			}
+			redactSecretsInDiff(diff, keys)
+			return diff, nil
+		},
+		create: func(ctx context.Context, cp definitions.EmbeddedContactPoint) error {
+			_, err := s.contactPoints.CreateContactPoint(ctx, state.orgID, cp, target)
+			return err
+		},
+		update: func(ctx context.Context, cp definitions.EmbeddedContactPoint) error {
+			return s.contactPoints.UpdateContactPoint(ctx, state.orgID, cp, target)
+		},
+		delete: func(ctx context.Context, cp definitions.EmbeddedContactPoint) error {
+			return s.contactPoints.DeleteContactPoint(ctx, state.orgID, cp.UID)
+		},
+		currentProvenances: provenances,
+		target:             target,
+		missingKeyError:    "contact point UID must not be empty",
+	}.plan(current, merged)
+}
+
+// redactSecretsInDiff replaces the values of the secure settings in the diff of a contact point with the redacted value.
+func redactSecretsInDiff(diff cmputil.DiffReport, secretKeys []string) {
+	redacted := reflect.ValueOf(definitions.RedactedValue)
+	for i := range diff {
+		for _, key := range secretKeys {
+			if diff[i].Path != "settings."+key {
+				continue
+			}
+			if diff[i].Left.IsValid() {
+				diff[i].Left = redacted
+			}
+			if diff[i].Right.IsValid() {
+				diff[i].Right = redacted
+			}
+		}
+	}
+}
+
+func (s *ConfigurationService) currentPolicies(ctx context.Context, state *configurationState) (*definitions.Route, error) {
+	route := state.revision.cfg.AlertmanagerConfig.Route
+	if route == nil {
+		return nil, nil
+	}
+	provenance, err := s.provenanceStore.GetProvenance(ctx, route, state.orgID)
+	if err != nil {
+		return nil, err
+	}
+	result := *route
+	result.Provenance = definitions.Provenance(provenance)
+	return &result, nil
+}
+
+func (s *ConfigurationService) planPolicies(ctx context.Context, state *configurationState, desired AlertingConfiguration, target models.Provenance) ([]ConfigurationChange, error) {
+	tree := *desired.Policies
+	if err := tree.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+
+	// The policies must only reference the contact points and mute timings of the desired configuration.
+	receivers := map[string]struct{}{"": {}}
+	if desired.ContactPoints != nil {
+		for _, cp := range desired.ContactPoints {
+			receivers[cp.Name] = struct{}{}
+		}
+	} else {
+		for _, receiver := range state.revision.cfg.AlertmanagerConfig.Receivers {
+			receivers[receiver.Name] = struct{}{}
+		}
+	}
+	if err := tree.ValidateReceivers(receivers); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+	muteTimes := map[string]struct{}{}
+	if desired.MuteTimings != nil {
+		for _, mt := range desired.MuteTimings {
+			muteTimes[mt.Name] = struct{}{}
+		}
+	} else {
+		for _, mt := range state.revision.cfg.AlertmanagerConfig.MuteTimeIntervals {
+			muteTimes[mt.Name] = struct{}{}
+		}
+	}
+	if err := tree.ValidateMuteTimes(muteTimes); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidation, err.Error())
+	}
+
+	current, err := s.currentPolicies(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	var diff cmputil.DiffReport
+	if current != nil {
+		stored := models.Provenance(current.Provenance)
+		if err := checkConfigurationProvenance(stored, target); err != nil {
+			return nil, err
+		}
+		c := *current
+		c.Provenance, tree.Provenance = "", ""
+		if diff, err = diffJSON(c, tree); err != nil {
+			return nil, err
+		}
+		if stored != target {
+			diff = append(diff, cmputil.Diff{Path: "provenance", Left: reflect.ValueOf(stored), Right: reflect.ValueOf(target)})
+		}
+		if len(diff) == 0 {
+			return nil, nil
+		}
+	}
+	return []ConfigurationChange{{
+		Kind:   ConfigurationResourceNotificationPolicies,
+		Action: ConfigurationActionUpdate,
+		Diff:   diff,
+		order:  applyPolicies,
+		apply: func(ctx context.Context) error {
+			return s.policies.UpdatePolicyTree(ctx, state.orgID, tree, target)
+		},
+	}}, nil
+}
+
+// currentRuleGroups returns the rule groups of the current configuration, sorted by folder and title.
+func currentRuleGroups(state *configurationState) []models.AlertRuleGroup {
+	groups := models.GroupByAlertRuleGroupKey(state.rules)
+	keys := make([]models.AlertRuleGroupKey, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sortRuleGroupKeys(keys)
+
+	result := make([]models.AlertRuleGroup, 0, len(keys))
+	for _, key := range keys {
+		rules := groups[key]
+		rules.SortByGroupIndex()
+		group := models.AlertRuleGroup{
+			Title:      key.RuleGroup,
+			FolderUID:  key.NamespaceUID,
+			Interval:   rules[0].IntervalSeconds,
+			Provenance: state.ruleProvenances[rules[0].UID],
+			Rules:      make([]models.AlertRule, 0, len(rules)),
+		}
+		for _, rule := range rules {
+			group.Rules = append(group.Rules, *rule)
+		}
+		result = append(result, group)
+	}
+	return result
+}
+
+func sortRuleGroupKeys(keys []models.AlertRuleGroupKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].NamespaceUID != keys[j].NamespaceUID {
+			return keys[i].NamespaceUID < keys[j].NamespaceUID
+		}
+		return keys[i].RuleGroup < keys[j].RuleGroup
+	})
+}
+
+func (s *ConfigurationService) planRuleGroups(ctx context.Context, user identity.Requester, state *configurationState, desired []models.AlertRuleGroup, target models.Provenance) ([]ConfigurationChange, error) {
+	existingUIDs := make(map[string]struct{}, len(state.rules))
+	titles := make(map[string]map[string]string)
+	for _, rule := range state.rules {
+		existingUIDs[rule.UID] = struct{}{}
+		if titles[rule.NamespaceUID] == nil {
+			titles[rule.NamespaceUID] = make(map[string]string)
+		}
+		titles[rule.NamespaceUID][rule.Title] = rule.UID
+	}
+
+	// The rules without UID are matched with the current rules by title, because titles are unique in a folder. The rules
+	// whose UID does not exist yet are created with this UID.
+	groups := make([]models.AlertRuleGroup, 0, len(desired))
+	desiredKeys := make(map[models.AlertRuleGroupKey]struct{}, len(desired))
+	desiredUIDs := make(map[string]struct{})
+	newUIDs := make(map[string]struct{})
+	for _, group := range desired {
+		if group.Title == "" || group.FolderUID == "" {
+			return nil, fmt.Errorf("%w: title and folder UID of rule groups must not be empty", ErrValidation)
+		}
+		key := models.AlertRuleGroupKey{OrgID: state.orgID, NamespaceUID: group.FolderUID, RuleGroup: group.Title}
+		if _, ok := desiredKeys[key]; ok {
+			return nil, fmt.Errorf("%w: rule group '%s' of folder '%s' is defined more than once", ErrValidation, group.Title, group.FolderUID)
+		}
+		desiredKeys[key] = struct{}{}
+		if err := models.ValidateRuleGroupInterval(group.Interval, s.alertRules.baseIntervalSeconds); err != nil {
+			return nil, err
+		}
+
+		rules := make([]models.AlertRule, len(group.Rules))
+		copy(rules, group.Rules)
+		for i := range rules {
+			if rules[i].UID == "" {
+				rules[i].UID = titles[group.FolderUID][rules[i].Title]
+			}
+			if rules[i].UID == "" {
+				continue
+			}
+			if _, ok := desiredUIDs[rules[i].UID]; ok {
+				return nil, fmt.Errorf("%w: rule '%s' is defined more than once", ErrValidation, rules[i].UID)
+			}
+			desiredUIDs[rules[i].UID] = struct{}{}
+			if _, ok := existingUIDs[rules[i].UID]; !ok {
+				newUIDs[rules[i].UID] = struct{}{}
+			}
+		}
+		group.Rules = rules
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].FolderUID != groups[j].FolderUID {
+			return groups[i].FolderUID < groups[j].FolderUID
+		}
+		return groups[i].Title < groups[j].Title
+	})
+
+	checkProvenance := func(rule *models.AlertRule) error {
+		if stored := state.ruleProvenances[rule.UID]; !canUpdateProvenanceInRuleGroup(stored, target) {
+			return MakeErrProvenanceChangeNotAllowed(stored, target)
+		}
+		return nil
+	}
+
+	var changes []ConfigurationChange
+	current := models.GroupByAlertRuleGroupKey(state.rules)
+	for _, group := range groups {
+		delta, err := s.ruleGroupDelta(ctx, user, group, newUIDs)
+		if err != nil {
+			return nil, err
+		}
+		var diff cmputil.DiffReport
+		for _, rule := range delta.New {
+			diff = append(diff, cmputil.Diff{Path: ruleDiffPath(rule), Right: reflect.ValueOf(rule.Title)})
+		}
+		for _, update := range delta.Update {
+			if err := checkProvenance(update.Existing); err != nil {
+				return nil, err
+			}
+			for _, d := range update.Diff {
+				d.Path = ruleDiffPath(update.Existing) + "." + d.Path
+				diff = append(diff, d)
+			}
+			if stored := state.ruleProvenances[update.Existing.UID]; stored != target && update.Existing.GetGroupKey() == delta.GroupKey {
+				diff = append(diff, cmputil.Diff{Path: ruleDiffPath(update.Existing) + ".Provenance", Left: reflect.ValueOf(stored), Right: reflect.ValueOf(target)})
+			}
+		}
+		for _, rule := range delta.Delete {
+			if err := checkProvenance(rule); err != nil {
+				return nil, err
+			}
+			if _, ok := desiredUIDs[rule.UID]; ok { // the rule is moved to another group
+				continue
+			}
+			diff = append(diff, cmputil.Diff{Path: ruleDiffPath(rule), Left: reflect.ValueOf(rule.Title)})
+		}
+		if len(diff) == 0 {
+			continue
+		}
+
+		action := ConfigurationActionUpdate
+		if _, ok := current[delta.GroupKey]; !ok {
+			action = ConfigurationActionCreate
+		}
+		changes = append(changes, ConfigurationChange{
+			Kind:      ConfigurationResourceRuleGroup,
+			FolderUID: group.FolderUID,
+			Name:      group.Title,
+			Action:    action,
+			Diff:      diff,
+			order:     applyRuleGroups,
+			apply: func(ctx context.Context) error {
+				// The delta is calculated again, because the rules that move between groups change the other groups.
+				delta, err := s.ruleGroupDelta(ctx, user, group, newUIDs)
+				if err != nil {
+					return err
+				}
+				return s.alertRules.applyRuleGroupDelta(ctx, user, delta, target)
+			},
+		})
+	}
+
+	keys := make([]models.AlertRuleGroupKey, 0, len(current))
+	for key := range current {
+		if _, ok := desiredKeys[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sortRuleGroupKeys(keys)
+	for _, key := range keys {
+		var diff cmputil.DiffReport
+		for _, rule := range current[key] {
+			if _, ok := desiredUIDs[rule.UID]; ok { // the rule is moved to another group
+				continue
+			}
+			if err := checkProvenance(rule); err != nil {
+				return nil, err
+			}
+			diff = append(diff, cmputil.Diff{Path: ruleDiffPath(rule), Left: reflect.ValueOf(rule.Title)})
+		}
+		if len(diff) == 0 {
+			continue
+		}
+		changes = append(changes, ConfigurationChange{
+			Kind:      ConfigurationResourceRuleGroup,
+			FolderUID: key.NamespaceUID,
+			Name:      key.RuleGroup,
+			Action:    ConfigurationActionDelete,
+			Diff:      diff,
+			order:     deleteRuleGroups,
+			apply: func(ctx context.Context) error {
+				return s.alertRules.DeleteRuleGroup(ctx, user, key.NamespaceUID, key.RuleGroup, target)
+			},
+		})
+	}
+	return changes, nil
+}
+
+// ruleGroupDelta calculates the changes to the rules of the group. The rules with UIDs in newUIDs are created with these
+// UIDs, while calcDelta expects that all rules with UID exist.
+func (s *ConfigurationService) ruleGroupDelta(ctx context.Context, user identity.Requester, group models.AlertRuleGroup, newUIDs map[string]struct{}) (*store.GroupDelta, error) {
+	rules := make([]models.AlertRule, len(group.Rules))
+	copy(rules, group.Rules)
+	uids := make(map[string]string)
+	for i := range rules {
+		rules[i].RuleGroupIndex = i + 1
+		if _, ok := newUIDs[rules[i].UID]; ok {
+			uids[rules[i].Title] = rules[i].UID
+			rules[i].UID = ""
+		}
+	}
+	group.Rules = rules
+
+	delta, err := s.alertRules.calcDelta(ctx, user, group)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range delta.New {
+		if uid, ok := uids[rule.Title]; ok {
+			rule.UID = uid
+		}
+	}
+	return delta, nil
+}
+
+func ruleDiffPath(rule *models.AlertRule) string {
+	if rule.UID == "" {
+		return fmt.Sprintf("Rules[%s]", rule.Title)
+	}
+	return fmt.Sprintf("Rules[%s]", rule.UID)
+}
+
+// diffJSON compares the JSON representations of two resources. The path of a difference is made of the JSON keys of the
+// fields, separated by periods, and of the indexes of array elements in square brackets.
+func diffJSON(current, desired any) (cmputil.DiffReport, error) {
+	var left, right any
+	for _, v := range []struct {
+		in  any
+		out *any
+	}{{current, &left}, {desired, &right}} {
+		b, err := json.Marshal(v.in)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, v.out); err != nil {
+			return nil, err
+		}
+	}
+	var report cmputil.DiffReport
+	diffJSONValues("", left, right, &report)
+	return report, nil
+}
+
+func diffJSONValues(path string, left, right any, report *cmputil.DiffReport) {
+	switch l := left.(type) {
+	case map[string]any:
+		r, ok := right.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(l)+len(r))
+		for k := range l {
+			keys = append(keys, k)
+		}
+		for k := range r {
+			if _, ok := l[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			diffJSONValues(p, l[k], r[k], report)
+		}
+		return
+	case []any:
+		r, ok := right.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < len(l) || i < len(r); i++ {
+			var lv, rv any
+			if i < len(l) {
+				lv = l[i]
+			}
+			if i < len(r) {
+				rv = r[i]
+			}
+			diffJSONValues(path+"["+strconv.Itoa(i)+"]", lv, rv, report)
+		}
+		return
+	}
+	if reflect.DeepEqual(left, right) {
+		return
+	}
+	*report = append(*report, cmputil.Diff{
+		Path:  path,
+		Left:  reflect.ValueOf(left),
+		Right: reflect.ValueOf(right),
+	})
+}
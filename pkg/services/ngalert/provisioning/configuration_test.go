@@ -0,0 +1,335 @@
+package provisioning
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	secretsfakes "github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestConfigurationService(t *testing.T) {
+	orgID := int64(1)
+	u := &user.SignedInUser{OrgID: orgID}
+
+	contactPoint := func(uid, name, typ, settings string) definitions.EmbeddedContactPoint {
+		s, err := simplejson.NewJson([]byte(settings))
+		require.NoError(t, err)
+		return definitions.EmbeddedContactPoint{UID: uid, Name: name, Type: typ, Settings: s}
+	}
+	muteTiming := definitions.MuteTimeInterval{
+		MuteTimeInterval: config.MuteTimeInterval{
+			Name: "weekends",
+			TimeIntervals: []timeinterval.TimeInterval{{
+				Weekdays: []timeinterval.WeekdayRange{{InclusiveRange: timeinterval.InclusiveRange{Begin: 6, End: 6}}},
+			}},
+		},
+	}
+
+	t.Run("GetConfiguration returns all resources with redacted secrets", func(t *testing.T) {
+		sut, _, _, _ := createConfigurationServiceSut(t)
+
+		cfg, version, err := sut.GetConfiguration(context.Background(), u)
+		require.NoError(t, err)
+		assert.NotEmpty(t, version)
+		require.Len(t, cfg.ContactPoints, 2)
+		assert.Equal(t, definitions.RedactedValue, cfg.ContactPoints[1].Settings.Get("url").MustString())
+		require.NotNil(t, cfg.Policies)
+		assert.Equal(t, "grafana-default-email", cfg.Policies.Receiver)
+		assert.Len(t, cfg.RuleGroups, 1)
+		assert.Empty(t, cfg.Templates)
+		assert.Empty(t, cfg.MuteTimings)
+
+		_, again, err := sut.GetConfiguration(context.Background(), u)
+		require.NoError(t, err)
+		assert.Equal(t, version, again)
+	})
+
+	t.Run("the current configuration has no changes", func(t *testing.T) {
+		sut, _, _, _ := createConfigurationServiceSut(t)
+		cfg, _, err := sut.GetConfiguration(context.Background(), u)
+		require.NoError(t, err)
+		plan, err := sut.PlanConfiguration(context.Background(), u, cfg, models.ProvenanceNone)
+		require.NoError(t, err)
+		assert.Empty(t, plan.Changes)
+	})
+
+	t.Run("kinds of resources that are not set are not managed", func(t *testing.T) {
+		sut, _, _, _ := createConfigurationServiceSut(t)
+
+		plan, err := sut.PlanConfiguration(context.Background(), u, AlertingConfiguration{
+			Templates: []definitions.NotificationTemplate{},
+		}, models.ProvenanceAPI)
+		require.NoError(t, err)
+		assert.Empty(t, plan.Changes)
+	})
+
+	t.Run("PlanConfiguration returns the changes in the order they are applied", func(t *testing.T) {
+		sut, amStore, _, _ := createConfigurationServiceSut(t)
+		before := amStore.Config.AlertmanagerConfiguration
+
+		plan, err := sut.PlanConfiguration(context.Background(), u, AlertingConfiguration{
+			ContactPoints: []definitions.EmbeddedContactPoint{
+				contactPoint("UID1", "grafana-default-email", "email", `{"addresses":"team@example.com"}`),
+			},
+			MuteTimings: []definitions.MuteTimeInterval{muteTiming},
+			Templates:   []definitions.NotificationTemplate{{Name: "a", Template: `{{ define "a" }}a{{ end }}`}},
+		}, models.ProvenanceAPI)
+		require.NoError(t, err)
+		assert.Equal(t, before, amStore.Config.AlertmanagerConfiguration, "planning should not change the configuration")
+
+		type change struct {
+			kind   ConfigurationResourceKind
+			name   string
+			action ConfigurationAction
+		}
+		changes := make([]change, 0, len(plan.Changes))
+		for _, c := range plan.Changes {
+			changes = append(changes, change{c.Kind, c.Name, c.Action})
+		}
+		assert.Equal(t, []change{
+			{ConfigurationResourceTemplate, "a", ConfigurationActionCreate},
+			{ConfigurationResourceMuteTiming, "weekends", ConfigurationActionCreate},
+			{ConfigurationResourceContactPoint, "grafana-default-email", ConfigurationActionUpdate},
+			{ConfigurationResourceContactPoint, "slack receiver", ConfigurationActionDelete},
+		}, changes)
+
+		diff := plan.Changes[2].Diff
+		assert.Equal(t, []string{"settings.addresses", "provenance"}, diff.Paths())
+		assert.Equal(t, "team@example.com", diff[0].Right.Interface())
+	})
+
+	t.Run("redacted secrets keep their current value", func(t *testing.T) {
+		sut, _, _, _ := createConfigurationServiceSut(t)
+
+		plan, err := sut.PlanConfiguration(context.Background(), u, AlertingConfiguration{
+			ContactPoints: []definitions.EmbeddedContactPoint{
+				contactPoint("UID1", "grafana-default-email", "email", `{"addresses":"<example@email.com>"}`),
+				contactPoint("UID2", "slack receiver", "slack", `{"url":"[REDACTED]"}`),
+			},
+		}, models.ProvenanceNone)
+		require.NoError(t, err)
+		assert.Empty(t, plan.Changes)
+
+		plan, err = sut.PlanConfiguration(context.Background(), u, AlertingConfiguration{
+			ContactPoints: []definitions.EmbeddedContactPoint{
+				contactPoint("UID1", "grafana-default-email", "email", `{"addresses":"<example@email.com>"}`),
+				contactPoint("UID2", "slack receiver", "slack", `{"url":"https://hooks.slack.com/new"}`),
+			},
+		}, models.ProvenanceNone)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 1)
+		require.Len(t, plan.Changes[0].Diff, 1)
+		assert.Equal(t, definitions.RedactedValue, plan.Changes[0].Diff[0].Left.Interface())
+		assert.Equal(t, definitions.RedactedValue, plan.Changes[0].Diff[0].Right.Interface())
+
+		// The secure settings of the current type are redacted when the type of the contact point changes.
+		plan, err = sut.PlanConfiguration(context.Background(), u, AlertingConfiguration{
+			ContactPoints: []definitions.EmbeddedContactPoint{
+				contactPoint("UID1", "grafana-default-email", "email", `{"addresses":"<example@email.com>"}`),
+				contactPoint("UID2", "slack receiver", "email", `{"addresses":"team@example.com"}`),
+			},
+		}, models.ProvenanceNone)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 1)
+		var found bool
+		for _, d := range plan.Changes[0].Diff {
+			if d.Path == "settings.url" {
+				found = true
+				assert.Equal(t, definitions.RedactedValue, d.Left.Interface())
+			}
+		}
+		assert.True(t, found, "the diff should contain the secure setting of the current type")
+	})
+
+	t.Run("ApplyConfiguration applies the changes and tags the resources with the provenance", func(t *testing.T) {
+		sut, _, _, prov := createConfigurationServiceSut(t)
+		_, version, err := sut.GetConfiguration(context.Background(), u)
+		require.NoError(t, err)
+
+		desired := AlertingConfiguration{
+			ContactPoints: []definitions.EmbeddedContactPoint{
+				contactPoint("UID1", "grafana-default-email", "email", `{"addresses":"team@example.com"}`),
+			},
+			MuteTimings: []definitions.MuteTimeInterval{muteTiming},
+			Templates:   []definitions.NotificationTemplate{{Name: "a", Template: `{{ define "a" }}a{{ end }}`}},
+		}
+		plan, err := sut.ApplyConfiguration(context.Background(), u, desired, models.ProvenanceAPI, version)
+		require.NoError(t, err)
+		assert.Len(t, plan.Changes, 4)
+
+		cfg, newVersion, err := sut.GetConfiguration(context.Background(), u)
+		require.NoError(t, err)
+		assert.NotEqual(t, version, newVersion)
+		require.Len(t, cfg.ContactPoints, 1)
+		assert.Equal(t, "team@example.com", cfg.ContactPoints[0].Settings.Get("addresses").MustString())
+		require.Len(t, cfg.MuteTimings, 1)
+		assert.Equal(t, definitions.Provenance(models.ProvenanceAPI), cfg.MuteTimings[0].Provenance)
+		require.Len(t, cfg.Templates, 1)
+		assert.Equal(t, definitions.Provenance(models.ProvenanceAPI), cfg.Templates[0].Provenance)
+		p, err := prov.GetProvenance(context.Background(), &cfg.ContactPoints[0], orgID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ProvenanceAPI, p)
+
+		plan, err = sut.PlanConfiguration(context.Background(), u, desired, models.ProvenanceAPI)
+		require.NoError(t, err)
+		assert.Empty(t, plan.Changes)
+	})
+
+	t.Run("ApplyConfiguration fails if the version does not match", func(t *testing.T) {
+		sut, amStore, _, _ := createConfigurationServiceSut(t)
+		before := amStore.Config.AlertmanagerConfiguration
+
+		_, err := sut.ApplyConfiguration(context.Background(), u, AlertingConfiguration{
+			Templates: []definitions.NotificationTemplate{{Name: "a", Template: `{{ define "a" }}a{{ end }}`}},
+		}, models.ProvenanceAPI, "stale")
+		require.ErrorIs(t, err, ErrVersionConflict)
+		assert.Equal(t, before, amStore.Config.AlertmanagerConfiguration)
+	})
+
+	t.Run("resources with another provenance cannot be changed", func(t *testing.T) {
+		sut, _, _, prov := createConfigurationServiceSut(t)
+		require.NoError(t, prov.SetProvenance(context.Background(), &definitions.EmbeddedContactPoint{UID: "UID2"}, orgID, models.ProvenanceFile))
+
+		_, err := sut.PlanConfiguration(context.Background(), u, AlertingConfiguration{
+			ContactPoints: []definitions.EmbeddedContactPoint{
+				contactPoint("UID1", "grafana-default-email", "email", `{"addresses":"<example@email.com>"}`),
+			},
+		}, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrProvenanceChangeNotAllowed)
+	})
+
+	t.Run("invalid resources fail the plan", func(t *testing.T) {
+		sut, _, _, _ := createConfigurationServiceSut(t)
+
+		testCases := map[string]AlertingConfiguration{
+			"duplicate template": {Templates: []definitions.NotificationTemplate{
+				{Name: "a", Template: "a"}, {Name: "a", Template: "b"},
+			}},
+			"contact point without UID": {ContactPoints: []definitions.EmbeddedContactPoint{
+				contactPoint("", "new", "email", `{"addresses":"team@example.com"}`),
+			}},
+			"redacted secret of a new contact point": {ContactPoints: []definitions.EmbeddedContactPoint{
+				contactPoint("new", "new", "slack", `{"url":"[REDACTED]"}`),
+			}},
+			"policy with unknown contact point": {Policies: &definitions.Route{Receiver: "unknown"}},
+		}
+		for name, desired := range testCases {
+			t.Run(name, func(t *testing.T) {
+				_, err := sut.PlanConfiguration(context.Background(), u, desired, models.ProvenanceAPI)
+				require.ErrorIs(t, err, ErrValidation)
+			})
+		}
+	})
+
+	t.Run("rule groups", func(t *testing.T) {
+		sut, _, ruleStore, _ := createConfigurationServiceSut(t)
+		current := make([]*models.AlertRule, len(ruleStore.Rules[orgID]))
+		copy(current, ruleStore.Rules[orgID])
+
+		updated := models.CopyRule(current[0])
+		updated.Title = "updated title"
+		added := models.RuleGen.With(models.RuleGen.WithOrgID(orgID), models.RuleGen.WithNoNotificationSettings()).Generate()
+		added.UID = "new-rule"
+		desired := []models.AlertRuleGroup{{
+			Title:     current[0].RuleGroup,
+			FolderUID: current[0].NamespaceUID,
+			Interval:  current[0].IntervalSeconds,
+			Rules:     []models.AlertRule{*updated, added},
+		}}
+
+		plan, err := sut.PlanConfiguration(context.Background(), u, AlertingConfiguration{RuleGroups: []models.AlertRuleGroup{}}, models.ProvenanceNone)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 1, "groups that are not in the configuration should be deleted")
+		assert.Equal(t, ConfigurationActionDelete, plan.Changes[0].Action)
+		assert.Len(t, plan.Changes[0].Diff, len(current))
+
+		plan, err = sut.PlanConfiguration(context.Background(), u, AlertingConfiguration{RuleGroups: desired}, models.ProvenanceNone)
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 1)
+		change := plan.Changes[0]
+		assert.Equal(t, ConfigurationResourceRuleGroup, change.Kind)
+		assert.Equal(t, ConfigurationActionUpdate, change.Action)
+		assert.Equal(t, current[0].NamespaceUID, change.FolderUID)
+		paths := change.Diff.Paths()
+		assert.Contains(t, paths, "Rules[new-rule]")
+		assert.Contains(t, paths, "Rules["+current[0].UID+"].Title")
+		assert.Contains(t, paths, "Rules["+current[1].UID+"]")
+
+		_, err = sut.ApplyConfiguration(context.Background(), u, AlertingConfiguration{RuleGroups: desired}, models.ProvenanceNone, plan.Version)
+		require.NoError(t, err)
+		inserted := ruleStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			c, ok := cmd.([]models.AlertRule)
+			return c, ok
+		})
+		require.Len(t, inserted, 1)
+		rules := inserted[0].([]models.AlertRule)
+		require.Len(t, rules, 1)
+		assert.Equal(t, "new-rule", rules[0].UID, "the rule should be created with the UID of the configuration")
+	})
+}
+
+type fakeNotificationSettingsStore struct{}
+
+func (fakeNotificationSettingsStore) RenameReceiverInNotificationSettings(ctx context.Context, orgID int64, oldReceiver, newReceiver string) (int, error) {
+	return 0, nil
+}
+
+func (fakeNotificationSettingsStore) ListNotificationSettings(ctx context.Context, q models.ListNotificationSettingsQuery) (map[models.AlertRuleKey][]models.NotificationSettings, error) {
+	return nil, nil
+}
+
+func createConfigurationServiceSut(t *testing.T) (*ConfigurationService, *fakes.FakeAlertmanagerConfigStore, *fakes.RuleStore, *fakes.FakeProvisioningStore) {
+	t.Helper()
+	secretsService := secretsfakes.NewFakeSecretsService()
+	amStore := fakes.NewFakeAlertmanagerConfigStore(createEncryptedConfig(t, secretsService))
+	prov := fakes.NewFakeProvisioningStore()
+	xact := newNopTransactionManager()
+	logger := log.NewNopLogger()
+
+	alertRules, ruleStore, _, _ := initService(t)
+	alertRules.provenanceStore = prov
+	rules := models.RuleGen.With(
+		models.RuleGen.WithOrgID(1),
+		models.RuleGen.WithNamespaceUID("folder"),
+		models.RuleGen.WithGroupName("group"),
+		models.RuleGen.WithIntervalSeconds(60),
+		models.RuleGen.WithNoNotificationSettings(),
+	).GenerateManyRef(2)
+	for i, rule := range rules {
+		rule.RuleGroupIndex = i + 1
+	}
+	ruleStore.PutRule(context.Background(), rules...)
+
+	contactPoints := &ContactPointService{
+		configStore:               &alertmanagerConfigStoreImpl{store: amStore},
+		encryptionService:         secretsService,
+		provenanceStore:           prov,
+		notificationSettingsStore: fakeNotificationSettingsStore{},
+		xact:                      xact,
+		log:                       logger,
+	}
+	sut := NewConfigurationService(
+		alertRules,
+		contactPoints,
+		NewNotificationPolicyService(amStore, prov, xact, setting.UnifiedAlertingSettings{}, logger),
+		NewMuteTimingService(amStore, prov, xact, logger),
+		NewTemplateService(amStore, prov, xact, logger),
+		amStore,
+		prov,
+		xact,
+		logger,
+	)
+	return sut, amStore, ruleStore, prov
+}
@@ -124,6 +124,26 @@ func TestContactPointService(t *testing.T) {
 		require.ErrorIs(t, err, ErrValidation)
 	})
 
+	t.Run("service validates HTTP contact points and redacts their secrets", func(t *testing.T) {
+		sut := createContactPointServiceSut(t, secretsService)
+		newCp := createTestContactPoint()
+		newCp.Type = "http"
+		newCp.Settings, _ = simplejson.NewJson([]byte(`{"url":"https://example.com/alerts","authType":"bearer"}`))
+
+		_, err := sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.ErrorIs(t, err, ErrValidation)
+
+		newCp.Settings.Set("bearerToken", "token")
+		_, err = sut.CreateContactPoint(context.Background(), 1, newCp, models.ProvenanceAPI)
+		require.NoError(t, err)
+
+		cps, err := sut.GetContactPoints(context.Background(), cpsQueryWithName(1, newCp.Name), nil)
+		require.NoError(t, err)
+		require.Len(t, cps, 1)
+		require.Equal(t, "http", cps[0].Type)
+		require.Equal(t, definitions.RedactedValue, cps[0].Settings.Get("bearerToken").MustString())
+	})
+
 	t.Run("update rejects contact points with no settings", func(t *testing.T) {
 		sut := createContactPointServiceSut(t, secretsService)
 		newCp := createTestContactPoint()
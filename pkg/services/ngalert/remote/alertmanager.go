@@ -5,6 +5,7 @@ import (
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -17,6 +18,7 @@ import (
 	amgeneral "github.com/prometheus/alertmanager/api/v2/client/general"
 	amsilence "github.com/prometheus/alertmanager/api/v2/client/silence"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
 
 	alertingClusterPB "github.com/grafana/alerting/cluster/clusterpb"
 	alertingNotify "github.com/grafana/alerting/notify"
@@ -497,6 +499,14 @@ func (am *Alertmanager) TestTemplate(ctx context.Context, c apimodels.TestTempla
 	return &notifier.TestTemplatesResults{}, nil
 }
 
+func (am *Alertmanager) GetNotificationDeliveries(ctx context.Context, q notifier.NotificationDeliveryQuery) ([]notifier.NotificationDelivery, error) {
+	return nil, errors.New("the notification delivery log is not supported by the remote Alertmanager")
+}
+
+func (am *Alertmanager) ExplainRouting(ctx context.Context, labelSets []model.LabelSet) ([]notifier.RoutingExplanation, error) {
+	return nil, errors.New("routing explanation is not supported by the remote Alertmanager")
+}
+
 // StopAndWait is called when the grafana server is instructed to shut down or an org is deleted.
 // In the context of a "remote Alertmanager" it is a good heuristic for Grafana is about to shut down or we no longer need you.
 func (am *Alertmanager) StopAndWait() {
@@ -654,6 +654,14 @@ func TestForkedAlertmanager_ModeRemotePrimary(t *testing.T) {
 		require.ErrorIs(tt, expErr, err)
 	})
 
+	t.Run("GetNotificationDeliveries", func(tt *testing.T) {
+		// GetNotificationDeliveries should be called only in the remote Alertmanager, which sends the notifications.
+		_, remote, forked := genTestAlertmanagers(tt, modeRemotePrimary)
+		remote.EXPECT().GetNotificationDeliveries(mock.Anything, mock.Anything).Return(nil, expErr).Once()
+		_, err := forked.GetNotificationDeliveries(ctx, notifier.NotificationDeliveryQuery{})
+		require.ErrorIs(tt, expErr, err)
+	})
+
 	t.Run("StopAndWait", func(tt *testing.T) {
 		// StopAndWait should be called on both Alertmanagers.
 		internal, remote, forked := genTestAlertmanagers(tt, modeRemotePrimary)
@@ -11,6 +11,8 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 
+	model "github.com/prometheus/common/model"
+
 	models "github.com/grafana/grafana/pkg/services/ngalert/models"
 
 	notifier "github.com/grafana/grafana/pkg/services/ngalert/notifier"
@@ -277,6 +279,65 @@ func (_c *RemoteAlertmanagerMock_DeleteSilence_Call) RunAndReturn(run func(conte
 	return _c
 }
 
+// ExplainRouting provides a mock function with given fields: ctx, labelSets
+func (_m *RemoteAlertmanagerMock) ExplainRouting(ctx context.Context, labelSets []model.LabelSet) ([]notifier.RoutingExplanation, error) {
+	ret := _m.Called(ctx, labelSets)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExplainRouting")
+	}
+
+	var r0 []notifier.RoutingExplanation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []model.LabelSet) ([]notifier.RoutingExplanation, error)); ok {
+		return rf(ctx, labelSets)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []model.LabelSet) []notifier.RoutingExplanation); ok {
+		r0 = rf(ctx, labelSets)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]notifier.RoutingExplanation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []model.LabelSet) error); ok {
+		r1 = rf(ctx, labelSets)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoteAlertmanagerMock_ExplainRouting_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExplainRouting'
+type RemoteAlertmanagerMock_ExplainRouting_Call struct {
+	*mock.Call
+}
+
+// ExplainRouting is a helper method to define mock.On call
+//   - ctx context.Context
+//   - labelSets []model.LabelSet
+func (_e *RemoteAlertmanagerMock_Expecter) ExplainRouting(ctx interface{}, labelSets interface{}) *RemoteAlertmanagerMock_ExplainRouting_Call {
+	return &RemoteAlertmanagerMock_ExplainRouting_Call{Call: _e.mock.On("ExplainRouting", ctx, labelSets)}
+}
+
+func (_c *RemoteAlertmanagerMock_ExplainRouting_Call) Run(run func(ctx context.Context, labelSets []model.LabelSet)) *RemoteAlertmanagerMock_ExplainRouting_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]model.LabelSet))
+	})
+	return _c
+}
+
+func (_c *RemoteAlertmanagerMock_ExplainRouting_Call) Return(_a0 []notifier.RoutingExplanation, _a1 error) *RemoteAlertmanagerMock_ExplainRouting_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *RemoteAlertmanagerMock_ExplainRouting_Call) RunAndReturn(run func(context.Context, []model.LabelSet) ([]notifier.RoutingExplanation, error)) *RemoteAlertmanagerMock_ExplainRouting_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetAlertGroups provides a mock function with given fields: ctx, active, silenced, inhibited, filter, receiver
 func (_m *RemoteAlertmanagerMock) GetAlertGroups(ctx context.Context, active bool, silenced bool, inhibited bool, filter []string, receiver string) (v2models.AlertGroups, error) {
 	ret := _m.Called(ctx, active, silenced, inhibited, filter, receiver)
@@ -403,6 +464,65 @@ func (_c *RemoteAlertmanagerMock_GetAlerts_Call) RunAndReturn(run func(context.C
 	return _c
 }
 
+// GetNotificationDeliveries provides a mock function with given fields: ctx, q
+func (_m *RemoteAlertmanagerMock) GetNotificationDeliveries(ctx context.Context, q notifier.NotificationDeliveryQuery) ([]notifier.NotificationDelivery, error) {
+	ret := _m.Called(ctx, q)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetNotificationDeliveries")
+	}
+
+	var r0 []notifier.NotificationDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, notifier.NotificationDeliveryQuery) ([]notifier.NotificationDelivery, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, notifier.NotificationDeliveryQuery) []notifier.NotificationDelivery); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]notifier.NotificationDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, notifier.NotificationDeliveryQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoteAlertmanagerMock_GetNotificationDeliveries_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetNotificationDeliveries'
+type RemoteAlertmanagerMock_GetNotificationDeliveries_Call struct {
+	*mock.Call
+}
+
+// GetNotificationDeliveries is a helper method to define mock.On call
+//   - ctx context.Context
+//   - q notifier.NotificationDeliveryQuery
+func (_e *RemoteAlertmanagerMock_Expecter) GetNotificationDeliveries(ctx interface{}, q interface{}) *RemoteAlertmanagerMock_GetNotificationDeliveries_Call {
+	return &RemoteAlertmanagerMock_GetNotificationDeliveries_Call{Call: _e.mock.On("GetNotificationDeliveries", ctx, q)}
+}
+
+func (_c *RemoteAlertmanagerMock_GetNotificationDeliveries_Call) Run(run func(ctx context.Context, q notifier.NotificationDeliveryQuery)) *RemoteAlertmanagerMock_GetNotificationDeliveries_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(notifier.NotificationDeliveryQuery))
+	})
+	return _c
+}
+
+func (_c *RemoteAlertmanagerMock_GetNotificationDeliveries_Call) Return(_a0 []notifier.NotificationDelivery, _a1 error) *RemoteAlertmanagerMock_GetNotificationDeliveries_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *RemoteAlertmanagerMock_GetNotificationDeliveries_Call) RunAndReturn(run func(context.Context, notifier.NotificationDeliveryQuery) ([]notifier.NotificationDelivery, error)) *RemoteAlertmanagerMock_GetNotificationDeliveries_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetReceivers provides a mock function with given fields: ctx
 func (_m *RemoteAlertmanagerMock) GetReceivers(ctx context.Context) ([]alertingmodels.Receiver, error) {
 	ret := _m.Called(ctx)
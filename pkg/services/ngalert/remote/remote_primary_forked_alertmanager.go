@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -128,6 +129,16 @@ func (fam *RemotePrimaryForkedAlertmanager) TestTemplate(ctx context.Context, c
 	return fam.internal.TestTemplate(ctx, c)
 }
 
+func (fam *RemotePrimaryForkedAlertmanager) GetNotificationDeliveries(ctx context.Context, q notifier.NotificationDeliveryQuery) ([]notifier.NotificationDelivery, error) {
+	// The remote Alertmanager sends the notifications, so the log of the internal one would be empty.
+	return fam.remote.GetNotificationDeliveries(ctx, q)
+}
+
+func (fam *RemotePrimaryForkedAlertmanager) ExplainRouting(ctx context.Context, labelSets []model.LabelSet) ([]notifier.RoutingExplanation, error) {
+	// TODO: change to remote AM once it's implemented there.
+	return fam.internal.ExplainRouting(ctx, labelSets)
+}
+
 func (fam *RemotePrimaryForkedAlertmanager) SilenceState(ctx context.Context) (alertingNotify.SilenceState, error) {
 	return fam.remote.SilenceState(ctx)
 }
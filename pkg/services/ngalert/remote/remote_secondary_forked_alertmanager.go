@@ -7,6 +7,7 @@ import (
 	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -167,6 +168,14 @@ func (fam *RemoteSecondaryForkedAlertmanager) TestTemplate(ctx context.Context,
 	return fam.internal.TestTemplate(ctx, c)
 }
 
+func (fam *RemoteSecondaryForkedAlertmanager) GetNotificationDeliveries(ctx context.Context, q notifier.NotificationDeliveryQuery) ([]notifier.NotificationDelivery, error) {
+	return fam.internal.GetNotificationDeliveries(ctx, q)
+}
+
+func (fam *RemoteSecondaryForkedAlertmanager) ExplainRouting(ctx context.Context, labelSets []model.LabelSet) ([]notifier.RoutingExplanation, error) {
+	return fam.internal.ExplainRouting(ctx, labelSets)
+}
+
 func (fam *RemoteSecondaryForkedAlertmanager) SilenceState(ctx context.Context) (alertingNotify.SilenceState, error) {
 	return fam.internal.SilenceState(ctx)
 }
@@ -246,6 +246,7 @@ func (a *alertRule) Run(key ngmodels.AlertRuleKey) error {
 
 				evalStart := a.clock.Now()
 				defer func() {
+					ctx.done()
 					a.evalApplied(key, ctx.scheduledAt)
 					evalDuration.Observe(a.clock.Now().Sub(evalStart).Seconds())
 				}()
@@ -326,6 +327,13 @@ func (a *alertRule) Run(key ngmodels.AlertRuleKey) error {
 				states := a.stateManager.DeleteStateByRuleUID(ngmodels.WithRuleKey(ctx, key), key, ngmodels.StateReasonRuleDeleted)
 				a.notify(grafanaCtx, key, states)
 			}
+			// keep the state if another instance takes over the evaluation of the rule
+			var handoff ruleHandedOffError
+			if errors.As(grafanaCtx.Err(), &handoff) {
+				ctx, cancelFunc := context.WithTimeout(context.Background(), time.Minute)
+				defer cancelFunc()
+				a.stateManager.HandOffStateByRuleUID(ngmodels.WithRuleKey(ctx, key), key, handoff.version)
+			}
 			logger.Debug("Stopping alert rule routine")
 			return nil
 		}
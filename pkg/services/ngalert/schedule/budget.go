@@ -0,0 +1,247 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// evaluationBudgets limits the number of rule evaluations that run at the same time and the rate at which they start,
+// per organization and per data source. Evaluations that exceed a budget wait until the budget is available before
+// they are dispatched to the rule routines, so that the routines do not drop the ticks that arrive meanwhile.
+// Evaluations that wait longer than delayThreshold are reported as delayed.
+type evaluationBudgets struct {
+	cfg            setting.EvaluationBudgetSettings
+	delayThreshold time.Duration
+	metrics        *metrics.Scheduler
+
+	mtx         sync.Mutex
+	orgs        map[int64]*budget
+	datasources map[string]*budget
+	// delayed contains the rules whose last evaluation was delayed, and by how long.
+	delayed map[ngmodels.AlertRuleKey]time.Duration
+	// waiting contains the rules that have an evaluation waiting for the budgets.
+	waiting map[ngmodels.AlertRuleKey]struct{}
+}
+
+// newEvaluationBudgets returns the budgets for the configuration, or nil if the configuration has no limits.
+func newEvaluationBudgets(cfg setting.EvaluationBudgetSettings, delayThreshold time.Duration, met *metrics.Scheduler) *evaluationBudgets {
+	limited := cfg.OrgMaxConcurrency > 0 || cfg.OrgMaxQPS > 0 || cfg.DatasourceMaxConcurrency > 0 || cfg.DatasourceMaxQPS > 0
+	for _, ds := range cfg.Datasources {
+		limited = limited || ds.MaxConcurrency > 0 || ds.MaxQPS > 0
+	}
+	if !limited {
+		return nil
+	}
+	return &evaluationBudgets{
+		cfg:            cfg,
+		delayThreshold: delayThreshold,
+		metrics:        met,
+		orgs:           make(map[int64]*budget),
+		datasources:    make(map[string]*budget),
+		delayed:        make(map[ngmodels.AlertRuleKey]time.Duration),
+		waiting:        make(map[ngmodels.AlertRuleKey]struct{}),
+	}
+}
+
+// errEvaluationQueued is returned by acquire if an evaluation of the rule already waits for the budgets.
+var errEvaluationQueued = errors.New("an evaluation of the rule already waits for an evaluation budget")
+
+// acquire waits until the budgets of the data sources queried by the rule and of its organization allow the rule to be
+// evaluated. The returned function must be called when the evaluation is done.
+// Returns errEvaluationQueued if another evaluation of the rule already waits for the budgets, and an error if the
+// context is cancelled while waiting.
+func (b *evaluationBudgets) acquire(ctx context.Context, rule *ngmodels.AlertRule) (func(), error) {
+	if b == nil {
+		return func() {}, nil
+	}
+	budgets := b.budgetsFor(rule)
+	if len(budgets) == 0 {
+		return func() {}, nil
+	}
+
+	key := rule.GetKey()
+	b.mtx.Lock()
+	if _, ok := b.waiting[key]; ok {
+		b.mtx.Unlock()
+		return nil, errEvaluationQueued
+	}
+	b.waiting[key] = struct{}{}
+	b.mtx.Unlock()
+	defer func() {
+		b.mtx.Lock()
+		delete(b.waiting, key)
+		b.mtx.Unlock()
+	}()
+
+	orgID := fmt.Sprint(rule.OrgID)
+	queued := b.metrics.EvaluationsQueued.WithLabelValues(orgID)
+	queued.Inc()
+	defer queued.Dec()
+
+	start := time.Now()
+	acquired := make([]*budget, 0, len(budgets))
+	release := func() {
+		for _, bu := range acquired {
+			bu.release()
+		}
+	}
+	// The budgets are always acquired in the same order so that evaluations do not wait for each other. The budget of
+	// the organization is acquired last, so that evaluations that wait for a slow data source do not hold it and block
+	// the evaluations of the other data sources of the organization.
+	for _, bu := range budgets {
+		if err := bu.acquire(ctx); err != nil {
+			release()
+			return nil, err
+		}
+		acquired = append(acquired, bu)
+	}
+
+	waited := time.Since(start)
+	b.metrics.EvaluationQueueDuration.WithLabelValues(orgID).Observe(waited.Seconds())
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	if waited > b.delayThreshold {
+		b.metrics.EvaluationsDelayed.WithLabelValues(orgID).Inc()
+		b.delayed[key] = waited
+	} else {
+		delete(b.delayed, key)
+	}
+	return release, nil
+}
+
+// waitForBudgets waits until the evaluation budgets allow the rule to be evaluated, and sets the function that releases
+// them when the evaluation is done. Returns false if the evaluation must not be dispatched: either another evaluation
+// of the rule still waits for the budgets and the tick is merged into it, or the context is cancelled.
+func (sch *schedule) waitForBudgets(ctx context.Context, item *readyToRunItem) bool {
+	release, err := sch.budgets.acquire(ctx, item.rule)
+	if err != nil {
+		item.done()
+		key := item.rule.GetKey()
+		if errors.Is(err, errEvaluationQueued) {
+			sch.log.Warn("Tick merged into the evaluation of the rule that waits for an evaluation budget", append(key.LogContext(), "time", item.scheduledAt)...)
+			return false
+		}
+		sch.log.Debug("Scheduled evaluation was canceled while waiting for an evaluation budget", append(key.LogContext(), "time", item.scheduledAt)...)
+		return false
+	}
+	item.releaseBudgets = release
+	return true
+}
+
+// budgetsFor returns the budgets that limit the evaluation of the rule: the budgets of the data sources it queries
+// sorted by UID first, and then the budget of its organization.
+func (b *evaluationBudgets) budgetsFor(rule *ngmodels.AlertRule) []*budget {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	var result []*budget
+	uids := make([]string, 0, len(rule.Data))
+	for i := range rule.Data {
+		if isExpr, err := rule.Data[i].IsExpression(); err != nil || isExpr {
+			continue
+		}
+		uids = append(uids, rule.Data[i].DatasourceUID)
+	}
+	slices.Sort(uids)
+	for _, uid := range slices.Compact(uids) {
+		bu, ok := b.datasources[uid]
+		if !ok {
+			maxConcurrency, maxQPS := b.cfg.DatasourceMaxConcurrency, b.cfg.DatasourceMaxQPS
+			if override, ok := b.cfg.Datasources[uid]; ok {
+				maxConcurrency, maxQPS = override.MaxConcurrency, override.MaxQPS
+			}
+			bu = newBudget(maxConcurrency, maxQPS)
+			b.datasources[uid] = bu
+		}
+		if bu.limited() {
+			result = append(result, bu)
+		}
+	}
+
+	if b.cfg.OrgMaxConcurrency > 0 || b.cfg.OrgMaxQPS > 0 {
+		bu, ok := b.orgs[rule.OrgID]
+		if !ok {
+			bu = newBudget(b.cfg.OrgMaxConcurrency, b.cfg.OrgMaxQPS)
+			b.orgs[rule.OrgID] = bu
+		}
+		result = append(result, bu)
+	}
+	return result
+}
+
+// delay returns how long the last evaluation of the rule was delayed, if it was delayed by more than the threshold.
+func (b *evaluationBudgets) delay(key ngmodels.AlertRuleKey) (time.Duration, bool) {
+	if b == nil {
+		return 0, false
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	d, ok := b.delayed[key]
+	return d, ok
+}
+
+// forget removes the rule from the delayed rules.
+func (b *evaluationBudgets) forget(key ngmodels.AlertRuleKey) {
+	if b == nil {
+		return
+	}
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	delete(b.delayed, key)
+}
+
+// budget limits the number of concurrent evaluations and the rate at which they start.
+type budget struct {
+	sem     chan struct{}
+	limiter *rate.Limiter
+}
+
+func newBudget(maxConcurrency int, maxQPS float64) *budget {
+	bu := &budget{}
+	if maxConcurrency > 0 {
+		bu.sem = make(chan struct{}, maxConcurrency)
+	}
+	if maxQPS > 0 {
+		bu.limiter = rate.NewLimiter(rate.Limit(maxQPS), 1)
+	}
+	return bu
+}
+
+func (bu *budget) limited() bool {
+	return bu.sem != nil || bu.limiter != nil
+}
+
+func (bu *budget) acquire(ctx context.Context) error {
+	if bu.sem != nil {
+		select {
+		case bu.sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if bu.limiter != nil {
+		if err := bu.limiter.Wait(ctx); err != nil {
+			if bu.sem != nil {
+				<-bu.sem
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (bu *budget) release() {
+	if bu.sem != nil {
+		<-bu.sem
+	}
+}
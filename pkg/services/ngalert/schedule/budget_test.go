@@ -0,0 +1,203 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestEvaluationBudgets(t *testing.T) {
+	gen := models.RuleGen
+	query := func(dsUID string) models.AlertRuleMutator {
+		return gen.WithQuery(models.AlertQuery{
+			RefID:         "A",
+			DatasourceUID: dsUID,
+			Model:         json.RawMessage(`{"expr": "up"}`),
+		})
+	}
+	newBudgets := func(cfg setting.EvaluationBudgetSettings, delayThreshold time.Duration) *evaluationBudgets {
+		return newEvaluationBudgets(cfg, delayThreshold, metrics.NewSchedulerMetrics(prometheus.NewRegistry()))
+	}
+
+	t.Run("returns nil if there are no limits", func(t *testing.T) {
+		b := newBudgets(setting.EvaluationBudgetSettings{
+			Datasources: map[string]setting.DatasourceEvaluationBudget{"elastic": {}},
+		}, time.Second)
+		require.Nil(t, b)
+
+		release, err := b.acquire(context.Background(), gen.GenerateRef())
+		require.NoError(t, err)
+		release()
+		_, delayed := b.delay(models.GenerateRuleKey(1))
+		require.False(t, delayed)
+	})
+
+	t.Run("limits concurrent evaluations per data source", func(t *testing.T) {
+		b := newBudgets(setting.EvaluationBudgetSettings{
+			Datasources: map[string]setting.DatasourceEvaluationBudget{"elastic": {MaxConcurrency: 1}},
+		}, time.Hour)
+		elastic1 := gen.With(query("elastic")).GenerateRef()
+		elastic2 := gen.With(query("elastic")).GenerateRef()
+		loki := gen.With(query("loki")).GenerateRef()
+
+		release, err := b.acquire(context.Background(), elastic1)
+		require.NoError(t, err)
+
+		// Rules that query other data sources are not limited.
+		releaseLoki, err := b.acquire(context.Background(), loki)
+		require.NoError(t, err)
+		releaseLoki()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = b.acquire(ctx, elastic2)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		release()
+		release, err = b.acquire(context.Background(), elastic2)
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("limits concurrent evaluations per organization", func(t *testing.T) {
+		b := newBudgets(setting.EvaluationBudgetSettings{OrgMaxConcurrency: 1}, time.Hour)
+		rule1 := gen.With(gen.WithOrgID(1)).GenerateRef()
+		rule2 := gen.With(gen.WithOrgID(1)).GenerateRef()
+		otherOrg := gen.With(gen.WithOrgID(2)).GenerateRef()
+
+		release, err := b.acquire(context.Background(), rule1)
+		require.NoError(t, err)
+		defer release()
+
+		releaseOther, err := b.acquire(context.Background(), otherOrg)
+		require.NoError(t, err)
+		releaseOther()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err = b.acquire(ctx, rule2)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("does not hold the budget of the organization while waiting for a data source", func(t *testing.T) {
+		b := newBudgets(setting.EvaluationBudgetSettings{
+			OrgMaxConcurrency: 2,
+			Datasources:       map[string]setting.DatasourceEvaluationBudget{"elastic": {MaxConcurrency: 1}},
+		}, time.Hour)
+		elastic1 := gen.With(gen.WithOrgID(1), query("elastic")).GenerateRef()
+		elastic2 := gen.With(gen.WithOrgID(1), query("elastic")).GenerateRef()
+		loki := gen.With(gen.WithOrgID(1), query("loki")).GenerateRef()
+
+		release, err := b.acquire(context.Background(), elastic1)
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		waiting := make(chan error)
+		go func() {
+			_, err := b.acquire(ctx, elastic2)
+			waiting <- err
+		}()
+		defer func() {
+			cancel()
+			require.ErrorIs(t, <-waiting, context.Canceled)
+		}()
+
+		// The evaluation waiting for the elastic budget does not take the second slot of the organization.
+		timeout, cancelTimeout := context.WithTimeout(context.Background(), time.Second)
+		defer cancelTimeout()
+		releaseLoki, err := b.acquire(timeout, loki)
+		require.NoError(t, err)
+		releaseLoki()
+	})
+
+	t.Run("merges evaluations of a rule that already waits", func(t *testing.T) {
+		b := newBudgets(setting.EvaluationBudgetSettings{OrgMaxConcurrency: 1}, time.Hour)
+		rule1 := gen.With(gen.WithOrgID(1)).GenerateRef()
+		rule2 := gen.With(gen.WithOrgID(1)).GenerateRef()
+
+		release, err := b.acquire(context.Background(), rule1)
+		require.NoError(t, err)
+
+		waiting := make(chan error)
+		go func() {
+			release, err := b.acquire(context.Background(), rule2)
+			if err == nil {
+				release()
+			}
+			waiting <- err
+		}()
+		require.Eventually(t, func() bool {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			_, err := b.acquire(ctx, rule2)
+			return errors.Is(err, errEvaluationQueued)
+		}, time.Second, 10*time.Millisecond)
+
+		release()
+		require.NoError(t, <-waiting)
+	})
+
+	t.Run("reports evaluations delayed by more than the threshold", func(t *testing.T) {
+		b := newBudgets(setting.EvaluationBudgetSettings{DatasourceMaxQPS: 10}, 50*time.Millisecond)
+		rule := gen.With(query("elastic")).GenerateRef()
+		key := rule.GetKey()
+
+		release, err := b.acquire(context.Background(), rule)
+		require.NoError(t, err)
+		release()
+		_, delayed := b.delay(key)
+		require.False(t, delayed)
+
+		// Exhaust the budget so that the next evaluation waits for 100ms.
+		for i := 0; i < 2; i++ {
+			release, err = b.acquire(context.Background(), rule)
+			require.NoError(t, err)
+			release()
+		}
+		d, delayed := b.delay(key)
+		require.True(t, delayed)
+		require.Greater(t, d, 50*time.Millisecond)
+
+		b.forget(key)
+		_, delayed = b.delay(key)
+		require.False(t, delayed)
+	})
+}
+
+func TestWaitForBudgets(t *testing.T) {
+	gen := models.RuleGen
+	sch := &schedule{
+		log:     log.NewNopLogger(),
+		budgets: newEvaluationBudgets(setting.EvaluationBudgetSettings{OrgMaxConcurrency: 1}, time.Hour, metrics.NewSchedulerMetrics(prometheus.NewRegistry())),
+	}
+	rule := gen.With(gen.WithOrgID(1)).GenerateRef()
+	other := gen.With(gen.WithOrgID(1)).GenerateRef()
+
+	var afterEval int
+	item := readyToRunItem{Evaluation: Evaluation{rule: rule, afterEval: func() { afterEval++ }}}
+	require.True(t, sch.waitForBudgets(context.Background(), &item))
+	require.NotNil(t, item.releaseBudgets)
+
+	// The budget is held until the evaluation is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	otherItem := readyToRunItem{Evaluation: Evaluation{rule: other, afterEval: func() { afterEval++ }}}
+	require.False(t, sch.waitForBudgets(ctx, &otherItem))
+	require.Equal(t, 1, afterEval, "the evaluation that is not dispatched should be done")
+
+	item.done()
+	require.Nil(t, item.releaseBudgets)
+	require.True(t, sch.waitForBudgets(context.Background(), &otherItem))
+	otherItem.done()
+}
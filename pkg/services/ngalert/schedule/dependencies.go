@@ -0,0 +1,101 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ruleDependencies contains, for every rule that depends on other rules of its group, the keys of these rules.
+type ruleDependencies map[ngmodels.AlertRuleKey][]ngmodels.AlertRuleKey
+
+// buildRuleDependencies resolves the dependencies between the rules of each group. The rules of groups whose
+// dependencies form a cycle do not get any dependencies, and are evaluated independently. Returns an error for every
+// such group.
+func buildRuleDependencies(rules []*ngmodels.AlertRule) (ruleDependencies, []error) {
+	groups := make(map[ngmodels.AlertRuleGroupKey][]*ngmodels.AlertRule)
+	for _, rule := range rules {
+		key := rule.GetGroupKey()
+		groups[key] = append(groups[key], rule)
+	}
+
+	result := make(ruleDependencies)
+	var errs []error
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		groupDeps := ngmodels.NewGroupDependencies(group)
+		if _, err := groupDeps.Sort(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, rule := range group {
+			deps := groupDeps.Of(rule)
+			if len(deps) == 0 {
+				continue
+			}
+			keys := make([]ngmodels.AlertRuleKey, 0, len(deps))
+			for _, dep := range deps {
+				keys = append(keys, ngmodels.AlertRuleKey{OrgID: rule.OrgID, UID: dep.UID})
+			}
+			result[rule.GetKey()] = keys
+		}
+	}
+	return result, errs
+}
+
+// chainEvaluations links the evaluations of the rules that are ready to run on the same tick and depend on each other.
+// It returns, for every rule that depends on other ready rules, the channels that are closed when the evaluations of
+// these rules are done.
+func chainEvaluations(readyToRun []readyToRunItem, deps ruleDependencies) map[ngmodels.AlertRuleKey][]<-chan struct{} {
+	if len(deps) == 0 {
+		return nil
+	}
+	ready := make(map[ngmodels.AlertRuleKey]int, len(readyToRun))
+	for i, item := range readyToRun {
+		ready[item.rule.GetKey()] = i
+	}
+
+	done := make(map[ngmodels.AlertRuleKey]chan struct{})
+	result := make(map[ngmodels.AlertRuleKey][]<-chan struct{})
+	for _, item := range readyToRun {
+		key := item.rule.GetKey()
+		for _, dep := range deps[key] {
+			i, ok := ready[dep]
+			if !ok {
+				continue
+			}
+			ch, ok := done[dep]
+			if !ok {
+				ch = make(chan struct{})
+				done[dep] = ch
+				var once sync.Once
+				readyToRun[i].afterEval = func() {
+					once.Do(func() { close(ch) })
+				}
+			}
+			result[key] = append(result[key], ch)
+		}
+	}
+	return result
+}
+
+// waitForDependencies blocks until the evaluations of the dependencies of the rule are done, the evaluation interval of
+// the rule elapses, or the context is cancelled.
+func (sch *schedule) waitForDependencies(ctx context.Context, item readyToRunItem, waitFor []<-chan struct{}) {
+	timeout := time.NewTimer(time.Duration(item.rule.IntervalSeconds) * time.Second)
+	defer timeout.Stop()
+	for _, ch := range waitFor {
+		select {
+		case <-ch:
+		case <-timeout.C:
+			sch.log.Warn("Evaluating rule without waiting for its dependencies because they did not complete in time", item.rule.GetKey().LogContext()...)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
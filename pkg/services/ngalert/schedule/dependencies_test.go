@@ -0,0 +1,84 @@
+package schedule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestBuildRuleDependencies(t *testing.T) {
+	gen := models.RuleGen
+	groupKey := models.GenerateGroupKey(1)
+
+	t.Run("resolves dependencies within groups", func(t *testing.T) {
+		rules := gen.With(gen.WithGroupKey(groupKey)).GenerateManyRef(3, 3)
+		rules[0].DependsOn = []string{rules[1].UID}
+		other := gen.With(gen.WithOrgID(1), gen.WithDependsOn(rules[2].UID)).GenerateRef()
+
+		deps, errs := buildRuleDependencies(append(rules, other))
+		require.Empty(t, errs)
+		require.Equal(t, ruleDependencies{
+			rules[0].GetKey(): {rules[1].GetKey()},
+		}, deps)
+	})
+
+	t.Run("ignores groups with cycles", func(t *testing.T) {
+		rules := gen.With(gen.WithGroupKey(groupKey)).GenerateManyRef(2, 2)
+		rules[0].DependsOn = []string{rules[1].UID}
+		rules[1].DependsOn = []string{rules[0].UID}
+
+		deps, errs := buildRuleDependencies(rules)
+		require.Len(t, errs, 1)
+		require.ErrorIs(t, errs[0], models.ErrRuleDependencyCycle)
+		require.Empty(t, deps)
+	})
+}
+
+func TestChainEvaluations(t *testing.T) {
+	gen := models.RuleGen
+	rules := gen.With(gen.WithGroupKey(models.GenerateGroupKey(1))).GenerateManyRef(3, 3)
+	deps := ruleDependencies{
+		rules[0].GetKey(): {rules[1].GetKey(), rules[2].GetKey()},
+	}
+
+	t.Run("waits only for dependencies ready on the same tick", func(t *testing.T) {
+		readyToRun := []readyToRunItem{
+			{Evaluation: Evaluation{rule: rules[0]}},
+			{Evaluation: Evaluation{rule: rules[1]}},
+		}
+
+		waitFor := chainEvaluations(readyToRun, deps)
+		require.Len(t, waitFor, 1)
+		require.Len(t, waitFor[rules[0].GetKey()], 1)
+		require.Nil(t, readyToRun[0].afterEval)
+		require.NotNil(t, readyToRun[1].afterEval)
+
+		ch := waitFor[rules[0].GetKey()][0]
+		select {
+		case <-ch:
+			require.Fail(t, "dependency should not be done before it is evaluated")
+		default:
+		}
+
+		// done can be called more than once.
+		readyToRun[1].done()
+		readyToRun[1].done()
+		select {
+		case <-ch:
+		default:
+			require.Fail(t, "dependency should be done after it is evaluated")
+		}
+	})
+
+	t.Run("does nothing without dependencies", func(t *testing.T) {
+		readyToRun := []readyToRunItem{
+			{Evaluation: Evaluation{rule: rules[1]}},
+			{Evaluation: Evaluation{rule: rules[2]}},
+		}
+		require.Empty(t, chainEvaluations(readyToRun, deps))
+		require.Nil(t, readyToRun[0].afterEval)
+		require.Nil(t, readyToRun[1].afterEval)
+	})
+}
@@ -36,6 +36,11 @@ func (sch *schedule) updateSchedulableAlertRules(ctx context.Context) (diff, err
 		return diff{}, fmt.Errorf("failed to get alert rules: %w", err)
 	}
 	d := sch.schedulableAlertRules.set(q.ResultRules, q.ResultFoldersTitles)
+	deps, errs := buildRuleDependencies(q.ResultRules)
+	for _, err := range errs {
+		sch.log.Warn("Rules of the group are evaluated independently because their dependencies cannot be resolved", "error", err)
+	}
+	sch.schedulableAlertRules.setDependencies(deps)
 	sch.log.Debug("Alert rules fetched", "rulesCount", len(q.ResultRules), "foldersCount", len(q.ResultFoldersTitles), "updatedRules", len(d.updated))
 	return d, nil
 }
@@ -1,7 +1,9 @@
 package schedule
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -68,3 +70,22 @@ func jitterHash(r *ngmodels.AlertRule, strategy JitterStrategy) uint64 {
 	}
 	return uint64(ls.Fingerprint())
 }
+
+// jitterDispatchOrder orders the rules that are ready to run on a tick by their jitter hash, so that the rules the
+// strategy jitters together are dispatched together, and rotates the order by the tick number, so that the same rules
+// are not always dispatched first.
+func jitterDispatchOrder(items []readyToRunItem, strategy JitterStrategy, tickNum int64) {
+	if len(items) < 2 {
+		return
+	}
+	hashes := make(map[ngmodels.AlertRuleKey]uint64, len(items))
+	for _, item := range items {
+		hashes[item.rule.GetKey()] = jitterHash(item.rule, strategy)
+	}
+	slices.SortStableFunc(items, func(a, b readyToRunItem) int {
+		return cmp.Compare(hashes[a.rule.GetKey()], hashes[b.rule.GetKey()])
+	})
+	shift := int(tickNum % int64(len(items)))
+	rotated := append(slices.Clone(items[shift:]), items[:shift]...)
+	copy(items, rotated)
+}
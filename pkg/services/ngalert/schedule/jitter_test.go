@@ -93,3 +93,41 @@ func TestJitter(t *testing.T) {
 		})
 	})
 }
+
+func TestJitterDispatchOrder(t *testing.T) {
+	gen := ngmodels.RuleGen
+	group1 := gen.With(gen.WithGroupKey(ngmodels.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "ns", RuleGroup: "group1"})).GenerateManyRef(5)
+	group2 := gen.With(gen.WithGroupKey(ngmodels.AlertRuleGroupKey{OrgID: 1, NamespaceUID: "ns", RuleGroup: "group2"})).GenerateManyRef(5)
+	readyToRun := func() []readyToRunItem {
+		var items []readyToRunItem
+		for i := range group1 {
+			items = append(items, readyToRunItem{Evaluation: Evaluation{rule: group1[i]}}, readyToRunItem{Evaluation: Evaluation{rule: group2[i]}})
+		}
+		return items
+	}
+	groups := func(items []readyToRunItem) []string {
+		result := make([]string, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.rule.RuleGroup)
+		}
+		return result
+	}
+
+	t.Run("dispatches the rules of a group together", func(t *testing.T) {
+		items := readyToRun()
+		jitterDispatchOrder(items, JitterByGroup, 0)
+		require.Equal(t, items[0].rule.RuleGroup, items[4].rule.RuleGroup)
+		require.Equal(t, items[5].rule.RuleGroup, items[9].rule.RuleGroup)
+		require.NotEqual(t, items[0].rule.RuleGroup, items[5].rule.RuleGroup)
+	})
+
+	t.Run("rotates the order by tick", func(t *testing.T) {
+		first := readyToRun()
+		jitterDispatchOrder(first, JitterByGroup, 0)
+		next := readyToRun()
+		jitterDispatchOrder(next, JitterByGroup, 3)
+		require.Equal(t, first[3].rule.UID, next[0].rule.UID)
+		require.Equal(t, first[0].rule.UID, next[7].rule.UID)
+		require.Equal(t, append(groups(first[3:]), groups(first[:3])...), groups(next))
+	})
+}
@@ -97,6 +97,7 @@ func (r *recordingRule) Run(key ngmodels.AlertRuleKey) error {
 				return nil
 			}
 			if !r.featureToggles.IsEnabled(ctx, featuremgmt.FlagGrafanaManagedRecordingRules) {
+				eval.done()
 				logger.Warn("Recording rule scheduled but toggle is not enabled. Skipping")
 				return nil
 			}
@@ -122,6 +123,7 @@ func (r *recordingRule) doEvaluate(ctx context.Context, ev *Evaluation) {
 	evalStart := r.clock.Now()
 
 	defer func() {
+		ev.done()
 		evalTotal.Inc()
 		evalDuration.Observe(r.clock.Now().Sub(evalStart).Seconds())
 		r.evaluationDoneTestHook(ev)
@@ -215,13 +217,13 @@ func (r *recordingRule) tryEvaluation(ctx context.Context, ev *Evaluation, logge
 	}
 
 	writeStart := r.clock.Now()
-	err = r.writer.Write(ctx, ev.rule.Record.Metric, writeStart, frames, ev.rule.Labels)
+	err = r.writer.Write(ctx, ev.rule.OrgID, ev.rule.Record, writeStart, frames, ev.rule.Labels)
 	writeDur := r.clock.Now().Sub(writeStart)
 
 	if err != nil {
 		span.SetStatus(codes.Error, "failed to write metrics")
 		span.RecordError(err)
-		return fmt.Errorf("metric remote write failed: %w", err)
+		return fmt.Errorf("metric write failed: %w", err)
 	}
 
 	logger.Debug("Metrics written", "duration", writeDur)
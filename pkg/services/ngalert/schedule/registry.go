@@ -85,6 +85,22 @@ type Evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// afterEval is called when the evaluation is done or dropped. It is set when other rules wait for the evaluation.
+	afterEval func()
+	// releaseBudgets releases the evaluation budgets acquired for the evaluation. It is set when evaluations are limited.
+	releaseBudgets func()
+}
+
+// done releases the evaluation budgets of the evaluation, and notifies the rules that wait for the evaluation that it
+// is completed.
+func (e *Evaluation) done() {
+	if e.releaseBudgets != nil {
+		e.releaseBudgets()
+		e.releaseBudgets = nil
+	}
+	if e.afterEval != nil {
+		e.afterEval()
+	}
 }
 
 func (e *Evaluation) Fingerprint() fingerprint {
@@ -94,6 +110,7 @@ func (e *Evaluation) Fingerprint() fingerprint {
 type alertRulesRegistry struct {
 	rules        map[models.AlertRuleKey]*models.AlertRule
 	folderTitles map[models.FolderKey]string
+	dependencies ruleDependencies
 	mu           sync.Mutex
 }
 
@@ -129,6 +146,20 @@ func (r *alertRulesRegistry) set(rules []*models.AlertRule, folders map[models.F
 	return d
 }
 
+// setDependencies replaces the dependencies between the rules in the registry.
+func (r *alertRulesRegistry) setDependencies(deps ruleDependencies) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dependencies = deps
+}
+
+// getDependencies returns the dependencies between the rules in the registry.
+func (r *alertRulesRegistry) getDependencies() ruleDependencies {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dependencies
+}
+
 // update inserts or replaces a rule in the registry.
 func (r *alertRulesRegistry) update(rule *models.AlertRule) {
 	r.mu.Lock()
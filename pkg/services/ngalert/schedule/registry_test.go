@@ -151,13 +151,16 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 		f2 := ruleWithFolder{rule: rule, folderTitle: uuid.NewString()}.Fingerprint()
 		require.NotEqual(t, f, f2)
 	})
-	t.Run("Version, Updated, IntervalSeconds and Annotations should be excluded from fingerprint", func(t *testing.T) {
+	t.Run("Version, Updated, UpdatedBy, IntervalSeconds, Annotations and DependsOn should be excluded from fingerprint", func(t *testing.T) {
 		cp := models.CopyRule(rule)
 		cp.Version++
 		cp.Updated = cp.Updated.Add(1 * time.Second)
 		cp.IntervalSeconds++
 		cp.Annotations = make(map[string]string)
 		cp.Annotations["test"] = "test"
+		cp.DependsOn = []string{"other-rule"}
+		updatedBy := models.UserUID("other-user")
+		cp.UpdatedBy = &updatedBy
 
 		f2 := ruleWithFolder{rule: cp, folderTitle: title}.Fingerprint()
 		require.Equal(t, f, f2)
@@ -250,6 +253,11 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			"Updated":         {},
 			"IntervalSeconds": {},
 			"Annotations":     {},
+			// UpdatedBy is metadata about the last change, like Version and Updated.
+			"UpdatedBy": {},
+			// The dependencies do not change how the rule is evaluated but only when, and the scheduler resolves them from
+			// the registry at every tick, so the rule routine does not need to be updated when they change.
+			"DependsOn": {},
 		}
 
 		tp := reflect.TypeOf(rule).Elem()
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/ticker"
 )
 
@@ -47,8 +48,9 @@ type RulesStore interface {
 	GetAlertRulesForScheduling(ctx context.Context, query *ngmodels.GetAlertRulesForSchedulingQuery) error
 }
 
+// RecordingWriter writes the results of recording rules to the target of the rule.
 type RecordingWriter interface {
-	Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+	Write(ctx context.Context, orgID int64, record *ngmodels.Record, t time.Time, frames data.Frames, extraLabels map[string]string) error
 }
 
 type schedule struct {
@@ -99,6 +101,17 @@ type schedule struct {
 	tracer tracing.Tracer
 
 	recordingWriter RecordingWriter
+
+	// clusterMembership provides the members of the cluster the rules are sharded across. If it is nil, every rule
+	// is evaluated by this instance.
+	clusterMembership ClusterMembership
+	// shardRing is the ring that was used to assign the rules to the members of the cluster on the last tick.
+	shardRing *shardRing
+	// pendingHandoffs are the rules this instance waits for the previous owner to hand off, by the time it started to wait.
+	pendingHandoffs map[ngmodels.AlertRuleKey]time.Time
+
+	// budgets limits the evaluations per organization and per data source. If it is nil, evaluations are not limited.
+	budgets *evaluationBudgets
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -118,6 +131,10 @@ type SchedulerCfg struct {
 	Tracer               tracing.Tracer
 	Log                  log.Logger
 	RecordingWriter      RecordingWriter
+	// ClusterMembership shards the evaluation of rules across the members of the cluster. Optional.
+	ClusterMembership ClusterMembership
+	// EvaluationBudgets limits the evaluations of rules per organization and per data source.
+	EvaluationBudgets setting.EvaluationBudgetSettings
 }
 
 // NewScheduler returns a new scheduler.
@@ -147,6 +164,9 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		alertsSender:          cfg.AlertSender,
 		tracer:                cfg.Tracer,
 		recordingWriter:       cfg.RecordingWriter,
+		clusterMembership:     cfg.ClusterMembership,
+		pendingHandoffs:       make(map[ngmodels.AlertRuleKey]time.Time),
+		budgets:               newEvaluationBudgets(cfg.EvaluationBudgets, cfg.BaseInterval, cfg.Metrics),
 	}
 
 	return &sch
@@ -170,6 +190,12 @@ func (sch *schedule) Rules() ([]*ngmodels.AlertRule, map[ngmodels.FolderKey]stri
 	return sch.schedulableAlertRules.all()
 }
 
+// EvaluationDelay returns how long the last evaluation of the rule waited for the evaluation budgets of its
+// organization and data sources, if it waited longer than the base interval of the scheduler.
+func (sch *schedule) EvaluationDelay(key ngmodels.AlertRuleKey) (time.Duration, bool) {
+	return sch.budgets.delay(key)
+}
+
 // deleteAlertRule stops evaluation of the rule, deletes it from active rules, and cleans up state cache.
 func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	for _, key := range keys {
@@ -179,6 +205,7 @@ func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 		if _, ok := sch.schedulableAlertRules.del(key); !ok {
 			sch.log.Info("Alert rule cannot be removed from the scheduler as it is not scheduled", key.LogContext()...)
 		}
+		sch.budgets.forget(key)
 		// Delete the rule routine
 		ruleRoutine, ok := sch.registry.del(key)
 		if !ok {
@@ -247,6 +274,14 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	sch.updateRulesMetrics(alertRules)
 
+	// If the rules are sharded, only the rules assigned to this instance are evaluated.
+	previousRing := sch.shardRing
+	firstShardedTick := previousRing == nil
+	ring := sch.currentShardRing()
+	if ring != nil && !firstShardedTick {
+		previousRing = previousOwners(previousRing, ring)
+	}
+
 	readyToRun := make([]readyToRunItem, 0)
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	missingFolder := make(map[string][]string)
@@ -268,10 +303,32 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.stopAppliedFunc,
 	)
 	for _, item := range alertRules {
-		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
 		key := item.GetKey()
 		logger := sch.log.FromContext(ctx).New(key.LogContext()...)
 
+		if ring != nil && !ring.owns(key) {
+			// The rule is evaluated by another instance. If this instance evaluated it so far, the rule routine
+			// hands off the states of the rule so that the new owner continues from them.
+			if ruleRoutine, ok := sch.registry.del(key); ok {
+				logger.Debug("Rule is handed off to another instance", "owner", ring.owner(key))
+				ruleRoutine.Stop(ruleHandedOffError{version: ring.version})
+			} else if firstShardedTick {
+				// Drop the states loaded on startup, they are maintained by the owner of the rule.
+				sch.stateManager.ForgetStateByRuleUID(key)
+			}
+			delete(sch.pendingHandoffs, key)
+			delete(registeredDefinitions, key)
+			continue
+		}
+
+		if ring != nil && !firstShardedTick && !sch.takeOver(ctx, item, previousRing, ring) {
+			logger.Debug("Waiting for the previous owner to hand off the rule")
+			delete(registeredDefinitions, key)
+			continue
+		}
+
+		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
+
 		// enforce minimum evaluation interval
 		if item.IntervalSeconds < int64(sch.minRuleInterval.Seconds()) {
 			logger.Debug("Interval adjusted", "originalInterval", item.IntervalSeconds, "adjustedInterval", sch.minRuleInterval.Seconds())
@@ -346,22 +403,51 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 	slices.SortFunc(readyToRun, func(a, b readyToRunItem) int {
 		return strings.Compare(a.rule.UID, b.rule.UID)
 	})
+	// The rules dispatched first on a tick are the first to get the evaluation budgets.
+	if sch.budgets != nil {
+		jitterDispatchOrder(readyToRun, sch.jitterEvaluations, tickNum)
+	}
+	// Rules that depend on other rules ready to run on this tick are evaluated after their dependencies are evaluated.
+	waitFor := chainEvaluations(readyToRun, sch.schedulableAlertRules.getDependencies())
 	for i := range readyToRun {
 		item := readyToRun[i]
 
-		time.AfterFunc(time.Duration(int64(i)*step), func() {
+		eval := func() {
 			key := item.rule.GetKey()
+			if !sch.waitForBudgets(ctx, &item) {
+				return
+			}
 			success, dropped := item.ruleRoutine.Eval(&item.Evaluation)
 			if !success {
+				item.done()
 				sch.log.Debug("Scheduled evaluation was canceled because evaluation routine was stopped", append(key.LogContext(), "time", tick)...)
 				return
 			}
 			if dropped != nil {
+				dropped.done()
 				sch.log.Warn("Tick dropped because alert rule evaluation is too slow", append(key.LogContext(), "time", tick)...)
 				orgID := fmt.Sprint(key.OrgID)
 				sch.metrics.EvaluationMissed.WithLabelValues(orgID, item.rule.Title).Inc()
 			}
-		})
+		}
+
+		// Rules that depend on other rules still start at their own offset in the tick, and then wait for their
+		// dependencies that have not been evaluated yet.
+		if deps := waitFor[item.rule.GetKey()]; len(deps) > 0 {
+			time.AfterFunc(time.Duration(int64(i)*step), func() {
+				sch.waitForDependencies(ctx, item, deps)
+				eval()
+			})
+			continue
+		}
+		time.AfterFunc(time.Duration(int64(i)*step), eval)
+	}
+
+	// stop waiting for the handoff of the deleted alert rules
+	for key := range sch.pendingHandoffs {
+		if sch.schedulableAlertRules.get(key) == nil {
+			delete(sch.pendingHandoffs, key)
+		}
 	}
 
 	// unregister and stop routines of the deleted alert rules
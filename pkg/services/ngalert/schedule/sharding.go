@@ -0,0 +1,184 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// errRuleHandedOff is the reason a rule routine is stopped when another instance of the cluster takes over the
+// evaluation of the rule.
+var errRuleHandedOff = errors.New("rule handed off to another instance")
+
+// ruleHandedOffError is the reason a rule routine is stopped with when the rule is handed off. It carries the version
+// of the ring the handoff is recorded for.
+type ruleHandedOffError struct {
+	version string
+}
+
+func (e ruleHandedOffError) Error() string { return errRuleHandedOff.Error() }
+func (e ruleHandedOffError) Unwrap() error { return errRuleHandedOff }
+
+// shardRingReplicas is the number of points every member of the cluster gets on the hash ring. More points spread
+// the rules more evenly across the members.
+const shardRingReplicas = 128
+
+// maxHandoffWait is how long this instance waits for the previous owner of a rule to hand off the states of the rule
+// before it evaluates the rule from the last saved states, for example because the previous owner is not responsive.
+const maxHandoffWait = time.Minute
+
+// ClusterMembership provides the members of the high availability cluster the rules are sharded across.
+type ClusterMembership interface {
+	// Self returns the name of this instance in the cluster.
+	Self() string
+	// Members returns the names of the live members of the cluster, including this instance.
+	Members() []string
+}
+
+// shardRing assigns every alert rule to one member of the cluster using consistent hashing, so that only the rules
+// of the members that join or leave the cluster are moved to other members.
+type shardRing struct {
+	self    string
+	members []string
+	// version identifies the members of the cluster, all instances that see the same members have the same version.
+	version string
+	tokens  []uint64
+	owners  map[uint64]string
+}
+
+// newShardRing creates a ring for the members of the cluster. If this instance is not a member of the cluster, for
+// example because it has not joined the cluster yet, the ring assigns all rules to it.
+func newShardRing(self string, members []string) *shardRing {
+	members = slices.Clone(members)
+	slices.Sort(members)
+	members = slices.Compact(members)
+
+	r := &shardRing{
+		self:    self,
+		members: members,
+		version: strconv.FormatUint(shardHash(strings.Join(members, ",")), 16),
+		owners:  make(map[uint64]string, len(members)*shardRingReplicas),
+	}
+	if self == "" || !slices.Contains(members, self) {
+		return r
+	}
+	for _, member := range members {
+		for i := 0; i < shardRingReplicas; i++ {
+			token := shardHash(member + "/" + strconv.Itoa(i))
+			// On collision, the member that sorts first keeps the token so that all instances build the same ring.
+			if _, ok := r.owners[token]; ok {
+				continue
+			}
+			r.owners[token] = member
+			r.tokens = append(r.tokens, token)
+		}
+	}
+	slices.Sort(r.tokens)
+	return r
+}
+
+// owner returns the member of the cluster the rule is assigned to.
+func (r *shardRing) owner(key ngmodels.AlertRuleKey) string {
+	if len(r.tokens) == 0 {
+		return r.self
+	}
+	h := shardHash(strconv.FormatInt(key.OrgID, 10) + "/" + key.UID)
+	i := sort.Search(len(r.tokens), func(i int) bool { return r.tokens[i] >= h })
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.owners[r.tokens[i]]
+}
+
+// owns returns true if the rule is assigned to this instance.
+func (r *shardRing) owns(key ngmodels.AlertRuleKey) bool {
+	return r.owner(key) == r.self
+}
+
+// equal returns true if both rings assign the rules to the same members.
+func (r *shardRing) equal(other *shardRing) bool {
+	if other == nil {
+		return false
+	}
+	return r.self == other.self && len(r.tokens) == len(other.tokens) && slices.Equal(r.members, other.members)
+}
+
+func shardHash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// currentShardRing returns the ring for the current members of the cluster, or nil if the rules are not sharded.
+func (sch *schedule) currentShardRing() *shardRing {
+	if sch.clusterMembership == nil {
+		return nil
+	}
+	ring := newShardRing(sch.clusterMembership.Self(), sch.clusterMembership.Members())
+	if !ring.equal(sch.shardRing) {
+		if len(ring.tokens) == 0 {
+			sch.log.Warn("This instance is not a member of the cluster yet, evaluating all rules", "self", ring.self, "members", ring.members)
+		} else {
+			sch.log.Info("Cluster membership changed, rebalancing rules", "self", ring.self, "members", ring.members)
+		}
+		sch.shardRing = ring
+	}
+	return ring
+}
+
+// previousOwners returns the ring to look up the owners of the rules in before the ring changed from previous to ring.
+// If this instance was not a member of the cluster before, the rules were owned by the other members.
+func previousOwners(previous, ring *shardRing) *shardRing {
+	if len(previous.tokens) > 0 || len(ring.tokens) == 0 {
+		return previous
+	}
+	others := slices.DeleteFunc(slices.Clone(ring.members), func(member string) bool { return member == ring.self })
+	if len(others) == 0 {
+		return previous
+	}
+	return newShardRing(others[0], others)
+}
+
+// takeOver restores the states of a rule that is assigned to this instance, and returns false if the rule must not be
+// evaluated yet. If the rule was evaluated by another member of the cluster that is still alive, the states are
+// restored only after that member has handed them off, so that the rule is neither evaluated by both members nor
+// restored from outdated states. If the handoff does not happen within maxHandoffWait, the last saved states are restored.
+func (sch *schedule) takeOver(ctx context.Context, rule *ngmodels.AlertRule, previous, ring *shardRing) bool {
+	key := rule.GetKey()
+	since, waiting := sch.pendingHandoffs[key]
+	if !waiting {
+		previousOwner := previous.owner(key)
+		if previousOwner == ring.self || !slices.Contains(ring.members, previousOwner) {
+			// The rule is new, or was evaluated by this instance or by a member that left the cluster, so the saved
+			// states are the latest ones.
+			if !sch.registry.exists(key) {
+				sch.stateManager.WarmRule(ctx, rule)
+			}
+			return true
+		}
+		// If this instance evaluated the rule while it was not a member of the cluster, the states of the previous
+		// owner take precedence.
+		if ruleRoutine, ok := sch.registry.del(key); ok {
+			ruleRoutine.Stop(errRuleHandedOff)
+		}
+		since = sch.clock.Now()
+		sch.pendingHandoffs[key] = since
+	}
+
+	if !sch.stateManager.HandedOff(ctx, key, ring.version) {
+		if sch.clock.Now().Sub(since) < maxHandoffWait {
+			return false
+		}
+		sch.log.FromContext(ctx).Warn("The previous owner of the rule did not hand off its states in time, restoring the last saved states", append(key.LogContext(), "waited", maxHandoffWait)...)
+	}
+	delete(sch.pendingHandoffs, key)
+	sch.stateManager.WarmRule(ctx, rule)
+	return true
+}
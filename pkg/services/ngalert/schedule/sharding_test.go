@@ -0,0 +1,241 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+)
+
+type fakeClusterMembership struct {
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembership) Self() string      { return f.self }
+func (f *fakeClusterMembership) Members() []string { return f.members }
+
+func TestShardRing(t *testing.T) {
+	keys := make([]models.AlertRuleKey, 0, 1000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, models.GenerateRuleKey(1))
+	}
+	members := []string{"grafana-0", "grafana-1", "grafana-2"}
+
+	t.Run("assigns every rule to exactly one member", func(t *testing.T) {
+		rings := make([]*shardRing, 0, len(members))
+		for _, member := range members {
+			rings = append(rings, newShardRing(member, members))
+		}
+		counts := make(map[string]int)
+		for _, key := range keys {
+			owners := 0
+			for _, ring := range rings {
+				if ring.owns(key) {
+					owners++
+				}
+			}
+			require.Equal(t, 1, owners)
+			counts[rings[0].owner(key)]++
+		}
+		for _, member := range members {
+			require.Greater(t, counts[member], len(keys)/len(members)/2, "rules should be spread across members")
+		}
+	})
+
+	t.Run("does not depend on the order of members", func(t *testing.T) {
+		a := newShardRing("grafana-0", members)
+		b := newShardRing("grafana-0", []string{"grafana-2", "grafana-0", "grafana-1", "grafana-1"})
+		require.True(t, a.equal(b))
+		for _, key := range keys {
+			require.Equal(t, a.owner(key), b.owner(key))
+		}
+	})
+
+	t.Run("moves only rules of the member that joins", func(t *testing.T) {
+		before := newShardRing("grafana-0", members)
+		after := newShardRing("grafana-0", append([]string{"grafana-3"}, members...))
+		require.False(t, before.equal(after))
+		moved := 0
+		for _, key := range keys {
+			if before.owner(key) != after.owner(key) {
+				require.Equal(t, "grafana-3", after.owner(key))
+				moved++
+			}
+		}
+		require.NotZero(t, moved)
+	})
+
+	t.Run("assigns all rules to the instance if it is not a member", func(t *testing.T) {
+		for _, ring := range []*shardRing{newShardRing("grafana-3", members), newShardRing("", members), newShardRing("grafana-0", nil)} {
+			for _, key := range keys {
+				require.True(t, ring.owns(key))
+			}
+		}
+	})
+}
+
+func TestProcessTickSharding(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	membership := &fakeClusterMembership{self: "grafana-0", members: []string{"grafana-0"}}
+	sch.clusterMembership = membership
+
+	gen := models.RuleGen
+	rules := gen.With(gen.WithOrgID(1), gen.WithInterval(time.Second)).GenerateManyRef(20)
+	for _, rule := range rules {
+		ruleStore.PutRule(ctx, rule)
+	}
+	tick := time.Time{}
+
+	t.Run("evaluates all rules if it is the only member", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, len(rules))
+	})
+
+	membership.members = []string{"grafana-0", "grafana-1"}
+	ring := newShardRing("grafana-0", membership.members)
+
+	t.Run("hands off rules assigned to another member", func(t *testing.T) {
+		ruleFactory := ruleFactoryFromScheduler(sch)
+		routines := make(map[models.AlertRuleKey]Rule, len(rules))
+		for _, rule := range rules {
+			routine, _ := sch.registry.getOrCreate(ctx, rule, ruleFactory)
+			routines[rule.GetKey()] = routine
+		}
+
+		tick = tick.Add(time.Second)
+		scheduled, stopped, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Empty(t, stopped, "rules that are handed off should not be deleted")
+
+		owned := 0
+		for _, rule := range rules {
+			key := rule.GetKey()
+			if ring.owns(key) {
+				owned++
+				require.True(t, sch.registry.exists(key))
+				continue
+			}
+			require.False(t, sch.registry.exists(key))
+			require.ErrorIs(t, routines[key].(*alertRule).ctx.Err(), errRuleHandedOff)
+		}
+		require.NotZero(t, owned)
+		require.Less(t, owned, len(rules))
+		require.Len(t, scheduled, owned)
+
+		all, _ := sch.schedulableAlertRules.all()
+		require.Len(t, all, len(rules), "rules that are handed off should stay schedulable")
+	})
+
+	membership.members = []string{"grafana-0"}
+
+	t.Run("takes over rules of the member that leaves", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, len(rules))
+		for _, rule := range rules {
+			require.True(t, sch.registry.exists(rule.GetKey()))
+		}
+	})
+}
+
+func TestProcessTickShardingHandoff(t *testing.T) {
+	ctx := context.Background()
+	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+
+	ruleStore := newFakeRulesStore()
+	sch := setupScheduler(t, ruleStore, nil, nil, nil, nil)
+	handoffs := fakes.NewFakeKVStore(t)
+	newManager := func() *state.Manager {
+		return state.NewManager(state.ManagerCfg{
+			Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			InstanceStore: &state.FakeInstanceStore{},
+			HandoffStore:  handoffs,
+			Images:        &state.NoopImageService{},
+			Clock:         sch.clock,
+			Historian:     &state.FakeHistorian{},
+			Tracer:        tracing.InitializeTracerForTest(),
+			Log:           log.New("ngalert.state.manager"),
+		}, state.NewNoopPersister())
+	}
+	sch.stateManager = newManager()
+	previousOwner := newManager()
+	// This instance evaluates all rules until it joins the cluster.
+	membership := &fakeClusterMembership{self: "grafana-1", members: []string{"grafana-0"}}
+	sch.clusterMembership = membership
+
+	gen := models.RuleGen
+	rules := gen.With(gen.WithOrgID(1), gen.WithInterval(time.Second)).GenerateManyRef(20)
+	for _, rule := range rules {
+		ruleStore.PutRule(ctx, rule)
+	}
+	tick := time.Time{}
+	scheduledKeys := func(scheduled []readyToRunItem) map[models.AlertRuleKey]struct{} {
+		keys := make(map[models.AlertRuleKey]struct{}, len(scheduled))
+		for _, item := range scheduled {
+			keys[item.rule.GetKey()] = struct{}{}
+		}
+		return keys
+	}
+
+	tick = tick.Add(time.Second)
+	scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+	require.Len(t, scheduled, len(rules))
+
+	membership.members = []string{"grafana-0", "grafana-1"}
+	ring := newShardRing("grafana-1", membership.members)
+	var owned []*models.AlertRule
+	for _, rule := range rules {
+		if ring.owns(rule.GetKey()) {
+			owned = append(owned, rule)
+		}
+	}
+	require.NotEmpty(t, owned)
+	require.Less(t, len(owned), len(rules))
+
+	t.Run("waits for the previous owner to hand off the rules it takes over", func(t *testing.T) {
+		tick = tick.Add(time.Second)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Empty(t, scheduled)
+		for _, rule := range rules {
+			require.False(t, sch.registry.exists(rule.GetKey()))
+		}
+	})
+
+	t.Run("evaluates the rules once they are handed off", func(t *testing.T) {
+		handedOff := owned[0].GetKey()
+		previousOwner.HandOffStateByRuleUID(ctx, handedOff, ring.version)
+
+		tick = tick.Add(time.Second)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Equal(t, map[models.AlertRuleKey]struct{}{handedOff: {}}, scheduledKeys(scheduled))
+		require.True(t, sch.registry.exists(handedOff))
+	})
+
+	t.Run("evaluates the rules if the previous owner does not hand them off in time", func(t *testing.T) {
+		sch.clock.(*clock.Mock).Add(maxHandoffWait)
+
+		tick = tick.Add(time.Second)
+		scheduled, _, _ := sch.processTick(ctx, dispatcherGroup, tick)
+		require.Len(t, scheduled, len(owned))
+		for _, rule := range owned {
+			require.True(t, sch.registry.exists(rule.GetKey()))
+		}
+		require.Empty(t, sch.pendingHandoffs)
+	})
+}
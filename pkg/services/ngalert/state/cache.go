@@ -268,6 +268,16 @@ func (c *cache) setAllStates(newStates map[int64]map[string]*ruleStates) {
 	c.states = newStates
 }
 
+// setRuleStates replaces the states of the rule.
+func (c *cache) setRuleStates(ruleKey ngModels.AlertRuleKey, states *ruleStates) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	if _, ok := c.states[ruleKey.OrgID]; !ok {
+		c.states[ruleKey.OrgID] = make(map[string]*ruleStates)
+	}
+	c.states[ruleKey.OrgID][ruleKey.UID] = states
+}
+
 func (c *cache) set(entry *State) {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
@@ -364,26 +374,35 @@ func (c *cache) asInstances(skipNormalState bool) []ngModels.AlertInstance {
 				if skipNormalState && IsNormalStateWithNoReason(v2) {
 					continue
 				}
-				key, err := v2.GetAlertInstanceKey()
+				instance, err := instanceFromState(v2)
 				if err != nil {
 					continue
 				}
-				states = append(states, ngModels.AlertInstance{
-					AlertInstanceKey:  key,
-					Labels:            ngModels.InstanceLabels(v2.Labels),
-					CurrentState:      ngModels.InstanceStateType(v2.State.String()),
-					CurrentReason:     v2.StateReason,
-					LastEvalTime:      v2.LastEvaluationTime,
-					CurrentStateSince: v2.StartsAt,
-					CurrentStateEnd:   v2.EndsAt,
-					ResultFingerprint: v2.ResultFingerprint.String(),
-				})
+				states = append(states, instance)
 			}
 		}
 	}
 	return states
 }
 
+// instanceFromState returns the AlertInstance that is saved to the database for the state.
+func instanceFromState(s *State) (ngModels.AlertInstance, error) {
+	key, err := s.GetAlertInstanceKey()
+	if err != nil {
+		return ngModels.AlertInstance{}, err
+	}
+	return ngModels.AlertInstance{
+		AlertInstanceKey:  key,
+		Labels:            ngModels.InstanceLabels(s.Labels),
+		CurrentState:      ngModels.InstanceStateType(s.State.String()),
+		CurrentReason:     s.StateReason,
+		LastEvalTime:      s.LastEvaluationTime,
+		CurrentStateSince: s.StartsAt,
+		CurrentStateEnd:   s.EndsAt,
+		ResultFingerprint: s.ResultFingerprint.String(),
+	}, nil
+}
+
 // if duplicate labels exist, keep the value from the first set
 func mergeLabels(a, b data.Labels) data.Labels {
 	newLbs := make(data.Labels, len(a)+len(b))
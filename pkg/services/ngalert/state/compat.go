@@ -175,3 +175,35 @@ func FromAlertsStateToStoppedAlert(firingStates []StateTransition, appURL *url.U
 	}
 	return alerts
 }
+
+// AlertInstanceStateFromInstance converts an alert instance to the model used to export the state of alert instances.
+func AlertInstanceStateFromInstance(instance ngModels.AlertInstance) apimodels.AlertInstanceState {
+	return apimodels.AlertInstanceState{
+		RuleUID:           instance.RuleUID,
+		Labels:            instance.Labels,
+		State:             string(instance.CurrentState),
+		Reason:            instance.CurrentReason,
+		StateSince:        instance.CurrentStateSince,
+		StateEnd:          instance.CurrentStateEnd,
+		LastEvaluation:    instance.LastEvalTime,
+		ResultFingerprint: instance.ResultFingerprint,
+	}
+}
+
+// InstanceFromAlertInstanceState converts the exported state of an alert instance to an alert instance of the
+// organization. The labels hash of the instance is not set.
+func InstanceFromAlertInstanceState(orgID int64, s apimodels.AlertInstanceState) ngModels.AlertInstance {
+	return ngModels.AlertInstance{
+		AlertInstanceKey: ngModels.AlertInstanceKey{
+			RuleOrgID: orgID,
+			RuleUID:   s.RuleUID,
+		},
+		Labels:            s.Labels,
+		CurrentState:      ngModels.InstanceStateType(s.State),
+		CurrentReason:     s.Reason,
+		CurrentStateSince: s.StateSince,
+		CurrentStateEnd:   s.StateEnd,
+		LastEvalTime:      s.LastEvaluation,
+		ResultFingerprint: s.ResultFingerprint,
+	}
+}
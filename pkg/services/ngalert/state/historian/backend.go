@@ -18,6 +18,8 @@ const (
 	BackendTypeLoki        BackendType = "loki"
 	BackendTypeMultiple    BackendType = "multiple"
 	BackendTypeNoop        BackendType = "noop"
+	BackendTypePrometheus  BackendType = "prometheus"
+	BackendTypeSQL         BackendType = "sql"
 )
 
 func ParseBackendType(s string) (BackendType, error) {
@@ -28,6 +30,8 @@ func ParseBackendType(s string) (BackendType, error) {
 		BackendTypeLoki:        {},
 		BackendTypeMultiple:    {},
 		BackendTypeNoop:        {},
+		BackendTypePrometheus:  {},
+		BackendTypeSQL:         {},
 	}
 	p := BackendType(norm)
 	if _, ok := types[p]; !ok {
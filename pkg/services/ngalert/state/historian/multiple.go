@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
@@ -14,6 +16,11 @@ type Backend interface {
 	Query(ctx context.Context, query ngmodels.HistoryQuery) (*data.Frame, error)
 }
 
+// Runner is implemented by the backends that run background work, such as deleting old state history.
+type Runner interface {
+	Run(ctx context.Context) error
+}
+
 // MultipleBackend is a state.Historian that records history to multiple backends at once.
 // Only one backend is used for reads. The backend selected for read traffic is called the primary and all others are called secondaries.
 type MultipleBackend struct {
@@ -53,6 +60,19 @@ func (h *MultipleBackend) Query(ctx context.Context, query ngmodels.HistoryQuery
 	return h.primary.Query(ctx, query)
 }
 
+// Run runs the background work of all backends until the context is cancelled.
+func (h *MultipleBackend) Run(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	for _, b := range append([]Backend{h.primary}, h.secondaries...) {
+		if r, ok := b.(Runner); ok {
+			g.Go(func() error {
+				return r.Run(ctx)
+			})
+		}
+	}
+	return g.Wait()
+}
+
 // TODO: This is vendored verbatim from the Go standard library.
 // TODO: The grafana project doesn't support go 1.20 yet, so we can't use errors.Join() directly.
 // TODO: Remove this and replace calls with "errors.Join(...)" when go 1.20 becomes the minimum supported version.
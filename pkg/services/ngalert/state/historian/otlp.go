@@ -0,0 +1,137 @@
+package historian
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+)
+
+const (
+	otlpScopeName = "grafana/alerting/state-history"
+	// otlpLabelPrefix is the prefix of the attributes with the labels of the alert instance.
+	otlpLabelPrefix = "labels."
+)
+
+// OTLPLogsClient writes logs to an OTLP/HTTP logs endpoint.
+type OTLPLogsClient struct {
+	url    *url.URL
+	client client.Requester
+	log    log.Logger
+}
+
+func NewOTLPLogsClient(u *url.URL, req client.Requester, logger log.Logger) *OTLPLogsClient {
+	return &OTLPLogsClient{
+		url:    u,
+		client: req,
+		log:    logger,
+	}
+}
+
+// Push writes the logs to the endpoint, encoded as protobuf.
+func (c *OTLPLogsClient) Push(ctx context.Context, logs plog.Logs) error {
+	body, err := plogotlp.NewExportRequestFromLogs(logs).MarshalProto()
+	if err != nil {
+		return fmt.Errorf("failed to marshal logs: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.log.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("received a non-200 response from OTLP endpoint, status: %d, body: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// TransitionsToLogs converts the state transitions of a rule to OTLP logs. The body of each log record is the same
+// JSON entry as the one written to Loki, and the attributes are the labels of the Loki stream and of the alert instance.
+func TransitionsToLogs(rule history_model.RuleMeta, transitions []state.StateTransition, externalLabels map[string]string) plog.Logs {
+	logs := plog.NewLogs()
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	resourceLogs.Resource().Attributes().PutStr("service.name", "grafana")
+	scopeLogs := resourceLogs.ScopeLogs().AppendEmpty()
+	scopeLogs.Scope().SetName(otlpScopeName)
+
+	for _, t := range transitions {
+		sanitizedLabels := removePrivateLabels(t.Labels)
+		entry := LokiEntry{
+			SchemaVersion:  1,
+			Previous:       t.PreviousFormatted(),
+			Current:        t.Formatted(),
+			Values:         valuesAsDataBlob(t.State),
+			Condition:      rule.Condition,
+			DashboardUID:   rule.DashboardUID,
+			PanelID:        rule.PanelID,
+			Fingerprint:    labelFingerprint(sanitizedLabels),
+			RuleTitle:      rule.Title,
+			RuleID:         rule.ID,
+			RuleUID:        rule.UID,
+			InstanceLabels: sanitizedLabels,
+		}
+		if t.State.State == eval.Error {
+			entry.Error = t.Error.Error()
+		}
+		// The entry only holds strings and JSON values, so it can always be serialized.
+		line, _ := json.Marshal(entry)
+
+		record := scopeLogs.LogRecords().AppendEmpty()
+		record.SetTimestamp(pcommon.NewTimestampFromTime(t.State.LastEvaluationTime))
+		record.SetSeverityNumber(severity(t.State.State))
+		record.SetSeverityText(t.Formatted())
+		record.Body().SetStr(string(line))
+
+		attrs := record.Attributes()
+		for k, v := range externalLabels {
+			attrs.PutStr(k, v)
+		}
+		attrs.PutStr(StateHistoryLabelKey, StateHistoryLabelValue)
+		attrs.PutStr(OrgIDLabel, fmt.Sprint(rule.OrgID))
+		attrs.PutStr(GroupLabel, rule.Group)
+		attrs.PutStr(FolderUIDLabel, rule.NamespaceUID)
+		attrs.PutStr(RuleUIDLabel, rule.UID)
+		for k, v := range sanitizedLabels {
+			attrs.PutStr(otlpLabelPrefix+k, v)
+		}
+	}
+	return logs
+}
+
+func severity(s eval.State) plog.SeverityNumber {
+	switch s {
+	case eval.Alerting:
+		return plog.SeverityNumberWarn
+	case eval.Error:
+		return plog.SeverityNumberError
+	default:
+		return plog.SeverityNumberInfo
+	}
+}
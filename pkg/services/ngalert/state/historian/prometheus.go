@@ -0,0 +1,245 @@
+package historian
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/value"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/client"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// Names of the labels of the alert state series. They are the same as the ones of the ALERTS series of Prometheus.
+	alertNameLabel  = "alertname"
+	alertStateLabel = "alertstate"
+	// forStateSuffix is the suffix of the name of the series with the time since when the alerts are active.
+	forStateSuffix = "_FOR_STATE"
+)
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	// staleValue marks the series of resolved alerts as stale, as Prometheus does for its own ALERTS series.
+	staleValue = math.Float64frombits(value.StaleNaN)
+)
+
+// PrometheusConfig configures the Prometheus state history backend.
+type PrometheusConfig struct {
+	RemoteWriteURL    *url.URL
+	BasicAuthUser     string
+	BasicAuthPassword string
+	MetricName        string
+	ExternalLabels    map[string]string
+	// OTLPLogsURL is the OTLP/HTTP logs endpoint the state transitions are also written to. Optional.
+	OTLPLogsURL *url.URL
+}
+
+func NewPrometheusConfig(cfg setting.UnifiedAlertingStateHistorySettings) (PrometheusConfig, error) {
+	if cfg.PrometheusRemoteWriteURL == "" {
+		return PrometheusConfig{}, fmt.Errorf("remote write URL must be provided")
+	}
+	writeURL, err := url.Parse(cfg.PrometheusRemoteWriteURL)
+	if err != nil {
+		return PrometheusConfig{}, fmt.Errorf("failed to parse remote write URL: %w", err)
+	}
+	if !metricNameRE.MatchString(cfg.PrometheusMetricName) {
+		return PrometheusConfig{}, fmt.Errorf("invalid metric name: %q", cfg.PrometheusMetricName)
+	}
+
+	var logsURL *url.URL
+	if cfg.OTLPLogsURL != "" {
+		logsURL, err = url.Parse(cfg.OTLPLogsURL)
+		if err != nil {
+			return PrometheusConfig{}, fmt.Errorf("failed to parse OTLP logs URL: %w", err)
+		}
+	}
+
+	return PrometheusConfig{
+		RemoteWriteURL:    writeURL,
+		BasicAuthUser:     cfg.PrometheusBasicAuthUsername,
+		BasicAuthPassword: cfg.PrometheusBasicAuthPassword,
+		MetricName:        cfg.PrometheusMetricName,
+		ExternalLabels:    cfg.ExternalLabels,
+		OTLPLogsURL:       logsURL,
+	}, nil
+}
+
+type pointsWriter interface {
+	WritePoints(ctx context.Context, points []writer.Point) error
+}
+
+// PrometheusBackend is a state.Historian that writes the state of the alerts to a Prometheus remote write endpoint,
+// with the same series as the ALERTS and ALERTS_FOR_STATE series of Prometheus.
+// It can also write the state transitions to an OTLP logs endpoint. It does not support queries.
+type PrometheusBackend struct {
+	writer         pointsWriter
+	logs           *OTLPLogsClient
+	metricName     string
+	externalLabels map[string]string
+	metrics        *metrics.Historian
+	log            log.Logger
+}
+
+func NewPrometheusBackend(logger log.Logger, cfg PrometheusConfig, req client.Requester, metrics *metrics.Historian) (*PrometheusBackend, error) {
+	w, err := writer.NewPrometheusWriter(setting.RecordingRuleSettings{
+		URL:               cfg.RemoteWriteURL.String(),
+		BasicAuthUsername: cfg.BasicAuthUser,
+		BasicAuthPassword: cfg.BasicAuthPassword,
+		Timeout:           StateHistoryWriteTimeout,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var logs *OTLPLogsClient
+	if cfg.OTLPLogsURL != nil {
+		logs = NewOTLPLogsClient(cfg.OTLPLogsURL, req, logger)
+	}
+
+	return &PrometheusBackend{
+		writer:         w,
+		logs:           logs,
+		metricName:     cfg.MetricName,
+		externalLabels: cfg.ExternalLabels,
+		metrics:        metrics,
+		log:            logger,
+	}, nil
+}
+
+// Record writes the state of the alerts of a given rule to the remote write endpoint, and the state transitions to the OTLP logs endpoint.
+func (h *PrometheusBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	points := h.statesToPoints(rule, states)
+	var transitions []state.StateTransition
+	for _, s := range states {
+		if shouldRecord(s) {
+			transitions = append(transitions, s)
+		}
+	}
+
+	errCh := make(chan error, 1)
+	if len(points) == 0 && (h.logs == nil || len(transitions) == 0) {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// Same as for Loki, we don't want grafana shutdowns or the lifetime of the evaluation to interrupt the writes.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "prometheus").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(transitions)))
+
+		var errs []error
+		if err := h.writer.WritePoints(ctx, points); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write alert state series: %w", err))
+		}
+		if h.logs != nil && len(transitions) > 0 {
+			if err := h.logs.Push(ctx, TransitionsToLogs(rule, transitions, h.externalLabels)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to write alert state history logs: %w", err))
+			}
+		}
+
+		if err := errors.Join(errs...); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "prometheus").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(transitions)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+	}(writeCtx)
+	return errCh
+}
+
+// Query is not supported by the Prometheus backend. The state of the alerts is queried from the remote write target instead.
+func (h *PrometheusBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	return nil, fmt.Errorf("the prometheus state history backend does not support queries")
+}
+
+// statesToPoints returns the points of the active alerts and the stale markers of the resolved alerts.
+// Same as for Prometheus, the series of the active alerts are written at every evaluation, not only on transitions,
+// so that they can be queried at any time.
+func (h *PrometheusBackend) statesToPoints(rule history_model.RuleMeta, states []state.StateTransition) []writer.Point {
+	forStateName := h.metricName + forStateSuffix
+	points := make([]writer.Point, 0, len(states))
+	for _, st := range states {
+		current, previous := alertState(st.State.State), alertState(st.PreviousState)
+		if current == "" && previous == "" {
+			continue
+		}
+
+		labels := mergeLabels(make(map[string]string), h.externalLabels)
+		for k, v := range removePrivateLabels(st.Labels) {
+			labels[k] = v
+		}
+		if _, ok := labels[alertNameLabel]; !ok {
+			labels[alertNameLabel] = rule.Title
+		}
+		t := st.State.LastEvaluationTime.Unix()
+
+		if previous != "" && previous != current {
+			points = append(points, alertPoint(h.metricName, labels, previous, t, staleValue))
+		}
+		if current == "" {
+			points = append(points, alertPoint(forStateName, labels, "", t, staleValue))
+			continue
+		}
+		points = append(points,
+			alertPoint(h.metricName, labels, current, t, 1),
+			alertPoint(forStateName, labels, "", t, float64(st.State.StartsAt.Unix())),
+		)
+	}
+	return points
+}
+
+// alertPoint returns the point of an alert state series. The alertstate label is only added if the state is set.
+func alertPoint(name string, labels map[string]string, alertstate string, t int64, v float64) writer.Point {
+	lbls := make(map[string]string, len(labels)+1)
+	for k, lv := range labels {
+		lbls[k] = lv
+	}
+	if alertstate != "" {
+		lbls[alertStateLabel] = alertstate
+	}
+	return writer.Point{
+		Name:   name,
+		Labels: lbls,
+		Metric: writer.Metric{T: t, V: v},
+	}
+}
+
+// alertState returns the value of the alertstate label of the alerts in the given state, or an empty string if
+// the alerts are not active. Alerts in the NoData and Error states are firing, as they are sent to the Alertmanager as such.
+func alertState(s eval.State) string {
+	switch s {
+	case eval.Pending:
+		return "pending"
+	case eval.Alerting, eval.NoData, eval.Error:
+		return "firing"
+	default:
+		return ""
+	}
+}
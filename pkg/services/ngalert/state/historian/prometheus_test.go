@@ -0,0 +1,193 @@
+package historian
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNewPrometheusConfig(t *testing.T) {
+	t.Run("requires a remote write URL", func(t *testing.T) {
+		_, err := NewPrometheusConfig(setting.UnifiedAlertingStateHistorySettings{PrometheusMetricName: "ALERTS"})
+		require.ErrorContains(t, err, "remote write URL must be provided")
+	})
+
+	t.Run("requires a valid metric name", func(t *testing.T) {
+		_, err := NewPrometheusConfig(setting.UnifiedAlertingStateHistorySettings{
+			PrometheusRemoteWriteURL: "http://localhost:9090/api/v1/write",
+			PrometheusMetricName:     "not-valid",
+		})
+		require.ErrorContains(t, err, "invalid metric name")
+	})
+
+	t.Run("OTLP logs are optional", func(t *testing.T) {
+		cfg, err := NewPrometheusConfig(setting.UnifiedAlertingStateHistorySettings{
+			PrometheusRemoteWriteURL: "http://localhost:9090/api/v1/write",
+			PrometheusMetricName:     "ALERTS",
+		})
+		require.NoError(t, err)
+		require.Nil(t, cfg.OTLPLogsURL)
+		require.Equal(t, "localhost:9090", cfg.RemoteWriteURL.Host)
+	})
+}
+
+func TestPrometheusBackendRecord(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	startsAt := now.Add(-5 * time.Minute)
+	rule := createTestRule()
+
+	transition := func(from, to eval.State) state.StateTransition {
+		return state.StateTransition{
+			PreviousState: from,
+			State: &state.State{
+				State:              to,
+				Labels:             data.Labels{"alertname": "my-title", "instance": "a", "__private__": "x"},
+				StartsAt:           startsAt,
+				LastEvaluationTime: now,
+			},
+		}
+	}
+
+	t.Run("writes active alerts at every evaluation", func(t *testing.T) {
+		fw := &fakePointsWriter{}
+		h := createTestPrometheusBackend(fw, nil)
+
+		require.NoError(t, <-h.Record(context.Background(), rule, []state.StateTransition{transition(eval.Alerting, eval.Alerting)}))
+
+		require.Equal(t, []writer.Point{
+			{
+				Name:   "ALERTS",
+				Labels: map[string]string{"alertname": "my-title", "alertstate": "firing", "instance": "a", "externalLabelKey": "externalLabelValue"},
+				Metric: writer.Metric{T: now.Unix(), V: 1},
+			},
+			{
+				Name:   "ALERTS_FOR_STATE",
+				Labels: map[string]string{"alertname": "my-title", "instance": "a", "externalLabelKey": "externalLabelValue"},
+				Metric: writer.Metric{T: now.Unix(), V: float64(startsAt.Unix())},
+			},
+		}, fw.points)
+	})
+
+	t.Run("marks the previous state as stale", func(t *testing.T) {
+		fw := &fakePointsWriter{}
+		h := createTestPrometheusBackend(fw, nil)
+
+		require.NoError(t, <-h.Record(context.Background(), rule, []state.StateTransition{transition(eval.Pending, eval.Alerting)}))
+
+		require.Len(t, fw.points, 3)
+		require.Equal(t, "pending", fw.points[0].Labels[alertStateLabel])
+		require.True(t, value.IsStaleNaN(fw.points[0].Metric.V))
+		require.Equal(t, "firing", fw.points[1].Labels[alertStateLabel])
+	})
+
+	t.Run("marks resolved alerts as stale", func(t *testing.T) {
+		fw := &fakePointsWriter{}
+		h := createTestPrometheusBackend(fw, nil)
+
+		require.NoError(t, <-h.Record(context.Background(), rule, []state.StateTransition{transition(eval.Alerting, eval.Normal)}))
+
+		require.Len(t, fw.points, 2)
+		for _, p := range fw.points {
+			require.True(t, math.IsNaN(p.Metric.V))
+			require.True(t, value.IsStaleNaN(p.Metric.V))
+		}
+		require.Equal(t, "ALERTS", fw.points[0].Name)
+		require.Equal(t, "ALERTS_FOR_STATE", fw.points[1].Name)
+	})
+
+	t.Run("elides writes of inactive alerts", func(t *testing.T) {
+		fw := &fakePointsWriter{}
+		h := createTestPrometheusBackend(fw, nil)
+
+		require.NoError(t, <-h.Record(context.Background(), rule, []state.StateTransition{transition(eval.Normal, eval.Normal)}))
+
+		require.False(t, fw.called)
+	})
+
+	t.Run("returns write errors", func(t *testing.T) {
+		fw := &fakePointsWriter{err: errors.New("boom")}
+		h := createTestPrometheusBackend(fw, nil)
+
+		err := <-h.Record(context.Background(), rule, []state.StateTransition{transition(eval.Normal, eval.Alerting)})
+
+		require.ErrorContains(t, err, "boom")
+	})
+
+	t.Run("writes transitions as OTLP logs", func(t *testing.T) {
+		var received plogotlp.ExportRequest
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			received = plogotlp.NewExportRequest()
+			require.NoError(t, received.UnmarshalProto(body))
+		}))
+		t.Cleanup(server.Close)
+		u, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		fw := &fakePointsWriter{}
+		h := createTestPrometheusBackend(fw, NewOTLPLogsClient(u, NewRequester(), log.NewNopLogger()))
+
+		require.NoError(t, <-h.Record(context.Background(), rule, []state.StateTransition{
+			transition(eval.Normal, eval.Alerting),
+			// Unchanged states are not logged.
+			transition(eval.Alerting, eval.Alerting),
+		}))
+
+		require.Equal(t, 1, received.Logs().LogRecordCount())
+		record := received.Logs().ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		require.Equal(t, now.UnixNano(), record.Timestamp().AsTime().UnixNano())
+		require.Contains(t, record.Body().Str(), `"current":"Alerting"`)
+		ruleUID, ok := record.Attributes().Get(RuleUIDLabel)
+		require.True(t, ok)
+		require.Equal(t, rule.UID, ruleUID.Str())
+		instance, ok := record.Attributes().Get("labels.instance")
+		require.True(t, ok)
+		require.Equal(t, "a", instance.Str())
+		_, ok = record.Attributes().Get("labels.__private__")
+		require.False(t, ok)
+	})
+}
+
+type fakePointsWriter struct {
+	called bool
+	points []writer.Point
+	err    error
+}
+
+func (w *fakePointsWriter) WritePoints(_ context.Context, points []writer.Point) error {
+	w.called = true
+	w.points = points
+	return w.err
+}
+
+func createTestPrometheusBackend(w pointsWriter, logs *OTLPLogsClient) *PrometheusBackend {
+	return &PrometheusBackend{
+		writer:         w,
+		logs:           logs,
+		metricName:     "ALERTS",
+		externalLabels: map[string]string{"externalLabelKey": "externalLabelValue"},
+		metrics:        metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem),
+		log:            log.NewNopLogger(),
+	}
+}
@@ -0,0 +1,514 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// sqlMaintenanceInterval is how often the SQL backend deletes and downsamples old state history.
+	sqlMaintenanceInterval = time.Hour
+	// sqlDeleteBatchSize is the maximum number of rows deleted by a single statement.
+	sqlDeleteBatchSize = 500
+	// sqlMaintenanceAction is the name of the server lock that makes sure that a single Grafana instance maintains
+	// the state history at a time.
+	sqlMaintenanceAction = "alert state history maintenance"
+)
+
+// ServerLock executes an action on a single Grafana instance. It is satisfied by serverlock.ServerLockService.
+type ServerLock interface {
+	LockAndExecute(ctx context.Context, actionName string, maxInterval time.Duration, fn func(ctx context.Context)) error
+}
+
+// SQLConfig configures the retention of the SQL state history backend.
+type SQLConfig struct {
+	// Retention is how long state history is kept. Zero keeps it forever.
+	Retention time.Duration
+	// DownsampleAfter is the age after which state history is downsampled. Zero disables downsampling.
+	DownsampleAfter time.Duration
+	// DownsampleInterval is the interval in which a single transition is kept per alert instance after downsampling.
+	DownsampleInterval time.Duration
+}
+
+func NewSQLConfig(cfg setting.UnifiedAlertingStateHistorySettings) (SQLConfig, error) {
+	if cfg.SQLRetention < 0 {
+		return SQLConfig{}, fmt.Errorf("retention must not be negative")
+	}
+	if cfg.SQLDownsampleAfter < 0 {
+		return SQLConfig{}, fmt.Errorf("downsample after must not be negative")
+	}
+	if cfg.SQLDownsampleAfter > 0 {
+		if cfg.SQLDownsampleInterval <= 0 {
+			return SQLConfig{}, fmt.Errorf("downsample interval must be positive when downsampling is enabled")
+		}
+		if cfg.SQLRetention > 0 && cfg.SQLDownsampleAfter >= cfg.SQLRetention {
+			return SQLConfig{}, fmt.Errorf("downsample after (%s) must be shorter than the retention (%s)", cfg.SQLDownsampleAfter, cfg.SQLRetention)
+		}
+	}
+	return SQLConfig{
+		Retention:          cfg.SQLRetention,
+		DownsampleAfter:    cfg.SQLDownsampleAfter,
+		DownsampleInterval: cfg.SQLDownsampleInterval,
+	}, nil
+}
+
+// stateHistoryEntry is a single state transition stored in the alert_state_history table.
+type stateHistoryEntry struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	RuleUID       string `xorm:"rule_uid"`
+	RuleID        int64  `xorm:"rule_id"`
+	RuleTitle     string `xorm:"rule_title"`
+	RuleGroup     string `xorm:"rule_group"`
+	NamespaceUID  string `xorm:"namespace_uid"`
+	DashboardUID  string `xorm:"dashboard_uid"`
+	PanelID       int64  `xorm:"panel_id"`
+	Condition     string `xorm:"condition"`
+	Labels        string `xorm:"labels"`
+	LabelsHash    string `xorm:"labels_hash"`
+	PreviousState string `xorm:"previous_state"`
+	CurrentState  string `xorm:"current_state"`
+	Error         string `xorm:"error"`
+	StateValues   string `xorm:"state_values"`
+	// At is the time of the transition in Unix milliseconds.
+	At          int64 `xorm:"at"`
+	Downsampled bool  `xorm:"downsampled"`
+}
+
+func (stateHistoryEntry) TableName() string {
+	return "alert_state_history"
+}
+
+// SQLBackend is a state.Historian that records state history to the Grafana database.
+type SQLBackend struct {
+	db      db.DB
+	cfg     SQLConfig
+	lock    ServerLock
+	clock   clock.Clock
+	metrics *metrics.Historian
+	log     log.Logger
+}
+
+func NewSQLBackend(logger log.Logger, cfg SQLConfig, db db.DB, lock ServerLock, metrics *metrics.Historian) *SQLBackend {
+	return &SQLBackend{
+		db:      db,
+		cfg:     cfg,
+		lock:    lock,
+		clock:   clock.New(),
+		metrics: metrics,
+		log:     logger,
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the database.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	entries := statesToEntries(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it.
+	// Same as for Loki, we don't want grafana shutdowns or the lifetime of the evaluation to interrupt the writes.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "sql").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		if err := h.insert(ctx, entries); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "sql").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch")
+	}(writeCtx)
+	return errCh
+}
+
+func (h *SQLBackend) insert(ctx context.Context, entries []stateHistoryEntry) error {
+	return h.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.BulkInsert(stateHistoryEntry{}, entries, sqlstore.NativeSettingsForDialect(h.db.GetDialect()))
+		return err
+	})
+}
+
+// Query retrieves state history entries from the database and formats the results into a dataframe.
+// The dataframe has the same format as the one of the Loki backend.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	if query.OrgID == 0 {
+		return nil, fmt.Errorf("missing org id in query")
+	}
+
+	now := h.clock.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+
+	var entries []stateHistoryEntry
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(stateHistoryEntry{}).
+			Where("org_id = ?", query.OrgID).
+			And("at >= ? AND at <= ?", query.From.UnixMilli(), query.To.UnixMilli())
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if query.DashboardUID != "" {
+			q = q.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		if query.PanelID != 0 {
+			q = q.And("panel_id = ?", query.PanelID)
+		}
+		// Labels are stored as JSON, so the label filters are applied after the rows are read
+		// and the limit can only be applied by the database when there are none.
+		if query.Limit > 0 && len(query.Labels) == 0 {
+			q = q.Limit(query.Limit)
+		}
+		// Same as for Loki, the most recent entries are returned when the result is limited.
+		return q.Desc("at", "id").Find(&entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state history: %w", err)
+	}
+
+	return entriesToFrame(entries, query.Labels, query.Limit)
+}
+
+// Run deletes state history older than the retention and downsamples state history older than
+// the configured age, until the context is cancelled. When Grafana runs in high availability mode, the
+// state history is maintained by a single instance at a time.
+func (h *SQLBackend) Run(ctx context.Context) error {
+	if h.cfg.Retention <= 0 && h.cfg.DownsampleAfter <= 0 {
+		return nil
+	}
+	ticker := h.clock.Ticker(sqlMaintenanceInterval)
+	defer ticker.Stop()
+	for {
+		// The maximum interval is a bit shorter than the maintenance interval so that the instance that holds the
+		// lock does not skip every other maintenance because its ticks are a few milliseconds early.
+		if err := h.lock.LockAndExecute(ctx, sqlMaintenanceAction, sqlMaintenanceInterval-time.Minute, h.maintain); err != nil {
+			h.log.Error("Failed to lock the maintenance of state history", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *SQLBackend) maintain(ctx context.Context) {
+	now := h.clock.Now()
+	if h.cfg.Retention > 0 {
+		deleted, err := h.deleteExpired(ctx, now.Add(-h.cfg.Retention))
+		if err != nil {
+			h.log.Error("Failed to delete expired state history", "error", err)
+		} else if deleted > 0 {
+			h.log.Debug("Deleted expired state history", "rows", deleted)
+		}
+	}
+	if h.cfg.DownsampleAfter > 0 {
+		deleted, err := h.downsample(ctx, now.Add(-h.cfg.DownsampleAfter))
+		if err != nil {
+			h.log.Error("Failed to downsample state history", "error", err)
+		} else if deleted > 0 {
+			h.log.Debug("Downsampled state history", "deleted", deleted)
+		}
+	}
+}
+
+// deleteExpired deletes all state transitions that happened before the given time, in batches of
+// sqlDeleteBatchSize rows so that the table is not locked for long.
+func (h *SQLBackend) deleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		var ids []int64
+		err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table(stateHistoryEntry{}).
+				Cols("id").
+				Where("at < ?", before.UnixMilli()).
+				Limit(sqlDeleteBatchSize).
+				Find(&ids)
+		})
+		if err != nil {
+			return total, err
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		var deleted int64
+		err = h.db.WithDbSession(ctx, func(sess *db.Session) error {
+			deleted, err = sess.In("id", ids).Delete(&stateHistoryEntry{})
+			return err
+		})
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if len(ids) < sqlDeleteBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// historyInstance identifies the state history of a single alert instance.
+type historyInstance struct {
+	OrgID      int64  `xorm:"org_id"`
+	RuleUID    string `xorm:"rule_uid"`
+	LabelsHash string `xorm:"labels_hash"`
+}
+
+// downsample keeps a single transition per alert instance and downsample interval for all
+// transitions that happened before the given time. The kept transition is the last one of the interval
+// and its previous state is changed to the previous state of the first one, so that the history
+// still goes from the state before the interval to the state after it. It returns the number of deleted transitions.
+func (h *SQLBackend) downsample(ctx context.Context, before time.Time) (int64, error) {
+	// Only whole intervals are downsampled. Otherwise, the transitions of the interval the given time falls in
+	// would be downsampled again once they are all older than the given time, and the interval would keep two.
+	interval := h.cfg.DownsampleInterval.Milliseconds()
+	before = time.UnixMilli(before.UnixMilli() - before.UnixMilli()%interval)
+
+	var instances []historyInstance
+	err := h.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table(stateHistoryEntry{}).
+			Distinct("org_id", "rule_uid", "labels_hash").
+			Where("at < ? AND downsampled = ?", before.UnixMilli(), false).
+			Find(&instances)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	var errs []error
+	for _, instance := range instances {
+		if ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+		deleted, err := h.downsampleInstance(ctx, instance, before)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to downsample state history of rule %s: %w", instance.RuleUID, err))
+			continue
+		}
+		total += deleted
+	}
+	return total, errors.Join(errs...)
+}
+
+func (h *SQLBackend) downsampleInstance(ctx context.Context, instance historyInstance, before time.Time) (int64, error) {
+	var deleted int64
+	err := h.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var entries []stateHistoryEntry
+		err := sess.Table(stateHistoryEntry{}).
+			Where("org_id = ? AND rule_uid = ? AND labels_hash = ?", instance.OrgID, instance.RuleUID, instance.LabelsHash).
+			And("at < ? AND downsampled = ?", before.UnixMilli(), false).
+			Asc("at", "id").
+			Find(&entries)
+		if err != nil {
+			return err
+		}
+
+		interval := h.cfg.DownsampleInterval.Milliseconds()
+		var obsolete []int64
+		for start := 0; start < len(entries); {
+			bucket := entries[start].At - entries[start].At%interval
+			end := start + 1
+			for end < len(entries) && entries[end].At-entries[end].At%interval == bucket {
+				end++
+			}
+
+			kept := entries[end-1]
+			kept.PreviousState = entries[start].PreviousState
+			kept.Downsampled = true
+			if _, err := sess.ID(kept.ID).Cols("previous_state", "downsampled").Update(&kept); err != nil {
+				return err
+			}
+			for _, e := range entries[start : end-1] {
+				obsolete = append(obsolete, e.ID)
+			}
+			start = end
+		}
+
+		for start := 0; start < len(obsolete); start += sqlDeleteBatchSize {
+			end := min(start+sqlDeleteBatchSize, len(obsolete))
+			n, err := sess.In("id", obsolete[start:end]).Delete(&stateHistoryEntry{})
+			if err != nil {
+				return err
+			}
+			deleted += n
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+func statesToEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []stateHistoryEntry {
+	entries := make([]stateHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		labels, err := json.Marshal(sanitizedLabels)
+		if err != nil {
+			logger.Error("Failed to serialize labels of state, skipping", "error", err)
+			continue
+		}
+		var values []byte
+		if blob := valuesAsDataBlob(state.State); blob != nil {
+			values, err = blob.Encode()
+			if err != nil {
+				logger.Error("Failed to serialize values of state, skipping", "error", err)
+				continue
+			}
+		}
+
+		entry := stateHistoryEntry{
+			OrgID:         rule.OrgID,
+			RuleUID:       rule.UID,
+			RuleID:        rule.ID,
+			RuleTitle:     rule.Title,
+			RuleGroup:     rule.Group,
+			NamespaceUID:  rule.NamespaceUID,
+			DashboardUID:  rule.DashboardUID,
+			PanelID:       rule.PanelID,
+			Condition:     rule.Condition,
+			Labels:        string(labels),
+			LabelsHash:    labelFingerprint(sanitizedLabels),
+			PreviousState: state.PreviousFormatted(),
+			CurrentState:  state.Formatted(),
+			StateValues:   string(values),
+			At:            state.State.LastEvaluationTime.UnixMilli(),
+		}
+		if state.State.State == eval.Error {
+			entry.Error = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// entriesToFrame converts the entries, which are sorted from the newest to the oldest, into the
+// state history dataframe. Only the entries that have all the given labels are added, up to the limit.
+func entriesToFrame(entries []stateHistoryEntry, matchers map[string]string, limit int) (*data.Frame, error) {
+	lokiEntries := make([]LokiEntry, 0, len(entries))
+	streams := make([]map[string]string, 0, len(entries))
+	ats := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		if limit > 0 && len(lokiEntries) >= limit {
+			break
+		}
+
+		var instanceLabels map[string]string
+		if err := json.Unmarshal([]byte(e.Labels), &instanceLabels); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal labels of state history entry %d: %w", e.ID, err)
+		}
+		if !hasLabels(instanceLabels, matchers) {
+			continue
+		}
+
+		values := simplejson.New()
+		if e.StateValues != "" {
+			v, err := simplejson.NewJson([]byte(e.StateValues))
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal values of state history entry %d: %w", e.ID, err)
+			}
+			values = v
+		}
+
+		lokiEntries = append(lokiEntries, LokiEntry{
+			SchemaVersion:  1,
+			Previous:       e.PreviousState,
+			Current:        e.CurrentState,
+			Error:          e.Error,
+			Values:         values,
+			Condition:      e.Condition,
+			DashboardUID:   e.DashboardUID,
+			PanelID:        e.PanelID,
+			Fingerprint:    e.LabelsHash,
+			RuleTitle:      e.RuleTitle,
+			RuleID:         e.RuleID,
+			RuleUID:        e.RuleUID,
+			InstanceLabels: instanceLabels,
+		})
+		streams = append(streams, map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.NamespaceUID,
+		})
+		ats = append(ats, e.At)
+	}
+
+	// The entries are sorted from the newest to the oldest but the frame is sorted from the oldest to the newest.
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+	times := make([]time.Time, len(lokiEntries))
+	lines := make([]json.RawMessage, len(lokiEntries))
+	labels := make([]json.RawMessage, len(lokiEntries))
+	for i := range lokiEntries {
+		j := len(lokiEntries) - 1 - i
+		line, err := json.Marshal(lokiEntries[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history entry: %w", err)
+		}
+		lblsJson, err := json.Marshal(streams[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize stream labels: %w", err)
+		}
+		times[j] = time.UnixMilli(ats[i])
+		lines[j] = line
+		labels[j] = lblsJson
+	}
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
+
+func hasLabels(labels map[string]string, matchers map[string]string) bool {
+	for k, v := range matchers {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,329 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestNewSQLConfig(t *testing.T) {
+	cases := []struct {
+		name   string
+		in     setting.UnifiedAlertingStateHistorySettings
+		expErr string
+	}{
+		{
+			name: "keeps history forever without downsampling",
+			in:   setting.UnifiedAlertingStateHistorySettings{},
+		},
+		{
+			name: "downsampling before the retention",
+			in: setting.UnifiedAlertingStateHistorySettings{
+				SQLRetention:          30 * 24 * time.Hour,
+				SQLDownsampleAfter:    7 * 24 * time.Hour,
+				SQLDownsampleInterval: time.Hour,
+			},
+		},
+		{
+			name:   "negative retention",
+			in:     setting.UnifiedAlertingStateHistorySettings{SQLRetention: -time.Hour},
+			expErr: "retention must not be negative",
+		},
+		{
+			name: "downsampling without interval",
+			in: setting.UnifiedAlertingStateHistorySettings{
+				SQLDownsampleAfter: time.Hour,
+			},
+			expErr: "downsample interval must be positive",
+		},
+		{
+			name: "downsampling after the retention",
+			in: setting.UnifiedAlertingStateHistorySettings{
+				SQLRetention:          time.Hour,
+				SQLDownsampleAfter:    2 * time.Hour,
+				SQLDownsampleInterval: time.Minute,
+			},
+			expErr: "must be shorter than the retention",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg, err := NewSQLConfig(tc.in)
+			if tc.expErr != "" {
+				require.ErrorContains(t, err, tc.expErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.in.SQLRetention, cfg.Retention)
+			require.Equal(t, tc.in.SQLDownsampleAfter, cfg.DownsampleAfter)
+		})
+	}
+}
+
+func TestIntegrationSQLBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("records and queries state transitions", func(t *testing.T) {
+		h := createTestSQLBackend(t, SQLConfig{}, now)
+		rule := createTestRule()
+		states := []state.StateTransition{
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "b", "__private__": "x"}, now.Add(-2*time.Minute)),
+			transitionAt(eval.Normal, eval.Pending, data.Labels{"a": "c"}, now.Add(-time.Minute)),
+			// Normal to normal is not recorded.
+			transitionAt(eval.Normal, eval.Normal, data.Labels{"a": "d"}, now.Add(-time.Minute)),
+		}
+
+		require.NoError(t, <-h.Record(context.Background(), rule, states))
+
+		frame, err := h.Query(context.Background(), models.HistoryQuery{OrgID: 1, RuleUID: rule.UID})
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+
+		first := requireFrameEntry(t, frame, 0)
+		require.Equal(t, now.Add(-2*time.Minute), frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, "Normal", first.Previous)
+		require.Equal(t, "Alerting", first.Current)
+		require.Equal(t, rule.UID, first.RuleUID)
+		require.Equal(t, rule.Title, first.RuleTitle)
+		require.Equal(t, rule.DashboardUID, first.DashboardUID)
+		require.Equal(t, map[string]string{"a": "b"}, first.InstanceLabels)
+		require.Equal(t, labelFingerprint(data.Labels{"a": "b"}), first.Fingerprint)
+
+		var streamLabels map[string]string
+		require.NoError(t, json.Unmarshal(frame.Fields[2].At(0).(json.RawMessage), &streamLabels))
+		require.Equal(t, map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           "1",
+			GroupLabel:           rule.Group,
+			FolderUIDLabel:       rule.NamespaceUID,
+		}, streamLabels)
+
+		require.Equal(t, "Pending", requireFrameEntry(t, frame, 1).Current)
+	})
+
+	t.Run("filters by labels and org", func(t *testing.T) {
+		h := createTestSQLBackend(t, SQLConfig{}, now)
+		rule := createTestRule()
+		states := []state.StateTransition{
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "b"}, now.Add(-2*time.Minute)),
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "c"}, now.Add(-time.Minute)),
+		}
+		require.NoError(t, <-h.Record(context.Background(), rule, states))
+
+		frame, err := h.Query(context.Background(), models.HistoryQuery{OrgID: 1, Labels: map[string]string{"a": "c"}})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, map[string]string{"a": "c"}, requireFrameEntry(t, frame, 0).InstanceLabels)
+
+		frame, err = h.Query(context.Background(), models.HistoryQuery{OrgID: 2})
+		require.NoError(t, err)
+		require.Equal(t, 0, frame.Rows())
+	})
+
+	t.Run("returns the most recent entries when limited", func(t *testing.T) {
+		h := createTestSQLBackend(t, SQLConfig{}, now)
+		rule := createTestRule()
+		states := []state.StateTransition{
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "b"}, now.Add(-3*time.Minute)),
+			transitionAt(eval.Alerting, eval.Normal, data.Labels{"a": "b"}, now.Add(-2*time.Minute)),
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "b"}, now.Add(-time.Minute)),
+		}
+		require.NoError(t, <-h.Record(context.Background(), rule, states))
+
+		frame, err := h.Query(context.Background(), models.HistoryQuery{OrgID: 1, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, now.Add(-2*time.Minute), frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, now.Add(-time.Minute), frame.Fields[0].At(1).(time.Time).UTC())
+	})
+
+	t.Run("deletes expired entries", func(t *testing.T) {
+		h := createTestSQLBackend(t, SQLConfig{Retention: time.Hour}, now)
+		rule := createTestRule()
+		states := []state.StateTransition{
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "b"}, now.Add(-2*time.Hour)),
+			transitionAt(eval.Alerting, eval.Normal, data.Labels{"a": "b"}, now.Add(-time.Minute)),
+		}
+		require.NoError(t, <-h.Record(context.Background(), rule, states))
+
+		h.maintain(context.Background())
+
+		frame, err := h.Query(context.Background(), models.HistoryQuery{OrgID: 1, From: now.Add(-24 * time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "Normal", requireFrameEntry(t, frame, 0).Current)
+	})
+
+	t.Run("deletes expired entries in batches", func(t *testing.T) {
+		h := createTestSQLBackend(t, SQLConfig{Retention: time.Hour}, now)
+		rule := createTestRule()
+		states := make([]state.StateTransition, 0, sqlDeleteBatchSize+2)
+		for i := 0; i < sqlDeleteBatchSize+1; i++ {
+			states = append(states, transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": fmt.Sprint(i)}, now.Add(-2*time.Hour)))
+		}
+		states = append(states, transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "b"}, now.Add(-time.Minute)))
+		require.NoError(t, <-h.Record(context.Background(), rule, states))
+
+		deleted, err := h.deleteExpired(context.Background(), now.Add(-h.cfg.Retention))
+		require.NoError(t, err)
+		require.EqualValues(t, sqlDeleteBatchSize+1, deleted)
+
+		frame, err := h.Query(context.Background(), models.HistoryQuery{OrgID: 1, From: now.Add(-24 * time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+	})
+
+	t.Run("maintains the state history on a single instance", func(t *testing.T) {
+		h := createTestSQLBackend(t, SQLConfig{Retention: time.Hour}, now)
+		lock := &fakeServerLock{locked: true}
+		h.lock = lock
+		rule := createTestRule()
+		states := []state.StateTransition{
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "b"}, now.Add(-2*time.Hour)),
+		}
+		require.NoError(t, <-h.Record(context.Background(), rule, states))
+
+		// The context is cancelled so that Run returns after the first maintenance.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.NoError(t, h.Run(ctx))
+		require.Equal(t, []string{sqlMaintenanceAction}, lock.actions)
+
+		frame, err := h.Query(context.Background(), models.HistoryQuery{OrgID: 1, From: now.Add(-24 * time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows(), "another instance holds the lock, so the expired entry should be kept")
+	})
+
+	t.Run("downsamples old entries", func(t *testing.T) {
+		h := createTestSQLBackend(t, SQLConfig{DownsampleAfter: 24 * time.Hour, DownsampleInterval: time.Hour}, now)
+		rule := createTestRule()
+		old := now.Add(-48 * time.Hour).Truncate(time.Hour)
+		states := []state.StateTransition{
+			// Three transitions in the same hour are downsampled to one.
+			transitionAt(eval.Normal, eval.Pending, data.Labels{"a": "b"}, old.Add(time.Minute)),
+			transitionAt(eval.Pending, eval.Alerting, data.Labels{"a": "b"}, old.Add(2*time.Minute)),
+			transitionAt(eval.Alerting, eval.Normal, data.Labels{"a": "b"}, old.Add(3*time.Minute)),
+			// Another instance in the same hour is kept.
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "c"}, old.Add(time.Minute)),
+			// The next hour is kept.
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "b"}, old.Add(time.Hour)),
+			// Recent transitions are not downsampled.
+			transitionAt(eval.Alerting, eval.Normal, data.Labels{"a": "b"}, now.Add(-2*time.Minute)),
+			transitionAt(eval.Normal, eval.Alerting, data.Labels{"a": "b"}, now.Add(-time.Minute)),
+		}
+		require.NoError(t, <-h.Record(context.Background(), rule, states))
+
+		deleted, err := h.downsample(context.Background(), now.Add(-h.cfg.DownsampleAfter))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, deleted)
+
+		frame, err := h.Query(context.Background(), models.HistoryQuery{OrgID: 1, From: now.Add(-72 * time.Hour), Labels: map[string]string{"a": "b"}})
+		require.NoError(t, err)
+		require.Equal(t, 4, frame.Rows())
+
+		downsampled := requireFrameEntry(t, frame, 0)
+		require.Equal(t, old.Add(3*time.Minute), frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, "Normal", downsampled.Previous)
+		require.Equal(t, "Normal", downsampled.Current)
+
+		// Downsampling again does not change anything.
+		deleted, err = h.downsample(context.Background(), now.Add(-h.cfg.DownsampleAfter))
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+	})
+
+	t.Run("downsamples only whole intervals", func(t *testing.T) {
+		h := createTestSQLBackend(t, SQLConfig{DownsampleAfter: 24 * time.Hour, DownsampleInterval: time.Hour}, now)
+		rule := createTestRule()
+		hour := now.Add(-48 * time.Hour).Truncate(time.Hour)
+		states := []state.StateTransition{
+			transitionAt(eval.Normal, eval.Pending, data.Labels{"a": "b"}, hour.Add(10*time.Minute)),
+			transitionAt(eval.Pending, eval.Alerting, data.Labels{"a": "b"}, hour.Add(20*time.Minute)),
+			transitionAt(eval.Alerting, eval.Normal, data.Labels{"a": "b"}, hour.Add(40*time.Minute)),
+		}
+		require.NoError(t, <-h.Record(context.Background(), rule, states))
+
+		// The cutoff falls in the middle of the hour, so none of its transitions are downsampled yet.
+		deleted, err := h.downsample(context.Background(), hour.Add(30*time.Minute))
+		require.NoError(t, err)
+		require.Zero(t, deleted)
+
+		deleted, err = h.downsample(context.Background(), hour.Add(90*time.Minute))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, deleted)
+
+		frame, err := h.Query(context.Background(), models.HistoryQuery{OrgID: 1, From: now.Add(-72 * time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, 1, frame.Rows())
+		downsampled := requireFrameEntry(t, frame, 0)
+		require.Equal(t, "Normal", downsampled.Previous)
+		require.Equal(t, "Normal", downsampled.Current)
+	})
+}
+
+type fakeServerLock struct {
+	locked  bool
+	actions []string
+}
+
+func (f *fakeServerLock) LockAndExecute(ctx context.Context, actionName string, _ time.Duration, fn func(ctx context.Context)) error {
+	f.actions = append(f.actions, actionName)
+	if !f.locked {
+		fn(ctx)
+	}
+	return nil
+}
+
+func createTestSQLBackend(t *testing.T, cfg SQLConfig, now time.Time) *SQLBackend {
+	t.Helper()
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+	h := NewSQLBackend(log.NewNopLogger(), cfg, db.InitTestDB(t), &fakeServerLock{}, met)
+	clk := clock.NewMock()
+	clk.Set(now)
+	h.clock = clk
+	return h
+}
+
+func transitionAt(from, to eval.State, labels data.Labels, at time.Time) state.StateTransition {
+	return state.StateTransition{
+		PreviousState: from,
+		State: &state.State{
+			State:              to,
+			Labels:             labels,
+			LastEvaluationTime: at,
+		},
+	}
+}
+
+func requireFrameEntry(t *testing.T, frame *data.Frame, i int) LokiEntry {
+	t.Helper()
+
+	var entry LokiEntry
+	require.NoError(t, json.Unmarshal(frame.Fields[1].At(i).(json.RawMessage), &entry))
+	return entry
+}
@@ -29,6 +29,16 @@ type AlertInstanceManager interface {
 	GetStatesForRuleUID(orgID int64, alertRuleUID string) []*State
 }
 
+// HandoffStore records the handoffs of the states of rules between the instances of a cluster. It is satisfied by kvstore.KVStore.
+type HandoffStore interface {
+	Get(ctx context.Context, orgId int64, namespace string, key string) (string, bool, error)
+	Set(ctx context.Context, orgId int64, namespace string, key string, value string) error
+	Del(ctx context.Context, orgId int64, namespace string, key string) error
+}
+
+// handoffNamespace is the namespace of the HandoffStore the handoffs are recorded in, by rule UID.
+const handoffNamespace = "alerting.state.handoff"
+
 type StatePersister interface {
 	Async(ctx context.Context, cache *cache)
 	Sync(ctx context.Context, span trace.Span, states, staleStates []StateTransition)
@@ -45,6 +55,7 @@ type Manager struct {
 	ResolvedRetention time.Duration
 
 	instanceStore InstanceStore
+	handoffStore  HandoffStore
 	images        ImageCapturer
 	historian     Historian
 	externalURL   *url.URL
@@ -60,9 +71,11 @@ type ManagerCfg struct {
 	Metrics       *metrics.State
 	ExternalURL   *url.URL
 	InstanceStore InstanceStore
-	Images        ImageCapturer
-	Clock         clock.Clock
-	Historian     Historian
+	// HandoffStore records the handoffs of the states of rules when the rules are sharded across the instances of a cluster. Optional.
+	HandoffStore HandoffStore
+	Images       ImageCapturer
+	Clock        clock.Clock
+	Historian    Historian
 	// DoNotSaveNormalState controls whether eval.Normal state is persisted to the database and returned by get methods
 	DoNotSaveNormalState bool
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
@@ -96,6 +109,7 @@ func NewManager(cfg ManagerCfg, statePersister StatePersister) *Manager {
 		log:                            cfg.Log,
 		metrics:                        cfg.Metrics,
 		instanceStore:                  cfg.InstanceStore,
+		handoffStore:                   cfg.HandoffStore,
 		images:                         cfg.Images,
 		historian:                      cfg.Historian,
 		clock:                          cfg.Clock,
@@ -189,30 +203,8 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID := entry.Labels.Fingerprint()
-			var resultFp data.Fingerprint
-			if entry.ResultFingerprint != "" {
-				fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
-				if err != nil {
-					st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
-				}
-				resultFp = data.Fingerprint(fp)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          ruleForEntry.Annotations,
-				ResultFingerprint:    resultFp,
-			}
+			s := st.stateFromInstance(entry, ruleForEntry)
+			rulesStates.states[s.CacheID] = s
 			statesCount++
 		}
 	}
@@ -221,6 +213,125 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// WarmRule replaces the states of the rule in the cache with the states saved in the instanceStore.
+// It is used when this instance takes over the evaluation of the rule from another instance of the cluster.
+func (st *Manager) WarmRule(ctx context.Context, rule *ngModels.AlertRule) {
+	if st.instanceStore == nil {
+		return
+	}
+	logger := st.log.FromContext(ctx).New(rule.GetKey().LogContext()...)
+
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	})
+	if err != nil {
+		logger.Error("Unable to fetch previous state of the rule", "error", err)
+		return
+	}
+
+	rulesStates := &ruleStates{states: make(map[data.Fingerprint]*State, len(alertInstances))}
+	for _, entry := range alertInstances {
+		s := st.stateFromInstance(entry, rule)
+		rulesStates.states[s.CacheID] = s
+	}
+	st.cache.setRuleStates(rule.GetKey(), rulesStates)
+	logger.Debug("Rule states have been restored", "states", len(alertInstances))
+}
+
+// HandOffStateByRuleUID saves the states of the rule to the instanceStore and removes them from the cache without
+// resolving them, so that the instance that takes over the evaluation of the rule can restore them with WarmRule.
+// Once all states are saved, the handoff is recorded for the membership version of the cluster, see HandedOff.
+func (st *Manager) HandOffStateByRuleUID(ctx context.Context, ruleKey ngModels.AlertRuleKey, version string) {
+	logger := st.log.FromContext(ctx).New(ruleKey.LogContext()...)
+
+	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	if st.instanceStore == nil {
+		return
+	}
+
+	saved, failed := 0, 0
+	for _, s := range states {
+		if st.doNotSaveNormalState && IsNormalStateWithNoReason(s) {
+			continue
+		}
+		instance, err := instanceFromState(s)
+		if err != nil {
+			logger.Error("Failed to create an alert instance from the state", "error", err)
+			failed++
+			continue
+		}
+		if err := st.instanceStore.SaveAlertInstance(ctx, instance); err != nil {
+			logger.Error("Failed to save the state of the rule", "error", err)
+			failed++
+			continue
+		}
+		saved++
+	}
+	if failed > 0 || st.handoffStore == nil {
+		logger.Info("Rule states were handed off", "states", saved, "failed", failed)
+		return
+	}
+	if err := st.handoffStore.Set(ctx, ruleKey.OrgID, handoffNamespace, ruleKey.UID, version); err != nil {
+		logger.Error("Failed to record the handoff of the rule states", "error", err)
+		return
+	}
+	logger.Info("Rule states were handed off", "states", saved, "version", version)
+}
+
+// HandedOff returns true if the previous owner of the rule has handed off its states for the membership version of
+// the cluster, in which case the states in the instanceStore are up to date and can be restored with WarmRule.
+// The handoff is consumed so that it is not mistaken for a later handoff of the rule. HandedOff always returns true if
+// handoffs are not recorded.
+func (st *Manager) HandedOff(ctx context.Context, ruleKey ngModels.AlertRuleKey, version string) bool {
+	if st.handoffStore == nil {
+		return true
+	}
+	handedOff, ok, err := st.handoffStore.Get(ctx, ruleKey.OrgID, handoffNamespace, ruleKey.UID)
+	if err != nil {
+		st.log.FromContext(ctx).Error("Failed to get the handoff of the rule states", append(ruleKey.LogContext(), "error", err)...)
+		return false
+	}
+	if !ok || handedOff != version {
+		return false
+	}
+	if err := st.handoffStore.Del(ctx, ruleKey.OrgID, handoffNamespace, ruleKey.UID); err != nil {
+		st.log.FromContext(ctx).Warn("Failed to delete the handoff of the rule states", append(ruleKey.LogContext(), "error", err)...)
+	}
+	return true
+}
+
+// ForgetStateByRuleUID removes the states of the rule from the cache without changing the states in the instanceStore.
+// It is used for the rules that are evaluated by another instance of the cluster.
+func (st *Manager) ForgetStateByRuleUID(ruleKey ngModels.AlertRuleKey) {
+	st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule) *State {
+	var resultFp data.Fingerprint
+	if entry.ResultFingerprint != "" {
+		fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
+		if err != nil {
+			st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
+		}
+		resultFp = data.Fingerprint(fp)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              entry.Labels.Fingerprint(),
+		Labels:               map[string]string(entry.Labels),
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          rule.Annotations,
+		ResultFingerprint:    resultFp,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID string, stateId data.Fingerprint) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
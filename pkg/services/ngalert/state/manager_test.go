@@ -24,6 +24,7 @@ import (
 	"golang.org/x/exp/slices"
 
 	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/annotations"
@@ -2093,6 +2094,85 @@ func TestResetStateByRuleUID(t *testing.T) {
 	}
 }
 
+func TestHandOffStateByRuleUID(t *testing.T) {
+	interval := time.Minute
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, 1)
+
+	const mainOrgID int64 = 1
+	rule := tests.CreateTestAlertRule(t, ctx, dbstore, int64(interval.Seconds()), mainOrgID)
+
+	labels := models.InstanceLabels{"test1": "testValue1"}
+	_, hash, _ := labels.StringAndHash()
+	err := dbstore.SaveAlertInstance(ctx, models.AlertInstance{
+		AlertInstanceKey: models.AlertInstanceKey{
+			RuleOrgID:  rule.OrgID,
+			RuleUID:    rule.UID,
+			LabelsHash: hash,
+		},
+		CurrentState:      models.InstanceStatePending,
+		CurrentStateSince: time.Unix(100, 0),
+		LastEvalTime:      time.Unix(100, 0),
+		Labels:            labels,
+	})
+	require.NoError(t, err)
+
+	handoffs := kvstore.NewFakeKVStore()
+	newManager := func() *state.Manager {
+		cfg := state.ManagerCfg{
+			Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			InstanceStore: dbstore,
+			HandoffStore:  handoffs,
+			Images:        &state.NoopImageService{},
+			Clock:         clock.NewMock(),
+			Historian:     &state.FakeHistorian{},
+			Tracer:        tracing.InitializeTracerForTest(),
+			Log:           log.New("ngalert.state.manager"),
+		}
+		st := state.NewManager(cfg, state.NewNoopPersister())
+		st.Warm(ctx, dbstore)
+		return st
+	}
+	previousOwner := newManager()
+	newOwner := newManager()
+
+	// The previous owner evaluates the rule once more before it hands off the states.
+	states := previousOwner.GetStatesForRuleUID(rule.OrgID, rule.UID)
+	require.Len(t, states, 1)
+	states[0].LastEvaluationTime = time.Unix(160, 0)
+
+	// The new owner waits for the handoff of the membership version it took over the rule in.
+	require.False(t, newOwner.HandedOff(ctx, rule.GetKey(), "v1"))
+
+	previousOwner.HandOffStateByRuleUID(ctx, rule.GetKey(), "v1")
+	require.Empty(t, previousOwner.GetStatesForRuleUID(rule.OrgID, rule.UID))
+
+	alertInstances, err := dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+	require.NoError(t, err)
+	require.Len(t, alertInstances, 1)
+
+	require.False(t, newOwner.HandedOff(ctx, rule.GetKey(), "v2"), "handoffs of other versions are ignored")
+	require.True(t, newOwner.HandedOff(ctx, rule.GetKey(), "v1"))
+	require.False(t, newOwner.HandedOff(ctx, rule.GetKey(), "v1"), "handoffs are consumed")
+
+	newOwner.WarmRule(ctx, rule)
+	restored := newOwner.GetStatesForRuleUID(rule.OrgID, rule.UID)
+	require.Len(t, restored, 1)
+	assert.Equal(t, eval.Pending, restored[0].State)
+	assert.Equal(t, int64(100), restored[0].StartsAt.Unix())
+	assert.Equal(t, int64(160), restored[0].LastEvaluationTime.Unix())
+
+	newOwner.ForgetStateByRuleUID(rule.GetKey())
+	require.Empty(t, newOwner.GetStatesForRuleUID(rule.OrgID, rule.UID))
+	alertInstances, err = dbstore.ListAlertInstances(ctx, &models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+	require.NoError(t, err)
+	require.Len(t, alertInstances, 1)
+
+	// The handoff is recorded even if the rule has no states, so that the new owner does not wait for it.
+	newOwner.HandOffStateByRuleUID(ctx, rule.GetKey(), "v3")
+	require.True(t, previousOwner.HandedOff(ctx, rule.GetKey(), "v3"))
+}
+
 func setCacheID(s *state.State) *state.State {
 	if s.CacheID != 0 {
 		return s
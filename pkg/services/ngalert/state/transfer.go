@@ -0,0 +1,172 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	alertingModels "github.com/grafana/alerting/models"
+	prometheusModel "github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// SkippedInstance is an alert instance that cannot be imported.
+type SkippedInstance struct {
+	Instance ngModels.AlertInstance
+	Reason   string
+}
+
+// ExportStates returns the states of the organization as alert instances, sorted by rule UID and labels hash.
+// If rule UIDs are provided, only the states of these rules are returned.
+// When the rules are sharded across the instances of a cluster, the cache only contains the states of the rules
+// evaluated by this instance. The states are then read from the instanceStore, where every instance saves the states
+// of its rules.
+func (st *Manager) ExportStates(ctx context.Context, orgID int64, ruleUIDs ...string) ([]ngModels.AlertInstance, error) {
+	var instances []ngModels.AlertInstance
+	// The handoffs are only recorded when the rules are sharded.
+	if st.handoffStore != nil && st.instanceStore != nil {
+		stored, err := st.storedInstances(ctx, orgID, ruleUIDs)
+		if err != nil {
+			return nil, err
+		}
+		instances = stored
+	} else {
+		instances = st.cachedInstances(orgID, ruleUIDs)
+	}
+	slices.SortFunc(instances, func(a, b ngModels.AlertInstance) int {
+		if c := strings.Compare(a.RuleUID, b.RuleUID); c != 0 {
+			return c
+		}
+		return strings.Compare(a.LabelsHash, b.LabelsHash)
+	})
+	return instances, nil
+}
+
+// cachedInstances returns the states of the rules in the cache as alert instances.
+func (st *Manager) cachedInstances(orgID int64, ruleUIDs []string) []ngModels.AlertInstance {
+	var states []*State
+	if len(ruleUIDs) == 0 {
+		states = st.cache.getAll(orgID, st.doNotSaveNormalState)
+	} else {
+		for _, uid := range ruleUIDs {
+			states = append(states, st.cache.getStatesForRuleUID(orgID, uid, st.doNotSaveNormalState)...)
+		}
+	}
+
+	instances := make([]ngModels.AlertInstance, 0, len(states))
+	for _, s := range states {
+		instance, err := instanceFromState(s)
+		if err != nil {
+			st.log.Error("Failed to create an alert instance from the state", "error", err, "ruleUID", s.AlertRuleUID)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	return instances
+}
+
+// storedInstances returns the alert instances of the rules saved in the instanceStore.
+func (st *Manager) storedInstances(ctx context.Context, orgID int64, ruleUIDs []string) ([]ngModels.AlertInstance, error) {
+	queries := make([]ngModels.ListAlertInstancesQuery, 0, len(ruleUIDs))
+	if len(ruleUIDs) == 0 {
+		queries = append(queries, ngModels.ListAlertInstancesQuery{RuleOrgID: orgID})
+	}
+	for _, uid := range ruleUIDs {
+		queries = append(queries, ngModels.ListAlertInstancesQuery{RuleOrgID: orgID, RuleUID: uid})
+	}
+
+	var instances []ngModels.AlertInstance
+	for i := range queries {
+		stored, err := st.instanceStore.ListAlertInstances(ctx, &queries[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to list alert instances: %w", err)
+		}
+		for _, instance := range stored {
+			if st.doNotSaveNormalState && instance.CurrentState == ngModels.InstanceStateNormal && instance.CurrentReason == "" {
+				continue
+			}
+			instances = append(instances, *instance)
+		}
+	}
+	return instances, nil
+}
+
+// ImportStates saves the alert instances to the instanceStore and adds them to the cache, replacing the states of the
+// rules that have the same labels. The instances are expected to be prepared with PrepareInstancesForImport.
+func (st *Manager) ImportStates(ctx context.Context, rules map[string]*ngModels.AlertRule, instances []ngModels.AlertInstance) error {
+	logger := st.log.FromContext(ctx)
+	for i := range instances {
+		rule, ok := rules[instances[i].RuleUID]
+		if !ok {
+			return fmt.Errorf("alert rule %s of the alert instance does not exist", instances[i].RuleUID)
+		}
+		if st.instanceStore != nil {
+			if err := st.instanceStore.SaveAlertInstance(ctx, instances[i]); err != nil {
+				return fmt.Errorf("failed to save alert instance: %w", err)
+			}
+		}
+		st.cache.set(st.stateFromInstance(&instances[i], rule))
+	}
+	logger.Info("Alert instance states were imported", "states", len(instances))
+	return nil
+}
+
+// PrepareInstancesForImport moves the alert instances to the organization and the alert rules they are imported to.
+// The rule UIDs of the instances are replaced using ruleUIDMapping, and the built-in labels of the instances are
+// replaced with the labels of the target rules, so that the instances match the states created by the next evaluation
+// of the rules. folderTitles contains the full paths of the folders of the rules by UID, as used by the scheduler for
+// the folder label. The folder label is kept if the folder is unknown.
+// The instances of rules that are not in rules, and the instances with an invalid state are skipped.
+func PrepareInstancesForImport(l log.Logger, orgID int64, instances []ngModels.AlertInstance, ruleUIDMapping map[string]string, rules map[string]*ngModels.AlertRule, folderTitles map[string]string) ([]ngModels.AlertInstance, []SkippedInstance) {
+	result := make([]ngModels.AlertInstance, 0, len(instances))
+	var skipped []SkippedInstance
+	for _, instance := range instances {
+		ruleUID := instance.RuleUID
+		if mapped, ok := ruleUIDMapping[ruleUID]; ok {
+			ruleUID = mapped
+		}
+		rule, ok := rules[ruleUID]
+		if !ok {
+			skipped = append(skipped, SkippedInstance{Instance: instance, Reason: fmt.Sprintf("alert rule %s does not exist", ruleUID)})
+			continue
+		}
+		if !instance.CurrentState.IsValid() {
+			skipped = append(skipped, SkippedInstance{Instance: instance, Reason: fmt.Sprintf("invalid state '%s'", instance.CurrentState)})
+			continue
+		}
+
+		labels := make(ngModels.InstanceLabels, len(instance.Labels))
+		for k, v := range instance.Labels {
+			switch k {
+			case alertingModels.NamespaceUIDLabel, alertingModels.RuleUIDLabel, prometheusModel.AlertNameLabel,
+				ngModels.AutogeneratedRouteLabel, ngModels.AutogeneratedRouteReceiverNameLabel, ngModels.AutogeneratedRouteSettingsHashLabel:
+				continue
+			}
+			labels[k] = v
+		}
+		folderTitle, includeFolder := labels[ngModels.FolderTitleLabel]
+		if title, ok := folderTitles[rule.NamespaceUID]; ok {
+			folderTitle = title
+		}
+		for k, v := range GetRuleExtraLabels(l, rule, folderTitle, includeFolder) {
+			labels[k] = v
+		}
+		_, labelsHash, err := labels.StringAndHash()
+		if err != nil {
+			skipped = append(skipped, SkippedInstance{Instance: instance, Reason: fmt.Sprintf("invalid labels: %s", err)})
+			continue
+		}
+
+		instance.AlertInstanceKey = ngModels.AlertInstanceKey{
+			RuleOrgID:  orgID,
+			RuleUID:    rule.UID,
+			LabelsHash: labelsHash,
+		}
+		instance.Labels = labels
+		result = append(result, instance)
+	}
+	return result, skipped
+}
@@ -0,0 +1,203 @@
+package state_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	alertingModels "github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestPrepareInstancesForImport(t *testing.T) {
+	gen := models.RuleGen
+	gen = gen.With(gen.WithNoNotificationSettings())
+	target := gen.With(gen.WithOrgID(2), gen.WithTitle("target")).GenerateRef()
+	sameUID := gen.With(gen.WithOrgID(2)).GenerateRef()
+
+	exported := func(ruleUID string, labels models.InstanceLabels) models.AlertInstance {
+		_, hash, err := labels.StringAndHash()
+		require.NoError(t, err)
+		return models.AlertInstance{
+			AlertInstanceKey:  models.AlertInstanceKey{RuleOrgID: 1, RuleUID: ruleUID, LabelsHash: hash},
+			Labels:            labels,
+			CurrentState:      models.InstanceStateFiring,
+			CurrentStateSince: time.Unix(100, 0),
+			LastEvalTime:      time.Unix(200, 0),
+		}
+	}
+	instances := []models.AlertInstance{
+		exported("old-uid", models.InstanceLabels{
+			alertingModels.RuleUIDLabel:      "old-uid",
+			alertingModels.NamespaceUIDLabel: "old-folder",
+			prometheusModel.AlertNameLabel:   "source",
+			models.FolderTitleLabel:          "Old folder",
+			models.AutogeneratedRouteLabel:   "true",
+			"instance":                       "node-1",
+		}),
+		exported(sameUID.UID, models.InstanceLabels{
+			alertingModels.RuleUIDLabel: sameUID.UID,
+			"instance":                  "node-2",
+		}),
+		exported("missing", models.InstanceLabels{"instance": "node-3"}),
+	}
+	invalid := exported(sameUID.UID, models.InstanceLabels{"instance": "node-4"})
+	invalid.CurrentState = "Unknown"
+	instances = append(instances, invalid)
+
+	result, skipped := state.PrepareInstancesForImport(
+		log.NewNopLogger(),
+		2,
+		instances,
+		map[string]string{"old-uid": target.UID},
+		map[string]*models.AlertRule{target.UID: target, sameUID.UID: sameUID},
+		map[string]string{target.NamespaceUID: "New folder"},
+	)
+
+	require.Len(t, result, 2)
+	assert.Equal(t, models.InstanceLabels{
+		alertingModels.RuleUIDLabel:      target.UID,
+		alertingModels.NamespaceUIDLabel: target.NamespaceUID,
+		prometheusModel.AlertNameLabel:   "target",
+		models.FolderTitleLabel:          "New folder",
+		"instance":                       "node-1",
+	}, result[0].Labels)
+	_, hash, err := result[0].Labels.StringAndHash()
+	require.NoError(t, err)
+	assert.Equal(t, models.AlertInstanceKey{RuleOrgID: 2, RuleUID: target.UID, LabelsHash: hash}, result[0].AlertInstanceKey)
+	assert.Equal(t, models.InstanceStateFiring, result[0].CurrentState)
+	assert.Equal(t, time.Unix(100, 0), result[0].CurrentStateSince)
+
+	assert.Equal(t, sameUID.UID, result[1].RuleUID)
+	assert.Equal(t, int64(2), result[1].RuleOrgID)
+	assert.Equal(t, sameUID.Title, result[1].Labels[prometheusModel.AlertNameLabel])
+	assert.NotContains(t, result[1].Labels, models.FolderTitleLabel, "folder label should only be added if it was exported")
+
+	require.Len(t, skipped, 2)
+	assert.Equal(t, "missing", skipped[0].Instance.RuleUID)
+	assert.Equal(t, "Unknown", string(skipped[1].Instance.CurrentState))
+}
+
+func TestExportImportStates(t *testing.T) {
+	ctx := context.Background()
+	gen := models.RuleGen
+	rule := gen.With(gen.WithOrgID(1)).GenerateRef()
+	other := gen.With(gen.WithOrgID(1)).GenerateRef()
+
+	newManager := func(store state.InstanceStore) *state.Manager {
+		return state.NewManager(state.ManagerCfg{
+			InstanceStore: store,
+			Images:        &state.NoopImageService{},
+			Clock:         clock.NewMock(),
+			Historian:     &state.FakeHistorian{},
+			Tracer:        tracing.InitializeTracerForTest(),
+			Log:           log.New("ngalert.state.manager"),
+		}, state.NewNoopPersister())
+	}
+	source := newManager(&state.FakeInstanceStore{})
+	source.Put([]*state.State{
+		setCacheID(&state.State{OrgID: 1, AlertRuleUID: rule.UID, Labels: data.Labels{"instance": "b"}, State: eval.Alerting, StartsAt: time.Unix(100, 0)}),
+		setCacheID(&state.State{OrgID: 1, AlertRuleUID: rule.UID, Labels: data.Labels{"instance": "a"}, State: eval.Pending, StartsAt: time.Unix(200, 0)}),
+		setCacheID(&state.State{OrgID: 1, AlertRuleUID: other.UID, Labels: data.Labels{"instance": "c"}, State: eval.Alerting}),
+	})
+
+	exported, err := source.ExportStates(ctx, 1, rule.UID)
+	require.NoError(t, err)
+	require.Len(t, exported, 2)
+	for _, instance := range exported {
+		assert.Equal(t, rule.UID, instance.RuleUID)
+	}
+	assert.Less(t, exported[0].LabelsHash, exported[1].LabelsHash)
+	all, err := source.ExportStates(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	otherOrg, err := source.ExportStates(ctx, 2)
+	require.NoError(t, err)
+	require.Empty(t, otherOrg)
+
+	store := &state.FakeInstanceStore{}
+	target := newManager(store)
+	err = target.ImportStates(ctx, map[string]*models.AlertRule{rule.UID: rule}, exported)
+	require.NoError(t, err)
+	require.Len(t, store.RecordedOps(), 2)
+
+	imported := target.GetStatesForRuleUID(1, rule.UID)
+	require.Len(t, imported, 2)
+	for _, s := range imported {
+		switch s.Labels["instance"] {
+		case "a":
+			assert.Equal(t, eval.Pending, s.State)
+			assert.Equal(t, int64(200), s.StartsAt.Unix())
+		case "b":
+			assert.Equal(t, eval.Alerting, s.State)
+			assert.Equal(t, int64(100), s.StartsAt.Unix())
+		default:
+			require.Fail(t, "unexpected state", s.Labels)
+		}
+	}
+
+	err = target.ImportStates(ctx, map[string]*models.AlertRule{}, exported)
+	require.Error(t, err)
+}
+
+type storedInstances struct {
+	state.FakeInstanceStore
+	instances []*models.AlertInstance
+}
+
+func (s *storedInstances) ListAlertInstances(_ context.Context, q *models.ListAlertInstancesQuery) ([]*models.AlertInstance, error) {
+	var result []*models.AlertInstance
+	for _, instance := range s.instances {
+		if instance.RuleOrgID == q.RuleOrgID && (q.RuleUID == "" || instance.RuleUID == q.RuleUID) {
+			result = append(result, instance)
+		}
+	}
+	return result, nil
+}
+
+func TestExportStatesShardedRules(t *testing.T) {
+	ctx := context.Background()
+	stored := func(ruleUID, hash string) *models.AlertInstance {
+		return &models.AlertInstance{
+			AlertInstanceKey: models.AlertInstanceKey{RuleOrgID: 1, RuleUID: ruleUID, LabelsHash: hash},
+			CurrentState:     models.InstanceStateFiring,
+		}
+	}
+	store := &storedInstances{instances: []*models.AlertInstance{stored("b", "1"), stored("a", "2"), stored("a", "1")}}
+	manager := state.NewManager(state.ManagerCfg{
+		InstanceStore: store,
+		HandoffStore:  kvstore.NewFakeKVStore(),
+		Images:        &state.NoopImageService{},
+		Clock:         clock.NewMock(),
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}, state.NewNoopPersister())
+	// The rules of the states in the cache are evaluated by this instance, the others by other instances of the cluster.
+	manager.Put([]*state.State{
+		setCacheID(&state.State{OrgID: 1, AlertRuleUID: "a", Labels: data.Labels{"instance": "a"}, State: eval.Alerting}),
+	})
+
+	exported, err := manager.ExportStates(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, exported, 3)
+	assert.Equal(t, models.AlertInstanceKey{RuleOrgID: 1, RuleUID: "a", LabelsHash: "1"}, exported[0].AlertInstanceKey)
+	assert.Equal(t, models.AlertInstanceKey{RuleOrgID: 1, RuleUID: "a", LabelsHash: "2"}, exported[1].AlertInstanceKey)
+	assert.Equal(t, models.AlertInstanceKey{RuleOrgID: 1, RuleUID: "b", LabelsHash: "1"}, exported[2].AlertInstanceKey)
+
+	exported, err = manager.ExportStates(ctx, 1, "b")
+	require.NoError(t, err)
+	require.Len(t, exported, 1)
+	assert.Equal(t, "b", exported[0].RuleUID)
+}
@@ -103,6 +103,29 @@ func (st DBstore) GetAlertRuleByUID(ctx context.Context, query *ngmodels.GetAler
 	return result, err
 }
 
+// GetAlertRuleVersions returns the versions of the alert rule with the given UID, the most recent first.
+// It returns ngmodels.ErrAlertRuleNotFound if there are no versions of the rule.
+func (st DBstore) GetAlertRuleVersions(ctx context.Context, query *ngmodels.GetAlertRuleVersionsQuery) (result []*ngmodels.AlertRuleVersion, err error) {
+	err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var versions []*ngmodels.AlertRuleVersion
+		if err := sess.Table("alert_rule_version").Where("rule_org_id = ? AND rule_uid = ?", query.OrgID, query.UID).Desc("version", "id").Find(&versions); err != nil {
+			return err
+		}
+		// MySQL by default compares strings without case-sensitivity, make sure we keep the case-sensitive comparison.
+		result = make([]*ngmodels.AlertRuleVersion, 0, len(versions))
+		for _, v := range versions {
+			if v.RuleUID == query.UID {
+				result = append(result, v)
+			}
+		}
+		if len(result) == 0 {
+			return ngmodels.ErrAlertRuleNotFound
+		}
+		return nil
+	})
+	return result, err
+}
+
 // GetAlertRulesGroupByRuleUID is a handler for retrieving a group of alert rules from that database by UID and organisation ID of one of rules that belong to that group.
 func (st DBstore) GetAlertRulesGroupByRuleUID(ctx context.Context, query *ngmodels.GetAlertRulesGroupByRuleUIDQuery) (result []*ngmodels.AlertRule, err error) {
 	err = st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
@@ -178,7 +201,10 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 				Annotations:          r.Annotations,
 				Labels:               r.Labels,
 				Record:               r.Record,
+				IsPaused:             r.IsPaused,
 				NotificationSettings: r.NotificationSettings,
+				DependsOn:            r.DependsOn,
+				CreatedBy:            r.UpdatedBy,
 			})
 		}
 		if len(newRules) > 0 {
@@ -259,7 +285,10 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				For:                  r.New.For,
 				Annotations:          r.New.Annotations,
 				Labels:               r.New.Labels,
+				IsPaused:             r.New.IsPaused,
 				NotificationSettings: r.New.NotificationSettings,
+				DependsOn:            r.New.DependsOn,
+				CreatedBy:            r.New.UpdatedBy,
 			})
 		}
 		if len(ruleVersions) > 0 {
@@ -1072,6 +1072,69 @@ func TestIntegrationRuleGroupsCaseSensitive(t *testing.T) {
 	})
 }
 
+func TestIntegrationGetAlertRuleVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.UnifiedAlerting.BaseInterval = 1 * time.Second
+	store := &DBstore{
+		SQLStore:      sqlStore,
+		FolderService: setupFolderService(t, sqlStore, cfg, featuremgmt.WithFeatures()),
+		Logger:        log.New("test-dbstore"),
+		Cfg:           cfg.UnifiedAlerting,
+	}
+	gen := models.RuleGen
+	gen = gen.With(gen.WithOrgID(1), gen.WithIntervalMatching(store.Cfg.BaseInterval))
+
+	author := models.UserUID("author")
+	editor := models.UserUID("editor")
+	rule := gen.With(gen.WithUpdatedBy(&author), gen.WithIsPaused(true)).Generate()
+	ids, err := store.InsertAlertRules(context.Background(), []models.AlertRule{rule})
+	require.NoError(t, err)
+	rule.ID = ids[0].ID
+	rule.UID = ids[0].UID
+
+	existing, err := store.GetAlertRuleByUID(context.Background(), &models.GetAlertRuleByUIDQuery{UID: rule.UID, OrgID: rule.OrgID})
+	require.NoError(t, err)
+	require.Equal(t, &author, existing.UpdatedBy)
+
+	updated := models.CopyRule(existing)
+	updated.Title = util.GenerateShortUID()
+	updated.UpdatedBy = &editor
+	updated.IsPaused = false
+	err = store.UpdateAlertRules(context.Background(), []models.UpdateRule{{
+		Existing: existing,
+		New:      *updated,
+	}})
+	require.NoError(t, err)
+
+	versions, err := store.GetAlertRuleVersions(context.Background(), &models.GetAlertRuleVersionsQuery{UID: rule.UID, OrgID: rule.OrgID})
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+
+	assert.Equal(t, existing.Version+1, versions[0].Version)
+	assert.Equal(t, existing.Version, versions[0].ParentVersion)
+	assert.Equal(t, updated.Title, versions[0].Title)
+	assert.Equal(t, &editor, versions[0].CreatedBy)
+	assert.False(t, versions[0].IsPaused)
+
+	assert.Equal(t, existing.Version, versions[1].Version)
+	assert.Equal(t, rule.Title, versions[1].Title)
+	assert.Equal(t, &author, versions[1].CreatedBy)
+	assert.True(t, versions[1].IsPaused, "the version should record the paused state of the rule")
+
+	restored := models.AlertRuleFromVersion(versions[1])
+	assert.Empty(t, restored.Diff(existing, "ID", "DashboardUID", "PanelID", "RuleGroupIndex").Paths())
+
+	t.Run("should return not found if rule has no versions", func(t *testing.T) {
+		_, err := store.GetAlertRuleVersions(context.Background(), &models.GetAlertRuleVersionsQuery{UID: "missing", OrgID: rule.OrgID})
+		require.ErrorIs(t, err, models.ErrAlertRuleNotFound)
+	})
+}
+
 // createAlertRule creates an alert rule in the database and returns it.
 // If a generator is not specified, uniqueness of primary key is not guaranteed.
 func createRule(t *testing.T, store *DBstore, generator *models.AlertRuleGenerator) *models.AlertRule {
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -9,7 +10,7 @@ import (
 )
 
 // AlertRuleFieldsToIgnoreInDiff contains fields that are ignored when calculating the RuleDelta.Diff.
-var AlertRuleFieldsToIgnoreInDiff = [...]string{"ID", "Version", "Updated"}
+var AlertRuleFieldsToIgnoreInDiff = [...]string{"ID", "Version", "Updated", "UpdatedBy"}
 
 type RuleDelta struct {
 	Existing *models.AlertRule
@@ -145,6 +146,27 @@ func calculateChanges(ctx context.Context, ruleReader RuleReader, groupKey model
 		toDelete = append(toDelete, rule)
 	}
 
+	newGroup := make([]*models.AlertRule, 0, len(submittedRules))
+	submittedUIDs := make(map[string]struct{}, len(submittedRules))
+	for _, r := range submittedRules {
+		if r != nil {
+			newGroup = append(newGroup, &r.AlertRule)
+			submittedUIDs[r.UID] = struct{}{}
+		}
+	}
+	if err := validateGroupDependencies(newGroup); err != nil {
+		return nil, err
+	}
+	// The rules moved from other groups must not leave behind rules that depend on them.
+	for key, rules := range affectedGroups {
+		if key == groupKey {
+			continue
+		}
+		if err := validateNoDependents(rules, submittedUIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	return &GroupDelta{
 		GroupKey:       groupKey,
 		AffectedGroups: affectedGroups,
@@ -154,6 +176,44 @@ func calculateChanges(ctx context.Context, ruleReader RuleReader, groupKey model
 	}, nil
 }
 
+// validateGroupDependencies checks that the rules of the group only depend on rules of the same group, and that the
+// dependencies, declared and inferred from the recorded metrics, do not form a cycle.
+func validateGroupDependencies(group []*models.AlertRule) error {
+	uids := make(map[string]struct{}, len(group))
+	for _, r := range group {
+		if r.UID != "" {
+			uids[r.UID] = struct{}{}
+		}
+	}
+	for _, rule := range group {
+		for _, uid := range rule.DependsOn {
+			if _, ok := uids[uid]; !ok {
+				return fmt.Errorf("%w: rule '%s' depends on rule with UID %s that does not belong to the group", models.ErrAlertRuleFailedValidation, rule.Title, uid)
+			}
+		}
+	}
+	if _, err := models.SortByDependencies(group); err != nil {
+		return errors.Join(models.ErrAlertRuleFailedValidation, err)
+	}
+	return nil
+}
+
+// validateNoDependents checks that the rules of the group that remain in it do not depend on the rules that are removed
+// from it.
+func validateNoDependents(group []*models.AlertRule, removedUIDs map[string]struct{}) error {
+	for _, rule := range group {
+		if _, ok := removedUIDs[rule.UID]; ok {
+			continue
+		}
+		for _, uid := range rule.DependsOn {
+			if _, ok := removedUIDs[uid]; ok {
+				return fmt.Errorf("%w: rule with UID %s cannot be removed from its group because rule '%s' depends on it", models.ErrAlertRuleFailedValidation, uid, rule.Title)
+			}
+		}
+	}
+	return nil
+}
+
 // UpdateCalculatedRuleFields refreshes the calculated fields in a set of alert rule changes.
 // This may generate new changes to keep a group consistent, such as versions or rule indexes.
 func UpdateCalculatedRuleFields(ch *GroupDelta) *GroupDelta {
@@ -213,9 +273,12 @@ func CalculateRuleUpdate(ctx context.Context, ruleReader RuleReader, rule *model
 	newGroup := make([]*models.AlertRuleWithOptionals, 0, len(existingGroupRules)+1)
 	added := false
 	for _, alertRule := range existingGroupRules {
+		// The updated rule replaces the stored one. Submitting both would make the stored rule look like a rule moved
+		// from another group.
 		if alertRule.GetKey() == rule.GetKey() {
 			newGroup = append(newGroup, rule)
 			added = true
+			continue
 		}
 		newGroup = append(newGroup, &models.AlertRuleWithOptionals{AlertRule: *alertRule})
 	}
@@ -272,6 +335,9 @@ func CalculateRuleDelete(ctx context.Context, ruleReader RuleReader, ruleKey mod
 	if toDelete == nil { // should not happen if rule exists.
 		return nil, models.ErrAlertRuleNotFound
 	}
+	if err := validateNoDependents(group, map[string]struct{}{toDelete.UID: {}}); err != nil {
+		return nil, err
+	}
 	groupKey := group[0].GetGroupKey()
 	delta := &GroupDelta{
 		GroupKey: groupKey,
@@ -295,6 +361,12 @@ func CalculateRuleCreate(ctx context.Context, ruleReader RuleReader, rule *model
 		return nil, err
 	}
 
+	newGroup := make([]*models.AlertRule, 0, len(group)+1)
+	newGroup = append(newGroup, group...)
+	if err := validateGroupDependencies(append(newGroup, rule)); err != nil {
+		return nil, err
+	}
+
 	delta := &GroupDelta{
 		GroupKey:       rule.GetGroupKey(),
 		AffectedGroups: make(map[models.AlertRuleGroupKey]models.RulesGroup),
@@ -285,6 +285,67 @@ func TestCalculateChanges(t *testing.T) {
 		_, err := CalculateChanges(context.Background(), fakeStore, groupKey, []*models.AlertRuleWithOptionals{{AlertRule: submitted}})
 		require.ErrorIs(t, err, expectedErr)
 	})
+
+	t.Run("should fail if rule depends on rule that does not belong to the group", func(t *testing.T) {
+		groupKey := models.GenerateGroupKey(orgId)
+		inDatabase := gen.With(gen.WithGroupKey(groupKey)).GenerateRef()
+		fakeStore := fakes.NewRuleStore(t)
+		fakeStore.PutRule(context.Background(), inDatabase)
+
+		submitted := models.CopyRule(inDatabase, gen.WithDependsOn("missing"))
+
+		_, err := CalculateChanges(context.Background(), fakeStore, groupKey, []*models.AlertRuleWithOptionals{{AlertRule: *submitted}})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "missing")
+	})
+
+	t.Run("should fail if rule dependencies form a cycle", func(t *testing.T) {
+		groupKey := models.GenerateGroupKey(orgId)
+		inDatabase := gen.With(gen.WithGroupKey(groupKey)).GenerateManyRef(2, 2)
+		fakeStore := fakes.NewRuleStore(t)
+		fakeStore.PutRule(context.Background(), inDatabase...)
+
+		first := models.CopyRule(inDatabase[0], gen.WithDependsOn(inDatabase[1].UID))
+		second := models.CopyRule(inDatabase[1], gen.WithDependsOn(inDatabase[0].UID))
+
+		_, err := CalculateChanges(context.Background(), fakeStore, groupKey, []*models.AlertRuleWithOptionals{{AlertRule: *first}, {AlertRule: *second}})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorIs(t, err, models.ErrRuleDependencyCycle)
+	})
+
+	t.Run("should accept rules that depend on rules of the group", func(t *testing.T) {
+		groupKey := models.GenerateGroupKey(orgId)
+		inDatabase := gen.With(gen.WithGroupKey(groupKey)).GenerateManyRef(2, 2)
+		fakeStore := fakes.NewRuleStore(t)
+		fakeStore.PutRule(context.Background(), inDatabase...)
+
+		first := models.CopyRule(inDatabase[0], gen.WithDependsOn(inDatabase[1].UID))
+		second := models.CopyRule(inDatabase[1])
+
+		changes, err := CalculateChanges(context.Background(), fakeStore, groupKey, []*models.AlertRuleWithOptionals{{AlertRule: *first}, {AlertRule: *second}})
+		require.NoError(t, err)
+		require.NotEmpty(t, changes.Update)
+		for _, upd := range changes.Update {
+			if upd.New.UID == first.UID {
+				require.Equal(t, []string{inDatabase[1].UID}, upd.New.DependsOn)
+			}
+		}
+	})
+
+	t.Run("should fail if rule moved from another group leaves rules that depend on it", func(t *testing.T) {
+		groupKey := models.GenerateGroupKey(orgId)
+		otherGroupKey := models.GenerateGroupKey(orgId)
+		moved := gen.With(gen.WithGroupKey(otherGroupKey)).GenerateRef()
+		dependent := gen.With(gen.WithGroupKey(otherGroupKey), gen.WithDependsOn(moved.UID)).GenerateRef()
+		fakeStore := fakes.NewRuleStore(t)
+		fakeStore.PutRule(context.Background(), moved, dependent)
+
+		submitted := models.CopyRule(moved, gen.WithGroupKey(groupKey))
+
+		_, err := CalculateChanges(context.Background(), fakeStore, groupKey, []*models.AlertRuleWithOptionals{{AlertRule: *submitted}})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, dependent.Title)
+	})
 }
 
 func TestCalculateAutomaticChanges(t *testing.T) {
@@ -485,6 +546,19 @@ func TestCalculateRuleDelete(t *testing.T) {
 		assert.Len(t, delta.AffectedGroups, 1)
 		assert.Equal(t, models.RulesGroup(groupRules), delta.AffectedGroups[delta.GroupKey])
 	})
+
+	t.Run("should fail if other rules of the group depend on the rule", func(t *testing.T) {
+		groupRules := gen.With(gen.WithOrgID(rule.OrgID), gen.WithGroupKey(models.GenerateGroupKey(rule.OrgID))).GenerateManyRef(2, 2)
+		groupRules[1].DependsOn = []string{groupRules[0].UID}
+		fakeStore.Rules[rule.OrgID] = append(fakeStore.Rules[rule.OrgID], groupRules...)
+
+		_, err := CalculateRuleDelete(context.Background(), fakeStore, groupRules[0].GetKey())
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		delta, err := CalculateRuleDelete(context.Background(), fakeStore, groupRules[1].GetKey())
+		require.NoError(t, err)
+		require.Equal(t, []*models.AlertRule{groupRules[1]}, delta.Delete)
+	})
 }
 
 func TestCalculateRuleUpdate(t *testing.T) {
@@ -527,6 +601,27 @@ func TestCalculateRuleUpdate(t *testing.T) {
 		assert.Equal(t, models.RulesGroup(groupRules), delta.AffectedGroups[delta.GroupKey])
 	})
 
+	t.Run("should submit the updated rule instead of the stored one", func(t *testing.T) {
+		fakeStore.RecordedOps = nil
+		cp := models.CopyRule(rule)
+		cp.Title = "updated-title"
+
+		delta, err := CalculateRuleUpdate(context.Background(), fakeStore, &models.AlertRuleWithOptionals{
+			AlertRule: *cp,
+			HasPause:  false,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, delta.Update, 1)
+		assert.Equal(t, "updated-title", delta.Update[0].New.Title)
+		// The stored rule is not submitted as well, otherwise it would be looked up as a rule from another group.
+		lookups := fakeStore.GetRecordedCommands(func(cmd any) (any, bool) {
+			q, ok := cmd.(models.GetAlertRulesGroupByRuleUIDQuery)
+			return q, ok
+		})
+		assert.Empty(t, lookups)
+	})
+
 	t.Run("when a rule is moved between groups", func(t *testing.T) {
 		sourceGroupKey := rule.GetGroupKey()
 		targetGroupKey := models.GenerateGroupKey(rule.OrgID)
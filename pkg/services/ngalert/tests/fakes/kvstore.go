@@ -88,7 +88,7 @@ func (fkv *FakeKVStore) Keys(ctx context.Context, orgID int64, namespace string,
 					keys = append(keys, kvstore.Key{
 						OrgId:     orgIDFromStore,
 						Namespace: namespace,
-						Key:       keyPrefix,
+						Key:       k,
 					})
 				}
 			}
@@ -25,6 +25,8 @@ type RuleStore struct {
 	Hook        func(cmd any) error // use Hook if you need to intercept some query and return an error
 	RecordedOps []any
 	Folders     map[int64][]*folder.Folder
+	// OrgID -> Versions of the rules
+	Versions map[int64][]*models.AlertRuleVersion
 }
 
 type GenericRecordedQuery struct {
@@ -39,7 +41,8 @@ func NewRuleStore(t *testing.T) *RuleStore {
 		Hook: func(any) error {
 			return nil
 		},
-		Folders: map[int64][]*folder.Folder{},
+		Folders:  map[int64][]*folder.Folder{},
+		Versions: map[int64][]*models.AlertRuleVersion{},
 	}
 }
 
@@ -144,6 +147,29 @@ func (f *RuleStore) GetAlertRuleByUID(_ context.Context, q *models.GetAlertRuleB
 	return nil, models.ErrAlertRuleNotFound
 }
 
+// GetAlertRuleVersions returns the versions of the rule in the Versions map, the most recent first.
+func (f *RuleStore) GetAlertRuleVersions(_ context.Context, q *models.GetAlertRuleVersionsQuery) ([]*models.AlertRuleVersion, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.RecordedOps = append(f.RecordedOps, *q)
+	if err := f.Hook(*q); err != nil {
+		return nil, err
+	}
+	var result []*models.AlertRuleVersion
+	for _, v := range f.Versions[q.OrgID] {
+		if v.RuleUID == q.UID {
+			result = append(result, v)
+		}
+	}
+	if len(result) == 0 {
+		return nil, models.ErrAlertRuleNotFound
+	}
+	slices.SortFunc(result, func(a, b *models.AlertRuleVersion) int {
+		return int(b.Version - a.Version)
+	})
+	return result, nil
+}
+
 func (f *RuleStore) GetAlertRulesGroupByRuleUID(_ context.Context, q *models.GetAlertRulesGroupByRuleUIDQuery) ([]*models.AlertRule, error) {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -2,6 +2,7 @@ package fakes
 
 import (
 	"context"
+	"errors"
 
 	"golang.org/x/exp/maps"
 
@@ -18,6 +19,8 @@ type Call struct {
 type FakeSilenceStore struct {
 	Silences       map[string]*models.Silence
 	RuleUIDFolders map[string]string
+	Templates      map[string]*models.SilenceTemplate
+	Schedules      map[string]*models.SilenceSchedule
 
 	RecordedOps []GenericRecordedQuery
 }
@@ -60,3 +63,80 @@ func (s *FakeSilenceStore) DeleteSilence(ctx context.Context, orgID int64, id st
 	delete(s.Silences, id)
 	return nil
 }
+
+var (
+	errFakeSilenceTemplateNotFound = errors.New("silence template not found")
+	errFakeSilenceScheduleNotFound = errors.New("silence schedule not found")
+)
+
+func (s *FakeSilenceStore) ListSilenceTemplates(ctx context.Context, orgID int64) ([]*models.SilenceTemplate, error) {
+	s.RecordedOps = append(s.RecordedOps, GenericRecordedQuery{"ListSilenceTemplates", []interface{}{ctx, orgID}})
+	return maps.Values(s.Templates), nil
+}
+
+func (s *FakeSilenceStore) GetSilenceTemplate(ctx context.Context, orgID int64, uid string) (*models.SilenceTemplate, error) {
+	s.RecordedOps = append(s.RecordedOps, GenericRecordedQuery{"GetSilenceTemplate", []interface{}{ctx, orgID, uid}})
+	if t, ok := s.Templates[uid]; ok {
+		return t, nil
+	}
+	return nil, errFakeSilenceTemplateNotFound
+}
+
+func (s *FakeSilenceStore) SaveSilenceTemplate(ctx context.Context, orgID int64, t models.SilenceTemplate) (string, error) {
+	s.RecordedOps = append(s.RecordedOps, GenericRecordedQuery{"SaveSilenceTemplate", []interface{}{ctx, orgID, t}})
+	if t.UID == "" {
+		t.UID = util.GenerateShortUID()
+	} else if _, ok := s.Templates[t.UID]; !ok {
+		return "", errFakeSilenceTemplateNotFound
+	}
+	if s.Templates == nil {
+		s.Templates = map[string]*models.SilenceTemplate{}
+	}
+	s.Templates[t.UID] = &t
+	return t.UID, nil
+}
+
+func (s *FakeSilenceStore) DeleteSilenceTemplate(ctx context.Context, orgID int64, uid string) error {
+	s.RecordedOps = append(s.RecordedOps, GenericRecordedQuery{"DeleteSilenceTemplate", []interface{}{ctx, orgID, uid}})
+	if _, ok := s.Templates[uid]; !ok {
+		return errFakeSilenceTemplateNotFound
+	}
+	delete(s.Templates, uid)
+	return nil
+}
+
+func (s *FakeSilenceStore) ListSilenceSchedules(ctx context.Context, orgID int64) ([]*models.SilenceSchedule, error) {
+	s.RecordedOps = append(s.RecordedOps, GenericRecordedQuery{"ListSilenceSchedules", []interface{}{ctx, orgID}})
+	return maps.Values(s.Schedules), nil
+}
+
+func (s *FakeSilenceStore) GetSilenceSchedule(ctx context.Context, orgID int64, uid string) (*models.SilenceSchedule, error) {
+	s.RecordedOps = append(s.RecordedOps, GenericRecordedQuery{"GetSilenceSchedule", []interface{}{ctx, orgID, uid}})
+	if schedule, ok := s.Schedules[uid]; ok {
+		return schedule, nil
+	}
+	return nil, errFakeSilenceScheduleNotFound
+}
+
+func (s *FakeSilenceStore) SaveSilenceSchedule(ctx context.Context, orgID int64, schedule models.SilenceSchedule) (string, error) {
+	s.RecordedOps = append(s.RecordedOps, GenericRecordedQuery{"SaveSilenceSchedule", []interface{}{ctx, orgID, schedule}})
+	if schedule.UID == "" {
+		schedule.UID = util.GenerateShortUID()
+	} else if _, ok := s.Schedules[schedule.UID]; !ok {
+		return "", errFakeSilenceScheduleNotFound
+	}
+	if s.Schedules == nil {
+		s.Schedules = map[string]*models.SilenceSchedule{}
+	}
+	s.Schedules[schedule.UID] = &schedule
+	return schedule.UID, nil
+}
+
+func (s *FakeSilenceStore) DeleteSilenceSchedule(ctx context.Context, orgID int64, uid string) error {
+	s.RecordedOps = append(s.RecordedOps, GenericRecordedQuery{"DeleteSilenceSchedule", []interface{}{ctx, orgID, uid}})
+	if _, ok := s.Schedules[uid]; !ok {
+		return errFakeSilenceScheduleNotFound
+	}
+	delete(s.Schedules, uid)
+	return nil
+}
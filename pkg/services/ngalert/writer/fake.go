@@ -5,16 +5,18 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 type FakeWriter struct {
-	WriteFunc func(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+	WriteFunc func(ctx context.Context, orgID int64, record *models.Record, t time.Time, frames data.Frames, extraLabels map[string]string) error
 }
 
-func (w FakeWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+func (w FakeWriter) Write(ctx context.Context, orgID int64, record *models.Record, t time.Time, frames data.Frames, extraLabels map[string]string) error {
 	if w.WriteFunc == nil {
 		return nil
 	}
 
-	return w.WriteFunc(ctx, name, t, frames, extraLabels)
+	return w.WriteFunc(ctx, orgID, record, t, frames, extraLabels)
 }
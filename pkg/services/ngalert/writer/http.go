@@ -0,0 +1,51 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// httpTarget is an HTTP endpoint the results of recording rules are written to. The client sends the requests with
+// the authentication of the data source.
+type httpTarget struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+	logger  log.Logger
+}
+
+// post sends the body to the endpoint, and returns an error if the response status is not 2xx.
+func (h httpTarget) post(ctx context.Context, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, vs := range h.headers {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			h.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("request failed with status %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
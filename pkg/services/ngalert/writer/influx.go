@@ -0,0 +1,73 @@
+package writer
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+	influxTagEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+)
+
+// InfluxWriter writes the results of recording rules to an InfluxDB write endpoint, using the line protocol.
+// Each point is written to the measurement with the name of the metric, with the labels as tags and the value in the
+// field "value". The timestamps are written with a precision of seconds.
+type InfluxWriter struct {
+	target httpTarget
+}
+
+// Write writes the given frames to the InfluxDB write endpoint.
+func (w *InfluxWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+	body := encodeLineProtocol(points)
+	if len(body) == 0 {
+		return nil
+	}
+
+	w.target.logger.FromContext(ctx).Debug("Writing points", "points", len(points))
+	return w.target.post(ctx, "text/plain; charset=utf-8", body)
+}
+
+// encodeLineProtocol encodes the points in the InfluxDB line protocol. InfluxDB does not support NaN and infinite
+// values, so the points with such values are skipped.
+func encodeLineProtocol(points []Point) []byte {
+	var sb strings.Builder
+	for _, p := range points {
+		if math.IsNaN(p.Metric.V) || math.IsInf(p.Metric.V, 0) {
+			continue
+		}
+		sb.WriteString(influxMeasurementEscaper.Replace(p.Name))
+
+		keys := make([]string, 0, len(p.Labels))
+		for k, v := range p.Labels {
+			// Tags with empty values are not allowed.
+			if v != "" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sb.WriteByte(',')
+			sb.WriteString(influxTagEscaper.Replace(k))
+			sb.WriteByte('=')
+			sb.WriteString(influxTagEscaper.Replace(p.Labels[k]))
+		}
+
+		sb.WriteString(" value=")
+		sb.WriteString(strconv.FormatFloat(p.Metric.V, 'g', -1, 64))
+		sb.WriteByte(' ')
+		sb.WriteString(strconv.FormatInt(p.Metric.T, 10))
+		sb.WriteByte('\n')
+	}
+	return []byte(sb.String())
+}
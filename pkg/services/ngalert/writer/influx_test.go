@@ -0,0 +1,57 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestEncodeLineProtocol(t *testing.T) {
+	points := []Point{
+		{Name: "test metric", Labels: map[string]string{"b": "2", "a": "x,y=z", "empty": ""}, Metric: Metric{T: 1700000000, V: 1.5}},
+		{Name: "nan", Labels: map[string]string{"a": "1"}, Metric: Metric{T: 1700000000, V: math.NaN()}},
+		{Name: "inf", Labels: map[string]string{"a": "1"}, Metric: Metric{T: 1700000000, V: math.Inf(1)}},
+		{Name: "no_labels", Metric: Metric{T: 1700000001, V: 2}},
+	}
+
+	expected := "test\\ metric,a=x\\,y\\=z,b=2 value=1.5 1700000000\n" +
+		"no_labels value=2 1700000001\n"
+	require.Equal(t, expected, string(encodeLineProtocol(points)))
+}
+
+func TestInfluxWriter_Write(t *testing.T) {
+	var body string
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		body = string(b)
+		headers = r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	w := &InfluxWriter{target: httpTarget{
+		url:     server.URL,
+		headers: http.Header{"Authorization": []string{"Token secret"}},
+		client:  server.Client(),
+		logger:  log.NewNopLogger(),
+	}}
+
+	now := time.Unix(1700000000, 0)
+	frames := frameGenMulti(t, []map[string]string{{"a": "b"}})
+	require.NoError(t, w.Write(context.Background(), "test_metric", now, frames, map[string]string{"extra": "label"}))
+
+	require.Contains(t, body, "test_metric,a=b,extra=label value=")
+	require.Contains(t, body, " 1700000000\n")
+	require.Equal(t, "Token secret", headers.Get("Authorization"))
+	require.Equal(t, "text/plain; charset=utf-8", headers.Get("Content-Type"))
+}
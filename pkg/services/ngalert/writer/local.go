@@ -0,0 +1,158 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/util/annotations"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// localOrgIDLabel is the label of the series that contains the ID of the organization of the recording rule. The
+// series of an organization are only read by the queries of this organization.
+const localOrgIDLabel = "__grafana_org_id__"
+
+// LocalWriter writes the results of recording rules to an embedded Prometheus time series database, stored in the
+// data path of Grafana. It is intended for small installations without a remote write endpoint. The series are read
+// with Queryable.
+type LocalWriter struct {
+	db     *tsdb.DB
+	logger log.Logger
+}
+
+func NewLocalWriter(settings setting.RecordingRuleSettings, l log.Logger) (*LocalWriter, error) {
+	opts := tsdb.DefaultOptions()
+	opts.RetentionDuration = settings.LocalTSDBRetention.Milliseconds()
+
+	db, err := tsdb.Open(settings.LocalTSDBPath, l, nil, opts, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local time series database: %w", err)
+	}
+
+	return &LocalWriter{
+		db:     db,
+		logger: l,
+	}, nil
+}
+
+// ForOrg returns a writer that appends the results of the recording rules of the organization to the database.
+func (w *LocalWriter) ForOrg(orgID int64) Writer {
+	return localOrgWriter{w: w, orgID: orgID}
+}
+
+// Queryable returns the series written by the recording rules of the organization. The label with the organization
+// is not part of the returned series.
+func (w *LocalWriter) Queryable(orgID int64) storage.Queryable {
+	matcher := labels.MustNewMatcher(labels.MatchEqual, localOrgIDLabel, strconv.FormatInt(orgID, 10))
+	return storage.QueryableFunc(func(mint, maxt int64) (storage.Querier, error) {
+		q, err := w.db.Querier(mint, maxt)
+		if err != nil {
+			return nil, err
+		}
+		return orgQuerier{Querier: q, matcher: matcher}, nil
+	})
+}
+
+// write appends the given frames of a recording rule of the organization to the database.
+func (w *LocalWriter) write(ctx context.Context, orgID int64, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	app := w.db.Appender(ctx)
+	for _, p := range points {
+		lbls := make(map[string]string, len(p.Labels)+2)
+		for k, v := range p.Labels {
+			lbls[k] = v
+		}
+		lbls[labels.MetricName] = p.Name
+		lbls[localOrgIDLabel] = strconv.FormatInt(orgID, 10)
+
+		if _, err := app.Append(0, labels.FromMap(lbls), time.Unix(p.Metric.T, 0).UnixMilli(), p.Metric.V); err != nil {
+			if rerr := app.Rollback(); rerr != nil {
+				w.logger.Warn("Failed to roll back append", "error", rerr)
+			}
+			return fmt.Errorf("failed to append sample: %w", err)
+		}
+	}
+	if err := app.Commit(); err != nil {
+		return fmt.Errorf("failed to commit samples: %w", err)
+	}
+
+	w.logger.FromContext(ctx).Debug("Appended points", "points", len(points))
+	return nil
+}
+
+// Close closes the database.
+func (w *LocalWriter) Close() error {
+	return w.db.Close()
+}
+
+type localOrgWriter struct {
+	w     *LocalWriter
+	orgID int64
+}
+
+func (o localOrgWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	return o.w.write(ctx, o.orgID, name, t, frames, extraLabels)
+}
+
+// orgQuerier only selects the series of an organization, and removes the label with the organization from them.
+type orgQuerier struct {
+	storage.Querier
+	matcher *labels.Matcher
+}
+
+func (q orgQuerier) Select(ctx context.Context, sortSeries bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	return orgSeriesSet{SeriesSet: q.Querier.Select(ctx, sortSeries, hints, q.withOrg(matchers)...)}
+}
+
+func (q orgQuerier) LabelValues(ctx context.Context, name string, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	if name == localOrgIDLabel {
+		return nil, nil, nil
+	}
+	return q.Querier.LabelValues(ctx, name, q.withOrg(matchers)...)
+}
+
+func (q orgQuerier) LabelNames(ctx context.Context, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	names, warnings, err := q.Querier.LabelNames(ctx, q.withOrg(matchers)...)
+	if err != nil {
+		return nil, warnings, err
+	}
+	return slices.DeleteFunc(names, func(name string) bool { return name == localOrgIDLabel }), warnings, nil
+}
+
+func (q orgQuerier) withOrg(matchers []*labels.Matcher) []*labels.Matcher {
+	return append(slices.Clone(matchers), q.matcher)
+}
+
+type orgSeriesSet struct {
+	storage.SeriesSet
+}
+
+func (s orgSeriesSet) At() storage.Series {
+	return orgSeries{Series: s.SeriesSet.At()}
+}
+
+type orgSeries struct {
+	storage.Series
+}
+
+// Labels returns the labels of the series without the organization. Since all the selected series have the same
+// organization, removing the label does not change their order.
+func (s orgSeries) Labels() labels.Labels {
+	return labels.NewBuilder(s.Series.Labels()).Del(localOrgIDLabel).Labels()
+}
@@ -0,0 +1,53 @@
+package writer
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLocalWriter(t *testing.T) {
+	w, err := NewLocalWriter(setting.RecordingRuleSettings{LocalTSDBPath: t.TempDir(), LocalTSDBRetention: time.Hour}, log.NewNopLogger())
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, w.Close()) })
+
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, w.ForOrg(1).Write(ctx, "test_metric", now, frameGenMulti(t, []map[string]string{{"a": "1"}}), map[string]string{"extra": "x"}))
+	require.NoError(t, w.ForOrg(2).Write(ctx, "test_metric", now, frameGenMulti(t, []map[string]string{{"a": "2"}}), nil))
+
+	q, err := w.Queryable(1).Querier(math.MinInt64, math.MaxInt64)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, q.Close()) })
+
+	t.Run("only selects the series of the organization, without the organization label", func(t *testing.T) {
+		set := q.Select(ctx, true, nil, labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "test_metric"))
+		var series []labels.Labels
+		for set.Next() {
+			series = append(series, set.At().Labels())
+		}
+		require.NoError(t, set.Err())
+		require.Equal(t, []labels.Labels{labels.FromStrings(labels.MetricName, "test_metric", "a", "1", "extra", "x")}, series)
+	})
+
+	t.Run("only returns the labels of the organization", func(t *testing.T) {
+		names, _, err := q.LabelNames(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{labels.MetricName, "a", "extra"}, names)
+
+		values, _, err := q.LabelValues(ctx, "a")
+		require.NoError(t, err)
+		require.Equal(t, []string{"1"}, values)
+
+		values, _, err = q.LabelValues(ctx, localOrgIDLabel)
+		require.NoError(t, err)
+		require.Empty(t, values)
+	})
+}
@@ -0,0 +1,70 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// LokiMetricLabel is the stream label with the name of the metric of the results written to Loki.
+const LokiMetricLabel = "metric"
+
+// LokiWriter writes the results of recording rules to the push endpoint of Loki, so that they can be queried as
+// metrics from logs. Each series is written to the stream with its labels and the metric label, as a logfmt line with
+// the value, which can be queried with, for example:
+//
+//	avg_over_time({metric="my_metric"} | logfmt | unwrap value [1m])
+type LokiWriter struct {
+	target httpTarget
+}
+
+type lokiPushRequest struct {
+	Streams []lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// Write writes the given frames to the Loki push endpoint.
+func (w *LokiWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(pointsToLokiPushRequest(points))
+	if err != nil {
+		return fmt.Errorf("failed to marshal push request: %w", err)
+	}
+
+	w.target.logger.FromContext(ctx).Debug("Writing points", "points", len(points))
+	return w.target.post(ctx, "application/json", body)
+}
+
+func pointsToLokiPushRequest(points []Point) lokiPushRequest {
+	req := lokiPushRequest{Streams: make([]lokiStream, 0, len(points))}
+	for _, p := range points {
+		stream := make(map[string]string, len(p.Labels)+1)
+		for k, v := range p.Labels {
+			stream[k] = v
+		}
+		stream[LokiMetricLabel] = p.Name
+
+		ts := strconv.FormatInt(time.Unix(p.Metric.T, 0).UnixNano(), 10)
+		line := "value=" + strconv.FormatFloat(p.Metric.V, 'g', -1, 64)
+		req.Streams = append(req.Streams, lokiStream{
+			Stream: stream,
+			Values: [][2]string{{ts, line}},
+		})
+	}
+	return req
+}
@@ -0,0 +1,51 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestLokiWriter_Write(t *testing.T) {
+	var received lokiPushRequest
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	w := &LokiWriter{target: httpTarget{
+		url:    server.URL + lokiPushPath,
+		client: server.Client(),
+		logger: log.NewNopLogger(),
+	}}
+
+	now := time.Unix(1700000000, 0)
+	frames := frameGenMulti(t, []map[string]string{{"a": "b"}})
+
+	t.Run("pushes a stream per series", func(t *testing.T) {
+		require.NoError(t, w.Write(context.Background(), "test_metric", now, frames, map[string]string{"extra": "label"}))
+
+		require.Len(t, received.Streams, 1)
+		stream := received.Streams[0]
+		require.Equal(t, map[string]string{"a": "b", "extra": "label", LokiMetricLabel: "test_metric"}, stream.Stream)
+		require.Len(t, stream.Values, 1)
+		require.Equal(t, "1700000000000000000", stream.Values[0][0])
+		require.Contains(t, stream.Values[0][1], "value=")
+	})
+
+	t.Run("returns error on failure status", func(t *testing.T) {
+		status = http.StatusBadRequest
+		err := w.Write(context.Background(), "test_metric", now, frames, nil)
+		require.ErrorContains(t, err, "status 400")
+	})
+}
@@ -5,10 +5,22 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 type NoopWriter struct{}
 
-func (w NoopWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+func (w NoopWriter) Write(ctx context.Context, orgID int64, record *models.Record, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	return nil
+}
+
+func (w NoopWriter) ValidateTarget(ctx context.Context, user identity.Requester, orgID int64, target *models.RecordTarget) error {
 	return nil
 }
+
+func (w NoopWriter) LocalQueryable(orgID int64) (storage.Queryable, bool) {
+	return nil, false
+}
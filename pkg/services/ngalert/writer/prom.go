@@ -1,19 +1,29 @@
 package writer
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
 	"github.com/grafana/dataplane/sdata/numeric"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/prometheus/prometheus/prompb"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
 // Metric represents a Prometheus time series metric.
 type Metric struct {
+	// T is the Unix time of the metric in seconds.
 	T int64
 	V float64
 }
@@ -72,29 +82,118 @@ func PointsFromFrames(name string, t time.Time, frames data.Frames, extraLabels
 }
 
 type PrometheusWriter struct {
-	logger log.Logger
+	url      string
+	username string
+	password string
+	headers  map[string]string
+	client   *http.Client
+	logger   log.Logger
 }
 
 func NewPrometheusWriter(
 	settings setting.RecordingRuleSettings,
 	l log.Logger,
 ) (*PrometheusWriter, error) {
+	if settings.URL != "" {
+		if _, err := url.Parse(settings.URL); err != nil {
+			return nil, fmt.Errorf("failed to parse remote write URL: %w", err)
+		}
+	}
+
 	return &PrometheusWriter{
-		logger: l,
+		url:      settings.URL,
+		username: settings.BasicAuthUsername,
+		password: settings.BasicAuthPassword,
+		headers:  settings.CustomHeaders,
+		client:   &http.Client{Timeout: settings.Timeout},
+		logger:   l,
 	}, nil
 }
 
 // Write writes the given frames to the Prometheus remote write endpoint.
-// TODO: stub implementation, does not make any remote write calls.
 func (w PrometheusWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+
+	return w.WritePoints(ctx, points)
+}
+
+// WritePoints writes the given points to the Prometheus remote write endpoint.
+// If no remote write URL is configured, the points are only logged, as recording rules did before remote write
+// was supported.
+func (w PrometheusWriter) WritePoints(ctx context.Context, points []Point) error {
 	l := w.logger.FromContext(ctx)
+	if len(points) == 0 {
+		return nil
+	}
+	if w.url == "" {
+		l.Debug("Skip writing points because no remote write URL is configured", "points", len(points))
+		return nil
+	}
 
-	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	body, err := encodeWriteRequest(points)
 	if err != nil {
 		return err
 	}
 
-	// TODO: placeholder for actual remote write call
-	l.Debug("writing points", "points", points)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create remote write request: %w", err)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	if w.username != "" || w.password != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	l.Debug("Writing points", "points", len(points))
+	res, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send remote write request: %w", err)
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			l.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("remote write request failed with status %d: %s", res.StatusCode, strings.TrimSpace(string(msg)))
+	}
 	return nil
 }
+
+// encodeWriteRequest encodes the points as a snappy-compressed remote write request.
+func encodeWriteRequest(points []Point) ([]byte, error) {
+	series := make([]prompb.TimeSeries, 0, len(points))
+	for _, p := range points {
+		labels := make([]prompb.Label, 0, len(p.Labels)+1)
+		labels = append(labels, prompb.Label{Name: "__name__", Value: p.Name})
+		for k, v := range p.Labels {
+			labels = append(labels, prompb.Label{Name: k, Value: v})
+		}
+		// Remote write receivers expect the labels to be sorted by name.
+		sort.Slice(labels, func(i, j int) bool {
+			return labels[i].Name < labels[j].Name
+		})
+
+		series = append(series, prompb.TimeSeries{
+			Labels: labels,
+			// Remote write timestamps are in milliseconds.
+			Samples: []prompb.Sample{{Timestamp: p.Metric.T * 1000, Value: p.Metric.V}},
+		})
+	}
+
+	raw, err := proto.Marshal(&prompb.WriteRequest{Timeseries: series})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal remote write request: %w", err)
+	}
+	return snappy.Encode(nil, raw), nil
+}
@@ -1,19 +1,91 @@
 package writer
 
 import (
+	"context"
+	"io"
 	"math"
 	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"slices"
 	"testing"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestPrometheusWriter_Write(t *testing.T) {
-	t.Skip("TODO: implement")
+	var received *prompb.WriteRequest
+	var headers http.Header
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		raw, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		received = &prompb.WriteRequest{}
+		require.NoError(t, proto.Unmarshal(raw, received))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	w, err := NewPrometheusWriter(setting.RecordingRuleSettings{
+		URL:               server.URL,
+		BasicAuthUsername: "user",
+		BasicAuthPassword: "pass",
+		CustomHeaders:     map[string]string{"X-Scope-OrgID": "tenant"},
+		Timeout:           time.Second,
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	frames := frameGenMulti(t, []map[string]string{{"a": "b"}})
+
+	t.Run("sends the points", func(t *testing.T) {
+		err := w.Write(context.Background(), "test_metric", now, frames, map[string]string{"extra": "label"})
+		require.NoError(t, err)
+
+		require.Equal(t, "snappy", headers.Get("Content-Encoding"))
+		require.Equal(t, "tenant", headers.Get("X-Scope-OrgID"))
+		require.Equal(t, "Basic dXNlcjpwYXNz", headers.Get("Authorization"))
+
+		require.Len(t, received.Timeseries, 1)
+		series := received.Timeseries[0]
+		require.Equal(t, []prompb.Label{
+			{Name: "__name__", Value: "test_metric"},
+			{Name: "a", Value: "b"},
+			{Name: "extra", Value: "label"},
+		}, series.Labels)
+		require.Len(t, series.Samples, 1)
+		require.Equal(t, now.UnixMilli(), series.Samples[0].Timestamp)
+	})
+
+	t.Run("returns error on failure status", func(t *testing.T) {
+		status = http.StatusBadRequest
+		err := w.Write(context.Background(), "test_metric", now, frames, nil)
+		require.ErrorContains(t, err, "status 400")
+	})
+
+	t.Run("does not send empty writes", func(t *testing.T) {
+		received = nil
+		require.NoError(t, w.WritePoints(context.Background(), nil))
+		require.Nil(t, received)
+	})
+
+	t.Run("does nothing without remote write URL", func(t *testing.T) {
+		w, err := NewPrometheusWriter(setting.RecordingRuleSettings{}, log.NewNopLogger())
+		require.NoError(t, err)
+		require.NoError(t, w.Write(context.Background(), "test_metric", now, frames, nil))
+	})
 }
 
 func TestPointsFromFrames(t *testing.T) {
@@ -0,0 +1,368 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	authz "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Writer writes the results of a recording rule.
+type Writer interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error
+}
+
+const (
+	lokiPushPath             = "/loki/api/v1/push"
+	influxDBWritePath        = "/write"
+	influxDBWriteV2Path      = "/api/v2/write"
+	influxDBWritePrecision   = "s"
+	influxDBWriteV2Precision = "second"
+	influxDBFluxVersion      = "Flux"
+	influxDBSQLVersion       = "SQL"
+
+	// datasourceWriterCacheSize is the maximum number of cached data source writers.
+	datasourceWriterCacheSize = 100
+)
+
+var (
+	ErrLocalTSDBDisabled     = errors.New("the local time series database is not enabled")
+	ErrUnsupportedDatasource = errors.New("data source does not support writing recording rule results")
+
+	errSQLTableRequired     = errors.New("a table is required to write to SQL data sources")
+	errSQLTableNotSupported = errors.New("a table can only be set for SQL data sources")
+
+	sqlDatasourceTypes = []string{datasources.DS_MYSQL, datasources.DS_MSSQL, datasources.DS_POSTGRES, "postgres"}
+)
+
+// Router writes the results of recording rules to the target of each rule: the remote write endpoint configured for
+// the instance, the embedded time series database, or a data source.
+// The writers of the data sources are cached, and recreated when the data source is updated.
+type Router struct {
+	remoteWrite Writer
+	local       *LocalWriter
+	datasources datasources.DataSourceService
+	ac          accesscontrol.AccessControl
+	// clientProvider creates the HTTP transports of the data sources that are written to over HTTP.
+	clientProvider httpclient.Provider
+	proxy          setting.SecureSocksDSProxySettings
+	// dataPath is the directory the certificates of the SQL data sources are written to, for the drivers that only read them from files.
+	dataPath string
+	timeout  time.Duration
+	logger   log.Logger
+
+	mtx     sync.Mutex
+	writers map[datasourceWriterKey]*datasourceWriter
+}
+
+type datasourceWriterKey struct {
+	orgID int64
+	uid   string
+	table string
+}
+
+type datasourceWriter struct {
+	version int
+	writer  Writer
+	// refs is the number of writes in progress. A writer that is removed from the cache is closed once they are done.
+	refs    int
+	removed bool
+}
+
+// NewRouter creates a router. The local writer can be nil if the local time series database is disabled.
+func NewRouter(remoteWrite Writer, local *LocalWriter, ds datasources.DataSourceService, ac accesscontrol.AccessControl, cfg *setting.Cfg, l log.Logger) *Router {
+	return &Router{
+		remoteWrite:    remoteWrite,
+		local:          local,
+		datasources:    ds,
+		ac:             ac,
+		clientProvider: httpclient.NewProvider(),
+		proxy:          cfg.SecureSocksDSProxy,
+		dataPath:       cfg.DataPath,
+		timeout:        cfg.UnifiedAlerting.RecordingRules.Timeout,
+		logger:         l,
+		writers:        make(map[datasourceWriterKey]*datasourceWriter),
+	}
+}
+
+// Write writes the results of the recording rule to its target.
+func (r *Router) Write(ctx context.Context, orgID int64, record *models.Record, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	w, release, err := r.writerFor(ctx, orgID, record.Target)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return w.Write(ctx, record.Metric, t, frames, extraLabels)
+}
+
+// ValidateTarget checks that the results of recording rules can be written to the target, and that the user is
+// allowed to write to the data source of the target.
+func (r *Router) ValidateTarget(ctx context.Context, user identity.Requester, orgID int64, target *models.RecordTarget) error {
+	if target == nil {
+		return nil
+	}
+	if err := target.Validate(); err != nil {
+		return err
+	}
+	switch target.Type {
+	case models.RecordTargetLocal:
+		if r.local == nil {
+			return ErrLocalTSDBDisabled
+		}
+	case models.RecordTargetDatasource:
+		eval := accesscontrol.EvalPermission(datasources.ActionWrite, datasources.ScopeProvider.GetResourceScopeUID(target.DatasourceUID))
+		ok, err := r.ac.Evaluate(ctx, user, eval)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return authz.NewAuthorizationErrorWithPermissions(fmt.Sprintf("write the results of recording rules to data source %s", target.DatasourceUID), eval)
+		}
+		ds, err := r.datasources.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: target.DatasourceUID, OrgID: orgID})
+		if err != nil {
+			return fmt.Errorf("failed to get data source %s: %w", target.DatasourceUID, err)
+		}
+		return validateDatasourceTarget(ds, target)
+	}
+	return nil
+}
+
+// LocalQueryable returns the series written by the recording rules of the organization to the local time series
+// database, or false if the local time series database is disabled.
+func (r *Router) LocalQueryable(orgID int64) (storage.Queryable, bool) {
+	if r.local == nil {
+		return nil, false
+	}
+	return r.local.Queryable(orgID), true
+}
+
+// Run closes the writers when the context is done.
+func (r *Router) Run(ctx context.Context) error {
+	<-ctx.Done()
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for key, w := range r.writers {
+		r.remove(key, w)
+	}
+	if r.local != nil {
+		if err := r.local.Close(); err != nil {
+			r.logger.Warn("Failed to close local time series database", "error", err)
+		}
+	}
+	return nil
+}
+
+// writerFor returns the writer of the target, and a function that must be called once the write is done.
+func (r *Router) writerFor(ctx context.Context, orgID int64, target *models.RecordTarget) (Writer, func(), error) {
+	if target == nil {
+		return r.remoteWrite, func() {}, nil
+	}
+	switch target.Type {
+	case models.RecordTargetRemoteWrite:
+		return r.remoteWrite, func() {}, nil
+	case models.RecordTargetLocal:
+		if r.local == nil {
+			return nil, nil, ErrLocalTSDBDisabled
+		}
+		return r.local.ForOrg(orgID), func() {}, nil
+	case models.RecordTargetDatasource:
+		w, err := r.datasourceWriter(ctx, orgID, target)
+		if err != nil {
+			return nil, nil, err
+		}
+		return w.writer, func() { r.release(w) }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown target type %q", target.Type)
+	}
+}
+
+// datasourceWriter returns the cached writer of the data source, or creates it. The writer must be released once the
+// write is done, so that it is not closed while in use.
+func (r *Router) datasourceWriter(ctx context.Context, orgID int64, target *models.RecordTarget) (*datasourceWriter, error) {
+	ds, err := r.datasources.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: target.DatasourceUID, OrgID: orgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data source %s: %w", target.DatasourceUID, err)
+	}
+	if err := validateDatasourceTarget(ds, target); err != nil {
+		return nil, err
+	}
+
+	key := datasourceWriterKey{orgID: orgID, uid: ds.UID, table: target.Table}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if cached, ok := r.writers[key]; ok {
+		if cached.version == ds.Version {
+			cached.refs++
+			return cached, nil
+		}
+		r.remove(key, cached)
+	}
+
+	w, err := r.newDatasourceWriter(ctx, ds, target)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.writers) >= datasourceWriterCacheSize {
+		// The cache is only meant to avoid recreating the writers at every evaluation, so it is simply reset when full.
+		for k, cached := range r.writers {
+			r.remove(k, cached)
+		}
+	}
+	cached := &datasourceWriter{version: ds.Version, writer: w, refs: 1}
+	r.writers[key] = cached
+	return cached, nil
+}
+
+// remove removes the writer from the cache, and closes it unless writes are in progress. It must be called with r.mtx held.
+func (r *Router) remove(key datasourceWriterKey, w *datasourceWriter) {
+	delete(r.writers, key)
+	w.removed = true
+	if w.refs == 0 {
+		closeWriter(w.writer, r.logger)
+	}
+}
+
+// release releases a writer returned by datasourceWriter, and closes it if it was removed from the cache in the meantime.
+func (r *Router) release(w *datasourceWriter) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	w.refs--
+	if w.removed && w.refs == 0 {
+		closeWriter(w.writer, r.logger)
+	}
+}
+
+func (r *Router) newDatasourceWriter(ctx context.Context, ds *datasources.DataSource, target *models.RecordTarget) (Writer, error) {
+	logger := r.logger.New("datasource_uid", ds.UID, "datasource_type", ds.Type)
+	jsonData := ds.JsonData
+	if jsonData == nil {
+		jsonData = simplejson.New()
+	}
+
+	switch {
+	case isSQLDatasource(ds.Type):
+		db, driver, err := r.openSQLDatasource(ctx, ds, jsonData)
+		if err != nil {
+			return nil, err
+		}
+		return NewSQLWriter(db, driver, target.Table, logger), nil
+	case ds.Type == datasources.DS_INFLUXDB:
+		return r.newInfluxWriter(ctx, ds, jsonData, logger)
+	case ds.Type == datasources.DS_LOKI:
+		t, err := r.newHTTPTarget(ctx, ds, lokiPushPath, logger)
+		if err != nil {
+			return nil, err
+		}
+		return &LokiWriter{target: t}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDatasource, ds.Type)
+	}
+}
+
+func (r *Router) newInfluxWriter(ctx context.Context, ds *datasources.DataSource, jsonData *simplejson.Json, logger log.Logger) (*InfluxWriter, error) {
+	version := jsonData.Get("version").MustString("")
+	if version != influxDBFluxVersion && version != influxDBSQLVersion {
+		t, err := r.newHTTPTarget(ctx, ds, influxDBWritePath, logger)
+		if err != nil {
+			return nil, err
+		}
+		q := url.Values{}
+		q.Set("db", jsonData.Get("dbName").MustString(ds.Database))
+		q.Set("precision", influxDBWritePrecision)
+		t.url += "?" + q.Encode()
+		return &InfluxWriter{target: t}, nil
+	}
+
+	t, err := r.newHTTPTarget(ctx, ds, influxDBWriteV2Path, logger)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	if version == influxDBFluxVersion {
+		q.Set("org", jsonData.Get("organization").MustString(""))
+		q.Set("bucket", jsonData.Get("defaultBucket").MustString(""))
+	} else {
+		q.Set("bucket", jsonData.Get("dbName").MustString(ds.Database))
+	}
+	q.Set("precision", influxDBWriteV2Precision)
+	t.url += "?" + q.Encode()
+	token, ok, err := r.datasources.DecryptedValue(ctx, ds, "token")
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data source token: %w", err)
+	}
+	if ok && token != "" {
+		t.headers.Set("Authorization", "Token "+token)
+	}
+	return &InfluxWriter{target: t}, nil
+}
+
+// newHTTPTarget returns the endpoint at the given path of the data source. The requests are sent with the HTTP client
+// options of the data source, like its queries: the TLS settings, the secure socks proxy, the basic authentication or
+// the user and password, and the custom headers.
+func (r *Router) newHTTPTarget(ctx context.Context, ds *datasources.DataSource, path string, logger log.Logger) (httpTarget, error) {
+	u, err := url.Parse(ds.URL)
+	if err != nil {
+		return httpTarget{}, fmt.Errorf("failed to parse data source URL: %w", err)
+	}
+	u = u.JoinPath(path)
+
+	transport, err := r.datasources.GetHTTPTransport(ctx, ds, r.clientProvider)
+	if err != nil {
+		return httpTarget{}, fmt.Errorf("failed to create data source HTTP transport: %w", err)
+	}
+	return httpTarget{
+		url:     u.String(),
+		headers: http.Header{},
+		client:  &http.Client{Transport: transport, Timeout: r.timeout},
+		logger:  logger,
+	}, nil
+}
+
+func validateDatasourceTarget(ds *datasources.DataSource, target *models.RecordTarget) error {
+	if !isSupportedDatasource(ds.Type) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedDatasource, ds.Type)
+	}
+	if isSQLDatasource(ds.Type) {
+		if target.Table == "" {
+			return errSQLTableRequired
+		}
+	} else if target.Table != "" {
+		return errSQLTableNotSupported
+	}
+	return nil
+}
+
+func isSupportedDatasource(dsType string) bool {
+	return isSQLDatasource(dsType) || dsType == datasources.DS_INFLUXDB || dsType == datasources.DS_LOKI
+}
+
+func isSQLDatasource(dsType string) bool {
+	return slices.Contains(sqlDatasourceTypes, dsType)
+}
+
+func closeWriter(w Writer, l log.Logger) {
+	if c, ok := w.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			l.Warn("Failed to close recording rule writer", "error", err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package writer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sdkhttpclient "github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	fakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	authz "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestRouter_ValidateTarget(t *testing.T) {
+	ds := &fakes.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{UID: "influx", OrgID: 1, Type: datasources.DS_INFLUXDB},
+		{UID: "mysql", OrgID: 1, Type: datasources.DS_MYSQL},
+		{UID: "prom", OrgID: 1, Type: datasources.DS_PROMETHEUS},
+		{UID: "forbidden", OrgID: 1, Type: datasources.DS_MYSQL},
+		{UID: "query-only", OrgID: 1, Type: datasources.DS_MYSQL},
+	}}
+	router := NewRouter(&recordingWriter{}, nil, ds, acimpl.ProvideAccessControl(featuremgmt.WithFeatures()), &setting.Cfg{}, log.NewNopLogger())
+	usr := &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: {
+		datasources.ActionWrite: {
+			datasources.ScopeProvider.GetResourceScopeUID("influx"),
+			datasources.ScopeProvider.GetResourceScopeUID("mysql"),
+			datasources.ScopeProvider.GetResourceScopeUID("prom"),
+			datasources.ScopeProvider.GetResourceScopeUID("missing"),
+		},
+		datasources.ActionQuery: {
+			datasources.ScopeProvider.GetResourceScopeUID("query-only"),
+		},
+	}}}
+
+	testCases := []struct {
+		name   string
+		target *models.RecordTarget
+		err    error
+		errMsg string
+	}{
+		{name: "no target", target: nil},
+		{name: "remote write", target: &models.RecordTarget{Type: models.RecordTargetRemoteWrite}},
+		{name: "local disabled", target: &models.RecordTarget{Type: models.RecordTargetLocal}, err: ErrLocalTSDBDisabled},
+		{name: "influxdb", target: &models.RecordTarget{Type: models.RecordTargetDatasource, DatasourceUID: "influx"}},
+		{name: "influxdb with table", target: &models.RecordTarget{Type: models.RecordTargetDatasource, DatasourceUID: "influx", Table: "t"}, err: errSQLTableNotSupported},
+		{name: "sql", target: &models.RecordTarget{Type: models.RecordTargetDatasource, DatasourceUID: "mysql", Table: "t"}},
+		{name: "sql without table", target: &models.RecordTarget{Type: models.RecordTargetDatasource, DatasourceUID: "mysql"}, err: errSQLTableRequired},
+		{name: "unsupported data source", target: &models.RecordTarget{Type: models.RecordTargetDatasource, DatasourceUID: "prom"}, err: ErrUnsupportedDatasource},
+		{name: "missing data source", target: &models.RecordTarget{Type: models.RecordTargetDatasource, DatasourceUID: "missing"}, errMsg: "failed to get data source"},
+		{name: "data source without permission", target: &models.RecordTarget{Type: models.RecordTargetDatasource, DatasourceUID: "forbidden", Table: "t"}, err: authz.ErrAuthorizationBase},
+		{name: "data source with query permission only", target: &models.RecordTarget{Type: models.RecordTargetDatasource, DatasourceUID: "query-only", Table: "t"}, err: authz.ErrAuthorizationBase},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := router.ValidateTarget(context.Background(), usr, 1, tc.target)
+			switch {
+			case tc.err != nil:
+				require.ErrorIs(t, err, tc.err)
+			case tc.errMsg != "":
+				require.ErrorContains(t, err, tc.errMsg)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRouter_Write(t *testing.T) {
+	remoteWrite := &recordingWriter{}
+	router := NewRouter(remoteWrite, nil, &fakes.FakeDataSourceService{}, acimpl.ProvideAccessControl(featuremgmt.WithFeatures()), &setting.Cfg{}, log.NewNopLogger())
+
+	t.Run("writes to remote write without target", func(t *testing.T) {
+		err := router.Write(context.Background(), 1, &models.Record{Metric: "test_metric"}, time.Now(), nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, "test_metric", remoteWrite.name)
+	})
+
+	t.Run("fails for local target when disabled", func(t *testing.T) {
+		record := &models.Record{Metric: "test_metric", Target: &models.RecordTarget{Type: models.RecordTargetLocal}}
+		err := router.Write(context.Background(), 1, record, time.Now(), nil, nil)
+		require.ErrorIs(t, err, ErrLocalTSDBDisabled)
+	})
+}
+
+func TestRouter_HTTPDatasourceTransport(t *testing.T) {
+	var received http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	ds := &transportDataSourceService{FakeDataSourceService: fakes.FakeDataSourceService{DataSources: []*datasources.DataSource{
+		{UID: "loki", OrgID: 1, Type: datasources.DS_LOKI, URL: server.URL},
+	}}}
+	router := NewRouter(&recordingWriter{}, nil, ds, acimpl.ProvideAccessControl(featuremgmt.WithFeatures()), &setting.Cfg{}, log.NewNopLogger())
+
+	record := &models.Record{Metric: "test_metric", Target: &models.RecordTarget{Type: models.RecordTargetDatasource, DatasourceUID: "loki"}}
+	frames := frameGenMulti(t, []map[string]string{{"a": "b"}})
+	require.NoError(t, router.Write(context.Background(), 1, record, time.Now(), frames, nil))
+	require.Equal(t, "loki", ds.transportFor)
+	require.Equal(t, "transport", received.Get("X-Datasource-Transport"), "the request should be sent with the transport of the data source")
+}
+
+// transportDataSourceService returns a transport that adds a header to the requests, to check that the writers of the
+// data sources use the HTTP client options of the data source.
+type transportDataSourceService struct {
+	fakes.FakeDataSourceService
+	transportFor string
+}
+
+func (s *transportDataSourceService) GetHTTPTransport(_ context.Context, ds *datasources.DataSource, _ httpclient.Provider, _ ...sdkhttpclient.Middleware) (http.RoundTripper, error) {
+	s.transportFor = ds.UID
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req.Header.Set("X-Datasource-Transport", "transport")
+		return http.DefaultTransport.RoundTrip(req)
+	}), nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRouter_DatasourceWriterRelease(t *testing.T) {
+	router := NewRouter(&recordingWriter{}, nil, &fakes.FakeDataSourceService{}, acimpl.ProvideAccessControl(featuremgmt.WithFeatures()), &setting.Cfg{}, log.NewNopLogger())
+	key := datasourceWriterKey{orgID: 1, uid: "ds"}
+
+	t.Run("writer in use is closed once released", func(t *testing.T) {
+		w := &closingWriter{}
+		cached := &datasourceWriter{writer: w, refs: 1}
+		router.writers[key] = cached
+
+		router.mtx.Lock()
+		router.remove(key, cached)
+		router.mtx.Unlock()
+		require.False(t, w.closed)
+		require.NotContains(t, router.writers, key)
+
+		router.release(cached)
+		require.True(t, w.closed)
+	})
+
+	t.Run("unused writer is closed when removed", func(t *testing.T) {
+		w := &closingWriter{}
+		cached := &datasourceWriter{writer: w}
+		router.writers[key] = cached
+
+		router.mtx.Lock()
+		router.remove(key, cached)
+		router.mtx.Unlock()
+		require.True(t, w.closed)
+	})
+
+	t.Run("released writer stays open while cached", func(t *testing.T) {
+		w := &closingWriter{}
+		cached := &datasourceWriter{writer: w, refs: 1}
+		router.writers[key] = cached
+
+		router.release(cached)
+		require.False(t, w.closed)
+	})
+}
+
+type closingWriter struct {
+	recordingWriter
+	closed bool
+}
+
+func (w *closingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+type recordingWriter struct {
+	name string
+}
+
+func (w *recordingWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	w.name = name
+	return nil
+}
@@ -0,0 +1,124 @@
+package writer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	sqlDriverMySQL    = "mysql"
+	sqlDriverPostgres = "postgres"
+	sqlDriverMSSQL    = "sqlserver"
+
+	// sqlInsertBatchSize is the maximum number of rows inserted by a single statement.
+	// It keeps the number of parameters below the limit of 2100 parameters per statement of SQL Server.
+	sqlInsertBatchSize = 500
+)
+
+// SQLWriter inserts the results of recording rules into a table of a SQL database.
+// The table must have the columns time, metric, labels and value:
+//
+//	CREATE TABLE recorded_metrics (time TIMESTAMP NOT NULL, metric VARCHAR(255) NOT NULL, labels TEXT NOT NULL, value DOUBLE PRECISION NOT NULL)
+//
+// The labels are stored as a JSON object.
+type SQLWriter struct {
+	db     *sql.DB
+	driver string
+	table  string
+	logger log.Logger
+}
+
+func NewSQLWriter(db *sql.DB, driver string, table string, l log.Logger) *SQLWriter {
+	return &SQLWriter{
+		db:     db,
+		driver: driver,
+		table:  table,
+		logger: l,
+	}
+}
+
+// Write inserts the given frames into the table.
+func (w *SQLWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, extraLabels map[string]string) error {
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return err
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	for start := 0; start < len(points); start += sqlInsertBatchSize {
+		end := min(start+sqlInsertBatchSize, len(points))
+		query, args, err := w.insertStatement(points[start:end])
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to insert into table %s: %w", w.table, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	w.logger.FromContext(ctx).Debug("Inserted points", "table", w.table, "points", len(points))
+	return nil
+}
+
+// Close closes the connections to the database.
+func (w *SQLWriter) Close() error {
+	return w.db.Close()
+}
+
+func (w *SQLWriter) insertStatement(points []Point) (string, []any, error) {
+	var sb strings.Builder
+	args := make([]any, 0, len(points)*4)
+	sb.WriteString("INSERT INTO ")
+	sb.WriteString(w.table)
+	sb.WriteString(" (time, metric, labels, value) VALUES ")
+	for i, p := range points {
+		labels, err := json.Marshal(p.Labels)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to marshal labels: %w", err)
+		}
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("(")
+		for j := 0; j < 4; j++ {
+			if j > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(w.placeholder(len(args) + j + 1))
+		}
+		sb.WriteString(")")
+		args = append(args, time.Unix(p.Metric.T, 0).UTC(), p.Name, string(labels), p.Metric.V)
+	}
+	return sb.String(), args, nil
+}
+
+// placeholder returns the placeholder of the n-th parameter of a statement, starting from 1.
+func (w *SQLWriter) placeholder(n int) string {
+	switch w.driver {
+	case sqlDriverPostgres:
+		return fmt.Sprintf("$%d", n)
+	case sqlDriverMSSQL:
+		return fmt.Sprintf("@p%d", n)
+	default:
+		return "?"
+	}
+}
@@ -0,0 +1,285 @@
+package writer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	sdkproxy "github.com/grafana/grafana-plugin-sdk-go/backend/proxy"
+	"github.com/lib/pq"
+	mssql "github.com/microsoft/go-mssqldb"
+	"golang.org/x/net/proxy"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// openSQLDatasource opens a connection pool to the database of a SQL data source, and returns it with the name of its
+// driver. The connections use the TLS settings of the data source, and go through the secure socks proxy if it is
+// enabled for the data source, like the queries of the data source.
+func (r *Router) openSQLDatasource(ctx context.Context, ds *datasources.DataSource, jsonData *simplejson.Json) (*sql.DB, string, error) {
+	password, err := r.datasources.DecryptedPassword(ctx, ds)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decrypt data source password: %w", err)
+	}
+	secure, err := r.datasources.DecryptedValues(ctx, ds)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decrypt data source secure settings: %w", err)
+	}
+	dialer, err := r.secureSocksProxyDialer(ds, jsonData, secure)
+	if err != nil {
+		return nil, "", err
+	}
+	database := jsonData.Get("database").MustString(ds.Database)
+
+	var db *sql.DB
+	var driver string
+	switch {
+	case ds.Type == datasources.DS_MYSQL:
+		driver = sqlDriverMySQL
+		db, err = openMySQL(ds, jsonData, secure, password, database, dialer)
+	case ds.Type == datasources.DS_MSSQL:
+		driver = sqlDriverMSSQL
+		db, err = openMSSQL(ds, jsonData, password, database, dialer)
+	default:
+		driver = sqlDriverPostgres
+		db, err = r.openPostgres(ds, jsonData, secure, password, database, dialer)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open connection to data source: %w", err)
+	}
+	db.SetMaxOpenConns(jsonData.Get("maxOpenConns").MustInt(10))
+	db.SetConnMaxLifetime(time.Duration(jsonData.Get("connMaxLifetime").MustInt(14400)) * time.Second)
+	return db, driver, nil
+}
+
+func openMySQL(ds *datasources.DataSource, jsonData *simplejson.Json, secure map[string]string, password, database string, dialer *sqlProxyDialer) (*sql.DB, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = ds.User
+	cfg.Passwd = password
+	cfg.Net = "tcp"
+	if strings.HasPrefix(ds.URL, "/") {
+		cfg.Net = "unix"
+	}
+	cfg.Addr = ds.URL
+	cfg.DBName = database
+	cfg.ParseTime = true
+
+	tlsConfig, err := mysqlTLSConfig(jsonData, secure)
+	if err != nil {
+		return nil, err
+	}
+	// The TLS configurations and the dialers are registered globally by name in the driver. The name contains the
+	// version of the data source so that the connections opened before the data source is updated are not affected.
+	name := fmt.Sprintf("ngalert-%d-%s-%d", ds.OrgID, ds.UID, ds.Version)
+	if tlsConfig != nil {
+		if err := mysql.RegisterTLSConfig(name, tlsConfig); err != nil {
+			return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+		}
+		cfg.TLSConfig = name
+	} else if jsonData.Get("tlsSkipVerify").MustBool(false) {
+		cfg.TLSConfig = "skip-verify"
+	}
+	if dialer != nil {
+		network := cfg.Net
+		mysql.RegisterDialContext(name, func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, network, addr)
+		})
+		cfg.Net = name
+	}
+	return sql.Open(sqlDriverMySQL, cfg.FormatDSN())
+}
+
+// mysqlTLSConfig returns the TLS configuration of a MySQL data source with a CA or a client certificate, or nil if it
+// uses the default TLS configuration of the driver.
+func mysqlTLSConfig(jsonData *simplejson.Json, secure map[string]string) (*tls.Config, error) {
+	withCACert := jsonData.Get("tlsAuthWithCACert").MustBool(false)
+	withClientCert := jsonData.Get("tlsAuth").MustBool(false)
+	if !withCACert && !withClientCert {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		ServerName: jsonData.Get("serverName").MustString(""),
+		// nolint:gosec
+		InsecureSkipVerify: jsonData.Get("tlsSkipVerify").MustBool(false),
+	}
+	if withCACert {
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM([]byte(secure["tlsCACert"])) {
+			return nil, errors.New("invalid TLS CA certificate")
+		}
+	}
+	if withClientCert {
+		cert, err := tls.X509KeyPair([]byte(secure["tlsClientCert"]), []byte(secure["tlsClientKey"]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS client certificate or key: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func openMSSQL(ds *datasources.DataSource, jsonData *simplejson.Json, password, database string, dialer *sqlProxyDialer) (*sql.DB, error) {
+	q := url.Values{}
+	q.Set("database", database)
+	encrypt := jsonData.Get("encrypt").MustString("false")
+	q.Set("encrypt", encrypt)
+	if encrypt == "true" {
+		q.Set("TrustServerCertificate", strconv.FormatBool(jsonData.Get("tlsSkipVerify").MustBool(false)))
+		if serverName := jsonData.Get("serverName").MustString(""); serverName != "" {
+			q.Set("hostNameInCertificate", serverName)
+		}
+		if rootCert := jsonData.Get("sslRootCertFile").MustString(""); rootCert != "" {
+			q.Set("certificate", rootCert)
+		}
+	}
+	u := &url.URL{Scheme: "sqlserver", User: url.UserPassword(ds.User, password), Host: ds.URL, RawQuery: q.Encode()}
+
+	connector, err := mssql.NewConnector(u.String())
+	if err != nil {
+		return nil, err
+	}
+	if dialer != nil {
+		connector.Dialer = dialer
+	}
+	return sql.OpenDB(connector), nil
+}
+
+func (r *Router) openPostgres(ds *datasources.DataSource, jsonData *simplejson.Json, secure map[string]string, password, database string, dialer *sqlProxyDialer) (*sql.DB, error) {
+	q := url.Values{}
+	sslMode := jsonData.Get("sslmode").MustString("verify-full")
+	q.Set("sslmode", sslMode)
+	if sslMode != "disable" {
+		files := map[string]string{
+			"sslrootcert": jsonData.Get("sslRootCertFile").MustString(""),
+			"sslcert":     jsonData.Get("sslCertFile").MustString(""),
+			"sslkey":      jsonData.Get("sslKeyFile").MustString(""),
+		}
+		if jsonData.Get("tlsConfigurationMethod").MustString("") == "file-content" {
+			var err error
+			files, err = r.writePostgresCertificates(ds, secure)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for param, file := range files {
+			if file != "" {
+				q.Set(param, file)
+			}
+		}
+	}
+	u := &url.URL{Scheme: "postgres", User: url.UserPassword(ds.User, password), Host: ds.URL, Path: "/" + database, RawQuery: q.Encode()}
+
+	connector, err := pq.NewConnector(u.String())
+	if err != nil {
+		return nil, err
+	}
+	if dialer != nil {
+		connector.Dialer(dialer)
+	}
+	return sql.OpenDB(connector), nil
+}
+
+// writePostgresCertificates writes the certificates of a Postgres data source that are configured by content to
+// files, since the driver only reads them from files, and returns the files by connection parameter.
+func (r *Router) writePostgresCertificates(ds *datasources.DataSource, secure map[string]string) (map[string]string, error) {
+	dir := filepath.Join(r.dataPath, "tls", "recording-rules", fmt.Sprintf("%d-%s", ds.OrgID, ds.UID))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the directory of the TLS certificates: %w", err)
+	}
+	files := make(map[string]string, 3)
+	for param, f := range map[string]struct{ key, name string }{
+		"sslrootcert": {key: "tlsCACert", name: "root.crt"},
+		"sslcert":     {key: "tlsClientCert", name: "client.crt"},
+		"sslkey":      {key: "tlsClientKey", name: "client.key"},
+	} {
+		content := strings.TrimSpace(secure[f.key])
+		if content == "" {
+			continue
+		}
+		path := filepath.Join(dir, f.name)
+		// The driver requires the key to be readable by the owner only.
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write TLS certificate: %w", err)
+		}
+		files[param] = path
+	}
+	return files, nil
+}
+
+// secureSocksProxyDialer returns the dialer of the secure socks proxy if it is enabled for the data source, or nil.
+func (r *Router) secureSocksProxyDialer(ds *datasources.DataSource, jsonData *simplejson.Json, secure map[string]string) (*sqlProxyDialer, error) {
+	if !r.proxy.Enabled || !jsonData.Get("enableSecureSocksProxy").MustBool(false) {
+		return nil, nil
+	}
+	opts := &sdkproxy.Options{
+		Enabled:        true,
+		DatasourceName: ds.Name,
+		DatasourceType: ds.Type,
+		Auth: &sdkproxy.AuthOptions{
+			Username: jsonData.Get("secureSocksProxyUsername").MustString(ds.UID),
+			Password: secure["secureSocksProxyPassword"],
+		},
+		Timeouts: &sdkproxy.TimeoutOptions{
+			Timeout:   sdkproxy.DefaultTimeoutOptions.Timeout,
+			KeepAlive: sdkproxy.DefaultTimeoutOptions.KeepAlive,
+		},
+		ClientCfg: &sdkproxy.ClientCfg{
+			ClientCert:    r.proxy.ClientCertFilePath,
+			ClientKey:     r.proxy.ClientKeyFilePath,
+			RootCAs:       r.proxy.RootCAFilePaths,
+			ClientCertVal: r.proxy.ClientCert,
+			ClientKeyVal:  r.proxy.ClientKey,
+			RootCAsVals:   r.proxy.RootCAs,
+			ProxyAddress:  r.proxy.ProxyAddress,
+			ServerName:    r.proxy.ServerName,
+			AllowInsecure: r.proxy.AllowInsecure,
+		},
+	}
+	if v, err := jsonData.Get("timeout").Float64(); err == nil {
+		opts.Timeouts.Timeout = time.Duration(v) * time.Second
+	}
+	if v, err := jsonData.Get("keepAlive").Float64(); err == nil {
+		opts.Timeouts.KeepAlive = time.Duration(v) * time.Second
+	}
+	d, err := sdkproxy.New(opts).NewSecureSocksProxyContextDialer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the secure socks proxy dialer: %w", err)
+	}
+	contextDialer, ok := d.(proxy.ContextDialer)
+	if !ok {
+		return nil, errors.New("the secure socks proxy dialer does not support contexts")
+	}
+	return &sqlProxyDialer{dialer: contextDialer}, nil
+}
+
+// sqlProxyDialer dials the connections to a SQL data source through the secure socks proxy. It implements the dialer
+// interfaces of the Postgres and SQL Server drivers.
+type sqlProxyDialer struct {
+	dialer proxy.ContextDialer
+}
+
+func (d *sqlProxyDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.dialer.DialContext(context.Background(), network, addr)
+}
+
+func (d *sqlProxyDialer) DialTimeout(network, addr string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.dialer.DialContext(ctx, network, addr)
+}
+
+func (d *sqlProxyDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return d.dialer.DialContext(ctx, network, addr)
+}
@@ -0,0 +1,70 @@
+package writer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestSQLWriter_Write(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE metrics (time TIMESTAMP, metric TEXT, labels TEXT, value REAL)")
+	require.NoError(t, err)
+
+	w := NewSQLWriter(db, "sqlite3", "metrics", log.NewNopLogger())
+	t.Cleanup(func() { require.NoError(t, w.Close()) })
+
+	now := time.Unix(1700000000, 0)
+	frames := frameGenMulti(t, []map[string]string{{"a": "1"}, {"a": "2"}})
+	require.NoError(t, w.Write(context.Background(), "test_metric", now, frames, map[string]string{"extra": "label"}))
+
+	rows, err := db.Query("SELECT metric, labels FROM metrics ORDER BY labels")
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+
+	var got []map[string]string
+	for rows.Next() {
+		var metric, rawLabels string
+		require.NoError(t, rows.Scan(&metric, &rawLabels))
+		require.Equal(t, "test_metric", metric)
+		lbls := map[string]string{}
+		require.NoError(t, json.Unmarshal([]byte(rawLabels), &lbls))
+		got = append(got, lbls)
+	}
+	require.NoError(t, rows.Err())
+	require.Equal(t, []map[string]string{{"a": "1", "extra": "label"}, {"a": "2", "extra": "label"}}, got)
+}
+
+func TestSQLWriter_insertStatement(t *testing.T) {
+	points := []Point{
+		{Name: "m", Metric: Metric{T: 1, V: 1}},
+		{Name: "m", Metric: Metric{T: 2, V: 2}},
+	}
+
+	testCases := []struct {
+		driver   string
+		expected string
+	}{
+		{driver: sqlDriverMySQL, expected: "INSERT INTO t (time, metric, labels, value) VALUES (?, ?, ?, ?), (?, ?, ?, ?)"},
+		{driver: sqlDriverPostgres, expected: "INSERT INTO t (time, metric, labels, value) VALUES ($1, $2, $3, $4), ($5, $6, $7, $8)"},
+		{driver: sqlDriverMSSQL, expected: "INSERT INTO t (time, metric, labels, value) VALUES (@p1, @p2, @p3, @p4), (@p5, @p6, @p7, @p8)"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.driver, func(t *testing.T) {
+			w := &SQLWriter{driver: tc.driver, table: "t"}
+			stmt, args, err := w.insertStatement(points)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, stmt)
+			require.Len(t, args, 8)
+		})
+	}
+}
@@ -76,6 +76,7 @@ type AlertRuleV1 struct {
 	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
 	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
 	Record               *RecordV1               `json:"record" yaml:"record"`
+	DependsOn            []values.StringValue    `json:"dependsOn,omitempty" yaml:"dependsOn"`
 }
 
 func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
@@ -147,6 +148,12 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		}
 		alertRule.Record = &record
 	}
+	for _, uid := range rule.DependsOn {
+		if uid.Value() == "" {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: dependency UID cannot be empty", alertRule.Title)
+		}
+		alertRule.DependsOn = append(alertRule.DependsOn, uid.Value())
+	}
 	return alertRule, nil
 }
 
@@ -258,11 +265,29 @@ func (nsV1 *NotificationSettingsV1) mapToModel() (models.NotificationSettings, e
 type RecordV1 struct {
 	Metric values.StringValue `json:"metric" yaml:"metric"`
 	From   values.StringValue `json:"from" yaml:"from"`
+	Target *RecordTargetV1    `json:"target" yaml:"target"`
+}
+
+type RecordTargetV1 struct {
+	Type          values.StringValue `json:"type" yaml:"type"`
+	DatasourceUID values.StringValue `json:"datasourceUid" yaml:"datasourceUid"`
+	Table         values.StringValue `json:"table" yaml:"table"`
 }
 
 func (record *RecordV1) mapToModel() (models.Record, error) {
-	return models.Record{
+	r := models.Record{
 		Metric: record.Metric.Value(),
 		From:   record.From.Value(),
-	}, nil
+	}
+	if record.Target != nil {
+		r.Target = &models.RecordTarget{
+			Type:          models.RecordTargetType(record.Target.Type.Value()),
+			DatasourceUID: record.Target.DatasourceUID.Value(),
+			Table:         record.Target.Table.Value(),
+		}
+		if err := r.Target.Validate(); err != nil {
+			return models.Record{}, fmt.Errorf("invalid record target: %w", err)
+		}
+	}
+	return r, nil
 }
@@ -21,6 +21,8 @@ type parsedRequest struct {
 	hasExpression bool
 	parsedQueries map[string][]parsedQuery
 	dsTypes       map[string]bool
+	// debug requests the explanation of how expressions are executed.
+	debug bool
 }
 
 func (pr parsedRequest) getFlattenedQueries() []parsedQuery {
@@ -123,6 +123,12 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddManagedFolderAlertingSilencesActionsMigrator(mg)
 
 	ualert.AddRecordingRuleColumns(mg)
+
+	ualert.AddStateHistoryMigrations(mg)
+
+	ualert.AddRuleDependenciesColumns(mg)
+
+	ualert.AddRuleVersionAuthorColumns(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
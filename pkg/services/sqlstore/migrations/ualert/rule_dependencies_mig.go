@@ -0,0 +1,19 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddRuleDependenciesColumns adds columns to alert_rule and alert_rule_version to store the dependencies of rules on
+// other rules of the same group.
+func AddRuleDependenciesColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add depends_on column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "depends_on",
+		Type:     migrator.DB_Text, // Text, as this contains a JSON-ified list of rule UIDs.
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add depends_on column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "depends_on",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+}
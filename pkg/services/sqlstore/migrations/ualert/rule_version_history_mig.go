@@ -0,0 +1,21 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddRuleVersionAuthorColumns adds columns to alert_rule and alert_rule_version to store the user who made a change
+// to a rule.
+func AddRuleVersionAuthorColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add updated_by column to alert_rule", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "updated_by",
+		Type:     migrator.DB_NVarchar,
+		Length:   40,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add created_by column to alert_rule_version", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "created_by",
+		Type:     migrator.DB_NVarchar,
+		Length:   40,
+		Nullable: true,
+	}))
+}
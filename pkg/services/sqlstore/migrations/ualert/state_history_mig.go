@@ -0,0 +1,42 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddStateHistoryMigrations creates the table of the "sql" state history backend.
+func AddStateHistoryMigrations(mg *migrator.Migrator) {
+	stateHistory := migrator.Table{
+		Name: "alert_state_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "rule_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_title", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "rule_group", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "namespace_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: true},
+			{Name: "panel_id", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "condition", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "previous_state", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "current_state", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "state_values", Type: migrator.DB_Text, Nullable: true},
+			{Name: "at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "downsampled", Type: migrator.DB_Bool, Nullable: false, Default: "0"},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid", "at"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "labels_hash", "at"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "dashboard_uid", "panel_id", "at"}, Type: migrator.IndexType},
+			{Cols: []string{"at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_state_history table", migrator.NewAddTableMigration(stateHistory))
+	mg.AddMigration("add index in alert_state_history on org_id, rule_uid and at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[0]))
+	mg.AddMigration("add index in alert_state_history on org_id, labels_hash and at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[1]))
+	mg.AddMigration("add index in alert_state_history on org_id, dashboard_uid, panel_id and at columns", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[2]))
+	mg.AddMigration("add index in alert_state_history on at column", migrator.NewAddIndexMigration(stateHistory, stateHistory.Indices[3]))
+}
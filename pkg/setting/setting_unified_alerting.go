@@ -2,6 +2,7 @@ package setting
 
 import (
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -60,10 +61,14 @@ const (
 	// with intervals that are not exactly divided by this number not to be evaluated
 	SchedulerBaseInterval = 10 * time.Second
 	// DefaultRuleEvaluationInterval indicates a default interval of for how long a rule should be evaluated to change state from Pending to Alerting
-	DefaultRuleEvaluationInterval  = SchedulerBaseInterval * 6 // == 60 seconds
-	stateHistoryDefaultEnabled     = true
-	lokiDefaultMaxQueryLength      = 721 * time.Hour // 30d1h, matches the default value in Loki
-	defaultRecordingRequestTimeout = 10 * time.Second
+	DefaultRuleEvaluationInterval      = SchedulerBaseInterval * 6 // == 60 seconds
+	stateHistoryDefaultEnabled         = true
+	lokiDefaultMaxQueryLength          = 721 * time.Hour // 30d1h, matches the default value in Loki
+	sqlHistoryDefaultRetention         = 30 * 24 * time.Hour
+	sqlHistoryDefaultDownsample        = time.Hour
+	prometheusHistoryDefaultMetricName = "ALERTS"
+	defaultRecordingRequestTimeout     = 10 * time.Second
+	defaultRecordingLocalTSDBRetention = 15 * 24 * time.Hour
 )
 
 type UnifiedAlertingSettings struct {
@@ -87,6 +92,7 @@ type UnifiedAlertingSettings struct {
 	HARedisMaxConns                int
 	HARedisTLSEnabled              bool
 	HARedisTLSConfig               dstls.ClientConfig
+	HARuleShardingEnabled          bool
 	MaxAttempts                    int64
 	MinInterval                    time.Duration
 	EvaluationTimeout              time.Duration
@@ -106,6 +112,7 @@ type UnifiedAlertingSettings struct {
 	StateHistory                  UnifiedAlertingStateHistorySettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	RecordingRules                RecordingRuleSettings
+	EvaluationBudgets             EvaluationBudgetSettings
 
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
 	MaxStateSaveConcurrency   int
@@ -115,6 +122,9 @@ type UnifiedAlertingSettings struct {
 	// Retention period for Alertmanager notification log entries.
 	NotificationLogRetention time.Duration
 
+	// Maximum number of notification deliveries kept in the delivery log of the Alertmanager of each organization.
+	NotificationDeliveryLogLimit int
+
 	// Duration for which a resolved alert state transition will continue to be sent to the Alertmanager.
 	ResolvedAlertRetention time.Duration
 }
@@ -125,6 +135,31 @@ type RecordingRuleSettings struct {
 	BasicAuthPassword string
 	CustomHeaders     map[string]string
 	Timeout           time.Duration
+
+	// LocalTSDBEnabled enables the embedded time series database that recording rules can write to.
+	LocalTSDBEnabled   bool
+	LocalTSDBPath      string
+	LocalTSDBRetention time.Duration
+}
+
+// EvaluationBudgetSettings limits the evaluations of alert rules per organization and per data source.
+// A zero value means no limit.
+type EvaluationBudgetSettings struct {
+	// OrgMaxConcurrency is the maximum number of rules of an organization that are evaluated at the same time.
+	OrgMaxConcurrency int
+	// OrgMaxQPS is the maximum number of evaluations per second of the rules of an organization.
+	OrgMaxQPS float64
+	// DatasourceMaxConcurrency is the maximum number of rules that query a data source at the same time.
+	DatasourceMaxConcurrency int
+	// DatasourceMaxQPS is the maximum number of evaluations per second of the rules that query a data source.
+	DatasourceMaxQPS float64
+	// Datasources overrides the budget of specific data sources, by data source UID.
+	Datasources map[string]DatasourceEvaluationBudget
+}
+
+type DatasourceEvaluationBudget struct {
+	MaxConcurrency int
+	MaxQPS         float64
 }
 
 // RemoteAlertmanagerSettings contains the configuration needed
@@ -163,6 +198,23 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+	// SQLRetention is how long the "sql" backend keeps state history. Zero keeps it forever.
+	SQLRetention time.Duration
+	// SQLDownsampleAfter is the age after which the "sql" backend downsamples state history. Zero disables downsampling.
+	SQLDownsampleAfter time.Duration
+	// SQLDownsampleInterval is the interval in which the "sql" backend keeps a single transition per alert instance when downsampling.
+	SQLDownsampleInterval time.Duration
+	// PrometheusRemoteWriteURL is the remote write endpoint the "prometheus" backend writes the alert state series to.
+	PrometheusRemoteWriteURL string
+	// PrometheusBasicAuthUsername and PrometheusBasicAuthPassword are used for basic auth
+	// if one of them is set.
+	PrometheusBasicAuthUsername string
+	PrometheusBasicAuthPassword string
+	// PrometheusMetricName is the name of the series of the active alerts. The series of the active since
+	// timestamps is named the same with the _FOR_STATE suffix.
+	PrometheusMetricName string
+	// OTLPLogsURL is the OTLP/HTTP logs endpoint the "prometheus" backend also writes state transitions to, if set.
+	OTLPLogsURL string
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -269,6 +321,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisTLSConfig.InsecureSkipVerify = ua.Key("ha_redis_tls_insecure_skip_verify").MustBool(false)
 	uaCfg.HARedisTLSConfig.CipherSuites = ua.Key("ha_redis_tls_cipher_suites").MustString("")
 	uaCfg.HARedisTLSConfig.MinVersion = ua.Key("ha_redis_tls_min_version").MustString("")
+	uaCfg.HARuleShardingEnabled = ua.Key("ha_rule_sharding_enabled").MustBool(false)
 
 	// TODO load from ini file
 	uaCfg.DefaultConfiguration = alertmanagerDefaultConfiguration
@@ -395,18 +448,26 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	stateHistory := iniFile.Section("unified_alerting.state_history")
 	stateHistoryLabels := iniFile.Section("unified_alerting.state_history.external_labels")
 	uaCfgStateHistory := UnifiedAlertingStateHistorySettings{
-		Enabled:               stateHistory.Key("enabled").MustBool(stateHistoryDefaultEnabled),
-		Backend:               stateHistory.Key("backend").MustString("annotations"),
-		LokiRemoteURL:         stateHistory.Key("loki_remote_url").MustString(""),
-		LokiReadURL:           stateHistory.Key("loki_remote_read_url").MustString(""),
-		LokiWriteURL:          stateHistory.Key("loki_remote_write_url").MustString(""),
-		LokiTenantID:          stateHistory.Key("loki_tenant_id").MustString(""),
-		LokiBasicAuthUsername: stateHistory.Key("loki_basic_auth_username").MustString(""),
-		LokiBasicAuthPassword: stateHistory.Key("loki_basic_auth_password").MustString(""),
-		LokiMaxQueryLength:    stateHistory.Key("loki_max_query_length").MustDuration(lokiDefaultMaxQueryLength),
-		MultiPrimary:          stateHistory.Key("primary").MustString(""),
-		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
-		ExternalLabels:        stateHistoryLabels.KeysHash(),
+		Enabled:                     stateHistory.Key("enabled").MustBool(stateHistoryDefaultEnabled),
+		Backend:                     stateHistory.Key("backend").MustString("annotations"),
+		LokiRemoteURL:               stateHistory.Key("loki_remote_url").MustString(""),
+		LokiReadURL:                 stateHistory.Key("loki_remote_read_url").MustString(""),
+		LokiWriteURL:                stateHistory.Key("loki_remote_write_url").MustString(""),
+		LokiTenantID:                stateHistory.Key("loki_tenant_id").MustString(""),
+		LokiBasicAuthUsername:       stateHistory.Key("loki_basic_auth_username").MustString(""),
+		LokiBasicAuthPassword:       stateHistory.Key("loki_basic_auth_password").MustString(""),
+		LokiMaxQueryLength:          stateHistory.Key("loki_max_query_length").MustDuration(lokiDefaultMaxQueryLength),
+		MultiPrimary:                stateHistory.Key("primary").MustString(""),
+		MultiSecondaries:            splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
+		ExternalLabels:              stateHistoryLabels.KeysHash(),
+		SQLRetention:                stateHistory.Key("sql_retention").MustDuration(sqlHistoryDefaultRetention),
+		SQLDownsampleAfter:          stateHistory.Key("sql_downsample_after").MustDuration(0),
+		SQLDownsampleInterval:       stateHistory.Key("sql_downsample_interval").MustDuration(sqlHistoryDefaultDownsample),
+		PrometheusRemoteWriteURL:    stateHistory.Key("prometheus_remote_write_url").MustString(""),
+		PrometheusBasicAuthUsername: stateHistory.Key("prometheus_basic_auth_username").MustString(""),
+		PrometheusBasicAuthPassword: stateHistory.Key("prometheus_basic_auth_password").MustString(""),
+		PrometheusMetricName:        stateHistory.Key("prometheus_metric_name").MustString(prometheusHistoryDefaultMetricName),
+		OTLPLogsURL:                 stateHistory.Key("otlp_logs_url").MustString(""),
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
@@ -416,6 +477,13 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		BasicAuthUsername: rr.Key("basic_auth_username").MustString(""),
 		BasicAuthPassword: rr.Key("basic_auth_password").MustString(""),
 		Timeout:           rr.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+
+		LocalTSDBEnabled:   rr.Key("local_tsdb_enabled").MustBool(false),
+		LocalTSDBPath:      filepath.Join(cfg.DataPath, "recording-rules"),
+		LocalTSDBRetention: rr.Key("local_tsdb_retention").MustDuration(defaultRecordingLocalTSDBRetention),
+	}
+	if uaCfgRecordingRules.LocalTSDBRetention <= 0 {
+		return fmt.Errorf("setting 'local_tsdb_retention' in [recording_rules] must be positive")
 	}
 
 	rrHeaders := iniFile.Section("recording_rules.custom_headers")
@@ -427,6 +495,27 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfg.RecordingRules = uaCfgRecordingRules
 
+	budgets := iniFile.Section("unified_alerting.evaluation_budgets")
+	uaCfgBudgets := EvaluationBudgetSettings{
+		OrgMaxConcurrency:        budgets.Key("org_max_concurrency").MustInt(0),
+		OrgMaxQPS:                budgets.Key("org_max_qps").MustFloat64(0),
+		DatasourceMaxConcurrency: budgets.Key("datasource_max_concurrency").MustInt(0),
+		DatasourceMaxQPS:         budgets.Key("datasource_max_qps").MustFloat64(0),
+	}
+	if uaCfgBudgets.OrgMaxConcurrency < 0 || uaCfgBudgets.OrgMaxQPS < 0 || uaCfgBudgets.DatasourceMaxConcurrency < 0 || uaCfgBudgets.DatasourceMaxQPS < 0 {
+		return fmt.Errorf("evaluation budgets in [unified_alerting.evaluation_budgets] cannot be negative")
+	}
+	budgetsDatasources := iniFile.Section("unified_alerting.evaluation_budgets.datasources")
+	uaCfgBudgets.Datasources = make(map[string]DatasourceEvaluationBudget, len(budgetsDatasources.Keys()))
+	for _, key := range budgetsDatasources.Keys() {
+		budget, err := parseDatasourceEvaluationBudget(key.Value())
+		if err != nil {
+			return fmt.Errorf("invalid evaluation budget of data source '%s' in [unified_alerting.evaluation_budgets.datasources]: %w", key.Name(), err)
+		}
+		uaCfgBudgets.Datasources[key.Name()] = budget
+	}
+	uaCfg.EvaluationBudgets = uaCfgBudgets
+
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
 
 	uaCfg.StatePeriodicSaveInterval, err = gtime.ParseDuration(valueAsString(ua, "state_periodic_save_interval", (time.Minute * 5).String()))
@@ -439,6 +528,11 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		return err
 	}
 
+	uaCfg.NotificationDeliveryLogLimit = ua.Key("notification_delivery_log_limit").MustInt(1000)
+	if uaCfg.NotificationDeliveryLogLimit <= 0 {
+		return fmt.Errorf("setting 'notification_delivery_log_limit' must be positive")
+	}
+
 	uaCfg.ResolvedAlertRetention, err = gtime.ParseDuration(valueAsString(ua, "resolved_alert_retention", (15 * time.Minute).String()))
 	if err != nil {
 		return err
@@ -452,6 +546,27 @@ func GetAlertmanagerDefaultConfiguration() string {
 	return alertmanagerDefaultConfiguration
 }
 
+// parseDatasourceEvaluationBudget parses a budget in the format "<max concurrency>[,<max QPS>]".
+func parseDatasourceEvaluationBudget(s string) (DatasourceEvaluationBudget, error) {
+	var budget DatasourceEvaluationBudget
+	parts := splitTrim(s, ",")
+	if len(parts) > 2 {
+		return budget, fmt.Errorf("expected <max concurrency>[,<max QPS>], got %q", s)
+	}
+	var err error
+	if parts[0] != "" {
+		if budget.MaxConcurrency, err = strconv.Atoi(parts[0]); err != nil || budget.MaxConcurrency < 0 {
+			return budget, fmt.Errorf("max concurrency must be a non-negative integer, got %q", parts[0])
+		}
+	}
+	if len(parts) == 2 && parts[1] != "" {
+		if budget.MaxQPS, err = strconv.ParseFloat(parts[1], 64); err != nil || budget.MaxQPS < 0 {
+			return budget, fmt.Errorf("max QPS must be a non-negative number, got %q", parts[1])
+		}
+	}
+	return budget, nil
+}
+
 func splitTrim(s string, sep string) []string {
 	spl := strings.Split(s, sep)
 	for i := range spl {
@@ -346,3 +346,40 @@ func TestHARedisTLSSettings(t *testing.T) {
 	require.Equal(t, cipherSuites, cfg.UnifiedAlerting.HARedisTLSConfig.CipherSuites)
 	require.Equal(t, minVersion, cfg.UnifiedAlerting.HARedisTLSConfig.MinVersion)
 }
+
+func TestEvaluationBudgetSettings(t *testing.T) {
+	t.Run("reads budgets and data source overrides", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[unified_alerting.evaluation_budgets]
+org_max_concurrency = 10
+datasource_max_qps = 2.5
+
+[unified_alerting.evaluation_budgets.datasources]
+elastic = 4,0.5
+loki = 8
+`))
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.NoError(t, cfg.ReadUnifiedAlertingSettings(f))
+		require.Equal(t, EvaluationBudgetSettings{
+			OrgMaxConcurrency: 10,
+			DatasourceMaxQPS:  2.5,
+			Datasources: map[string]DatasourceEvaluationBudget{
+				"elastic": {MaxConcurrency: 4, MaxQPS: 0.5},
+				"loki":    {MaxConcurrency: 8},
+			},
+		}, cfg.UnifiedAlerting.EvaluationBudgets)
+	})
+
+	t.Run("fails on invalid data source override", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[unified_alerting.evaluation_budgets.datasources]
+elastic = many
+`))
+		require.NoError(t, err)
+
+		cfg := NewCfg()
+		require.ErrorContains(t, cfg.ReadUnifiedAlertingSettings(f), "elastic")
+	})
+}
@@ -58,6 +58,12 @@ export function RuleListIcon({
     stateName = 'Insufficient data';
   }
 
+  if (health === 'delayed') {
+    iconName = 'clock-nine';
+    iconColor = 'warning';
+    stateName = 'Evaluation delayed';
+  }
+
   if (isErrorHealth(health)) {
     iconName = 'times-circle';
     iconColor = 'error';
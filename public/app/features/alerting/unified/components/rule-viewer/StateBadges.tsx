@@ -57,6 +57,11 @@ export const StateBadge = ({ state, health }: StateBadgeProps) => {
     stateLabel = 'No data';
   }
 
+  if (health === 'delayed') {
+    color = 'warning';
+    stateLabel = 'Delayed';
+  }
+
   return <Badge color={color} text={stateLabel} />;
 };
 
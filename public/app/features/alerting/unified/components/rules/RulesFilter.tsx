@@ -55,6 +55,7 @@ const RuleTypeOptions: SelectableValue[] = [
 const RuleHealthOptions: SelectableValue[] = [
   { label: 'Ok', value: RuleHealth.Ok },
   { label: 'No Data', value: RuleHealth.NoData },
+  { label: 'Delayed', value: RuleHealth.Delayed },
   { label: 'Error', value: RuleHealth.Error },
 ];
 
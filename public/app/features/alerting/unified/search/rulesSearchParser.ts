@@ -41,6 +41,7 @@ export enum RuleHealth {
   Ok = 'ok',
   Error = 'error',
   NoData = 'nodata',
+  Delayed = 'delayed',
   Unknown = 'unknown',
 }
 
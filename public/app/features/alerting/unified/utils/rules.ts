@@ -93,6 +93,8 @@ export function getRuleHealth(health: string): RuleHealth | undefined {
       return RuleHealth.Ok;
     case 'nodata':
       return RuleHealth.NoData;
+    case 'delayed':
+      return RuleHealth.Delayed;
     case 'error':
     case 'err': // Prometheus-compat data sources
       return RuleHealth.Error;
@@ -66,7 +66,7 @@ export const expressionTypes: Array<SelectableValue<ExpressionQueryType>> = [
   {
     value: ExpressionQueryType.sql,
     label: 'SQL',
-    description: 'Transform data using SQL. Supports Aggregate/Analytics functions from DuckDB',
+    description: 'Transform data using SQL. Supports joins, aggregate and window functions',
   },
 ].filter((expr) => {
   if (expr.value === ExpressionQueryType.sql) {
@@ -29,7 +29,7 @@ export function hasAlertState(alert: Alert, state: PromAlertingRuleState | Grafa
 }
 
 // Prometheus API uses "err" but grafana API uses "error" *sigh*
-export type RuleHealth = 'nodata' | 'error' | 'err' | string;
+export type RuleHealth = 'nodata' | 'error' | 'err' | 'delayed' | string;
 
 interface RuleBase {
   health: RuleHealth;